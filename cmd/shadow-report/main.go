@@ -0,0 +1,94 @@
+// Command shadow-report scores a candidate model evaluated in shadow mode (ML_SHADOW_ENABLED)
+// against the live model. It reads the linked live / candidate pairs from the dataset database
+// and prints, per field the service acts on, how often the candidate agreed:
+//
+//	call_type     the dispatch call types (whether an alert posts)
+//	tac_channel   the dispatch TAC channels (which channel is monitored)
+//	sar_notified  the summary's SAR-notified flag (the badge on the alert)
+//
+// Usage:
+//
+//	DATASET_POSTGRES_URL=postgres://... go run ./cmd/shadow-report -since 168h
+//	go run ./cmd/shadow-report -dsn postgres://... -model gpt-4.1-mini
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // database/sql driver "pgx"
+	"github.com/searchandrescuegg/transcribe/internal/dataset"
+)
+
+func main() {
+	var (
+		dsn     = flag.String("dsn", os.Getenv("DATASET_POSTGRES_URL"), "Dataset Postgres URL (default $DATASET_POSTGRES_URL)")
+		since   = flag.Duration("since", 7*24*time.Hour, "How far back to read pairs")
+		model   = flag.String("model", "", "Only pairs whose candidate was this model (default: all)")
+		timeout = flag.Duration("timeout", 60*time.Second, "Query timeout")
+	)
+	flag.Parse()
+	if *dsn == "" {
+		slog.Error("-dsn or DATASET_POSTGRES_URL is required")
+		os.Exit(2)
+	}
+
+	db, err := sql.Open("pgx", *dsn)
+	if err != nil {
+		slog.Error("could not open dataset database", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	pairs, err := dataset.LoadShadowPairs(ctx, db, time.Now().Add(-*since), *model)
+	if err != nil {
+		slog.Error("could not load shadow pairs", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if len(pairs) == 0 {
+		fmt.Println("no shadow pairs in range")
+		return
+	}
+
+	report := dataset.CompareShadowPairs(pairs)
+	models := map[string]bool{}
+	for _, p := range pairs {
+		models[p.ShadowModel] = true
+	}
+	fmt.Printf("%d pairs over the last %s; candidate model(s): %v\n\n", len(pairs), *since, sortedKeys(models))
+
+	fmt.Printf("%-18s %8s %14s\n", "kind", "pairs", "shadow errors")
+	kinds := make(map[string]bool, len(report.Pairs))
+	for k := range report.Pairs {
+		kinds[k] = true
+	}
+	for _, k := range sortedKeys(kinds) {
+		fmt.Printf("%-18s %8d %14d\n", k, report.Pairs[k], report.ShadowErrors[k])
+	}
+
+	fmt.Printf("\n%-14s %10s %8s %9s\n", "field", "compared", "agreed", "rate")
+	for _, f := range report.Fields {
+		if f.Compared == 0 {
+			fmt.Printf("%-14s %10d %8s %9s\n", f.Field, 0, "-", "-")
+			continue
+		}
+		fmt.Printf("%-14s %10d %8d %8.1f%%\n", f.Field, f.Compared, f.Agreed, 100*f.Rate())
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	slices.Sort(out)
+	return out
+}
@@ -1,7 +1,7 @@
 // Command test-summary is a prompt-iteration tool for the rescue interpretation feature.
 // It reads a JSON file describing a rescue (dispatch transcript + ordered TAC transmissions),
 // calls the configured ML backend, and prints the structured RescueSummary back to stdout.
-// Use it to iterate on the summary prompt + King County gazetteer in internal/prompts without
+// Use it to iterate on the summary prompt + place list (internal/gazetteer) in internal/prompts without
 // having to round-trip through Pulsar / Slack / docker-compose.
 //
 // Backend selection mirrors the service (ML_BACKEND=openai|anthropic):
@@ -41,6 +41,7 @@ import (
 	"github.com/sashabaranov/go-openai"
 	anthropicClient "github.com/searchandrescuegg/transcribe/internal/anthropic"
 	"github.com/searchandrescuegg/transcribe/internal/calltypes"
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
 	"github.com/searchandrescuegg/transcribe/internal/ml"
 	openaiClient "github.com/searchandrescuegg/transcribe/internal/openai"
 )
@@ -117,6 +118,13 @@ func main() {
 		allowedCallTypes = loaded
 	}
 
+	// Optional: GAZETTEER_FILE, same as the service; unset renders the bundled place list.
+	places, err := gazetteer.Load(os.Getenv("GAZETTEER_FILE"))
+	if err != nil {
+		slog.Error("failed to load gazetteer", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	var client summarizerCleaner
 	switch backend := strings.ToLower(getenv("ML_BACKEND", "openai")); backend {
 	case "anthropic":
@@ -136,6 +144,7 @@ func main() {
 			SummaryModel:     model,
 			CleanupModel:     cleanupModel,
 			AllowedCallTypes: allowedCallTypes,
+			Places:           places,
 			Timeout:          *timeout,
 			MaxTokens:        2048,
 		})
@@ -149,7 +158,7 @@ func main() {
 		cfg := openai.DefaultConfig(os.Getenv("OPENAI_API_KEY"))
 		cfg.BaseURL = getenv("OPENAI_BASE_URL", "https://api.openai.com/v1")
 		cfg.HTTPClient = &http.Client{Timeout: *timeout}
-		client = openaiClient.NewOpenAIClient(openai.NewClientWithConfig(cfg), modelName, allowedCallTypes, false)
+		client = openaiClient.NewOpenAIClient(openai.NewClientWithConfig(cfg), modelName, allowedCallTypes, places, false)
 		slog.Info("using OpenAI backend", slog.String("model", modelName))
 	default:
 		slog.Error("unknown ML_BACKEND; expected \"openai\" or \"anthropic\"", slog.String("value", backend))
@@ -27,6 +27,7 @@ import (
 	"github.com/sashabaranov/go-openai"
 	anthropicClient "github.com/searchandrescuegg/transcribe/internal/anthropic"
 	"github.com/searchandrescuegg/transcribe/internal/calltypes"
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
 	"github.com/searchandrescuegg/transcribe/internal/ml"
 	openaiClient "github.com/searchandrescuegg/transcribe/internal/openai"
 )
@@ -46,7 +47,7 @@ func main() {
 	}
 
 	// Optional: same encrypted call-types file as the production binary, so local iteration
-	// exercises the same prompt + schema enum (and the baked-in King County gazetteer) as prod.
+	// exercises the same prompt + schema enum as prod.
 	var allowedCallTypes []string
 	if path := os.Getenv("CALL_TYPES_PATH"); path != "" {
 		loaded, err := calltypes.Load(path, os.Getenv("CALL_TYPES_KEY"))
@@ -57,6 +58,13 @@ func main() {
 		allowedCallTypes = loaded
 	}
 
+	// Optional: GAZETTEER_FILE, same as the service; unset renders the bundled place list.
+	places, err := gazetteer.Load(os.Getenv("GAZETTEER_FILE"))
+	if err != nil {
+		slog.Error("failed to load gazetteer", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	var parser ml.DispatchMessageParser
 	switch backend := strings.ToLower(getenv("ML_BACKEND", "openai")); backend {
 	case "anthropic":
@@ -72,6 +80,7 @@ func main() {
 			DispatchModel:    model,
 			SummaryModel:     model,
 			AllowedCallTypes: allowedCallTypes,
+			Places:           places,
 			Timeout:          30 * time.Second,
 			MaxTokens:        2048,
 		})
@@ -86,7 +95,7 @@ func main() {
 		cfg.BaseURL = getenv("OPENAI_BASE_URL", "https://api.openai.com/v1")
 		cfg.HTTPClient = &http.Client{Timeout: 30 * time.Second}
 		// Mirror prod's default — thinking off for fast, deterministic responses.
-		parser = openaiClient.NewOpenAIClient(openai.NewClientWithConfig(cfg), modelName, allowedCallTypes, false)
+		parser = openaiClient.NewOpenAIClient(openai.NewClientWithConfig(cfg), modelName, allowedCallTypes, places, false)
 		slog.Info("using OpenAI backend", slog.String("model", modelName))
 	default:
 		slog.Error("unknown ML_BACKEND; expected \"openai\" or \"anthropic\"", slog.String("value", backend))
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
@@ -20,17 +21,26 @@ import (
 	openai "github.com/sashabaranov/go-openai"
 	anthropicClient "github.com/searchandrescuegg/transcribe/internal/anthropic"
 	"github.com/searchandrescuegg/transcribe/internal/asr"
+	"github.com/searchandrescuegg/transcribe/internal/cad"
+	"github.com/searchandrescuegg/transcribe/internal/callsign"
 	"github.com/searchandrescuegg/transcribe/internal/calltypes"
 	"github.com/searchandrescuegg/transcribe/internal/config"
 	"github.com/searchandrescuegg/transcribe/internal/dataset"
 	"github.com/searchandrescuegg/transcribe/internal/dragonfly"
+	"github.com/searchandrescuegg/transcribe/internal/escalation"
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
 	"github.com/searchandrescuegg/transcribe/internal/logging"
+	"github.com/searchandrescuegg/transcribe/internal/mlcache"
+	"github.com/searchandrescuegg/transcribe/internal/mlensemble"
+	"github.com/searchandrescuegg/transcribe/internal/mlusage"
+	"github.com/searchandrescuegg/transcribe/internal/notify"
 	openaiClient "github.com/searchandrescuegg/transcribe/internal/openai"
 	"github.com/searchandrescuegg/transcribe/internal/pulsar"
 	"github.com/searchandrescuegg/transcribe/internal/pulsepoint"
 	"github.com/searchandrescuegg/transcribe/internal/s3"
 	"github.com/searchandrescuegg/transcribe/internal/slackctl"
 	"github.com/searchandrescuegg/transcribe/internal/transcribe"
+	"github.com/slack-go/slack"
 	"github.com/sourcegraph/conc/pool"
 	"go.opentelemetry.io/contrib/instrumentation/host"
 	"go.opentelemetry.io/contrib/instrumentation/runtime"
@@ -130,7 +140,7 @@ func main() {
 	}
 	defer pulsarClient.Close()
 
-	s3Client, err := s3.NewS3Client(c.S3AccessKey, c.S3SecretKey, c.S3Endpoint, c.S3Region, c.S3Bucket, c.S3Timeout)
+	s3Client, err := s3.NewS3Client(c.S3AccessKey, c.S3SecretKey, c.S3Endpoint, c.S3Region, c.S3Bucket, c.S3Timeout, c.S3MaxObjectSize)
 	if err != nil {
 		slog.Error("could not create s3 client", slog.String("error", err.Error()))
 		os.Exit(1)
@@ -156,56 +166,71 @@ func main() {
 		slog.Info("CALL_TYPES_PATH not set; running without call-type enum constraint")
 	}
 
+	// Place-name gazetteer: rendered into the system prompts, and used by the optional snap
+	// pre-pass and map links. GAZETTEER_FILE replaces the bundled King County list; a broken
+	// file fails startup rather than silently dropping the place reference.
+	places, err := gazetteer.Load(c.GazetteerFile)
+	if err != nil {
+		slog.Error("failed to load gazetteer", slog.String("error", err.Error()), slog.String("file", c.GazetteerFile))
+		os.Exit(1)
+	}
+	slog.Info("loaded gazetteer", slog.Int("places", len(places.Places())), slog.Bool("bundled", c.GazetteerFile == ""),
+		slog.Bool("snap", c.PlaceNameSnapEnabled))
+
 	// ML backend selection. Both the OpenAI-compatible path (also usable with Ollama / vLLM /
 	// LiteLLM via OPENAI_BASE_URL) and the first-party Anthropic path implement
-	// transcribe.MLClient (= DispatchMessageParser + RescueSummarizer); ML_BACKEND picks one
-	// at startup.
-	var mlClient transcribe.MLClient
-	switch strings.ToLower(c.MLBackend) {
-	case "anthropic":
-		slog.Info("initializing Anthropic ML backend",
-			slog.String("dispatch_model", c.AnthropicDispatchModel),
-			slog.String("summary_model", c.AnthropicSummaryModel))
-		if c.AnthropicAPIKey == "" {
-			slog.Error("ML_BACKEND=anthropic requires ANTHROPIC_API_KEY")
+	// transcribe.MLClient (= DispatchMessageParser + RescueSummarizer); ML_BACKEND picks the
+	// primary at startup and ML_FALLBACK_BACKEND optionally adds the other behind it.
+	mlBackend := strings.ToLower(c.MLBackend)
+	primaryML, err := newMLBackend(c, "ML_BACKEND", mlBackend, allowedCallTypes, places)
+	if err != nil {
+		slog.Error("could not initialize ML backend", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	fallbackBackend := strings.ToLower(c.MLFallbackBackend)
+	var fallbackML transcribe.MLClient
+	if fallbackBackend != "" {
+		if fallbackBackend == mlBackend {
+			slog.Error("ML_FALLBACK_BACKEND must differ from ML_BACKEND", slog.String("value", c.MLFallbackBackend))
 			os.Exit(1)
 		}
-		mlClient = anthropicClient.NewClient(anthropicClient.Options{
-			APIKey:           c.AnthropicAPIKey,
-			BaseURL:          c.AnthropicBaseURL,
-			DispatchModel:    c.AnthropicDispatchModel,
-			SummaryModel:     c.AnthropicSummaryModel,
-			CleanupModel:     c.AnthropicCleanupModel,
-			AllowedCallTypes: allowedCallTypes,
-			Timeout:          c.AnthropicTimeout,
-			MaxTokens:        c.AnthropicMaxTokens,
-		})
-	case "openai":
-		slog.Info("initializing OpenAI ML backend", slog.String("model", c.OpenAIModel), slog.String("base_url", c.OpenAIBaseURL))
-		if c.OpenAIAPIKey == "" {
-			slog.Warn("OpenAI API key not provided - this may be required depending on your endpoint configuration")
+		if c.MLPrimaryTimeout < 0 || c.MLPrimaryTimeout >= c.WorkerTimeout {
+			slog.Error("ML_PRIMARY_TIMEOUT must be below WORKER_TIMEOUT to leave the fallback time to run",
+				slog.Duration("value", c.MLPrimaryTimeout), slog.Duration("worker_timeout", c.WorkerTimeout))
+			os.Exit(1)
 		}
-		openaiConfig := openai.DefaultConfig(c.OpenAIAPIKey)
-		if c.OpenAIBaseURL != "https://api.openai.com/v1" {
-			openaiConfig.BaseURL = c.OpenAIBaseURL
+		fallbackML, err = newMLBackend(c, "ML_FALLBACK_BACKEND", fallbackBackend, allowedCallTypes, places)
+		if err != nil {
+			slog.Error("could not initialize fallback ML backend", slog.String("error", err.Error()))
+			os.Exit(1)
 		}
-		openaiConfig.HTTPClient = &http.Client{Timeout: c.OpenAITimeout}
-		mlClient = openaiClient.NewOpenAIClient(
-			openai.NewClientWithConfig(openaiConfig),
-			c.OpenAIModel,
-			allowedCallTypes,
-			c.OpenAIEnableThinking,
-		)
-	default:
-		slog.Error("unknown ML_BACKEND; expected \"openai\" or \"anthropic\"", slog.String("value", c.MLBackend))
+	} else if c.MLConsensusEnabled {
+		slog.Error("ML_CONSENSUS_ENABLED=true requires ML_FALLBACK_BACKEND")
 		os.Exit(1)
 	}
 
 	// Optional dataset capture: records raw transcriptions + LLM interactions to Postgres for
 	// offline prompt refinement. Best-effort (drops rather than blocks) and fully disabled
-	// unless DATASET_ENABLED=true. When enabled, the MLClient is wrapped in a recording
-	// decorator so every dispatch-parse / rescue-summary call is logged with its I/O.
+	// unless DATASET_ENABLED=true. When enabled, each backend is wrapped in a recording
+	// decorator so every dispatch-parse / rescue-summary call is logged with its I/O, under the
+	// backend and model that actually answered it.
 	var recorder dataset.Recorder
+	// record wraps a backend in dataset capture, under the models cfg gives it. A no-op until
+	// capture is enabled below.
+	record := func(client transcribe.MLClient, cfg *config.Config, backend string) transcribe.MLClient {
+		if recorder == nil {
+			return client
+		}
+		dispatchModel, summaryModel, cleanupModel := mlModels(cfg, backend)
+		return dataset.NewRecordingMLClient(client, recorder, dataset.DecoratorOptions{
+			Backend:          backend,
+			DispatchModel:    dispatchModel,
+			SummaryModel:     summaryModel,
+			CleanupModel:     cleanupModel,
+			AllowedCallTypes: allowedCallTypes,
+			Places:           places,
+		})
+	}
 	if c.DatasetEnabled {
 		if c.DatasetPostgresURL == "" {
 			slog.Error("DATASET_ENABLED=true requires DATASET_POSTGRES_URL")
@@ -221,18 +246,93 @@ func main() {
 		}()
 		recorder = store
 
-		dispatchModel, summaryModel, cleanupModel := c.OpenAIModel, c.OpenAIModel, c.OpenAIModel
-		if strings.ToLower(c.MLBackend) == "anthropic" {
-			dispatchModel, summaryModel, cleanupModel = c.AnthropicDispatchModel, c.AnthropicSummaryModel, c.AnthropicCleanupModel
+		primaryML = record(primaryML, c, mlBackend)
+		if fallbackML != nil {
+			fallbackML = record(fallbackML, c, fallbackBackend)
 		}
-		mlClient = dataset.NewRecordingMLClient(mlClient, store, dataset.DecoratorOptions{
-			Backend:          strings.ToLower(c.MLBackend),
-			DispatchModel:    dispatchModel,
-			SummaryModel:     summaryModel,
-			CleanupModel:     cleanupModel,
+		slog.Info("dataset capture enabled")
+	}
+
+	// Optional fallback / consensus: the primary backend's failures (and, with consensus, its
+	// dispatch classification) are checked against the second backend.
+	mlClient := primaryML
+	if fallbackML != nil {
+		var auditor mlensemble.Auditor
+		if c.MLConsensusEnabled && c.MLConsensusAuditChannelID != "" {
+			auditor = mlensemble.NewSlackAuditor(slack.New(c.SlackToken), c.MLConsensusAuditChannelID, c.SlackTimeout)
+		}
+		mlClient, err = mlensemble.NewFallbackMLClient(
+			mlensemble.Backend{Name: mlBackend, Client: primaryML},
+			mlensemble.Backend{Name: fallbackBackend, Client: fallbackML},
+			mlensemble.Options{
+				Capabilities:   c.MLFallbackCapabilities,
+				PrimaryTimeout: c.MLPrimaryTimeout,
+				Consensus:      c.MLConsensusEnabled,
+				IsTrailRescue:  transcribe.CallIsTrailRescue,
+				Auditor:        auditor,
+			})
+		if err != nil {
+			slog.Error("could not initialize ML fallback", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		slog.Info("ML fallback enabled",
+			slog.String("primary", mlBackend),
+			slog.String("fallback", fallbackBackend),
+			slog.Any("capabilities", c.MLFallbackCapabilities),
+			slog.Duration("primary_timeout", c.MLPrimaryTimeout),
+			slog.Bool("consensus", c.MLConsensusEnabled),
+			slog.String("audit_channel", c.MLConsensusAuditChannelID))
+	}
+
+	// Optional shadow evaluation of a candidate model. It wraps everything that answers live
+	// calls, so the recorded live side is whichever backend actually answered; it sits inside the
+	// LLM cache, so cache hits aren't shadowed.
+	if c.MLShadowEnabled {
+		if !c.DatasetEnabled {
+			slog.Error("ML_SHADOW_ENABLED=true requires DATASET_ENABLED=true")
+			os.Exit(1)
+		}
+		if c.MLShadowModel == "" {
+			slog.Error("ML_SHADOW_ENABLED=true requires ML_SHADOW_MODEL")
+			os.Exit(1)
+		}
+		shadowBackend := strings.ToLower(c.MLShadowBackend)
+		if shadowBackend == "" {
+			shadowBackend = mlBackend
+		}
+		// The candidate is the configured backend with every model swapped for ML_SHADOW_MODEL.
+		shadowCfg := *c
+		shadowCfg.OpenAIModel = c.MLShadowModel
+		shadowCfg.AnthropicDispatchModel = c.MLShadowModel
+		shadowCfg.AnthropicSummaryModel = c.MLShadowModel
+		shadowCfg.AnthropicCleanupModel = c.MLShadowModel
+		candidate, err := newMLBackend(&shadowCfg, "ML_SHADOW_BACKEND", shadowBackend, allowedCallTypes, places)
+		if err != nil {
+			slog.Error("could not initialize shadow ML backend", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		mlClient, err = dataset.NewShadowMLClient(mlClient, candidate, recorder, dataset.DecoratorOptions{
+			Backend:          shadowBackend,
+			DispatchModel:    c.MLShadowModel,
+			SummaryModel:     c.MLShadowModel,
+			CleanupModel:     c.MLShadowModel,
 			AllowedCallTypes: allowedCallTypes,
+			Places:           places,
+		}, dataset.ShadowOptions{
+			Kinds:       c.MLShadowKinds,
+			SampleRate:  c.MLShadowSampleRate,
+			Timeout:     c.MLShadowTimeout,
+			MaxInFlight: c.MLShadowMaxInFlight,
 		})
-		slog.Info("dataset capture enabled")
+		if err != nil {
+			slog.Error("could not initialize shadow evaluation", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		slog.Info("shadow evaluation enabled",
+			slog.String("backend", shadowBackend),
+			slog.String("model", c.MLShadowModel),
+			slog.Float64("sample_rate", c.MLShadowSampleRate),
+			slog.Any("kinds", c.MLShadowKinds))
 	}
 
 	dragonflyClient, err := dragonfly.NewClient(ctx, c.DragonflyRequestTimeout, &redis.Options{
@@ -248,27 +348,372 @@ func main() {
 		_ = dragonflyClient.Close()
 	}()
 
-	// Optional CAD (PulsePoint) unit enrichment: resolves the units assigned to the active
-	// rescue so garbled unit callsigns can be canonicalized in cleanup + summaries. Best-effort
-	// and fully disabled unless PULPO_ENABLED=true. A nil resolver means "no enrichment".
+	// Optional token accounting and daily budget. It wraps everything that reaches a model, the
+	// shadow candidate included (its tokens are billed like any other), and sits inside the LLM
+	// cache, so a cache hit costs nothing.
+	if c.LLMUsageTrackingEnabled {
+		if c.LLMUsageRetention <= 0 {
+			slog.Error("LLM_USAGE_RETENTION must be positive", slog.Duration("value", c.LLMUsageRetention))
+			os.Exit(1)
+		}
+		prices, err := mlusage.ParsePrices(c.LLMPrices)
+		if err != nil {
+			slog.Error("invalid LLM_PRICES", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		opts := mlusage.Options{
+			IncidentRetention: c.LLMUsageRetention,
+			Prices:            prices,
+			DailyBudget:       c.LLMDailyTokenBudget,
+			BudgetAction:      strings.ToLower(c.LLMBudgetAction),
+			BudgetInterval:    c.LLMBudgetSummaryInterval,
+		}
+		if c.LLMDailyTokenBudget < 0 {
+			slog.Error("LLM_DAILY_TOKEN_BUDGET must not be negative", slog.Int64("value", c.LLMDailyTokenBudget))
+			os.Exit(1)
+		}
+		if c.LLMDailyTokenBudget > 0 && opts.BudgetAction == mlusage.ActionDowngrade {
+			if c.LLMBudgetSummaryModel == "" {
+				slog.Error("LLM_BUDGET_ACTION=downgrade requires LLM_BUDGET_SUMMARY_MODEL")
+				os.Exit(1)
+			}
+			// The downgrade is the primary backend with its summary model swapped.
+			budgetCfg := *c
+			budgetCfg.OpenAIModel = c.LLMBudgetSummaryModel
+			budgetCfg.AnthropicSummaryModel = c.LLMBudgetSummaryModel
+			budgetML, err := newMLBackend(&budgetCfg, "ML_BACKEND", mlBackend, allowedCallTypes, places)
+			if err != nil {
+				slog.Error("could not initialize budget summary backend", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+			opts.BudgetSummarizer = record(budgetML, &budgetCfg, mlBackend)
+		}
+		mlClient, err = mlusage.NewAccountingMLClient(mlClient, dragonflyClient, opts)
+		if err != nil {
+			slog.Error("could not initialize LLM usage tracking", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		slog.Info("LLM usage tracking enabled",
+			slog.Duration("retention", c.LLMUsageRetention),
+			slog.Int("priced_models", len(prices)),
+			slog.Int64("daily_token_budget", c.LLMDailyTokenBudget),
+			slog.String("budget_action", opts.BudgetAction))
+	} else if c.LLMDailyTokenBudget > 0 {
+		slog.Error("LLM_DAILY_TOKEN_BUDGET requires LLM_USAGE_TRACKING_ENABLED=true")
+		os.Exit(1)
+	}
+
+	// Optional LLM response cache. It wraps the recording decorator, so dataset capture sees only
+	// calls that actually reached the model. Entries are keyed under the primary backend, even
+	// when the fallback produced them.
+	if c.LLMCacheEnabled {
+		dispatchModel, summaryModel, cleanupModel := mlModels(c, mlBackend)
+		if c.LLMCacheTTL <= 0 {
+			slog.Error("LLM_CACHE_TTL must be positive", slog.Duration("value", c.LLMCacheTTL))
+			os.Exit(1)
+		}
+		mlClient, err = mlcache.NewCachingMLClient(mlClient, dragonflyClient, mlcache.Options{
+			Backend:          mlBackend,
+			DispatchModel:    dispatchModel,
+			SummaryModel:     summaryModel,
+			CleanupModel:     cleanupModel,
+			AllowedCallTypes: allowedCallTypes,
+			Places:           places,
+			TTL:              c.LLMCacheTTL,
+		})
+		if err != nil {
+			slog.Error("could not initialize LLM cache", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		slog.Info("LLM response cache enabled", slog.Duration("ttl", c.LLMCacheTTL))
+	}
+
+	// Optional split detection for re-pages onto an active TAC. The threshold is a fraction, so
+	// reject anything outside 0–1 rather than silently never (or always) prompting.
+	if c.SplitDetectionEnabled {
+		if c.SplitDetectionMinOverlap < 0 || c.SplitDetectionMinOverlap > 1 {
+			slog.Error("SPLIT_DETECTION_MIN_OVERLAP must be between 0 and 1", slog.Float64("value", c.SplitDetectionMinOverlap))
+			os.Exit(1)
+		}
+		if c.SlackAppToken == "" {
+			slog.Warn("SPLIT_DETECTION_ENABLED without SLACK_APP_TOKEN: split prompts will post but their button can't be pressed")
+		}
+		slog.Info("split detection enabled", slog.Float64("min_overlap", c.SplitDetectionMinOverlap), slog.Bool("llm", c.SplitDetectionLLMEnabled))
+	}
+
+	// Optional inactivity closure policy. Negative durations are always a typo; a lifetime
+	// shorter than the activation window cuts every rescue short of its first window and leaves
+	// Extend nothing to add, which is almost certainly not what was meant, so flag it.
+	if c.TACIdleTimeout < 0 || c.TACMaxLifetime < 0 || c.TACClosingWarning < 0 {
+		slog.Error("TAC_IDLE_TIMEOUT, TAC_MAX_LIFETIME and TAC_CLOSING_WARNING must not be negative")
+		os.Exit(1)
+	}
+	if c.TACMaxLifetime > 0 && c.TACMaxLifetime < c.TacticalChannelActivationDuration {
+		slog.Warn("TAC_MAX_LIFETIME is shorter than TACTICAL_CHANNEL_ACTIVATION_DURATION; every rescue closes at the lifetime and Extend adds no time",
+			slog.Duration("max_lifetime", c.TACMaxLifetime), slog.Duration("activation", c.TacticalChannelActivationDuration))
+	}
+	if c.TACClosingWarning > 0 && c.TACClosingWarning >= c.TacticalChannelActivationDuration {
+		slog.Error("TAC_CLOSING_WARNING must be shorter than TACTICAL_CHANNEL_ACTIVATION_DURATION",
+			slog.Duration("warning", c.TACClosingWarning), slog.Duration("activation", c.TacticalChannelActivationDuration))
+		os.Exit(1)
+	}
+	if c.TACIdleTimeout > 0 || c.TACMaxLifetime > 0 || c.TACClosingWarning > 0 {
+		slog.Info("TAC closure policy",
+			slog.Duration("idle_timeout", c.TACIdleTimeout),
+			slog.Duration("max_lifetime", c.TACMaxLifetime),
+			slog.Duration("closing_warning", c.TACClosingWarning))
+	}
+
+	// Optional close prompt when the live summary reports a terminal outcome.
+	if c.OutcomeCloseEnabled {
+		if c.OutcomeCloseGrace <= 0 {
+			slog.Error("OUTCOME_CLOSE_GRACE must be positive when OUTCOME_CLOSE_ENABLED=true", slog.Duration("value", c.OutcomeCloseGrace))
+			os.Exit(1)
+		}
+		if c.SlackAppToken == "" {
+			slog.Warn("OUTCOME_CLOSE_ENABLED without SLACK_APP_TOKEN: close prompts will post but their buttons can't be pressed, so every prompt auto-closes after the grace period")
+		}
+		slog.Info("outcome close prompts enabled", slog.Duration("grace", c.OutcomeCloseGrace))
+	}
+
+	// Optional streamed live interpretation.
+	if c.LiveInterpretationStreamingEnabled {
+		if c.LiveInterpretationStreamInterval <= 0 {
+			slog.Error("LIVE_INTERPRETATION_STREAM_INTERVAL must be positive when LIVE_INTERPRETATION_STREAMING_ENABLED=true", slog.Duration("value", c.LiveInterpretationStreamInterval))
+			os.Exit(1)
+		}
+		slog.Info("live interpretation streaming enabled", slog.Duration("interval", c.LiveInterpretationStreamInterval))
+	}
+
+	// Optional transcript compaction. Both limits at 0 would never compact, so reject it rather
+	// than run with a setting that silently does nothing.
+	if c.LiveInterpretationCompactionEnabled {
+		if c.LiveInterpretationCompactMaxTransmissions < 0 || c.LiveInterpretationCompactMaxTokens < 0 ||
+			(c.LiveInterpretationCompactMaxTransmissions == 0 && c.LiveInterpretationCompactMaxTokens == 0) {
+			slog.Error("LIVE_INTERPRETATION_COMPACTION_ENABLED=true requires a positive LIVE_INTERPRETATION_COMPACT_MAX_TRANSMISSIONS or LIVE_INTERPRETATION_COMPACT_MAX_TOKENS (and neither negative)",
+				slog.Int("max_transmissions", c.LiveInterpretationCompactMaxTransmissions),
+				slog.Int("max_tokens", c.LiveInterpretationCompactMaxTokens))
+			os.Exit(1)
+		}
+		slog.Info("live interpretation compaction enabled",
+			slog.Int("max_transmissions", c.LiveInterpretationCompactMaxTransmissions),
+			slog.Int("max_tokens", c.LiveInterpretationCompactMaxTokens))
+	}
+
+	if c.AfterActionReportEnabled {
+		if c.AfterActionReportTimeout <= 0 {
+			slog.Error("AFTER_ACTION_REPORT_TIMEOUT must be positive when AFTER_ACTION_REPORT_ENABLED=true", slog.Duration("value", c.AfterActionReportTimeout))
+			os.Exit(1)
+		}
+		slog.Info("after-action reports enabled",
+			slog.Duration("timeout", c.AfterActionReportTimeout),
+			slog.String("s3_prefix", c.AfterActionReportS3Prefix))
+	}
+
+	if c.DigestEnabled {
+		if err := transcribe.ValidateDigestTimes(c.DigestTimes); err != nil {
+			slog.Error("invalid DIGEST_TIMES", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		// The digest reads records closed since the previous digest time, so they must live at
+		// least a day (the longest possible gap) to be there when it runs.
+		if c.DigestRetention < 24*time.Hour {
+			slog.Error("DIGEST_RETENTION must be at least 24h", slog.Duration("value", c.DigestRetention))
+			os.Exit(1)
+		}
+		digestChannel := c.DigestChannelID
+		if digestChannel == "" {
+			digestChannel = c.SlackChannelID
+		}
+		slog.Info("shift digest enabled",
+			slog.Any("times", c.DigestTimes),
+			slog.String("channel", digestChannel),
+			slog.Duration("retention", c.DigestRetention))
+	}
+
+	// Optional CAD unit enrichment: resolves the units assigned to the active rescue so garbled
+	// unit callsigns can be canonicalized in cleanup + summaries. Best-effort and fully disabled
+	// unless PULPO_ENABLED=true; CAD_PROVIDER picks the feed. A nil resolver means "no
+	// enrichment".
 	var unitResolver transcribe.UnitResolver
 	if c.PulpoEnabled {
-		if c.PulpoBaseURL == "" || c.PulpoAPIKey == "" || c.PulpoAgencyID == "" {
-			slog.Error("PULPO_ENABLED=true requires PULPO_BASE_URL, PULPO_API_KEY, and PULPO_AGENCY_ID")
+		var provider cad.Provider
+		switch c.CADProvider {
+		case "pulsepoint":
+			if c.PulpoBaseURL == "" || c.PulpoAPIKey == "" || c.PulpoAgencyID == "" {
+				slog.Error("CAD_PROVIDER=pulsepoint requires PULPO_BASE_URL, PULPO_API_KEY, and PULPO_AGENCY_ID")
+				os.Exit(1)
+			}
+			provider = pulsepoint.NewProvider(pulsepoint.Options{
+				BaseURL:  c.PulpoBaseURL,
+				APIKey:   c.PulpoAPIKey,
+				AgencyID: c.PulpoAgencyID,
+				Username: c.PulpoUsername,
+				Password: c.PulpoPassword,
+				Timeout:  c.PulpoTimeout,
+			})
+		case "http":
+			if c.CADHTTPURL == "" || c.CADHTTPMappingFile == "" {
+				slog.Error("CAD_PROVIDER=http requires CAD_HTTP_URL and CAD_HTTP_MAPPING_FILE")
+				os.Exit(1)
+			}
+			mapping, err := cad.LoadMapping(c.CADHTTPMappingFile)
+			if err != nil {
+				slog.Error("invalid CAD_HTTP_MAPPING_FILE", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+			provider = cad.NewHTTPProvider(c.CADHTTPURL, c.CADHTTPHeaders, mapping, &http.Client{Timeout: c.PulpoTimeout})
+		case "static":
+			p, err := cad.NewStaticProvider(c.CADStaticFile)
+			if err != nil {
+				slog.Error("CAD_PROVIDER=static requires a readable CAD_STATIC_FILE", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+			provider = p
+		default:
+			slog.Error("invalid CAD_PROVIDER; must be pulsepoint, http, or static", slog.String("value", c.CADProvider))
 			os.Exit(1)
 		}
-		unitResolver = pulsepoint.NewResolver(pulsepoint.Options{
-			BaseURL:  c.PulpoBaseURL,
-			APIKey:   c.PulpoAPIKey,
-			AgencyID: c.PulpoAgencyID,
-			Username: c.PulpoUsername,
-			Password: c.PulpoPassword,
-			Timeout:  c.PulpoTimeout,
-		})
-		slog.Info("PulsePoint unit enrichment enabled", slog.String("agency_id", c.PulpoAgencyID))
+		unitResolver = cad.NewResolver(provider, c.PulpoTimeout)
+		slog.Info("CAD unit enrichment enabled", slog.String("provider", c.CADProvider))
+	}
+
+	if c.CADUnitTimelineEnabled {
+		if !c.PulpoEnabled {
+			slog.Error("CAD_UNIT_TIMELINE_ENABLED=true requires PULPO_ENABLED=true")
+			os.Exit(1)
+		}
+		slog.Info("CAD unit timeline enabled", slog.Duration("refresh", c.PulpoRefreshInterval))
+	}
+
+	if c.CADMatchDetailsEnabled {
+		if !c.PulpoEnabled {
+			slog.Error("CAD_MATCH_DETAILS_ENABLED=true requires PULPO_ENABLED=true")
+			os.Exit(1)
+		}
+		slog.Info("CAD match details enabled", slog.Bool("pin", c.SlackAppToken != ""))
+	}
+
+	// Optional CAD poller that alerts on rescues the radio path missed. It reads the same feed
+	// as enrichment, so it needs PULPO_ENABLED.
+	if c.CADPollEnabled {
+		if !c.PulpoEnabled {
+			slog.Error("CAD_POLL_ENABLED=true requires PULPO_ENABLED=true")
+			os.Exit(1)
+		}
+		if c.CADPollInterval <= 0 || c.CADPollGrace < 0 {
+			slog.Error("CAD_POLL_INTERVAL must be positive and CAD_POLL_GRACE non-negative",
+				slog.Duration("interval", c.CADPollInterval), slog.Duration("grace", c.CADPollGrace))
+			os.Exit(1)
+		}
+		slog.Info("CAD poller enabled",
+			slog.Duration("interval", c.CADPollInterval),
+			slog.Duration("grace", c.CADPollGrace),
+			slog.Any("call_types", c.CADPollCallTypes))
+	}
+
+	// Optional deterministic callsign pass ahead of the TAC cleanup. The unit-type table is
+	// validated here so a typo fails the rollout rather than silently normalizing nothing.
+	var callsigns *callsign.Normalizer
+	if c.CallsignNormalizerEnabled {
+		unitTypes, err := callsign.ParseUnitTypes(c.CallsignUnitTypes)
+		if err != nil {
+			slog.Error("invalid CALLSIGN_UNIT_TYPES", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		callsigns = callsign.NewNormalizer(unitTypes)
+		slog.Info("callsign normalizer enabled", slog.Int("unit_types", len(unitTypes)), slog.Bool("cad_roster", unitResolver != nil))
+	}
+
+	// Optional original-audio attachment on TAC thread replies. Validate up front so a typo
+	// fails the rollout instead of silently posting text-only replies.
+	switch c.SlackAudioMode {
+	case "":
+	case transcribe.AudioModeUpload:
+		if c.SlackAudioUploadFormat != transcribe.AudioFormatWAV && c.SlackAudioUploadFormat != transcribe.AudioFormatWAVDownsampled {
+			slog.Error("invalid SLACK_AUDIO_UPLOAD_FORMAT (want wav or wav-downsampled)", slog.String("format", c.SlackAudioUploadFormat))
+			os.Exit(1)
+		}
+		slog.Info("thread audio enabled: uploading clips to Slack", slog.String("format", c.SlackAudioUploadFormat))
+	case transcribe.AudioModePresign:
+		slog.Info("thread audio enabled: presigned S3 links", slog.Duration("ttl", c.SlackAudioPresignTTL))
+	default:
+		slog.Error("invalid SLACK_AUDIO_MODE (want upload or presign)", slog.String("mode", c.SlackAudioMode))
+		os.Exit(1)
+	}
+
+	// Optional secondary notification sinks (Teams, signed webhooks, partner Slack). Parsed
+	// up front so a malformed NOTIFY_SINKS fails the rollout rather than silently dropping
+	// partner notifications. The transcribe client puts the primary Slack sink in front of
+	// them, and the Slack controller uses them alone to announce cancellations. A nil notifier
+	// leaves Slack the only sink.
+	var notifier *notify.FanOut
+	sinkSpecs, err := notify.ParseSinks(c.NotifySinks)
+	if err != nil {
+		slog.Error("invalid NOTIFY_SINKS", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if len(sinkSpecs) > 0 {
+		fanOut, err := notify.Build(sinkSpecs, c.NotifyTimeout, &http.Client{Timeout: c.NotifyTimeout})
+		if err != nil {
+			slog.Error("failed to build notification sinks", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		notifier = fanOut
+		slog.Info("notification sinks enabled", slog.Int("sinks", fanOut.Len()))
+	}
+
+	// Optional SMS / email escalation pages. Validated up front for the same reason as the
+	// sinks: a typo should fail the rollout, not silently leave leadership un-paged.
+	var escalator *escalation.Escalator
+	escalationRules, err := escalation.ParseRules(c.EscalationRules)
+	if err != nil {
+		slog.Error("invalid ESCALATION_RULES", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if len(escalationRules) > 0 {
+		if len(c.EscalationTo) == 0 {
+			slog.Error("ESCALATION_RULES requires ESCALATION_TO")
+			os.Exit(1)
+		}
+		var transport escalation.Transport
+		switch c.EscalationTransport {
+		case "twilio":
+			if c.EscalationTwilioAccountSID == "" || c.EscalationTwilioAuthToken == "" || c.EscalationTwilioFrom == "" {
+				slog.Error("ESCALATION_TRANSPORT=twilio requires ESCALATION_TWILIO_ACCOUNT_SID, ESCALATION_TWILIO_AUTH_TOKEN, and ESCALATION_TWILIO_FROM")
+				os.Exit(1)
+			}
+			transport = escalation.NewTwilio(c.EscalationTwilioBaseURL, c.EscalationTwilioAccountSID, c.EscalationTwilioAuthToken,
+				c.EscalationTwilioFrom, c.EscalationTo, &http.Client{Timeout: c.EscalationTimeout})
+		case "smtp":
+			if c.EscalationSMTPAddr == "" || c.EscalationSMTPFrom == "" {
+				slog.Error("ESCALATION_TRANSPORT=smtp requires ESCALATION_SMTP_ADDR and ESCALATION_SMTP_FROM")
+				os.Exit(1)
+			}
+			transport = escalation.NewSMTP(c.EscalationSMTPAddr, c.EscalationSMTPUsername, c.EscalationSMTPPassword,
+				c.EscalationSMTPFrom, c.EscalationTo)
+		default:
+			slog.Error("invalid ESCALATION_TRANSPORT (want twilio or smtp)", slog.String("transport", c.EscalationTransport))
+			os.Exit(1)
+		}
+		escalator = escalation.New(escalationRules, transport)
+		slog.Info("escalation enabled",
+			slog.Int("rules", len(escalationRules)),
+			slog.String("transport", c.EscalationTransport),
+			slog.Int("recipients", len(c.EscalationTo)))
 	}
 
-	transcribeClient := transcribe.NewTranscribeClient(c, pulsarClient, s3Client, asrClient, mlClient, dragonflyClient, recorder, unitResolver)
+	// Optional map links on the alert and live interpretation, resolved against the gazetteer
+	// loaded above.
+	if c.MapLinksEnabled {
+		if !gazetteer.ValidProvider(c.MapLinkProvider) {
+			slog.Error("invalid MAP_LINK_PROVIDER (want caltopo or osm)", slog.String("provider", c.MapLinkProvider))
+			os.Exit(1)
+		}
+		slog.Info("map links enabled", slog.String("provider", c.MapLinkProvider))
+	}
+
+	transcribeClient := transcribe.NewTranscribeClient(c, pulsarClient, s3Client, asrClient, mlClient, dragonflyClient, recorder, unitResolver, callsigns, notifier, escalator, places)
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -293,10 +738,29 @@ func main() {
 		transcribeClient.Sweep(processingCtx)
 	})
 
+	// Durable outbound Slack queue sender. No-op unless SLACK_QUEUE_ENABLED=true, in which
+	// case thread replies and chat.updates are drained from a Dragonfly stream with per-channel
+	// pacing and backoff instead of being sent inline by the workers.
+	workerPool.Go(func() {
+		transcribeClient.RunSlackQueue(processingCtx)
+	})
+
+	// Shift digest poster. No-op unless DIGEST_ENABLED=true; every replica runs it and a
+	// Dragonfly claim picks the one that posts each digest.
+	workerPool.Go(func() {
+		transcribeClient.RunDigest(processingCtx)
+	})
+
+	// CAD poller. No-op unless CAD_POLL_ENABLED=true; every replica runs it and a Dragonfly
+	// claim per CAD incident picks the one that posts.
+	workerPool.Go(func() {
+		transcribeClient.RunCADPoller(processingCtx)
+	})
+
 	// Slack interactivity controller (Cancel / Extend buttons). Optional: when SLACK_APP_TOKEN
 	// is unset the feature is silently disabled. When set, the controller opens an outbound
 	// Socket Mode WebSocket to Slack — no public HTTP endpoint required.
-	slackController, err := slackctl.New(c, dragonflyClient)
+	slackController, err := slackctl.New(c, dragonflyClient, notifier)
 	switch {
 	case errors.Is(err, slackctl.ErrSocketModeDisabled):
 		slog.Info("Slack interactivity disabled (SLACK_APP_TOKEN not set)")
@@ -317,3 +781,55 @@ func main() {
 	workerPool.Wait()
 	slog.Info("all workers stopped")
 }
+
+// newMLBackend builds the named backend ("anthropic" or "openai"). envName is the variable that
+// selected it, for the error messages.
+func newMLBackend(c *config.Config, envName, backend string, allowedCallTypes []string, places *gazetteer.Gazetteer) (transcribe.MLClient, error) {
+	switch backend {
+	case "anthropic":
+		slog.Info("initializing Anthropic ML backend",
+			slog.String("dispatch_model", c.AnthropicDispatchModel),
+			slog.String("summary_model", c.AnthropicSummaryModel))
+		if c.AnthropicAPIKey == "" {
+			return nil, fmt.Errorf("%s=anthropic requires ANTHROPIC_API_KEY", envName)
+		}
+		return anthropicClient.NewClient(anthropicClient.Options{
+			APIKey:           c.AnthropicAPIKey,
+			BaseURL:          c.AnthropicBaseURL,
+			DispatchModel:    c.AnthropicDispatchModel,
+			SummaryModel:     c.AnthropicSummaryModel,
+			CleanupModel:     c.AnthropicCleanupModel,
+			AllowedCallTypes: allowedCallTypes,
+			Places:           places,
+			Timeout:          c.AnthropicTimeout,
+			MaxTokens:        c.AnthropicMaxTokens,
+		}), nil
+	case "openai":
+		slog.Info("initializing OpenAI ML backend", slog.String("model", c.OpenAIModel), slog.String("base_url", c.OpenAIBaseURL))
+		if c.OpenAIAPIKey == "" {
+			slog.Warn("OpenAI API key not provided - this may be required depending on your endpoint configuration")
+		}
+		openaiConfig := openai.DefaultConfig(c.OpenAIAPIKey)
+		if c.OpenAIBaseURL != "https://api.openai.com/v1" {
+			openaiConfig.BaseURL = c.OpenAIBaseURL
+		}
+		openaiConfig.HTTPClient = &http.Client{Timeout: c.OpenAITimeout}
+		return openaiClient.NewOpenAIClient(
+			openai.NewClientWithConfig(openaiConfig),
+			c.OpenAIModel,
+			allowedCallTypes,
+			places,
+			c.OpenAIEnableThinking,
+		), nil
+	}
+	return nil, fmt.Errorf("unknown %s %q; expected \"openai\" or \"anthropic\"", envName, backend)
+}
+
+// mlModels returns the models behind the dispatch, summary and cleanup calls of backend, for the
+// decorators that record or key on them.
+func mlModels(c *config.Config, backend string) (dispatch, summary, cleanup string) {
+	if backend == "anthropic" {
+		return c.AnthropicDispatchModel, c.AnthropicSummaryModel, c.AnthropicCleanupModel
+	}
+	return c.OpenAIModel, c.OpenAIModel, c.OpenAIModel
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/sashabaranov/go-openai/jsonschema"
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
 	"github.com/searchandrescuegg/transcribe/internal/ml"
 	"github.com/searchandrescuegg/transcribe/internal/prompts"
 )
@@ -34,9 +35,13 @@ type Client struct {
 	// call_type to an enum of these values plus "Unknown"; empty means no enum constraint.
 	allowedCallTypes []string
 
-	// maxTokens caps the structured-output response. The dispatch and summary payloads are
-	// small (a cleaned transcription, or a headline + a handful of key events), so a modest
-	// ceiling is plenty of headroom while keeping the request non-streaming.
+	// places is rendered into the dispatch, summary and cleanup system prompts as the
+	// place-name reference. Nil uses the bundled gazetteer.
+	places *gazetteer.Gazetteer
+
+	// maxTokens caps the structured-output response, for the unary calls and the streamed live
+	// summary alike. The dispatch and summary payloads are small (a cleaned transcription, or a
+	// headline + a handful of key events), so a modest ceiling is plenty of headroom.
 	maxTokens int64
 }
 
@@ -48,6 +53,7 @@ type Options struct {
 	SummaryModel     string // e.g. "claude-sonnet-5"
 	CleanupModel     string // e.g. "claude-haiku-4-5"; empty falls back to DispatchModel
 	AllowedCallTypes []string
+	Places           *gazetteer.Gazetteer // nil uses the bundled gazetteer
 	// Timeout bounds each request. Keep it <= WorkerTimeout so the worker context doesn't
 	// cancel an in-flight call before it can answer (see CLAUDE.md invariant #7).
 	Timeout   time.Duration
@@ -80,6 +86,7 @@ func NewClient(opts Options) *Client {
 		summaryModel:     anthropic.Model(opts.SummaryModel),
 		cleanupModel:     anthropic.Model(cleanupModel),
 		allowedCallTypes: opts.AllowedCallTypes,
+		places:           opts.Places,
 		maxTokens:        maxTokens,
 	}
 }
@@ -100,7 +107,7 @@ func (c *Client) ParseRelevantInformationFromDispatchMessage(ctx context.Context
 		return nil, fmt.Errorf("failed to convert schema: %w", err)
 	}
 
-	raw, err := c.complete(ctx, c.dispatchModel, prompts.DispatchSystemPrompt(c.allowedCallTypes), transcription, schema)
+	raw, err := c.complete(ctx, c.dispatchModel, prompts.DispatchSystemPrompt(c.allowedCallTypes, c.places), transcription, schema)
 	if err != nil {
 		return nil, err
 	}
@@ -128,11 +135,38 @@ func (c *Client) SummarizeRescue(ctx context.Context, input ml.RescueSummaryInpu
 		return nil, fmt.Errorf("failed to convert schema: %w", err)
 	}
 
-	raw, err := c.complete(ctx, c.summaryModel, prompts.RescueSummarySystemPrompt, prompts.BuildRescueSummaryUserPrompt(input), schema)
+	raw, err := c.complete(ctx, c.summaryModel, prompts.RescueSummarySystemPrompt(c.places), prompts.BuildRescueSummaryUserPrompt(input), schema)
+	if err != nil {
+		return nil, fmt.Errorf("rescue summary: %w", err)
+	}
+	return decodeRescueSummary(raw)
+}
+
+// SummarizeRescueStream is SummarizeRescue over a streaming request, reporting the summary to
+// onPartial as its fields finish. Same prompt, schema and model, so the final answer matches.
+func (c *Client) SummarizeRescueStream(ctx context.Context, input ml.RescueSummaryInput, onPartial func(*ml.RescueSummary)) (*ml.RescueSummary, error) {
+	if input.DispatchTranscription == "" && len(input.TACTranscripts) == 0 {
+		return nil, fmt.Errorf("no transcripts to summarize")
+	}
+
+	def, err := prompts.RescueSummarySchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate summary schema: %w", err)
+	}
+	schema, err := schemaToMap(def)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert schema: %w", err)
+	}
+
+	partials := ml.NewSummaryStream(onPartial)
+	raw, err := c.completeStream(ctx, c.summaryModel, prompts.RescueSummarySystemPrompt(c.places), prompts.BuildRescueSummaryUserPrompt(input), schema, partials.Update)
 	if err != nil {
 		return nil, fmt.Errorf("rescue summary: %w", err)
 	}
+	return decodeRescueSummary(raw)
+}
 
+func decodeRescueSummary(raw string) (*ml.RescueSummary, error) {
 	var summary ml.RescueSummary
 	if err := json.Unmarshal([]byte(raw), &summary); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal rescue summary: %w, content: %s", err, raw)
@@ -157,7 +191,7 @@ func (c *Client) CleanTACTranscript(ctx context.Context, in ml.TACCleanupInput)
 		return nil, fmt.Errorf("failed to convert schema: %w", err)
 	}
 
-	raw, err := c.complete(ctx, c.cleanupModel, prompts.TACCleanupSystemPrompt, prompts.BuildTACCleanupUserPrompt(in), schema)
+	raw, err := c.complete(ctx, c.cleanupModel, prompts.TACCleanupSystemPrompt(c.places), prompts.BuildTACCleanupUserPrompt(in), schema)
 	if err != nil {
 		return nil, fmt.Errorf("tac cleanup: %w", err)
 	}
@@ -169,6 +203,63 @@ func (c *Client) CleanTACTranscript(ctx context.Context, in ml.TACCleanupInput)
 	return &result, nil
 }
 
+// CompareDispatches asks whether a new dispatch onto an active TAC is the same incident as the
+// one that started the rescue. Runs on the dispatch model: it is a short classification over
+// two dispatch transcriptions, the same kind of input that model already handles.
+func (c *Client) CompareDispatches(ctx context.Context, in ml.DispatchComparisonInput) (*ml.DispatchComparison, error) {
+	if in.ActiveDispatch == "" || in.NewDispatch == "" {
+		return nil, fmt.Errorf("both dispatch transcriptions are required")
+	}
+
+	def, err := prompts.DispatchComparisonSchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate comparison schema: %w", err)
+	}
+	schema, err := schemaToMap(def)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert schema: %w", err)
+	}
+
+	raw, err := c.complete(ctx, c.dispatchModel, prompts.DispatchComparisonSystemPrompt, prompts.BuildDispatchComparisonUserPrompt(in), schema)
+	if err != nil {
+		return nil, fmt.Errorf("dispatch comparison: %w", err)
+	}
+
+	var result ml.DispatchComparison
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal comparison result: %w, content: %s", err, raw)
+	}
+	return &result, nil
+}
+
+// GenerateAfterActionReport writes the final report for a closed rescue. Runs on the summary
+// model: it is the same long-context reasoning over the whole transcript list, once per rescue.
+func (c *Client) GenerateAfterActionReport(ctx context.Context, in ml.AfterActionInput) (*ml.AfterActionReport, error) {
+	if in.DispatchTranscription == "" && len(in.TACTranscripts) == 0 {
+		return nil, fmt.Errorf("no transcripts to report on")
+	}
+
+	def, err := prompts.AfterActionSchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate after-action schema: %w", err)
+	}
+	schema, err := schemaToMap(def)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert schema: %w", err)
+	}
+
+	raw, err := c.complete(ctx, c.summaryModel, prompts.AfterActionSystemPrompt, prompts.BuildAfterActionUserPrompt(in), schema)
+	if err != nil {
+		return nil, fmt.Errorf("after-action report: %w", err)
+	}
+
+	var report ml.AfterActionReport
+	if err := json.Unmarshal([]byte(raw), &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal after-action report: %w, content: %s", err, raw)
+	}
+	return &report, nil
+}
+
 // complete issues one non-streaming structured-output request and returns the raw JSON text.
 //
 // Thinking is disabled: these are short extraction/classification tasks where reasoning adds
@@ -181,7 +272,72 @@ func (c *Client) CleanTACTranscript(ctx context.Context, in ml.TACCleanupInput)
 // caching is effectively a no-op today but kicks in automatically if a large CALL_TYPES enum
 // or a longer prompt pushes the prefix past the threshold.
 func (c *Client) complete(ctx context.Context, model anthropic.Model, systemPrompt, userContent string, schema map[string]any) (string, error) {
-	resp, err := c.client.Messages.New(ctx, anthropic.MessageNewParams{
+	resp, err := c.client.Messages.New(ctx, c.params(model, systemPrompt, userContent, schema))
+	if err != nil {
+		return "", fmt.Errorf("messages request error: %w", err)
+	}
+	reportUsage(ctx, model, resp.Usage)
+
+	text := extractText(resp)
+	if text == "" {
+		return "", fmt.Errorf("empty response content from Anthropic (stop_reason: %s)", resp.StopReason)
+	}
+	return text, nil
+}
+
+// completeStream is complete over a streaming request. onText is called with the whole text
+// streamed so far after every text delta; the return value is the same as complete's.
+func (c *Client) completeStream(ctx context.Context, model anthropic.Model, systemPrompt, userContent string, schema map[string]any, onText func(string)) (string, error) {
+	stream := c.client.Messages.NewStreaming(ctx, c.params(model, systemPrompt, userContent, schema))
+	defer func() { _ = stream.Close() }()
+
+	// The accumulated message's text blocks only render their text once the block stops, so the
+	// running text is built from the deltas directly.
+	var (
+		resp  anthropic.Message
+		sofar strings.Builder
+	)
+	for stream.Next() {
+		event := stream.Current()
+		if err := resp.Accumulate(event); err != nil {
+			return "", fmt.Errorf("accumulate stream event: %w", err)
+		}
+		if delta, ok := event.AsAny().(anthropic.ContentBlockDeltaEvent); ok {
+			if text, ok := delta.Delta.AsAny().(anthropic.TextDelta); ok {
+				sofar.WriteString(text.Text)
+				onText(strings.TrimSpace(sofar.String()))
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		if resp.Usage.InputTokens > 0 || resp.Usage.OutputTokens > 0 {
+			reportUsage(ctx, model, resp.Usage) // a stream cut short was still billed
+		}
+		return "", fmt.Errorf("messages stream error: %w", err)
+	}
+	reportUsage(ctx, model, resp.Usage)
+
+	text := extractText(&resp)
+	if text == "" {
+		return "", fmt.Errorf("empty response content from Anthropic (stop_reason: %s)", resp.StopReason)
+	}
+	return text, nil
+}
+
+// reportUsage hands a response's token counts to the caller's usage sinks (see ml.ReportUsage).
+func reportUsage(ctx context.Context, model anthropic.Model, u anthropic.Usage) {
+	ml.ReportUsage(ctx, ml.Usage{
+		Backend:          "anthropic",
+		Model:            string(model),
+		InputTokens:      u.InputTokens,
+		OutputTokens:     u.OutputTokens,
+		CacheReadTokens:  u.CacheReadInputTokens,
+		CacheWriteTokens: u.CacheCreationInputTokens,
+	})
+}
+
+func (c *Client) params(model anthropic.Model, systemPrompt, userContent string, schema map[string]any) anthropic.MessageNewParams {
+	return anthropic.MessageNewParams{
 		Model:     model,
 		MaxTokens: c.maxTokens,
 		System: []anthropic.TextBlockParam{{
@@ -197,16 +353,7 @@ func (c *Client) complete(ctx context.Context, model anthropic.Model, systemProm
 		OutputConfig: anthropic.OutputConfigParam{
 			Format: anthropic.JSONOutputFormatParam{Schema: schema},
 		},
-	})
-	if err != nil {
-		return "", fmt.Errorf("messages request error: %w", err)
 	}
-
-	text := extractText(resp)
-	if text == "" {
-		return "", fmt.Errorf("empty response content from Anthropic (stop_reason: %s)", resp.StopReason)
-	}
-	return text, nil
 }
 
 // extractText concatenates the text of every text content block in the response. Structured
@@ -1,6 +1,11 @@
 package anthropic
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/searchandrescuegg/transcribe/internal/ml"
@@ -11,8 +16,9 @@ import (
 
 // Compile-time proof the Anthropic client satisfies both halves of transcribe.MLClient.
 var (
-	_ ml.DispatchMessageParser = (*Client)(nil)
-	_ ml.RescueSummarizer      = (*Client)(nil)
+	_ ml.DispatchMessageParser     = (*Client)(nil)
+	_ ml.RescueSummarizer          = (*Client)(nil)
+	_ ml.StreamingRescueSummarizer = (*Client)(nil)
 )
 
 // schemaToMap must preserve the strict-mode keys Anthropic's output_config.format requires:
@@ -54,3 +60,48 @@ func TestSchemaToMap_CarriesCallTypeEnum(t *testing.T) {
 	assert.Contains(t, enum, "Rescue - Trail")
 	assert.Contains(t, enum, prompts.UnknownCallType)
 }
+
+// SummarizeRescueStream decodes the text deltas as they arrive and returns the accumulated
+// message's summary.
+func TestSummarizeRescueStream(t *testing.T) {
+	deltas := []string{`{"headline":"Hiker`, ` down","sar_notified":`, `true}`}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		send := func(event string, data any) {
+			raw, _ := json.Marshal(data)
+			_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, raw)
+		}
+		send("message_start", map[string]any{"type": "message_start", "message": map[string]any{
+			"id": "msg_1", "type": "message", "role": "assistant", "model": "m", "content": []any{},
+			"usage": map[string]any{"input_tokens": 120, "output_tokens": 0, "cache_read_input_tokens": 2048},
+		}})
+		send("content_block_start", map[string]any{"type": "content_block_start", "index": 0, "content_block": map[string]any{"type": "text", "text": ""}})
+		for _, d := range deltas {
+			send("content_block_delta", map[string]any{"type": "content_block_delta", "index": 0, "delta": map[string]any{"type": "text_delta", "text": d}})
+		}
+		send("content_block_stop", map[string]any{"type": "content_block_stop", "index": 0})
+		send("message_delta", map[string]any{"type": "message_delta", "delta": map[string]any{"stop_reason": "end_turn"}, "usage": map[string]any{"output_tokens": 3}})
+		send("message_stop", map[string]any{"type": "message_stop"})
+	}))
+	defer srv.Close()
+
+	c := NewClient(Options{APIKey: "test", BaseURL: srv.URL, SummaryModel: "m"})
+	var (
+		partials []*ml.RescueSummary
+		usage    []ml.Usage
+	)
+	ctx := ml.WithUsageSink(context.Background(), func(_ context.Context, u ml.Usage) { usage = append(usage, u) })
+	got, err := c.SummarizeRescueStream(ctx, ml.RescueSummaryInput{DispatchTranscription: "x"}, func(s *ml.RescueSummary) {
+		partials = append(partials, s)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Hiker down", got.Headline)
+	assert.True(t, got.SARNotified)
+
+	require.Len(t, partials, 2)
+	assert.Equal(t, "Hiker down", partials[0].Headline)
+	assert.False(t, partials[0].SARNotified)
+	assert.True(t, partials[1].SARNotified)
+
+	assert.Equal(t, []ml.Usage{{Backend: "anthropic", Model: "m", InputTokens: 120, OutputTokens: 3, CacheReadTokens: 2048}}, usage)
+}
@@ -1,7 +1,6 @@
 package asr
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -39,30 +38,40 @@ func NewASRClient(endpoint string, defaultTimeout time.Duration) *ASRClient {
 	}
 }
 
+// Transcribe posts fileContent to the ASR endpoint as a multipart "file" field. The body is
+// streamed through an io.Pipe rather than assembled in a buffer first, so a recording is only
+// ever held by the reader the caller passes in (typically the S3 object stream) — not copied
+// again into a multipart buffer.
 func (c *ASRClient) Transcribe(ctx context.Context, fileName string, fileContent io.Reader) (*TranscriptionResponse, error) {
 	transcribeCtx, cancel := context.WithTimeout(ctx, c.defaultTimeout)
 	defer cancel()
 
-	var body bytes.Buffer
-	writer := multipart.NewWriter(&body)
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
 
-	part, err := writer.CreateFormFile("file", fileName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %w", err)
-	}
-
-	_, err = io.Copy(part, fileContent)
-	if err != nil {
-		return nil, fmt.Errorf("failed to copy file content: %w", err)
-	}
-
-	err = writer.Close()
-	if err != nil {
-		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
-	}
+	// The writer goroutine ends when the copy finishes or the pipe's reader is closed — the
+	// HTTP transport closes the request body on both success and failure, which unblocks a
+	// pending Write with io.ErrClosedPipe.
+	go func() {
+		part, err := writer.CreateFormFile("file", fileName)
+		if err != nil {
+			pw.CloseWithError(fmt.Errorf("failed to create form file: %w", err))
+			return
+		}
+		if _, err := io.Copy(part, fileContent); err != nil {
+			pw.CloseWithError(fmt.Errorf("failed to copy file content: %w", err))
+			return
+		}
+		if err := writer.Close(); err != nil {
+			pw.CloseWithError(fmt.Errorf("failed to close multipart writer: %w", err))
+			return
+		}
+		_ = pw.Close()
+	}()
 
-	req, err := http.NewRequestWithContext(transcribeCtx, "POST", c.endpoint, &body)
+	req, err := http.NewRequestWithContext(transcribeCtx, "POST", c.endpoint, pr)
 	if err != nil {
+		_ = pr.CloseWithError(err)
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
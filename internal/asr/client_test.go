@@ -0,0 +1,66 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscribe_StreamsMultipartFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, hdr, err := r.FormFile("file")
+		if !assert.NoError(t, err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(f)
+		assert.Equal(t, "1385-1.wav", hdr.Filename)
+		assert.Equal(t, "RIFF-audio-bytes", string(body))
+		_, _ = w.Write([]byte(`{"text":"engine 1838 on scene","no_speech_detected":false}`))
+	}))
+	defer srv.Close()
+
+	c := NewASRClient(srv.URL, 5*time.Second)
+	tr, err := c.Transcribe(context.Background(), "1385-1.wav", strings.NewReader("RIFF-audio-bytes"))
+	require.NoError(t, err)
+	assert.Equal(t, "engine 1838 on scene", tr.Transcription)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("s3 stream broke") }
+
+func TestTranscribe_SourceReadErrorFailsRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"text":"should not be trusted"}`))
+	}))
+	defer srv.Close()
+
+	c := NewASRClient(srv.URL, 5*time.Second)
+	_, err := c.Transcribe(context.Background(), "1385-1.wav", failingReader{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "s3 stream broke")
+}
+
+func TestTranscribe_Non2xxSurfacesBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := NewASRClient(srv.URL, 5*time.Second)
+	_, err := c.Transcribe(context.Background(), "1385-1.wav", strings.NewReader("x"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+	assert.Contains(t, err.Error(), "model not loaded")
+}
@@ -0,0 +1,159 @@
+// Package audio holds the small amount of audio handling the service does itself. Radio
+// captures arrive as PCM WAV from trunk-recorder; the only transform needed is shrinking them
+// before a Slack upload. That is a downsample, not a codec: the output is still 16-bit PCM WAV,
+// because a compressed format (Opus, MP3) would need a cgo or ffmpeg encoder in the image.
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// NarrowbandSampleRate is the rate P25/analog radio audio is effectively band-limited to.
+// Anything above it in a capture is resampling headroom, not signal.
+const NarrowbandSampleRate = 8000
+
+var ErrUnsupportedWAV = errors.New("unsupported WAV encoding")
+
+// PCM is decoded 16-bit audio, interleaved when Channels > 1.
+type PCM struct {
+	SampleRate int
+	Channels   int
+	Samples    []int16
+}
+
+// DecodeWAV parses a RIFF/WAVE file containing 16-bit integer PCM. Unknown chunks (LIST,
+// fact, …) are skipped; other encodings return ErrUnsupportedWAV.
+func DecodeWAV(data []byte) (*PCM, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("%w: missing RIFF/WAVE header", ErrUnsupportedWAV)
+	}
+
+	var (
+		pcm    PCM
+		gotFmt bool
+	)
+	for off := 12; off+8 <= len(data); {
+		id := string(data[off : off+4])
+		size := int(binary.LittleEndian.Uint32(data[off+4 : off+8]))
+		body := off + 8
+		if size < 0 || body+size > len(data) {
+			// Truncated trailing chunk — common when a recorder is killed mid-write. Take
+			// what's there for the data chunk, bail for anything else.
+			if id != "data" {
+				break
+			}
+			size = len(data) - body
+		}
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("%w: short fmt chunk", ErrUnsupportedWAV)
+			}
+			format := binary.LittleEndian.Uint16(data[body : body+2])
+			pcm.Channels = int(binary.LittleEndian.Uint16(data[body+2 : body+4]))
+			pcm.SampleRate = int(binary.LittleEndian.Uint32(data[body+4 : body+8]))
+			bits := binary.LittleEndian.Uint16(data[body+14 : body+16])
+			// 0xFFFE (WAVE_FORMAT_EXTENSIBLE) with 16-bit samples is PCM in practice.
+			if (format != 1 && format != 0xFFFE) || bits != 16 || pcm.Channels < 1 || pcm.SampleRate <= 0 {
+				return nil, fmt.Errorf("%w: format=%d bits=%d channels=%d", ErrUnsupportedWAV, format, bits, pcm.Channels)
+			}
+			gotFmt = true
+		case "data":
+			if !gotFmt {
+				return nil, fmt.Errorf("%w: data chunk before fmt chunk", ErrUnsupportedWAV)
+			}
+			n := size / 2
+			pcm.Samples = make([]int16, n)
+			for i := 0; i < n; i++ {
+				pcm.Samples[i] = int16(binary.LittleEndian.Uint16(data[body+2*i:]))
+			}
+			return &pcm, nil
+		}
+
+		off = body + size + size%2 // chunks are word-aligned
+	}
+	return nil, fmt.Errorf("%w: no data chunk", ErrUnsupportedWAV)
+}
+
+// EncodeWAV writes mono or interleaved 16-bit PCM as a canonical 44-byte-header WAV.
+func EncodeWAV(pcm *PCM) []byte {
+	dataLen := len(pcm.Samples) * 2
+	var buf bytes.Buffer
+	buf.Grow(44 + dataLen)
+
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(36+dataLen))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1)) // PCM
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(pcm.Channels))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(pcm.SampleRate))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(pcm.SampleRate*pcm.Channels*2))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(pcm.Channels*2))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(16))
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(dataLen))
+	_ = binary.Write(&buf, binary.LittleEndian, pcm.Samples)
+	return buf.Bytes()
+}
+
+// ToNarrowbandMono downmixes to one channel and resamples to NarrowbandSampleRate. When
+// decimating, each output sample is the mean of the input samples it covers — a box filter
+// that is crude but enough to keep aliasing out of voice. Upsampling is never done: audio
+// already at or below the target rate is only downmixed.
+func ToNarrowbandMono(pcm *PCM) *PCM {
+	mono := pcm.Samples
+	if pcm.Channels > 1 {
+		frames := len(pcm.Samples) / pcm.Channels
+		mono = make([]int16, frames)
+		for i := 0; i < frames; i++ {
+			sum := 0
+			for c := 0; c < pcm.Channels; c++ {
+				sum += int(pcm.Samples[i*pcm.Channels+c])
+			}
+			mono[i] = int16(sum / pcm.Channels)
+		}
+	}
+	if pcm.SampleRate <= NarrowbandSampleRate {
+		return &PCM{SampleRate: pcm.SampleRate, Channels: 1, Samples: mono}
+	}
+
+	ratio := float64(pcm.SampleRate) / NarrowbandSampleRate
+	outLen := int(float64(len(mono)) / ratio)
+	out := make([]int16, outLen)
+	for i := 0; i < outLen; i++ {
+		start := int(float64(i) * ratio)
+		end := int(float64(i+1) * ratio)
+		if end > len(mono) {
+			end = len(mono)
+		}
+		if end <= start {
+			end = start + 1
+		}
+		sum := 0
+		for _, s := range mono[start:end] {
+			sum += int(s)
+		}
+		out[i] = int16(sum / (end - start))
+	}
+	return &PCM{SampleRate: NarrowbandSampleRate, Channels: 1, Samples: out}
+}
+
+// DownsampleWAV is the one-call form used by the Slack upload path: decode, shrink to 8 kHz
+// mono, re-encode as PCM WAV. Returns the input unchanged when it is already narrowband mono,
+// so the common trunk-recorder case costs nothing.
+func DownsampleWAV(data []byte) ([]byte, error) {
+	pcm, err := DecodeWAV(data)
+	if err != nil {
+		return nil, err
+	}
+	if pcm.Channels == 1 && pcm.SampleRate <= NarrowbandSampleRate {
+		return data, nil
+	}
+	return EncodeWAV(ToNarrowbandMono(pcm)), nil
+}
@@ -0,0 +1,41 @@
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeWAV_RoundTrip(t *testing.T) {
+	in := &PCM{SampleRate: 16000, Channels: 2, Samples: []int16{1, -1, 300, -300, 32767, -32768}}
+	out, err := DecodeWAV(EncodeWAV(in))
+	require.NoError(t, err)
+	assert.Equal(t, in, out)
+}
+
+func TestDecodeWAV_RejectsNonPCM(t *testing.T) {
+	_, err := DecodeWAV([]byte("not a wav file at all"))
+	assert.ErrorIs(t, err, ErrUnsupportedWAV)
+
+	data := EncodeWAV(&PCM{SampleRate: 8000, Channels: 1, Samples: []int16{0}})
+	data[20] = 3 // IEEE float
+	_, err = DecodeWAV(data)
+	assert.ErrorIs(t, err, ErrUnsupportedWAV)
+}
+
+func TestToNarrowbandMono_DownmixesAndDecimates(t *testing.T) {
+	// 16 kHz stereo, 4 frames: L/R average then pairs averaged down to 8 kHz.
+	in := &PCM{SampleRate: 16000, Channels: 2, Samples: []int16{100, 300, 200, 400, -100, -300, -200, -400}}
+	out := ToNarrowbandMono(in)
+	assert.Equal(t, NarrowbandSampleRate, out.SampleRate)
+	assert.Equal(t, 1, out.Channels)
+	assert.Equal(t, []int16{250, -250}, out.Samples)
+}
+
+func TestDownsampleWAV_PassesThroughNarrowbandMono(t *testing.T) {
+	data := EncodeWAV(&PCM{SampleRate: 8000, Channels: 1, Samples: []int16{1, 2, 3}})
+	out, err := DownsampleWAV(data)
+	require.NoError(t, err)
+	assert.Equal(t, data, out)
+}
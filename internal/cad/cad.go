@@ -0,0 +1,175 @@
+// Package cad is the provider-neutral CAD model the transcribe service enriches rescues from: the
+// active incidents (call type, location, received time) and the units assigned to each, with
+// their statuses already decoded to human-readable form. A Provider adapts one CAD feed to that
+// model — PulsePoint (package pulsepoint), any JSON-over-HTTP feed via a field mapping
+// (HTTPProvider), or a static file for testing (StaticProvider) — and the Resolver does the rest
+// the same way for all of them: scoring incidents against a radio dispatch, rendering the unit
+// prompt block, and listing rescues for the CAD poller.
+//
+// Correlating a radio rescue to a specific CAD incident is inherently fuzzy, so ResolveForRescue
+// scores active incidents by location overlap (with the dispatch text), call-type, and recency,
+// and falls back to the union of all active rescue-like units when no single incident matches
+// confidently. Everything here is best-effort: the caller treats any error as "no unit context".
+package cad
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Incident is one active CAD incident.
+type Incident struct {
+	ID       string
+	CallType string
+	Address  string
+	// Place is a public place name the feed gives separately from the address (e.g. "Mount Si
+	// Trailhead"), or empty. It is scored alongside Address but not displayed.
+	Place string
+	// ReceivedAt is when CAD received the call; zero when the feed's timestamp didn't parse.
+	ReceivedAt time.Time
+	// Lat and Lon are the incident's coordinates, valid only when Located.
+	Lat, Lon float64
+	Located  bool
+	Units    []Unit
+}
+
+// Unit is one unit assigned to a call, with its dispatch status decoded to human-readable form
+// by the provider (falls back to the raw status code when the feed has no decoding for it).
+type Unit struct {
+	ID     string `json:"id"`               // canonical callsign, e.g. "A181", "B181", "L161"
+	Status string `json:"status,omitempty"` // decoded dispatch status, e.g. "En Route", "On Scene"
+}
+
+// Provider adapts one CAD feed to the neutral model. ListActive returns the in-progress incidents
+// (closed calls are the provider's to drop). Implementations must be safe for concurrent use; the
+// Resolver bounds each call with its own timeout.
+type Provider interface {
+	ListActive(ctx context.Context) ([]Incident, error)
+}
+
+// UnitContext is the resolved set of units feeding the cleanup + summary prompts. Matched
+// distinguishes a confident single-incident match from the active rescue roster fallback; Pinned
+// marks a match an operator chose rather than the scorer. Candidates explains the choice: every
+// active incident the scorer looked at, best first, so operators can see why an incident won
+// and pin a different one.
+type UnitContext struct {
+	Matched    bool        `json:"matched"`
+	Pinned     bool        `json:"pinned,omitempty"`
+	IncidentID string      `json:"incident_id,omitempty"`
+	CallType   string      `json:"call_type,omitempty"`
+	Address    string      `json:"address,omitempty"`
+	Units      []Unit      `json:"units,omitempty"`
+	Candidates []Candidate `json:"candidates,omitempty"`
+}
+
+// Candidate is one active incident as scored against the dispatch.
+type Candidate struct {
+	IncidentID string  `json:"incident_id"`
+	CallType   string  `json:"call_type,omitempty"`
+	Address    string  `json:"address,omitempty"`
+	Score      float64 `json:"score"`
+	// Overlap is the location words the incident shares with the dispatch, sorted.
+	Overlap []string `json:"overlap,omitempty"`
+}
+
+// PromptBlock renders the unit context as a labeled block for inclusion in an LLM prompt. Returns
+// an empty string when there are no units, so callers can concatenate unconditionally without
+// emitting an empty header.
+func (u UnitContext) PromptBlock() string {
+	if len(u.Units) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("=== Units currently assigned to this call (from CAD) ===\n")
+	if u.Matched {
+		header := "Matched incident"
+		if u.CallType != "" {
+			header += ": " + u.CallType
+		}
+		if u.Address != "" {
+			header += " — " + u.Address
+		}
+		b.WriteString(header)
+		b.WriteString("\n")
+	} else {
+		b.WriteString("(No single incident matched confidently — showing all units active for the agency; use these callsigns for spelling/disambiguation only.)\n")
+	}
+	b.WriteString("Use these exact callsigns to correct garbled unit references. Do not add a unit that the radio traffic does not mention.\n")
+	for _, unit := range u.Units {
+		if unit.Status != "" {
+			fmt.Fprintf(&b, "  - %s (%s)\n", unit.ID, unit.Status)
+		} else {
+			fmt.Fprintf(&b, "  - %s\n", unit.ID)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+const defaultTimeout = 5 * time.Second
+
+// Resolver builds UnitContexts and rescue lists from a Provider. Safe for concurrent use.
+type Resolver struct {
+	provider Provider
+	timeout  time.Duration
+}
+
+// NewResolver wraps provider. timeout bounds each CAD round-trip independently of the caller's
+// context so a slow feed can't eat the worker budget; zero uses a 5s default.
+func NewResolver(provider Provider, timeout time.Duration) *Resolver {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Resolver{provider: provider, timeout: timeout}
+}
+
+func (r *Resolver) listActive(ctx context.Context) ([]Incident, error) {
+	if r.provider == nil {
+		return nil, nil
+	}
+	callCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.provider.ListActive(callCtx)
+}
+
+// ResolveForRescue lists the active incidents and selects the best match for the dispatch (or
+// the rescue roster fallback). A non-empty pinnedID that is still active wins regardless of
+// score; once it closes, selection falls back to scoring. Returns a zero UnitContext when the
+// feed is empty; returns an error only when the listing itself fails. Satisfies
+// transcribe.UnitResolver.
+func (r *Resolver) ResolveForRescue(ctx context.Context, dispatchText string, referenceTime time.Time, pinnedID string) (UnitContext, error) {
+	active, err := r.listActive(ctx)
+	if err != nil {
+		return UnitContext{}, err
+	}
+	return selectUnitContext(active, dispatchText, referenceTime, pinnedID), nil
+}
+
+// ActiveIncidents returns the active incidents whose call type contains any of callTypes
+// (case-insensitive substrings, e.g. "RESCUE"). Unlike the enrichment hints this list is the
+// caller's, because opening an alert needs a narrower net than picking a unit roster.
+func (r *Resolver) ActiveIncidents(ctx context.Context, callTypes []string) ([]Incident, error) {
+	active, err := r.listActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []Incident
+	for _, inc := range active {
+		if inc.ID != "" && callTypeMatches(inc.CallType, callTypes) {
+			out = append(out, inc)
+		}
+	}
+	return out, nil
+}
+
+func callTypeMatches(callType string, callTypes []string) bool {
+	upper := strings.ToUpper(callType)
+	for _, want := range callTypes {
+		if want = strings.ToUpper(strings.TrimSpace(want)); want != "" && strings.Contains(upper, want) {
+			return true
+		}
+	}
+	return false
+}
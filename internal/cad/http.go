@@ -0,0 +1,257 @@
+package cad
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxFeedBytes caps one feed response so a misbehaving endpoint can't exhaust memory.
+const maxFeedBytes = 16 << 20
+
+// Mapping tells HTTPProvider where each Incident field lives in a feed's JSON. Every field is a
+// dot-separated path ("location.lat"; numeric segments index arrays, "units.0.id"). Incidents is
+// the path to the incident array from the document root (empty when the root is the array); the
+// other incident paths are relative to one incident, and UnitID / UnitStatus are relative to one
+// element of Units. A units array of plain strings is read as bare callsigns.
+//
+// Only ID is required. Values may be strings or numbers; ReceivedAt takes anything ParseTime
+// understands, including epoch seconds or milliseconds. StatusLabels decodes raw unit status
+// codes ("ER" → "En Route", matched case-insensitively); unlisted codes pass through. When Closed
+// is set, an incident whose value there is truthy (true, a non-zero number, or a string other
+// than "", "false", "0", "no") is dropped, for feeds that list closed calls alongside active ones.
+type Mapping struct {
+	Incidents    string            `json:"incidents"`
+	ID           string            `json:"id"`
+	CallType     string            `json:"call_type"`
+	Address      string            `json:"address"`
+	Place        string            `json:"place"`
+	ReceivedAt   string            `json:"received_at"`
+	Lat          string            `json:"lat"`
+	Lon          string            `json:"lon"`
+	Closed       string            `json:"closed"`
+	Units        string            `json:"units"`
+	UnitID       string            `json:"unit_id"`
+	UnitStatus   string            `json:"unit_status"`
+	StatusLabels map[string]string `json:"status_labels"`
+}
+
+// LoadMapping reads and validates a JSON Mapping file.
+func LoadMapping(path string) (Mapping, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Mapping{}, fmt.Errorf("failed to read CAD field mapping: %w", err)
+	}
+	var m Mapping
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return Mapping{}, fmt.Errorf("failed to parse CAD field mapping %s: %w", path, err)
+	}
+	if err := m.validate(); err != nil {
+		return Mapping{}, fmt.Errorf("invalid CAD field mapping %s: %w", path, err)
+	}
+	return m, nil
+}
+
+func (m Mapping) validate() error {
+	if strings.TrimSpace(m.ID) == "" {
+		return fmt.Errorf("id path is required")
+	}
+	if (m.Lat == "") != (m.Lon == "") {
+		return fmt.Errorf("lat and lon paths must be set together")
+	}
+	return nil
+}
+
+// HTTPProvider reads a JSON CAD feed over HTTP GET and maps it onto the neutral model with a
+// Mapping. Safe for concurrent use.
+type HTTPProvider struct {
+	url     string
+	headers map[string]string
+	mapping Mapping
+	labels  map[string]string // StatusLabels keyed by upper-cased code
+	client  *http.Client
+}
+
+// NewHTTPProvider reads url with the given extra request headers (e.g. an Authorization header)
+// and mapping. client may be nil for http.DefaultClient; the Resolver bounds each call anyway.
+func NewHTTPProvider(url string, headers map[string]string, mapping Mapping, client *http.Client) *HTTPProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	labels := make(map[string]string, len(mapping.StatusLabels))
+	for code, label := range mapping.StatusLabels {
+		labels[strings.ToUpper(strings.TrimSpace(code))] = label
+	}
+	return &HTTPProvider{url: url, headers: headers, mapping: mapping, labels: labels, client: client}
+}
+
+// ListActive fetches the feed and maps it. Satisfies Provider.
+func (p *HTTPProvider) ListActive(ctx context.Context) ([]Incident, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build CAD feed request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch CAD feed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("CAD feed returned %s", resp.Status)
+	}
+
+	dec := json.NewDecoder(io.LimitReader(resp.Body, maxFeedBytes))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode CAD feed: %w", err)
+	}
+	return p.mapIncidents(doc)
+}
+
+// mapIncidents is the pure half of ListActive.
+func (p *HTTPProvider) mapIncidents(doc any) ([]Incident, error) {
+	m := p.mapping
+	list, ok := lookup(doc, m.Incidents)
+	if !ok {
+		return nil, fmt.Errorf("CAD feed has no %q", m.Incidents)
+	}
+	items, ok := list.([]any)
+	if !ok {
+		return nil, fmt.Errorf("CAD feed %q is not an array", m.Incidents)
+	}
+
+	out := make([]Incident, 0, len(items))
+	for _, item := range items {
+		inc := Incident{
+			ID:       str(item, m.ID),
+			CallType: str(item, m.CallType),
+			Address:  str(item, m.Address),
+			Place:    str(item, m.Place),
+		}
+		if inc.ID == "" || (m.Closed != "" && truthy(field(item, m.Closed))) {
+			continue
+		}
+		if t, ok := ParseTime(str(item, m.ReceivedAt)); ok {
+			inc.ReceivedAt = t
+		}
+		if m.Lat != "" {
+			lat, latOK := number(field(item, m.Lat))
+			lon, lonOK := number(field(item, m.Lon))
+			if latOK && lonOK && (lat != 0 || lon != 0) {
+				inc.Lat, inc.Lon, inc.Located = lat, lon, true
+			}
+		}
+		inc.Units = p.units(field(item, m.Units))
+		out = append(out, inc)
+	}
+	return out, nil
+}
+
+func (p *HTTPProvider) units(v any) []Unit {
+	items, _ := v.([]any)
+	var out []Unit
+	for _, item := range items {
+		var u Unit
+		if id, ok := item.(string); ok {
+			u.ID = strings.TrimSpace(id)
+		} else {
+			u.ID = str(item, p.mapping.UnitID)
+			u.Status = str(item, p.mapping.UnitStatus)
+		}
+		if u.ID == "" {
+			continue
+		}
+		if label, ok := p.labels[strings.ToUpper(u.Status)]; ok && label != "" {
+			u.Status = label
+		}
+		out = append(out, u)
+	}
+	return out
+}
+
+// str reads path under v as a string; empty when the path is unset, missing, or not a scalar.
+func str(v any, path string) string {
+	return scalar(field(v, path))
+}
+
+// field is lookup for an optional path: an unset path reads as missing rather than as v.
+func field(v any, path string) any {
+	if path == "" {
+		return nil
+	}
+	got, _ := lookup(v, path)
+	return got
+}
+
+// lookup walks a dot-separated path through decoded JSON. The empty path is v itself.
+func lookup(v any, path string) (any, bool) {
+	if path == "" {
+		return v, true
+	}
+	for _, seg := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			next, ok := node[seg]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func scalar(v any) string {
+	switch s := v.(type) {
+	case string:
+		return strings.TrimSpace(s)
+	case json.Number:
+		return s.String()
+	case bool:
+		return strconv.FormatBool(s)
+	}
+	return ""
+}
+
+func number(v any) (float64, bool) {
+	f, err := strconv.ParseFloat(scalar(v), 64)
+	return f, err == nil
+}
+
+func truthy(v any) bool {
+	switch s := v.(type) {
+	case bool:
+		return s
+	case json.Number:
+		f, err := s.Float64()
+		return err == nil && f != 0
+	case string:
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "", "false", "0", "no":
+			return false
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package cad
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	recencyWindow      = 30 * time.Minute // incidents older than this contribute no recency score
+	locationTokenMinLn = 4                // ignore short/common tokens when comparing locations
+	maxCandidates      = 10               // candidates kept on a UnitContext (the pin dropdown's options)
+
+	// MatchScoreThreshold is the minimum score to accept a single-incident match. A match also
+	// needs at least one shared location word.
+	MatchScoreThreshold = 2.0
+)
+
+// rescueCallTypeHints are substrings (case-insensitive) in an incident's CallType that suggest a
+// trail-rescue / medical incident. Call-type codes vary by agency and feed, so this is a soft
+// boost, never a hard filter. Keep each hint specific enough that it won't collide with unrelated
+// call types (e.g. avoid a bare "TR", which matches "TRAFFIC"/"STRUCTURE").
+var rescueCallTypeHints = []string{"RESCUE", "MEDICAL", "MEDIC", "TRAUMA", "INJURY", "FALL"}
+
+// selectUnitContext is the pure selection logic (no I/O): score each active incident, and either
+// return the pinned incident, the best confident match, or the union roster of all active
+// rescue-like units. Every outcome carries the scored candidates.
+func selectUnitContext(active []Incident, dispatchText string, referenceTime time.Time, pinnedID string) UnitContext {
+	if len(active) == 0 {
+		return UnitContext{}
+	}
+
+	dispatchTokens := tokenize(dispatchText)
+
+	bestIdx, bestScore, bestOverlap := -1, 0.0, 0
+	candidates := make([]Candidate, 0, len(active))
+	pinnedIdx := -1
+	for i, inc := range active {
+		score, overlap := scoreIncident(inc, dispatchTokens, referenceTime)
+		if score > bestScore {
+			bestScore, bestIdx, bestOverlap = score, i, len(overlap)
+		}
+		if inc.ID == "" {
+			continue
+		}
+		if pinnedID != "" && inc.ID == pinnedID {
+			pinnedIdx = i
+		}
+		candidates = append(candidates, Candidate{
+			IncidentID: inc.ID,
+			CallType:   inc.CallType,
+			Address:    inc.Address,
+			Score:      score,
+			Overlap:    overlap,
+		})
+	}
+	// Stable, so equal scores keep feed order and the best candidate is the incident picked above.
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	if len(candidates) > maxCandidates {
+		candidates = candidates[:maxCandidates]
+	}
+
+	matched := func(inc Incident) UnitContext {
+		return UnitContext{
+			Matched:    true,
+			IncidentID: inc.ID,
+			CallType:   inc.CallType,
+			Address:    inc.Address,
+			Units:      assignedUnits(inc.Units),
+			Candidates: candidates,
+		}
+	}
+	if pinnedIdx >= 0 {
+		uc := matched(active[pinnedIdx])
+		uc.Pinned = true
+		return uc
+	}
+
+	// A confident single-incident match requires BOTH a score over threshold AND at least one
+	// shared location token — call-type + recency alone can't win, so we don't grab the wrong
+	// simultaneous rescue when the dispatch clearly names a different place.
+	if bestIdx >= 0 && bestScore >= MatchScoreThreshold && bestOverlap >= 1 {
+		return matched(active[bestIdx])
+	}
+
+	// Fallback: union of units from active RESCUE/MEDICAL-type incidents only (deduped by
+	// callsign). Restricting to rescue-like incidents keeps unrelated agency traffic (traffic
+	// collisions, alarms) out of the prompt, so the model has a tight, relevant callsign set to
+	// disambiguate against rather than the whole agency roster.
+	seen := make(map[string]bool)
+	var union []Unit
+	for _, inc := range active {
+		if !matchesRescueHint(inc.CallType) {
+			continue
+		}
+		for _, u := range assignedUnits(inc.Units) {
+			if seen[u.ID] {
+				continue
+			}
+			seen[u.ID] = true
+			union = append(union, u)
+		}
+	}
+	return UnitContext{
+		Matched:    false,
+		Units:      union,
+		Candidates: candidates,
+	}
+}
+
+// scoreIncident weights location-token overlap most heavily (the strongest correlation signal),
+// with softer boosts for a rescue/medical call type and recency to the reference time. It also
+// returns the shared location tokens (sorted) so the caller can require ≥1 for a confident match
+// and explain the score.
+func scoreIncident(inc Incident, dispatchTokens map[string]bool, referenceTime time.Time) (float64, []string) {
+	var overlap []string
+	for tok := range tokenize(inc.Address + " " + inc.Place) {
+		if dispatchTokens[tok] {
+			overlap = append(overlap, tok)
+		}
+	}
+	sort.Strings(overlap)
+
+	score := 1.5 * float64(len(overlap))
+
+	if matchesRescueHint(inc.CallType) {
+		score += 1.0
+	}
+
+	if !inc.ReceivedAt.IsZero() {
+		delta := referenceTime.Sub(inc.ReceivedAt)
+		if delta < 0 {
+			delta = -delta
+		}
+		if delta <= recencyWindow {
+			// Linear falloff from 1.0 (simultaneous) to 0 (at the window edge).
+			score += 1.0 - float64(delta)/float64(recencyWindow)
+		}
+	}
+
+	return score, overlap
+}
+
+// matchesRescueHint reports whether a CallType looks like a trail-rescue / medical incident.
+func matchesRescueHint(callType string) bool {
+	upper := strings.ToUpper(callType)
+	for _, hint := range rescueCallTypeHints {
+		if strings.Contains(upper, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignedUnits drops units without a callsign, preserving order.
+func assignedUnits(units []Unit) []Unit {
+	out := make([]Unit, 0, len(units))
+	for _, u := range units {
+		if u.ID != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// tokenize lowercases text and returns the set of significant word tokens (alphanumeric, at least
+// locationTokenMinLn long) so location comparison ignores punctuation and short filler words.
+func tokenize(s string) map[string]bool {
+	out := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		// A separator is any rune that is neither a lowercase letter nor a digit. Written
+		// without a negated group so staticcheck (QF1001 De Morgan) stays quiet.
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}) {
+		if len(field) >= locationTokenMinLn {
+			out[field] = true
+		}
+	}
+	return out
+}
+
+// ParseTime parses a CAD timestamp in any of the layouts feeds have been observed to emit, or
+// as Unix epoch seconds (milliseconds when the number is too large to be seconds). Layouts
+// without a zone are read as UTC.
+func ParseTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05Z",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n > 0 {
+		if n > 1e12 {
+			return time.UnixMilli(n).UTC(), true
+		}
+		return time.Unix(n, 0).UTC(), true
+	}
+	return time.Time{}, false
+}
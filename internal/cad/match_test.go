@@ -0,0 +1,192 @@
+package cad
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sortedUnitIDs(units []Unit) []string {
+	ids := make([]string, 0, len(units))
+	for _, u := range units {
+		ids = append(ids, u.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+var refTime = time.Date(2026, 7, 14, 14, 2, 0, 0, time.UTC)
+
+func TestSelectUnitContext_MatchesByLocationAndCallType(t *testing.T) {
+	active := []Incident{
+		{
+			ID:       "inc-noise",
+			CallType: "TC", // traffic collision, different location
+			Address:  "Interstate 405 near Coal Creek Parkway",
+			Units:    []Unit{{ID: "E999", Status: "En Route"}},
+		},
+		{
+			ID:         "inc-rescue",
+			CallType:   "RESCUE",
+			Address:    "Mount Si Trailhead, Southeast Mount Si Road, North Bend",
+			ReceivedAt: time.Date(2026, 7, 14, 14, 1, 30, 0, time.UTC),
+			Units: []Unit{
+				{ID: "B171", Status: "On Scene"},
+				{ID: "E171", Status: "En Route"},
+			},
+		},
+	}
+
+	// Dispatch text shares "mount", "trailhead", "road" tokens with the rescue incident.
+	got := selectUnitContext(active, "Rescue Trail Mount Si Trailhead Southeast Mount Si Road", refTime, "")
+
+	assert.True(t, got.Matched, "should confidently match the rescue incident")
+	assert.Equal(t, "inc-rescue", got.IncidentID)
+	assert.Equal(t, []string{"B171", "E171"}, sortedUnitIDs(got.Units))
+
+	block := got.PromptBlock()
+	assert.Contains(t, block, "B171 (On Scene)")
+	assert.Contains(t, block, "E171 (En Route)")
+	assert.Contains(t, block, "Matched incident")
+}
+
+func TestSelectUnitContext_PlaceCountsTowardTheMatch(t *testing.T) {
+	active := []Incident{{
+		ID:       "inc-rescue",
+		CallType: "RESCUE",
+		Address:  "43000 SE Mount Si Rd",
+		Place:    "Little Si Trailhead",
+		Units:    []Unit{{ID: "B171"}},
+	}}
+
+	got := selectUnitContext(active, "little si trailhead", refTime, "")
+
+	assert.True(t, got.Matched, "the place name overlaps even though the address doesn't")
+	assert.Equal(t, "43000 SE Mount Si Rd", got.Address, "the address, not the place, is shown")
+}
+
+func TestSelectUnitContext_FallsBackToRescueRosterWhenNoConfidentMatch(t *testing.T) {
+	active := []Incident{
+		{ID: "a", CallType: "RESCUE", Address: "somewhere far", Units: []Unit{{ID: "E1"}}},
+		{ID: "b", CallType: "MEDICAL", Address: "elsewhere entirely", Units: []Unit{{ID: "L2"}, {ID: "E1"}}},
+	}
+
+	// Dispatch text overlaps no location token → no confident match (call-type alone can't win).
+	got := selectUnitContext(active, "unrelated words zzzz", refTime, "")
+
+	assert.False(t, got.Matched, "no location overlap → roster fallback")
+	assert.Equal(t, []string{"E1", "L2"}, sortedUnitIDs(got.Units), "union of rescue-like incidents, deduped")
+
+	block := got.PromptBlock()
+	assert.Contains(t, block, "No single incident matched")
+	assert.Contains(t, block, "E1")
+}
+
+func TestSelectUnitContext_FallbackExcludesNonRescueIncidents(t *testing.T) {
+	active := []Incident{
+		{ID: "a", CallType: "TC", Address: "far", Units: []Unit{{ID: "E1"}}},     // traffic — excluded
+		{ID: "b", CallType: "AFA", Address: "far", Units: []Unit{{ID: "L2"}}},    // alarm — excluded
+		{ID: "c", CallType: "RESCUE", Address: "far", Units: []Unit{{ID: "R3"}}}, // included
+	}
+
+	got := selectUnitContext(active, "unrelated zzzz", refTime, "")
+
+	assert.False(t, got.Matched)
+	assert.Equal(t, []string{"R3"}, sortedUnitIDs(got.Units), "only rescue/medical units survive the fallback")
+}
+
+func TestSelectUnitContext_CallTypeAndRecencyAloneDoNotMatch(t *testing.T) {
+	// A recent RESCUE incident (type +1.0, recency ~+1.0 = 2.0 ≥ threshold) but ZERO location
+	// overlap must NOT be taken as a confident match — it drops to the roster fallback instead.
+	active := []Incident{{
+		ID:         "rescue-elsewhere",
+		CallType:   "RESCUE",
+		Address:    "Completely Different Place",
+		ReceivedAt: refTime, // simultaneous
+		Units:      []Unit{{ID: "R9"}},
+	}}
+
+	got := selectUnitContext(active, "mount si trailhead", refTime, "")
+
+	assert.False(t, got.Matched, "no shared location token → not a confident match")
+	assert.Equal(t, []string{"R9"}, sortedUnitIDs(got.Units), "still surfaced via the rescue roster fallback")
+}
+
+func TestSelectUnitContext_ExplainsTheChoice(t *testing.T) {
+	active := []Incident{
+		{ID: "tc", CallType: "TC", Address: "I-90 near exit 31"},
+		{ID: "res", CallType: "RESCUE", Address: "Mount Si Trailhead, North Bend", ReceivedAt: refTime},
+		{ID: "med", CallType: "MEDICAL", Address: "Rattlesnake Lake Trailhead"},
+	}
+
+	got := selectUnitContext(active, "rescue trail mount si trailhead", refTime, "")
+
+	require.Len(t, got.Candidates, 3)
+	assert.Equal(t, Candidate{
+		IncidentID: "res",
+		CallType:   "RESCUE",
+		Address:    "Mount Si Trailhead, North Bend",
+		Score:      1.5*2 + 1 + 1,
+		Overlap:    []string{"mount", "trailhead"},
+	}, got.Candidates[0], "the winner comes first, with its shared words")
+	assert.Equal(t, "med", got.Candidates[1].IncidentID, "runner-up: one shared word plus the call type")
+	assert.Equal(t, []string{"trailhead"}, got.Candidates[1].Overlap)
+	assert.Equal(t, "tc", got.Candidates[2].IncidentID)
+	assert.Zero(t, got.Candidates[2].Score)
+
+	fallback := selectUnitContext(active, "unrelated zzzz", refTime, "")
+	assert.False(t, fallback.Matched)
+	assert.Len(t, fallback.Candidates, 3, "the fallback still explains what was considered")
+}
+
+func TestSelectUnitContext_PinWins(t *testing.T) {
+	active := []Incident{
+		{ID: "res", CallType: "RESCUE", Address: "Mount Si Trailhead", Units: []Unit{{ID: "B171"}}},
+		{ID: "other", CallType: "MEDICAL", Address: "Tiger Mountain", Units: []Unit{{ID: "M104"}}},
+	}
+
+	got := selectUnitContext(active, "mount si trailhead", refTime, "other")
+	assert.True(t, got.Matched)
+	assert.True(t, got.Pinned)
+	assert.Equal(t, "other", got.IncidentID)
+	assert.Equal(t, []Unit{{ID: "M104"}}, got.Units)
+	assert.Equal(t, "res", got.Candidates[0].IncidentID, "candidates still rank by score")
+
+	closed := selectUnitContext(active, "mount si trailhead", refTime, "gone")
+	assert.False(t, closed.Pinned, "a pin that is no longer active falls back to scoring")
+	assert.Equal(t, "res", closed.IncidentID)
+}
+
+func TestSelectUnitContext_EmptyWhenNoActiveIncidents(t *testing.T) {
+	got := selectUnitContext(nil, "anything", refTime, "")
+	assert.False(t, got.Matched)
+	assert.Empty(t, got.Units)
+	assert.Equal(t, "", got.PromptBlock(), "zero context renders no block")
+}
+
+func TestPromptBlock_EmptyForZeroValue(t *testing.T) {
+	assert.Equal(t, "", UnitContext{}.PromptBlock())
+}
+
+func TestPromptBlock_RawStatusWhenUndecoded(t *testing.T) {
+	uc := UnitContext{
+		Matched: true,
+		Units:   []Unit{{ID: "A181", Status: "XX"}},
+	}
+	assert.Contains(t, uc.PromptBlock(), "A181 (XX)", "raw status when the provider can't decode")
+}
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2026, 7, 14, 14, 1, 30, 0, time.UTC)
+	for _, in := range []string{"2026-07-14T14:01:30Z", "2026-07-14 14:01:30", "1784037690", "1784037690000"} {
+		got, ok := ParseTime(in)
+		if assert.True(t, ok, in) {
+			assert.True(t, want.Equal(got), "%s → %s", in, got)
+		}
+	}
+	_, ok := ParseTime("yesterday")
+	assert.False(t, ok)
+}
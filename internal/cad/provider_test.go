@@ -0,0 +1,161 @@
+package cad
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider []Incident
+
+func (f fakeProvider) ListActive(context.Context) ([]Incident, error) { return f, nil }
+
+func TestResolverActiveIncidents_FiltersByCallType(t *testing.T) {
+	r := NewResolver(fakeProvider{
+		{ID: "tc", CallType: "TC"},
+		{ID: "med", CallType: "MEDICAL"},
+		{ID: "res", CallType: "Rescue - Trail"},
+		{ID: "", CallType: "RESCUE"},
+	}, 0)
+
+	got, err := r.ActiveIncidents(context.Background(), []string{" rescue "})
+	require.NoError(t, err)
+	if assert.Len(t, got, 1, "only RESCUE call types with an ID, matched case-insensitively") {
+		assert.Equal(t, "res", got[0].ID)
+	}
+}
+
+func TestResolver_NilProviderIsEmpty(t *testing.T) {
+	uc, err := NewResolver(nil, 0).ResolveForRescue(context.Background(), "anything", refTime, "")
+	require.NoError(t, err)
+	assert.Equal(t, UnitContext{}, uc)
+}
+
+func writeFile(t *testing.T, name, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	return path
+}
+
+func TestStaticProvider(t *testing.T) {
+	path := writeFile(t, "cad.json", `[
+		{"id": "res", "call_type": "RESCUE", "address": "Mount Si Trailhead", "received_at": "-4m",
+		 "lat": 47.488, "lon": -121.723, "units": [{"id": "B171", "status": "On Scene"}]},
+		{"id": "old", "call_type": "MEDICAL", "received_at": "2026-07-14T14:01:30Z"},
+		{"id": "unplaced", "call_type": "RESCUE", "lat": 47.5}
+	]`)
+	p, err := NewStaticProvider(path)
+	require.NoError(t, err)
+	p.now = func() time.Time { return refTime }
+
+	got, err := p.ListActive(context.Background())
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, Incident{
+		ID:         "res",
+		CallType:   "RESCUE",
+		Address:    "Mount Si Trailhead",
+		ReceivedAt: refTime.Add(-4 * time.Minute),
+		Lat:        47.488,
+		Lon:        -121.723,
+		Located:    true,
+		Units:      []Unit{{ID: "B171", Status: "On Scene"}},
+	}, got[0])
+	assert.Equal(t, time.Date(2026, 7, 14, 14, 1, 30, 0, time.UTC), got[1].ReceivedAt)
+	assert.False(t, got[2].Located, "both coordinates are needed")
+
+	_, err = NewStaticProvider(writeFile(t, "bad.json", `{"id": "not a list"}`))
+	assert.Error(t, err)
+}
+
+func TestLoadMapping(t *testing.T) {
+	m, err := LoadMapping(writeFile(t, "mapping.json", `{"incidents": "data.calls", "id": "number"}`))
+	require.NoError(t, err)
+	assert.Equal(t, Mapping{Incidents: "data.calls", ID: "number"}, m)
+
+	for name, body := range map[string]string{
+		"no id":         `{"incidents": "calls"}`,
+		"lat alone":     `{"id": "id", "lat": "y"}`,
+		"unknown field": `{"id": "id", "adress": "where"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadMapping(writeFile(t, "mapping.json", body))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHTTPProvider(t *testing.T) {
+	feed := `{"data": {"calls": [
+		{"number": 2604123, "type": "Rescue", "where": {"text": "Mount Si Trailhead", "lat": "47.488", "lng": -121.723},
+		 "created": 1784037690, "closed": false,
+		 "apparatus": [{"name": "B171", "code": "os"}, {"name": "E171", "code": "DP"}, {"code": "ER"}]},
+		{"number": "26-9", "type": "MEDICAL", "apparatus": ["M104"], "closed": "false"},
+		{"number": "26-8", "type": "RESCUE", "closed": true},
+		{"type": "no id"}
+	]}}`
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(feed))
+	}))
+	defer srv.Close()
+
+	p := NewHTTPProvider(srv.URL, map[string]string{"Authorization": "Bearer token"}, Mapping{
+		Incidents:    "data.calls",
+		ID:           "number",
+		CallType:     "type",
+		Address:      "where.text",
+		ReceivedAt:   "created",
+		Lat:          "where.lat",
+		Lon:          "where.lng",
+		Closed:       "closed",
+		Units:        "apparatus",
+		UnitID:       "name",
+		UnitStatus:   "code",
+		StatusLabels: map[string]string{"OS": "On Scene", "ER": "En Route"},
+	}, srv.Client())
+
+	got, err := p.ListActive(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token", gotAuth)
+	require.Len(t, got, 2, "closed calls and calls without an ID are dropped")
+	assert.Equal(t, Incident{
+		ID:         "2604123",
+		CallType:   "Rescue",
+		Address:    "Mount Si Trailhead",
+		ReceivedAt: time.Date(2026, 7, 14, 14, 1, 30, 0, time.UTC),
+		Lat:        47.488,
+		Lon:        -121.723,
+		Located:    true,
+		Units:      []Unit{{ID: "B171", Status: "On Scene"}, {ID: "E171", Status: "DP"}},
+	}, got[0])
+	assert.Equal(t, []Unit{{ID: "M104"}}, got[1].Units, "plain strings are bare callsigns")
+	assert.False(t, got[1].Located)
+}
+
+func TestHTTPProvider_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"calls": {"not": "a list"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewHTTPProvider(srv.URL+"/down", nil, Mapping{ID: "id"}, srv.Client()).ListActive(context.Background())
+	assert.Error(t, err)
+	_, err = NewHTTPProvider(srv.URL, nil, Mapping{Incidents: "calls", ID: "id"}, srv.Client()).ListActive(context.Background())
+	assert.Error(t, err)
+	_, err = NewHTTPProvider(srv.URL, nil, Mapping{Incidents: "missing", ID: "id"}, srv.Client()).ListActive(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package cad
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// StaticProvider serves the incidents in a JSON file, for exercising enrichment, the CAD poller
+// and the unit timeline without a live feed. The file is re-read on every call, so editing it
+// (adding a unit, changing a status) is seen on the next refresh:
+//
+//	[
+//	  {
+//	    "id": "26-104233",
+//	    "call_type": "RESCUE",
+//	    "address": "Mount Si Trailhead, North Bend",
+//	    "received_at": "-4m",
+//	    "lat": 47.488, "lon": -121.723,
+//	    "units": [{"id": "B171", "status": "On Scene"}, {"id": "E171", "status": "En Route"}]
+//	  }
+//	]
+//
+// received_at is a timestamp ParseTime understands or a negative Go duration relative to the
+// read ("-4m": received four minutes ago), so a fixture stays fresh. Coordinates are optional;
+// an incident is located only when both are present.
+type StaticProvider struct {
+	path string
+	now  func() time.Time
+}
+
+// staticIncident is one entry in the file.
+type staticIncident struct {
+	ID         string   `json:"id"`
+	CallType   string   `json:"call_type"`
+	Address    string   `json:"address"`
+	Place      string   `json:"place"`
+	ReceivedAt string   `json:"received_at"`
+	Lat        *float64 `json:"lat"`
+	Lon        *float64 `json:"lon"`
+	Units      []Unit   `json:"units"`
+}
+
+// NewStaticProvider serves path. The file is checked up front so a typo fails at startup rather
+// than as an empty feed.
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	p := &StaticProvider{path: path, now: time.Now}
+	if _, err := p.ListActive(context.Background()); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListActive reads the file. Satisfies Provider.
+func (p *StaticProvider) ListActive(_ context.Context) ([]Incident, error) {
+	raw, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CAD file: %w", err)
+	}
+	var entries []staticIncident
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse CAD file %s: %w", p.path, err)
+	}
+
+	now := p.now()
+	out := make([]Incident, 0, len(entries))
+	for _, e := range entries {
+		inc := Incident{
+			ID:       e.ID,
+			CallType: e.CallType,
+			Address:  e.Address,
+			Place:    e.Place,
+			Units:    e.Units,
+		}
+		if strings.HasPrefix(strings.TrimSpace(e.ReceivedAt), "-") {
+			if ago, err := time.ParseDuration(strings.TrimSpace(e.ReceivedAt)); err == nil {
+				inc.ReceivedAt = now.Add(ago)
+			}
+		} else if t, ok := ParseTime(e.ReceivedAt); ok {
+			inc.ReceivedAt = t
+		}
+		if e.Lat != nil && e.Lon != nil {
+			inc.Lat, inc.Lon, inc.Located = *e.Lat, *e.Lon, true
+		}
+		out = append(out, inc)
+	}
+	return out, nil
+}
@@ -0,0 +1,382 @@
+// Package callsign is the deterministic unit-callsign pass run on every TAC transmission ahead of
+// the LLM cleanup (or instead of it, with TAC_CLEANUP_ENABLED=false). It does three things:
+//
+//   - spoken numbers become digits ("one eighty one" → "181");
+//   - a unit-type word followed by a number becomes its canonical form through a configurable
+//     table that also absorbs the usual ASR garbles ("Italian one seventy one" → "Battalion 171");
+//   - a bare number that belongs to exactly one unit on the CAD roster becomes that unit's
+//     callsign ("eighty one seventy one" → "A8171" when A8171 is assigned).
+//
+// Like the place-name snap it is deliberately conservative. Anything it isn't sure of is left
+// as transcribed for the model (or the reader) to interpret.
+package callsign
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/agnivade/levenshtein"
+	"github.com/searchandrescuegg/transcribe/internal/cad"
+)
+
+// UnitType is one row of the unit-type table (CALLSIGN_UNIT_TYPES).
+type UnitType struct {
+	Name    string   // canonical spoken form, e.g. "Battalion"
+	Prefix  string   // CAD callsign prefix, e.g. "B" for B171
+	Aliases []string // other words ASR produces for it, e.g. "Italian"
+}
+
+// ParseUnitTypes parses CALLSIGN_UNIT_TYPES entries of the form Name=PREFIX[/alias...], e.g.
+// "Battalion=B/Italian". Names and aliases are single words; a word may only appear once.
+func ParseUnitTypes(entries []string) ([]UnitType, error) {
+	var types []UnitType
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("unit type %q: want Name=PREFIX[/alias...]", entry)
+		}
+		parts := strings.Split(rest, "/")
+		ut := UnitType{Name: strings.TrimSpace(name), Prefix: strings.ToUpper(strings.TrimSpace(parts[0]))}
+		if !isLetters(ut.Prefix) {
+			return nil, fmt.Errorf("unit type %q: prefix must be letters", entry)
+		}
+		for _, alias := range parts[1:] {
+			ut.Aliases = append(ut.Aliases, strings.TrimSpace(alias))
+		}
+		for _, word := range append([]string{ut.Name}, ut.Aliases...) {
+			if !isLetters(word) {
+				return nil, fmt.Errorf("unit type %q: %q must be a single word", entry, word)
+			}
+			if other, dup := seen[strings.ToLower(word)]; dup {
+				return nil, fmt.Errorf("unit type %q: %q is already used by %s", entry, word, other)
+			}
+			seen[strings.ToLower(word)] = ut.Name
+		}
+		types = append(types, ut)
+	}
+	if len(types) == 0 {
+		return nil, errors.New("no unit types")
+	}
+	return types, nil
+}
+
+// Substitution is one Normalize rewrite.
+type Substitution struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Normalizer rewrites unit callsigns. Immutable; safe for concurrent use.
+type Normalizer struct {
+	types  []UnitType
+	byWord map[string]*UnitType // lowercase name or alias → type
+}
+
+// NewNormalizer builds a Normalizer over the given unit-type table.
+func NewNormalizer(types []UnitType) *Normalizer {
+	n := &Normalizer{types: types, byWord: make(map[string]*UnitType)}
+	for i := range n.types {
+		ut := &n.types[i]
+		for _, word := range append([]string{ut.Name}, ut.Aliases...) {
+			n.byWord[strings.ToLower(word)] = ut
+		}
+	}
+	return n
+}
+
+// Normalize returns text with its unit callsigns canonicalized, and the rewrites made in order.
+// roster is the CAD unit list for the call (nil when enrichment is off); it is what lets a bare
+// number become a callsign, and it must confirm any unit-type word that is only a near-miss of
+// the table ("Battalian 171" → "Battalion 171" only when B171 is assigned).
+func (n *Normalizer) Normalize(text string, roster []cad.Unit) (string, []Substitution) {
+	tokens := tokenize(text)
+	units := newRoster(roster)
+	var (
+		out  strings.Builder
+		subs []Substitution
+		last int // end of the text already copied to out
+	)
+	for i := 0; i < len(tokens); {
+		to, consumed := n.rewriteAt(text, tokens, i, units)
+		if consumed == 0 {
+			i++
+			continue
+		}
+		start, end := tokens[i].start, tokens[i+consumed-1].end
+		if from := text[start:end]; to != from {
+			out.WriteString(text[last:start])
+			out.WriteString(to)
+			subs = append(subs, Substitution{From: from, To: to})
+			last = end
+		}
+		i += consumed
+	}
+	if len(subs) == 0 {
+		return text, nil
+	}
+	out.WriteString(text[last:])
+	return out.String(), subs
+}
+
+// numberContextWords are words that introduce a number which is not a unit: a spoken "time one
+// forty two" is the elapsed call timer, "exit 181" is a freeway exit. A number right after one
+// of them, or right before a unit of measure, is left alone.
+var (
+	numberContextWords = map[string]bool{
+		"time": true, "mile": true, "milepost": true, "exit": true, "highway": true, "route": true,
+		"sr": true, "i": true, "us": true, "tac": true, "channel": true, "block": true, "elevation": true,
+	}
+	measureWords = map[string]bool{
+		"feet": true, "foot": true, "ft": true, "meters": true, "yards": true, "miles": true,
+		"degrees": true, "minutes": true, "seconds": true, "block": true,
+	}
+)
+
+// rewriteAt tries a callsign starting at tokens[i]. It returns the replacement and the number of
+// tokens it covers; consumed is 0 when nothing starts there, and the replacement equals the
+// original text when the span is recognized but should be kept (a timer, an unconfirmed guess).
+func (n *Normalizer) rewriteAt(text string, tokens []token, i int, units roster) (string, int) {
+	// "<unit type> <number>": canonical type word, digits.
+	if ut, exact := n.unitType(tokens[i].lower); ut != nil && i+1 < len(tokens) && contiguous(text, tokens[i], tokens[i+1]) {
+		if digits, count := numberAt(text, tokens, i+1); digits != "" {
+			if exact || units.has(ut.Prefix+digits) {
+				return ut.Name + " " + digits, 1 + count
+			}
+		}
+	}
+
+	digits, count := numberAt(text, tokens, i)
+	if digits == "" {
+		return "", 0
+	}
+	original := text[tokens[i].start:tokens[i+count-1].end]
+	if i > 0 && contiguous(text, tokens[i-1], tokens[i]) && (numberContextWords[tokens[i-1].lower] || n.byWord[tokens[i-1].lower] != nil || tokens[i-1].proper) {
+		return original, count
+	}
+	if end := i + count; end < len(tokens) && measureWords[tokens[end].lower] {
+		return original, count
+	}
+	if len(digits) < 3 {
+		// Short numbers are usually counts or ages ("one of them", "twenty five"), not callsigns.
+		return original, count
+	}
+	if id, ok := units.byNumber(digits); ok {
+		return id, count
+	}
+	return digits, count
+}
+
+// unitType looks word up in the table: exact names and aliases first, then a one-edit near-miss
+// of a name or alias of five letters or more with the same first letter. exact is false for
+// near-misses, which the caller only accepts when the roster confirms them.
+func (n *Normalizer) unitType(word string) (ut *UnitType, exact bool) {
+	if ut := n.byWord[word]; ut != nil {
+		return ut, true
+	}
+	if len(word) < 5 {
+		return nil, false
+	}
+	var match *UnitType
+	for known, ut := range n.byWord {
+		if len(known) < 5 || known[0] != word[0] || levenshtein.ComputeDistance(known, word) > 1 {
+			continue
+		}
+		if match != nil && match != ut {
+			return nil, false
+		}
+		match = ut
+	}
+	return match, false
+}
+
+// roster indexes the CAD units by callsign and by number.
+type roster struct {
+	ids      map[string]bool
+	byDigits map[string][]string
+}
+
+var callsignPattern = regexp.MustCompile(`^([A-Z]+)(\d+)$`)
+
+func newRoster(units []cad.Unit) roster {
+	r := roster{ids: make(map[string]bool), byDigits: make(map[string][]string)}
+	for _, u := range units {
+		id := strings.ToUpper(strings.TrimSpace(u.ID))
+		if r.ids[id] {
+			continue
+		}
+		r.ids[id] = true
+		if m := callsignPattern.FindStringSubmatch(id); m != nil {
+			r.byDigits[m[2]] = append(r.byDigits[m[2]], id)
+		}
+	}
+	return r
+}
+
+func (r roster) has(id string) bool { return r.ids[id] }
+
+// byNumber returns the one unit numbered digits. Several (A181 and E181 from the same station)
+// is ambiguous and returns ok=false.
+func (r roster) byNumber(digits string) (string, bool) {
+	if ids := r.byDigits[digits]; len(ids) == 1 {
+		return ids[0], true
+	}
+	return "", false
+}
+
+// token is one word of the input with its byte span.
+type token struct {
+	start, end int
+	lower      string
+	digits     bool // all ASCII digits
+	proper     bool // capitalized and not the first word of a sentence
+}
+
+func tokenize(text string) []token {
+	var tokens []token
+	sentenceStart := true
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		raw := text[start:end]
+		tokens = append(tokens, token{
+			start:  start,
+			end:    end,
+			lower:  strings.ToLower(raw),
+			digits: strings.Trim(raw, "0123456789") == "",
+			proper: !sentenceStart && unicode.IsUpper([]rune(raw)[0]),
+		})
+		sentenceStart = false
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+		if r == '.' || r == '!' || r == '?' {
+			sentenceStart = true
+		}
+	}
+	flush(len(text))
+	return tokens
+}
+
+// contiguous reports whether a and b are separated only by spaces or hyphens ("eighty-one"), not
+// punctuation that would join two separate phrases.
+func contiguous(text string, a, b token) bool {
+	return strings.Trim(text[a.end:b.start], " -") == ""
+}
+
+func isLetters(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	digitWords = map[string]int{
+		"zero": 0, "oh": 0, "one": 1, "two": 2, "three": 3, "four": 4,
+		"five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+	}
+	teenWords = map[string]int{
+		"ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14,
+		"fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19,
+	}
+	tensWords = map[string]int{
+		"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50,
+		"sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+	}
+)
+
+// numberAt reads the number starting at tokens[i]: a digit token, or a run of number words read
+// the way radio callsigns are spoken, group by group ("eighty one seventy one" → "8171", "one
+// eighty one" → "181", "one six one" → "161", "one hundred five" → "105"). It returns the digits
+// and the tokens consumed, or "" when tokens[i] isn't a number.
+func numberAt(text string, tokens []token, i int) (string, int) {
+	if tokens[i].digits {
+		return tokens[i].lower, 1
+	}
+	var words []string
+	for j := i; j < len(tokens) && !tokens[j].digits; j++ {
+		if j > i && !contiguous(text, tokens[j-1], tokens[j]) {
+			break
+		}
+		words = append(words, tokens[j].lower)
+	}
+	return spokenNumber(words)
+}
+
+// spokenNumber converts the leading number words of words to digits. "oh" only counts as zero
+// inside a number ("one oh one"), never to start one.
+func spokenNumber(words []string) (string, int) {
+	var b strings.Builder
+	i := 0
+	for i < len(words) {
+		w := words[i]
+		if d, ok := digitWords[w]; ok && (w != "oh" || i > 0) {
+			if i+1 < len(words) && words[i+1] == "hundred" {
+				rest, n := belowHundred(words[i+2:])
+				if n == 0 {
+					fmt.Fprintf(&b, "%d00", d)
+				} else {
+					fmt.Fprintf(&b, "%d%02d", d, rest)
+				}
+				i += 2 + n
+				continue
+			}
+			b.WriteString(strconv.Itoa(d))
+			i++
+			continue
+		}
+		v, n := belowHundred(words[i:])
+		if n == 0 || v < 10 {
+			break
+		}
+		b.WriteString(strconv.Itoa(v))
+		i += n
+	}
+	return b.String(), i
+}
+
+// belowHundred reads one value under 100 from the front of words: a teen, a tens word with an
+// optional digit ("eighty one"), or a single digit. n is the words consumed (0 when none).
+func belowHundred(words []string) (v, n int) {
+	if len(words) == 0 {
+		return 0, 0
+	}
+	if t, ok := teenWords[words[0]]; ok {
+		return t, 1
+	}
+	if t, ok := tensWords[words[0]]; ok {
+		if len(words) > 1 {
+			if d, ok := digitWords[words[1]]; ok && d > 0 {
+				return t + d, 2
+			}
+		}
+		return t, 1
+	}
+	if d, ok := digitWords[words[0]]; ok && words[0] != "oh" {
+		return d, 1
+	}
+	return 0, 0
+}
@@ -0,0 +1,95 @@
+package callsign
+
+import (
+	"testing"
+
+	"github.com/searchandrescuegg/transcribe/internal/cad"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNormalizer(t *testing.T) *Normalizer {
+	t.Helper()
+	types, err := ParseUnitTypes([]string{"Aid=A", "Medic=M", "Engine=E", "Ladder=L", "Battalion=B/Italian"})
+	require.NoError(t, err)
+	return NewNormalizer(types)
+}
+
+func TestParseUnitTypes(t *testing.T) {
+	types, err := ParseUnitTypes([]string{" Battalion=b/Italian/Batallion ", "", "Aid=A"})
+	require.NoError(t, err)
+	assert.Equal(t, []UnitType{
+		{Name: "Battalion", Prefix: "B", Aliases: []string{"Italian", "Batallion"}},
+		{Name: "Aid", Prefix: "A"},
+	}, types)
+
+	for name, entries := range map[string][]string{
+		"empty":          nil,
+		"no prefix":      {"Aid"},
+		"numeric prefix": {"Aid=1"},
+		"two words":      {"Fire Engine=E"},
+		"duplicate word": {"Aid=A", "Medic=M/aid"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseUnitTypes(entries)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	n := testNormalizer(t)
+	roster := []cad.Unit{{ID: "A8171"}, {ID: "B171"}, {ID: "A181"}, {ID: "E181"}}
+
+	cases := []struct {
+		name, in, want string
+		roster         []cad.Unit
+	}{
+		{"garbled type word", "Italian one seventy one on scene", "Battalion 171 on scene", nil},
+		{"spoken number after type", "aid one eighty one en route", "Aid 181 en route", nil},
+		{"digits after type", "Medic 104 is transporting", "Medic 104 is transporting", nil},
+		{"oh inside a number", "Engine one oh one", "Engine 101", nil},
+		{"hundreds", "Ladder one hundred five", "Ladder 105", nil},
+		{"bare number on the roster", "eighty one seventy one, copy", "A8171, copy", roster},
+		{"bare digits on the roster", "8171 copies", "A8171 copies", roster},
+		{"bare number off the roster", "eighty one seventy one, copy", "8171, copy", nil},
+		{"ambiguous roster number", "one eighty one copies", "181 copies", roster},
+		{"near-miss confirmed by the roster", "Battalian 171 is command", "Battalion 171 is command", roster},
+		{"several callsigns", "Italian one seventy one to aid eighty one seventy one", "Battalion 171 to Aid 8171", nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, subs := n.Normalize(tc.in, tc.roster)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.in != tc.want, len(subs) > 0)
+		})
+	}
+}
+
+func TestNormalize_LeavesTextAlone(t *testing.T) {
+	n := testNormalizer(t)
+	roster := []cad.Unit{{ID: "A142"}, {ID: "B181"}, {ID: "M1"}}
+
+	for _, text := range []string{
+		"one of them is walking out",
+		"patient is twenty five years old",
+		"Time one forty two",
+		"taking exit 181",
+		"about one hundred eighty one feet below the trail",
+		"Battalian 171 is command", // near-miss without roster confirmation
+		"first aid, one patient",   // punctuation breaks the callsign
+		"TAC two",
+	} {
+		got, subs := n.Normalize(text, roster)
+		assert.Equal(t, text, got, text)
+		assert.Empty(t, subs, text)
+	}
+}
+
+func TestNormalize_Substitutions(t *testing.T) {
+	_, subs := testNormalizer(t).Normalize("Italian one seventy one, eighty one seventy one", []cad.Unit{{ID: "A8171"}})
+	assert.Equal(t, []Substitution{
+		{From: "Italian one seventy one", To: "Battalion 171"},
+		{From: "eighty one seventy one", To: "A8171"},
+	}, subs)
+}
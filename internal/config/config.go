@@ -55,6 +55,14 @@ type Config struct {
 	S3Endpoint  string        `env:"S3_ENDPOINT"`
 	S3Timeout   time.Duration `env:"S3_TIMEOUT" envDefault:"10s"` // Timeout for S3 requests in seconds
 
+	// S3MaxObjectSize (bytes) caps how much of a recording a worker will pull. Checked against
+	// Content-Length before the download and against bytes read during it; oversized objects
+	// are logged and acked (not nacked — redelivery can't shrink them). Recordings stream
+	// from S3 straight into the ASR request, so S3Timeout bounds the upload leg of the ASR
+	// call as well as the GET. The default (50 MiB) is ~55 minutes of 8 kHz mono PCM. <= 0
+	// disables the guard.
+	S3MaxObjectSize int64 `env:"S3_MAX_OBJECT_SIZE" envDefault:"52428800"`
+
 	ASREndpoint string        `env:"ASR_ENDPOINT" envDefault:"http://localhost:8080/asr"`
 	ASRTimeout  time.Duration `env:"ASR_TIMEOUT" envDefault:"10s"` // Timeout for ASR requests in seconds
 
@@ -87,6 +95,58 @@ type Config struct {
 	AnthropicTimeout      time.Duration `env:"ANTHROPIC_TIMEOUT" envDefault:"30s"`
 	AnthropicMaxTokens    int64         `env:"ANTHROPIC_MAX_TOKENS" envDefault:"2048"`
 
+	// MLFallbackBackend ("openai" or "anthropic", not the same as MLBackend) runs a call when the
+	// primary backend errors or takes longer than MLPrimaryTimeout, for the capabilities listed in
+	// MLFallbackCapabilities ("dispatch" also covers the re-page comparison, "summary" the
+	// after-action report). MLPrimaryTimeout must leave the fallback room inside WorkerTimeout.
+	//
+	// MLConsensusEnabled (requires MLFallbackBackend) runs the dispatch parse on both backends in
+	// parallel and only alerts when they find the same trail-rescue call type; disagreements are
+	// logged and, when MLConsensusAuditChannelID is set, posted there. If one backend fails the
+	// other's answer is used alone.
+	MLFallbackBackend         string        `env:"ML_FALLBACK_BACKEND"`
+	MLFallbackCapabilities    []string      `env:"ML_FALLBACK_CAPABILITIES" envDefault:"dispatch,summary,cleanup" envSeparator:","`
+	MLPrimaryTimeout          time.Duration `env:"ML_PRIMARY_TIMEOUT" envDefault:"15s"`
+	MLConsensusEnabled        bool          `env:"ML_CONSENSUS_ENABLED" envDefault:"false"`
+	MLConsensusAuditChannelID string        `env:"ML_CONSENSUS_AUDIT_CHANNEL_ID"`
+
+	// MLShadowEnabled (requires DatasetEnabled) evaluates a candidate model on live traffic: a
+	// MLShadowSampleRate fraction of the calls in MLShadowKinds is replayed in the background on
+	// MLShadowModel (on MLShadowBackend, default MLBackend) and recorded in the dataset linked to
+	// the live call. The candidate's answers are never acted on; cmd/shadow-report scores them.
+	MLShadowEnabled     bool          `env:"ML_SHADOW_ENABLED" envDefault:"false"`
+	MLShadowBackend     string        `env:"ML_SHADOW_BACKEND"`
+	MLShadowModel       string        `env:"ML_SHADOW_MODEL"`
+	MLShadowSampleRate  float64       `env:"ML_SHADOW_SAMPLE_RATE" envDefault:"0.1"`
+	MLShadowKinds       []string      `env:"ML_SHADOW_KINDS" envDefault:"dispatch_parse,rescue_summary" envSeparator:","`
+	MLShadowTimeout     time.Duration `env:"ML_SHADOW_TIMEOUT" envDefault:"60s"`
+	MLShadowMaxInFlight int           `env:"ML_SHADOW_MAX_IN_FLIGHT" envDefault:"4"`
+
+	// LLMCacheEnabled answers byte-identical model calls (Pulsar redeliveries, replay runs,
+	// re-pages) from Dragonfly for LLMCacheTTL instead of calling the model again. Entries are
+	// keyed by backend, model, system-prompt hash and input hash, so changing any of them misses.
+	// Rescue summaries that extend a previous summary always reach the model.
+	LLMCacheEnabled bool          `env:"LLM_CACHE_ENABLED" envDefault:"false"`
+	LLMCacheTTL     time.Duration `env:"LLM_CACHE_TTL" envDefault:"24h"`
+
+	// LLMUsageTrackingEnabled (requires Dragonfly, which is always configured) records the
+	// tokens every model response reports: as llm_usage.* metrics, per incident in Dragonfly
+	// for LLMUsageRetention, and in the dataset when it is enabled. LLMPrices adds an estimated
+	// cost for the models it lists, as "model=<input>/<output>" USD per million tokens
+	// separated by commas ("=" because Ollama model names contain colons).
+	//
+	// LLMDailyTokenBudget (0 = off) caps the day's tokens, in DISPLAY_TIMEZONE. Once spent, the
+	// rescue summarizer either runs on LLMBudgetSummaryModel (LLMBudgetAction "downgrade") or
+	// at most once per incident per LLMBudgetSummaryInterval ("throttle"). Everything else keeps
+	// running as normal.
+	LLMUsageTrackingEnabled  bool              `env:"LLM_USAGE_TRACKING_ENABLED" envDefault:"false"`
+	LLMUsageRetention        time.Duration     `env:"LLM_USAGE_RETENTION" envDefault:"168h"`
+	LLMPrices                map[string]string `env:"LLM_PRICES" envKeyValSeparator:"="`
+	LLMDailyTokenBudget      int64             `env:"LLM_DAILY_TOKEN_BUDGET" envDefault:"0"`
+	LLMBudgetAction          string            `env:"LLM_BUDGET_ACTION" envDefault:"downgrade"`
+	LLMBudgetSummaryModel    string            `env:"LLM_BUDGET_SUMMARY_MODEL"`
+	LLMBudgetSummaryInterval time.Duration     `env:"LLM_BUDGET_SUMMARY_INTERVAL" envDefault:"5m"`
+
 	// TACCleanupEnabled turns on the per-transmission LLM cleanup pass: every TAC transmission
 	// is rewritten by the ML backend (fixing ASR errors, place names, and unit callsigns) before
 	// it is posted to the Slack thread and fed into the live summary. Best-effort — on any error
@@ -101,12 +161,39 @@ type Config struct {
 	// worker context + the backend's own per-request timeout).
 	TACCleanupTimeout time.Duration `env:"TAC_CLEANUP_TIMEOUT" envDefault:"20s"`
 
-	// Pulpo / PulsePoint CAD enrichment (optional). When PulpoEnabled is true the service queries
-	// the dispatch API for the units assigned to the active rescue and feeds that roster into the
-	// cleanup and summary prompts so garbled unit callsigns can be canonicalized. Entirely
-	// best-effort — a slow or unavailable API degrades to empty unit context, never blocking the
-	// pipeline. PulpoBaseURL, PulpoAPIKey, and PulpoAgencyID are required when enabled.
-	// PulpoRefreshInterval doubles as the TTL of the per-rescue cached unit block, so the roster
+	// CallsignNormalizerEnabled runs a deterministic callsign pass over every TAC transmission
+	// ahead of the LLM cleanup (or instead of it, with TAC_CLEANUP_ENABLED=false): spoken numbers
+	// become digits, unit-type words are canonicalized through CallsignUnitTypes ("Italian one
+	// seventy one" → "Battalion 171"), and with PULPO_ENABLED a bare number belonging to exactly
+	// one assigned unit becomes its callsign. Every rewrite is logged.
+	CallsignNormalizerEnabled bool `env:"CALLSIGN_NORMALIZER_ENABLED" envDefault:"false"`
+	// CallsignUnitTypes is the unit-type table, one Name=PREFIX[/alias...] entry per type. The
+	// prefix is the CAD callsign prefix (B for B171); aliases are words ASR produces instead.
+	CallsignUnitTypes []string `env:"CALLSIGN_UNIT_TYPES" envDefault:"Aid=A,Medic=M,Engine=E,Ladder=L,Battalion=B/Italian" envSeparator:","`
+
+	// SplitDetectionEnabled compares every re-page onto an already-active TAC with the dispatch
+	// that started the rescue. When the two look like different incidents (location-token
+	// overlap below SplitDetectionMinOverlap and, with SplitDetectionLLMEnabled, the LLM agrees)
+	// the re-page reply in the rescue thread carries a Split button that moves the new dispatch
+	// into its own incident. Off by default: the re-page path then assumes same-incident, as
+	// before. The Split button needs Socket Mode (SLACK_APP_TOKEN).
+	//
+	// SplitDetectionMinOverlap is the fraction of the shorter dispatch's location tokens that
+	// must also appear in the other for the two to count as the same place (0–1).
+	// SplitDetectionLLMEnabled adds a structured "same incident?" call on the dispatch model
+	// to confirm a token mismatch before prompting; on LLM error the token verdict stands.
+	SplitDetectionEnabled    bool    `env:"SPLIT_DETECTION_ENABLED" envDefault:"false"`
+	SplitDetectionMinOverlap float64 `env:"SPLIT_DETECTION_MIN_OVERLAP" envDefault:"0.34"`
+	SplitDetectionLLMEnabled bool    `env:"SPLIT_DETECTION_LLM_ENABLED" envDefault:"false"`
+
+	// CAD enrichment (optional). When PulpoEnabled is true the service queries the CAD feed for
+	// the units assigned to the active rescue and feeds that roster into the cleanup and summary
+	// prompts so garbled unit callsigns can be canonicalized. Entirely best-effort — a slow or
+	// unavailable feed degrades to empty unit context, never blocking the pipeline. CADProvider
+	// picks the feed: "pulsepoint" (PulpoBaseURL, PulpoAPIKey, and PulpoAgencyID required),
+	// "http" (any JSON feed; CADHTTPURL and CADHTTPMappingFile required), or "static" (the
+	// incidents in CADStaticFile, for testing). PulpoTimeout bounds every provider's round-trip.
+	// PulpoRefreshInterval doubles as the TTL of the per-rescue cached unit context, so the roster
 	// self-refreshes as units are added over the life of the incident.
 	//
 	// PulpoUsername / PulpoPassword are the HTTP Basic auth credentials the PulsePoint API
@@ -120,6 +207,35 @@ type Config struct {
 	PulpoPassword        string        `env:"PULPO_PASSWORD"`
 	PulpoTimeout         time.Duration `env:"PULPO_TIMEOUT" envDefault:"5s"`
 	PulpoRefreshInterval time.Duration `env:"PULPO_REFRESH_INTERVAL" envDefault:"45s"`
+	CADProvider          string        `env:"CAD_PROVIDER" envDefault:"pulsepoint"`
+
+	// CADHTTPHeaders are extra request headers for the http provider as "Name:value" pairs
+	// separated by commas, e.g. an Authorization header.
+	CADHTTPURL         string            `env:"CAD_HTTP_URL"`
+	CADHTTPMappingFile string            `env:"CAD_HTTP_MAPPING_FILE"`
+	CADHTTPHeaders     map[string]string `env:"CAD_HTTP_HEADERS"`
+	CADStaticFile      string            `env:"CAD_STATIC_FILE"`
+
+	// CADPollEnabled (requires PulpoEnabled) lists active CAD incidents every CADPollInterval
+	// and posts a "CAD shows a rescue, no radio dispatch detected yet" alert for any whose call
+	// type contains one of CADPollCallTypes and that no radio dispatch has matched CADPollGrace
+	// after CAD received it. CADPollCallTypes is deliberately narrower than the enrichment hints
+	// (which include MEDICAL): every match here is a top-level alert.
+	CADPollEnabled   bool          `env:"CAD_POLL_ENABLED" envDefault:"false"`
+	CADPollInterval  time.Duration `env:"CAD_POLL_INTERVAL" envDefault:"30s"`
+	CADPollGrace     time.Duration `env:"CAD_POLL_GRACE" envDefault:"3m"`
+	CADPollCallTypes []string      `env:"CAD_POLL_CALL_TYPES" envDefault:"RESCUE" envSeparator:","`
+
+	// CADUnitTimelineEnabled (requires PulpoEnabled) posts the matched CAD incident's unit
+	// status changes in the rescue thread as they are seen on each unit-context refresh ("E171
+	// Dispatched → En Route → On Scene") and adds a CAD units table to the live interpretation.
+	CADUnitTimelineEnabled bool `env:"CAD_UNIT_TIMELINE_ENABLED" envDefault:"false"`
+
+	// CADMatchDetailsEnabled (requires PulpoEnabled) shows which CAD incident a rescue was
+	// matched to, and why, on the live interpretation: the top candidates with their scores and
+	// shared location words, plus a dropdown to pin a different incident. The pin needs the
+	// Slack controller (SLACK_APP_TOKEN) and lasts for the rescue.
+	CADMatchDetailsEnabled bool `env:"CAD_MATCH_DETAILS_ENABLED" envDefault:"false"`
 
 	// Dataset capture (optional). When DatasetEnabled is true the service records every ASR
 	// transcription and LLM interaction to Postgres for offline prompt refinement. Capture is
@@ -163,6 +279,97 @@ type Config struct {
 	// the active_tacs ZSET for due "channel closed" notifications.
 	TACSweeperInterval time.Duration `env:"TAC_SWEEPER_INTERVAL" envDefault:"5s"`
 
+	// Inactivity-based closure policy. Each knob is independent and zero disables it, leaving
+	// the fixed activation window (dispatch or last Extend + TACTICAL_CHANNEL_ACTIVATION_DURATION).
+	//
+	// TACIdleTimeout: the auto-close follows the traffic. The dispatch's activation window is
+	// the first window; from the first transmission on, each one reschedules the close to now +
+	// this, earlier or later, so a channel that goes quiet closes this long after its last
+	// transmission. Windows someone asked for (re-page, Extend, Keep) are held: traffic doesn't
+	// pull the close in before they end.
+	//
+	// TACMaxLifetime: nothing keeps a rescue open past dispatch + this — not the first window,
+	// traffic, re-pages, Extend, Switch or Keep. Works on its own.
+	//
+	// TACClosingWarning: posts a "closing soon" thread reply this long before the auto-close,
+	// once per scheduled closure time (a push or an Extend re-arms it).
+	TACIdleTimeout    time.Duration `env:"TAC_IDLE_TIMEOUT" envDefault:"0"`
+	TACMaxLifetime    time.Duration `env:"TAC_MAX_LIFETIME" envDefault:"0"`
+	TACClosingWarning time.Duration `env:"TAC_CLOSING_WARNING" envDefault:"0"`
+
+	// OutcomeCloseEnabled acts on the live summary's Outcome: the first time it reports a
+	// terminal outcome ("Resolved — …", "Cancelled en route", "False alarm"), the service posts
+	// a "Looks resolved — close monitoring now?" thread prompt with Close and Keep buttons and
+	// pulls the auto-close in to OutcomeCloseGrace from now. Nobody answering lets the sweeper
+	// close it as a normal auto-close; Keep restores the previous schedule. TACIdleTimeout
+	// doesn't move the grace close until Keep is pressed.
+	OutcomeCloseEnabled bool          `env:"OUTCOME_CLOSE_ENABLED" envDefault:"false"`
+	OutcomeCloseGrace   time.Duration `env:"OUTCOME_CLOSE_GRACE" envDefault:"10m"`
+
+	// LiveInterpretationStreamingEnabled streams the live summary instead of waiting for the
+	// whole structured response: as the model finishes the headline and each new key event, the
+	// Live Interpretation message is chat.update'd with them, marked as still updating, and the
+	// finished summary replaces it as before. Escalations, the SAR badge and the close prompt
+	// still act on the finished summary only.
+	//
+	// LiveInterpretationStreamInterval is the minimum gap between streamed updates of one
+	// message. chat.update is rate limited per workspace, so keep it at a second or more; the
+	// finished summary is never held back by it.
+	LiveInterpretationStreamingEnabled bool          `env:"LIVE_INTERPRETATION_STREAMING_ENABLED" envDefault:"false"`
+	LiveInterpretationStreamInterval   time.Duration `env:"LIVE_INTERPRETATION_STREAM_INTERVAL" envDefault:"2s"`
+
+	// LiveInterpretationCompactionEnabled bounds what each live summary pass sends. Normally
+	// every pass sends the whole TAC log plus the previous summary, which on a multi-hour rescue
+	// grows until the call times out. With compaction, once the transmissions not yet folded in
+	// exceed LiveInterpretationCompactMaxTransmissions or roughly
+	// LiveInterpretationCompactMaxTokens (either limit 0 = unused), the ones the last summary
+	// already covers are folded into it and only the transmissions since are sent. The full log
+	// is kept for the after-action report.
+	LiveInterpretationCompactionEnabled       bool `env:"LIVE_INTERPRETATION_COMPACTION_ENABLED" envDefault:"false"`
+	LiveInterpretationCompactMaxTransmissions int  `env:"LIVE_INTERPRETATION_COMPACT_MAX_TRANSMISSIONS" envDefault:"40"`
+	LiveInterpretationCompactMaxTokens        int  `env:"LIVE_INTERPRETATION_COMPACT_MAX_TOKENS" envDefault:"8000"`
+
+	// AfterActionReportEnabled runs one more, dedicated LLM pass when a rescue closes (auto-close
+	// or Close; not Cancel) and writes an after-action report: timeline, units, duration, patient
+	// outcome, SAR involvement and radio issues. The report is posted in the rescue thread and,
+	// when S3 is configured, exported as Markdown and JSON under AfterActionReportS3Prefix
+	// (<prefix><YYYY>/<MM>/<incident>.md|.json).
+	//
+	// AfterActionReportTimeout bounds the model call. The sweeper snapshots the transcripts and
+	// hands them to a background goroutine, so a slow backend never holds up later closures; it
+	// only bounds how long a report can keep one of the few generation slots.
+	AfterActionReportEnabled  bool          `env:"AFTER_ACTION_REPORT_ENABLED" envDefault:"false"`
+	AfterActionReportTimeout  time.Duration `env:"AFTER_ACTION_REPORT_TIMEOUT" envDefault:"60s"`
+	AfterActionReportS3Prefix string        `env:"AFTER_ACTION_REPORT_S3_PREFIX" envDefault:"reports/"`
+
+	// DigestEnabled posts a shift digest: at each DigestTimes wall-clock time (local time,
+	// "HH:MM"), a compact list of every incident that closed since the previous digest time,
+	// with a CSV of the same rows in the thread. Posted to DigestChannelID (defaults to
+	// SLACK_CHANNEL_ID). One replica posts each digest; the others skip it.
+	//
+	// Enabling it makes closures (auto-close, Close and Cancel) keep a small incident record —
+	// headline, outcome, times, SAR flag, false-alarm flag — for DigestRetention after the
+	// sweeper deletes everything else. Retention must cover the longest gap between digests.
+	DigestEnabled   bool          `env:"DIGEST_ENABLED" envDefault:"false"`
+	DigestTimes     []string      `env:"DIGEST_TIMES" envDefault:"07:00" envSeparator:","`
+	DigestChannelID string        `env:"DIGEST_CHANNEL_ID"`
+	DigestRetention time.Duration `env:"DIGEST_RETENTION" envDefault:"72h"`
+
+	// GazetteerFile replaces the bundled place-name list (internal/gazetteer/places.json, King
+	// County) that is rendered into the dispatch, summary and cleanup prompts and backs the
+	// snap pre-pass and map links. PlaceNameSnapEnabled runs the deterministic pre-pass that
+	// rewrites obvious near-misses of listed names ("Mount Sai" → "Mount Si") in dispatch and
+	// TAC text before the model sees it; every substitution is logged.
+	GazetteerFile        string `env:"GAZETTEER_FILE"`
+	PlaceNameSnapEnabled bool   `env:"PLACE_NAME_SNAP_ENABLED" envDefault:"false"`
+
+	// MapLinksEnabled resolves the structured location the dispatch parser and summarizer
+	// extract (trailhead, landmark, address, spoken coordinates) against the gazetteer and
+	// adds a map button to the alert and the live interpretation. MapLinkProvider is
+	// "caltopo" or "osm".
+	MapLinksEnabled bool   `env:"MAP_LINKS_ENABLED" envDefault:"false"`
+	MapLinkProvider string `env:"MAP_LINK_PROVIDER" envDefault:"caltopo"`
+
 	// FIX (review item #11): TTL for the per-S3-object dedup key used to suppress duplicate
 	// processing on Pulsar redelivery. Should comfortably exceed the worst-case end-to-end latency.
 	DedupTTL time.Duration `env:"DEDUP_TTL" envDefault:"1h"`
@@ -172,6 +379,76 @@ type Config struct {
 	SlackTimeout                       time.Duration `env:"SLACK_TIMEOUT" envDefault:"5s"`                             // Timeout for Slack API requests in seconds
 	SlackChannelClosedBroadcastEnabled bool          `env:"SLACK_CHANNEL_CLOSED_BROADCAST_ENABLED" envDefault:"false"` // Whether to broadcast channel closed messages
 
+	// SlackSendMaxAttempts bounds the synchronous send path (the initial rescue alert and the
+	// first live-interpretation post, whose ts we need back immediately). Rate limits honour
+	// Slack's Retry-After; 5xx and network errors back off exponentially. Anything else
+	// (channel_not_found, invalid_blocks, …) fails on the first attempt.
+	SlackSendMaxAttempts int `env:"SLACK_SEND_MAX_ATTEMPTS" envDefault:"3"`
+
+	// Durable outbound Slack queue (opt-in). When enabled, fire-and-forget Slack calls —
+	// thread replies, live-interpretation chat.updates, SAR badges, closure rewrites and the
+	// channel-closed notice — are appended to a Dragonfly stream instead of being sent inline.
+	// A sender goroutine drains the stream through a consumer group, spaces calls per channel
+	// (SlackQueueChannelInterval), retries 5xx / network errors with exponential backoff up to
+	// SlackQueueMaxAttempts, and drops chat.updates that a newer update for the same message ts
+	// has already superseded. Entries left pending by a crashed replica are reclaimed after
+	// SlackQueueClaimIdle. Delivery is at-least-once: a crash between send and ack re-sends.
+	SlackQueueEnabled         bool          `env:"SLACK_QUEUE_ENABLED" envDefault:"false"`
+	SlackQueueStream          string        `env:"SLACK_QUEUE_STREAM" envDefault:"slack_outbound"`
+	SlackQueueMaxLen          int64         `env:"SLACK_QUEUE_MAX_LEN" envDefault:"10000"`
+	SlackQueueChannelInterval time.Duration `env:"SLACK_QUEUE_CHANNEL_INTERVAL" envDefault:"1s"`
+	SlackQueueMaxAttempts     int           `env:"SLACK_QUEUE_MAX_ATTEMPTS" envDefault:"10"`
+	SlackQueueMaxBackoff      time.Duration `env:"SLACK_QUEUE_MAX_BACKOFF" envDefault:"1m"`
+	SlackQueueClaimIdle       time.Duration `env:"SLACK_QUEUE_CLAIM_IDLE" envDefault:"2m"`
+
+	// SlackAudioMode attaches each TAC transmission's original audio to its thread reply so a
+	// garbled word can be checked without scrubbing OpenMHz. Empty (default) disables it.
+	//   upload  — the reply is posted via files.uploadV2 with the clip attached (bot needs the
+	//             files:write scope). SlackAudioUploadFormat picks the payload: "wav" sends the
+	//             capture as-is, "wav-downsampled" resamples it in-process to 8 kHz mono PCM WAV
+	//             (narrowband radio loses nothing; wideband or stereo captures shrink 2-12x).
+	//             Neither is a compressed codec: Opus/MP3 would need a cgo or ffmpeg encoder.
+	//             An upload failure falls back to the plain text reply.
+	//   presign — the reply gains a "Play Clip" button linking to a presigned S3 GET URL that
+	//             expires after SlackAudioPresignTTL (SigV4 caps this at 7 days). No extra
+	//             Slack scope; listeners need network reach to the S3 endpoint.
+	SlackAudioMode         string        `env:"SLACK_AUDIO_MODE"`
+	SlackAudioUploadFormat string        `env:"SLACK_AUDIO_UPLOAD_FORMAT" envDefault:"wav"`
+	SlackAudioPresignTTL   time.Duration `env:"SLACK_AUDIO_PRESIGN_TTL" envDefault:"24h"`
+
+	// NotifySinks is a JSON array of secondary notification sinks that receive the incident
+	// lifecycle (alert, thread_reply, live_interpretation, closed) alongside the primary Slack
+	// channel — Teams Adaptive Cards for mutual-aid partners, signed JSON webhooks for the CAD
+	// vendor, Slack incoming webhooks for partner workspaces. See notify.SinkSpec for the
+	// shape. Every event reaches the primary Slack channel first, through the same fan-out;
+	// the sinks follow once the Slack write is through. Empty (default) leaves Slack the only
+	// sink. Each delivery is bounded by NotifyTimeout and failures are logged only; a broken
+	// sink never affects the primary Slack thread.
+	NotifySinks   string        `env:"NOTIFY_SINKS"`
+	NotifyTimeout time.Duration `env:"NOTIFY_TIMEOUT" envDefault:"5s"`
+
+	// EscalationRules is a JSON array of rules that page people outside Slack when an active
+	// rescue crosses a threshold: sar_notified, active_for (with "after", a Go duration) and
+	// patient_status (with "match" substrings). See escalation.Rule. Each rule pages at most
+	// once per rescue. Empty (default) disables escalation.
+	//
+	// EscalationTransport picks the pager: "twilio" sends SMS via a Twilio-compatible Messages
+	// API (EscalationTwilioBaseURL lets you point at a compatible provider), "smtp" sends one
+	// email to every recipient (works with email-to-pager gateways). EscalationTo lists the
+	// recipients — E.164 phone numbers for twilio, email addresses for smtp.
+	EscalationRules            string        `env:"ESCALATION_RULES"`
+	EscalationTransport        string        `env:"ESCALATION_TRANSPORT"`
+	EscalationTo               []string      `env:"ESCALATION_TO" envSeparator:","`
+	EscalationTimeout          time.Duration `env:"ESCALATION_TIMEOUT" envDefault:"10s"`
+	EscalationTwilioBaseURL    string        `env:"ESCALATION_TWILIO_BASE_URL" envDefault:"https://api.twilio.com"`
+	EscalationTwilioAccountSID string        `env:"ESCALATION_TWILIO_ACCOUNT_SID"`
+	EscalationTwilioAuthToken  string        `env:"ESCALATION_TWILIO_AUTH_TOKEN"`
+	EscalationTwilioFrom       string        `env:"ESCALATION_TWILIO_FROM"`
+	EscalationSMTPAddr         string        `env:"ESCALATION_SMTP_ADDR"`
+	EscalationSMTPUsername     string        `env:"ESCALATION_SMTP_USERNAME"`
+	EscalationSMTPPassword     string        `env:"ESCALATION_SMTP_PASSWORD"`
+	EscalationSMTPFrom         string        `env:"ESCALATION_SMTP_FROM"`
+
 	// Socket Mode + interactivity (leave SlackAppToken empty to disable).
 	//
 	// SlackAppToken is the app-level token (xapp-...) generated in your Slack app's "Basic
@@ -8,15 +8,20 @@
 //   - LLM interactions are recorded by RecordingMLClient, a transparent decorator that wraps
 //     any transcribe.MLClient and logs each dispatch-parse / rescue-summary call's
 //     input, structured output (or error), model, and system-prompt hash.
+//
+// ShadowMLClient builds on the second surface to evaluate a candidate model on live traffic:
+// sampled calls are replayed on the candidate and its answers recorded, linked to the live ones.
 package dataset
 
 import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/cespare/xxhash/v2"
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
 	"github.com/searchandrescuegg/transcribe/internal/ml"
 	"github.com/searchandrescuegg/transcribe/internal/prompts"
 )
@@ -43,6 +48,14 @@ type LLMInteractionRecord struct {
 	Output     json.RawMessage // structured result; nil when the call errored
 	Err        string          // non-empty when the call failed
 	LatencyMS  int64
+
+	// InteractionID is set on a call sampled for shadowing, and ShadowOf on the candidate
+	// model's answer to it, pointing back at that ID. Both are empty otherwise.
+	InteractionID string
+	ShadowOf      string
+
+	// Usage is the tokens the call was billed for; nil when the backend reported none.
+	Usage *ml.Usage
 }
 
 // Recorder is the sink the pipeline writes to. Implementations MUST be non-blocking and
@@ -75,6 +88,25 @@ func SourceFromContext(ctx context.Context) Source {
 	return s
 }
 
+type linkKey struct{}
+
+// link is the shadow-evaluation identity of a call; see LLMInteractionRecord.
+type link struct {
+	InteractionID string
+	ShadowOf      string
+}
+
+// contextWithLink stamps the identity the recording decorator writes for the next call. The
+// shadow decorator uses it; nothing else needs to.
+func contextWithLink(ctx context.Context, l link) context.Context {
+	return context.WithValue(ctx, linkKey{}, l)
+}
+
+func linkFromContext(ctx context.Context) link {
+	l, _ := ctx.Value(linkKey{}).(link)
+	return l
+}
+
 // --- recording decorator ---------------------------------------------------------------
 
 // mlClient is the capability contract the decorator wraps. It is structurally identical
@@ -84,15 +116,18 @@ type mlClient interface {
 	ml.DispatchMessageParser
 	ml.RescueSummarizer
 	ml.TranscriptCleaner
+	ml.IncidentComparer
+	ml.AfterActionReporter
 }
 
 // DecoratorOptions carries the static metadata recorded alongside every LLM interaction.
 type DecoratorOptions struct {
-	Backend          string   // "anthropic" | "openai"
-	DispatchModel    string   // model that runs the dispatch parser
-	SummaryModel     string   // model that runs the rescue summarizer
-	CleanupModel     string   // model that runs the per-transmission TAC cleanup
-	AllowedCallTypes []string // needed to hash the exact dispatch system prompt in use
+	Backend          string               // "anthropic" | "openai"
+	DispatchModel    string               // model that runs the dispatch parser
+	SummaryModel     string               // model that runs the rescue summarizer
+	CleanupModel     string               // model that runs the per-transmission TAC cleanup
+	AllowedCallTypes []string             // needed to hash the exact dispatch system prompt in use
+	Places           *gazetteer.Gazetteer // likewise for the place-name reference; nil is the bundled one
 }
 
 // RecordingMLClient wraps an MLClient and records every call. It implements both
@@ -107,6 +142,8 @@ type RecordingMLClient struct {
 	dispatchPromptHash string
 	summaryPromptHash  string
 	cleanupPromptHash  string
+	comparePromptHash  string
+	reportPromptHash   string
 }
 
 // NewRecordingMLClient wraps inner so each call is logged to rec.
@@ -115,40 +152,69 @@ func NewRecordingMLClient(inner mlClient, rec Recorder, opts DecoratorOptions) *
 		inner:              inner,
 		rec:                rec,
 		opts:               opts,
-		dispatchPromptHash: hashString(prompts.DispatchSystemPrompt(opts.AllowedCallTypes)),
-		summaryPromptHash:  hashString(prompts.RescueSummarySystemPrompt),
-		cleanupPromptHash:  hashString(prompts.TACCleanupSystemPrompt),
+		dispatchPromptHash: hashString(prompts.DispatchSystemPrompt(opts.AllowedCallTypes, opts.Places)),
+		summaryPromptHash:  hashString(prompts.RescueSummarySystemPrompt(opts.Places)),
+		cleanupPromptHash:  hashString(prompts.TACCleanupSystemPrompt(opts.Places)),
+		comparePromptHash:  hashString(prompts.DispatchComparisonSystemPrompt),
+		reportPromptHash:   hashString(prompts.AfterActionSystemPrompt),
 	}
 }
 
 func (r *RecordingMLClient) ParseRelevantInformationFromDispatchMessage(ctx context.Context, transcription string) (*ml.DispatchMessages, error) {
 	start := time.Now()
+	ctx, usage := captureUsage(ctx)
 	out, err := r.inner.ParseRelevantInformationFromDispatchMessage(ctx, transcription)
-	r.record(ctx, "dispatch_parse", r.opts.DispatchModel, r.dispatchPromptHash, transcription, out, err, time.Since(start))
+	r.record(ctx, KindDispatchParse, r.opts.DispatchModel, r.dispatchPromptHash, transcription, out, err, time.Since(start), usage)
 	return out, err
 }
 
 func (r *RecordingMLClient) SummarizeRescue(ctx context.Context, input ml.RescueSummaryInput) (*ml.RescueSummary, error) {
+	return r.SummarizeRescueStream(ctx, input, nil)
+}
+
+// SummarizeRescueStream records the finished summary; the partials aren't recorded.
+func (r *RecordingMLClient) SummarizeRescueStream(ctx context.Context, input ml.RescueSummaryInput, onPartial func(*ml.RescueSummary)) (*ml.RescueSummary, error) {
 	start := time.Now()
-	out, err := r.inner.SummarizeRescue(ctx, input)
-	r.record(ctx, "rescue_summary", r.opts.SummaryModel, r.summaryPromptHash, prompts.BuildRescueSummaryUserPrompt(input), out, err, time.Since(start))
+	ctx, usage := captureUsage(ctx)
+	out, err := ml.SummarizeRescueStream(ctx, r.inner, input, onPartial)
+	r.record(ctx, KindRescueSummary, r.opts.SummaryModel, r.summaryPromptHash, prompts.BuildRescueSummaryUserPrompt(input), out, err, time.Since(start), usage)
 	return out, err
 }
 
 func (r *RecordingMLClient) CleanTACTranscript(ctx context.Context, in ml.TACCleanupInput) (*ml.TACCleanupResult, error) {
 	start := time.Now()
+	ctx, usage := captureUsage(ctx)
 	out, err := r.inner.CleanTACTranscript(ctx, in)
-	r.record(ctx, "tac_cleanup", r.opts.CleanupModel, r.cleanupPromptHash, prompts.BuildTACCleanupUserPrompt(in), out, err, time.Since(start))
+	r.record(ctx, KindTACCleanup, r.opts.CleanupModel, r.cleanupPromptHash, prompts.BuildTACCleanupUserPrompt(in), out, err, time.Since(start), usage)
+	return out, err
+}
+
+// CompareDispatches runs on the dispatch model in both backends, so it is recorded under it.
+func (r *RecordingMLClient) CompareDispatches(ctx context.Context, in ml.DispatchComparisonInput) (*ml.DispatchComparison, error) {
+	start := time.Now()
+	ctx, usage := captureUsage(ctx)
+	out, err := r.inner.CompareDispatches(ctx, in)
+	r.record(ctx, KindDispatchCompare, r.opts.DispatchModel, r.comparePromptHash, prompts.BuildDispatchComparisonUserPrompt(in), out, err, time.Since(start), usage)
+	return out, err
+}
+
+// GenerateAfterActionReport runs on the summary model in both backends, so it is recorded under it.
+func (r *RecordingMLClient) GenerateAfterActionReport(ctx context.Context, in ml.AfterActionInput) (*ml.AfterActionReport, error) {
+	start := time.Now()
+	ctx, usage := captureUsage(ctx)
+	out, err := r.inner.GenerateAfterActionReport(ctx, in)
+	r.record(ctx, KindAfterAction, r.opts.SummaryModel, r.reportPromptHash, prompts.BuildAfterActionUserPrompt(in), out, err, time.Since(start), usage)
 	return out, err
 }
 
 // record builds and enqueues an interaction record. Marshal failures degrade to a nil
 // Output rather than dropping the whole record — the input text and error are still useful.
-func (r *RecordingMLClient) record(ctx context.Context, kind, model, promptHash, input string, out any, callErr error, latency time.Duration) {
+func (r *RecordingMLClient) record(ctx context.Context, kind, model, promptHash, input string, out any, callErr error, latency time.Duration, usage *usageCapture) {
 	if r.rec == nil {
 		return
 	}
 	src := SourceFromContext(ctx)
+	l := linkFromContext(ctx)
 
 	var output json.RawMessage
 	if callErr == nil && out != nil {
@@ -173,9 +239,46 @@ func (r *RecordingMLClient) record(ctx context.Context, kind, model, promptHash,
 		Output:     output,
 		Err:        errStr,
 		LatencyMS:  latency.Milliseconds(),
+
+		InteractionID: l.InteractionID,
+		ShadowOf:      l.ShadowOf,
+
+		Usage: usage.get(),
 	})
 }
 
+// usageCapture collects the usage the wrapped backend reports for one call. A backend reports
+// once per response; the sum is kept in case one ever reports more than once.
+type usageCapture struct {
+	mu       sync.Mutex
+	usage    ml.Usage
+	reported bool
+}
+
+func captureUsage(ctx context.Context) (context.Context, *usageCapture) {
+	c := &usageCapture{}
+	return ml.WithUsageSink(ctx, func(_ context.Context, u ml.Usage) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.usage.InputTokens += u.InputTokens
+		c.usage.OutputTokens += u.OutputTokens
+		c.usage.CacheReadTokens += u.CacheReadTokens
+		c.usage.CacheWriteTokens += u.CacheWriteTokens
+		c.usage.Backend, c.usage.Model = u.Backend, u.Model
+		c.reported = true
+	}), c
+}
+
+func (c *usageCapture) get() *ml.Usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.reported {
+		return nil
+	}
+	u := c.usage
+	return &u
+}
+
 func hashString(s string) string {
 	return fmt.Sprintf("%016x", xxhash.Sum64String(s))
 }
@@ -19,6 +19,10 @@ type fakeInner struct {
 	summaryErr  error
 	cleanupOut  *ml.TACCleanupResult
 	cleanupErr  error
+	compareOut  *ml.DispatchComparison
+	compareErr  error
+	reportOut   *ml.AfterActionReport
+	reportErr   error
 }
 
 func (f *fakeInner) ParseRelevantInformationFromDispatchMessage(context.Context, string) (*ml.DispatchMessages, error) {
@@ -33,6 +37,14 @@ func (f *fakeInner) CleanTACTranscript(context.Context, ml.TACCleanupInput) (*ml
 	return f.cleanupOut, f.cleanupErr
 }
 
+func (f *fakeInner) CompareDispatches(context.Context, ml.DispatchComparisonInput) (*ml.DispatchComparison, error) {
+	return f.compareOut, f.compareErr
+}
+
+func (f *fakeInner) GenerateAfterActionReport(context.Context, ml.AfterActionInput) (*ml.AfterActionReport, error) {
+	return f.reportOut, f.reportErr
+}
+
 type fakeRecorder struct {
 	llm []LLMInteractionRecord
 }
@@ -97,6 +109,71 @@ func TestRecordingMLClient_Summary_RecordsSummaryKind(t *testing.T) {
 	assert.Contains(t, got.InputText, "dispatch text", "input records the built summary prompt")
 }
 
+// streamingInner is a fakeInner whose summarizer streams one partial before answering.
+type streamingInner struct {
+	fakeInner
+	partial  *ml.RescueSummary
+	streamed bool
+}
+
+func (f *streamingInner) SummarizeRescueStream(_ context.Context, _ ml.RescueSummaryInput, onPartial func(*ml.RescueSummary)) (*ml.RescueSummary, error) {
+	f.streamed = true
+	onPartial(f.partial)
+	return f.summaryOut, f.summaryErr
+}
+
+func TestRecordingMLClient_SummaryStream_PassesPartialsThroughAndRecordsOnce(t *testing.T) {
+	inner := &streamingInner{fakeInner: fakeInner{summaryOut: &ml.RescueSummary{Headline: "hiker down"}}, partial: &ml.RescueSummary{Headline: "hiker"}}
+	rec := &fakeRecorder{}
+	dec := NewRecordingMLClient(inner, rec, DecoratorOptions{SummaryModel: "claude-sonnet-5"})
+
+	var partials []*ml.RescueSummary
+	out, err := ml.SummarizeRescueStream(context.Background(), dec, ml.RescueSummaryInput{DispatchTranscription: "x"}, func(s *ml.RescueSummary) {
+		partials = append(partials, s)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hiker down", out.Headline)
+	assert.Equal(t, []*ml.RescueSummary{inner.partial}, partials)
+	require.Len(t, rec.llm, 1, "partials aren't recorded")
+
+	// A plain call doesn't stream.
+	inner.streamed = false
+	_, err = dec.SummarizeRescue(context.Background(), ml.RescueSummaryInput{DispatchTranscription: "x"})
+	require.NoError(t, err)
+	assert.False(t, inner.streamed)
+}
+
+// usageInner reports usage for its cleanup call, the way a backend does.
+type usageInner struct {
+	fakeInner
+	usage ml.Usage
+}
+
+func (f *usageInner) CleanTACTranscript(ctx context.Context, in ml.TACCleanupInput) (*ml.TACCleanupResult, error) {
+	ml.ReportUsage(ctx, f.usage)
+	return f.fakeInner.CleanTACTranscript(ctx, in)
+}
+
+func TestRecordingMLClient_RecordsUsageAndPassesItOut(t *testing.T) {
+	inner := &usageInner{fakeInner: fakeInner{cleanupOut: &ml.TACCleanupResult{}}, usage: ml.Usage{Backend: "anthropic", Model: "claude-haiku-4-5", InputTokens: 300, OutputTokens: 20}}
+	rec := &fakeRecorder{}
+	dec := NewRecordingMLClient(inner, rec, DecoratorOptions{CleanupModel: "claude-haiku-4-5"})
+
+	var outer []ml.Usage
+	ctx := ml.WithUsageSink(context.Background(), func(_ context.Context, u ml.Usage) { outer = append(outer, u) })
+	_, err := dec.CleanTACTranscript(ctx, ml.TACCleanupInput{Text: "x"})
+	require.NoError(t, err)
+
+	require.Len(t, rec.llm, 1)
+	assert.Equal(t, &inner.usage, rec.llm[0].Usage)
+	assert.Equal(t, []ml.Usage{inner.usage}, outer, "outer sinks still see the usage")
+
+	// A backend that reports nothing records nil.
+	_, err = dec.ParseRelevantInformationFromDispatchMessage(context.Background(), "x")
+	require.NoError(t, err)
+	assert.Nil(t, rec.llm[1].Usage)
+}
+
 func TestRecordingMLClient_Cleanup_RecordsCleanupKind(t *testing.T) {
 	inner := &fakeInner{cleanupOut: &ml.TACCleanupResult{CleanedText: "TAC2 Norway Hill Trail"}}
 	rec := &fakeRecorder{}
@@ -114,6 +191,26 @@ func TestRecordingMLClient_Cleanup_RecordsCleanupKind(t *testing.T) {
 	assert.NotNil(t, got.Output)
 }
 
+func TestRecordingMLClient_AfterAction_RecordsUnderSummaryModel(t *testing.T) {
+	inner := &fakeInner{reportOut: &ml.AfterActionReport{Headline: "Injured hiker carried out"}}
+	rec := &fakeRecorder{}
+	dec := NewRecordingMLClient(inner, rec, DecoratorOptions{Backend: "anthropic", DispatchModel: "claude-haiku-4-5", SummaryModel: "claude-sonnet-5"})
+
+	out, err := dec.GenerateAfterActionReport(context.Background(), ml.AfterActionInput{
+		DispatchTranscription: "dispatch text",
+		TACTranscripts:        []ml.TACTranscript{{CapturedAt: "13:05:00", Text: "patient contact"}},
+	})
+	require.NoError(t, err)
+	assert.Same(t, inner.reportOut, out, "result must pass through unchanged")
+
+	require.Len(t, rec.llm, 1)
+	got := rec.llm[0]
+	assert.Equal(t, "after_action", got.Kind)
+	assert.Equal(t, "claude-sonnet-5", got.Model, "after-action is recorded under the summary model")
+	assert.Contains(t, got.InputText, "patient contact", "input records the built after-action prompt")
+	assert.NotEmpty(t, got.PromptHash)
+}
+
 // pingWithBackoff must abort promptly when the context is cancelled (shutdown), not keep retrying
 // for the full pingMaxElapsedTime — otherwise a shutdown mid-startup would hang for minutes.
 func TestPingWithBackoff_RespectsCanceledContext(t *testing.T) {
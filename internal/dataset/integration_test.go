@@ -171,6 +171,33 @@ func (s *StoreSuite) TestRecordLLMInteraction_Success_StoresJSONBAndFields() {
 	s.Equal("audio/1965-tac.wav", s3Key)
 }
 
+func (s *StoreSuite) TestRecordLLMInteraction_Usage() {
+	s.store.RecordLLMInteraction(LLMInteractionRecord{
+		Kind: "dispatch_parse", Backend: "anthropic", Model: "claude-haiku-4-5", InputText: "with usage",
+		Usage: &ml.Usage{InputTokens: 812, OutputTokens: 64, CacheReadTokens: 4096, CacheWriteTokens: 0},
+	})
+	s.store.RecordLLMInteraction(LLMInteractionRecord{
+		Kind: "dispatch_parse", Backend: "openai", Model: "gpt-4.1-mini", InputText: "without usage",
+	})
+	s.eventuallyCount(2, "SELECT count(*) FROM llm_interactions WHERE kind = $1", "dispatch_parse")
+
+	var input, output, cacheRead, cacheWrite sql.NullInt64
+	err := s.rawDB.QueryRowContext(s.ctx,
+		"SELECT input_tokens, output_tokens, cache_read_tokens, cache_write_tokens FROM llm_interactions WHERE input_text = 'with usage'",
+	).Scan(&input, &output, &cacheRead, &cacheWrite)
+	s.Require().NoError(err)
+	s.EqualValues(812, input.Int64)
+	s.EqualValues(64, output.Int64)
+	s.EqualValues(4096, cacheRead.Int64)
+	s.True(cacheWrite.Valid, "a reported zero is stored as 0, not NULL")
+
+	err = s.rawDB.QueryRowContext(s.ctx,
+		"SELECT input_tokens FROM llm_interactions WHERE input_text = 'without usage'",
+	).Scan(&input)
+	s.Require().NoError(err)
+	s.False(input.Valid, "no reported usage stores NULL")
+}
+
 func (s *StoreSuite) TestRecordLLMInteraction_Error_NullsOutputAndEmptyFields() {
 	s.store.RecordLLMInteraction(LLMInteractionRecord{
 		Kind:       "dispatch_parse",
@@ -235,3 +262,26 @@ func (s *StoreSuite) TestRecordingDecorator_WritesThroughToPostgres() {
 	s.Equal("1399", talkgroup)
 	s.NotEmpty(promptHash, "system-prompt hash must be recorded")
 }
+
+// Shadow pairs are joined on interaction_id / shadow_of, so this runs against the real schema:
+// the 00002 migration, NULL handling for unlinked rows, and the failed-candidate case.
+func (s *StoreSuite) TestLoadShadowPairs_JoinsLiveAndCandidate() {
+	live, _ := json.Marshal(ml.DispatchMessages{Messages: []ml.DispatchMessage{{CallType: "Rescue - Trail", TACChannel: "TAC3"}}})
+	s.store.RecordLLMInteraction(LLMInteractionRecord{Kind: KindDispatchParse, Backend: "anthropic", Model: "claude-haiku-4-5", InputText: "a", Output: live, InteractionID: "live-1"})
+	s.store.RecordLLMInteraction(LLMInteractionRecord{Kind: KindDispatchParse, Backend: "openai", Model: "gpt-candidate", InputText: "a", Output: live, ShadowOf: "live-1"})
+	s.store.RecordLLMInteraction(LLMInteractionRecord{Kind: KindDispatchParse, Backend: "anthropic", Model: "claude-haiku-4-5", InputText: "b", Output: live, InteractionID: "live-2"})
+	s.store.RecordLLMInteraction(LLMInteractionRecord{Kind: KindDispatchParse, Backend: "openai", Model: "gpt-candidate", InputText: "b", Err: "timeout", ShadowOf: "live-2"})
+	s.store.RecordLLMInteraction(LLMInteractionRecord{Kind: KindDispatchParse, Backend: "anthropic", Model: "claude-haiku-4-5", InputText: "c", Output: live})
+	s.eventuallyCount(5, "SELECT count(*) FROM llm_interactions")
+
+	pairs, err := LoadShadowPairs(s.ctx, s.rawDB, time.Now().Add(-time.Hour), "")
+	s.Require().NoError(err)
+	s.Require().Len(pairs, 2)
+	s.Equal("gpt-candidate", pairs[0].ShadowModel)
+	s.JSONEq(string(live), string(pairs[0].Shadow))
+	s.Nil(pairs[1].Shadow, "a failed candidate call pairs with no output")
+
+	pairs, err = LoadShadowPairs(s.ctx, s.rawDB, time.Now().Add(-time.Hour), "some-other-model")
+	s.Require().NoError(err)
+	s.Empty(pairs)
+}
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Shadow evaluation. interaction_id is minted by the service for a call that was sampled for
+-- shadowing; the candidate model's answer to the same input is recorded as its own row with
+-- shadow_of pointing back at it. Both are NULL for ordinary, unsampled calls.
+ALTER TABLE llm_interactions ADD COLUMN IF NOT EXISTS interaction_id TEXT;
+ALTER TABLE llm_interactions ADD COLUMN IF NOT EXISTS shadow_of TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_llm_interactions_interaction_id ON llm_interactions (interaction_id);
+CREATE INDEX IF NOT EXISTS idx_llm_interactions_shadow_of ON llm_interactions (shadow_of) WHERE shadow_of IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_llm_interactions_shadow_of;
+DROP INDEX IF EXISTS idx_llm_interactions_interaction_id;
+ALTER TABLE llm_interactions DROP COLUMN IF EXISTS shadow_of;
+ALTER TABLE llm_interactions DROP COLUMN IF EXISTS interaction_id;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Token usage per interaction, as the provider reported it. NULL when the backend reported none
+-- (older rows, OpenAI-compatible servers that omit usage). cache_read_tokens / cache_write_tokens
+-- are Anthropic prompt-cache tokens and are 0 for OpenAI-compatible backends.
+ALTER TABLE llm_interactions ADD COLUMN IF NOT EXISTS input_tokens INTEGER;
+ALTER TABLE llm_interactions ADD COLUMN IF NOT EXISTS output_tokens INTEGER;
+ALTER TABLE llm_interactions ADD COLUMN IF NOT EXISTS cache_read_tokens INTEGER;
+ALTER TABLE llm_interactions ADD COLUMN IF NOT EXISTS cache_write_tokens INTEGER;
+
+-- +goose Down
+ALTER TABLE llm_interactions DROP COLUMN IF EXISTS cache_write_tokens;
+ALTER TABLE llm_interactions DROP COLUMN IF EXISTS cache_read_tokens;
+ALTER TABLE llm_interactions DROP COLUMN IF EXISTS output_tokens;
+ALTER TABLE llm_interactions DROP COLUMN IF EXISTS input_tokens;
@@ -0,0 +1,164 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+)
+
+// Interaction kinds, as recorded in llm_interactions.kind and accepted in ShadowOptions.Kinds.
+const (
+	KindDispatchParse   = "dispatch_parse"
+	KindRescueSummary   = "rescue_summary"
+	KindTACCleanup      = "tac_cleanup"
+	KindDispatchCompare = "dispatch_compare"
+	KindAfterAction     = "after_action"
+)
+
+// ShadowOptions configures a ShadowMLClient.
+type ShadowOptions struct {
+	Kinds       []string      // interaction kinds to shadow (KindDispatchParse, ...)
+	SampleRate  float64       // fraction of those calls to shadow, 0–1
+	Timeout     time.Duration // bound on each shadow call
+	MaxInFlight int           // shadow calls running at once; a sampled call is skipped beyond it
+}
+
+// ShadowMLClient evaluates a candidate model on live traffic. For a sample of calls it gives the
+// live call an interaction ID and, once the live call has succeeded, replays the same input on
+// the candidate in the background. The candidate's answer is recorded with shadow_of set to
+// that ID (cmd/shadow-report compares the pairs) and is never returned or acted on.
+//
+// The live call is passed through unchanged and never waits on the candidate. Shadow calls are
+// bounded by Timeout and MaxInFlight, so a slow candidate costs samples, not pipeline latency.
+// It must wrap a RecordingMLClient, which is what records the live side of each pair.
+type ShadowMLClient struct {
+	inner  mlClient
+	shadow *RecordingMLClient
+	opts   ShadowOptions
+	kinds  map[string]bool
+	slots  chan struct{}
+	sample func() bool
+}
+
+// NewShadowMLClient shadows inner's calls on candidate, recording the candidate's answers
+// through rec under candidateOpts (the candidate's backend and models).
+func NewShadowMLClient(inner, candidate mlClient, rec Recorder, candidateOpts DecoratorOptions, opts ShadowOptions) (*ShadowMLClient, error) {
+	if opts.SampleRate < 0 || opts.SampleRate > 1 {
+		return nil, fmt.Errorf("shadow sample rate must be between 0 and 1, got %v", opts.SampleRate)
+	}
+	kinds := make(map[string]bool, len(opts.Kinds))
+	for _, k := range opts.Kinds {
+		k = strings.ToLower(strings.TrimSpace(k))
+		switch k {
+		case "":
+			continue
+		case KindDispatchParse, KindRescueSummary, KindTACCleanup, KindDispatchCompare, KindAfterAction:
+			kinds[k] = true
+		default:
+			return nil, fmt.Errorf("unknown shadow interaction kind %q", k)
+		}
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 60 * time.Second
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = 4
+	}
+	rate := opts.SampleRate
+	return &ShadowMLClient{
+		inner:  inner,
+		shadow: NewRecordingMLClient(candidate, rec, candidateOpts),
+		opts:   opts,
+		kinds:  kinds,
+		slots:  make(chan struct{}, opts.MaxInFlight),
+		sample: func() bool { return rand.Float64() < rate },
+	}, nil
+}
+
+func (s *ShadowMLClient) ParseRelevantInformationFromDispatchMessage(ctx context.Context, transcription string) (*ml.DispatchMessages, error) {
+	ctx, id := s.begin(ctx, KindDispatchParse)
+	out, err := s.inner.ParseRelevantInformationFromDispatchMessage(ctx, transcription)
+	s.replay(ctx, id, err, KindDispatchParse, func(ctx context.Context) {
+		_, _ = s.shadow.ParseRelevantInformationFromDispatchMessage(ctx, transcription)
+	})
+	return out, err
+}
+
+func (s *ShadowMLClient) SummarizeRescue(ctx context.Context, input ml.RescueSummaryInput) (*ml.RescueSummary, error) {
+	return s.SummarizeRescueStream(ctx, input, nil)
+}
+
+// SummarizeRescueStream streams the live call; the candidate's replay never streams.
+func (s *ShadowMLClient) SummarizeRescueStream(ctx context.Context, input ml.RescueSummaryInput, onPartial func(*ml.RescueSummary)) (*ml.RescueSummary, error) {
+	ctx, id := s.begin(ctx, KindRescueSummary)
+	out, err := ml.SummarizeRescueStream(ctx, s.inner, input, onPartial)
+	s.replay(ctx, id, err, KindRescueSummary, func(ctx context.Context) {
+		_, _ = s.shadow.SummarizeRescue(ctx, input)
+	})
+	return out, err
+}
+
+func (s *ShadowMLClient) CleanTACTranscript(ctx context.Context, in ml.TACCleanupInput) (*ml.TACCleanupResult, error) {
+	ctx, id := s.begin(ctx, KindTACCleanup)
+	out, err := s.inner.CleanTACTranscript(ctx, in)
+	s.replay(ctx, id, err, KindTACCleanup, func(ctx context.Context) {
+		_, _ = s.shadow.CleanTACTranscript(ctx, in)
+	})
+	return out, err
+}
+
+func (s *ShadowMLClient) CompareDispatches(ctx context.Context, in ml.DispatchComparisonInput) (*ml.DispatchComparison, error) {
+	ctx, id := s.begin(ctx, KindDispatchCompare)
+	out, err := s.inner.CompareDispatches(ctx, in)
+	s.replay(ctx, id, err, KindDispatchCompare, func(ctx context.Context) {
+		_, _ = s.shadow.CompareDispatches(ctx, in)
+	})
+	return out, err
+}
+
+func (s *ShadowMLClient) GenerateAfterActionReport(ctx context.Context, in ml.AfterActionInput) (*ml.AfterActionReport, error) {
+	ctx, id := s.begin(ctx, KindAfterAction)
+	out, err := s.inner.GenerateAfterActionReport(ctx, in)
+	s.replay(ctx, id, err, KindAfterAction, func(ctx context.Context) {
+		_, _ = s.shadow.GenerateAfterActionReport(ctx, in)
+	})
+	return out, err
+}
+
+// begin samples the call and, when it's picked, mints the interaction ID the live call is
+// recorded under. id is empty for a call that isn't shadowed.
+func (s *ShadowMLClient) begin(ctx context.Context, kind string) (context.Context, string) {
+	if !s.kinds[kind] || !s.sample() {
+		return ctx, ""
+	}
+	id := ulid.Make().String()
+	return contextWithLink(ctx, link{InteractionID: id}), id
+}
+
+// replay runs call against the candidate in the background when the live call was sampled and
+// succeeded; a failed live call has nothing to compare against. The shadow context keeps the
+// request's values (the Source) but not its deadline or cancellation: the worker moving on is
+// no reason to abandon the sample.
+func (s *ShadowMLClient) replay(ctx context.Context, id string, liveErr error, kind string, call func(context.Context)) {
+	if id == "" || liveErr != nil {
+		return
+	}
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		slog.Debug("shadow: all slots busy; skipping sample", slog.String("kind", kind))
+		return
+	}
+	go func() {
+		defer func() { <-s.slots }()
+		shadowCtx, cancel := context.WithTimeout(contextWithLink(context.WithoutCancel(ctx), link{ShadowOf: id}), s.opts.Timeout)
+		defer cancel()
+		call(shadowCtx)
+	}()
+}
@@ -0,0 +1,136 @@
+package dataset
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+)
+
+// ShadowPair is a live interaction and the candidate's answer to the same input. Shadow is nil
+// when the candidate call failed.
+type ShadowPair struct {
+	Kind        string
+	ShadowModel string
+	Live        json.RawMessage
+	Shadow      json.RawMessage
+}
+
+// LoadShadowPairs reads the pairs recorded since since, optionally only those whose candidate
+// was model. A live call that went to both backends (fallback, consensus) has more than one
+// live row; the first successful one is used.
+func LoadShadowPairs(ctx context.Context, db *sql.DB, since time.Time, model string) ([]ShadowPair, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT DISTINCT ON (s.id) s.kind, s.model, l.output, s.output
+		 FROM llm_interactions s
+		 JOIN llm_interactions l ON l.interaction_id = s.shadow_of AND l.output IS NOT NULL
+		 WHERE s.shadow_of IS NOT NULL AND s.created_at >= $1 AND ($2 = '' OR s.model = $2)
+		 ORDER BY s.id, l.id`,
+		since, model)
+	if err != nil {
+		return nil, fmt.Errorf("query shadow pairs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var pairs []ShadowPair
+	for rows.Next() {
+		var (
+			p            ShadowPair
+			live, shadow []byte
+		)
+		if err := rows.Scan(&p.Kind, &p.ShadowModel, &live, &shadow); err != nil {
+			return nil, fmt.Errorf("scan shadow pair: %w", err)
+		}
+		p.Live, p.Shadow = live, shadow
+		pairs = append(pairs, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read shadow pairs: %w", err)
+	}
+	return pairs, nil
+}
+
+// FieldAgreement counts how often the candidate matched the live model on one field.
+type FieldAgreement struct {
+	Field    string
+	Compared int
+	Agreed   int
+}
+
+// Rate is Agreed / Compared, or 0 when nothing was compared.
+func (f FieldAgreement) Rate() float64 {
+	if f.Compared == 0 {
+		return 0
+	}
+	return float64(f.Agreed) / float64(f.Compared)
+}
+
+// ShadowReport summarizes a set of ShadowPairs.
+type ShadowReport struct {
+	Pairs        map[string]int // pairs per kind
+	ShadowErrors map[string]int // pairs per kind where the candidate call failed
+	Fields       []FieldAgreement
+}
+
+// CompareShadowPairs scores the candidate against the live model on the fields that decide what
+// the service does: the dispatch call types and TAC channels (whether and where it alerts) and
+// the summary's sar_notified flag. Call types and channels are compared as sets across a
+// dispatch's messages, ignoring case and spacing. A pair whose outputs don't decode is counted
+// but not compared.
+func CompareShadowPairs(pairs []ShadowPair) ShadowReport {
+	r := ShadowReport{Pairs: map[string]int{}, ShadowErrors: map[string]int{}}
+	callType := FieldAgreement{Field: "call_type"}
+	tacChannel := FieldAgreement{Field: "tac_channel"}
+	sarNotified := FieldAgreement{Field: "sar_notified"}
+
+	for _, p := range pairs {
+		r.Pairs[p.Kind]++
+		if len(p.Shadow) == 0 {
+			r.ShadowErrors[p.Kind]++
+			continue
+		}
+		switch p.Kind {
+		case KindDispatchParse:
+			var live, shadow ml.DispatchMessages
+			if json.Unmarshal(p.Live, &live) != nil || json.Unmarshal(p.Shadow, &shadow) != nil {
+				continue
+			}
+			tally(&callType, slices.Equal(dispatchSet(live, callTypeOf), dispatchSet(shadow, callTypeOf)))
+			tally(&tacChannel, slices.Equal(dispatchSet(live, tacChannelOf), dispatchSet(shadow, tacChannelOf)))
+		case KindRescueSummary:
+			var live, shadow ml.RescueSummary
+			if json.Unmarshal(p.Live, &live) != nil || json.Unmarshal(p.Shadow, &shadow) != nil {
+				continue
+			}
+			tally(&sarNotified, live.SARNotified == shadow.SARNotified)
+		}
+	}
+	r.Fields = []FieldAgreement{callType, tacChannel, sarNotified}
+	return r
+}
+
+func tally(f *FieldAgreement, agreed bool) {
+	f.Compared++
+	if agreed {
+		f.Agreed++
+	}
+}
+
+func callTypeOf(m ml.DispatchMessage) string   { return m.CallType }
+func tacChannelOf(m ml.DispatchMessage) string { return m.TACChannel }
+
+// dispatchSet is the sorted, de-duplicated set of field across d's messages, normalized so
+// "Rescue - Trail" and "rescue -  trail" compare equal.
+func dispatchSet(d ml.DispatchMessages, field func(ml.DispatchMessage) string) []string {
+	var out []string
+	for _, m := range d.Messages {
+		out = append(out, strings.Join(strings.Fields(strings.ToLower(field(m))), " "))
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
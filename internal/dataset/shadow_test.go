@@ -0,0 +1,136 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncRecorder is fakeRecorder for the shadow tests, whose candidate calls record from their
+// own goroutine.
+type syncRecorder struct {
+	mu  sync.Mutex
+	llm []LLMInteractionRecord
+}
+
+func (r *syncRecorder) RecordTranscription(TranscriptionRecord) {}
+func (r *syncRecorder) RecordLLMInteraction(l LLMInteractionRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.llm = append(r.llm, l)
+}
+func (r *syncRecorder) Close() error { return nil }
+
+func (r *syncRecorder) records() []LLMInteractionRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]LLMInteractionRecord(nil), r.llm...)
+}
+
+func newShadow(t *testing.T, live, candidate *fakeInner, rec Recorder, sampled bool) *ShadowMLClient {
+	t.Helper()
+	s, err := NewShadowMLClient(
+		NewRecordingMLClient(live, rec, DecoratorOptions{Backend: "anthropic", DispatchModel: "claude-haiku-4-5"}),
+		candidate, rec,
+		DecoratorOptions{Backend: "openai", DispatchModel: "gpt-candidate"},
+		ShadowOptions{Kinds: []string{KindDispatchParse}, SampleRate: 1, Timeout: time.Second})
+	require.NoError(t, err)
+	s.sample = func() bool { return sampled }
+	return s
+}
+
+func TestShadowMLClient_RecordsLinkedCandidateAnswer(t *testing.T) {
+	rec := &syncRecorder{}
+	live := &fakeInner{dispatchOut: &ml.DispatchMessages{Transcription: "live"}}
+	candidate := &fakeInner{dispatchOut: &ml.DispatchMessages{Transcription: "candidate"}}
+	s := newShadow(t, live, candidate, rec, true)
+
+	out, err := s.ParseRelevantInformationFromDispatchMessage(ContextWithSource(context.Background(), "a.wav", "1399"), "raw")
+	require.NoError(t, err)
+	assert.Same(t, live.dispatchOut, out, "the live answer is the one returned")
+
+	require.Eventually(t, func() bool { return len(rec.records()) == 2 }, time.Second, 5*time.Millisecond)
+	got := rec.records()
+	liveRec, shadowRec := got[0], got[1]
+	assert.Equal(t, "claude-haiku-4-5", liveRec.Model)
+	assert.NotEmpty(t, liveRec.InteractionID)
+	assert.Empty(t, liveRec.ShadowOf)
+	assert.Equal(t, "gpt-candidate", shadowRec.Model)
+	assert.Equal(t, liveRec.InteractionID, shadowRec.ShadowOf)
+	assert.Empty(t, shadowRec.InteractionID)
+	assert.Equal(t, "a.wav", shadowRec.S3Key, "the source carries over to the shadow call")
+}
+
+func TestShadowMLClient_UnsampledCallIsUntouched(t *testing.T) {
+	rec := &syncRecorder{}
+	s := newShadow(t, &fakeInner{dispatchOut: &ml.DispatchMessages{}}, &fakeInner{}, rec, false)
+
+	_, err := s.ParseRelevantInformationFromDispatchMessage(context.Background(), "raw")
+	require.NoError(t, err)
+	require.Len(t, rec.records(), 1)
+	assert.Empty(t, rec.records()[0].InteractionID)
+}
+
+func TestShadowMLClient_OnlyConfiguredKinds(t *testing.T) {
+	rec := &syncRecorder{}
+	s := newShadow(t, &fakeInner{cleanupOut: &ml.TACCleanupResult{}}, &fakeInner{}, rec, true)
+
+	_, err := s.CleanTACTranscript(context.Background(), ml.TACCleanupInput{Text: "x"})
+	require.NoError(t, err)
+	require.Len(t, rec.records(), 1, "cleanup isn't shadowed here")
+	assert.Empty(t, rec.records()[0].InteractionID)
+}
+
+func TestShadowMLClient_FailedLiveCallIsNotShadowed(t *testing.T) {
+	rec := &syncRecorder{}
+	s := newShadow(t, &fakeInner{dispatchErr: errors.New("boom")}, &fakeInner{}, rec, true)
+
+	_, err := s.ParseRelevantInformationFromDispatchMessage(context.Background(), "raw")
+	require.Error(t, err)
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, rec.records(), 1)
+}
+
+func TestNewShadowMLClient_Validates(t *testing.T) {
+	_, err := NewShadowMLClient(&fakeInner{}, &fakeInner{}, &syncRecorder{}, DecoratorOptions{}, ShadowOptions{SampleRate: 1.5})
+	require.Error(t, err)
+	_, err = NewShadowMLClient(&fakeInner{}, &fakeInner{}, &syncRecorder{}, DecoratorOptions{}, ShadowOptions{Kinds: []string{"dispatch"}})
+	require.Error(t, err)
+}
+
+func mustJSON(t *testing.T, v any) json.RawMessage {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	require.NoError(t, err)
+	return raw
+}
+
+func TestCompareShadowPairs(t *testing.T) {
+	dispatch := func(callType, tac string) *ml.DispatchMessages {
+		return &ml.DispatchMessages{Messages: []ml.DispatchMessage{{CallType: callType, TACChannel: tac}}}
+	}
+	pairs := []ShadowPair{
+		{Kind: KindDispatchParse, Live: mustJSON(t, dispatch("Rescue - Trail", "TAC3")), Shadow: mustJSON(t, dispatch("rescue -  trail", "TAC3"))},
+		{Kind: KindDispatchParse, Live: mustJSON(t, dispatch("Rescue - Trail", "TAC3")), Shadow: mustJSON(t, dispatch("Aid Emergency", "TAC8"))},
+		{Kind: KindDispatchParse, Live: mustJSON(t, dispatch("Rescue - Trail", "TAC3"))},
+		{Kind: KindRescueSummary, Live: mustJSON(t, ml.RescueSummary{SARNotified: true}), Shadow: mustJSON(t, ml.RescueSummary{SARNotified: false})},
+		{Kind: KindTACCleanup, Live: mustJSON(t, ml.TACCleanupResult{}), Shadow: mustJSON(t, ml.TACCleanupResult{})},
+	}
+
+	r := CompareShadowPairs(pairs)
+	assert.Equal(t, map[string]int{KindDispatchParse: 3, KindRescueSummary: 1, KindTACCleanup: 1}, r.Pairs)
+	assert.Equal(t, map[string]int{KindDispatchParse: 1}, r.ShadowErrors)
+	assert.Equal(t, []FieldAgreement{
+		{Field: "call_type", Compared: 2, Agreed: 1},
+		{Field: "tac_channel", Compared: 2, Agreed: 1},
+		{Field: "sar_notified", Compared: 1, Agreed: 0},
+	}, r.Fields)
+	assert.InDelta(t, 0.5, r.Fields[0].Rate(), 1e-9)
+}
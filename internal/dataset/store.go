@@ -192,11 +192,19 @@ func (s *Store) writeLLM(rec LLMInteractionRecord) {
 		output = string(rec.Output)
 	}
 
+	var inputTokens, outputTokens, cacheReadTokens, cacheWriteTokens any // int64 or NULL
+	if u := rec.Usage; u != nil {
+		inputTokens, outputTokens, cacheReadTokens, cacheWriteTokens = u.InputTokens, u.OutputTokens, u.CacheReadTokens, u.CacheWriteTokens
+	}
+
 	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO llm_interactions (kind, backend, model, prompt_hash, s3_key, talkgroup, input_text, output, error, latency_ms)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8::jsonb, $9, $10)`,
+		`INSERT INTO llm_interactions (kind, backend, model, prompt_hash, s3_key, talkgroup, input_text, output, error, latency_ms, interaction_id, shadow_of,
+		                               input_tokens, output_tokens, cache_read_tokens, cache_write_tokens)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8::jsonb, $9, $10, $11, $12, $13, $14, $15, $16)`,
 		rec.Kind, rec.Backend, rec.Model, rec.PromptHash,
 		nullIfEmpty(rec.S3Key), nullIfEmpty(rec.Talkgroup), rec.InputText, output, nullIfEmpty(rec.Err), rec.LatencyMS,
+		nullIfEmpty(rec.InteractionID), nullIfEmpty(rec.ShadowOf),
+		inputTokens, outputTokens, cacheReadTokens, cacheWriteTokens,
 	)
 	if err != nil {
 		slog.Warn("dataset: failed to insert llm interaction", slog.String("error", err.Error()), slog.String("kind", rec.Kind))
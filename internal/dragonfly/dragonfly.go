@@ -2,7 +2,9 @@ package dragonfly
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -74,6 +76,45 @@ func (d *DragonflyClient) SetNX(ctx context.Context, key string, ttl time.Durati
 	return d.client.SetNX(dflyCtx, key, value, ttl).Result()
 }
 
+// ErrUpdateContention is returned by Update when the key kept changing under it.
+var ErrUpdateContention = errors.New("dragonfly: key kept changing during update")
+
+const updateMaxAttempts = 10
+
+// Update is an optimistic read-modify-write of a string key: WATCH it, read it, let fn compute
+// the new value, and SET it (with ttl) in a MULTI/EXEC that aborts if anyone else wrote the key
+// in between, in which case the whole round starts over from a fresh read. Backs tac_meta, which
+// the transcribe service and the Slack controller both rewrite.
+//
+// fn sees "" for a missing key and may run several times, so it must not have side effects
+// beyond reads. An error from fn aborts the update and is returned as-is.
+func (d *DragonflyClient) Update(ctx context.Context, key string, ttl time.Duration, fn func(current string) (string, error)) error {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
+	defer cancel()
+
+	for range updateMaxAttempts {
+		err := d.client.Watch(dflyCtx, func(tx *redis.Tx) error {
+			current, err := tx.Get(dflyCtx, key).Result()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return fmt.Errorf("failed to get value for key %s: %w", key, err)
+			}
+			next, err := fn(current)
+			if err != nil {
+				return err
+			}
+			_, err = tx.TxPipelined(dflyCtx, func(p redis.Pipeliner) error {
+				p.Set(dflyCtx, key, next, ttl)
+				return nil
+			})
+			return err
+		}, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return ErrUpdateContention
+}
+
 // FIX (review item #10 / option B): ZAdd / ZRangeByScore / ZRem back the durable TAC-expiry sweeper,
 // replacing the in-process time.AfterFunc that lost scheduled "channel closed" messages on restart.
 func (d *DragonflyClient) ZAdd(ctx context.Context, key string, score float64, member string) error {
@@ -83,6 +124,54 @@ func (d *DragonflyClient) ZAdd(ctx context.Context, key string, score float64, m
 	return d.client.ZAdd(dflyCtx, key, redis.Z{Score: score, Member: member}).Err()
 }
 
+// ZRaise moves an existing member's score up to score (ZADD XX GT CH). It never adds a missing
+// member and never lowers a score, so the idle-timeout path can push a closure out without
+// resurrecting one the sweeper or a Cancel already claimed, or undoing a longer Extend.
+// Returns whether the score changed.
+func (d *DragonflyClient) ZRaise(ctx context.Context, key string, score float64, member string) (bool, error) {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
+	defer cancel()
+
+	changed, err := d.client.ZAddArgs(dflyCtx, key, redis.ZAddArgs{
+		XX:      true,
+		GT:      true,
+		Ch:      true,
+		Members: []redis.Z{{Score: score, Member: member}},
+	}).Result()
+	return changed > 0, err
+}
+
+// ZLower is ZRaise's counterpart (ZADD XX LT CH): it only ever brings an existing member's
+// score down. The outcome-close path uses it to pull a closure in to the grace deadline
+// without postponing one that is already sooner.
+func (d *DragonflyClient) ZLower(ctx context.Context, key string, score float64, member string) (bool, error) {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
+	defer cancel()
+
+	changed, err := d.client.ZAddArgs(dflyCtx, key, redis.ZAddArgs{
+		XX:      true,
+		LT:      true,
+		Ch:      true,
+		Members: []redis.Z{{Score: score, Member: member}},
+	}).Result()
+	return changed > 0, err
+}
+
+// ZUpdate sets an existing member's score in either direction (ZADD XX CH). Like ZRaise it never
+// adds a missing member, so the idle-timeout path can reschedule a closure without resurrecting
+// one the sweeper or a Cancel already claimed. Returns whether the score changed.
+func (d *DragonflyClient) ZUpdate(ctx context.Context, key string, score float64, member string) (bool, error) {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
+	defer cancel()
+
+	changed, err := d.client.ZAddArgs(dflyCtx, key, redis.ZAddArgs{
+		XX:      true,
+		Ch:      true,
+		Members: []redis.Z{{Score: score, Member: member}},
+	}).Result()
+	return changed > 0, err
+}
+
 func (d *DragonflyClient) ZRangeByScore(ctx context.Context, key string, min, max string) ([]string, error) {
 	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
 	defer cancel()
@@ -90,6 +179,15 @@ func (d *DragonflyClient) ZRangeByScore(ctx context.Context, key string, min, ma
 	return d.client.ZRangeByScore(dflyCtx, key, &redis.ZRangeBy{Min: min, Max: max}).Result()
 }
 
+// ZRemRangeByScore drops every member scored in [min, max]. The incident digest uses it to
+// age closed incidents out of its index once their records have expired.
+func (d *DragonflyClient) ZRemRangeByScore(ctx context.Context, key string, min, max string) (int64, error) {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
+	defer cancel()
+
+	return d.client.ZRemRangeByScore(dflyCtx, key, min, max).Result()
+}
+
 // ZRem returns the number of members actually removed; the sweeper uses this as a
 // claim primitive so each due closure is processed by exactly one goroutine.
 func (d *DragonflyClient) ZRem(ctx context.Context, key string, member string) (int64, error) {
@@ -151,3 +249,133 @@ func (d *DragonflyClient) Expire(ctx context.Context, key string, ttl time.Durat
 
 	return d.client.Expire(dflyCtx, key, ttl).Err()
 }
+
+// IncrByEx / HIncrByEx back LLM token accounting: a per-day counter the budget is
+// checked against, and a per-incident hash of per-backend totals. Each increment re-stamps the
+// TTL in the same transaction, so a counter never outlives its window by more than one write.
+func (d *DragonflyClient) IncrByEx(ctx context.Context, key string, ttl time.Duration, n int64) (int64, error) {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
+	defer cancel()
+
+	var incr *redis.IntCmd
+	_, err := d.client.TxPipelined(dflyCtx, func(p redis.Pipeliner) error {
+		incr = p.IncrBy(dflyCtx, key, n)
+		p.Expire(dflyCtx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (d *DragonflyClient) HIncrByEx(ctx context.Context, key string, ttl time.Duration, fields map[string]int64) error {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
+	defer cancel()
+
+	_, err := d.client.TxPipelined(dflyCtx, func(p redis.Pipeliner) error {
+		for field, n := range fields {
+			p.HIncrBy(dflyCtx, key, field, n)
+		}
+		p.Expire(dflyCtx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+// XGroupCreateMkStream / XAdd / XReadGroup / XAck / XAutoClaim back the durable outbound
+// Slack queue: producers append to a stream, a consumer group fans entries out to the sender
+// goroutines, and entries left pending by a crashed replica are reclaimed after an idle window.
+//
+// XGroupCreateMkStream is idempotent — a BUSYGROUP reply (group already exists) is not an error.
+func (d *DragonflyClient) XGroupCreateMkStream(ctx context.Context, stream, group string) error {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
+	defer cancel()
+
+	err := d.client.XGroupCreateMkStream(dflyCtx, stream, group, "0").Err()
+	if err != nil && strings.Contains(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// XAdd appends an entry and returns its stream ID. maxLen > 0 caps the stream length
+// (approximate trimming) so a long Slack outage can't grow it without bound.
+func (d *DragonflyClient) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
+	defer cancel()
+
+	args := &redis.XAddArgs{Stream: stream, Values: values}
+	if maxLen > 0 {
+		args.MaxLen = maxLen
+		args.Approx = true
+	}
+	return d.client.XAdd(dflyCtx, args).Result()
+}
+
+// XReadGroup reads up to count new entries for the consumer, blocking up to block. The
+// per-call timeout is extended by block so the server-side wait isn't cut short. Returns
+// (nil, nil) when the block elapses with nothing to read.
+func (d *DragonflyClient) XReadGroup(ctx context.Context, stream, group, consumer string, count int64, block time.Duration) ([]redis.XMessage, error) {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout+block)
+	defer cancel()
+
+	res, err := d.client.XReadGroup(dflyCtx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{stream, ">"},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []redis.XMessage
+	for _, s := range res {
+		out = append(out, s.Messages...)
+	}
+	return out, nil
+}
+
+func (d *DragonflyClient) XAck(ctx context.Context, stream, group string, ids ...string) error {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
+	defer cancel()
+
+	return d.client.XAck(dflyCtx, stream, group, ids...).Err()
+}
+
+// XAutoClaim transfers up to count entries that have been pending longer than minIdle to
+// consumer, so work abandoned by a crashed or restarted replica is retried.
+func (d *DragonflyClient) XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, count int64) ([]redis.XMessage, error) {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
+	defer cancel()
+
+	msgs, _, err := d.client.XAutoClaim(dflyCtx, &redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Start:    "0",
+		Count:    count,
+	}).Result()
+	return msgs, err
+}
+
+// XClaimTouch re-claims ids for consumer with a zero idle threshold, which resets their idle
+// time. A sender calls it between retries of a long-running delivery so another replica's
+// XAutoClaim doesn't steal (and double-send) an entry that is still being worked on.
+func (d *DragonflyClient) XClaimTouch(ctx context.Context, stream, group, consumer string, ids ...string) error {
+	dflyCtx, cancel := context.WithTimeout(ctx, d.defaultTimeout)
+	defer cancel()
+
+	return d.client.XClaimJustID(dflyCtx, &redis.XClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  0,
+		Messages: ids,
+	}).Err()
+}
@@ -0,0 +1,227 @@
+// Package escalation pages people outside Slack when an active rescue crosses a threshold
+// leadership cares about — SAR was notified, the incident has run long, or the patient status
+// sounds serious. Rules are pure predicates over an incident snapshot; a Transport delivers
+// the page (Twilio-compatible SMS or SMTP email).
+//
+// Per-incident dedup lives with the caller (internal/transcribe keys it in Dragonfly), so a
+// rule fires at most once per rescue no matter how many replicas evaluate it.
+package escalation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+)
+
+// RuleType selects a rule's predicate.
+type RuleType string
+
+const (
+	// RuleSARNotified fires once the live interpretation reports SAR as notified.
+	RuleSARNotified RuleType = "sar_notified"
+	// RuleActiveFor fires once the incident has been active for at least After.
+	RuleActiveFor RuleType = "active_for"
+	// RulePatientStatus fires when the summary's patient status contains any of Match
+	// (case-insensitive substring).
+	RulePatientStatus RuleType = "patient_status"
+)
+
+// Rule is one ESCALATION_RULES entry, e.g.
+//
+//	{"name":"sar","type":"sar_notified"}
+//	{"name":"long-running","type":"active_for","after":"90m"}
+//	{"name":"injury","type":"patient_status","match":["injur","fracture","unconscious"]}
+//
+// Name is the dedup key, so renaming a rule re-arms it for incidents already in flight.
+type Rule struct {
+	Name  string        `json:"name"`
+	Type  RuleType      `json:"type"`
+	After time.Duration `json:"-"`
+	Match []string      `json:"match,omitempty"`
+}
+
+// UnmarshalJSON accepts "after" as a Go duration string ("90m") rather than nanoseconds.
+func (r *Rule) UnmarshalJSON(b []byte) error {
+	type plain Rule
+	aux := struct {
+		*plain
+		After string `json:"after"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	if aux.After != "" {
+		d, err := time.ParseDuration(aux.After)
+		if err != nil {
+			return fmt.Errorf("after: %w", err)
+		}
+		r.After = d
+	}
+	return nil
+}
+
+// Incident is the snapshot a rule is evaluated against. Summary is nil until the first live
+// interpretation lands; DispatchedAt is zero for rescues scheduled before it was recorded.
+type Incident struct {
+	ID           string
+	TACChannel   string
+	DispatchedAt time.Time
+	Summary      *ml.RescueSummary
+}
+
+// Matches reports whether r fires for inc at now.
+func (r Rule) Matches(inc Incident, now time.Time) bool {
+	switch r.Type {
+	case RuleSARNotified:
+		return inc.Summary != nil && inc.Summary.SARNotified
+	case RuleActiveFor:
+		return !inc.DispatchedAt.IsZero() && now.Sub(inc.DispatchedAt) >= r.After
+	case RulePatientStatus:
+		if inc.Summary == nil || inc.Summary.PatientStatus == "" {
+			return false
+		}
+		status := strings.ToLower(inc.Summary.PatientStatus)
+		for _, m := range r.Match {
+			if strings.Contains(status, strings.ToLower(m)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseRules decodes and validates ESCALATION_RULES. An empty string means no rules.
+func ParseRules(raw string) ([]Rule, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var rules []Rule
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, fmt.Errorf("ESCALATION_RULES is not a valid JSON array: %w", err)
+	}
+	seen := make(map[string]bool, len(rules))
+	for i, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("ESCALATION_RULES[%d]: name is required", i)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("ESCALATION_RULES[%d]: duplicate name %q", i, r.Name)
+		}
+		seen[r.Name] = true
+		switch r.Type {
+		case RuleSARNotified:
+		case RuleActiveFor:
+			if r.After <= 0 {
+				return nil, fmt.Errorf("ESCALATION_RULES[%d] (%s): active_for needs a positive \"after\"", i, r.Name)
+			}
+		case RulePatientStatus:
+			if len(r.Match) == 0 {
+				return nil, fmt.Errorf("ESCALATION_RULES[%d] (%s): patient_status needs at least one \"match\" term", i, r.Name)
+			}
+		default:
+			return nil, fmt.Errorf("ESCALATION_RULES[%d] (%s): unknown type %q", i, r.Name, r.Type)
+		}
+	}
+	return rules, nil
+}
+
+// Page is one outbound escalation. Subject is used by email; SMS sends Body only.
+type Page struct {
+	Subject string
+	Body    string
+}
+
+// Transport delivers a page to every configured recipient.
+type Transport interface {
+	Send(ctx context.Context, p Page) error
+}
+
+// maxPageBody keeps an SMS page to two GSM-7 segments; pagers truncate anything longer.
+const maxPageBody = 300
+
+// BuildPage renders the page for rule firing on inc. listenURL (live audio) is appended when
+// set so the recipient can tune in straight from the text.
+func BuildPage(rule Rule, inc Incident, now time.Time, listenURL string) Page {
+	var reason string
+	switch rule.Type {
+	case RuleSARNotified:
+		reason = "SAR notified"
+	case RuleActiveFor:
+		reason = fmt.Sprintf("active %s", now.Sub(inc.DispatchedAt).Truncate(time.Minute))
+	case RulePatientStatus:
+		reason = "patient: " + inc.Summary.PatientStatus
+	default:
+		reason = rule.Name
+	}
+
+	subject := fmt.Sprintf("Rescue %s — %s", inc.TACChannel, reason)
+	var b strings.Builder
+	b.WriteString(subject)
+	if s := inc.Summary; s != nil {
+		if s.Headline != "" {
+			b.WriteString(". " + s.Headline)
+		}
+		if s.Location != "" {
+			b.WriteString(". Location: " + s.Location)
+		}
+	}
+	body := b.String()
+	if listenURL != "" {
+		// Keep the link intact; trim the prose instead.
+		body = truncate(body, maxPageBody-len(listenURL)-1) + " " + listenURL
+	} else {
+		body = truncate(body, maxPageBody)
+	}
+	return Page{Subject: subject, Body: body}
+}
+
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// Escalator bundles the configured rules with the transport that delivers their pages.
+type Escalator struct {
+	rules     []Rule
+	transport Transport
+}
+
+func New(rules []Rule, transport Transport) *Escalator {
+	return &Escalator{rules: rules, transport: transport}
+}
+
+// Due returns the rules that fire for inc at now. Dedup is the caller's job.
+func (e *Escalator) Due(inc Incident, now time.Time) []Rule {
+	var due []Rule
+	for _, r := range e.rules {
+		if r.Matches(inc, now) {
+			due = append(due, r)
+		}
+	}
+	return due
+}
+
+// HasTimeRules reports whether any rule can become true without a new transmission, i.e.
+// whether the sweeper needs to evaluate active incidents on its own tick.
+func (e *Escalator) HasTimeRules() bool {
+	for _, r := range e.rules {
+		if r.Type == RuleActiveFor {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Escalator) Send(ctx context.Context, p Page) error {
+	return e.transport.Send(ctx, p)
+}
@@ -0,0 +1,133 @@
+package escalation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleMatches(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	sar := Rule{Name: "sar", Type: RuleSARNotified}
+	long := Rule{Name: "long", Type: RuleActiveFor, After: 90 * time.Minute}
+	injury := Rule{Name: "injury", Type: RulePatientStatus, Match: []string{"injur", "Fracture"}}
+
+	cases := []struct {
+		name string
+		rule Rule
+		inc  Incident
+		want bool
+	}{
+		{"sar: no summary yet", sar, Incident{}, false},
+		{"sar: not notified", sar, Incident{Summary: &ml.RescueSummary{}}, false},
+		{"sar: notified", sar, Incident{Summary: &ml.RescueSummary{SARNotified: true}}, true},
+		{"long: unknown dispatch time", long, Incident{}, false},
+		{"long: under threshold", long, Incident{DispatchedAt: now.Add(-89 * time.Minute)}, false},
+		{"long: at threshold", long, Incident{DispatchedAt: now.Add(-90 * time.Minute)}, true},
+		{"injury: no status", injury, Incident{Summary: &ml.RescueSummary{}}, false},
+		{"injury: benign", injury, Incident{Summary: &ml.RescueSummary{PatientStatus: "ambulatory, tired"}}, false},
+		{"injury: case-insensitive", injury, Incident{Summary: &ml.RescueSummary{PatientStatus: "Possible FRACTURE left ankle"}}, true},
+		{"injury: substring", injury, Incident{Summary: &ml.RescueSummary{PatientStatus: "injured hiker"}}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.rule.Matches(tc.inc, now))
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(`[{"name":"sar","type":"sar_notified"},{"name":"long","type":"active_for","after":"90m"},{"name":"injury","type":"patient_status","match":["injur"]}]`)
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+	assert.Equal(t, 90*time.Minute, rules[1].After)
+	assert.True(t, New(rules, nil).HasTimeRules())
+	assert.False(t, New(rules[:1], nil).HasTimeRules())
+
+	rules, err = ParseRules("")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	for name, raw := range map[string]string{
+		"not json":         `nope`,
+		"missing name":     `[{"type":"sar_notified"}]`,
+		"duplicate":        `[{"name":"a","type":"sar_notified"},{"name":"a","type":"sar_notified"}]`,
+		"unknown type":     `[{"name":"a","type":"moon_phase"}]`,
+		"bad duration":     `[{"name":"a","type":"active_for","after":"soon"}]`,
+		"missing duration": `[{"name":"a","type":"active_for"}]`,
+		"missing match":    `[{"name":"a","type":"patient_status"}]`,
+	} {
+		_, err := ParseRules(raw)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestBuildPage(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	inc := Incident{
+		TACChannel:   "TAC10",
+		DispatchedAt: now.Add(-95*time.Minute - 20*time.Second),
+		Summary:      &ml.RescueSummary{Headline: "Injured hiker on Mailbox Peak", Location: "Mailbox Peak trail, mile 2"},
+	}
+
+	p := BuildPage(Rule{Name: "long", Type: RuleActiveFor, After: 90 * time.Minute}, inc, now, "https://openmhz.example/x")
+	assert.Equal(t, "Rescue TAC10 — active 1h35m0s", p.Subject)
+	assert.Equal(t, "Rescue TAC10 — active 1h35m0s. Injured hiker on Mailbox Peak. Location: Mailbox Peak trail, mile 2 https://openmhz.example/x", p.Body)
+
+	inc.Summary.Headline = strings.Repeat("x", 500)
+	p = BuildPage(Rule{Name: "sar", Type: RuleSARNotified}, inc, now, "https://openmhz.example/x")
+	assert.LessOrEqual(t, len([]rune(p.Body)), maxPageBody)
+	assert.True(t, strings.HasSuffix(p.Body, "… https://openmhz.example/x"))
+}
+
+func TestTwilio_SendsOnePerRecipient(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		tos  []string
+		auth []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+		require.NoError(t, r.ParseForm())
+		user, pass, _ := r.BasicAuth()
+		mu.Lock()
+		tos = append(tos, r.PostForm.Get("To"))
+		auth = append(auth, user+":"+pass)
+		mu.Unlock()
+		assert.Equal(t, "+15550000000", r.PostForm.Get("From"))
+		assert.Equal(t, "hello", r.PostForm.Get("Body"))
+		if r.PostForm.Get("To") == "+15550000002" {
+			http.Error(w, `{"message":"invalid number"}`, http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	tw := NewTwilio(srv.URL+"/", "AC123", "secret", "+15550000000", []string{"+15550000001", "+15550000002"}, srv.Client())
+	err := tw.Send(context.Background(), Page{Body: "hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "+15550000002")
+	assert.NotContains(t, err.Error(), "+15550000001")
+	assert.Equal(t, []string{"+15550000001", "+15550000002"}, tos)
+	assert.Equal(t, []string{"AC123:secret", "AC123:secret"}, auth)
+}
+
+func TestSMTPMessage(t *testing.T) {
+	s := NewSMTP("mail.example.com:587", "", "", "alerts@example.com", []string{"a@example.com", "b@example.com"})
+	s.now = func() time.Time { return time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	msg := string(s.message(Page{Subject: "Rescue TAC10 — SAR notified", Body: "line one\nline two"}))
+	assert.Contains(t, msg, "To: a@example.com, b@example.com\r\n")
+	assert.Contains(t, msg, "Subject: =?utf-8?q?Rescue_TAC10_=E2=80=94_SAR_notified?=\r\n")
+	assert.Contains(t, msg, "Date: Mon, 01 Jun 2026 12:00:00 +0000\r\n")
+	assert.True(t, strings.HasSuffix(msg, "\r\n\r\nline one\r\nline two\r\n"))
+}
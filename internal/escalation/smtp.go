@@ -0,0 +1,103 @@
+package escalation
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTP sends pages as a plain-text email to every recipient in one message. Works with email
+// and email-to-SMS/pager gateways alike. STARTTLS is used whenever the server offers it, and
+// PLAIN auth only when a username is configured (net/smtp refuses PLAIN over an unencrypted
+// connection to anything but localhost).
+type SMTP struct {
+	addr     string
+	username string
+	password string
+	from     string
+	to       []string
+	now      func() time.Time
+}
+
+// NewSMTP takes addr as host:port.
+func NewSMTP(addr, username, password, from string, to []string) *SMTP {
+	return &SMTP{addr: addr, username: username, password: password, from: from, to: to, now: time.Now}
+}
+
+func (s *SMTP) Send(ctx context.Context, p Page) error {
+	host, _, err := net.SplitHostPort(s.addr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address %q: %w", s.addr, err)
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("dial SMTP: %w", err)
+	}
+	// net/smtp has no context support; a connection deadline bounds the whole conversation.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("SMTP handshake: %w", err)
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("SMTP STARTTLS: %w", err)
+		}
+	}
+	if s.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.username, s.password, host)); err != nil {
+			return fmt.Errorf("SMTP auth: %w", err)
+		}
+	}
+	if err := c.Mail(s.from); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM: %w", err)
+	}
+	for _, to := range s.to {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA: %w", err)
+	}
+	if _, err := w.Write(s.message(p)); err != nil {
+		return fmt.Errorf("SMTP write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP DATA close: %w", err)
+	}
+	return c.Quit()
+}
+
+// message renders an RFC 5322 message. The subject is RFC 2047-encoded so em-dashes and
+// non-ASCII place names survive.
+func (s *SMTP) message(p Page) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", p.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", s.now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(p.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
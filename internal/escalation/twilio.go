@@ -0,0 +1,77 @@
+package escalation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultTwilioBaseURL is Twilio's REST API. Compatible providers (and test doubles) that
+// implement the same Messages resource can be targeted by overriding the base URL.
+const DefaultTwilioBaseURL = "https://api.twilio.com"
+
+// Twilio sends pages as SMS through the Twilio Messages API
+// (POST /2010-04-01/Accounts/{sid}/Messages.json, basic auth, form-encoded To/From/Body),
+// one request per recipient.
+type Twilio struct {
+	baseURL    string
+	accountSID string
+	authToken  string
+	from       string
+	to         []string
+	client     *http.Client
+}
+
+func NewTwilio(baseURL, accountSID, authToken, from string, to []string, client *http.Client) *Twilio {
+	if baseURL == "" {
+		baseURL = DefaultTwilioBaseURL
+	}
+	return &Twilio{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		to:         to,
+		client:     client,
+	}
+}
+
+// Send texts every recipient. A failure for one number doesn't stop the rest; errors are joined.
+func (t *Twilio) Send(ctx context.Context, p Page) error {
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", t.baseURL, url.PathEscape(t.accountSID))
+	var errs []error
+	for _, to := range t.to {
+		if err := t.sendOne(ctx, endpoint, to, p.Body); err != nil {
+			errs = append(errs, fmt.Errorf("sms to %s: %w", to, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *Twilio) sendOne(ctx context.Context, endpoint, to, body string) error {
+	form := url.Values{"To": {to}, "From": {t.from}, "Body": {body}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("provider returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
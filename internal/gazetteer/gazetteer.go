@@ -0,0 +1,243 @@
+// Package gazetteer is the deployment's list of local place names. It is used three ways:
+//
+//   - rendered into the dispatch, summary and cleanup system prompts (prompts.PlaceReference)
+//     so the model can correct garbled place names;
+//   - as a deterministic pre-pass (Snap) that fixes obvious near-misses before the model sees
+//     the text;
+//   - to resolve the structured locations the ML backends extract to a map point (Resolve).
+//
+// The bundled file (places.json) covers King County and the I-90 / Snoqualmie corridor.
+// Other deployments supply their own via GAZETTEER_FILE in the same format. Coordinates are
+// optional and approximate (feature-level, not parking-lot-precise). Rivers and highways carry
+// none on purpose: a single point for a 60-mile river misdirects more than it helps.
+package gazetteer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+)
+
+//go:embed places.json
+var bundled []byte
+
+// Place is one gazetteer entry. Aliases are alternative names matched like the name (and
+// shown next to it in the prompt); "Mt."/"Mount" and punctuation differences are already
+// normalized away, so aliases are only needed for genuinely different forms. Note is an
+// optional hint rendered into the prompt, e.g. how ASR usually garbles the name.
+type Place struct {
+	Name     string   `json:"name"`
+	Category string   `json:"category"` // one of Categories
+	Aliases  []string `json:"aliases,omitempty"`
+	// Lat and Lon are nil for places with no useful single point (rivers, roads).
+	Lat  *float64 `json:"lat,omitempty"`
+	Lon  *float64 `json:"lon,omitempty"`
+	Note string   `json:"note,omitempty"`
+}
+
+// Located reports whether the place has coordinates to map.
+func (p Place) Located() bool { return p.Lat != nil && p.Lon != nil }
+
+// Categories are the accepted Place.Category values. The prompt groups places by them.
+var Categories = []string{"city", "peak", "trail", "trailhead", "landmark", "lake", "river", "falls", "road", "pass"}
+
+// Gazetteer is an immutable, in-memory place index. Safe for concurrent use.
+type Gazetteer struct {
+	places []Place // file order, which is also prompt order
+	byName map[string]Place
+	// keys are the normalized names and aliases, longest first, so a contained-name search
+	// prefers "mount si trailhead" over "mount si".
+	keys []string
+	// snapForms are the names and aliases prepared for Snap, keyed by word count.
+	snapForms    map[int][]snapForm
+	maxSnapWords int
+}
+
+// Bundled returns the gazetteer built into the binary. Parsed once; the embedded file is
+// covered by tests, so a parse failure is a build defect and panics.
+var Bundled = sync.OnceValue(func() *Gazetteer {
+	g, err := Parse(bundled)
+	if err != nil {
+		panic(fmt.Sprintf("gazetteer: bundled places.json: %v", err))
+	}
+	return g
+})
+
+// Load reads a gazetteer file; an empty path returns the bundled one.
+func Load(path string) (*Gazetteer, error) {
+	if path == "" {
+		return Bundled(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read gazetteer file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse builds a gazetteer from a JSON array of places, rejecting entries without a name or
+// a known category, half-set or out-of-range coordinates, and names that collide after
+// normalization.
+func Parse(data []byte) (*Gazetteer, error) {
+	var places []Place
+	if err := json.Unmarshal(data, &places); err != nil {
+		return nil, fmt.Errorf("parse gazetteer: %w", err)
+	}
+	if len(places) == 0 {
+		return nil, errors.New("gazetteer has no places")
+	}
+	g := &Gazetteer{byName: make(map[string]Place)}
+	for i, p := range places {
+		if strings.TrimSpace(p.Name) == "" {
+			return nil, fmt.Errorf("gazetteer entry %d: name is required", i)
+		}
+		if !knownCategory(p.Category) {
+			return nil, fmt.Errorf("gazetteer entry %q: unknown category %q", p.Name, p.Category)
+		}
+		if (p.Lat == nil) != (p.Lon == nil) {
+			return nil, fmt.Errorf("gazetteer entry %q: lat and lon must be set together", p.Name)
+		}
+		if p.Located() && !validCoordinates(*p.Lat, *p.Lon) {
+			return nil, fmt.Errorf("gazetteer entry %q: coordinates %v,%v out of range", p.Name, *p.Lat, *p.Lon)
+		}
+		for _, name := range append([]string{p.Name}, p.Aliases...) {
+			key := normalize(name)
+			if key == "" {
+				continue
+			}
+			if other, dup := g.byName[key]; dup && other.Name != p.Name {
+				return nil, fmt.Errorf("gazetteer: %q and %q both normalize to %q", other.Name, p.Name, key)
+			}
+			g.byName[key] = p
+		}
+		g.places = append(g.places, p)
+	}
+	for key := range g.byName {
+		g.keys = append(g.keys, key)
+	}
+	sort.Slice(g.keys, func(i, j int) bool {
+		if len(g.keys[i]) != len(g.keys[j]) {
+			return len(g.keys[i]) > len(g.keys[j])
+		}
+		return g.keys[i] < g.keys[j]
+	})
+	g.buildSnapForms()
+	return g, nil
+}
+
+// Len is the number of distinct names and aliases indexed.
+func (g *Gazetteer) Len() int { return len(g.keys) }
+
+// Places returns the entries in file order. The slice is shared; don't modify it.
+func (g *Gazetteer) Places() []Place { return g.places }
+
+// Lookup finds the place text refers to: an exact (normalized) name match, or else the
+// longest listed name contained in text on word boundaries ("Mailbox Peak trail, mile 2" →
+// Mailbox Peak). Matching is deliberately literal; the ML prompt already snaps garbled names
+// to their canonical spelling.
+func (g *Gazetteer) Lookup(text string) (Place, bool) {
+	return g.lookup(text, func(Place) bool { return true })
+}
+
+// lookup is Lookup restricted to places accepted by keep.
+func (g *Gazetteer) lookup(text string, keep func(Place) bool) (Place, bool) {
+	norm := normalize(text)
+	if norm == "" {
+		return Place{}, false
+	}
+	if p, ok := g.byName[norm]; ok && keep(p) {
+		return p, true
+	}
+	padded := " " + norm + " "
+	for _, key := range g.keys {
+		if p := g.byName[key]; keep(p) && strings.Contains(padded, " "+key+" ") {
+			return p, true
+		}
+	}
+	return Place{}, false
+}
+
+// Point is a resolved map location.
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+	// Label is what the point is: the gazetteer place name, or "Reported coordinates".
+	Label string `json:"label"`
+	// Spoken is true when the point came from coordinates read out on the radio rather than
+	// from the gazetteer.
+	Spoken bool `json:"spoken,omitempty"`
+}
+
+// Resolve picks the best point for loc, most specific first: coordinates read out on the
+// radio, then the trailhead, the landmark, and finally anything recognizable in the address
+// (usually just the town). Places without coordinates are skipped, so "Mount Si Road" still
+// lands on Mount Si. ok=false when nothing resolves.
+func (g *Gazetteer) Resolve(loc ml.StructuredLocation) (Point, bool) {
+	if (loc.Latitude != 0 || loc.Longitude != 0) && validCoordinates(loc.Latitude, loc.Longitude) {
+		return Point{Lat: loc.Latitude, Lon: loc.Longitude, Label: "Reported coordinates", Spoken: true}, true
+	}
+	for _, field := range []string{loc.Trailhead, loc.Landmark, loc.Address} {
+		if p, ok := g.lookup(field, Place.Located); ok {
+			return Point{Lat: *p.Lat, Lon: *p.Lon, Label: p.Name}, true
+		}
+	}
+	return Point{}, false
+}
+
+// Map link providers accepted by MapURL (MAP_LINK_PROVIDER).
+const (
+	ProviderCalTopo       = "caltopo"
+	ProviderOpenStreetMap = "osm"
+)
+
+// ValidProvider reports whether MapURL knows provider.
+func ValidProvider(provider string) bool {
+	return provider == ProviderCalTopo || provider == ProviderOpenStreetMap
+}
+
+// MapURL links to p on the given provider, zoomed to trail scale. CalTopo opens on its
+// topographic base layer, which is what field teams use; OpenStreetMap drops a marker.
+// Unknown providers fall back to OpenStreetMap.
+func MapURL(provider string, p Point) string {
+	if provider == ProviderCalTopo {
+		return fmt.Sprintf("https://caltopo.com/map.html#ll=%.5f,%.5f&z=15&b=mbt", p.Lat, p.Lon)
+	}
+	return fmt.Sprintf("https://www.openstreetmap.org/?mlat=%.5f&mlon=%.5f#map=15/%.5f/%.5f", p.Lat, p.Lon, p.Lat, p.Lon)
+}
+
+func knownCategory(c string) bool {
+	for _, known := range Categories {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+func validCoordinates(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+// normalize lowercases, folds "Mt"/"Mt." to "mount", drops apostrophes and turns any other
+// punctuation into a word break, so "Mt. Si", "mount si" and "Mount Si." compare equal.
+func normalize(s string) string {
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, "'", "")
+	s = strings.ReplaceAll(s, "’", "")
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	for i, f := range fields {
+		if f == "mt" {
+			fields[i] = "mount"
+		}
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,114 @@
+package gazetteer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Bundled(t *testing.T) {
+	g, err := Load("")
+	require.NoError(t, err)
+	assert.Greater(t, g.Len(), 50)
+}
+
+func TestLoad_CustomFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "places.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"name": "Camp Muir", "category": "landmark", "lat": 46.8356, "lon": -121.7322}]`), 0o600))
+
+	g, err := Load(path)
+	require.NoError(t, err)
+	p, ok := g.Lookup("camp muir")
+	require.True(t, ok)
+	assert.Equal(t, "Camp Muir", p.Name)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestParse_Rejects(t *testing.T) {
+	cases := map[string]string{
+		"not json":         `{`,
+		"empty":            `[]`,
+		"no name":          `[{"name": " ", "category": "peak"}]`,
+		"unknown category": `[{"name": "Nowhere", "category": "volcano"}]`,
+		"lat without lon":  `[{"name": "Nowhere", "category": "peak", "lat": 47}]`,
+		"bad latitude":     `[{"name": "Nowhere", "category": "peak", "lat": 95, "lon": -121}]`,
+		"bad longitude":    `[{"name": "Nowhere", "category": "peak", "lat": 47, "lon": -181}]`,
+		"name collision":   `[{"name": "Mt. Si", "category": "peak"}, {"name": "Mount Si", "category": "peak"}]`,
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	g, err := Load("")
+	require.NoError(t, err)
+
+	cases := []struct {
+		text, want string
+	}{
+		{"Mount Si", "Mount Si"},
+		{"mt. si", "Mount Si"},
+		{"Mt Si trailhead", "Mount Si Trailhead"},
+		{"Mailbox Peak trail, about mile 2", "Mailbox Peak"},
+		{"hiker at the Mailbox Peak Trailhead lot", "Mailbox Peak Trailhead"},
+		{"Ira Spring Trailhead", "Ira Spring Trail"},
+		{"I-90 eastbound near exit 47", "Interstate 90"},
+	}
+	for _, tc := range cases {
+		p, ok := g.Lookup(tc.text)
+		if assert.True(t, ok, tc.text) {
+			assert.Equal(t, tc.want, p.Name, tc.text)
+		}
+	}
+
+	for _, text := range []string{"", "somewhere off trail", "Little Sister"} {
+		_, ok := g.Lookup(text)
+		assert.False(t, ok, "%q should not match", text)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	g, err := Load("")
+	require.NoError(t, err)
+
+	p, ok := g.Resolve(ml.StructuredLocation{Trailhead: "Mount Si Trailhead", Latitude: 47.5, Longitude: -121.7})
+	require.True(t, ok)
+	assert.True(t, p.Spoken, "spoken coordinates win over the gazetteer")
+	assert.Equal(t, 47.5, p.Lat)
+
+	p, ok = g.Resolve(ml.StructuredLocation{Trailhead: "Mount Si Trailhead", Landmark: "Little Si"})
+	require.True(t, ok)
+	assert.Equal(t, "Mount Si Trailhead", p.Label, "trailhead before landmark")
+
+	p, ok = g.Resolve(ml.StructuredLocation{Landmark: "the big rock", Address: "45000 SE Mount Si Rd, North Bend"})
+	require.True(t, ok)
+	assert.False(t, p.Spoken)
+
+	p, ok = g.Resolve(ml.StructuredLocation{Address: "Mount Si Road"})
+	require.True(t, ok)
+	assert.Equal(t, "Mount Si", p.Label, "roads have no point; the contained peak does")
+
+	_, ok = g.Resolve(ml.StructuredLocation{Latitude: 123, Longitude: -121.7})
+	assert.False(t, ok, "out-of-range spoken coordinates are ignored, and nothing else resolves")
+
+	_, ok = g.Resolve(ml.StructuredLocation{})
+	assert.False(t, ok)
+}
+
+func TestMapURL(t *testing.T) {
+	p := Point{Lat: 47.4879, Lon: -121.7232}
+	assert.Equal(t, "https://caltopo.com/map.html#ll=47.48790,-121.72320&z=15&b=mbt", MapURL(ProviderCalTopo, p))
+	assert.Equal(t, "https://www.openstreetmap.org/?mlat=47.48790&mlon=-121.72320#map=15/47.48790/-121.72320", MapURL(ProviderOpenStreetMap, p))
+	assert.True(t, ValidProvider("osm"))
+	assert.False(t, ValidProvider("google"))
+}
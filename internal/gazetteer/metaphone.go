@@ -0,0 +1,482 @@
+package gazetteer
+
+import "strings"
+
+// doubleMetaphone returns the primary and alternate Double Metaphone codes for one word
+// (Lawrence Philips' algorithm, untruncated). Two spellings that sound alike in English
+// usually share a code: "Sai" and "Si" both encode as "S", "Kalitan" and "Kaleetan" as
+// "KLTN". Non-letters are ignored. The alternate equals the primary when the word has only
+// one plausible pronunciation.
+//
+// This is a straight port of the reference rules; the comments name the spellings each
+// branch exists for rather than re-explaining the algorithm.
+func doubleMetaphone(word string) (primary, alternate string) {
+	var letters []byte
+	for _, r := range strings.ToUpper(word) {
+		if r >= 'A' && r <= 'Z' {
+			letters = append(letters, byte(r))
+		}
+	}
+	m := &metaphone{s: string(letters), last: len(letters) - 1}
+	if m.last < 0 {
+		return "", ""
+	}
+	m.slavoGermanic = strings.ContainsAny(m.s, "WK") || strings.Contains(m.s, "CZ") || strings.Contains(m.s, "WITZ")
+	m.encode()
+	return m.primary.String(), m.alternate.String()
+}
+
+type metaphone struct {
+	s                  string
+	last               int
+	slavoGermanic      bool
+	primary, alternate strings.Builder
+}
+
+func (m *metaphone) add(main, alt string) {
+	m.primary.WriteString(main)
+	m.alternate.WriteString(alt)
+}
+
+func (m *metaphone) both(code string) { m.add(code, code) }
+
+func (m *metaphone) at(i int) byte {
+	if i < 0 || i > m.last {
+		return 0
+	}
+	return m.s[i]
+}
+
+// is reports whether the substring starting at start equals any of options (all the same
+// length in practice, but each is checked on its own).
+func (m *metaphone) is(start int, options ...string) bool {
+	if start < 0 {
+		return false
+	}
+	for _, o := range options {
+		if start+len(o) <= len(m.s) && m.s[start:start+len(o)] == o {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *metaphone) vowel(i int) bool {
+	switch m.at(i) {
+	case 'A', 'E', 'I', 'O', 'U', 'Y':
+		return true
+	}
+	return false
+}
+
+func (m *metaphone) encode() {
+	i := 0
+	if m.is(0, "GN", "KN", "PN", "WR", "PS") {
+		i = 1
+	}
+	// Initial X is pronounced Z ("Xavier").
+	if m.at(0) == 'X' {
+		m.both("S")
+		i = 1
+	}
+
+	for i <= m.last {
+		c := m.at(i)
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if i == 0 {
+				m.both("A")
+			}
+			i++
+		case 'B':
+			m.both("P")
+			i += skipDouble(m, i, 'B')
+		case 'C':
+			i = m.encodeC(i)
+		case 'D':
+			switch {
+			case m.is(i, "DG") && (m.at(i+2) == 'I' || m.at(i+2) == 'E' || m.at(i+2) == 'Y'):
+				m.both("J") // "edge"
+				i += 3
+			case m.is(i, "DT", "DD"):
+				m.both("T")
+				i += 2
+			default:
+				m.both("T")
+				i++
+			}
+		case 'F':
+			m.both("F")
+			i += skipDouble(m, i, 'F')
+		case 'G':
+			i = m.encodeG(i)
+		case 'H':
+			// Only pronounced at the start or between vowels.
+			if (i == 0 || m.vowel(i-1)) && m.vowel(i+1) {
+				m.both("H")
+				i += 2
+			} else {
+				i++
+			}
+		case 'J':
+			i = m.encodeJ(i)
+		case 'K':
+			m.both("K")
+			i += skipDouble(m, i, 'K')
+		case 'L':
+			if m.at(i+1) == 'L' {
+				// Spanish "cabrillo", "gallegos".
+				if (i == m.last-2 && m.is(i-1, "ILLO", "ILLA", "ALLE")) ||
+					((m.is(m.last-1, "AS", "OS") || m.at(m.last) == 'A' || m.at(m.last) == 'O') && m.is(i-1, "ALLE")) {
+					m.add("L", "")
+					i += 2
+					continue
+				}
+				i += 2
+			} else {
+				i++
+			}
+			m.both("L")
+		case 'M':
+			m.both("M")
+			// "dumb", "thumb"
+			if (m.is(i-1, "UMB") && (i+1 == m.last || m.is(i+2, "ER"))) || m.at(i+1) == 'M' {
+				i += 2
+			} else {
+				i++
+			}
+		case 'N':
+			m.both("N")
+			i += skipDouble(m, i, 'N')
+		case 'P':
+			if m.at(i+1) == 'H' {
+				m.both("F")
+				i += 2
+				continue
+			}
+			m.both("P")
+			if m.at(i+1) == 'P' || m.at(i+1) == 'B' { // "campbell"
+				i += 2
+			} else {
+				i++
+			}
+		case 'Q':
+			m.both("K")
+			i += skipDouble(m, i, 'Q')
+		case 'R':
+			// French "rogier": final R after IE silent in the primary.
+			if i == m.last && !m.slavoGermanic && m.is(i-2, "IE") && !m.is(i-4, "ME", "MA") {
+				m.add("", "R")
+			} else {
+				m.both("R")
+			}
+			i += skipDouble(m, i, 'R')
+		case 'S':
+			i = m.encodeS(i)
+		case 'T':
+			i = m.encodeT(i)
+		case 'V':
+			m.both("F")
+			i += skipDouble(m, i, 'V')
+		case 'W':
+			i = m.encodeW(i)
+		case 'X':
+			// French "breaux": final X silent.
+			if !(i == m.last && (m.is(i-3, "IAU", "EAU") || m.is(i-2, "AU", "OU"))) {
+				m.both("KS")
+			}
+			if m.at(i+1) == 'C' || m.at(i+1) == 'X' {
+				i += 2
+			} else {
+				i++
+			}
+		case 'Z':
+			if m.at(i+1) == 'H' { // Chinese "zhao"
+				m.both("J")
+				i += 2
+				continue
+			}
+			if m.is(i+1, "ZO", "ZI", "ZA") || (m.slavoGermanic && i > 0 && m.at(i-1) != 'T') {
+				m.add("S", "TS")
+			} else {
+				m.both("S")
+			}
+			i += skipDouble(m, i, 'Z')
+		default:
+			i++
+		}
+	}
+}
+
+// skipDouble is how far to advance past a consonant that may be doubled ("BB", "FF").
+func skipDouble(m *metaphone, i int, c byte) int {
+	if m.at(i+1) == c {
+		return 2
+	}
+	return 1
+}
+
+func (m *metaphone) encodeC(i int) int {
+	switch {
+	// Germanic "bacher", "macher".
+	case i > 1 && !m.vowel(i-2) && m.is(i-1, "ACH") && m.at(i+2) != 'I' && (m.at(i+2) != 'E' || m.is(i-2, "BACHER", "MACHER")):
+		m.both("K")
+		return i + 2
+	case i == 0 && m.is(i, "CAESAR"):
+		m.both("S")
+		return i + 2
+	case m.is(i, "CHIA"): // "chianti"
+		m.both("K")
+		return i + 2
+	case m.is(i, "CH"):
+		switch {
+		case i > 0 && m.is(i, "CHAE"): // "michael"
+			m.add("K", "X")
+		case i == 0 && (m.is(i+1, "HARAC", "HARIS") || m.is(i+1, "HOR", "HYM", "HIA", "HEM")) && !m.is(0, "CHORE"):
+			m.both("K") // Greek roots: "chemistry", "chorus"
+		case m.is(0, "VAN ", "VON ") || m.is(0, "SCH") || m.is(i-2, "ORCHES", "ARCHIT", "ORCHID") ||
+			m.at(i+2) == 'T' || m.at(i+2) == 'S' ||
+			((m.at(i-1) == 'A' || m.at(i-1) == 'O' || m.at(i-1) == 'U' || m.at(i-1) == 'E' || i == 0) &&
+				strings.IndexByte("LRNMBHFVW ", m.at(i+2)) >= 0 && m.at(i+2) != 0):
+			m.both("K") // Germanic, "orchestra", "architect"
+		case i > 0:
+			if m.is(0, "MC") {
+				m.both("K") // "McHugh"
+			} else {
+				m.add("X", "K")
+			}
+		default:
+			m.both("X")
+		}
+		return i + 2
+	case m.is(i, "CZ") && !m.is(i-2, "WICZ"): // "czerny"
+		m.add("S", "X")
+		return i + 2
+	case m.is(i+1, "CIA"): // "focaccia"
+		m.both("X")
+		return i + 3
+	case m.is(i, "CC") && !(i == 1 && m.at(0) == 'M'):
+		// "bellocchio" but not "bacchus"
+		if strings.IndexByte("IEH", m.at(i+2)) >= 0 && m.at(i+2) != 0 && !m.is(i+2, "HU") {
+			if (i == 1 && m.at(0) == 'A') || m.is(i-1, "UCCEE", "UCCES") {
+				m.both("KS") // "accident", "success"
+			} else {
+				m.both("X") // "bacci"
+			}
+			return i + 3
+		}
+		m.both("K") // Pierce's rule
+		return i + 2
+	case m.is(i, "CK", "CG", "CQ"):
+		m.both("K")
+		return i + 2
+	case m.is(i, "CI", "CE", "CY"):
+		if m.is(i, "CIO", "CIE", "CIA") { // Italian vs. English
+			m.add("S", "X")
+		} else {
+			m.both("S")
+		}
+		return i + 2
+	}
+	m.both("K")
+	switch {
+	case m.is(i+1, " C", " Q", " G"): // "Mac Caffrey"
+		return i + 3
+	case m.is(i+1, "C", "K", "Q") && !m.is(i+1, "CE", "CI"):
+		return i + 2
+	}
+	return i + 1
+}
+
+func (m *metaphone) encodeG(i int) int {
+	if m.at(i+1) == 'H' {
+		switch {
+		case i > 0 && !m.vowel(i-1):
+			m.both("K")
+			return i + 2
+		case i == 0:
+			if m.at(i+2) == 'I' { // "ghislane"
+				m.both("J")
+			} else {
+				m.both("K") // "ghost"
+			}
+			return i + 2
+		// Silent: "hugh", "bough", "broughton"
+		case (i > 1 && strings.IndexByte("BHD", m.at(i-2)) >= 0 && m.at(i-2) != 0) ||
+			(i > 2 && strings.IndexByte("BHD", m.at(i-3)) >= 0 && m.at(i-3) != 0) ||
+			(i > 3 && (m.at(i-4) == 'B' || m.at(i-4) == 'H')):
+			return i + 2
+		default:
+			// "laugh", "cough", "tough"
+			if i > 2 && m.at(i-1) == 'U' && strings.IndexByte("CGLRT", m.at(i-3)) >= 0 && m.at(i-3) != 0 {
+				m.both("F")
+			} else if i > 0 && m.at(i-1) != 'I' {
+				m.both("K")
+			}
+			return i + 2
+		}
+	}
+	if m.at(i+1) == 'N' {
+		switch {
+		case i == 1 && m.vowel(0) && !m.slavoGermanic:
+			m.add("KN", "N")
+		case !m.is(i+2, "EY") && m.at(i+1) != 'Y' && !m.slavoGermanic:
+			m.add("N", "KN")
+		default:
+			m.both("KN")
+		}
+		return i + 2
+	}
+	switch {
+	case m.is(i+1, "LI") && !m.slavoGermanic: // "tagliaro"
+		m.add("KL", "L")
+		return i + 2
+	case i == 0 && (m.at(i+1) == 'Y' || m.is(i+1, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		m.add("K", "J")
+		return i + 2
+	case (m.is(i+1, "ER") || m.at(i+1) == 'Y') && !m.is(0, "DANGER", "RANGER", "MANGER") &&
+		m.at(i-1) != 'E' && m.at(i-1) != 'I' && !m.is(i-1, "RGY", "OGY"):
+		m.add("K", "J") // "-ger-", "-gy-"
+		return i + 2
+	case strings.IndexByte("EIY", m.at(i+1)) >= 0 && m.at(i+1) != 0 || m.is(i-1, "AGGI", "OGGI"):
+		// Italian "biaggi"
+		if m.is(0, "VAN ", "VON ") || m.is(0, "SCH") || m.is(i+1, "ET") {
+			m.both("K")
+		} else if m.is(i+1, "IER") {
+			m.both("J")
+		} else {
+			m.add("J", "K")
+		}
+		return i + 2
+	}
+	m.both("K")
+	return i + skipDouble(m, i, 'G')
+}
+
+func (m *metaphone) encodeJ(i int) int {
+	if m.is(i, "JOSE") || m.is(0, "SAN ") { // Spanish "jose", "san jacinto"
+		if (i == 0 && m.at(i+4) == ' ') || m.is(0, "SAN ") {
+			m.both("H")
+		} else {
+			m.add("J", "H")
+		}
+		return i + 1
+	}
+	switch {
+	case i == 0 && !m.is(i, "JOSE"):
+		m.add("J", "A") // "Yankelovich", "Jankelowicz"
+	case m.vowel(i-1) && !m.slavoGermanic && (m.at(i+1) == 'A' || m.at(i+1) == 'O'):
+		m.add("J", "H") // Spanish "bajador"
+	case i == m.last:
+		m.add("J", "")
+	case strings.IndexByte("LTKSNMBZ", m.at(i+1)) < 0 && !(m.at(i-1) == 'S' || m.at(i-1) == 'K' || m.at(i-1) == 'L'):
+		m.both("J")
+	}
+	return i + skipDouble(m, i, 'J')
+}
+
+func (m *metaphone) encodeS(i int) int {
+	switch {
+	case m.is(i-1, "ISL", "YSL"): // "island", "carlysle"
+		return i + 1
+	case i == 0 && m.is(i, "SUGAR"):
+		m.add("X", "S")
+		return i + 1
+	case m.is(i, "SH"):
+		if m.is(i+1, "HEIM", "HOEK", "HOLM", "HOLZ") { // Germanic
+			m.both("S")
+		} else {
+			m.both("X")
+		}
+		return i + 2
+	case m.is(i, "SIO", "SIA") || m.is(i, "SIAN"): // Italian, Armenian
+		if m.slavoGermanic {
+			m.both("S")
+		} else {
+			m.add("S", "X")
+		}
+		return i + 3
+	case (i == 0 && (m.at(i+1) == 'M' || m.at(i+1) == 'N' || m.at(i+1) == 'L' || m.at(i+1) == 'W')) || m.at(i+1) == 'Z':
+		m.add("S", "X") // German "schmidt" anglicized as "smith"
+		if m.at(i+1) == 'Z' {
+			return i + 2
+		}
+		return i + 1
+	case m.is(i, "SC"):
+		if m.at(i+2) == 'H' {
+			switch {
+			case m.is(i+3, "OO", "ER", "EN", "UY", "ED", "EM"): // Dutch "school"
+				if m.is(i+3, "ER", "EN") {
+					m.add("X", "SK")
+				} else {
+					m.both("SK")
+				}
+			case i == 0 && !m.vowel(3) && m.at(3) != 'W':
+				m.add("X", "S")
+			default:
+				m.both("X")
+			}
+			return i + 3
+		}
+		if strings.IndexByte("IEY", m.at(i+2)) >= 0 && m.at(i+2) != 0 {
+			m.both("S")
+		} else {
+			m.both("SK")
+		}
+		return i + 3
+	}
+	// French "resnais", "artois": final S silent in the primary.
+	if i == m.last && m.is(i-2, "AI", "OI") {
+		m.add("", "S")
+	} else {
+		m.both("S")
+	}
+	if m.at(i+1) == 'S' || m.at(i+1) == 'Z' {
+		return i + 2
+	}
+	return i + 1
+}
+
+func (m *metaphone) encodeT(i int) int {
+	switch {
+	case m.is(i, "TION", "TIA", "TCH"):
+		m.both("X")
+		return i + 3
+	case m.is(i, "TH", "TTH"):
+		if m.is(i+2, "OM", "AM") || m.is(0, "VAN ", "VON ") || m.is(0, "SCH") { // "thomas"
+			m.both("T")
+		} else {
+			m.add("0", "T") // "0" is the theta sound
+		}
+		return i + 2
+	}
+	m.both("T")
+	if m.at(i+1) == 'T' || m.at(i+1) == 'D' {
+		return i + 2
+	}
+	return i + 1
+}
+
+func (m *metaphone) encodeW(i int) int {
+	if m.is(i, "WR") {
+		m.both("R")
+		return i + 2
+	}
+	if i == 0 && (m.vowel(i+1) || m.is(i, "WH")) {
+		if m.vowel(i + 1) {
+			m.add("A", "F") // "Wasserman" / "Vasserman"
+		} else {
+			m.both("A")
+		}
+	}
+	// Polish "filipowicz", and Arnow-style final -OW.
+	if (i == m.last && m.vowel(i-1)) || m.is(i-1, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.is(0, "SCH") {
+		m.add("", "F")
+		return i + 1
+	}
+	if m.is(i, "WICZ", "WITZ") {
+		m.add("TS", "FX")
+		return i + 4
+	}
+	return i + 1
+}
@@ -0,0 +1,233 @@
+package gazetteer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/agnivade/levenshtein"
+)
+
+// Snap is the deterministic pre-pass run on ASR text before the model sees it: it rewrites
+// obvious near-misses of listed names ("Mount Sai" → "Mount Si", "Kalitan Peak" → "Kaleetan
+// Peak") and leaves everything else alone. It follows the prompt's location-safety rule, so
+// the bar is deliberately high. A window of words is rewritten only when all of these hold:
+//
+//   - it has the same number of words as a listed name or alias and isn't itself a listed
+//     name (a real place is never swapped for another);
+//   - every differing word starts with the same letter and shares a Double Metaphone code with
+//     its counterpart, and words with digits match exactly;
+//   - the whole window is within one edit of the name (two for names of six letters or more);
+//   - for multi-word names, at least one word already matches exactly;
+//   - for one-word names, the word is capitalized mid-sentence, which is how ASR marks proper
+//     nouns, so "bearing 270" never becomes "Baring 270";
+//   - exactly one listed place qualifies.
+//
+// Longer names are tried first, and a listed name already in the text is kept as-is.
+
+// Substitution is one Snap rewrite.
+type Substitution struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// snapForm is one name or alias prepared for Snap.
+type snapForm struct {
+	text  string // written as in the file, which is what Snap substitutes
+	name  string // owning place, to detect ambiguity between places
+	words []snapWord
+	norm  string
+}
+
+type snapWord struct {
+	norm      string
+	primary   string
+	alternate string
+	digits    bool
+}
+
+func newSnapWord(norm string) snapWord {
+	p, a := doubleMetaphone(norm)
+	return snapWord{norm: norm, primary: p, alternate: a, digits: strings.ContainsAny(norm, "0123456789")}
+}
+
+// soundsLike reports whether two differing words are a plausible ASR confusion.
+func (w snapWord) soundsLike(o snapWord) bool {
+	if w.digits || o.digits || w.primary == "" || w.norm[0] != o.norm[0] {
+		return false
+	}
+	return w.primary == o.primary || w.primary == o.alternate || w.alternate == o.primary || w.alternate == o.alternate
+}
+
+// buildSnapForms prepares every name and alias, grouped by word count.
+func (g *Gazetteer) buildSnapForms() {
+	g.snapForms = make(map[int][]snapForm)
+	for _, p := range g.places {
+		for _, form := range append([]string{p.Name}, p.Aliases...) {
+			norm := normalize(form)
+			if norm == "" {
+				continue
+			}
+			sf := snapForm{text: form, name: p.Name, norm: norm}
+			for _, w := range strings.Fields(norm) {
+				sf.words = append(sf.words, newSnapWord(w))
+			}
+			g.snapForms[len(sf.words)] = append(g.snapForms[len(sf.words)], sf)
+			if len(sf.words) > g.maxSnapWords {
+				g.maxSnapWords = len(sf.words)
+			}
+		}
+	}
+}
+
+// snapToken is one word of the input with its byte span.
+type snapToken struct {
+	start, end int
+	word       snapWord
+	proper     bool // capitalized and not the first word of a sentence
+}
+
+// Snap returns text with obvious place-name near-misses corrected, and the corrections made
+// in order. Text without any is returned unchanged with no substitutions.
+func (g *Gazetteer) Snap(text string) (string, []Substitution) {
+	tokens := tokenize(text)
+	var (
+		out  strings.Builder
+		subs []Substitution
+		last int // end of the text already copied to out
+	)
+	for i := 0; i < len(tokens); {
+		n, form := g.snapAt(text, tokens, i)
+		if n == 0 {
+			i++
+			continue
+		}
+		if form != nil {
+			start, end := tokens[i].start, tokens[i+n-1].end
+			out.WriteString(text[last:start])
+			out.WriteString(form.text)
+			subs = append(subs, Substitution{From: text[start:end], To: form.text})
+			last = end
+		}
+		i += n
+	}
+	if len(subs) == 0 {
+		return text, nil
+	}
+	out.WriteString(text[last:])
+	return out.String(), subs
+}
+
+// snapAt looks for a listed name starting at tokens[i], longest first. It returns the number
+// of tokens consumed (0 when nothing matched) and the form to substitute, or nil when the
+// text already has a listed name there.
+func (g *Gazetteer) snapAt(text string, tokens []snapToken, i int) (int, *snapForm) {
+	for n := min(g.maxSnapWords, len(tokens)-i); n >= 1; n-- {
+		window := tokens[i : i+n]
+		if !contiguous(text, window) {
+			continue
+		}
+		words := make([]string, n)
+		for j, t := range window {
+			words[j] = t.word.norm
+		}
+		norm := strings.Join(words, " ")
+		if _, listed := g.byName[norm]; listed {
+			return n, nil
+		}
+		if n == 1 && !window[0].proper {
+			continue
+		}
+		var match *snapForm
+		for k := range g.snapForms[n] {
+			form := &g.snapForms[n][k]
+			if !form.near(window, norm) {
+				continue
+			}
+			if match != nil && match.name != form.name {
+				return 0, nil // two places qualify; leave it to the model
+			}
+			match = form
+		}
+		if match != nil {
+			return n, match
+		}
+	}
+	return 0, nil
+}
+
+// near applies the per-word and whole-window rules from the Snap doc comment.
+func (f *snapForm) near(window []snapToken, norm string) bool {
+	anchored := len(window) == 1
+	for j, t := range window {
+		switch {
+		case t.word.norm == f.words[j].norm:
+			anchored = true
+		case !t.word.soundsLike(f.words[j]):
+			return false
+		}
+	}
+	if !anchored {
+		return false
+	}
+	maxEdits := 1
+	if len(f.norm) >= 6 {
+		maxEdits = 2
+	}
+	return levenshtein.ComputeDistance(norm, f.norm) <= maxEdits
+}
+
+// tokenize splits text into words (letters, digits, apostrophes), recording each word's span
+// and whether it looks like a proper noun.
+func tokenize(text string) []snapToken {
+	var tokens []snapToken
+	sentenceStart := true
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		raw := text[start:end]
+		if norm := normalize(raw); norm != "" && !strings.Contains(norm, " ") {
+			tokens = append(tokens, snapToken{
+				start:  start,
+				end:    end,
+				word:   newSnapWord(norm),
+				proper: !sentenceStart && unicode.IsUpper([]rune(raw)[0]),
+			})
+			sentenceStart = false
+		}
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+		if r == '.' || r == '!' || r == '?' {
+			sentenceStart = true
+		}
+	}
+	flush(len(text))
+	// "Mt." ends an abbreviation, not a sentence.
+	for j := 1; j < len(tokens); j++ {
+		if tokens[j-1].word.norm == "mount" && strings.HasPrefix(text[tokens[j-1].end:], ".") && unicode.IsUpper([]rune(text[tokens[j].start:])[0]) {
+			tokens[j].proper = true
+		}
+	}
+	return tokens
+}
+
+// contiguous reports whether the window's words are separated only by spaces, hyphens or
+// periods ("Mt. Sai", "Poo-Poo Point"), not commas or other punctuation that would join two
+// separate phrases.
+func contiguous(text string, window []snapToken) bool {
+	for j := 1; j < len(window); j++ {
+		if strings.Trim(text[window[j-1].end:window[j].start], " -.") != "" {
+			return false
+		}
+	}
+	return true
+}
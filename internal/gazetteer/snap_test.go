@@ -0,0 +1,86 @@
+package gazetteer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoubleMetaphone(t *testing.T) {
+	cases := map[string]string{
+		"Si":        "S",
+		"Sai":       "S",
+		"Sigh":      "S",
+		"Kaleetan":  "KLTN",
+		"Kalitan":   "KLTN",
+		"Melakwa":   "MLK",
+		"Melaqua":   "MLK",
+		"Thomas":    "TMS",
+		"Knight":    "NT",
+		"Schmidt":   "XMT",
+		"Issaquah":  "ASK",
+		"Phillip":   "FLP",
+		"Sammamish": "SMMX",
+	}
+	for word, want := range cases {
+		primary, _ := doubleMetaphone(word)
+		assert.Equal(t, want, primary, word)
+	}
+
+	primary, alternate := doubleMetaphone("Snoqualmie")
+	assert.Equal(t, "SNKLM", primary)
+	assert.Equal(t, "XNKLM", alternate)
+
+	primary, alternate = doubleMetaphone("123")
+	assert.Empty(t, primary)
+	assert.Empty(t, alternate)
+}
+
+func TestSnap(t *testing.T) {
+	g := Bundled()
+
+	cases := []struct {
+		name, in, want string
+		subs           []Substitution
+	}{
+		{"garbled peak", "hiker on Mount Sigh near the summit", "hiker on Mount Si near the summit",
+			[]Substitution{{From: "Mount Sigh", To: "Mount Si"}}},
+		{"abbreviation and longest name", "Rescue Trail, Mt. Sai trailhead, TAC 3", "Rescue Trail, Mount Si Trailhead, TAC 3",
+			[]Substitution{{From: "Mt. Sai trailhead", To: "Mount Si Trailhead"}}},
+		{"two substitutions", "Kalitan Peak then Melaqua Lake", "Kaleetan Peak then Melakwa Lake",
+			[]Substitution{{From: "Kalitan Peak", To: "Kaleetan Peak"}, {From: "Melaqua Lake", To: "Melakwa Lake"}}},
+		{"one-word name, proper noun mid-sentence", "Responding to Isaqua for a fall", "Responding to Issaquah for a fall",
+			[]Substitution{{From: "Isaqua", To: "Issaquah"}}},
+		{"alias snaps to the alias", "I-90 near Ira Spring Trailhed", "I-90 near Ira Spring Trailhead",
+			[]Substitution{{From: "Ira Spring Trailhed", To: "Ira Spring Trailhead"}}},
+		{"comma keeps names apart", "Rattlesnake Ledge, Mount Sai", "Rattlesnake Ledge, Mount Si",
+			[]Substitution{{From: "Mount Sai", To: "Mount Si"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, subs := g.Snap(tc.in)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.subs, subs)
+		})
+	}
+}
+
+// Everything here must come back untouched: the pre-pass is bound by the same location-safety
+// rule as the prompt.
+func TestSnap_LeavesTextAlone(t *testing.T) {
+	g := Bundled()
+	for _, in := range []string{
+		"Mount Si",
+		"caller at Mount Si Road",         // a listed name is never rewritten into another
+		"Norway Hill trail",               // unlisted place, nothing near it
+		"bearing 270 from the lot",        // lowercase common word vs. Baring
+		"Bearing 270 from the lot",        // sentence-initial capital isn't a proper-noun signal
+		"hiker near Tiger Mountain",       // only West and East Tiger Mountain are listed
+		"responding to Kent station 74",   // exact
+		"Interstate 91 near Interstate 5", // digits must match exactly
+	} {
+		got, subs := g.Snap(in)
+		assert.Equal(t, in, got)
+		assert.Empty(t, subs, in)
+	}
+}
@@ -10,6 +10,24 @@ type DispatchMessage struct {
 	CallType             string `json:"call_type"`
 	TACChannel           string `json:"tac_channel"`
 	CleanedTranscription string `json:"cleaned_transcription"`
+	// Location is where the call was dispatched to, split into parts the gazetteer can
+	// resolve to a map point.
+	Location StructuredLocation `json:"location"`
+}
+
+// StructuredLocation is a location broken into the parts radio traffic actually gives. Every
+// field is optional (empty / zero); the gazetteer resolves the most specific one it can.
+type StructuredLocation struct {
+	// Trailhead is the named trailhead or trail ("Mailbox Peak Trailhead", "Ira Spring Trail").
+	Trailhead string `json:"trailhead"`
+	// Landmark is a named feature: peak, lake, falls, viewpoint ("Rattlesnake Ledge").
+	Landmark string `json:"landmark"`
+	// Address is a street address, road and mile marker, or town ("44300 SE Middle Fork Rd").
+	Address string `json:"address"`
+	// Latitude / Longitude are decimal degrees, ONLY when coordinates were read out on the
+	// radio. Both 0 otherwise.
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
 }
 
 // FIX (review item #3): added ctx parameter so caller-imposed deadlines (WorkerTimeout) and
@@ -24,6 +42,9 @@ type DispatchMessageParser interface {
 type TACTranscript struct {
 	CapturedAt string `json:"captured_at"` // ISO-8601 or HH:MM:SS — the LLM treats it as opaque text
 	Text       string `json:"text"`
+	// Channel is the TAC the transmission was heard on ("TAC8"). Lets the model attribute
+	// traffic when a rescue spans several TACs; empty on older entries.
+	Channel string `json:"channel,omitempty"`
 }
 
 // RescueSummaryInput bundles every piece of context the summarizer needs. The dispatch
@@ -32,7 +53,7 @@ type TACTranscript struct {
 type RescueSummaryInput struct {
 	DispatchTranscription string
 	DispatchCallType      string // "Rescue - Trail", etc. — what the dispatch parser already extracted
-	TACChannel            string // "TAC10", etc.
+	TACChannel            string // "TAC10", or "TAC3, TAC8" for a multi-TAC rescue (primary first)
 	TACTranscripts        []TACTranscript
 
 	// PreviousSummary is the summary produced on the last pass, if any. When set, the model is
@@ -42,6 +63,12 @@ type RescueSummaryInput struct {
 	// couldn't be read), in which case the summarizer behaves exactly as before.
 	PreviousSummary *RescueSummary
 
+	// CompactedTransmissions is how many of the rescue's earliest TAC transmissions have been
+	// folded into PreviousSummary and left out of TACTranscripts, which then holds only the
+	// transmissions since. 0 when TACTranscripts is the whole log (compaction off, or not yet
+	// needed on this rescue).
+	CompactedTransmissions int
+
 	// UnitContext is an optional rendered block describing the units currently assigned to the
 	// call (from the CAD / PulsePoint feed). Empty when the enrichment is disabled or unavailable;
 	// when present it lets the model correct garbled unit callsigns to their canonical form.
@@ -85,6 +112,10 @@ type RescueSummary struct {
 	// or coordinates if mentioned. Empty if unclear.
 	Location string `json:"location"`
 
+	// LocationDetail is Location split into resolvable parts for the map link. Location stays
+	// the human-readable form.
+	LocationDetail StructuredLocation `json:"location_detail"`
+
 	// UnitsInvolved is the list of responding units mentioned by name (e.g. "Engine 8171",
 	// "Battalion 171", "Medic 104"). Best-effort deduplication.
 	UnitsInvolved []string `json:"units_involved"`
@@ -118,3 +149,91 @@ type RescueSummaryEvent struct {
 type RescueSummarizer interface {
 	SummarizeRescue(ctx context.Context, input RescueSummaryInput) (*RescueSummary, error)
 }
+
+// StreamingRescueSummarizer is a RescueSummarizer that can also report the summary while the
+// model is still writing it. onPartial is called, from the calling goroutine, each time the
+// streamed output decodes to a summary with more completed fields than the last call; fields
+// that haven't finished streaming are zero, never truncated. The returned summary is the same
+// one SummarizeRescue would return. Use SummarizeRescueStream rather than asserting this
+// directly, so a backend (or decorator chain) without streaming falls back cleanly.
+type StreamingRescueSummarizer interface {
+	SummarizeRescueStream(ctx context.Context, input RescueSummaryInput, onPartial func(*RescueSummary)) (*RescueSummary, error)
+}
+
+// SummarizeRescueStream streams the summary when s supports it and onPartial is set, and
+// otherwise makes the plain SummarizeRescue call. Decorators implement SummarizeRescue as their
+// SummarizeRescueStream with a nil onPartial, so a non-streaming caller never streams.
+func SummarizeRescueStream(ctx context.Context, s RescueSummarizer, input RescueSummaryInput, onPartial func(*RescueSummary)) (*RescueSummary, error) {
+	if ss, ok := s.(StreamingRescueSummarizer); ok && onPartial != nil {
+		return ss.SummarizeRescueStream(ctx, input, onPartial)
+	}
+	return s.SummarizeRescue(ctx, input)
+}
+
+// DispatchComparisonInput pairs the dispatch that started an active rescue with a new dispatch
+// assigned to the same TAC, so the model can judge whether they describe the same incident.
+type DispatchComparisonInput struct {
+	ActiveDispatch string // dispatch transcription that started the active rescue
+	NewDispatch    string // cleaned transcription of the new dispatch
+	NewCallType    string // call type the dispatch parser extracted for the new dispatch
+}
+
+// DispatchComparison is the structured verdict of a same-incident check. Reason is one short
+// sentence surfaced to operators alongside the split prompt.
+type DispatchComparison struct {
+	SameIncident bool   `json:"same_incident"`
+	Reason       string `json:"reason"`
+}
+
+// IncidentComparer decides whether a re-page onto an active TAC belongs to the active rescue or
+// is an unrelated incident that happened to be assigned the same channel.
+type IncidentComparer interface {
+	CompareDispatches(ctx context.Context, in DispatchComparisonInput) (*DispatchComparison, error)
+}
+
+// AfterActionInput is everything the after-action pass sees when a rescue closes: the
+// dispatch, every TAC transmission, and the last live summary as a starting point.
+type AfterActionInput struct {
+	DispatchTranscription string
+	TACChannel            string // "TAC10", or "TAC3, TAC8" for a multi-TAC rescue (primary first)
+	DispatchedAt          string // "15:04:05"; empty when unknown
+	ClosedAt              string // "15:04:05"
+	// Duration is dispatch-to-close ("1h12m"), computed by the caller. Empty when the dispatch
+	// time is unknown, in which case the model estimates it from the transmission times.
+	Duration       string
+	TACTranscripts []TACTranscript
+	// LatestSummary is the final live interpretation, if one was produced.
+	LatestSummary *RescueSummary
+}
+
+// AfterActionReport is the structured record of a closed rescue.
+type AfterActionReport struct {
+	// Headline is one sentence saying what the incident was and how it ended.
+	Headline string `json:"headline"`
+
+	// Timeline is the full chronological record, not just the highlights the live summary keeps.
+	Timeline []RescueSummaryEvent `json:"timeline"`
+
+	// Units lists every unit that took part, canonical callsigns, deduplicated.
+	Units []string `json:"units"`
+
+	// Duration is dispatch-to-close, e.g. "1h12m".
+	Duration string `json:"duration"`
+
+	// PatientOutcome is the patient's final condition and disposition ("ambulatory, refused
+	// transport", "transported to Harborview"). Empty when there was no patient contact.
+	PatientOutcome string `json:"patient_outcome"`
+
+	// SARInvolvement says whether and how Search and Rescue took part ("Not involved",
+	// "KCSAR notified 13:40, stood down 14:05").
+	SARInvolvement string `json:"sar_involvement"`
+
+	// RadioIssues are communication problems worth reviewing: unreadable or stepped-on
+	// traffic, repeated requests, dead spots, channel confusion. Empty when there were none.
+	RadioIssues []string `json:"radio_issues"`
+}
+
+// AfterActionReporter writes the final report for a closed rescue.
+type AfterActionReporter interface {
+	GenerateAfterActionReport(ctx context.Context, in AfterActionInput) (*AfterActionReport, error)
+}
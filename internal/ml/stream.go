@@ -0,0 +1,116 @@
+package ml
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// SummaryStream turns the text a backend has streamed so far into StreamingRescueSummarizer
+// onPartial calls. Both backends feed it the accumulated response after every delta; it decodes
+// what it can and calls onPartial only when that changed, so a burst of deltas inside one string
+// costs a decode, not a callback.
+type SummaryStream struct {
+	onPartial func(*RescueSummary)
+	last      []byte
+}
+
+// NewSummaryStream returns a SummaryStream reporting to onPartial. A nil onPartial makes
+// Update a no-op.
+func NewSummaryStream(onPartial func(*RescueSummary)) *SummaryStream {
+	empty, _ := json.Marshal(RescueSummary{})
+	return &SummaryStream{onPartial: onPartial, last: empty}
+}
+
+// Update decodes text, the whole response streamed so far, and reports it if it holds more than
+// the last report did.
+func (s *SummaryStream) Update(text string) {
+	if s.onPartial == nil {
+		return
+	}
+	summary, ok := ParsePartialRescueSummary(text)
+	if !ok {
+		return
+	}
+	encoded, err := json.Marshal(summary)
+	if err != nil || bytes.Equal(encoded, s.last) {
+		return
+	}
+	s.last = encoded
+	s.onPartial(summary)
+}
+
+// ParsePartialRescueSummary decodes a prefix of a streamed RescueSummary JSON document. Only
+// values that finished streaming are kept: a string still being written is dropped, not
+// truncated, so a headline never renders half-finished. ok is false when nothing usable has
+// streamed yet (or text isn't JSON at all).
+func ParsePartialRescueSummary(text string) (*RescueSummary, bool) {
+	for _, candidate := range closePartialJSON(text) {
+		var s RescueSummary
+		if json.Unmarshal([]byte(candidate), &s) == nil {
+			return &s, true
+		}
+	}
+	return nil, false
+}
+
+// closePartialJSON returns ways to turn a truncated JSON document into a valid one, best first:
+// the whole prefix with its open objects and arrays closed, then the prefix cut back to the last
+// point where every value before it was complete (after an opening bracket, a closing one, or
+// before a comma). The first fails whenever the prefix ends mid-string, mid-literal or after a
+// key; the second always parses for a well-formed stream.
+func closePartialJSON(s string) []string {
+	var (
+		stack       []byte
+		inString    bool
+		escaped     bool
+		safeLen     = -1
+		safeClosers string
+	)
+	closers := func() string {
+		out := make([]byte, len(stack))
+		for i, c := range stack {
+			out[len(stack)-1-i] = c
+		}
+		return string(out)
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+			safeLen, safeClosers = i+1, closers()
+		case '[':
+			stack = append(stack, ']')
+			safeLen, safeClosers = i+1, closers()
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			safeLen, safeClosers = i+1, closers()
+		case ',':
+			safeLen, safeClosers = i, closers()
+		}
+	}
+
+	var candidates []string
+	if !inString {
+		candidates = append(candidates, s+closers())
+	}
+	if safeLen >= 0 {
+		candidates = append(candidates, s[:safeLen]+safeClosers)
+	}
+	return candidates
+}
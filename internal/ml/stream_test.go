@@ -0,0 +1,79 @@
+package ml
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePartialRescueSummary(t *testing.T) {
+	full, err := json.Marshal(RescueSummary{
+		Headline:      "Hiker with ankle injury on Mailbox Peak",
+		UnitsInvolved: []string{"Engine 8171", "Medic 104"},
+		SARNotified:   true,
+		KeyEvents: []RescueSummaryEvent{
+			{CapturedAt: "13:02:10", Description: "Engine 8171 at trailhead"},
+			{CapturedAt: "13:20:44", Description: "Patient contact \"ambulatory\""},
+		},
+	})
+	require.NoError(t, err)
+
+	// Every prefix decodes to something (once the opening brace is in), and nothing decoded is
+	// ever a truncated string.
+	for i := 1; i <= len(full); i++ {
+		s, ok := ParsePartialRescueSummary(string(full[:i]))
+		require.True(t, ok, "prefix %q", full[:i])
+		assert.Contains(t, []string{"", "Hiker with ankle injury on Mailbox Peak"}, s.Headline)
+		for _, u := range s.UnitsInvolved {
+			assert.Contains(t, []string{"Engine 8171", "Medic 104"}, u)
+		}
+	}
+
+	got, ok := ParsePartialRescueSummary(string(full))
+	require.True(t, ok)
+	assert.Len(t, got.KeyEvents, 2)
+	assert.True(t, got.SARNotified)
+}
+
+func TestParsePartialRescueSummary_Prefixes(t *testing.T) {
+	cases := []struct {
+		prefix   string
+		headline string
+		events   int
+	}{
+		{`{"headline":"Hiker do`, "", 0},
+		{`{"headline":"Hiker down"`, "Hiker down", 0},
+		{`{"headline":"Hiker down",`, "Hiker down", 0},
+		{`{"headline":"Hiker down","sar_notified":tr`, "Hiker down", 0},
+		{`{"headline":"Hiker down","key_events":[{"captured_at":"13:02","description":"On sc`, "Hiker down", 1},
+		{`{"headline":"Hiker down","key_events":[{"captured_at":"13:02","description":"On scene"}`, "Hiker down", 1},
+	}
+	for _, tc := range cases {
+		s, ok := ParsePartialRescueSummary(tc.prefix)
+		require.True(t, ok, tc.prefix)
+		assert.Equal(t, tc.headline, s.Headline, tc.prefix)
+		assert.Len(t, s.KeyEvents, tc.events, tc.prefix)
+	}
+
+	_, ok := ParsePartialRescueSummary("<think>")
+	assert.False(t, ok)
+}
+
+func TestSummaryStream_ReportsOnlyChanges(t *testing.T) {
+	var got []*RescueSummary
+	s := NewSummaryStream(func(r *RescueSummary) { got = append(got, r) })
+
+	s.Update(`{"head`)
+	s.Update(`{"headline":"Hiker do`)
+	s.Update(`{"headline":"Hiker down"`)
+	s.Update(`{"headline":"Hiker down","situ`)
+	s.Update(`{"headline":"Hiker down","situation_summary":"A"`)
+
+	require.Len(t, got, 2)
+	assert.Equal(t, "Hiker down", got[0].Headline)
+	assert.Equal(t, "A", got[1].SituationSummary)
+
+	NewSummaryStream(nil).Update(`{"headline":"x"}`) // no callback, no panic
+}
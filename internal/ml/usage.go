@@ -0,0 +1,73 @@
+package ml
+
+import (
+	"context"
+	"errors"
+)
+
+// Usage is the token count of one model response, as the provider reported it. Backends report
+// it through ReportUsage rather than in their return values, so the client interfaces stay the
+// same and a decorator that wants the counts (dataset capture, metering) asks for them through
+// the context.
+type Usage struct {
+	Backend string // "anthropic" | "openai"
+	Model   string
+
+	InputTokens  int64 // prompt tokens; for Anthropic, excluding the cached prefix below
+	OutputTokens int64
+	// CacheReadTokens / CacheWriteTokens are the prompt tokens Anthropic served from, or wrote
+	// to, its prompt cache. Always 0 for OpenAI-compatible backends, whose cached tokens are
+	// part of InputTokens.
+	CacheReadTokens  int64
+	CacheWriteTokens int64
+}
+
+// Total is every token the response was billed for.
+func (u Usage) Total() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+// ErrBudgetThrottled is returned instead of a summary when the daily token budget is spent and
+// the summarizer is rate limited (LLM_BUDGET_ACTION=throttle). The caller skips this update;
+// the next transmission after the interval refreshes the summary.
+var ErrBudgetThrottled = errors.New("summary skipped: daily LLM token budget exceeded")
+
+type usageSinkKey struct{}
+
+// WithUsageSink returns a context whose model calls report their Usage to sink, and then to any
+// sink ctx already had, so a decorator can observe usage without hiding it from the ones outside
+// it. sink may be called from another goroutine than the one that installed it (the ensemble's
+// consensus runs both backends at once), and more than once when one call reaches several
+// backends.
+func WithUsageSink(ctx context.Context, sink func(context.Context, Usage)) context.Context {
+	parent, _ := ctx.Value(usageSinkKey{}).(func(context.Context, Usage))
+	if parent == nil {
+		return context.WithValue(ctx, usageSinkKey{}, sink)
+	}
+	return context.WithValue(ctx, usageSinkKey{}, func(ctx context.Context, u Usage) {
+		sink(ctx, u)
+		parent(ctx, u)
+	})
+}
+
+// ReportUsage hands u to ctx's usage sinks. A backend calls it once per response, failed or not,
+// whenever the provider reported usage. No-op without a sink.
+func ReportUsage(ctx context.Context, u Usage) {
+	if sink, ok := ctx.Value(usageSinkKey{}).(func(context.Context, Usage)); ok {
+		sink(ctx, u)
+	}
+}
+
+type incidentKey struct{}
+
+// ContextWithIncident tags model calls made for a rescue with its incident ID, so usage can be
+// totalled per incident and the summarizer budget applied per rescue.
+func ContextWithIncident(ctx context.Context, incidentID string) context.Context {
+	return context.WithValue(ctx, incidentKey{}, incidentID)
+}
+
+// IncidentFromContext returns the incident ID set by ContextWithIncident, or "".
+func IncidentFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(incidentKey{}).(string)
+	return id
+}
@@ -0,0 +1,21 @@
+package ml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithUsageSink_ChainsToOuterSinks(t *testing.T) {
+	var outer, inner []Usage
+	ctx := WithUsageSink(context.Background(), func(_ context.Context, u Usage) { outer = append(outer, u) })
+	ctx = WithUsageSink(ctx, func(_ context.Context, u Usage) { inner = append(inner, u) })
+
+	ReportUsage(ctx, Usage{InputTokens: 10, OutputTokens: 2, CacheReadTokens: 5})
+	assert.Len(t, inner, 1)
+	assert.Len(t, outer, 1)
+	assert.Equal(t, int64(17), outer[0].Total())
+
+	ReportUsage(context.Background(), Usage{InputTokens: 1}) // no sink, no panic
+}
@@ -0,0 +1,201 @@
+// Package mlcache caches LLM responses for byte-identical prompts. Pulsar redeliveries, replay
+// runs and the re-page path (handleAdditionalDispatch) routinely send the exact same dispatch,
+// cleanup and comparison prompts to the model more than once; CachingMLClient answers the
+// repeats from Dragonfly instead of paying for, and waiting on, another model call.
+//
+// The cache is best-effort like the dataset decorator it mirrors: a Dragonfly error, a corrupt
+// entry or a failed write degrades to calling the model, never to failing the call. Errors from
+// the model are passed through and never cached.
+package mlcache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/searchandrescuegg/transcribe/internal/prompts"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// cacheKeyFmt is llm_cache:<kind>:<backend>:<model>:<prompt_hash>:<input_hash>. Backend, model
+// and the system-prompt hash are all in the key, so switching models or editing a prompt
+// (including the call-type list or gazetteer baked into it) simply stops matching old entries
+// instead of serving answers to a question nobody asks any more.
+const cacheKeyFmt = "llm_cache:%s:%s:%s:%s:%s"
+
+// Lookup results, recorded as the "result" attribute of the lookup counter.
+const (
+	resultHit    = "hit"
+	resultMiss   = "miss"
+	resultBypass = "bypass"
+)
+
+// Store is the slice of the Dragonfly client the cache needs. Get returns "" with a nil error
+// for a missing key, as dragonfly.DragonflyClient does.
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, ttl time.Duration, value interface{}) error
+}
+
+// mlClient is the capability contract the decorator wraps. It is structurally identical to
+// transcribe.MLClient but declared here so this package doesn't import transcribe.
+type mlClient interface {
+	ml.DispatchMessageParser
+	ml.RescueSummarizer
+	ml.TranscriptCleaner
+	ml.IncidentComparer
+	ml.AfterActionReporter
+}
+
+// Options carries the static metadata that goes into every cache key. The fields mean what
+// they do in dataset.DecoratorOptions.
+type Options struct {
+	Backend          string               // "anthropic" | "openai"
+	DispatchModel    string               // model that runs the dispatch parser and comparison
+	SummaryModel     string               // model that runs the rescue summarizer and after-action report
+	CleanupModel     string               // model that runs the per-transmission TAC cleanup
+	AllowedCallTypes []string             // needed to hash the exact dispatch system prompt in use
+	Places           *gazetteer.Gazetteer // likewise for the place-name reference; nil is the bundled one
+	TTL              time.Duration        // how long an answer is reused
+	Meter            metric.Meter         // nil uses the global meter provider
+}
+
+// CachingMLClient wraps an MLClient and answers repeated identical calls from Dragonfly. It
+// satisfies transcribe.MLClient and drops in transparently.
+//
+// Summaries are cached only on the first pass. A rescue summary that extends PreviousSummary is
+// a function of a summary that changes on every transmission, so the same key effectively never
+// comes round twice and caching it would only fill Dragonfly; those calls bypass the cache.
+type CachingMLClient struct {
+	inner   mlClient
+	store   Store
+	opts    Options
+	lookups metric.Int64Counter
+
+	// Prompt hashes are stable for the life of the process, so compute them once.
+	dispatchPromptHash string
+	summaryPromptHash  string
+	cleanupPromptHash  string
+	comparePromptHash  string
+	reportPromptHash   string
+}
+
+// NewCachingMLClient wraps inner so identical calls within opts.TTL are served from store.
+func NewCachingMLClient(inner mlClient, store Store, opts Options) (*CachingMLClient, error) {
+	meter := opts.Meter
+	if meter == nil {
+		meter = otel.Meter("github.com/searchandrescuegg/transcribe/internal/mlcache")
+	}
+	lookups, err := meter.Int64Counter("llm_cache.lookups",
+		metric.WithDescription("LLM response cache lookups by call kind and result (hit, miss, bypass)"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create llm cache counter: %w", err)
+	}
+	return &CachingMLClient{
+		inner:              inner,
+		store:              store,
+		opts:               opts,
+		lookups:            lookups,
+		dispatchPromptHash: hashString(prompts.DispatchSystemPrompt(opts.AllowedCallTypes, opts.Places)),
+		summaryPromptHash:  hashString(prompts.RescueSummarySystemPrompt(opts.Places)),
+		cleanupPromptHash:  hashString(prompts.TACCleanupSystemPrompt(opts.Places)),
+		comparePromptHash:  hashString(prompts.DispatchComparisonSystemPrompt),
+		reportPromptHash:   hashString(prompts.AfterActionSystemPrompt),
+	}, nil
+}
+
+func (c *CachingMLClient) ParseRelevantInformationFromDispatchMessage(ctx context.Context, transcription string) (*ml.DispatchMessages, error) {
+	key := c.key("dispatch_parse", c.opts.DispatchModel, c.dispatchPromptHash, transcription)
+	return cached(ctx, c, "dispatch_parse", key, func() (*ml.DispatchMessages, error) {
+		return c.inner.ParseRelevantInformationFromDispatchMessage(ctx, transcription)
+	})
+}
+
+func (c *CachingMLClient) SummarizeRescue(ctx context.Context, input ml.RescueSummaryInput) (*ml.RescueSummary, error) {
+	return c.SummarizeRescueStream(ctx, input, nil)
+}
+
+// SummarizeRescueStream streams on a miss or bypass. A hit returns at once without partials.
+func (c *CachingMLClient) SummarizeRescueStream(ctx context.Context, input ml.RescueSummaryInput, onPartial func(*ml.RescueSummary)) (*ml.RescueSummary, error) {
+	if input.PreviousSummary != nil {
+		c.count(ctx, "rescue_summary", resultBypass)
+		return ml.SummarizeRescueStream(ctx, c.inner, input, onPartial)
+	}
+	key := c.key("rescue_summary", c.opts.SummaryModel, c.summaryPromptHash, prompts.BuildRescueSummaryUserPrompt(input))
+	return cached(ctx, c, "rescue_summary", key, func() (*ml.RescueSummary, error) {
+		return ml.SummarizeRescueStream(ctx, c.inner, input, onPartial)
+	})
+}
+
+func (c *CachingMLClient) CleanTACTranscript(ctx context.Context, in ml.TACCleanupInput) (*ml.TACCleanupResult, error) {
+	key := c.key("tac_cleanup", c.opts.CleanupModel, c.cleanupPromptHash, prompts.BuildTACCleanupUserPrompt(in))
+	return cached(ctx, c, "tac_cleanup", key, func() (*ml.TACCleanupResult, error) {
+		return c.inner.CleanTACTranscript(ctx, in)
+	})
+}
+
+// CompareDispatches runs on the dispatch model in both backends, so it is keyed under it.
+func (c *CachingMLClient) CompareDispatches(ctx context.Context, in ml.DispatchComparisonInput) (*ml.DispatchComparison, error) {
+	key := c.key("dispatch_compare", c.opts.DispatchModel, c.comparePromptHash, prompts.BuildDispatchComparisonUserPrompt(in))
+	return cached(ctx, c, "dispatch_compare", key, func() (*ml.DispatchComparison, error) {
+		return c.inner.CompareDispatches(ctx, in)
+	})
+}
+
+// GenerateAfterActionReport runs on the summary model in both backends, so it is keyed under it.
+func (c *CachingMLClient) GenerateAfterActionReport(ctx context.Context, in ml.AfterActionInput) (*ml.AfterActionReport, error) {
+	key := c.key("after_action", c.opts.SummaryModel, c.reportPromptHash, prompts.BuildAfterActionUserPrompt(in))
+	return cached(ctx, c, "after_action", key, func() (*ml.AfterActionReport, error) {
+		return c.inner.GenerateAfterActionReport(ctx, in)
+	})
+}
+
+// cached serves key from the store when it holds a decodable answer, and otherwise calls the
+// model and stores a successful answer. A generic function rather than a method because Go
+// methods can't take type parameters.
+func cached[T any](ctx context.Context, c *CachingMLClient, kind, key string, call func() (*T, error)) (*T, error) {
+	raw, err := c.store.Get(ctx, key)
+	if err != nil {
+		slog.Warn("llm cache: lookup failed; calling the model", slog.String("kind", kind), slog.String("error", err.Error()))
+	} else if raw != "" {
+		var out T
+		if err := json.Unmarshal([]byte(raw), &out); err == nil {
+			c.count(ctx, kind, resultHit)
+			return &out, nil
+		}
+		slog.Warn("llm cache: entry is not decodable; calling the model", slog.String("kind", kind))
+	}
+	c.count(ctx, kind, resultMiss)
+
+	out, callErr := call()
+	if callErr != nil || out == nil {
+		return out, callErr
+	}
+	payload, err := json.Marshal(out)
+	if err != nil {
+		return out, nil
+	}
+	if err := c.store.Set(ctx, key, c.opts.TTL, string(payload)); err != nil {
+		slog.Warn("llm cache: store failed", slog.String("kind", kind), slog.String("error", err.Error()))
+	}
+	return out, nil
+}
+
+func (c *CachingMLClient) key(kind, model, promptHash, input string) string {
+	return fmt.Sprintf(cacheKeyFmt, kind, c.opts.Backend, model, promptHash, hashString(input))
+}
+
+func (c *CachingMLClient) count(ctx context.Context, kind, result string) {
+	c.lookups.Add(ctx, 1, metric.WithAttributes(attribute.String("kind", kind), attribute.String("result", result)))
+}
+
+func hashString(s string) string {
+	return fmt.Sprintf("%016x", xxhash.Sum64String(s))
+}
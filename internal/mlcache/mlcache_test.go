@@ -0,0 +1,185 @@
+package mlcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+type fakeInner struct {
+	calls       int
+	dispatchOut *ml.DispatchMessages
+	dispatchErr error
+	summaryOut  *ml.RescueSummary
+}
+
+func (f *fakeInner) ParseRelevantInformationFromDispatchMessage(context.Context, string) (*ml.DispatchMessages, error) {
+	f.calls++
+	return f.dispatchOut, f.dispatchErr
+}
+
+func (f *fakeInner) SummarizeRescue(context.Context, ml.RescueSummaryInput) (*ml.RescueSummary, error) {
+	f.calls++
+	return f.summaryOut, nil
+}
+
+func (f *fakeInner) CleanTACTranscript(context.Context, ml.TACCleanupInput) (*ml.TACCleanupResult, error) {
+	f.calls++
+	return &ml.TACCleanupResult{CleanedText: "cleaned"}, nil
+}
+
+func (f *fakeInner) CompareDispatches(context.Context, ml.DispatchComparisonInput) (*ml.DispatchComparison, error) {
+	f.calls++
+	return &ml.DispatchComparison{}, nil
+}
+
+func (f *fakeInner) GenerateAfterActionReport(context.Context, ml.AfterActionInput) (*ml.AfterActionReport, error) {
+	f.calls++
+	return &ml.AfterActionReport{}, nil
+}
+
+type fakeStore struct {
+	entries map[string]string
+	ttls    map[string]time.Duration
+	getErr  error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{entries: map[string]string{}, ttls: map[string]time.Duration{}}
+}
+
+func (s *fakeStore) Get(_ context.Context, key string) (string, error) {
+	if s.getErr != nil {
+		return "", s.getErr
+	}
+	return s.entries[key], nil
+}
+
+func (s *fakeStore) Set(_ context.Context, key string, ttl time.Duration, value interface{}) error {
+	s.entries[key] = value.(string)
+	s.ttls[key] = ttl
+	return nil
+}
+
+func newClient(t *testing.T, inner *fakeInner, store *fakeStore, reader sdkmetric.Reader) *CachingMLClient {
+	t.Helper()
+	opts := Options{Backend: "anthropic", DispatchModel: "haiku", SummaryModel: "sonnet", CleanupModel: "haiku", TTL: time.Hour}
+	if reader != nil {
+		opts.Meter = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	}
+	c, err := NewCachingMLClient(inner, store, opts)
+	require.NoError(t, err)
+	return c
+}
+
+func TestCachingMLClient_IdenticalDispatchIsServedFromCache(t *testing.T) {
+	inner := &fakeInner{dispatchOut: &ml.DispatchMessages{Transcription: "Rescue Trail, TAC 3"}}
+	store := newFakeStore()
+	c := newClient(t, inner, store, nil)
+
+	first, err := c.ParseRelevantInformationFromDispatchMessage(context.Background(), "rescue trail tac 3")
+	require.NoError(t, err)
+	second, err := c.ParseRelevantInformationFromDispatchMessage(context.Background(), "rescue trail tac 3")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, inner.calls, "the repeat must not reach the model")
+	assert.Equal(t, first, second)
+	require.Len(t, store.ttls, 1)
+	for _, ttl := range store.ttls {
+		assert.Equal(t, time.Hour, ttl)
+	}
+
+	_, err = c.ParseRelevantInformationFromDispatchMessage(context.Background(), "rescue trail tac 8")
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls, "a different transcription is a different key")
+}
+
+func TestCachingMLClient_KeyIncludesBackendAndModel(t *testing.T) {
+	store := newFakeStore()
+	inner := &fakeInner{dispatchOut: &ml.DispatchMessages{}}
+	a := newClient(t, inner, store, nil)
+	b, err := NewCachingMLClient(inner, store, Options{Backend: "openai", DispatchModel: "gpt-4o-mini", TTL: time.Hour})
+	require.NoError(t, err)
+
+	_, _ = a.ParseRelevantInformationFromDispatchMessage(context.Background(), "same text")
+	_, _ = b.ParseRelevantInformationFromDispatchMessage(context.Background(), "same text")
+	assert.Equal(t, 2, inner.calls)
+	assert.Len(t, store.entries, 2)
+}
+
+func TestCachingMLClient_ErrorsAreNotCached(t *testing.T) {
+	inner := &fakeInner{dispatchErr: errors.New("overloaded")}
+	store := newFakeStore()
+	c := newClient(t, inner, store, nil)
+
+	_, err := c.ParseRelevantInformationFromDispatchMessage(context.Background(), "x")
+	require.Error(t, err)
+	_, err = c.ParseRelevantInformationFromDispatchMessage(context.Background(), "x")
+	require.Error(t, err)
+	assert.Equal(t, 2, inner.calls)
+	assert.Empty(t, store.entries)
+}
+
+func TestCachingMLClient_StoreFailureFallsThroughToModel(t *testing.T) {
+	inner := &fakeInner{dispatchOut: &ml.DispatchMessages{Transcription: "ok"}}
+	store := newFakeStore()
+	store.getErr = errors.New("connection refused")
+	c := newClient(t, inner, store, nil)
+
+	out, err := c.ParseRelevantInformationFromDispatchMessage(context.Background(), "x")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", out.Transcription)
+	assert.Equal(t, 1, inner.calls)
+}
+
+func TestCachingMLClient_SummaryWithPreviousSummaryBypasses(t *testing.T) {
+	inner := &fakeInner{summaryOut: &ml.RescueSummary{Headline: "h"}}
+	store := newFakeStore()
+	reader := sdkmetric.NewManualReader()
+	c := newClient(t, inner, store, reader)
+
+	first := ml.RescueSummaryInput{DispatchTranscription: "d", TACTranscripts: []ml.TACTranscript{{Text: "t"}}}
+	_, _ = c.SummarizeRescue(context.Background(), first)
+	_, _ = c.SummarizeRescue(context.Background(), first)
+	assert.Equal(t, 1, inner.calls, "a first-pass summary is cacheable")
+
+	extend := first
+	extend.PreviousSummary = &ml.RescueSummary{Headline: "h"}
+	_, _ = c.SummarizeRescue(context.Background(), extend)
+	_, _ = c.SummarizeRescue(context.Background(), extend)
+	assert.Equal(t, 3, inner.calls, "an extending summary always reaches the model")
+	assert.Len(t, store.entries, 1)
+
+	assert.Equal(t, map[string]int64{"hit": 1, "miss": 1, "bypass": 2}, lookupCounts(t, reader, "rescue_summary"))
+}
+
+// lookupCounts reads the lookup counter for kind, keyed by result.
+func lookupCounts(t *testing.T, reader sdkmetric.Reader, kind string) map[string]int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	out := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "llm_cache.lookups" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				if k, _ := dp.Attributes.Value(attribute.Key("kind")); k.AsString() != kind {
+					continue
+				}
+				r, _ := dp.Attributes.Value(attribute.Key("result"))
+				out[r.AsString()] = dp.Value
+			}
+		}
+	}
+	return out
+}
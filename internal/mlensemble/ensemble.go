@@ -0,0 +1,271 @@
+// Package mlensemble composes two ML backends into one MLClient. FallbackMLClient tries the
+// primary backend and, when it errors or runs past its time budget, retries the call on the
+// secondary, per capability; without it a flaky or overloaded provider fails the dispatch parse
+// and the message nacks. In consensus mode the dispatch parse instead runs on both backends in
+// parallel and only reports a trail rescue when the two agree, so one model's misclassification
+// can't page on its own; disagreements go to an Auditor for review.
+package mlensemble
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+)
+
+// Capability names, as accepted in Options.Capabilities and ML_FALLBACK_CAPABILITIES.
+const (
+	CapabilityDispatch = "dispatch" // dispatch parse and re-page comparison
+	CapabilitySummary  = "summary"  // rescue summary and after-action report
+	CapabilityCleanup  = "cleanup"  // per-transmission TAC cleanup
+)
+
+// mlClient is the capability contract both backends satisfy. It is structurally identical to
+// transcribe.MLClient but declared here so this package doesn't import transcribe.
+type mlClient interface {
+	ml.DispatchMessageParser
+	ml.RescueSummarizer
+	ml.TranscriptCleaner
+	ml.IncidentComparer
+	ml.AfterActionReporter
+}
+
+// Backend is one side of the ensemble. Name labels it in logs and audit posts ("anthropic",
+// "openai:gpt-4o-mini").
+type Backend struct {
+	Name   string
+	Client mlClient
+}
+
+// Disagreement is a dispatch the two parsers classified differently in consensus mode. The
+// call-type lists hold only the trail-rescue call types each side found (empty: none).
+type Disagreement struct {
+	Transcription      string
+	Primary            string
+	Secondary          string
+	PrimaryCallTypes   []string
+	SecondaryCallTypes []string
+}
+
+// Auditor receives consensus disagreements. Implementations must be best-effort; the dispatch
+// path doesn't wait on, or care about, the outcome.
+type Auditor interface {
+	DispatchDisagreement(ctx context.Context, d Disagreement)
+}
+
+// Options configures a FallbackMLClient.
+type Options struct {
+	// Capabilities lists which calls fall back to the secondary (CapabilityDispatch, ...).
+	// Capabilities not listed run on the primary only.
+	Capabilities []string
+
+	// PrimaryTimeout bounds each primary attempt so there is time left in the worker's budget
+	// for the secondary. Zero leaves the primary bounded only by the caller's context.
+	PrimaryTimeout time.Duration
+
+	// Consensus runs the dispatch parse on both backends in parallel and reports a trail rescue
+	// only when both find the same trail-rescue call types. IsTrailRescue decides which call
+	// types count (transcribe.CallIsTrailRescue in production); Auditor, if set, is told about
+	// every disagreement.
+	Consensus     bool
+	IsTrailRescue func(callType string) bool
+	Auditor       Auditor
+}
+
+// FallbackMLClient satisfies transcribe.MLClient over a primary and a secondary Backend.
+type FallbackMLClient struct {
+	primary   Backend
+	secondary Backend
+	opts      Options
+	fallback  map[string]bool
+}
+
+// NewFallbackMLClient composes primary and secondary. It rejects unknown capability names and
+// a consensus configuration without IsTrailRescue.
+func NewFallbackMLClient(primary, secondary Backend, opts Options) (*FallbackMLClient, error) {
+	fallback := make(map[string]bool, len(opts.Capabilities))
+	for _, c := range opts.Capabilities {
+		c = strings.ToLower(strings.TrimSpace(c))
+		switch c {
+		case "":
+			continue
+		case CapabilityDispatch, CapabilitySummary, CapabilityCleanup:
+			fallback[c] = true
+		default:
+			return nil, fmt.Errorf("unknown ML fallback capability %q; expected %s, %s or %s", c, CapabilityDispatch, CapabilitySummary, CapabilityCleanup)
+		}
+	}
+	if opts.Consensus && opts.IsTrailRescue == nil {
+		return nil, errors.New("ML consensus requires a trail-rescue classifier")
+	}
+	return &FallbackMLClient{primary: primary, secondary: secondary, opts: opts, fallback: fallback}, nil
+}
+
+func (f *FallbackMLClient) ParseRelevantInformationFromDispatchMessage(ctx context.Context, transcription string) (*ml.DispatchMessages, error) {
+	if f.opts.Consensus {
+		return f.consensusParse(ctx, transcription)
+	}
+	return withFallback(ctx, f, CapabilityDispatch, "dispatch_parse", func(ctx context.Context, c mlClient) (*ml.DispatchMessages, error) {
+		return c.ParseRelevantInformationFromDispatchMessage(ctx, transcription)
+	})
+}
+
+func (f *FallbackMLClient) SummarizeRescue(ctx context.Context, input ml.RescueSummaryInput) (*ml.RescueSummary, error) {
+	return f.SummarizeRescueStream(ctx, input, nil)
+}
+
+// SummarizeRescueStream streams from whichever backend is answering. A primary that fails
+// partway has already reported some partials; the secondary's then replace them.
+func (f *FallbackMLClient) SummarizeRescueStream(ctx context.Context, input ml.RescueSummaryInput, onPartial func(*ml.RescueSummary)) (*ml.RescueSummary, error) {
+	return withFallback(ctx, f, CapabilitySummary, "rescue_summary", func(ctx context.Context, c mlClient) (*ml.RescueSummary, error) {
+		return ml.SummarizeRescueStream(ctx, c, input, onPartial)
+	})
+}
+
+func (f *FallbackMLClient) CleanTACTranscript(ctx context.Context, in ml.TACCleanupInput) (*ml.TACCleanupResult, error) {
+	return withFallback(ctx, f, CapabilityCleanup, "tac_cleanup", func(ctx context.Context, c mlClient) (*ml.TACCleanupResult, error) {
+		return c.CleanTACTranscript(ctx, in)
+	})
+}
+
+// CompareDispatches is part of the dispatch path (the re-page check), so it follows the
+// dispatch capability.
+func (f *FallbackMLClient) CompareDispatches(ctx context.Context, in ml.DispatchComparisonInput) (*ml.DispatchComparison, error) {
+	return withFallback(ctx, f, CapabilityDispatch, "dispatch_compare", func(ctx context.Context, c mlClient) (*ml.DispatchComparison, error) {
+		return c.CompareDispatches(ctx, in)
+	})
+}
+
+// GenerateAfterActionReport runs on the summary model in both backends, so it follows the
+// summary capability.
+func (f *FallbackMLClient) GenerateAfterActionReport(ctx context.Context, in ml.AfterActionInput) (*ml.AfterActionReport, error) {
+	return withFallback(ctx, f, CapabilitySummary, "after_action", func(ctx context.Context, c mlClient) (*ml.AfterActionReport, error) {
+		return c.GenerateAfterActionReport(ctx, in)
+	})
+}
+
+// withFallback runs call on the primary and, for a fallback capability, on the secondary when
+// the primary fails. A caller whose own context is done gets the primary's error: the worker
+// has given up, and the secondary would only be cut short too. A generic function rather than
+// a method because Go methods can't take type parameters.
+func withFallback[T any](ctx context.Context, f *FallbackMLClient, capability, kind string, call func(context.Context, mlClient) (*T, error)) (*T, error) {
+	if !f.fallback[capability] {
+		return call(ctx, f.primary.Client)
+	}
+
+	primaryCtx, cancel := f.primaryContext(ctx)
+	out, err := call(primaryCtx, f.primary.Client)
+	cancel()
+	if err == nil {
+		return out, nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	slog.Warn("ml fallback: primary failed; retrying on secondary",
+		slog.String("kind", kind),
+		slog.String("primary", f.primary.Name),
+		slog.String("secondary", f.secondary.Name),
+		slog.String("error", err.Error()))
+
+	out, secondaryErr := call(ctx, f.secondary.Client)
+	if secondaryErr != nil {
+		return nil, fmt.Errorf("%s failed: %w; fallback %s failed: %w", f.primary.Name, err, f.secondary.Name, secondaryErr)
+	}
+	return out, nil
+}
+
+func (f *FallbackMLClient) primaryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if f.opts.PrimaryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, f.opts.PrimaryTimeout)
+}
+
+// consensusParse runs the dispatch parse on both backends at once. When both answer, the
+// primary's result is returned if the two found the same trail-rescue call types; otherwise
+// its trail-rescue messages are dropped, so nothing pages, and the Auditor is told. When only
+// one answers, its result is used as is: consensus is there to stop a lone misclassification,
+// not to turn one provider's outage into missed rescues.
+func (f *FallbackMLClient) consensusParse(ctx context.Context, transcription string) (*ml.DispatchMessages, error) {
+	var (
+		wg                    sync.WaitGroup
+		primaryOut, secondOut *ml.DispatchMessages
+		primaryErr, secondErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		primaryCtx, cancel := f.primaryContext(ctx)
+		defer cancel()
+		primaryOut, primaryErr = f.primary.Client.ParseRelevantInformationFromDispatchMessage(primaryCtx, transcription)
+	}()
+	go func() {
+		defer wg.Done()
+		secondOut, secondErr = f.secondary.Client.ParseRelevantInformationFromDispatchMessage(ctx, transcription)
+	}()
+	wg.Wait()
+
+	switch {
+	case primaryErr != nil && secondErr != nil:
+		return nil, fmt.Errorf("%s failed: %w; %s failed: %w", f.primary.Name, primaryErr, f.secondary.Name, secondErr)
+	case primaryErr != nil:
+		slog.Warn("ml consensus: primary failed; using secondary alone",
+			slog.String("primary", f.primary.Name), slog.String("error", primaryErr.Error()))
+		return secondOut, nil
+	case secondErr != nil:
+		slog.Warn("ml consensus: secondary failed; using primary alone",
+			slog.String("secondary", f.secondary.Name), slog.String("error", secondErr.Error()))
+		return primaryOut, nil
+	}
+
+	primaryTypes, secondTypes := f.trailRescueTypes(primaryOut), f.trailRescueTypes(secondOut)
+	if slices.Equal(primaryTypes, secondTypes) {
+		return primaryOut, nil
+	}
+
+	slog.Warn("ml consensus: dispatch parsers disagree; not alerting",
+		slog.String("primary", f.primary.Name),
+		slog.Any("primary_call_types", primaryTypes),
+		slog.String("secondary", f.secondary.Name),
+		slog.Any("secondary_call_types", secondTypes))
+	if f.opts.Auditor != nil {
+		f.opts.Auditor.DispatchDisagreement(ctx, Disagreement{
+			Transcription:      transcription,
+			Primary:            f.primary.Name,
+			Secondary:          f.secondary.Name,
+			PrimaryCallTypes:   primaryTypes,
+			SecondaryCallTypes: secondTypes,
+		})
+	}
+
+	agreed := &ml.DispatchMessages{Transcription: primaryOut.Transcription}
+	for _, m := range primaryOut.Messages {
+		if !f.opts.IsTrailRescue(m.CallType) {
+			agreed.Messages = append(agreed.Messages, m)
+		}
+	}
+	return agreed, nil
+}
+
+// trailRescueTypes is the sorted, de-duplicated set of trail-rescue call types in out, compared
+// case- and space-insensitively so "Rescue - Trail" and "rescue -  trail" agree.
+func (f *FallbackMLClient) trailRescueTypes(out *ml.DispatchMessages) []string {
+	if out == nil {
+		return nil
+	}
+	var types []string
+	for _, m := range out.Messages {
+		if f.opts.IsTrailRescue(m.CallType) {
+			types = append(types, strings.Join(strings.Fields(strings.ToLower(m.CallType)), " "))
+		}
+	}
+	slices.Sort(types)
+	return slices.Compact(types)
+}
@@ -0,0 +1,203 @@
+package mlensemble
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	calls       int
+	delay       time.Duration
+	dispatchOut *ml.DispatchMessages
+	err         error
+}
+
+func (f *fakeBackend) wait(ctx context.Context) error {
+	f.calls++
+	if f.delay == 0 {
+		return f.err
+	}
+	select {
+	case <-time.After(f.delay):
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (f *fakeBackend) ParseRelevantInformationFromDispatchMessage(ctx context.Context, _ string) (*ml.DispatchMessages, error) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	return f.dispatchOut, nil
+}
+
+func (f *fakeBackend) SummarizeRescue(ctx context.Context, _ ml.RescueSummaryInput) (*ml.RescueSummary, error) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	return &ml.RescueSummary{}, nil
+}
+
+func (f *fakeBackend) CleanTACTranscript(ctx context.Context, _ ml.TACCleanupInput) (*ml.TACCleanupResult, error) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	return &ml.TACCleanupResult{}, nil
+}
+
+func (f *fakeBackend) CompareDispatches(ctx context.Context, _ ml.DispatchComparisonInput) (*ml.DispatchComparison, error) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	return &ml.DispatchComparison{}, nil
+}
+
+func (f *fakeBackend) GenerateAfterActionReport(ctx context.Context, _ ml.AfterActionInput) (*ml.AfterActionReport, error) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	return &ml.AfterActionReport{}, nil
+}
+
+type recordingAuditor struct{ got []Disagreement }
+
+func (r *recordingAuditor) DispatchDisagreement(_ context.Context, d Disagreement) {
+	r.got = append(r.got, d)
+}
+
+func isTrailRescue(callType string) bool {
+	return strings.Contains(strings.ToLower(callType), "trail")
+}
+
+func dispatch(callTypes ...string) *ml.DispatchMessages {
+	out := &ml.DispatchMessages{Transcription: "raw"}
+	for _, ct := range callTypes {
+		out.Messages = append(out.Messages, ml.DispatchMessage{CallType: ct, TACChannel: "TAC3"})
+	}
+	return out
+}
+
+func newEnsemble(t *testing.T, primary, secondary *fakeBackend, opts Options) *FallbackMLClient {
+	t.Helper()
+	f, err := NewFallbackMLClient(Backend{Name: "anthropic", Client: primary}, Backend{Name: "openai", Client: secondary}, opts)
+	require.NoError(t, err)
+	return f
+}
+
+func TestFallback_PrimaryErrorFallsBack(t *testing.T) {
+	primary := &fakeBackend{err: errors.New("529 overloaded")}
+	secondary := &fakeBackend{dispatchOut: dispatch("Rescue - Trail")}
+	f := newEnsemble(t, primary, secondary, Options{Capabilities: []string{"dispatch"}})
+
+	out, err := f.ParseRelevantInformationFromDispatchMessage(context.Background(), "x")
+	require.NoError(t, err)
+	assert.Equal(t, secondary.dispatchOut, out)
+	assert.Equal(t, 1, secondary.calls)
+}
+
+func TestFallback_PrimaryTimeoutFallsBack(t *testing.T) {
+	primary := &fakeBackend{delay: time.Second}
+	secondary := &fakeBackend{}
+	f := newEnsemble(t, primary, secondary, Options{Capabilities: []string{"summary"}, PrimaryTimeout: 10 * time.Millisecond})
+
+	_, err := f.SummarizeRescue(context.Background(), ml.RescueSummaryInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, secondary.calls)
+}
+
+func TestFallback_OnlyListedCapabilities(t *testing.T) {
+	primary := &fakeBackend{err: errors.New("boom")}
+	secondary := &fakeBackend{}
+	f := newEnsemble(t, primary, secondary, Options{Capabilities: []string{"dispatch"}})
+
+	_, err := f.CleanTACTranscript(context.Background(), ml.TACCleanupInput{})
+	require.Error(t, err)
+	assert.Zero(t, secondary.calls, "cleanup isn't a fallback capability here")
+}
+
+func TestFallback_BothFailReportsBoth(t *testing.T) {
+	primaryErr, secondaryErr := errors.New("primary down"), errors.New("secondary down")
+	f := newEnsemble(t, &fakeBackend{err: primaryErr}, &fakeBackend{err: secondaryErr}, Options{Capabilities: []string{"cleanup"}})
+
+	_, err := f.CleanTACTranscript(context.Background(), ml.TACCleanupInput{})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, primaryErr)
+	assert.ErrorIs(t, err, secondaryErr)
+}
+
+func TestFallback_CallerContextDoneSkipsSecondary(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	secondary := &fakeBackend{}
+	f := newEnsemble(t, &fakeBackend{delay: time.Second}, secondary, Options{Capabilities: []string{"dispatch"}})
+
+	_, err := f.ParseRelevantInformationFromDispatchMessage(ctx, "x")
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, secondary.calls)
+}
+
+func TestNewFallbackMLClient_RejectsUnknownCapability(t *testing.T) {
+	_, err := NewFallbackMLClient(Backend{}, Backend{}, Options{Capabilities: []string{"dispatch", "sumary"}})
+	require.Error(t, err)
+}
+
+func TestConsensus_AgreementPassesPrimaryThrough(t *testing.T) {
+	auditor := &recordingAuditor{}
+	primary := &fakeBackend{dispatchOut: dispatch("Rescue - Trail")}
+	secondary := &fakeBackend{dispatchOut: dispatch("rescue -  trail")}
+	f := newEnsemble(t, primary, secondary, Options{Consensus: true, IsTrailRescue: isTrailRescue, Auditor: auditor})
+
+	out, err := f.ParseRelevantInformationFromDispatchMessage(context.Background(), "x")
+	require.NoError(t, err)
+	assert.Equal(t, primary.dispatchOut, out)
+	assert.Empty(t, auditor.got)
+}
+
+func TestConsensus_DisagreementSuppressesTrailRescueAndAudits(t *testing.T) {
+	auditor := &recordingAuditor{}
+	primary := &fakeBackend{dispatchOut: dispatch("Rescue - Trail", "Aid Emergency")}
+	secondary := &fakeBackend{dispatchOut: dispatch("Aid Emergency")}
+	f := newEnsemble(t, primary, secondary, Options{Consensus: true, IsTrailRescue: isTrailRescue, Auditor: auditor})
+
+	out, err := f.ParseRelevantInformationFromDispatchMessage(context.Background(), "engine 171 rescue trail")
+	require.NoError(t, err)
+	require.Len(t, out.Messages, 1)
+	assert.Equal(t, "Aid Emergency", out.Messages[0].CallType)
+
+	require.Len(t, auditor.got, 1)
+	assert.Equal(t, Disagreement{
+		Transcription:    "engine 171 rescue trail",
+		Primary:          "anthropic",
+		Secondary:        "openai",
+		PrimaryCallTypes: []string{"rescue - trail"},
+	}, auditor.got[0])
+}
+
+func TestConsensus_OneSideFailingUsesTheOther(t *testing.T) {
+	auditor := &recordingAuditor{}
+	secondary := &fakeBackend{dispatchOut: dispatch("Rescue - Trail")}
+	f := newEnsemble(t, &fakeBackend{err: errors.New("down")}, secondary, Options{Consensus: true, IsTrailRescue: isTrailRescue, Auditor: auditor})
+
+	out, err := f.ParseRelevantInformationFromDispatchMessage(context.Background(), "x")
+	require.NoError(t, err)
+	assert.Equal(t, secondary.dispatchOut, out)
+	assert.Empty(t, auditor.got)
+}
+
+func TestFormatDisagreement(t *testing.T) {
+	got := FormatDisagreement(Disagreement{
+		Transcription:    "rescue trail tac 3",
+		Primary:          "anthropic",
+		Secondary:        "openai",
+		PrimaryCallTypes: []string{"rescue - trail"},
+	})
+	assert.Equal(t, ":scales: *Dispatch parsers disagree; no alert posted*\n*anthropic:* rescue - trail\n*openai:* no trail rescue\n```rescue trail tac 3```", got)
+}
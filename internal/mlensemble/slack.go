@@ -0,0 +1,57 @@
+package mlensemble
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// slackPoster is the one Slack call SlackAuditor makes; *slack.Client satisfies it.
+type slackPoster interface {
+	SendMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, string, error)
+}
+
+// SlackAuditor posts consensus disagreements to an audit channel, one message each, so someone
+// can see which parser was right and whether a rescue went unalerted.
+type SlackAuditor struct {
+	client    slackPoster
+	channelID string
+	timeout   time.Duration
+}
+
+// NewSlackAuditor posts to channelID, bounding each post by timeout.
+func NewSlackAuditor(client slackPoster, channelID string, timeout time.Duration) *SlackAuditor {
+	return &SlackAuditor{client: client, channelID: channelID, timeout: timeout}
+}
+
+// DispatchDisagreement posts d. Satisfies Auditor. A failed post is logged and dropped: the
+// disagreement is already in the service log.
+func (a *SlackAuditor) DispatchDisagreement(ctx context.Context, d Disagreement) {
+	postCtx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+	if _, _, _, err := a.client.SendMessageContext(postCtx, a.channelID,
+		slack.MsgOptionText(FormatDisagreement(d), false),
+		slack.MsgOptionAsUser(true),
+	); err != nil {
+		slog.Warn("ml consensus: failed to post disagreement to audit channel", slog.String("error", err.Error()))
+	}
+}
+
+// FormatDisagreement renders d as the audit message text.
+func FormatDisagreement(d Disagreement) string {
+	return fmt.Sprintf(":scales: *Dispatch parsers disagree; no alert posted*\n*%s:* %s\n*%s:* %s\n```%s```",
+		d.Primary, callTypeList(d.PrimaryCallTypes),
+		d.Secondary, callTypeList(d.SecondaryCallTypes),
+		d.Transcription)
+}
+
+func callTypeList(types []string) string {
+	if len(types) == 0 {
+		return "no trail rescue"
+	}
+	return strings.Join(types, ", ")
+}
@@ -0,0 +1,318 @@
+// Package mlusage accounts for the tokens LLM calls spend. The backends report each response's
+// usage through ml.ReportUsage; AccountingMLClient picks it up and records it three ways:
+// OpenTelemetry counters by backend, model and call kind (with an estimated cost when the model
+// is priced), a per-incident hash of per-backend totals in Dragonfly, and a per-day counter the
+// optional token budget is checked against.
+//
+// Once the day's total passes the budget, the rescue summarizer (by far the largest and most
+// frequent spender: it re-reads the whole TAC log on every transmission) either moves to a
+// cheaper model or is limited to one pass per incident per interval. Dispatch parsing, cleanup,
+// comparison and after-action reports are never held back; they are small, and the alerts
+// depend on them.
+//
+// Accounting is best-effort like the dataset and cache decorators: a Dragonfly error is logged
+// and the call goes ahead. The budget fails open for the same reason.
+package mlusage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// incidentUsageKeyFmt is llm_usage:<incident_id>, a hash of <backend>:<type> token totals
+	// (type is input, output, cache_read or cache_write) plus <backend>:cost_micros when the
+	// model is priced.
+	incidentUsageKeyFmt = "llm_usage:%s"
+	// dailyTokensKeyFmt is llm_tokens_day:<YYYY-MM-DD>, the day's total across every backend and
+	// kind. The date is in the display timezone, so the budget resets at local midnight.
+	dailyTokensKeyFmt = "llm_tokens_day:%s"
+	// throttleKeyFmt is llm_budget_throttle:<incident_id>, held for BudgetInterval after each
+	// summary pass allowed while over budget.
+	throttleKeyFmt = "llm_budget_throttle:%s"
+
+	// dailyKeyTTL keeps yesterday's counter around long enough to read after midnight.
+	dailyKeyTTL = 48 * time.Hour
+)
+
+// Budget actions (LLM_BUDGET_ACTION).
+const (
+	ActionDowngrade = "downgrade" // summarize with BudgetSummarizer
+	ActionThrottle  = "throttle"  // at most one summary pass per incident per BudgetInterval
+)
+
+// Anthropic bills prompt-cache reads at a tenth of the input price and cache writes at 1.25x.
+const (
+	cacheReadPriceFactor  = 0.1
+	cacheWritePriceFactor = 1.25
+)
+
+// Store is the slice of the Dragonfly client accounting needs. Get returns "" with a nil error
+// for a missing key, as dragonfly.DragonflyClient does.
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	SetNX(ctx context.Context, key string, ttl time.Duration, value interface{}) (bool, error)
+	IncrByEx(ctx context.Context, key string, ttl time.Duration, n int64) (int64, error)
+	HIncrByEx(ctx context.Context, key string, ttl time.Duration, fields map[string]int64) error
+}
+
+// mlClient is the capability contract the decorator wraps. It is structurally identical to
+// transcribe.MLClient but declared here so this package doesn't import transcribe.
+type mlClient interface {
+	ml.DispatchMessageParser
+	ml.RescueSummarizer
+	ml.TranscriptCleaner
+	ml.IncidentComparer
+	ml.AfterActionReporter
+}
+
+// Price is what a model costs, in USD per million tokens.
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// ParsePrices parses LLM_PRICES entries, model name to "<input>/<output>" USD per million
+// tokens ("claude-sonnet-4-5" -> "3/15").
+func ParsePrices(raw map[string]string) (map[string]Price, error) {
+	prices := make(map[string]Price, len(raw))
+	for model, v := range raw {
+		in, out, ok := strings.Cut(v, "/")
+		if !ok {
+			return nil, fmt.Errorf("price for %q must be <input>/<output>, got %q", model, v)
+		}
+		var p Price
+		var err error
+		if p.Input, err = strconv.ParseFloat(strings.TrimSpace(in), 64); err != nil || p.Input < 0 {
+			return nil, fmt.Errorf("invalid input price for %q: %q", model, in)
+		}
+		if p.Output, err = strconv.ParseFloat(strings.TrimSpace(out), 64); err != nil || p.Output < 0 {
+			return nil, fmt.Errorf("invalid output price for %q: %q", model, out)
+		}
+		prices[strings.TrimSpace(model)] = p
+	}
+	return prices, nil
+}
+
+// Options configures accounting and the budget.
+type Options struct {
+	// IncidentRetention is how long a per-incident usage hash is kept after its last write.
+	IncidentRetention time.Duration
+	// Prices estimates cost by model. Unpriced models are counted in tokens only.
+	Prices map[string]Price
+
+	// DailyBudget is the tokens per day after which BudgetAction applies. 0 disables it.
+	DailyBudget  int64
+	BudgetAction string // ActionDowngrade | ActionThrottle
+	// BudgetSummarizer answers SummarizeRescue over budget under ActionDowngrade. Its usage is
+	// reported through the same context, so it is accounted like any other call.
+	BudgetSummarizer ml.RescueSummarizer
+	// BudgetInterval is the minimum gap between an incident's summary passes under
+	// ActionThrottle.
+	BudgetInterval time.Duration
+
+	Meter metric.Meter // nil uses the global meter provider
+}
+
+// AccountingMLClient wraps an MLClient, records the usage of every call made through it and
+// applies the daily budget to the summarizer. It satisfies transcribe.MLClient and drops in
+// transparently. Per-incident totals need the caller to tag the context with
+// ml.ContextWithIncident; untagged calls are still counted in the metrics and the daily total.
+type AccountingMLClient struct {
+	inner mlClient
+	store Store
+	opts  Options
+	now   func() time.Time
+
+	tokens  metric.Int64Counter
+	cost    metric.Float64Counter
+	actions metric.Int64Counter
+}
+
+// NewAccountingMLClient wraps inner, keeping its totals in store.
+func NewAccountingMLClient(inner mlClient, store Store, opts Options) (*AccountingMLClient, error) {
+	if opts.DailyBudget > 0 {
+		switch opts.BudgetAction {
+		case ActionDowngrade:
+			if opts.BudgetSummarizer == nil {
+				return nil, fmt.Errorf("budget action %q needs a budget summarizer", ActionDowngrade)
+			}
+		case ActionThrottle:
+			if opts.BudgetInterval <= 0 {
+				return nil, fmt.Errorf("budget action %q needs a positive interval", ActionThrottle)
+			}
+		default:
+			return nil, fmt.Errorf("unknown budget action %q", opts.BudgetAction)
+		}
+	}
+
+	meter := opts.Meter
+	if meter == nil {
+		meter = otel.Meter("github.com/searchandrescuegg/transcribe/internal/mlusage")
+	}
+	tokens, err := meter.Int64Counter("llm_usage.tokens",
+		metric.WithDescription("LLM tokens by backend, model, call kind and type (input, output, cache_read, cache_write)"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create llm token counter: %w", err)
+	}
+	cost, err := meter.Float64Counter("llm_usage.cost",
+		metric.WithUnit("USD"),
+		metric.WithDescription("Estimated LLM spend by backend, model and call kind, for models with a configured price"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create llm cost counter: %w", err)
+	}
+	actions, err := meter.Int64Counter("llm_budget.actions",
+		metric.WithDescription("Summary passes affected by the daily token budget, by action (downgrade, throttle)"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create llm budget counter: %w", err)
+	}
+	return &AccountingMLClient{
+		inner:   inner,
+		store:   store,
+		opts:    opts,
+		now:     time.Now,
+		tokens:  tokens,
+		cost:    cost,
+		actions: actions,
+	}, nil
+}
+
+func (a *AccountingMLClient) ParseRelevantInformationFromDispatchMessage(ctx context.Context, transcription string) (*ml.DispatchMessages, error) {
+	return a.inner.ParseRelevantInformationFromDispatchMessage(a.track(ctx, "dispatch_parse"), transcription)
+}
+
+func (a *AccountingMLClient) SummarizeRescue(ctx context.Context, input ml.RescueSummaryInput) (*ml.RescueSummary, error) {
+	return a.SummarizeRescueStream(ctx, input, nil)
+}
+
+// SummarizeRescueStream applies the budget, then streams from whichever summarizer it picked.
+// Over budget under ActionThrottle, a pass inside the incident's interval returns
+// ml.ErrBudgetThrottled; an untagged call is never throttled, having no incident to key on.
+func (a *AccountingMLClient) SummarizeRescueStream(ctx context.Context, input ml.RescueSummaryInput, onPartial func(*ml.RescueSummary)) (*ml.RescueSummary, error) {
+	ctx = a.track(ctx, "rescue_summary")
+	if !a.overBudget(ctx) {
+		return ml.SummarizeRescueStream(ctx, a.inner, input, onPartial)
+	}
+
+	switch a.opts.BudgetAction {
+	case ActionDowngrade:
+		a.actions.Add(ctx, 1, metric.WithAttributes(attribute.String("action", ActionDowngrade)))
+		return ml.SummarizeRescueStream(ctx, a.opts.BudgetSummarizer, input, onPartial)
+	case ActionThrottle:
+		if id := ml.IncidentFromContext(ctx); id != "" {
+			acquired, err := a.store.SetNX(ctx, fmt.Sprintf(throttleKeyFmt, id), a.opts.BudgetInterval, "1")
+			if err != nil {
+				slog.Warn("llm budget: throttle check failed; summarizing anyway", slog.String("incident_id", id), slog.String("error", err.Error()))
+			} else if !acquired {
+				a.actions.Add(ctx, 1, metric.WithAttributes(attribute.String("action", ActionThrottle)))
+				return nil, ml.ErrBudgetThrottled
+			}
+		}
+	}
+	return ml.SummarizeRescueStream(ctx, a.inner, input, onPartial)
+}
+
+func (a *AccountingMLClient) CleanTACTranscript(ctx context.Context, in ml.TACCleanupInput) (*ml.TACCleanupResult, error) {
+	return a.inner.CleanTACTranscript(a.track(ctx, "tac_cleanup"), in)
+}
+
+func (a *AccountingMLClient) CompareDispatches(ctx context.Context, in ml.DispatchComparisonInput) (*ml.DispatchComparison, error) {
+	return a.inner.CompareDispatches(a.track(ctx, "dispatch_compare"), in)
+}
+
+func (a *AccountingMLClient) GenerateAfterActionReport(ctx context.Context, in ml.AfterActionInput) (*ml.AfterActionReport, error) {
+	return a.inner.GenerateAfterActionReport(a.track(ctx, "after_action"), in)
+}
+
+// track returns ctx with a usage sink that accounts each response under kind.
+func (a *AccountingMLClient) track(ctx context.Context, kind string) context.Context {
+	return ml.WithUsageSink(ctx, func(ctx context.Context, u ml.Usage) {
+		a.account(ctx, kind, u)
+	})
+}
+
+func (a *AccountingMLClient) account(ctx context.Context, kind string, u ml.Usage) {
+	byType := []struct {
+		name string
+		n    int64
+	}{
+		{"input", u.InputTokens},
+		{"output", u.OutputTokens},
+		{"cache_read", u.CacheReadTokens},
+		{"cache_write", u.CacheWriteTokens},
+	}
+	fields := make(map[string]int64, len(byType)+1)
+	for _, t := range byType {
+		if t.n == 0 {
+			continue
+		}
+		a.tokens.Add(ctx, t.n, metric.WithAttributes(
+			attribute.String("backend", u.Backend),
+			attribute.String("model", u.Model),
+			attribute.String("kind", kind),
+			attribute.String("type", t.name),
+		))
+		fields[u.Backend+":"+t.name] = t.n
+	}
+	if p, ok := a.opts.Prices[u.Model]; ok {
+		usd := cost(p, u)
+		a.cost.Add(ctx, usd, metric.WithAttributes(
+			attribute.String("backend", u.Backend),
+			attribute.String("model", u.Model),
+			attribute.String("kind", kind),
+		))
+		fields[u.Backend+":cost_micros"] = int64(math.Round(usd * 1e6))
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	if _, err := a.store.IncrByEx(ctx, a.dailyKey(), dailyKeyTTL, u.Total()); err != nil {
+		slog.Warn("llm usage: failed to add to the daily total", slog.String("error", err.Error()))
+	}
+	if id := ml.IncidentFromContext(ctx); id != "" {
+		if err := a.store.HIncrByEx(ctx, fmt.Sprintf(incidentUsageKeyFmt, id), a.opts.IncidentRetention, fields); err != nil {
+			slog.Warn("llm usage: failed to add to the incident total", slog.String("incident_id", id), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// overBudget reports whether today's total has reached DailyBudget. An unreadable counter is
+// treated as under budget.
+func (a *AccountingMLClient) overBudget(ctx context.Context) bool {
+	if a.opts.DailyBudget <= 0 {
+		return false
+	}
+	raw, err := a.store.Get(ctx, a.dailyKey())
+	if err != nil {
+		slog.Warn("llm budget: failed to read the daily total; assuming under budget", slog.String("error", err.Error()))
+		return false
+	}
+	if raw == "" {
+		return false
+	}
+	used, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return false
+	}
+	return used >= a.opts.DailyBudget
+}
+
+func (a *AccountingMLClient) dailyKey() string {
+	return fmt.Sprintf(dailyTokensKeyFmt, a.now().Format("2006-01-02"))
+}
+
+func cost(p Price, u ml.Usage) float64 {
+	input := float64(u.InputTokens) + float64(u.CacheReadTokens)*cacheReadPriceFactor + float64(u.CacheWriteTokens)*cacheWritePriceFactor
+	return (input*p.Input + float64(u.OutputTokens)*p.Output) / 1e6
+}
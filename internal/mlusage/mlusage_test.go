@@ -0,0 +1,215 @@
+package mlusage
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// fakeInner reports a fixed usage from every call, the way a backend would.
+type fakeInner struct {
+	usage ml.Usage
+	calls int
+}
+
+func (f *fakeInner) report(ctx context.Context) {
+	f.calls++
+	ml.ReportUsage(ctx, f.usage)
+}
+
+func (f *fakeInner) ParseRelevantInformationFromDispatchMessage(ctx context.Context, _ string) (*ml.DispatchMessages, error) {
+	f.report(ctx)
+	return &ml.DispatchMessages{}, nil
+}
+
+func (f *fakeInner) SummarizeRescue(ctx context.Context, _ ml.RescueSummaryInput) (*ml.RescueSummary, error) {
+	f.report(ctx)
+	return &ml.RescueSummary{Headline: f.usage.Model}, nil
+}
+
+func (f *fakeInner) CleanTACTranscript(ctx context.Context, _ ml.TACCleanupInput) (*ml.TACCleanupResult, error) {
+	f.report(ctx)
+	return &ml.TACCleanupResult{}, nil
+}
+
+func (f *fakeInner) CompareDispatches(ctx context.Context, _ ml.DispatchComparisonInput) (*ml.DispatchComparison, error) {
+	f.report(ctx)
+	return &ml.DispatchComparison{}, nil
+}
+
+func (f *fakeInner) GenerateAfterActionReport(ctx context.Context, _ ml.AfterActionInput) (*ml.AfterActionReport, error) {
+	f.report(ctx)
+	return &ml.AfterActionReport{}, nil
+}
+
+type fakeStore struct {
+	values map[string]string
+	hashes map[string]map[string]int64
+	ttls   map[string]time.Duration
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{values: map[string]string{}, hashes: map[string]map[string]int64{}, ttls: map[string]time.Duration{}}
+}
+
+func (s *fakeStore) Get(_ context.Context, key string) (string, error) {
+	return s.values[key], nil
+}
+
+func (s *fakeStore) SetNX(_ context.Context, key string, ttl time.Duration, value interface{}) (bool, error) {
+	if _, ok := s.values[key]; ok {
+		return false, nil
+	}
+	s.values[key] = value.(string)
+	s.ttls[key] = ttl
+	return true, nil
+}
+
+func (s *fakeStore) IncrByEx(_ context.Context, key string, ttl time.Duration, n int64) (int64, error) {
+	v, _ := strconv.ParseInt(s.values[key], 10, 64)
+	v += n
+	s.values[key] = strconv.FormatInt(v, 10)
+	s.ttls[key] = ttl
+	return v, nil
+}
+
+func (s *fakeStore) HIncrByEx(_ context.Context, key string, ttl time.Duration, fields map[string]int64) error {
+	h := s.hashes[key]
+	if h == nil {
+		h = map[string]int64{}
+		s.hashes[key] = h
+	}
+	for f, n := range fields {
+		h[f] += n
+	}
+	s.ttls[key] = ttl
+	return nil
+}
+
+var fixedNow = time.Date(2026, 7, 4, 13, 0, 0, 0, time.Local)
+
+func newClient(t *testing.T, inner *fakeInner, store *fakeStore, opts Options, reader sdkmetric.Reader) *AccountingMLClient {
+	t.Helper()
+	if reader != nil {
+		opts.Meter = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	}
+	c, err := NewAccountingMLClient(inner, store, opts)
+	require.NoError(t, err)
+	c.now = func() time.Time { return fixedNow }
+	return c
+}
+
+func TestAccounting_RecordsIncidentDailyAndMetrics(t *testing.T) {
+	inner := &fakeInner{usage: ml.Usage{Backend: "anthropic", Model: "sonnet", InputTokens: 1000, OutputTokens: 200, CacheReadTokens: 4000}}
+	store := newFakeStore()
+	reader := sdkmetric.NewManualReader()
+	c := newClient(t, inner, store, Options{
+		IncidentRetention: time.Hour,
+		Prices:            map[string]Price{"sonnet": {Input: 3, Output: 15}},
+	}, reader)
+
+	ctx := ml.ContextWithIncident(context.Background(), "inc-1")
+	_, err := c.SummarizeRescue(ctx, ml.RescueSummaryInput{})
+	require.NoError(t, err)
+	_, err = c.CleanTACTranscript(context.Background(), ml.TACCleanupInput{}) // untagged
+	require.NoError(t, err)
+
+	assert.Equal(t, "10400", store.values["llm_tokens_day:2026-07-04"])
+	assert.Equal(t, dailyKeyTTL, store.ttls["llm_tokens_day:2026-07-04"])
+	// 1000*3 + 4000*0.1*3 + 200*15 = 7200 micro-dollars.
+	assert.Equal(t, map[string]int64{
+		"anthropic:input":       1000,
+		"anthropic:output":      200,
+		"anthropic:cache_read":  4000,
+		"anthropic:cost_micros": 7200,
+	}, store.hashes["llm_usage:inc-1"])
+	assert.Equal(t, time.Hour, store.ttls["llm_usage:inc-1"])
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	inputs := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "llm_usage.tokens" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				typ, _ := dp.Attributes.Value(attribute.Key("type"))
+				kind, _ := dp.Attributes.Value(attribute.Key("kind"))
+				if typ.AsString() == "input" {
+					inputs[kind.AsString()] = dp.Value
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]int64{"rescue_summary": 1000, "tac_cleanup": 1000}, inputs)
+}
+
+func TestBudget_DowngradesSummarizerOverBudget(t *testing.T) {
+	inner := &fakeInner{usage: ml.Usage{Backend: "openai", Model: "big", InputTokens: 600}}
+	cheap := &fakeInner{usage: ml.Usage{Backend: "openai", Model: "small", InputTokens: 600}}
+	store := newFakeStore()
+	c := newClient(t, inner, store, Options{DailyBudget: 1000, BudgetAction: ActionDowngrade, BudgetSummarizer: cheap}, nil)
+
+	ctx := ml.ContextWithIncident(context.Background(), "inc-1")
+	for _, want := range []string{"big", "big", "small"} {
+		s, err := c.SummarizeRescue(ctx, ml.RescueSummaryInput{})
+		require.NoError(t, err)
+		assert.Equal(t, want, s.Headline)
+	}
+	// The downgraded call is accounted too.
+	assert.Equal(t, "1800", store.values["llm_tokens_day:2026-07-04"])
+
+	// Other kinds are never downgraded.
+	_, err := c.CleanTACTranscript(ctx, ml.TACCleanupInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, inner.calls)
+}
+
+func TestBudget_ThrottlesSummaryPassesPerIncident(t *testing.T) {
+	inner := &fakeInner{usage: ml.Usage{Backend: "openai", Model: "big", InputTokens: 600}}
+	store := newFakeStore()
+	store.values["llm_tokens_day:2026-07-04"] = "5000"
+	c := newClient(t, inner, store, Options{DailyBudget: 1000, BudgetAction: ActionThrottle, BudgetInterval: 5 * time.Minute}, nil)
+
+	a := ml.ContextWithIncident(context.Background(), "inc-a")
+	_, err := c.SummarizeRescue(a, ml.RescueSummaryInput{})
+	require.NoError(t, err)
+	_, err = c.SummarizeRescue(a, ml.RescueSummaryInput{})
+	require.ErrorIs(t, err, ml.ErrBudgetThrottled)
+	assert.Equal(t, 5*time.Minute, store.ttls["llm_budget_throttle:inc-a"])
+
+	// Another incident has its own interval.
+	_, err = c.SummarizeRescue(ml.ContextWithIncident(context.Background(), "inc-b"), ml.RescueSummaryInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestNewAccountingMLClient_ValidatesBudget(t *testing.T) {
+	_, err := NewAccountingMLClient(&fakeInner{}, newFakeStore(), Options{DailyBudget: 1, BudgetAction: ActionDowngrade})
+	assert.Error(t, err)
+	_, err = NewAccountingMLClient(&fakeInner{}, newFakeStore(), Options{DailyBudget: 1, BudgetAction: "pause"})
+	assert.Error(t, err)
+	_, err = NewAccountingMLClient(&fakeInner{}, newFakeStore(), Options{BudgetAction: "pause"}) // budget off
+	assert.NoError(t, err)
+}
+
+func TestParsePrices(t *testing.T) {
+	p, err := ParsePrices(map[string]string{"sonnet": "3/15", "haiku": " 0.8 / 4 "})
+	require.NoError(t, err)
+	assert.Equal(t, Price{Input: 3, Output: 15}, p["sonnet"])
+	assert.Equal(t, Price{Input: 0.8, Output: 4}, p["haiku"])
+
+	for _, bad := range []string{"3", "x/15", "3/-1"} {
+		_, err := ParsePrices(map[string]string{"m": bad})
+		assert.Error(t, err, bad)
+	}
+}
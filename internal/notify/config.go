@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Sink types accepted in NOTIFY_SINKS.
+const (
+	SinkTeams   = "teams"
+	SinkWebhook = "webhook"
+	SinkSlack   = "slack"
+)
+
+// SinkSpec is one entry of the NOTIFY_SINKS JSON array, e.g.
+//
+//	[{"name":"king-county","type":"teams","url":"https://…"},
+//	 {"name":"cad","type":"webhook","url":"https://…","secret":"whsec_…","events":["alert","closed"]}]
+//
+// Events defaults per type when omitted: webhooks get everything (they're machine consumers
+// and can filter themselves), while teams and slack sinks get alert + closed, since neither can
+// thread or edit and every reply would otherwise land as its own top-level message.
+type SinkSpec struct {
+	Name   string      `json:"name"`
+	Type   string      `json:"type"`
+	URL    string      `json:"url"`
+	Secret string      `json:"secret,omitempty"`
+	Events []EventType `json:"events,omitempty"`
+}
+
+var knownEvents = map[EventType]bool{
+	EventAlert:              true,
+	EventThreadReply:        true,
+	EventLiveInterpretation: true,
+	EventClosed:             true,
+}
+
+// ParseSinks decodes and validates NOTIFY_SINKS. An empty string means no sinks.
+func ParseSinks(raw string) ([]SinkSpec, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var specs []SinkSpec
+	if err := json.Unmarshal([]byte(raw), &specs); err != nil {
+		return nil, fmt.Errorf("NOTIFY_SINKS is not a valid JSON array: %w", err)
+	}
+
+	seen := make(map[string]bool, len(specs))
+	for i, s := range specs {
+		if s.Name == "" {
+			return nil, fmt.Errorf("NOTIFY_SINKS[%d]: name is required", i)
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("NOTIFY_SINKS[%d]: duplicate name %q", i, s.Name)
+		}
+		seen[s.Name] = true
+		switch s.Type {
+		case SinkTeams, SinkWebhook, SinkSlack:
+		default:
+			return nil, fmt.Errorf("NOTIFY_SINKS[%d] (%s): type must be %q, %q or %q, got %q", i, s.Name, SinkTeams, SinkWebhook, SinkSlack, s.Type)
+		}
+		if s.URL == "" {
+			return nil, fmt.Errorf("NOTIFY_SINKS[%d] (%s): url is required", i, s.Name)
+		}
+		for _, e := range s.Events {
+			if !knownEvents[e] {
+				return nil, fmt.Errorf("NOTIFY_SINKS[%d] (%s): unknown event %q", i, s.Name, e)
+			}
+		}
+	}
+	return specs, nil
+}
+
+// Build constructs a FanOut over specs. client is shared by every sink; its timeout is a
+// backstop — per-delivery deadlines come from the fan-out's timeout.
+func Build(specs []SinkSpec, timeout time.Duration, client *http.Client) (*FanOut, error) {
+	f := NewFanOut(timeout)
+	for _, s := range specs {
+		var (
+			n      Notifier
+			events = s.Events
+		)
+		switch s.Type {
+		case SinkTeams:
+			n = NewTeams(s.Name, s.URL, client)
+		case SinkSlack:
+			n = NewSlackWebhook(s.Name, s.URL, client)
+		case SinkWebhook:
+			w, err := NewWebhook(s.Name, s.URL, s.Secret, client)
+			if err != nil {
+				return nil, fmt.Errorf("sink %s: %w", s.Name, err)
+			}
+			n = w
+		}
+		if len(events) == 0 && s.Type != SinkWebhook {
+			events = []EventType{EventAlert, EventClosed}
+		}
+		f.Add(n, events...)
+	}
+	return f, nil
+}
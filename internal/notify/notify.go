@@ -0,0 +1,216 @@
+// Package notify fans incident lifecycle events (alert, thread reply, live interpretation,
+// closed) out to every sink: the primary Slack channel, and the secondary sinks — Microsoft
+// Teams (Adaptive Cards), signed JSON webhooks (CAD vendors, automation), and Slack incoming
+// webhooks in partner workspaces.
+//
+// The primary sink is the bot-token Slack channel, implemented in internal/transcribe because
+// it owns the rescue thread, the interactive buttons, and the thread_ts state everything else
+// is keyed on. It is attached with FanOut.WithPrimary and runs first; the secondaries are
+// one-way and can't thread, update in place, or act back on the incident. The Slack
+// interactivity controller shares the secondaries without a primary: it writes its own Slack
+// replies and only needs them to hear about a cancelled rescue.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+)
+
+// EventType names an incident lifecycle event. Used on the wire (webhook "type") and in a
+// sink's NOTIFY_SINKS "events" filter.
+type EventType string
+
+const (
+	EventAlert              EventType = "alert"
+	EventThreadReply        EventType = "thread_reply"
+	EventLiveInterpretation EventType = "live_interpretation"
+	EventClosed             EventType = "closed"
+)
+
+// Incident identifies which rescue an event belongs to. ID is the rescue's incident ID (a
+// ULID, or the TAC's TGID for rescues that predate incident IDs) — the key every piece of
+// Dragonfly incident state is indexed by — so a receiver can correlate all events for one
+// rescue, including across a Switch TAC.
+type Incident struct {
+	ID         string `json:"id"`
+	TACChannel string `json:"tac_channel"`
+
+	// Origin is the producer's own handle on the event for the primary sink, which needs more
+	// than the wire payload (for Slack: the thread, the alert's buttons, the audio to upload)
+	// and may hand results back through it (the posted alert's ts). Never serialized;
+	// secondary sinks ignore it.
+	Origin any `json:"-"`
+}
+
+// Alert is emitted once per rescue, after the primary Slack alert posts.
+type Alert struct {
+	Incident
+	CallType      string    `json:"call_type"`
+	Transcription string    `json:"transcription"`
+	DispatchedAt  time.Time `json:"dispatched_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	// ListenURL links to live audio for the dispatch + TAC talkgroups (OpenMHz).
+	ListenURL string `json:"listen_url,omitempty"`
+}
+
+// ThreadReply is emitted for each TAC transmission (and each additional-unit re-page).
+type ThreadReply struct {
+	Incident
+	// Channel is the radio channel's display name ("Fire TAC 3", "Fire Dispatch 1").
+	Channel  string    `json:"channel"`
+	Message  string    `json:"message"`
+	At       time.Time `json:"at"`
+	AudioURL string    `json:"audio_url,omitempty"`
+}
+
+// LiveInterpretation is emitted every time the rolling summary is regenerated.
+type LiveInterpretation struct {
+	Incident
+	Summary   *ml.RescueSummary `json:"summary"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// ClosedReason says how a rescue ended.
+type ClosedReason string
+
+const (
+	// ClosedEnded is a rescue whose monitoring ran out or was closed early from Slack.
+	ClosedEnded ClosedReason = "ended"
+	// ClosedCancelled is a rescue cancelled from Slack as a false alarm.
+	ClosedCancelled ClosedReason = "cancelled"
+)
+
+// Closed is the last event for every rescue, however it ended.
+type Closed struct {
+	Incident
+	Reason   ClosedReason `json:"reason"`
+	ClosedAt time.Time    `json:"closed_at"`
+}
+
+// Notifier is one outbound sink. Implementations must be safe for concurrent use; every
+// method is called with a context bounded by NOTIFY_TIMEOUT.
+type Notifier interface {
+	Name() string
+	Alert(ctx context.Context, e *Alert) error
+	ThreadReply(ctx context.Context, e *ThreadReply) error
+	LiveInterpretation(ctx context.Context, e *LiveInterpretation) error
+	Closed(ctx context.Context, e *Closed) error
+}
+
+// ErrPrimary marks an error from the primary sink, which callers act on (a failed alert or
+// thread reply is retried by redelivery); secondary failures only ever get logged.
+var ErrPrimary = errors.New("primary sink failed")
+
+// FanOut delivers each event to the primary sink first, then to every secondary sink
+// concurrently, each under its own timeout, and waits for all of them.
+//
+// The primary runs without the NOTIFY_TIMEOUT bound (it brings its own retries and queue).
+// When it fails, the event stops there and the error is returned wrapped in ErrPrimary: the
+// caller retries the whole event, and the secondaries receive it once, on the retry. A
+// primary that deals with its own failures returns nil.
+//
+// Secondary failures are logged per sink and joined into the returned error; one slow or
+// broken sink never prevents the others from receiving the event.
+type FanOut struct {
+	primary Notifier
+	sinks   []filteredSink
+	timeout time.Duration
+}
+
+type filteredSink struct {
+	Notifier
+	events map[EventType]bool
+}
+
+func (f filteredSink) wants(t EventType) bool {
+	return len(f.events) == 0 || f.events[t]
+}
+
+// NewFanOut returns an empty fan-out; register sinks with Add.
+func NewFanOut(timeout time.Duration) *FanOut {
+	return &FanOut{timeout: timeout}
+}
+
+// Add registers a sink that receives only the listed event types (all when none are given).
+func (f *FanOut) Add(n Notifier, events ...EventType) {
+	set := make(map[EventType]bool, len(events))
+	for _, e := range events {
+		set[e] = true
+	}
+	f.sinks = append(f.sinks, filteredSink{Notifier: n, events: set})
+}
+
+// WithPrimary returns a fan-out with n as its primary sink, in front of f's secondaries. f is
+// left as it was, so one set of secondaries can serve producers with different primaries (or
+// none).
+func (f *FanOut) WithPrimary(n Notifier) *FanOut {
+	withPrimary := *f
+	withPrimary.primary = n
+	return &withPrimary
+}
+
+// Len reports how many secondary sinks are registered.
+func (f *FanOut) Len() int {
+	return len(f.sinks)
+}
+
+func (f *FanOut) Name() string { return "fanout" }
+
+func (f *FanOut) Alert(ctx context.Context, e *Alert) error {
+	return f.each(ctx, EventAlert, e.ID, func(ctx context.Context, n Notifier) error { return n.Alert(ctx, e) })
+}
+
+func (f *FanOut) ThreadReply(ctx context.Context, e *ThreadReply) error {
+	return f.each(ctx, EventThreadReply, e.ID, func(ctx context.Context, n Notifier) error { return n.ThreadReply(ctx, e) })
+}
+
+func (f *FanOut) LiveInterpretation(ctx context.Context, e *LiveInterpretation) error {
+	return f.each(ctx, EventLiveInterpretation, e.ID, func(ctx context.Context, n Notifier) error { return n.LiveInterpretation(ctx, e) })
+}
+
+func (f *FanOut) Closed(ctx context.Context, e *Closed) error {
+	return f.each(ctx, EventClosed, e.ID, func(ctx context.Context, n Notifier) error { return n.Closed(ctx, e) })
+}
+
+func (f *FanOut) each(ctx context.Context, t EventType, incidentID string, call func(context.Context, Notifier) error) error {
+	if f.primary != nil {
+		if err := call(ctx, f.primary); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrPrimary, f.primary.Name(), err)
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, s := range f.sinks {
+		if !s.wants(t) {
+			continue
+		}
+		wg.Add(1)
+		go func(s filteredSink) {
+			defer wg.Done()
+			sinkCtx, cancel := context.WithTimeout(ctx, f.timeout)
+			defer cancel()
+			if err := call(sinkCtx, s.Notifier); err != nil {
+				slog.Warn("notify: sink delivery failed",
+					slog.String("sink", s.Name()),
+					slog.String("event", string(t)),
+					slog.String("incident", incidentID),
+					slog.String("error", err.Error()))
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+				mu.Unlock()
+			}
+		}(s)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,229 @@
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingNotifier struct {
+	name string
+	err  error
+
+	mu     sync.Mutex
+	events []EventType
+}
+
+func (r *recordingNotifier) Name() string { return r.name }
+
+func (r *recordingNotifier) record(t EventType) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, t)
+	return r.err
+}
+
+func (r *recordingNotifier) Alert(context.Context, *Alert) error { return r.record(EventAlert) }
+func (r *recordingNotifier) ThreadReply(context.Context, *ThreadReply) error {
+	return r.record(EventThreadReply)
+}
+func (r *recordingNotifier) LiveInterpretation(context.Context, *LiveInterpretation) error {
+	return r.record(EventLiveInterpretation)
+}
+func (r *recordingNotifier) Closed(context.Context, *Closed) error { return r.record(EventClosed) }
+
+func TestFanOut_FiltersEventsAndJoinsErrors(t *testing.T) {
+	all := &recordingNotifier{name: "all"}
+	alertsOnly := &recordingNotifier{name: "alerts"}
+	broken := &recordingNotifier{name: "broken", err: errors.New("boom")}
+
+	f := NewFanOut(time.Second)
+	f.Add(all)
+	f.Add(alertsOnly, EventAlert)
+	f.Add(broken, EventClosed)
+
+	ctx := context.Background()
+	require.NoError(t, f.Alert(ctx, &Alert{}))
+	require.NoError(t, f.ThreadReply(ctx, &ThreadReply{}))
+
+	err := f.Closed(ctx, &Closed{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken: boom")
+
+	assert.Equal(t, []EventType{EventAlert, EventThreadReply, EventClosed}, all.events)
+	assert.Equal(t, []EventType{EventAlert}, alertsOnly.events)
+	assert.Equal(t, []EventType{EventClosed}, broken.events)
+}
+
+func TestFanOut_PrimaryFirstAndFailureStopsTheEvent(t *testing.T) {
+	primary := &recordingNotifier{name: "slack"}
+	secondary := &recordingNotifier{name: "teams", err: errors.New("boom")}
+
+	secondaries := NewFanOut(time.Second)
+	secondaries.Add(secondary)
+	f := secondaries.WithPrimary(primary)
+
+	ctx := context.Background()
+	err := f.Alert(ctx, &Alert{})
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrPrimary), "a secondary failure is not the caller's to retry")
+
+	primary.err = errors.New("rate limited")
+	err = f.ThreadReply(ctx, &ThreadReply{})
+	require.ErrorIs(t, err, ErrPrimary)
+	assert.Contains(t, err.Error(), "slack: rate limited")
+
+	assert.Equal(t, []EventType{EventAlert, EventThreadReply}, primary.events)
+	assert.Equal(t, []EventType{EventAlert}, secondary.events, "secondaries only get what the primary delivered")
+
+	// The fan-out WithPrimary was called on keeps no primary.
+	err = secondaries.Closed(ctx, &Closed{Reason: ClosedCancelled})
+	assert.False(t, errors.Is(err, ErrPrimary))
+	assert.Equal(t, []EventType{EventAlert, EventThreadReply}, primary.events)
+	assert.Equal(t, []EventType{EventAlert, EventClosed}, secondary.events)
+}
+
+func TestWebhook_SignsPerStandardWebhooks(t *testing.T) {
+	key := []byte("super-secret-key")
+	var (
+		gotHeaders http.Header
+		gotBody    []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhook("cad", srv.URL, "whsec_"+base64.StdEncoding.EncodeToString(key), srv.Client())
+	require.NoError(t, err)
+	w.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	require.NoError(t, w.Alert(context.Background(), &Alert{
+		Incident: Incident{ID: "1967", TACChannel: "TAC10"},
+		CallType: "Rescue - Trail",
+	}))
+
+	id := gotHeaders.Get("webhook-id")
+	assert.Regexp(t, `^msg_[0-9a-f]{24}$`, id)
+	assert.Equal(t, "1700000000", gotHeaders.Get("webhook-timestamp"))
+	assert.Equal(t, "v1,"+signWebhook(key, id, "1700000000", gotBody), gotHeaders.Get("webhook-signature"))
+
+	var env struct {
+		Type EventType `json:"type"`
+		Data Alert     `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(gotBody, &env))
+	assert.Equal(t, EventAlert, env.Type)
+	assert.Equal(t, "1967", env.Data.ID)
+	assert.Equal(t, "TAC10", env.Data.TACChannel)
+}
+
+func TestWebhook_NonSuccessStatusIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhook("cad", srv.URL, "", srv.Client())
+	require.NoError(t, err)
+	err = w.Closed(context.Background(), &Closed{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+	assert.Contains(t, err.Error(), "nope")
+}
+
+func TestAlertCard_Shape(t *testing.T) {
+	card := AlertCard(&Alert{
+		Incident:      Incident{ID: "1967", TACChannel: "TAC10"},
+		CallType:      "Rescue - Trail",
+		Transcription: "Rescue trail, Mailbox Peak, TAC10",
+		ListenURL:     "https://openmhz.com/system/kcers1b?filter-type=talkgroup&filter-code=1399,1967",
+	})
+
+	raw, err := json.Marshal(card)
+	require.NoError(t, err)
+	var decoded struct {
+		Type    string           `json:"type"`
+		Version string           `json:"version"`
+		Body    []map[string]any `json:"body"`
+		Actions []map[string]any `json:"actions"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, "AdaptiveCard", decoded.Type)
+	assert.Equal(t, "1.4", decoded.Version)
+	require.Len(t, decoded.Body, 3)
+	assert.Equal(t, "FactSet", decoded.Body[1]["type"])
+	// Zero times are dropped rather than rendered as empty facts.
+	assert.Len(t, decoded.Body[1]["facts"], 2)
+	assert.Equal(t, "Monospace", decoded.Body[2]["fontType"])
+	require.Len(t, decoded.Actions, 1)
+	assert.Equal(t, "Action.OpenUrl", decoded.Actions[0]["type"])
+}
+
+func TestLiveInterpretationCard_NilSummary(t *testing.T) {
+	card := LiveInterpretationCard(&LiveInterpretation{Incident: Incident{TACChannel: "TAC10"}})
+	assert.Len(t, card["body"], 2)
+
+	card = LiveInterpretationCard(&LiveInterpretation{
+		Incident: Incident{TACChannel: "TAC10"},
+		Summary: &ml.RescueSummary{
+			Headline:    "Injured hiker on Mailbox Peak",
+			KeyEvents:   []ml.RescueSummaryEvent{{CapturedAt: "14:02", Description: "Crews on scene"}},
+			SARNotified: true,
+		},
+	})
+	// title, headline, facts, one key event, updated.
+	assert.Len(t, card["body"], 5)
+}
+
+func TestParseSinks(t *testing.T) {
+	specs, err := ParseSinks("")
+	require.NoError(t, err)
+	assert.Empty(t, specs)
+
+	specs, err = ParseSinks(`[{"name":"kc","type":"teams","url":"https://example.com/a"},
+		{"name":"cad","type":"webhook","url":"https://example.com/b","secret":"s","events":["alert"]}]`)
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+	assert.Equal(t, []EventType{EventAlert}, specs[1].Events)
+
+	for name, raw := range map[string]string{
+		"not json":      `{`,
+		"missing name":  `[{"type":"teams","url":"u"}]`,
+		"bad type":      `[{"name":"a","type":"discord","url":"u"}]`,
+		"missing url":   `[{"name":"a","type":"teams"}]`,
+		"unknown event": `[{"name":"a","type":"teams","url":"u","events":["opened"]}]`,
+		"duplicate":     `[{"name":"a","type":"teams","url":"u"},{"name":"a","type":"slack","url":"v"}]`,
+	} {
+		_, err := ParseSinks(raw)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestBuild_DefaultEventsPerType(t *testing.T) {
+	specs, err := ParseSinks(`[{"name":"kc","type":"teams","url":"u"},{"name":"cad","type":"webhook","url":"v"}]`)
+	require.NoError(t, err)
+	f, err := Build(specs, time.Second, http.DefaultClient)
+	require.NoError(t, err)
+	require.Equal(t, 2, f.Len())
+
+	assert.True(t, f.sinks[0].wants(EventAlert))
+	assert.False(t, f.sinks[0].wants(EventThreadReply))
+	assert.True(t, f.sinks[1].wants(EventThreadReply))
+
+	_, err = Build([]SinkSpec{{Name: "x", Type: SinkWebhook, URL: "u", Secret: "whsec_!!"}}, time.Second, http.DefaultClient)
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SlackWebhook posts events to a Slack incoming webhook — typically a channel in a mutual-aid
+// partner's workspace where our bot isn't installed. Incoming webhooks can't thread or edit,
+// so each event is a standalone message; like Teams, it is usually filtered to alert + closed.
+type SlackWebhook struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+func NewSlackWebhook(name, url string, client *http.Client) *SlackWebhook {
+	return &SlackWebhook{name: name, url: url, client: client}
+}
+
+func (s *SlackWebhook) Name() string { return s.name }
+
+func (s *SlackWebhook) Alert(ctx context.Context, e *Alert) error {
+	text := fmt.Sprintf(":rotating_light: *Rescue Trail — %s* (%s)\nDispatched %s · monitoring until %s\n```%s```",
+		e.TACChannel, e.CallType, formatTime(e.DispatchedAt), formatTime(e.ExpiresAt), e.Transcription)
+	if e.ListenURL != "" {
+		text += fmt.Sprintf("\n<%s|:headphones: Live Audio>", e.ListenURL)
+	}
+	return s.post(ctx, text)
+}
+
+func (s *SlackWebhook) ThreadReply(ctx context.Context, e *ThreadReply) error {
+	text := fmt.Sprintf("*%s* — %s\n```%s```", e.Channel, formatTime(e.At), e.Message)
+	if e.AudioURL != "" {
+		text += fmt.Sprintf("\n<%s|:loud_sound: Play Clip>", e.AudioURL)
+	}
+	return s.post(ctx, text)
+}
+
+func (s *SlackWebhook) LiveInterpretation(ctx context.Context, e *LiveInterpretation) error {
+	if e.Summary == nil {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, ":dna: *Live Interpretation — %s*\n*%s*\n%s", e.TACChannel, e.Summary.Headline, e.Summary.SituationSummary)
+	if e.Summary.Outcome != "" {
+		fmt.Fprintf(&b, "\n*Outcome:* %s", e.Summary.Outcome)
+	}
+	return s.post(ctx, b.String())
+}
+
+func (s *SlackWebhook) Closed(ctx context.Context, e *Closed) error {
+	if e.Reason == ClosedCancelled {
+		return s.post(ctx, fmt.Sprintf(":octagonal_sign: %s monitoring cancelled (false alarm) %s", e.TACChannel, formatTime(e.ClosedAt)))
+	}
+	return s.post(ctx, fmt.Sprintf(":white_check_mark: %s monitoring closed %s", e.TACChannel, formatTime(e.ClosedAt)))
+}
+
+func (s *SlackWebhook) post(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("marshal slack webhook payload: %w", err)
+	}
+	return postJSON(ctx, s.client, s.url, body, nil)
+}
@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Teams posts each event as an Adaptive Card to a Teams incoming webhook (or a Power Automate
+// "post card to channel" workflow URL, which accepts the same envelope). Cards can't be
+// updated in place through a webhook, so a Teams sink subscribed to live_interpretation gets
+// a new card per refresh — most deployments filter Teams to alert + closed.
+type Teams struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+func NewTeams(name, url string, client *http.Client) *Teams {
+	return &Teams{name: name, url: url, client: client}
+}
+
+func (t *Teams) Name() string { return t.name }
+
+func (t *Teams) Alert(ctx context.Context, e *Alert) error {
+	return t.post(ctx, AlertCard(e))
+}
+
+func (t *Teams) ThreadReply(ctx context.Context, e *ThreadReply) error {
+	return t.post(ctx, ThreadReplyCard(e))
+}
+
+func (t *Teams) LiveInterpretation(ctx context.Context, e *LiveInterpretation) error {
+	return t.post(ctx, LiveInterpretationCard(e))
+}
+
+func (t *Teams) Closed(ctx context.Context, e *Closed) error {
+	return t.post(ctx, ClosedCard(e))
+}
+
+func (t *Teams) post(ctx context.Context, card map[string]any) error {
+	body, err := json.Marshal(map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal adaptive card: %w", err)
+	}
+	return postJSON(ctx, t.client, t.url, body, nil)
+}
+
+// Adaptive Card builders. Exported so the card JSON can be unit-tested and previewed in the
+// Adaptive Cards designer without a live webhook.
+
+func AlertCard(e *Alert) map[string]any {
+	body := []map[string]any{
+		textBlock("Rescue Trail — "+e.TACChannel, "Large", "Bolder", "Attention"),
+		factSet(
+			"Call type", e.CallType,
+			"TAC channel", e.TACChannel,
+			"Dispatched", formatTime(e.DispatchedAt),
+			"Monitoring until", formatTime(e.ExpiresAt),
+		),
+		monospaceBlock(e.Transcription),
+	}
+	var actions []map[string]any
+	if e.ListenURL != "" {
+		actions = append(actions, openURL("Live Audio", e.ListenURL))
+	}
+	return adaptiveCard(body, actions)
+}
+
+func ThreadReplyCard(e *ThreadReply) map[string]any {
+	body := []map[string]any{
+		textBlock(fmt.Sprintf("%s — %s", e.Channel, formatTime(e.At)), "Medium", "Bolder", ""),
+		monospaceBlock(e.Message),
+	}
+	var actions []map[string]any
+	if e.AudioURL != "" {
+		actions = append(actions, openURL("Play Clip", e.AudioURL))
+	}
+	return adaptiveCard(body, actions)
+}
+
+func LiveInterpretationCard(e *LiveInterpretation) map[string]any {
+	body := []map[string]any{
+		textBlock("Live Interpretation — "+e.TACChannel, "Large", "Bolder", ""),
+	}
+	if s := e.Summary; s != nil {
+		if s.Headline != "" {
+			body = append(body, textBlock(s.Headline, "Medium", "Bolder", ""))
+		}
+		if s.SituationSummary != "" {
+			body = append(body, textBlock(s.SituationSummary, "", "", ""))
+		}
+		sar := "No"
+		if s.SARNotified {
+			sar = "Yes"
+		}
+		body = append(body, factSet(
+			"Location", s.Location,
+			"Units", strings.Join(s.UnitsInvolved, ", "),
+			"Patient", s.PatientStatus,
+			"Outcome", s.Outcome,
+			"SAR notified", sar,
+		))
+		for _, ev := range s.KeyEvents {
+			body = append(body, textBlock(fmt.Sprintf("%s — %s", ev.CapturedAt, ev.Description), "Small", "", ""))
+		}
+	}
+	body = append(body, textBlock("Updated "+formatTime(e.UpdatedAt), "Small", "", "Accent"))
+	return adaptiveCard(body, nil)
+}
+
+func ClosedCard(e *Closed) map[string]any {
+	if e.Reason == ClosedCancelled {
+		return adaptiveCard([]map[string]any{
+			textBlock(e.TACChannel+" monitoring cancelled (false alarm)", "Medium", "Bolder", "Warning"),
+			textBlock("Cancelled "+formatTime(e.ClosedAt), "Small", "", ""),
+		}, nil)
+	}
+	return adaptiveCard([]map[string]any{
+		textBlock(e.TACChannel+" monitoring closed", "Medium", "Bolder", "Good"),
+		textBlock("Closed "+formatTime(e.ClosedAt), "Small", "", ""),
+	}, nil)
+}
+
+func adaptiveCard(body, actions []map[string]any) map[string]any {
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if len(actions) > 0 {
+		card["actions"] = actions
+	}
+	return card
+}
+
+func textBlock(text, size, weight, color string) map[string]any {
+	b := map[string]any{"type": "TextBlock", "text": text, "wrap": true}
+	if size != "" {
+		b["size"] = size
+	}
+	if weight != "" {
+		b["weight"] = weight
+	}
+	if color != "" {
+		b["color"] = color
+	}
+	return b
+}
+
+func monospaceBlock(text string) map[string]any {
+	b := textBlock(text, "", "", "")
+	b["fontType"] = "Monospace"
+	return b
+}
+
+// factSet builds a FactSet from title/value pairs, dropping empty values so a sparse summary
+// doesn't render rows of blanks.
+func factSet(pairs ...string) map[string]any {
+	var facts []map[string]any
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			continue
+		}
+		facts = append(facts, map[string]any{"title": pairs[i], "value": pairs[i+1]})
+	}
+	return map[string]any{"type": "FactSet", "facts": facts}
+}
+
+func openURL(title, url string) map[string]any {
+	return map[string]any{"type": "Action.OpenUrl", "title": title, "url": url}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.RFC1123)
+}
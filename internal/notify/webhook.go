@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook POSTs each event as JSON and signs it per the Standard Webhooks spec
+// (standardwebhooks.com), so receivers can verify with any off-the-shelf library:
+//
+//	webhook-id:        unique message id, for receiver-side deduplication
+//	webhook-timestamp: unix seconds
+//	webhook-signature: "v1," + base64(HMAC-SHA256(secret, id + "." + timestamp + "." + body))
+//
+// The secret may be given in the spec's "whsec_<base64>" form or as a raw string. An empty
+// secret sends unsigned requests (useful only against a trusted internal receiver).
+type Webhook struct {
+	name   string
+	url    string
+	secret []byte
+	client *http.Client
+	now    func() time.Time
+}
+
+// WebhookEnvelope is the JSON body of every webhook request.
+type WebhookEnvelope struct {
+	Type      EventType `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data"`
+}
+
+func NewWebhook(name, url, secret string, client *http.Client) (*Webhook, error) {
+	key, err := decodeWebhookSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &Webhook{name: name, url: url, secret: key, client: client, now: time.Now}, nil
+}
+
+func decodeWebhookSecret(secret string) ([]byte, error) {
+	if rest, ok := strings.CutPrefix(secret, "whsec_"); ok {
+		key, err := base64.StdEncoding.DecodeString(rest)
+		if err != nil {
+			return nil, fmt.Errorf("webhook secret: invalid whsec_ base64: %w", err)
+		}
+		return key, nil
+	}
+	return []byte(secret), nil
+}
+
+func (w *Webhook) Name() string { return w.name }
+
+func (w *Webhook) Alert(ctx context.Context, e *Alert) error {
+	return w.send(ctx, EventAlert, e)
+}
+
+func (w *Webhook) ThreadReply(ctx context.Context, e *ThreadReply) error {
+	return w.send(ctx, EventThreadReply, e)
+}
+
+func (w *Webhook) LiveInterpretation(ctx context.Context, e *LiveInterpretation) error {
+	return w.send(ctx, EventLiveInterpretation, e)
+}
+
+func (w *Webhook) Closed(ctx context.Context, e *Closed) error {
+	return w.send(ctx, EventClosed, e)
+}
+
+func (w *Webhook) send(ctx context.Context, t EventType, data any) error {
+	now := w.now()
+	body, err := json.Marshal(WebhookEnvelope{Type: t, Timestamp: now.UTC(), Data: data})
+	if err != nil {
+		return fmt.Errorf("marshal webhook payload: %w", err)
+	}
+
+	headers := map[string]string{}
+	if len(w.secret) > 0 {
+		id, err := newMessageID()
+		if err != nil {
+			return err
+		}
+		ts := strconv.FormatInt(now.Unix(), 10)
+		headers["webhook-id"] = id
+		headers["webhook-timestamp"] = ts
+		headers["webhook-signature"] = "v1," + signWebhook(w.secret, id, ts, body)
+	}
+	return postJSON(ctx, w.client, w.url, body, headers)
+}
+
+// signWebhook returns the base64 HMAC-SHA256 over "<id>.<timestamp>.<body>".
+func signWebhook(secret []byte, id, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id))
+	mac.Write([]byte("."))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newMessageID() (string, error) {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate webhook id: %w", err)
+	}
+	return "msg_" + hex.EncodeToString(b[:]), nil
+}
@@ -3,12 +3,15 @@ package openai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"regexp"
 	"strings"
 
 	openai "github.com/sashabaranov/go-openai"
 	"github.com/sashabaranov/go-openai/jsonschema"
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
 	"github.com/searchandrescuegg/transcribe/internal/ml"
 	"github.com/searchandrescuegg/transcribe/internal/prompts"
 )
@@ -49,6 +52,10 @@ type OpenAIClient struct {
 	// fall through and the schema imposes no enum constraint.
 	allowedCallTypes []string
 
+	// places is rendered into the dispatch, summary and cleanup system prompts as the
+	// place-name reference. Nil uses the bundled gazetteer.
+	places *gazetteer.Gazetteer
+
 	// enableThinking controls whether the chat-template renders the model's chain-of-
 	// thought (Qwen3-family templates). When false (default), every request carries
 	// chat_template_kwargs: {"enable_thinking": false} — the canonical, server-side,
@@ -59,13 +66,15 @@ type OpenAIClient struct {
 
 // NewOpenAIClient constructs the OpenAI ML client. allowedCallTypes is the (already-
 // decrypted) list of canonical call types; pass nil or an empty slice to skip both the
-// prompt injection and the schema-level enum constraint. enableThinking=false (typical)
-// suppresses Qwen3-style chain-of-thought emission server-side for faster responses.
-func NewOpenAIClient(client *openai.Client, model string, allowedCallTypes []string, enableThinking bool) *OpenAIClient {
+// prompt injection and the schema-level enum constraint. places is the place-name gazetteer
+// (nil for the bundled one). enableThinking=false (typical) suppresses Qwen3-style
+// chain-of-thought emission server-side for faster responses.
+func NewOpenAIClient(client *openai.Client, model string, allowedCallTypes []string, places *gazetteer.Gazetteer, enableThinking bool) *OpenAIClient {
 	return &OpenAIClient{
 		client:           client,
 		model:            model,
 		allowedCallTypes: allowedCallTypes,
+		places:           places,
 		enableThinking:   enableThinking,
 	}
 }
@@ -87,7 +96,7 @@ func (oc *OpenAIClient) ParseRelevantInformationFromDispatchMessage(ctx context.
 	req := openai.ChatCompletionRequest{
 		Model: oc.model,
 		Messages: []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: prompts.DispatchSystemPrompt(oc.allowedCallTypes)},
+			{Role: openai.ChatMessageRoleSystem, Content: prompts.DispatchSystemPrompt(oc.allowedCallTypes, oc.places)},
 			{Role: openai.ChatMessageRoleUser, Content: transcription},
 		},
 		ResponseFormat: &openai.ChatCompletionResponseFormat{
@@ -106,7 +115,7 @@ func (oc *OpenAIClient) ParseRelevantInformationFromDispatchMessage(ctx context.
 		req.ChatTemplateKwargs = map[string]any{"enable_thinking": false}
 	}
 
-	resp, err := oc.client.CreateChatCompletion(ctx, req)
+	resp, err := oc.createChatCompletion(ctx, req)
 
 	if err != nil {
 		return nil, fmt.Errorf("chat completion error: %w", err)
@@ -144,17 +153,102 @@ func (oc *OpenAIClient) buildSchema() (*jsonschema.Definition, error) {
 // chat_template_kwargs.enable_thinking flag and post-hoc <think>-stripping are inherited
 // because they apply to the OpenAI client, not per-method.
 //
-// Iterating on the prompt: edit prompts.RescueSummarySystemPrompt and re-run the iteration
+// Iterating on the prompt: edit prompts.rescueSummarySystemPromptBase and re-run the iteration
 // CLI (cmd/test-summary). The structured output schema is generated from the
 // ml.RescueSummary struct, so renaming fields there propagates automatically.
 func (oc *OpenAIClient) SummarizeRescue(ctx context.Context, input ml.RescueSummaryInput) (*ml.RescueSummary, error) {
+	req, err := oc.rescueSummaryRequest(input)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := oc.createChatCompletion(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("rescue summary chat completion: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no response choices returned from OpenAI")
+	}
+	return decodeRescueSummary(resp.Choices[0].Message.Content)
+}
+
+// SummarizeRescueStream is SummarizeRescue over a streaming chat completion, reporting the
+// summary to onPartial as its fields finish. Nothing is reported while the model is inside a
+// <think> block; once it closes, the JSON after it is decoded as it streams.
+func (oc *OpenAIClient) SummarizeRescueStream(ctx context.Context, input ml.RescueSummaryInput, onPartial func(*ml.RescueSummary)) (*ml.RescueSummary, error) {
+	req, err := oc.rescueSummaryRequest(input)
+	if err != nil {
+		return nil, err
+	}
+	req.Stream = true
+	// The usage arrives on a final chunk with no choices; servers that don't support the
+	// option ignore it and simply report none.
+	req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+
+	stream, err := oc.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("rescue summary chat completion stream: %w", err)
+	}
+	defer func() { _ = stream.Close() }()
+
+	partials := ml.NewSummaryStream(onPartial)
+	var content strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("rescue summary chat completion stream: %w", err)
+		}
+		if chunk.Usage != nil {
+			oc.reportUsage(ctx, *chunk.Usage)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		content.WriteString(chunk.Choices[0].Delta.Content)
+		sofar := content.String()
+		if strings.LastIndex(sofar, "<think>") > strings.LastIndex(sofar, "</think>") {
+			continue // still reasoning; any braces so far aren't the answer
+		}
+		partials.Update(stripThinkingPrefix(sofar))
+	}
+	return decodeRescueSummary(content.String())
+}
+
+// createChatCompletion makes a non-streaming request and reports its usage (see
+// ml.ReportUsage).
+func (oc *OpenAIClient) createChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	resp, err := oc.client.CreateChatCompletion(ctx, req)
+	if err == nil {
+		oc.reportUsage(ctx, resp.Usage)
+	}
+	return resp, err
+}
+
+func (oc *OpenAIClient) reportUsage(ctx context.Context, u openai.Usage) {
+	if u.PromptTokens == 0 && u.CompletionTokens == 0 {
+		return // the server didn't report usage
+	}
+	ml.ReportUsage(ctx, ml.Usage{
+		Backend:      "openai",
+		Model:        oc.model,
+		InputTokens:  int64(u.PromptTokens),
+		OutputTokens: int64(u.CompletionTokens),
+	})
+}
+
+// rescueSummaryRequest builds the structured-output request SummarizeRescue and
+// SummarizeRescueStream share.
+func (oc *OpenAIClient) rescueSummaryRequest(input ml.RescueSummaryInput) (openai.ChatCompletionRequest, error) {
 	if input.DispatchTranscription == "" && len(input.TACTranscripts) == 0 {
-		return nil, fmt.Errorf("no transcripts to summarize")
+		return openai.ChatCompletionRequest{}, fmt.Errorf("no transcripts to summarize")
 	}
 
 	schema, err := prompts.RescueSummarySchema()
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate summary schema: %w", err)
+		return openai.ChatCompletionRequest{}, fmt.Errorf("failed to generate summary schema: %w", err)
 	}
 
 	userPrompt := prompts.BuildRescueSummaryUserPrompt(input)
@@ -162,7 +256,7 @@ func (oc *OpenAIClient) SummarizeRescue(ctx context.Context, input ml.RescueSumm
 	req := openai.ChatCompletionRequest{
 		Model: oc.model,
 		Messages: []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: prompts.RescueSummarySystemPrompt},
+			{Role: openai.ChatMessageRoleSystem, Content: prompts.RescueSummarySystemPrompt(oc.places)},
 			{Role: openai.ChatMessageRoleUser, Content: userPrompt},
 		},
 		ResponseFormat: &openai.ChatCompletionResponseFormat{
@@ -177,15 +271,11 @@ func (oc *OpenAIClient) SummarizeRescue(ctx context.Context, input ml.RescueSumm
 	if !oc.enableThinking {
 		req.ChatTemplateKwargs = map[string]any{"enable_thinking": false}
 	}
+	return req, nil
+}
 
-	resp, err := oc.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("rescue summary chat completion: %w", err)
-	}
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response choices returned from OpenAI")
-	}
-	content := resp.Choices[0].Message.Content
+// decodeRescueSummary strips any reasoning prefix from a summary response and decodes it.
+func decodeRescueSummary(content string) (*ml.RescueSummary, error) {
 	if content == "" {
 		return nil, fmt.Errorf("empty response content from OpenAI")
 	}
@@ -216,7 +306,7 @@ func (oc *OpenAIClient) CleanTACTranscript(ctx context.Context, in ml.TACCleanup
 	req := openai.ChatCompletionRequest{
 		Model: oc.model,
 		Messages: []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: prompts.TACCleanupSystemPrompt},
+			{Role: openai.ChatMessageRoleSystem, Content: prompts.TACCleanupSystemPrompt(oc.places)},
 			{Role: openai.ChatMessageRoleUser, Content: prompts.BuildTACCleanupUserPrompt(in)},
 		},
 		ResponseFormat: &openai.ChatCompletionResponseFormat{
@@ -232,7 +322,7 @@ func (oc *OpenAIClient) CleanTACTranscript(ctx context.Context, in ml.TACCleanup
 		req.ChatTemplateKwargs = map[string]any{"enable_thinking": false}
 	}
 
-	resp, err := oc.client.CreateChatCompletion(ctx, req)
+	resp, err := oc.createChatCompletion(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("tac cleanup chat completion: %w", err)
 	}
@@ -254,3 +344,112 @@ func (oc *OpenAIClient) CleanTACTranscript(ctx context.Context, in ml.TACCleanup
 	}
 	return &result, nil
 }
+
+// CompareDispatches asks whether a new dispatch onto an active TAC is the same incident as the
+// one that started the rescue. Same structured-output discipline as the other calls.
+func (oc *OpenAIClient) CompareDispatches(ctx context.Context, in ml.DispatchComparisonInput) (*ml.DispatchComparison, error) {
+	if in.ActiveDispatch == "" || in.NewDispatch == "" {
+		return nil, fmt.Errorf("both dispatch transcriptions are required")
+	}
+
+	schema, err := prompts.DispatchComparisonSchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate comparison schema: %w", err)
+	}
+
+	req := openai.ChatCompletionRequest{
+		Model: oc.model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: prompts.DispatchComparisonSystemPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: prompts.BuildDispatchComparisonUserPrompt(in)},
+		},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   "dispatch_comparison",
+				Schema: schema,
+				Strict: true,
+			},
+		},
+	}
+	if !oc.enableThinking {
+		req.ChatTemplateKwargs = map[string]any{"enable_thinking": false}
+	}
+
+	resp, err := oc.createChatCompletion(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("dispatch comparison chat completion: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no response choices returned from OpenAI")
+	}
+	content := resp.Choices[0].Message.Content
+	if content == "" {
+		return nil, fmt.Errorf("empty response content from OpenAI")
+	}
+	cleaned := stripThinkingPrefix(content)
+	if cleaned == "" {
+		return nil, fmt.Errorf("response content was nothing but reasoning prose: %s", content)
+	}
+
+	var result ml.DispatchComparison
+	if err := json.Unmarshal([]byte(cleaned), &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal comparison result: %w, cleaned content: %s, raw content: %s", err, cleaned, content)
+	}
+	return &result, nil
+}
+
+// GenerateAfterActionReport writes the final report for a closed rescue. Same structured-output
+// discipline as SummarizeRescue; the input is the whole incident, so this is the largest prompt
+// the client sends.
+func (oc *OpenAIClient) GenerateAfterActionReport(ctx context.Context, in ml.AfterActionInput) (*ml.AfterActionReport, error) {
+	if in.DispatchTranscription == "" && len(in.TACTranscripts) == 0 {
+		return nil, fmt.Errorf("no transcripts to report on")
+	}
+
+	schema, err := prompts.AfterActionSchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate after-action schema: %w", err)
+	}
+
+	req := openai.ChatCompletionRequest{
+		Model: oc.model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: prompts.AfterActionSystemPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: prompts.BuildAfterActionUserPrompt(in)},
+		},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   "after_action_report",
+				Schema: schema,
+				Strict: true,
+			},
+		},
+	}
+	if !oc.enableThinking {
+		req.ChatTemplateKwargs = map[string]any{"enable_thinking": false}
+	}
+
+	resp, err := oc.createChatCompletion(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("after-action chat completion: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no response choices returned from OpenAI")
+	}
+	content := resp.Choices[0].Message.Content
+	if content == "" {
+		return nil, fmt.Errorf("empty response content from OpenAI")
+	}
+	cleaned := stripThinkingPrefix(content)
+	if cleaned == "" {
+		return nil, fmt.Errorf("response content was nothing but reasoning prose: %s", content)
+	}
+
+	var report ml.AfterActionReport
+	if err := json.Unmarshal([]byte(cleaned), &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal after-action report: %w, cleaned content: %s, raw content: %s", err, cleaned, content)
+	}
+	return &report, nil
+}
@@ -1,8 +1,15 @@
 package openai
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/searchandrescuegg/transcribe/internal/ml"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -90,3 +97,48 @@ func TestBuildSchema_NoAllowedCallTypes_ReturnsUnconstrainedSchema(t *testing.T)
 		assert.Empty(t, callType.Enum, "no allowed list configured → no enum constraint")
 	}
 }
+
+// SummarizeRescueStream reports completed fields as the stream arrives, says nothing while the
+// model is thinking, and returns the same summary a non-streamed call would.
+func TestSummarizeRescueStream(t *testing.T) {
+	chunks := []string{"<think>{not the answer", "}</think>", `{"headline":"Hiker`, ` down","key_events":[`, `{"captured_at":"13:02","description":"On scene"}]}`}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, c := range chunks {
+			data, _ := json.Marshal(map[string]any{"choices": []map[string]any{{"index": 0, "delta": map[string]string{"content": c}}}})
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+			data, _ := json.Marshal(map[string]any{"choices": []any{}, "usage": map[string]int{"prompt_tokens": 900, "completion_tokens": 40}})
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	cfg := openai.DefaultConfig("test")
+	cfg.BaseURL = srv.URL + "/v1"
+	oc := NewOpenAIClient(openai.NewClientWithConfig(cfg), "m", nil, nil, false)
+
+	var (
+		partials []*ml.RescueSummary
+		usage    []ml.Usage
+	)
+	ctx := ml.WithUsageSink(context.Background(), func(_ context.Context, u ml.Usage) { usage = append(usage, u) })
+	got, err := oc.SummarizeRescueStream(ctx, ml.RescueSummaryInput{DispatchTranscription: "x"}, func(s *ml.RescueSummary) {
+		partials = append(partials, s)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Hiker down", got.Headline)
+	require.Len(t, got.KeyEvents, 1)
+
+	require.Len(t, partials, 2)
+	assert.Equal(t, "Hiker down", partials[0].Headline)
+	assert.Empty(t, partials[0].KeyEvents)
+	assert.Len(t, partials[1].KeyEvents, 1)
+
+	assert.Equal(t, []ml.Usage{{Backend: "openai", Model: "m", InputTokens: 900, OutputTokens: 40}}, usage)
+}
@@ -10,8 +10,10 @@ package prompts
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
 	"github.com/searchandrescuegg/transcribe/internal/ml"
 )
 
@@ -23,9 +25,9 @@ const UnknownCallType = "Unknown"
 // allowedCallTypes is non-empty the prompt is rewritten to reference the canonical list
 // (the list itself is inlined so the model knows the exact spelling and casing it MUST
 // emit); an empty list keeps the in-line example call types for backward compatibility.
-// The King County place-name gazetteer is appended in both cases so the model can correct
-// garbled local locations in the cleaned transcription.
-func DispatchSystemPrompt(allowedCallTypes []string) string {
+// The place-name reference (PlaceReference) is appended in both cases so the model can
+// correct garbled local locations in the cleaned transcription.
+func DispatchSystemPrompt(allowedCallTypes []string, places *gazetteer.Gazetteer) string {
 	var b strings.Builder
 	if len(allowedCallTypes) == 0 {
 		b.WriteString(defaultSystemPrompt)
@@ -44,8 +46,10 @@ func DispatchSystemPrompt(allowedCallTypes []string) string {
 		b.WriteString("\n")
 		b.WriteString(constrainedSystemPromptTail)
 	}
+	b.WriteString("\n")
+	b.WriteString(dispatchLocationInstruction)
 	b.WriteString("\n\n")
-	b.WriteString(kingCountyGazetteer)
+	b.WriteString(PlaceReference(places))
 	return b.String()
 }
 
@@ -67,16 +71,20 @@ The tactical channel (TAC) should be in the format "TAC1", "TAC2", etc. Do not i
 Please clean the transcription to update any misspellings, incorrect locations, and generally ensure that it is clear and concise.
 Do not add any additional information or context that is not present in the transcription.`
 
-// rescueSummarySystemPromptBase is the canonical summarizer instruction; the King County
-// gazetteer is appended to it to form RescueSummarySystemPrompt below.
+// dispatchLocationInstruction asks the dispatch parser for the structured location the
+// gazetteer resolves to a map link. Shared by both dispatch prompt variants.
+const dispatchLocationInstruction = `For each call, also fill in the location fields from what dispatch said: trailhead (a named trailhead or trail), landmark (a named peak, lake, falls or other feature), address (a street address, road and mile marker, or town). Use the corrected place-name spelling. Leave a field empty when dispatch did not give it. Set latitude and longitude (decimal degrees) ONLY if coordinates were read out in the transcription; otherwise set both to 0. Never look up or estimate coordinates for a named place.`
+
+// rescueSummarySystemPromptBase is the canonical summarizer instruction; the place-name
+// reference is appended to it to form RescueSummarySystemPrompt below.
 // Iterate on this string to tune the summary's voice, completeness, and accuracy. Pair
 // edits with runs of cmd/test-summary against fixture inputs to see the effect.
 const rescueSummarySystemPromptBase = `You are an emergency-response analyst summarizing radio chatter from a US fire department's tactical channel during an in-progress rescue.
 
 You will receive:
   - The original dispatch transcription that initiated the rescue (anchors what kind of incident this is).
-  - The dispatched call type (e.g. "Rescue - Trail") and the assigned tactical channel (e.g. "TAC10").
-  - An ordered list of TAC channel transmissions (one per radio key-up), each with a capture timestamp.
+  - The dispatched call type (e.g. "Rescue - Trail") and the assigned tactical channel (e.g. "TAC10"), or several channels (e.g. "TAC3, TAC8") when command and the field teams work separate TACs.
+  - An ordered list of TAC channel transmissions (one per radio key-up), each with a capture timestamp and usually the channel it was heard on, e.g. "(TAC8)". On a multi-channel rescue, use the channel to tell command traffic from field traffic, and name the channel in a KeyEvent when it matters.
   - Optionally, your PREVIOUS summary from the last update (to be extended, not rewritten).
   - Optionally, a list of units currently assigned to the call from CAD (to canonicalize unit callsigns).
 
@@ -86,7 +94,7 @@ Produce a structured summary that lets a human responder catch up at a glance. F
 2. The transcripts come from imperfect speech-to-text. Normalize obvious mistakes: "Italian one seventy one" → "Battalion 171"; "Mabel Valley" → "Maple Valley"; numeric units like "8171" likely mean Battalion 8171 or Engine 8171 — preserve as written if context is ambiguous.
 3. Headline: one short sentence (≤ 80 chars) capturing the situation as it currently stands. Aim for what the on-call would want to know first.
 4. SituationSummary: 1–3 sentences. What's the incident, where, who's responding, what's happening operationally.
-5. Location: name the best-known location from the chatter (trailhead, address, mile marker). Empty string if not stated.
+5. Location: name the best-known location from the chatter (trailhead, address, mile marker). Empty string if not stated. Also split it into LocationDetail: trailhead (named trailhead or trail), landmark (named peak, lake, falls or other feature), address (street address, road and mile marker, or town), each empty if not stated. Set latitude and longitude (decimal degrees) ONLY when coordinates are read out on the radio — convert degrees-minutes-seconds or degrees-decimal-minutes to decimal degrees — and use the most recent ones; otherwise set both to 0. Never look up or estimate coordinates for a named place.
 6. UnitsInvolved: list every responding unit mentioned (e.g. "Engine 171", "Aid 151", "Battalion 171", "Medic 104"). Deduplicate. Use the canonical form, not the spoken form.
 7. PatientStatus: one short phrase about patient condition or transport ("ambulatory; refused transport", "transported to Overlake", "no patient contact"). Empty if unstated.
 8. Outcome: short phrase describing the disposition of the INCIDENT itself, not of individual units. Common values: "Ongoing", "Resolved — patient transported", "Resolved — handled on scene", "Cancelled en route", "False alarm". Carefully distinguish canceled RESOURCES from a canceled INCIDENT:
@@ -100,32 +108,67 @@ Produce a structured summary that lets a human responder catch up at a glance. F
 13. UNIT CANONICALIZATION: When a "Units currently assigned" list is provided, use it as ground truth for the UnitsInvolved field and for interpreting garbled unit callsigns in the transcripts (e.g. a transmission that sounds like "eighty one seventy one" resolves to a listed unit "A8171"). Never add a unit to UnitsInvolved solely because it appears in the assigned list — only include units that the transcripts actually reference; the list is for spelling/disambiguation, not for inventing participation.
 14. CALL TIMER: A spoken time reference like "Time 1:42" (or "time one forty two") is an ELAPSED call timer — time since dispatch — NOT a wall-clock time. Do not convert it to a 24-hour clock or a time of day, and do not use it as a KeyEvents timestamp. KeyEvents timestamps come ONLY from the CapturedAt value attached to each transmission.`
 
-// RescueSummarySystemPrompt is the base summarizer prompt with the King County place-name
-// gazetteer appended, so the model corrects garbled local locations in the Location field
-// and key events.
-var RescueSummarySystemPrompt = rescueSummarySystemPromptBase + "\n\n" + kingCountyGazetteer
-
-// kingCountyGazetteer is a baked-in reference of place names in the King County / I-90
-// (Snoqualmie) corridor that dominate trail-rescue traffic. Speech-to-text reliably garbles
-// these — especially Native American and short names — so the prompt uses the list to snap
-// phonetic near-misses back to canonical spellings. It is framed conservatively (correct only
-// clear matches; never force one) to avoid rewriting locations that were transcribed correctly
-// but aren't listed. This is public geography, so it lives in the prompt rather than the
-// encrypted call-types file. It rarely changes; when you edit it the prompt_hash recorded in
-// the dataset updates automatically, so you can A/B the effect. Appended to both the dispatch
-// and rescue-summary system prompts.
-const kingCountyGazetteer = `=== Local place-name reference (King County & the I-90 / Snoqualmie corridor) ===
-Speech-to-text frequently garbles the proper nouns below, especially Native American and short names (for example "Mount Sai" or "Mount Sigh" should be "Mount Si"; "Squallamie" or "Snowqualmie" should be "Snoqualmie"; "Sammish" should be "Sammamish"; "Kalitan" should be "Kaleetan"; "Melaqua" should be "Melakwa"). When a transcription clearly corresponds to a name below but is phonetically garbled or misspelled, correct it to the canonical form shown. "Mt." and "Mount" are interchangeable and do not need to be normalized — leave whichever form the transcription used — with one exception: Mount Si must always be written "Mount Si", never "Mt. Si" or just "Si".
-
-CRITICAL — location safety rule: Correct a place name to a listed entry ONLY when the transcription is an OBVIOUS PHONETIC NEAR-MATCH to that entry (they must sound clearly alike, as in the "Mount Sai" → "Mount Si" examples above). NEVER replace a place name with a DIFFERENT real place: if the transcribed location is not an obvious near-match to a listed name, keep it EXACTLY as transcribed — even if it resembles no place on this list, and even if some listed place seems "close enough." Do not swap "Norway Hill" for "Rattlesnake Ledge", or any name for a differently-sounding one, just to land on a known location. A wrong location misdirects emergency responders, so when in any doubt, preserve the transcribed name verbatim and do NOT force a match or invent a location.
+// RescueSummarySystemPrompt is the base summarizer prompt with the place-name reference
+// appended, so the model corrects garbled local locations in the Location field and key
+// events.
+func RescueSummarySystemPrompt(places *gazetteer.Gazetteer) string {
+	return rescueSummarySystemPromptBase + "\n\n" + PlaceReference(places)
+}
 
-Cities & towns: Seattle, Bellevue, Renton, Kent, Auburn, Federal Way, Kirkland, Redmond, Sammamish, Issaquah, Snoqualmie, North Bend, Enumclaw, Maple Valley, Covington, Black Diamond, Duvall, Carnation, Fall City, Preston, Hobart, Ravensdale, Newcastle, Woodinville, Bothell, Kenmore, Shoreline, Burien, SeaTac, Tukwila, Mercer Island, Vashon, Skykomish, Baring, Kanaskat, Palmer.
+// PlaceReference renders the gazetteer (nil means the bundled one) as the place-name
+// reference appended to the dispatch, summary and cleanup system prompts: the correction
+// instructions, then the places grouped by category in file order. Speech-to-text reliably
+// garbles local names — especially Native American and short ones — so the list lets the
+// model snap phonetic near-misses back to canonical spellings. The framing is conservative
+// (correct only clear matches; never force one) to avoid rewriting locations that were
+// transcribed correctly but aren't listed. Editing the gazetteer file changes the prompt, so
+// the prompt_hash recorded in the dataset updates with it and the effect can be A/B'd.
+func PlaceReference(places *gazetteer.Gazetteer) string {
+	if places == nil {
+		places = gazetteer.Bundled()
+	}
+	var b strings.Builder
+	b.WriteString(placeNameInstruction)
+	for _, group := range placeGroups {
+		var entries []string
+		for _, p := range places.Places() {
+			if !slices.Contains(group.categories, p.Category) {
+				continue
+			}
+			entry := p.Name
+			if len(p.Aliases) > 0 {
+				entry += " (" + strings.Join(p.Aliases, ", ") + ")"
+			}
+			if p.Note != "" {
+				entry += " [" + p.Note + "]"
+			}
+			entries = append(entries, entry)
+		}
+		if len(entries) > 0 {
+			fmt.Fprintf(&b, "\n\n%s: %s.", group.heading, strings.Join(entries, ", "))
+		}
+	}
+	return b.String()
+}
 
-Peaks, trails & trailheads: Mount Si, Little Si, Mount Teneriffe, Teneriffe Falls, Mailbox Peak, Rattlesnake Ledge, Rattlesnake Mountain, West Tiger Mountain, East Tiger Mountain, Poo Poo Point, Squak Mountain, Cougar Mountain, Cedar Butte, Grand Ridge, Taylor Mountain, Granite Mountain, Mount Washington, McClellan Butte, Bandera Mountain, Mount Defiance, Ira Spring Trail, Mason Lake, Snow Lake, Source Lake, Kendall Katwalk, Snoqualmie Mountain, Guye Peak, Chair Peak, Denny Mountain, Alpental, The Tooth, Kaleetan Peak, Chikamin Peak, Silver Peak, Humpback Mountain, Tinkham Peak, Bessemer Mountain, Dirty Harry's Peak, Dirty Harry's Balcony, Annette Lake, Talapus Lake, Olallie Lake, Pratt Lake, Melakwa Lake.
+// placeGroups are the prompt's headings, in order, and the gazetteer categories under each.
+// Every gazetteer.Categories value must appear in exactly one group.
+var placeGroups = []struct {
+	heading    string
+	categories []string
+}{
+	{"Cities & towns", []string{"city"}},
+	{"Peaks, trails & trailheads", []string{"peak", "trail", "trailhead", "landmark"}},
+	{"Rivers, lakes & falls", []string{"river", "lake", "falls"}},
+	{"Roads & highways", []string{"road", "pass"}},
+}
 
-Rivers, lakes & falls: Snoqualmie River (North Fork, Middle Fork, South Fork), Cedar River, Green River, White River, Raging River, Tolt River, Lake Washington, Lake Sammamish, Rattlesnake Lake, Keechelus Lake, Kachess Lake, Snoqualmie Falls, Twin Falls, Franklin Falls, Weeks Falls, Denny Creek.
+// placeNameInstruction heads the place-name reference. It is region-neutral; anything
+// specific to a place (known garbles, a required spelling) lives in that entry's note.
+const placeNameInstruction = `=== Local place-name reference ===
+Speech-to-text frequently garbles the proper nouns below, especially Native American and short names. When a transcription clearly corresponds to a name below but is phonetically garbled or misspelled, correct it to the canonical form shown. Names in parentheses are accepted alternative forms of the entry. Notes in [brackets] give known garbles or spelling rules for that entry; follow them. "Mt." and "Mount" are interchangeable and do not need to be normalized — leave whichever form the transcription used — unless a note says otherwise.
 
-Roads & highways: Interstate 90 (I-90), Interstate 5 (I-5), Interstate 405 (I-405), SR 18, SR 202, SR 203, SR 169 (Maple Valley Highway), SR 410, US 2 (Stevens Pass), SR 900, Snoqualmie Pass, Middle Fork Road, Mount Si Road, North Bend Way, Cedar Falls Road, Issaquah-Hobart Road, Preston-Fall City Road.`
+CRITICAL — location safety rule: Correct a place name to a listed entry ONLY when the transcription is an OBVIOUS PHONETIC NEAR-MATCH to that entry (they must sound clearly alike, like the garbles given in the notes). NEVER replace a place name with a DIFFERENT real place: if the transcribed location is not an obvious near-match to a listed name, keep it EXACTLY as transcribed — even if it resembles no place on this list, and even if some listed place seems "close enough." Do not swap an unlisted name such as "Norway Hill" for a differently-sounding listed one just to land on a known location. A wrong location misdirects emergency responders, so when in any doubt, preserve the transcribed name verbatim and do NOT force a match or invent a location.`
 
 // BuildRescueSummaryUserPrompt formats the input as a clearly-delimited block. The model
 // performs better when the dispatch and TAC sections are explicitly labeled.
@@ -151,11 +194,23 @@ func BuildRescueSummaryUserPrompt(input ml.RescueSummaryInput) string {
 	}
 
 	b.WriteString("\n\n=== TAC TRANSMISSIONS (chronological) ===\n")
+	// After compaction the earliest transmissions live only in the previous summary. Say so, and
+	// keep numbering from where they left off, so the model doesn't read the tail as the whole
+	// rescue and drop what it established earlier.
+	if input.CompactedTransmissions > 0 {
+		fmt.Fprintf(&b, "(Transmissions 1–%d are already reflected in the previous summary and are not repeated. Only the transmissions since are listed.)\n",
+			input.CompactedTransmissions)
+	}
 	if len(input.TACTranscripts) == 0 {
 		b.WriteString("(none yet)\n")
 	}
 	for i, t := range input.TACTranscripts {
-		fmt.Fprintf(&b, "[%d] %s — %s\n", i+1, emptyAsDash(t.CapturedAt), t.Text)
+		n := input.CompactedTransmissions + i + 1
+		if t.Channel != "" {
+			fmt.Fprintf(&b, "[%d] %s (%s) — %s\n", n, emptyAsDash(t.CapturedAt), t.Channel, t.Text)
+			continue
+		}
+		fmt.Fprintf(&b, "[%d] %s — %s\n", n, emptyAsDash(t.CapturedAt), t.Text)
 	}
 	return b.String()
 }
@@ -169,6 +224,9 @@ func renderPreviousSummary(s *ml.RescueSummary) string {
 	fmt.Fprintf(&b, "Headline: %s\n", emptyAsDash(s.Headline))
 	fmt.Fprintf(&b, "SituationSummary: %s\n", emptyAsDash(s.SituationSummary))
 	fmt.Fprintf(&b, "Location: %s\n", emptyAsDash(s.Location))
+	if d := s.LocationDetail; d.Latitude != 0 || d.Longitude != 0 {
+		fmt.Fprintf(&b, "Reported coordinates: %.5f, %.5f\n", d.Latitude, d.Longitude)
+	}
 	fmt.Fprintf(&b, "UnitsInvolved: %s\n", emptyAsDash(strings.Join(s.UnitsInvolved, ", ")))
 	fmt.Fprintf(&b, "PatientStatus: %s\n", emptyAsDash(s.PatientStatus))
 	fmt.Fprintf(&b, "Outcome: %s\n", emptyAsDash(s.Outcome))
@@ -184,10 +242,12 @@ func renderPreviousSummary(s *ml.RescueSummary) string {
 }
 
 // TACCleanupSystemPrompt instructs the model to clean up a single raw TAC transmission. Like the
-// dispatch parser it corrects ASR errors and place names (via the shared gazetteer) but is
-// strictly faithful — it never adds facts. It also uses the (optional) assigned-unit roster to
-// pin garbled unit callsigns.
-var TACCleanupSystemPrompt = tacCleanupSystemPromptBase + "\n\n" + kingCountyGazetteer
+// dispatch parser it corrects ASR errors and place names (via the shared place-name reference)
+// but is strictly faithful — it never adds facts. It also uses the (optional) assigned-unit
+// roster to pin garbled unit callsigns.
+func TACCleanupSystemPrompt(places *gazetteer.Gazetteer) string {
+	return tacCleanupSystemPromptBase + "\n\n" + PlaceReference(places)
+}
 
 const tacCleanupSystemPromptBase = `You are a transcription editor for a US fire department's tactical (TAC) radio channel during an in-progress rescue. You are given one raw speech-to-text transcription of a single radio transmission, plus context about the incident. Return a cleaned version of that ONE transmission.
 
@@ -219,6 +279,79 @@ func BuildTACCleanupUserPrompt(input ml.TACCleanupInput) string {
 	return b.String()
 }
 
+// DispatchComparisonSystemPrompt instructs the model to decide whether a new dispatch onto an
+// already-active TAC is a re-page of the same rescue or a different incident. The cost of a
+// wrong "different" is one needless prompt to the operator; the cost of a wrong "same" is two
+// rescues silently sharing a thread, so the prompt leans on concrete location evidence.
+const DispatchComparisonSystemPrompt = `You compare two fire-dispatch radio transcriptions for a US fire department. The first started a rescue that is still active; the second was dispatched later onto the same tactical (TAC) channel. Decide whether the second dispatch is the SAME incident (an additional unit paged, an upgrade, or a repeat of the original) or a DIFFERENT incident that happened to be assigned the same channel.
+
+Rules:
+1. Judge mainly by location: trail names, trailheads, roads, addresses, landmarks, mile markers and cross streets. The same place described differently (a trailhead vs. the trail it serves, an address vs. the park it is in) is the SAME incident.
+2. Clearly different places with no plausible connection mean a DIFFERENT incident, even when the call type matches.
+3. Additional units, a changed call type, or a patient update at the same place are the SAME incident.
+4. When the transcriptions are too garbled or too vague to tell, answer same_incident=true.
+5. Give the reason in one short sentence naming the locations you compared.`
+
+// BuildDispatchComparisonUserPrompt formats the two dispatches into clearly-delimited blocks.
+func BuildDispatchComparisonUserPrompt(input ml.DispatchComparisonInput) string {
+	var b strings.Builder
+	b.WriteString("=== ACTIVE RESCUE DISPATCH ===\n")
+	b.WriteString(emptyAsDash(input.ActiveDispatch))
+	b.WriteString("\n\n=== NEW DISPATCH")
+	if input.NewCallType != "" {
+		fmt.Fprintf(&b, " (%s)", input.NewCallType)
+	}
+	b.WriteString(" ===\n")
+	b.WriteString(emptyAsDash(input.NewDispatch))
+	return b.String()
+}
+
+// AfterActionSystemPrompt instructs the model to write the final report for a closed rescue.
+// Unlike the live summary it sees the whole incident at once, so it is asked for the complete
+// timeline and for a review of the radio traffic itself.
+const AfterActionSystemPrompt = `You write after-action reports for a US fire department's trail rescues. You are given the dispatch that started the rescue, every tactical (TAC) radio transmission in order with its capture time, and the last live summary written while the rescue was in progress. The rescue is now closed. Produce a structured report.
+
+Rules:
+1. Headline: one sentence saying what the incident was and how it ended.
+2. Timeline: every notable moment in order, each with the CapturedAt time of the transmission it comes from. Include dispatch, arrival, patient contact, resource requests and cancellations, SAR notification, transport or release, and units clearing. Keep events from the live summary unless a later transmission contradicts them. Timeline times come ONLY from CapturedAt values; a spoken "Time 1:42" is an elapsed call timer, not a time of day.
+3. Units: every unit that the transmissions or dispatch show taking part, in canonical form ("Engine 171", "Medic 104"), deduplicated.
+4. Duration: copy the given dispatch-to-close duration exactly. Only if none is given, estimate it from the first and last transmission times and say "about".
+5. PatientOutcome: the patient's final condition and disposition. Empty if there was no patient contact.
+6. SARInvolvement: "Not involved" unless the traffic explicitly shows Search and Rescue being notified, requested or responding; then say who, when and what they did.
+7. RadioIssues: communication problems worth reviewing — unreadable or garbled transmissions, stepped-on traffic, repeated requests for the same information, reported dead spots, traffic on the wrong channel. One short item each, with the time. Return an empty list when there were none; do not invent problems.
+8. Be strictly faithful to the transmissions. Do not add units, events, locations or outcomes that are not in the input.`
+
+// BuildAfterActionUserPrompt formats the closed rescue into clearly-delimited blocks, reusing
+// the live-summary layout for the dispatch and transmissions.
+func BuildAfterActionUserPrompt(input ml.AfterActionInput) string {
+	var b strings.Builder
+	b.WriteString("=== INCIDENT ===\n")
+	fmt.Fprintf(&b, "TAC channel: %s\n", emptyAsDash(input.TACChannel))
+	fmt.Fprintf(&b, "Dispatched: %s\n", emptyAsDash(input.DispatchedAt))
+	fmt.Fprintf(&b, "Closed: %s\n", emptyAsDash(input.ClosedAt))
+	fmt.Fprintf(&b, "Duration: %s\n", emptyAsDash(input.Duration))
+	b.WriteString("\n=== DISPATCH ===\n")
+	b.WriteString(emptyAsDash(input.DispatchTranscription))
+
+	if input.LatestSummary != nil {
+		b.WriteString("\n\n=== LAST LIVE SUMMARY ===\n")
+		b.WriteString(renderPreviousSummary(input.LatestSummary))
+	}
+
+	b.WriteString("\n\n=== TAC TRANSMISSIONS (chronological) ===\n")
+	if len(input.TACTranscripts) == 0 {
+		b.WriteString("(none)\n")
+	}
+	for i, t := range input.TACTranscripts {
+		if t.Channel != "" {
+			fmt.Fprintf(&b, "[%d] %s (%s) — %s\n", i+1, emptyAsDash(t.CapturedAt), t.Channel, t.Text)
+			continue
+		}
+		fmt.Fprintf(&b, "[%d] %s — %s\n", i+1, emptyAsDash(t.CapturedAt), t.Text)
+	}
+	return b.String()
+}
+
 func emptyAsDash(s string) string {
 	if s == "" {
 		return "—"
@@ -4,12 +4,14 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
 	"github.com/searchandrescuegg/transcribe/internal/ml"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-// The King County gazetteer must be concatenated into both system prompts (in every
-// dispatch branch and the summarizer), or local-location correction silently stops working.
+// The place-name reference must be concatenated into every system prompt (in every
+// dispatch branch, the summarizer and the cleanup), or local-location correction silently stops working.
 func TestGazetteerAppendedToBothPrompts(t *testing.T) {
 	// A canonical name and the delimiter header both distinguish the gazetteer from the
 	// base prompt text.
@@ -17,30 +19,30 @@ func TestGazetteerAppendedToBothPrompts(t *testing.T) {
 	const sampleName = "Mount Si"
 
 	t.Run("dispatch with call-types", func(t *testing.T) {
-		p := DispatchSystemPrompt([]string{"Rescue - Trail"})
+		p := DispatchSystemPrompt([]string{"Rescue - Trail"}, nil)
 		assert.Contains(t, p, marker)
 		assert.Contains(t, p, sampleName)
 		assert.Contains(t, p, "Snoqualmie")
 	})
 
 	t.Run("dispatch without call-types", func(t *testing.T) {
-		p := DispatchSystemPrompt(nil)
+		p := DispatchSystemPrompt(nil, nil)
 		assert.Contains(t, p, marker)
 		assert.Contains(t, p, sampleName)
 	})
 
 	t.Run("rescue summary", func(t *testing.T) {
-		assert.Contains(t, RescueSummarySystemPrompt, marker)
-		assert.Contains(t, RescueSummarySystemPrompt, sampleName)
+		assert.Contains(t, RescueSummarySystemPrompt(nil), marker)
+		assert.Contains(t, RescueSummarySystemPrompt(nil), sampleName)
 		// Base instruction must still be present (gazetteer is appended, not replacing).
-		assert.Contains(t, RescueSummarySystemPrompt, "emergency-response analyst")
+		assert.Contains(t, RescueSummarySystemPrompt(nil), "emergency-response analyst")
 	})
 }
 
 // The gazetteer instruction must keep its conservative "don't force a match" guardrail,
 // which is what prevents the model from rewriting correctly-heard but unlisted locations.
 func TestGazetteerHasConservativeFraming(t *testing.T) {
-	assert.True(t, strings.Contains(kingCountyGazetteer, "do NOT force a match"),
+	assert.True(t, strings.Contains(placeNameInstruction, "do NOT force a match"),
 		"gazetteer must retain the anti-over-correction instruction")
 }
 
@@ -50,16 +52,16 @@ func TestGazetteerHasConservativeFraming(t *testing.T) {
 // it. This is a prompt-content guard (behavior can't be asserted deterministically without a live
 // model), but it stops the guardrail from being silently dropped in an edit.
 func TestGazetteerHasLocationSafetyGuardrail(t *testing.T) {
-	assert.Contains(t, kingCountyGazetteer, "OBVIOUS PHONETIC NEAR-MATCH",
+	assert.Contains(t, placeNameInstruction, "OBVIOUS PHONETIC NEAR-MATCH",
 		"gazetteer must require an obvious phonetic near-match before correcting a location")
-	assert.Contains(t, kingCountyGazetteer, "NEVER replace a place name with a DIFFERENT real place",
+	assert.Contains(t, placeNameInstruction, "NEVER replace a place name with a DIFFERENT real place",
 		"gazetteer must forbid substituting one real place for another")
-	assert.Contains(t, kingCountyGazetteer, "misdirects emergency responders",
+	assert.Contains(t, placeNameInstruction, "misdirects emergency responders",
 		"gazetteer must state the safety stakes so the model weights faithfulness over helpfulness")
 
 	// The cleanup prompt must point the model at that safety rule.
-	assert.Contains(t, TACCleanupSystemPrompt, "location safety rule")
-	assert.Contains(t, TACCleanupSystemPrompt, "faithful garble is better than a confident fabrication")
+	assert.Contains(t, TACCleanupSystemPrompt(nil), "location safety rule")
+	assert.Contains(t, TACCleanupSystemPrompt(nil), "faithful garble is better than a confident fabrication")
 }
 
 // Regression: the model reinterpreted "Cadres provided" as "Grid reference provided" and
@@ -67,33 +69,33 @@ func TestGazetteerHasLocationSafetyGuardrail(t *testing.T) {
 // cleanup prompt must require corrections to be phonetic near-matches, not domain-plausible
 // substitutions.
 func TestTACCleanupPromptForbidsSemanticSubstitution(t *testing.T) {
-	assert.Contains(t, TACCleanupSystemPrompt, "obvious PHONETIC or spelling fix",
+	assert.Contains(t, TACCleanupSystemPrompt(nil), "obvious PHONETIC or spelling fix",
 		"cleanup must restrict corrections to phonetic/spelling fixes")
-	assert.Contains(t, TACCleanupSystemPrompt, "domain-plausible word from context is a fabrication")
+	assert.Contains(t, TACCleanupSystemPrompt(nil), "domain-plausible word from context is a fabrication")
 }
 
 // A trailing "Time X" is an elapsed call timer, not time-of-day; both the cleanup and summary
 // prompts must say so, or the model renders it as a 24h clock (e.g. "1342") or slots it into the
 // timeline as an event timestamp.
 func TestPromptsTreatTrailingTimeAsElapsedCallTimer(t *testing.T) {
-	assert.Contains(t, TACCleanupSystemPrompt, "ELAPSED call timer")
-	assert.Contains(t, RescueSummarySystemPrompt, "ELAPSED call timer")
-	assert.Contains(t, RescueSummarySystemPrompt, "KeyEvents timestamp",
+	assert.Contains(t, TACCleanupSystemPrompt(nil), "ELAPSED call timer")
+	assert.Contains(t, RescueSummarySystemPrompt(nil), "ELAPSED call timer")
+	assert.Contains(t, RescueSummarySystemPrompt(nil), "KeyEvents timestamp",
 		"summary must keep the call timer out of the KeyEvents timeline")
 }
 
 // The summarizer prompt must instruct the model on the SARNotified field, including the
 // requirement that SAR be explicitly referenced (not inferred from a generic rescue).
 func TestSummaryPromptCoversSARNotification(t *testing.T) {
-	assert.Contains(t, RescueSummarySystemPrompt, "SARNotified")
-	assert.Contains(t, RescueSummarySystemPrompt, "Search and Rescue")
+	assert.Contains(t, RescueSummarySystemPrompt(nil), "SARNotified")
+	assert.Contains(t, RescueSummarySystemPrompt(nil), "Search and Rescue")
 }
 
 // The additive behavior hinges on the prompt telling the model to preserve prior key events and
 // extend rather than rewrite. If this instruction is dropped, the summary reverts to churning.
 func TestSummaryPromptIsAdditive(t *testing.T) {
-	assert.Contains(t, RescueSummarySystemPrompt, "PREVIOUS SUMMARY")
-	assert.Contains(t, RescueSummarySystemPrompt, "preserve every prior KeyEvent")
+	assert.Contains(t, RescueSummarySystemPrompt(nil), "PREVIOUS SUMMARY")
+	assert.Contains(t, RescueSummarySystemPrompt(nil), "preserve every prior KeyEvent")
 }
 
 // The previous-summary block is rendered only when a prior summary is supplied — a first pass
@@ -131,9 +133,9 @@ func TestBuildRescueSummaryUserPrompt_UnitContext(t *testing.T) {
 // "do not invent" guardrail (so cleanup stays faithful), and the user builder must include the
 // raw transmission plus any provided context.
 func TestTACCleanupPrompt(t *testing.T) {
-	assert.Contains(t, TACCleanupSystemPrompt, "Local place-name reference", "gazetteer appended")
-	assert.Contains(t, TACCleanupSystemPrompt, "KCSO")
-	assert.Contains(t, TACCleanupSystemPrompt, "Do NOT add information", "faithfulness guardrail")
+	assert.Contains(t, TACCleanupSystemPrompt(nil), "Local place-name reference", "gazetteer appended")
+	assert.Contains(t, TACCleanupSystemPrompt(nil), "KCSO")
+	assert.Contains(t, TACCleanupSystemPrompt(nil), "Do NOT add information", "faithfulness guardrail")
 
 	user := BuildTACCleanupUserPrompt(ml.TACCleanupInput{
 		Text:            "tac two norwell hill trail",
@@ -144,3 +146,94 @@ func TestTACCleanupPrompt(t *testing.T) {
 	assert.Contains(t, user, "Rescue Trail TAC2", "incident context included")
 	assert.Contains(t, user, "A181", "unit context included")
 }
+
+// Multi-TAC rescues tag each transmission with its channel; untagged (legacy) entries keep the
+// original line shape.
+func TestBuildRescueSummaryUserPrompt_ChannelAttribution(t *testing.T) {
+	in := ml.RescueSummaryInput{
+		DispatchTranscription: "Rescue Trail TAC3 Mount Si",
+		TACChannel:            "TAC3, TAC8",
+		TACTranscripts: []ml.TACTranscript{
+			{CapturedAt: "14:02:00", Text: "command established", Channel: "TAC3"},
+			{CapturedAt: "14:05:10", Text: "team one at the trailhead", Channel: "TAC8"},
+			{CapturedAt: "14:06:00", Text: "copy"},
+		},
+	}
+	out := BuildRescueSummaryUserPrompt(in)
+	assert.Contains(t, out, "TAC channel: TAC3, TAC8\n")
+	assert.Contains(t, out, "[1] 14:02:00 (TAC3) — command established\n")
+	assert.Contains(t, out, "[2] 14:05:10 (TAC8) — team one at the trailhead\n")
+	assert.Contains(t, out, "[3] 14:06:00 — copy\n")
+}
+
+// A compacted prompt says the earlier transmissions are in the previous summary and numbers the
+// tail from where they left off; an uncompacted one carries no note.
+func TestBuildRescueSummaryUserPrompt_Compacted(t *testing.T) {
+	in := ml.RescueSummaryInput{
+		DispatchTranscription:  "Rescue Trail TAC8 Mount Si",
+		PreviousSummary:        &ml.RescueSummary{Headline: "Hiker with leg injury on Mount Si"},
+		CompactedTransmissions: 40,
+		TACTranscripts:         []ml.TACTranscript{{CapturedAt: "15:40:00", Text: "patient at the trailhead"}},
+	}
+	out := BuildRescueSummaryUserPrompt(in)
+	assert.Contains(t, out, "Transmissions 1–40 are already reflected in the previous summary")
+	assert.Contains(t, out, "[41] 15:40:00 — patient at the trailhead\n")
+
+	in.CompactedTransmissions = 0
+	assert.NotContains(t, BuildRescueSummaryUserPrompt(in), "already reflected")
+}
+
+// Both dispatch variants must ask for the structured location, including the guard against
+// the model inventing coordinates for a named place.
+func TestDispatchPromptAsksForStructuredLocation(t *testing.T) {
+	for _, p := range []string{DispatchSystemPrompt(nil, nil), DispatchSystemPrompt([]string{"Rescue - Trail"}, nil)} {
+		assert.Contains(t, p, "trailhead")
+		assert.Contains(t, p, "Never look up or estimate coordinates")
+	}
+	assert.Contains(t, RescueSummarySystemPrompt(nil), "LocationDetail")
+}
+
+// The bundled gazetteer renders into the same groups the hand-written list used, with aliases
+// in parentheses and per-place notes (known garbles) in brackets.
+func TestPlaceReference_Bundled(t *testing.T) {
+	ref := PlaceReference(nil)
+	assert.Contains(t, ref, "\n\nCities & towns: Seattle, Bellevue,")
+	assert.Contains(t, ref, "\n\nPeaks, trails & trailheads: Mount Si [often misheard as \"Mount Sai\"")
+	assert.Contains(t, ref, "Ira Spring Trail (Ira Spring Trailhead)")
+	assert.Contains(t, ref, "\n\nRivers, lakes & falls: ")
+	assert.Contains(t, ref, "Interstate 90 (I-90)")
+	assert.True(t, strings.HasSuffix(ref, "."))
+}
+
+// A deployment's own file replaces the bundled list entirely; empty groups are omitted.
+func TestPlaceReference_CustomGazetteer(t *testing.T) {
+	places, err := gazetteer.Parse([]byte(`[
+		{"name": "Ellensburg", "category": "city"},
+		{"name": "Manastash Ridge", "category": "peak", "note": "often misheard as \"Mana Stash\""}
+	]`))
+	require.NoError(t, err)
+
+	p := DispatchSystemPrompt(nil, places)
+	assert.Contains(t, p, "Cities & towns: Ellensburg.")
+	assert.Contains(t, p, "Peaks, trails & trailheads: Manastash Ridge [often misheard as \"Mana Stash\"].")
+	assert.NotContains(t, p, "Mount Si")
+	assert.NotContains(t, p, "Roads & highways")
+	assert.Contains(t, RescueSummarySystemPrompt(places), "Ellensburg")
+	assert.Contains(t, TACCleanupSystemPrompt(places), "Ellensburg")
+}
+
+// Every gazetteer category must land under exactly one heading, or its places silently drop
+// out of the prompt.
+func TestPlaceGroupsCoverEveryCategory(t *testing.T) {
+	for _, c := range gazetteer.Categories {
+		n := 0
+		for _, g := range placeGroups {
+			for _, gc := range g.categories {
+				if gc == c {
+					n++
+				}
+			}
+		}
+		assert.Equal(t, 1, n, c)
+	}
+}
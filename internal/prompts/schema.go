@@ -81,3 +81,15 @@ func RescueSummarySchema() (*jsonschema.Definition, error) {
 func TACCleanupSchema() (*jsonschema.Definition, error) {
 	return jsonschema.GenerateSchemaForType(&ml.TACCleanupResult{})
 }
+
+// DispatchComparisonSchema generates the response schema for the same-incident check from the
+// ml.DispatchComparison struct ({same_incident, reason}).
+func DispatchComparisonSchema() (*jsonschema.Definition, error) {
+	return jsonschema.GenerateSchemaForType(&ml.DispatchComparison{})
+}
+
+// AfterActionSchema generates the response schema for the after-action report from the
+// ml.AfterActionReport struct.
+func AfterActionSchema() (*jsonschema.Definition, error) {
+	return jsonschema.GenerateSchemaForType(&ml.AfterActionReport{})
+}
@@ -0,0 +1,174 @@
+// Package pulsepoint adapts the PulsePoint CAD feed (via the pulpo client) to the provider-neutral
+// CAD model in package cad: the agency's active incidents, with unit dispatch statuses decoded
+// through the agency's unit legend. Scoring, prompt rendering, and rescue listing all live in
+// cad.Resolver, so this package is only the PulsePoint-specific half.
+package pulsepoint
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/michaelpeterswa/pulpo"
+	"github.com/searchandrescuegg/transcribe/internal/cad"
+)
+
+// Options configures a Provider.
+type Options struct {
+	BaseURL  string
+	APIKey   string
+	AgencyID string
+	// Username / Password are the HTTP Basic auth credentials the PulsePoint API requires
+	// alongside the apikey. When both are non-empty the client sends them; leave empty to skip.
+	Username string
+	Password string
+	// Timeout bounds each HTTP round-trip (incident list and legend). Zero uses a 5s default.
+	Timeout time.Duration
+}
+
+// Provider lists PulsePoint incidents as cad.Incidents. Safe for concurrent use.
+type Provider struct {
+	client   *pulpo.Client
+	agencyID string
+
+	// The dispatch-status legend rarely changes, so it's cached process-wide behind a mutex.
+	legendMu      sync.Mutex
+	legend        map[string]string
+	legendExpires time.Time
+}
+
+const (
+	defaultTimeout = 5 * time.Second
+	legendCacheTTL = 1 * time.Hour
+)
+
+// NewProvider constructs a Provider. main.go validates the base URL / API key before calling.
+func NewProvider(opts Options) *Provider {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	clientOpts := []pulpo.Option{
+		pulpo.WithBaseURL(opts.BaseURL),
+		pulpo.WithAPIKey(opts.APIKey),
+		pulpo.WithHTTPClient(&http.Client{Timeout: timeout}),
+	}
+	// PulsePoint gates the API behind Basic auth in addition to the apikey; send it when both
+	// credentials are present.
+	if opts.Username != "" && opts.Password != "" {
+		clientOpts = append(clientOpts, pulpo.WithBasicAuth(opts.Username, opts.Password))
+	}
+
+	client, err := pulpo.NewClient(clientOpts...)
+	if err != nil {
+		// Construction only fails on empty base URL / API key, both validated upstream. Fall back
+		// to a nil client; ListActive guards on it and returns no incidents.
+		return &Provider{agencyID: opts.AgencyID}
+	}
+
+	return &Provider{
+		client:   client,
+		agencyID: opts.AgencyID,
+	}
+}
+
+// ListActive fetches the agency's active incidents and the status legend. Returns an error only
+// when the incident fetch itself fails; a legend failure leaves raw status codes. Satisfies
+// cad.Provider.
+func (p *Provider) ListActive(ctx context.Context) ([]cad.Incident, error) {
+	if p.client == nil {
+		return nil, nil
+	}
+
+	// Both:true (the `both=1` query param) is REQUIRED. Without it the PulsePoint feed returns a
+	// flat `{"incidents":[]}` array that does NOT unmarshal into the bucketed {alerts,active,recent}
+	// shape pulpo expects, so List silently yields nothing. With it we get the buckets and read
+	// Active (in-progress incidents); Recent holds closed calls we don't need.
+	resp, err := p.client.Incidents.List(ctx, p.agencyID, &pulpo.IncidentListOptions{Both: true})
+	if err != nil {
+		return nil, err
+	}
+	return toIncidents(resp.Incidents.Active, p.legendMap(ctx)), nil
+}
+
+// legendMap returns the (cached) dispatch-status legend as UnitKey→Description. Best-effort: a
+// fetch failure returns an empty map and callers render raw status codes.
+func (p *Provider) legendMap(ctx context.Context) map[string]string {
+	p.legendMu.Lock()
+	if p.legend != nil && time.Now().Before(p.legendExpires) {
+		cached := p.legend
+		p.legendMu.Unlock()
+		return cached
+	}
+	p.legendMu.Unlock()
+
+	resp, err := p.client.Units.Legend(ctx, p.agencyID)
+	if err != nil {
+		return map[string]string{}
+	}
+	m := make(map[string]string, len(resp.UnitLegend))
+	for _, e := range resp.UnitLegend {
+		m[strings.ToUpper(e.UnitKey)] = e.Description
+	}
+
+	p.legendMu.Lock()
+	p.legend = m
+	p.legendExpires = time.Now().Add(legendCacheTTL)
+	p.legendMu.Unlock()
+	return m
+}
+
+// toIncidents is the pure half of ListActive: map each PulsePoint incident onto the neutral
+// model. PulsePoint splits the location across a display address, a medical-emergency address,
+// and a public place name; the first non-empty is the Address and a distinct public name is the
+// Place, so both still count when scoring.
+func toIncidents(active []pulpo.Incident, legend map[string]string) []cad.Incident {
+	out := make([]cad.Incident, 0, len(active))
+	for _, inc := range active {
+		ci := cad.Incident{
+			ID:       inc.ID,
+			CallType: inc.CallType,
+			Address:  firstNonEmpty(inc.FullDisplayAddress, inc.MedicalEmergencyDisplayAddress, inc.PublicLocation),
+			Units:    units(inc.Unit, legend),
+		}
+		if inc.PublicLocation != ci.Address {
+			ci.Place = inc.PublicLocation
+		}
+		if t, ok := cad.ParseTime(inc.CallReceivedDateTime); ok {
+			ci.ReceivedAt = t
+		}
+		if lat, lon, err := inc.LatLng(); err == nil && (lat != 0 || lon != 0) {
+			ci.Lat, ci.Lon, ci.Located = lat, lon, true
+		}
+		out = append(out, ci)
+	}
+	return out
+}
+
+// units decodes each unit's dispatch status via the legend, preserving order.
+func units(units []pulpo.Unit, legend map[string]string) []cad.Unit {
+	out := make([]cad.Unit, 0, len(units))
+	for _, u := range units {
+		if u.UnitID == "" {
+			continue
+		}
+		status := u.DispatchStatus
+		if decoded, ok := legend[strings.ToUpper(u.DispatchStatus)]; ok && decoded != "" {
+			status = decoded
+		}
+		out = append(out, cad.Unit{ID: u.UnitID, Status: status})
+	}
+	return out
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
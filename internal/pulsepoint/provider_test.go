@@ -0,0 +1,52 @@
+package pulsepoint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/michaelpeterswa/pulpo"
+	"github.com/searchandrescuegg/transcribe/internal/cad"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToIncidents(t *testing.T) {
+	active := []pulpo.Incident{
+		{
+			ID:                   "res",
+			CallType:             "Rescue - Trail",
+			FullDisplayAddress:   "43000 SE Mount Si Rd, North Bend",
+			PublicLocation:       "Mount Si Trailhead",
+			CallReceivedDateTime: "2026-07-14T14:01:30Z",
+			Latitude:             "47.488",
+			Longitude:            "-121.723",
+			Unit: []pulpo.Unit{
+				{UnitID: "B171", DispatchStatus: "OS"},
+				{UnitID: "E171", DispatchStatus: "XX"},
+				{DispatchStatus: "ER"},
+			},
+		},
+		{ID: "res-no-time", CallType: "RESCUE", PublicLocation: "Tiger Mountain", Latitude: "", Longitude: ""},
+		{ID: "med", CallType: "MEDICAL", MedicalEmergencyDisplayAddress: "Snoqualmie Falls"},
+	}
+
+	got := toIncidents(active, map[string]string{"OS": "On Scene"})
+
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, cad.Incident{
+			ID:         "res",
+			CallType:   "Rescue - Trail",
+			Address:    "43000 SE Mount Si Rd, North Bend",
+			Place:      "Mount Si Trailhead",
+			ReceivedAt: time.Date(2026, 7, 14, 14, 1, 30, 0, time.UTC),
+			Lat:        47.488,
+			Lon:        -121.723,
+			Located:    true,
+			Units:      []cad.Unit{{ID: "B171", Status: "On Scene"}, {ID: "E171", Status: "XX"}},
+		}, got[0], "legend decodes statuses; undecodable codes pass through; unnamed units drop")
+		assert.Equal(t, "Tiger Mountain", got[1].Address, "a public place alone becomes the address")
+		assert.Empty(t, got[1].Place)
+		assert.True(t, got[1].ReceivedAt.IsZero())
+		assert.False(t, got[1].Located)
+		assert.Equal(t, "Snoqualmie Falls", got[2].Address)
+	}
+}
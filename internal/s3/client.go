@@ -2,6 +2,7 @@ package s3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"time"
@@ -12,13 +13,20 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
+// ErrObjectTooLarge is returned when an object exceeds the client's max object size. Callers
+// should treat it as permanent — redelivery won't make the recording any smaller.
+var ErrObjectTooLarge = errors.New("object exceeds max object size")
+
 type S3Client struct {
 	client         *s3.Client
 	bucket         string
 	defaultTimeout time.Duration
+	// maxObjectSize bounds what StreamFile / GetFile will hand out, so a runaway recording (a
+	// stuck-open squelch produces hours of WAV) can't OOM a worker. <= 0 disables the guard.
+	maxObjectSize int64
 }
 
-func NewS3Client(accessKey string, secretKey string, endpoint string, region string, bucket string, defaultTimeout time.Duration) (*S3Client, error) {
+func NewS3Client(accessKey string, secretKey string, endpoint string, region string, bucket string, defaultTimeout time.Duration, maxObjectSize int64) (*S3Client, error) {
 	cfg, err := config.LoadDefaultConfig(context.TODO(),
 		config.WithRegion(region),
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
@@ -36,28 +44,124 @@ func NewS3Client(accessKey string, secretKey string, endpoint string, region str
 		client:         client,
 		bucket:         bucket,
 		defaultTimeout: defaultTimeout,
+		maxObjectSize:  maxObjectSize,
 	}, nil
 }
 
+// GetFile reads a whole object into memory. Prefer StreamFile for anything that can be
+// consumed incrementally; GetFile is kept for small objects and callers that need []byte.
 func (c *S3Client) GetFile(ctx context.Context, key string) ([]byte, error) {
-	getFileCtx, cancel := context.WithTimeout(ctx, c.defaultTimeout)
-	defer cancel()
+	body, _, err := c.StreamFile(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
 
-	result, err := c.client.GetObject(getFileCtx, &s3.GetObjectInput{
+	bodyBytes, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object body: %w", err)
+	}
+	return bodyBytes, nil
+}
+
+// StreamFile opens an object for reading and returns its body and size (-1 when the server
+// didn't report one). The caller must Close the body. The S3 timeout covers the whole read,
+// not just the GET, so a stalled download can't pin a worker.
+//
+// The max-size guard applies twice: up front against Content-Length, and while reading, so an
+// object with a missing or lying Content-Length still fails with ErrObjectTooLarge instead of
+// being read to the end.
+func (c *S3Client) StreamFile(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	streamCtx, cancel := context.WithTimeout(ctx, c.defaultTimeout)
+
+	result, err := c.client.GetObject(streamCtx, &s3.GetObjectInput{
 		Bucket: aws.String(c.bucket),
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get object from S3: %w", err)
+		cancel()
+		return nil, 0, fmt.Errorf("failed to get object from S3: %w", err)
+	}
+	if result.Body == nil {
+		cancel()
+		return nil, 0, fmt.Errorf("object body is nil for key: %s", key)
 	}
 
-	bodyBytes, err := io.ReadAll(result.Body) // Read the body to ensure it is not nil
-	if err != nil {
-		return nil, fmt.Errorf("failed to read object body: %w", err)
+	size := int64(-1)
+	if result.ContentLength != nil {
+		size = *result.ContentLength
 	}
-	if result.Body == nil {
-		return nil, fmt.Errorf("object body is nil for key: %s", key)
+	if c.maxObjectSize > 0 && size > c.maxObjectSize {
+		_ = result.Body.Close()
+		cancel()
+		return nil, 0, fmt.Errorf("%w: %s is %d bytes (max %d)", ErrObjectTooLarge, key, size, c.maxObjectSize)
 	}
 
-	return bodyBytes, nil
+	return &objectBody{
+		body:   result.Body,
+		cancel: cancel,
+		key:    key,
+		max:    c.maxObjectSize,
+	}, size, nil
+}
+
+// objectBody ties the stream's context to the body's lifetime and enforces the size cap
+// on bytes actually read.
+type objectBody struct {
+	body   io.ReadCloser
+	cancel context.CancelFunc
+	key    string
+	max    int64
+	read   int64
+}
+
+func (b *objectBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.read += int64(n)
+	if b.max > 0 && b.read > b.max {
+		return n, fmt.Errorf("%w: %s exceeded %d bytes while reading", ErrObjectTooLarge, b.key, b.max)
+	}
+	return n, err
+}
+
+func (b *objectBody) Close() error {
+	defer b.cancel()
+	return b.body.Close()
+}
+
+// PutObject writes body to key. Pass a seekable body (bytes.Reader, *os.File): against a
+// plain-HTTP endpoint such as the local MinIO the SDK must hash the payload before sending,
+// and rejects unseekable streams. size may be -1 when unknown.
+func (c *S3Client) PutObject(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	putCtx, cancel := context.WithTimeout(ctx, c.defaultTimeout)
+	defer cancel()
+
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(c.bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	}
+	if size >= 0 {
+		input.ContentLength = aws.Int64(size)
+	}
+	if _, err := c.client.PutObject(putCtx, input); err != nil {
+		return fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+	return nil
+}
+
+// Presign returns a time-limited GET URL for key. Signing is local (no S3 round-trip), so the
+// only failure mode is a misconfigured client. SigV4 caps ttl at 7 days.
+func (c *S3Client) Presign(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	req, err := s3.NewPresignClient(c.client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign object %s: %w", key, err)
+	}
+	return req.URL, nil
 }
@@ -0,0 +1,36 @@
+package s3
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectBody_EnforcesMaxWhileReading(t *testing.T) {
+	cancelled := false
+	b := &objectBody{
+		body:   io.NopCloser(bytes.NewReader(make([]byte, 64))),
+		cancel: func() { cancelled = true },
+		key:    "1385-1.wav",
+		max:    32,
+	}
+	_, err := io.ReadAll(b)
+	assert.ErrorIs(t, err, ErrObjectTooLarge, "a missing or lying Content-Length must not bypass the guard")
+
+	require.NoError(t, b.Close())
+	assert.True(t, cancelled, "closing the body releases the stream context")
+}
+
+func TestObjectBody_UnderMaxReadsEverything(t *testing.T) {
+	b := &objectBody{
+		body:   io.NopCloser(bytes.NewReader([]byte("RIFF....WAVE"))),
+		cancel: func() {},
+		max:    32,
+	}
+	got, err := io.ReadAll(b)
+	require.NoError(t, err)
+	assert.Equal(t, "RIFF....WAVE", string(got))
+}
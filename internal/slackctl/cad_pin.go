@@ -0,0 +1,97 @@
+package slackctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/searchandrescuegg/transcribe/internal/transcribe"
+	"github.com/slack-go/slack"
+)
+
+// PinCADIncident pins the CAD incident the rescue's unit context comes from, overriding the
+// automatic match, or clears the pin when cadIncidentID is transcribe.CADPinAutomatic.
+//
+// Effects, in order:
+//  1. Read tac_meta:<incident>; ok=false (no error) when the rescue is no longer active.
+//  2. SET pulpo_pin:<incident>, or DEL it for automatic. The pin carries tac_meta's TTL rather
+//     than the activation window's: nothing refreshes it, and Extend, Keep, re-pages or traffic
+//     can keep a rescue open well past the window. Close and Cancel delete it.
+//  3. DEL pulpo_units:<incident> so the next transmission or summary re-resolves under the pin
+//     instead of serving the cached match until it expires.
+func (c *Controller) PinCADIncident(ctx context.Context, incidentID, cadIncidentID string) (meta transcribe.ClosureMeta, ok bool, err error) {
+	if incidentID == "" || cadIncidentID == "" {
+		return transcribe.ClosureMeta{}, false, errors.New("PinCADIncident: incident ID and CAD incident ID are required")
+	}
+
+	meta, found, err := c.readClosureMeta(ctx, incidentID)
+	if err != nil {
+		return transcribe.ClosureMeta{}, false, err
+	}
+	if !found {
+		return transcribe.ClosureMeta{}, false, nil
+	}
+
+	pinKey := fmt.Sprintf(pulpoPinKeyFmt, incidentID)
+	if cadIncidentID == transcribe.CADPinAutomatic {
+		if err := c.dfly.Del(ctx, pinKey); err != nil {
+			return meta, false, fmt.Errorf("del pulpo_pin:<incident>: %w", err)
+		}
+	} else if err := c.dfly.Set(ctx, pinKey, closureMetaTTL, cadIncidentID); err != nil {
+		return meta, false, fmt.Errorf("set pulpo_pin:<incident>: %w", err)
+	}
+	if err := c.dfly.Del(ctx, fmt.Sprintf(pulpoUnitsKeyFmt, incidentID)); err != nil {
+		return meta, false, fmt.Errorf("del pulpo_units:<incident>: %w", err)
+	}
+	return meta, true, nil
+}
+
+func (c *Controller) handleCADPin(ctx context.Context, payload slack.InteractionCallback, action *slack.BlockAction) {
+	incidentID := strings.TrimPrefix(action.BlockID, transcribe.CADMatchBlockIDPrefix)
+	if incidentID == "" || incidentID == action.BlockID {
+		slog.Warn("slackctl: cad_pin action has unparseable block_id", slog.String("block_id", action.BlockID))
+		c.postEphemeral(payload, ":warning: Pin failed (malformed action). Check service logs.")
+		return
+	}
+	cadIncidentID := action.SelectedOption.Value
+	if cadIncidentID == "" {
+		c.postEphemeral(payload, ":warning: No CAD incident selected.")
+		return
+	}
+
+	meta, ok, err := c.PinCADIncident(ctx, incidentID, cadIncidentID)
+	switch {
+	case err != nil:
+		slog.Error("slackctl: CAD pin failed",
+			slog.String("error", err.Error()),
+			slog.String("incident", incidentID),
+			slog.String("cad_incident", cadIncidentID),
+			slog.String("user", payload.User.ID))
+		c.postEphemeral(payload, ":warning: Pin failed; check service logs.")
+		return
+	case !ok:
+		c.postEphemeral(payload, ":information_source: This rescue is no longer active (already cancelled or auto-expired).")
+		return
+	}
+
+	slog.Info("slackctl: pinned CAD incident",
+		slog.String("user", payload.User.ID),
+		slog.String("user_name", payload.User.Name),
+		slog.String("incident", incidentID),
+		slog.String("cad_incident", cadIncidentID))
+
+	threadMsg := fmt.Sprintf(":pushpin: CAD incident *%s* pinned by <@%s>. Unit context comes from it from the next transmission.", cadIncidentID, payload.User.ID)
+	if cadIncidentID == transcribe.CADPinAutomatic {
+		threadMsg = fmt.Sprintf(":pushpin: CAD match set back to automatic by <@%s>.", payload.User.ID)
+	}
+	if _, _, _, err := c.slackClient.SendMessageContext(ctx,
+		c.cfg.SlackChannelID,
+		slack.MsgOptionText(threadMsg, false),
+		slack.MsgOptionTS(meta.ThreadTS),
+		slack.MsgOptionAsUser(true),
+	); err != nil {
+		slog.Error("slackctl: failed to post CAD pin thread reply", slog.String("error", err.Error()))
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/searchandrescuegg/transcribe/internal/notify"
 	"github.com/searchandrescuegg/transcribe/internal/transcribe"
 	"github.com/slack-go/slack"
 )
@@ -15,23 +17,46 @@ import (
 // allowedTalkgroupsKey and tgRoutingKeyFmt mirror the keys written by the transcribe
 // service. Kept here as constants (instead of importing from internal/transcribe) so a
 // schema change is visible at the controller layer too — both sides need to agree.
+//
+// allowed_talkgroups, tg:<TGID> and tac_incident:<TGID> are keyed by talkgroup; everything
+// else by incident ID (see internal/transcribe/incident.go). Button values and the actions
+// block_id carry the incident ID — or, on alerts posted before incident IDs existed, the TGID,
+// which is those rescues' key.
 const (
 	allowedTalkgroupsKey = "allowed_talkgroups"
 	tgRoutingKeyFmt      = "tg:%s"
 	tacMetaKeyFmt        = "tac_meta:%s"
 	activeTACsKey        = "active_tacs"
 	// Live-interpretation sidecars; mirror constants in internal/transcribe/live_interpretation.go.
-	// Cancel and Switch must clear them so a closed-then-reopened rescue doesn't inherit
-	// stale transcripts from the previous incident.
-	tacTranscriptsKeyFmt = "tac_transcripts:%s"
-	summaryTSKeyFmt      = "summary_ts:%s"
-	summaryLockKeyFmt    = "summary_lock:%s"
-	summaryStaleKeyFmt   = "summary_stale:%s"
-	summaryDataKeyFmt    = "summary_data:%s"
-	// pulpoUnitsKeyFmt caches the CAD unit-context block; mirror of the constant in
-	// internal/transcribe/unit_context.go. Cleared here so a reopened rescue doesn't inherit a
-	// stale unit roster.
+	// Cancel clears them, with the rest of the incident's state, through
+	// transcribe.DeleteIncidentState.
+	summaryTSKeyFmt   = "summary_ts:%s"
+	summaryDataKeyFmt = "summary_data:%s"
+	// pulpoUnitsKeyFmt caches the CAD unit context; mirror of the constant in
+	// internal/transcribe/unit_context.go.
 	pulpoUnitsKeyFmt = "pulpo_units:%s"
+	// pulpoPinKeyFmt is the operator's CAD incident pin; mirror of the constant in
+	// internal/transcribe/unit_context.go.
+	pulpoPinKeyFmt = "pulpo_pin:%s"
+	// tacIncidentKeyFmt is the TGID→incident index; mirror of the constant in
+	// internal/transcribe/incident.go.
+	tacIncidentKeyFmt = "tac_incident:%s"
+	// splitCandidateKeyFmt holds a re-page awaiting a Split decision; mirror of the constant
+	// in internal/transcribe/split.go.
+	splitCandidateKeyFmt = "split_candidate:%s"
+	// closeSuggestedKeyFmt marks an incident whose outcome close prompt has posted and holds
+	// the expiry Keep restores; mirror of the constant in internal/transcribe/close_suggestion.go.
+	closeSuggestedKeyFmt = "close_suggested:%s"
+	// closeSuggestionKept is the close_suggested value Keep leaves behind; mirror of the
+	// constant in internal/transcribe/close_suggestion.go.
+	closeSuggestionKept = "kept"
+	// closedIncidentsKey and incidentRecordKeyFmt hold the shift digest's closed-incident
+	// records; mirror of the constants in internal/transcribe/incident_record.go.
+	closedIncidentsKey   = "closed_incidents"
+	incidentRecordKeyFmt = "incident_record:%s"
+	// closureMetaTTL is tac_meta's safety-net TTL, also used by incident state that must last
+	// as long as the rescue does; mirror of the constant in internal/transcribe/sweeper.go.
+	closureMetaTTL = 24 * time.Hour
 )
 
 // CancelTAC performs the state mutations for a Cancel / False Alarm action. It is
@@ -40,25 +65,30 @@ const (
 // thread.
 //
 // Effects, in order:
-//  1. Remove the TGID from `allowed_talkgroups` so further TAC transmissions are dropped
-//     by the rules.IsObjectAllowed check.
-//  2. Delete the `tg:<TGID>` routing key so processNonDispatchCall returns a clean
-//     "thread ID empty" error rather than posting into a channel that's been cancelled.
-//  3. Remove the pending closure from the `active_tacs` ZSET so the sweeper doesn't fire
+//  1. Detach every TAC on the incident: SREM from `allowed_talkgroups` so further TAC
+//     transmissions are dropped by the rules.IsObjectAllowed check, and DEL the
+//     `tg:<TGID>` routing key and `tac_incident:<TGID>` index entry so processNonDispatchCall
+//     returns a clean "thread ID empty" error rather than posting into a cancelled thread.
+//  2. Remove the pending closure from the `active_tacs` ZSET so the sweeper doesn't fire
 //     a "channel closed" message after a cancellation already announced the close.
-//  4. Delete the metadata sibling key.
+//  3. With DIGEST_ENABLED, keep a false-alarm incident record for the shift digest (read from
+//     the summary before it is deleted). Best-effort: a failure is logged, not returned.
+//  4. Delete the metadata key and the incident's sidecars, pending split candidates included
+//     (transcribe.DeleteIncidentState, shared with the sweeper).
+//  5. Send a cancelled Closed event to the secondary notification sinks, which heard the alert
+//     and would otherwise never hear the rescue end. Best-effort, like every secondary.
 //
-// Returns ok=false (no error) when the TAC was not currently active (e.g. another worker
+// Returns ok=false (no error) when the incident was not currently active (e.g. another worker
 // already cancelled, or the TAC has already auto-expired). Callers surface this to the
 // user as "no longer active" rather than as a failure.
-func (c *Controller) CancelTAC(ctx context.Context, tgid string) (meta transcribe.ClosureMeta, ok bool, err error) {
-	if tgid == "" {
-		return transcribe.ClosureMeta{}, false, errors.New("CancelTAC: TGID is required")
+func (c *Controller) CancelTAC(ctx context.Context, incidentID string) (meta transcribe.ClosureMeta, ok bool, err error) {
+	if incidentID == "" {
+		return transcribe.ClosureMeta{}, false, errors.New("CancelTAC: incident ID is required")
 	}
 
 	// Read the metadata first so we can return it even if some of the deletes fail later.
 	// This is best-effort — if the metadata key is gone the TAC has likely already expired.
-	meta, found, err := c.readClosureMeta(ctx, tgid)
+	meta, found, err := c.readClosureMeta(ctx, incidentID)
 	if err != nil {
 		return transcribe.ClosureMeta{}, false, err
 	}
@@ -66,49 +96,79 @@ func (c *Controller) CancelTAC(ctx context.Context, tgid string) (meta transcrib
 		return transcribe.ClosureMeta{}, false, nil
 	}
 
-	if err := c.dfly.SRem(ctx, allowedTalkgroupsKey, tgid); err != nil {
-		return meta, false, fmt.Errorf("SRem allowed_talkgroups: %w", err)
-	}
-	if err := c.dfly.Del(ctx, fmt.Sprintf(tgRoutingKeyFmt, tgid)); err != nil {
-		return meta, false, fmt.Errorf("del tg:<TGID>: %w", err)
+	if err := c.detachTACs(ctx, meta.TGIDs()...); err != nil {
+		return meta, false, err
 	}
-	if _, err := c.dfly.ZRem(ctx, activeTACsKey, tgid); err != nil {
+	if _, err := c.dfly.ZRem(ctx, activeTACsKey, incidentID); err != nil {
 		return meta, false, fmt.Errorf("ZRem active_tacs: %w", err)
 	}
-	if err := c.dfly.Del(ctx,
-		fmt.Sprintf(tacMetaKeyFmt, tgid),
-		fmt.Sprintf(tacTranscriptsKeyFmt, tgid),
-		fmt.Sprintf(summaryTSKeyFmt, tgid),
-		fmt.Sprintf(summaryLockKeyFmt, tgid),
-		fmt.Sprintf(summaryStaleKeyFmt, tgid),
-		fmt.Sprintf(summaryDataKeyFmt, tgid),
-		fmt.Sprintf(pulpoUnitsKeyFmt, tgid),
-	); err != nil {
+	if c.cfg.DigestEnabled {
+		c.recordFalseAlarm(ctx, meta)
+	}
+	if err := transcribe.DeleteIncidentState(ctx, c.dfly, incidentID); err != nil {
 		return meta, false, fmt.Errorf("del closure sidecars: %w", err)
 	}
+	if c.notifier != nil {
+		_ = c.notifier.Closed(ctx, &notify.Closed{
+			Incident: notify.Incident{ID: meta.IncidentID, TACChannel: meta.TACChannel},
+			Reason:   notify.ClosedCancelled,
+			ClosedAt: time.Now(),
+		})
+	}
 	return meta, true, nil
 }
 
-func (c *Controller) readClosureMeta(ctx context.Context, tgid string) (transcribe.ClosureMeta, bool, error) {
-	raw, err := c.dfly.Get(ctx, fmt.Sprintf(tacMetaKeyFmt, tgid))
+// recordFalseAlarm writes the digest's record for a cancelled incident, the Cancel-side
+// counterpart of the sweeper's recordClosedIncident.
+func (c *Controller) recordFalseAlarm(ctx context.Context, meta transcribe.ClosureMeta) {
+	var summary *ml.RescueSummary
+	if raw, err := c.dfly.Get(ctx, fmt.Sprintf(summaryDataKeyFmt, meta.IncidentID)); err == nil && raw != "" {
+		var s ml.RescueSummary
+		if json.Unmarshal([]byte(raw), &s) == nil {
+			summary = &s
+		}
+	}
+	now := time.Now()
+	encoded, err := json.Marshal(transcribe.NewIncidentRecord(meta, summary, now, true))
+	if err != nil {
+		slog.Warn("slackctl: failed to encode incident record", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		return
+	}
+	if err := c.dfly.Set(ctx, fmt.Sprintf(incidentRecordKeyFmt, meta.IncidentID), c.cfg.DigestRetention, string(encoded)); err != nil {
+		slog.Warn("slackctl: failed to write incident record", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		return
+	}
+	if err := c.dfly.ZAdd(ctx, closedIncidentsKey, float64(now.Unix()), meta.IncidentID); err != nil {
+		slog.Warn("slackctl: failed to index incident record", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+	}
+}
+
+// readClosureMeta reads tac_meta:<incident>. Legacy records carry no IncidentID; the key they
+// were read from (their TGID) is their ID, so it's filled in and callers can always key off
+// meta.IncidentID.
+func (c *Controller) readClosureMeta(ctx context.Context, incidentID string) (transcribe.ClosureMeta, bool, error) {
+	raw, err := c.dfly.Get(ctx, fmt.Sprintf(tacMetaKeyFmt, incidentID))
 	if err != nil {
-		return transcribe.ClosureMeta{}, false, fmt.Errorf("get tac_meta:<TGID>: %w", err)
+		return transcribe.ClosureMeta{}, false, fmt.Errorf("get tac_meta:<incident>: %w", err)
 	}
 	if raw == "" {
 		return transcribe.ClosureMeta{}, false, nil
 	}
 	var meta transcribe.ClosureMeta
 	if err := json.Unmarshal([]byte(raw), &meta); err != nil {
-		return transcribe.ClosureMeta{}, false, fmt.Errorf("unmarshal tac_meta:<TGID>: %w", err)
+		return transcribe.ClosureMeta{}, false, fmt.Errorf("unmarshal tac_meta:<incident>: %w", err)
+	}
+	if meta.IncidentID == "" {
+		meta.IncidentID = incidentID
 	}
 	return meta, true, nil
 }
 
 func (c *Controller) handleCancel(ctx context.Context, payload slack.InteractionCallback, action *slack.BlockAction) {
-	tgid := action.Value
-	meta, ok, err := c.CancelTAC(ctx, tgid)
+	incidentID := action.Value
+	meta, ok, err := c.CancelTAC(ctx, incidentID)
 	if err != nil {
-		slog.Error("slackctl: cancel state mutation failed", slog.String("error", err.Error()), slog.String("tgid", tgid), slog.String("user", payload.User.ID))
+		slog.Error("slackctl: cancel state mutation failed", slog.String("error", err.Error()), slog.String("incident", incidentID), slog.String("user", payload.User.ID))
 		c.postEphemeral(payload, ":warning: Cancel failed; check service logs.")
 		return
 	}
@@ -120,7 +180,7 @@ func (c *Controller) handleCancel(ctx context.Context, payload slack.Interaction
 	slog.Info("slackctl: cancelled TAC monitoring",
 		slog.String("user", payload.User.ID),
 		slog.String("user_name", payload.User.Name),
-		slog.String("tgid", tgid),
+		slog.String("incident", incidentID),
 		slog.String("tac_channel", meta.TACChannel),
 	)
 
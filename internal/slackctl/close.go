@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/searchandrescuegg/transcribe/internal/transcribe"
@@ -18,21 +19,22 @@ import (
 // reply — identical to a natural expiry.
 //
 // Effects, in order:
-//  1. Read closure metadata for the TGID; ok=false (no error) when no longer active.
-//  2. SREM the TGID from allowed_talkgroups so further TAC traffic is rejected
-//     immediately rather than waiting for the per-member SADDEX TTL to lapse.
-//  3. DEL tg:<TGID> for the same reason — defensive routing cutoff.
-//  4. ZADD active_tacs with score = now-1 so the sweeper claims it on its next tick
+//  1. Read closure metadata for the incident; ok=false (no error) when no longer active.
+//  2. Detach every TAC on the incident — SREM from allowed_talkgroups, DEL tg:<TGID> and
+//     tac_incident:<TGID> — so further TAC traffic is rejected immediately rather than
+//     waiting for the per-member SADDEX TTL to lapse. The TGIDs stay in tac_meta for the
+//     closure rendering.
+//  3. ZADD active_tacs with score = now-1 so the sweeper claims it on its next tick
 //     (~5s) and runs postChannelClosed + updateAlertForClosure + sidecar cleanup.
 //
 // We deliberately do NOT touch tac_meta, tac_transcripts, summary_*, or call ZRem on
 // active_tacs — the sweeper owns those deletions, and clearing summary_data prematurely
 // would silently strip the feedback URL prefill (same hazard as invariant #4 in CLAUDE.md).
-func (c *Controller) CloseTAC(ctx context.Context, tgid string) (meta transcribe.ClosureMeta, ok bool, err error) {
-	if tgid == "" {
-		return transcribe.ClosureMeta{}, false, errors.New("CloseTAC: TGID is required")
+func (c *Controller) CloseTAC(ctx context.Context, incidentID string) (meta transcribe.ClosureMeta, ok bool, err error) {
+	if incidentID == "" {
+		return transcribe.ClosureMeta{}, false, errors.New("CloseTAC: incident ID is required")
 	}
-	meta, found, err := c.readClosureMeta(ctx, tgid)
+	meta, found, err := c.readClosureMeta(ctx, incidentID)
 	if err != nil {
 		return transcribe.ClosureMeta{}, false, err
 	}
@@ -40,26 +42,23 @@ func (c *Controller) CloseTAC(ctx context.Context, tgid string) (meta transcribe
 		return transcribe.ClosureMeta{}, false, nil
 	}
 
-	if err := c.dfly.SRem(ctx, allowedTalkgroupsKey, tgid); err != nil {
-		return meta, false, fmt.Errorf("SRem allowed_talkgroups: %w", err)
-	}
-	if err := c.dfly.Del(ctx, fmt.Sprintf(tgRoutingKeyFmt, tgid)); err != nil {
-		return meta, false, fmt.Errorf("del tg:<TGID>: %w", err)
+	if err := c.detachTACs(ctx, meta.TGIDs()...); err != nil {
+		return meta, false, err
 	}
 	// ZAdd updates the score for an existing member, so the original future-dated entry
 	// is moved into the past and picked up on the next sweeper tick.
 	triggerScore := float64(time.Now().Unix() - 1)
-	if err := c.dfly.ZAdd(ctx, activeTACsKey, triggerScore, tgid); err != nil {
+	if err := c.dfly.ZAdd(ctx, activeTACsKey, triggerScore, incidentID); err != nil {
 		return meta, false, fmt.Errorf("ZAdd active_tacs trigger: %w", err)
 	}
 	return meta, true, nil
 }
 
 func (c *Controller) handleClose(ctx context.Context, payload slack.InteractionCallback, action *slack.BlockAction) {
-	tgid := action.Value
-	meta, ok, err := c.CloseTAC(ctx, tgid)
+	incidentID := action.Value
+	meta, ok, err := c.CloseTAC(ctx, incidentID)
 	if err != nil {
-		slog.Error("slackctl: close state mutation failed", slog.String("error", err.Error()), slog.String("tgid", tgid), slog.String("user", payload.User.ID))
+		slog.Error("slackctl: close state mutation failed", slog.String("error", err.Error()), slog.String("incident", incidentID), slog.String("user", payload.User.ID))
 		c.postEphemeral(payload, ":warning: Close failed; check service logs.")
 		return
 	}
@@ -71,10 +70,16 @@ func (c *Controller) handleClose(ctx context.Context, payload slack.InteractionC
 	slog.Info("slackctl: closed TAC monitoring early",
 		slog.String("user", payload.User.ID),
 		slog.String("user_name", payload.User.Name),
-		slog.String("tgid", tgid),
+		slog.String("incident", incidentID),
 		slog.String("tac_channel", meta.TACChannel),
 	)
 
+	// Pressed on an outcome close prompt rather than the alert: retire the prompt so its Keep
+	// button can't be pressed after the fact.
+	if strings.HasPrefix(action.BlockID, transcribe.CloseSuggestionBlockIDPrefix) {
+		c.retirePrompt(ctx, payload, transcribe.CloseSuggestionBlockIDPrefix, fmt.Sprintf("Closed by <@%s>.", payload.User.ID))
+	}
+
 	// Brief attribution reply for the audit trail. The sweeper will follow up within
 	// ~TACSweeperInterval with the canonical "Channel Closed" message and rewrite the
 	// parent alert (including the Submit Feedback button).
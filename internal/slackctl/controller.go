@@ -13,6 +13,7 @@ import (
 
 	"github.com/searchandrescuegg/transcribe/internal/config"
 	"github.com/searchandrescuegg/transcribe/internal/dragonfly"
+	"github.com/searchandrescuegg/transcribe/internal/notify"
 	"github.com/searchandrescuegg/transcribe/internal/transcribe"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/socketmode"
@@ -30,6 +31,11 @@ type Controller struct {
 	dfly        *dragonfly.DragonflyClient
 	cfg         *config.Config
 
+	// notifier is the secondary notification sinks (NOTIFY_SINKS), with no primary: the
+	// controller writes its own Slack replies. Cancel tells them the rescue ended, since the
+	// sweeper never will. Nil when no sinks are configured.
+	notifier *notify.FanOut
+
 	// allowed is the membership set of Slack user IDs permitted to act on alerts. Built
 	// once at construction; refreshing requires a service restart, which is a deliberate
 	// trade-off to keep authorization auditable from one place (the env / secret manager).
@@ -42,8 +48,9 @@ type Controller struct {
 }
 
 // New constructs the controller. Returns ErrSocketModeDisabled if SLACK_APP_TOKEN is
-// empty so callers can no-op gracefully when the feature isn't configured.
-func New(cfg *config.Config, dfly *dragonfly.DragonflyClient) (*Controller, error) {
+// empty so callers can no-op gracefully when the feature isn't configured. notifier may be
+// nil.
+func New(cfg *config.Config, dfly *dragonfly.DragonflyClient, notifier *notify.FanOut) (*Controller, error) {
 	if cfg.SlackAppToken == "" {
 		return nil, ErrSocketModeDisabled
 	}
@@ -89,6 +96,7 @@ func New(cfg *config.Config, dfly *dragonfly.DragonflyClient) (*Controller, erro
 		slackClient: api,
 		dfly:        dfly,
 		cfg:         cfg,
+		notifier:    notifier,
 		allowed:     allowed,
 		allowAny:    allowAny,
 	}, nil
@@ -144,9 +152,19 @@ func (c *Controller) dispatch(evt *socketmode.Event, client *socketmode.Client)
 			c.handleExtend(ctx, payload, action)
 		case transcribe.ActionIDRescueSwitchTAC:
 			c.handleSwitchTAC(ctx, payload, action)
+		case transcribe.ActionIDRescueAddTAC:
+			c.handleAddTAC(ctx, payload, action)
+		case transcribe.ActionIDRescueDropTAC:
+			c.handleDropTAC(ctx, payload, action)
 		case transcribe.ActionIDRescueDelete:
 			c.handleDelete(ctx, payload, action)
-		case transcribe.ActionIDFeedbackForm:
+		case transcribe.ActionIDRescueSplit:
+			c.handleSplit(ctx, payload, action)
+		case transcribe.ActionIDRescueKeep:
+			c.handleKeep(ctx, payload, action)
+		case transcribe.ActionIDCADPin:
+			c.handleCADPin(ctx, payload, action)
+		case transcribe.ActionIDFeedbackForm, transcribe.ActionIDMapLink:
 			// URL buttons fire a block_actions event AND open the link client-side — Slack
 			// sends both. We have nothing to do server-side; this case exists only to
 			// suppress the "unknown action_id" warn that would otherwise log on every click.
@@ -10,6 +10,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/searchandrescuegg/transcribe/internal/config"
 	"github.com/searchandrescuegg/transcribe/internal/dragonfly"
+	"github.com/searchandrescuegg/transcribe/internal/notify"
 	"github.com/searchandrescuegg/transcribe/internal/transcribe"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -95,9 +96,21 @@ func (s *SlackctlSuite) SetupTest() {
 	}
 }
 
-// preloadActiveTAC sets up Dragonfly state to mirror what processDispatchCall produces:
-// allowed_talkgroups SADDEX, tg:<TGID> Set, active_tacs ZAdd, tac_meta:<TGID> Set.
+// preloadActiveTAC sets up Dragonfly state the way processDispatchCall produced it before
+// incident IDs: everything keyed by the TAC's TGID and no tac_incident index entry. Most
+// tests use it, which keeps the legacy layout covered for as long as such rescues can exist.
 func (s *SlackctlSuite) preloadActiveTAC(tgid, tac, threadTS string) {
+	s.preload(tgid, tgid, tac, threadTS)
+}
+
+// preloadIncident mirrors current processDispatchCall output: incident state keyed by the
+// incident ID, plus the tac_incident:<TGID> index entry.
+func (s *SlackctlSuite) preloadIncident(incidentID, tgid, tac, threadTS string) {
+	s.preload(incidentID, tgid, tac, threadTS)
+	s.Require().NoError(s.dfly.Set(s.ctx, fmt.Sprintf(tacIncidentKeyFmt, tgid), 30*time.Minute, incidentID))
+}
+
+func (s *SlackctlSuite) preload(key, tgid, tac, threadTS string) {
 	dur := 30 * time.Minute
 	expiresAt := time.Now().Add(dur)
 
@@ -111,9 +124,12 @@ func (s *SlackctlSuite) preloadActiveTAC(tgid, tac, threadTS string) {
 		SourceTalkgroup: "1399",
 		MessageTS:       threadTS,
 	}
+	if key != tgid {
+		meta.IncidentID = key
+	}
 	payload, _ := json.Marshal(meta)
-	s.Require().NoError(s.dfly.Set(s.ctx, fmt.Sprintf(tacMetaKeyFmt, tgid), 24*time.Hour, string(payload)))
-	s.Require().NoError(s.dfly.ZAdd(s.ctx, activeTACsKey, float64(expiresAt.Unix()), tgid))
+	s.Require().NoError(s.dfly.Set(s.ctx, fmt.Sprintf(tacMetaKeyFmt, key), 24*time.Hour, string(payload)))
+	s.Require().NoError(s.dfly.ZAdd(s.ctx, activeTACsKey, float64(expiresAt.Unix()), key))
 }
 
 // ============================================================================
@@ -150,6 +166,84 @@ func (s *SlackctlSuite) TestCancelTAC_ClearsAllStateAndReturnsMeta() {
 	s.EqualValues(0, exists, "tac_meta:1389 must be deleted")
 }
 
+// A Split prompt left in a cancelled rescue's thread must not be able to open a new incident.
+func (s *SlackctlSuite) TestCancelTAC_DeletesPendingSplitCandidates() {
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	const candidate = "01J9ZC8Q3V7N4X2K5M6P8R0T2X"
+	s.preloadIncident(incident, "1389", "TAC1", "ts-rescue-1")
+	s.Require().NoError(s.dfly.Set(s.ctx, fmt.Sprintf(splitCandidateKeyFmt, candidate), 30*time.Minute, `{"incident_id":"`+candidate+`"}`))
+	s.Require().NoError(s.dfly.RPush(s.ctx, "split_candidates:"+incident, candidate))
+
+	_, ok, err := s.controller.CancelTAC(s.ctx, incident)
+	s.Require().NoError(err)
+	s.True(ok)
+
+	_, found, err := s.controller.readSplitCandidate(s.ctx, candidate)
+	s.Require().NoError(err)
+	s.False(found, "the candidate must go with its rescue")
+	exists, err := s.rdb.Exists(s.ctx, "split_candidates:"+incident).Result()
+	s.Require().NoError(err)
+	s.EqualValues(0, exists)
+}
+
+func (s *SlackctlSuite) TestCancelTAC_DigestKeepsFalseAlarmRecord() {
+	s.controller.cfg.DigestEnabled = true
+	s.controller.cfg.DigestRetention = 72 * time.Hour
+	s.preloadActiveTAC("1389", "TAC1", "ts-rescue-1")
+	s.Require().NoError(s.rdb.Set(s.ctx, fmt.Sprintf(summaryDataKeyFmt, "1389"), `{"headline":"Hiker reported overdue"}`, time.Hour).Err())
+
+	_, ok, err := s.controller.CancelTAC(s.ctx, "1389")
+	s.Require().NoError(err)
+	s.True(ok)
+
+	raw, err := s.rdb.Get(s.ctx, fmt.Sprintf(incidentRecordKeyFmt, "1389")).Result()
+	s.Require().NoError(err, "the record outlives the sidecar cleanup")
+	var record transcribe.IncidentRecord
+	s.Require().NoError(json.Unmarshal([]byte(raw), &record))
+	s.True(record.FalseAlarm)
+	s.Equal("Hiker reported overdue", record.Headline, "headline is read before summary_data is deleted")
+	_, err = s.rdb.ZScore(s.ctx, closedIncidentsKey, "1389").Result()
+	s.NoError(err, "indexed for the digest")
+}
+
+// closedRecorder is a secondary sink that records the Closed events it receives.
+type closedRecorder struct {
+	closed []*notify.Closed
+}
+
+func (r *closedRecorder) Name() string                                           { return "recorder" }
+func (r *closedRecorder) Alert(context.Context, *notify.Alert) error             { return nil }
+func (r *closedRecorder) ThreadReply(context.Context, *notify.ThreadReply) error { return nil }
+func (r *closedRecorder) LiveInterpretation(context.Context, *notify.LiveInterpretation) error {
+	return nil
+}
+func (r *closedRecorder) Closed(_ context.Context, e *notify.Closed) error {
+	r.closed = append(r.closed, e)
+	return nil
+}
+
+func (s *SlackctlSuite) TestCancelTAC_TellsTheSecondarySinks() {
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	s.preloadIncident(incident, "1389", "TAC1", "ts-rescue-1")
+	sink := &closedRecorder{}
+	s.controller.notifier = notify.NewFanOut(time.Second)
+	s.controller.notifier.Add(sink)
+
+	_, ok, err := s.controller.CancelTAC(s.ctx, incident)
+	s.Require().NoError(err)
+	s.Require().True(ok)
+	s.Require().Len(sink.closed, 1)
+	s.Equal(incident, sink.closed[0].ID)
+	s.Equal("TAC1", sink.closed[0].TACChannel)
+	s.Equal(notify.ClosedCancelled, sink.closed[0].Reason)
+
+	// A second click finds nothing to cancel and sends nothing.
+	_, ok, err = s.controller.CancelTAC(s.ctx, incident)
+	s.Require().NoError(err)
+	s.False(ok)
+	s.Len(sink.closed, 1)
+}
+
 func (s *SlackctlSuite) TestCancelTAC_AlreadyExpired_ReturnsNotOk() {
 	// Nothing preloaded — simulate a TAC that already auto-expired before the click landed.
 	_, ok, err := s.controller.CancelTAC(s.ctx, "1389")
@@ -197,6 +291,30 @@ func (s *SlackctlSuite) TestExtendTAC_RefreshesTTLsAndScore() {
 	s.Equal("ts-rescue-1", thread)
 }
 
+// Extend is capped by TAC_MAX_LIFETIME and held against TAC_IDLE_TIMEOUT traffic.
+func (s *SlackctlSuite) TestExtendTAC_CappedByLifetimeAndHeld() {
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	s.preloadIncident(incident, "1389", "TAC1", "ts-rescue-1")
+	dispatchedAt := time.Now().Add(-50 * time.Minute)
+	s.Require().NoError(s.controller.writeClosureMeta(s.ctx, transcribe.ClosureMeta{
+		IncidentID: incident, TGID: "1389", TACChannel: "TAC1", ThreadTS: "ts-rescue-1", DispatchedAt: dispatchedAt,
+	}))
+	s.controller.cfg.TACMaxLifetime = time.Hour
+
+	newExpiry, _, ok, err := s.controller.ExtendTAC(s.ctx, incident)
+	s.Require().NoError(err)
+	s.True(ok)
+	s.Equal(dispatchedAt.Add(time.Hour).Unix(), newExpiry.Unix(), "no Extend runs past dispatch + lifetime")
+	score, err := s.rdb.ZScore(s.ctx, activeTACsKey, incident).Result()
+	s.Require().NoError(err)
+	s.EqualValues(newExpiry.Unix(), score)
+
+	meta, found, err := s.controller.readClosureMeta(s.ctx, incident)
+	s.Require().NoError(err)
+	s.Require().True(found)
+	s.Equal(newExpiry.Unix(), meta.HeldUntil.Unix(), "traffic must not pull the close in before the Extend ends")
+}
+
 func (s *SlackctlSuite) TestExtendTAC_AlreadyExpired_ReturnsNotOk() {
 	_, _, ok, err := s.controller.ExtendTAC(s.ctx, "1389")
 	s.Require().NoError(err)
@@ -207,48 +325,62 @@ func (s *SlackctlSuite) TestExtendTAC_AlreadyExpired_ReturnsNotOk() {
 // SwitchTAC
 // ============================================================================
 
-func (s *SlackctlSuite) TestSwitchTAC_MovesAllStateToNewTGID() {
+func (s *SlackctlSuite) TestSwitchTAC_RepointsRoutingAndKeepsIncidentState() {
 	// Active rescue on TAC1 (1389). Leadership corrects it to TAC8 (1963).
-	s.preloadActiveTAC("1389", "TAC1", "ts-rescue-1")
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	s.preloadIncident(incident, "1389", "TAC1", "ts-rescue-1")
+	s.Require().NoError(s.dfly.Set(s.ctx, fmt.Sprintf(summaryDataKeyFmt, incident), 30*time.Minute, `{"headline":"hiker fall"}`))
 
-	newMeta, newExpiry, ok, err := s.controller.SwitchTAC(s.ctx, "1389", "1963")
+	newMeta, newExpiry, ok, err := s.controller.SwitchTAC(s.ctx, incident, "1963")
 	s.Require().NoError(err)
 	s.True(ok)
+	s.Equal(incident, newMeta.IncidentID, "the incident keeps its identity across switches")
 	s.Equal("1963", newMeta.TGID)
 	s.Equal("TAC8", newMeta.TACChannel)
 	s.Equal("ts-rescue-1", newMeta.ThreadTS, "thread context must be preserved across switches")
 	s.WithinDuration(time.Now().Add(30*time.Minute), newExpiry, 2*time.Second, "new expiry should be a fresh activation window from now")
 
-	// allowed_talkgroups should contain new TGID, not old.
-	oldMember, err := s.rdb.SIsMember(s.ctx, allowedTalkgroupsKey, "1389").Result()
+	// Talkgroup-scoped routing moves: old TGID detached, new TGID routed into the incident.
+	s.assertTACDetached("1389")
+	s.assertTACRouted("1963", "ts-rescue-1", incident)
+
+	// Incident-scoped state stays where it was, rewritten in place.
+	newScore, err := s.rdb.ZScore(s.ctx, activeTACsKey, incident).Result()
+	s.Require().NoError(err)
+	s.InDelta(float64(newExpiry.Unix()), newScore, 2)
+	stored, found, err := s.controller.readClosureMeta(s.ctx, incident)
 	s.Require().NoError(err)
-	s.False(oldMember, "old TGID 1389 must be removed from allowed_talkgroups")
-	newMember, err := s.rdb.SIsMember(s.ctx, allowedTalkgroupsKey, "1963").Result()
+	s.Require().True(found)
+	s.Equal("1963", stored.TGID)
+	summary, err := s.rdb.Get(s.ctx, fmt.Sprintf(summaryDataKeyFmt, incident)).Result()
 	s.Require().NoError(err)
-	s.True(newMember, "new TGID 1963 must be added to allowed_talkgroups")
+	s.Equal(`{"headline":"hiker fall"}`, summary, "sidecars are not copied or dropped by a switch")
 
-	// Routing keys: old gone, new present with original thread_ts.
-	exists, err := s.rdb.Exists(s.ctx, fmt.Sprintf(tgRoutingKeyFmt, "1389")).Result()
+	// Nothing is created under either TGID.
+	exists, err := s.rdb.Exists(s.ctx, fmt.Sprintf(tacMetaKeyFmt, "1389"), fmt.Sprintf(tacMetaKeyFmt, "1963")).Result()
 	s.Require().NoError(err)
-	s.EqualValues(0, exists, "tg:1389 must be deleted")
-	thread, err := s.rdb.Get(s.ctx, fmt.Sprintf(tgRoutingKeyFmt, "1963")).Result()
+	s.EqualValues(0, exists)
+}
+
+func (s *SlackctlSuite) TestSwitchTAC_LegacyRescueKeepsTGIDKey() {
+	// A rescue scheduled before incident IDs is keyed by its original TGID; after a switch it
+	// stays under that key and the index routes the new TAC to it.
+	s.preloadActiveTAC("1389", "TAC1", "ts-rescue-1")
+
+	newMeta, _, ok, err := s.controller.SwitchTAC(s.ctx, "1389", "1963")
 	s.Require().NoError(err)
-	s.Equal("ts-rescue-1", thread, "tg:1963 must point at the original thread_ts")
+	s.True(ok)
+	s.Equal("1389", newMeta.IncidentID)
+	s.assertTACDetached("1389")
+	s.assertTACRouted("1963", "ts-rescue-1", "1389")
 
-	// active_tacs ZSET: old gone, new present.
 	_, err = s.rdb.ZScore(s.ctx, activeTACsKey, "1389").Result()
-	s.Equal(redis.Nil, err, "active_tacs must no longer contain 1389")
-	newScore, err := s.rdb.ZScore(s.ctx, activeTACsKey, "1963").Result()
-	s.Require().NoError(err)
-	s.InDelta(float64(newExpiry.Unix()), newScore, 2)
+	s.Require().NoError(err, "the legacy rescue keeps its active_tacs member")
 
-	// Metadata keys: old gone, new present and decodable.
-	exists, err = s.rdb.Exists(s.ctx, fmt.Sprintf(tacMetaKeyFmt, "1389")).Result()
-	s.Require().NoError(err)
-	s.EqualValues(0, exists, "tac_meta:1389 must be deleted")
-	exists, err = s.rdb.Exists(s.ctx, fmt.Sprintf(tacMetaKeyFmt, "1963")).Result()
+	// The old TAC is free again even though tac_meta:1389 still exists.
+	owner, err := s.controller.tacOwner(s.ctx, "1389")
 	s.Require().NoError(err)
-	s.EqualValues(1, exists, "tac_meta:1963 must be written")
+	s.Empty(owner)
 }
 
 func (s *SlackctlSuite) TestSwitchTAC_SameTGID_ReturnsErrSwitchSameTAC() {
@@ -275,21 +407,22 @@ func (s *SlackctlSuite) TestSwitchTAC_UnknownNewTGID_Errors() {
 	s.Require().Error(err)
 }
 
-func TestParseOldTGIDFromBlockID(t *testing.T) {
+func TestParseIncidentIDFromBlockID(t *testing.T) {
 	cases := map[string]struct {
 		in   string
 		want string
 		ok   bool
 	}{
-		"happy":         {in: "rescue_actions:1389", want: "1389", ok: true},
+		"happy":         {in: "rescue_actions:01J9ZC8Q3V7N4X2K5M6P8R0T1W", want: "01J9ZC8Q3V7N4X2K5M6P8R0T1W", ok: true},
 		"missing colon": {in: "rescue_actions", want: "", ok: false},
-		"empty TGID":    {in: "rescue_actions:", want: "", ok: false},
+		"legacy TGID":   {in: "rescue_actions:1389", want: "1389", ok: true},
+		"empty ID":      {in: "rescue_actions:", want: "", ok: false},
 		"wrong prefix":  {in: "other:1389", want: "", ok: false},
 		"empty input":   {in: "", want: "", ok: false},
 	}
 	for name, c := range cases {
 		t.Run(name, func(t *testing.T) {
-			got, ok := parseOldTGIDFromBlockID(c.in)
+			got, ok := parseIncidentIDFromBlockID(c.in)
 			assert.Equal(t, c.want, got)
 			assert.Equal(t, c.ok, ok)
 		})
@@ -353,6 +486,392 @@ func (s *SlackctlSuite) TestCloseTAC_EmptyTGID_ReturnsError() {
 	s.Require().Error(err)
 }
 
+// ============================================================================
+// KeepMonitoring (outcome close prompt)
+// ============================================================================
+
+func (s *SlackctlSuite) TestKeepMonitoring_RestoresPreviousExpiry() {
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	s.preloadIncident(incident, "1389", "TAC1", "ts-rescue-1")
+	previous := time.Now().Add(25 * time.Minute).Unix()
+	// What the prompt leaves behind: the old expiry stashed, the close pulled in.
+	s.Require().NoError(s.dfly.Set(s.ctx, fmt.Sprintf(closeSuggestedKeyFmt, incident), time.Hour, previous))
+	s.Require().NoError(s.dfly.ZAdd(s.ctx, activeTACsKey, float64(time.Now().Add(5*time.Minute).Unix()), incident))
+
+	expiresAt, meta, ok, err := s.controller.KeepMonitoring(s.ctx, incident)
+	s.Require().NoError(err)
+	s.True(ok)
+	s.Equal("TAC1", meta.TACChannel)
+	s.Equal(previous, expiresAt.Unix())
+	score, err := s.rdb.ZScore(s.ctx, activeTACsKey, incident).Result()
+	s.Require().NoError(err)
+	s.EqualValues(previous, score)
+
+	marker, err := s.rdb.Get(s.ctx, fmt.Sprintf(closeSuggestedKeyFmt, incident)).Result()
+	s.Require().NoError(err)
+	s.Equal(closeSuggestionKept, marker, "the marker stays so a later terminal outcome doesn't prompt again")
+}
+
+func (s *SlackctlSuite) TestKeepMonitoring_LapsedPreviousExpiryGetsFullWindow() {
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	s.preloadIncident(incident, "1389", "TAC1", "ts-rescue-1")
+	s.Require().NoError(s.dfly.Set(s.ctx, fmt.Sprintf(closeSuggestedKeyFmt, incident), time.Hour, time.Now().Add(-time.Minute).Unix()))
+	s.Require().NoError(s.dfly.ZAdd(s.ctx, activeTACsKey, float64(time.Now().Add(2*time.Minute).Unix()), incident))
+
+	expiresAt, _, ok, err := s.controller.KeepMonitoring(s.ctx, incident)
+	s.Require().NoError(err)
+	s.True(ok)
+	s.InDelta(time.Now().Add(30*time.Minute).Unix(), expiresAt.Unix(), 2)
+}
+
+func (s *SlackctlSuite) TestKeepMonitoring_AfterCloseReturnsNotOk() {
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	s.preloadIncident(incident, "1389", "TAC1", "ts-rescue-1")
+	// The sweeper has claimed the grace close but not yet cleaned up.
+	s.Require().NoError(s.rdb.ZRem(s.ctx, activeTACsKey, incident).Err())
+
+	_, _, ok, err := s.controller.KeepMonitoring(s.ctx, incident)
+	s.Require().NoError(err)
+	s.False(ok)
+	count, err := s.rdb.ZCard(s.ctx, activeTACsKey).Result()
+	s.Require().NoError(err)
+	s.EqualValues(0, count, "Keep must not resurrect a claimed closure")
+}
+
+// ============================================================================
+// Multi-TAC (AddTAC / DropTAC)
+// ============================================================================
+
+// assertTACRouted checks the allow-list, routing key and incident index entry of a TAC.
+func (s *SlackctlSuite) assertTACRouted(tgid, threadTS, incidentID string) {
+	member, err := s.rdb.SIsMember(s.ctx, allowedTalkgroupsKey, tgid).Result()
+	s.Require().NoError(err)
+	s.True(member, "%s must be in allowed_talkgroups", tgid)
+	thread, err := s.rdb.Get(s.ctx, fmt.Sprintf(tgRoutingKeyFmt, tgid)).Result()
+	s.Require().NoError(err)
+	s.Equal(threadTS, thread, "tg:%s must route into the rescue thread", tgid)
+	indexed, err := s.rdb.Get(s.ctx, fmt.Sprintf(tacIncidentKeyFmt, tgid)).Result()
+	s.Require().NoError(err)
+	s.Equal(incidentID, indexed, "tac_incident:%s must point at the incident", tgid)
+}
+
+func (s *SlackctlSuite) assertTACDetached(tgid string) {
+	member, err := s.rdb.SIsMember(s.ctx, allowedTalkgroupsKey, tgid).Result()
+	s.Require().NoError(err)
+	s.False(member, "%s must be removed from allowed_talkgroups", tgid)
+	exists, err := s.rdb.Exists(s.ctx, fmt.Sprintf(tgRoutingKeyFmt, tgid), fmt.Sprintf(tacIncidentKeyFmt, tgid)).Result()
+	s.Require().NoError(err)
+	s.EqualValues(0, exists, "routing and index keys for %s must be deleted", tgid)
+}
+
+func (s *SlackctlSuite) TestAddTAC_RoutesNewTACIntoPrimaryIncident() {
+	s.preloadActiveTAC("1385", "TAC3", "ts-rescue-1")
+
+	meta, ok, err := s.controller.AddTAC(s.ctx, "1385", "1963")
+	s.Require().NoError(err)
+	s.True(ok)
+	s.Equal([]string{"1963"}, meta.AdditionalTGIDs)
+
+	stored, found, err := s.controller.readClosureMeta(s.ctx, "1385")
+	s.Require().NoError(err)
+	s.Require().True(found)
+	s.Equal([]string{"1963"}, stored.AdditionalTGIDs, "tac_meta must record the additional TAC")
+	s.assertTACRouted("1963", "ts-rescue-1", "1385")
+
+	// The additional TAC lapses with the primary rather than getting a fresh window.
+	ttl, err := s.rdb.TTL(s.ctx, fmt.Sprintf(tacIncidentKeyFmt, "1963")).Result()
+	s.Require().NoError(err)
+	s.InDelta((30 * time.Minute).Seconds(), ttl.Seconds(), 5)
+
+	// No incident state is created under the additional TGID.
+	_, err = s.rdb.ZScore(s.ctx, activeTACsKey, "1963").Result()
+	s.Equal(redis.Nil, err, "additional TACs must not get their own closure")
+}
+
+func (s *SlackctlSuite) TestAddTAC_RejectsMonitoredAndInUseTACs() {
+	s.preloadActiveTAC("1385", "TAC3", "ts-rescue-1")
+	s.preloadActiveTAC("1389", "TAC1", "ts-rescue-2")
+
+	_, _, err := s.controller.AddTAC(s.ctx, "1385", "1385")
+	s.ErrorIs(err, ErrTACAlreadyMonitored)
+
+	_, _, err = s.controller.AddTAC(s.ctx, "1385", "1389")
+	s.ErrorIs(err, ErrTACInUse, "a TAC that is another rescue's primary can't be added")
+
+	_, ok, err := s.controller.AddTAC(s.ctx, "1389", "1963")
+	s.Require().NoError(err)
+	s.Require().True(ok)
+	_, _, err = s.controller.AddTAC(s.ctx, "1385", "1963")
+	s.ErrorIs(err, ErrTACInUse, "a TAC that is another rescue's additional TAC can't be added")
+
+	s.preloadIncident("01J9ZC8Q3V7N4X2K5M6P8R0T1W", "1965", "TAC9", "ts-rescue-3")
+	_, _, err = s.controller.AddTAC(s.ctx, "1385", "1965")
+	s.ErrorIs(err, ErrTACInUse, "a TAC indexed to another incident can't be added")
+}
+
+func (s *SlackctlSuite) TestCancelTAC_ClearsIncidentKeyedState() {
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	s.preloadIncident(incident, "1389", "TAC1", "ts-rescue-1")
+	s.Require().NoError(s.dfly.Set(s.ctx, fmt.Sprintf(summaryDataKeyFmt, incident), 30*time.Minute, `{}`))
+	s.Require().NoError(s.dfly.Set(s.ctx, fmt.Sprintf(pulpoPinKeyFmt, incident), 30*time.Minute, "26-2"))
+
+	meta, ok, err := s.controller.CancelTAC(s.ctx, incident)
+	s.Require().NoError(err)
+	s.True(ok)
+	s.Equal(incident, meta.IncidentID)
+	s.assertTACDetached("1389")
+
+	_, err = s.rdb.ZScore(s.ctx, activeTACsKey, incident).Result()
+	s.Equal(redis.Nil, err)
+	exists, err := s.rdb.Exists(s.ctx, fmt.Sprintf(tacMetaKeyFmt, incident), fmt.Sprintf(summaryDataKeyFmt, incident),
+		fmt.Sprintf(pulpoPinKeyFmt, incident)).Result()
+	s.Require().NoError(err)
+	s.EqualValues(0, exists, "incident-keyed state must be deleted")
+}
+
+func (s *SlackctlSuite) TestDropTAC_DetachesOnlyTheAdditionalTAC() {
+	s.preloadActiveTAC("1385", "TAC3", "ts-rescue-1")
+	_, _, err := s.controller.AddTAC(s.ctx, "1385", "1963")
+	s.Require().NoError(err)
+
+	_, _, err = s.controller.DropTAC(s.ctx, "1385", "1385")
+	s.ErrorIs(err, ErrTACNotAdditional, "the primary can't be dropped")
+
+	meta, ok, err := s.controller.DropTAC(s.ctx, "1385", "1963")
+	s.Require().NoError(err)
+	s.True(ok)
+	s.Empty(meta.AdditionalTGIDs)
+	s.assertTACDetached("1963")
+
+	member, err := s.rdb.SIsMember(s.ctx, allowedTalkgroupsKey, "1385").Result()
+	s.Require().NoError(err)
+	s.True(member, "the primary must keep monitoring")
+}
+
+// AddTAC's metadata write is the mutate below. A reschedule (the transcribe service recording
+// a re-page) that lands between its read and its write aborts the transaction, and the retry
+// keeps both changes.
+func (s *SlackctlSuite) TestAddTAC_InterleavedRescheduleKeepsBothChanges() {
+	s.preloadActiveTAC("1385", "TAC3", "ts-rescue-1")
+	heldUntil := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+
+	runs := 0
+	meta, found, err := s.controller.updateClosureMeta(s.ctx, "1385", func(meta *transcribe.ClosureMeta) error {
+		runs++
+		if runs == 1 {
+			rescheduled := *meta
+			rescheduled.HeldUntil = heldUntil
+			payload, _ := json.Marshal(rescheduled)
+			s.Require().NoError(s.rdb.Set(s.ctx, fmt.Sprintf(tacMetaKeyFmt, "1385"), string(payload), 24*time.Hour).Err())
+		}
+		meta.AdditionalTGIDs = append(meta.AdditionalTGIDs, "1963")
+		return nil
+	})
+	s.Require().NoError(err)
+	s.Require().True(found)
+	s.Equal(2, runs, "the interleaved write must abort the first attempt")
+
+	stored, found, err := s.controller.readClosureMeta(s.ctx, "1385")
+	s.Require().NoError(err)
+	s.Require().True(found)
+	s.Equal([]string{"1963"}, stored.AdditionalTGIDs)
+	s.True(stored.HeldUntil.Equal(heldUntil), "the reschedule survives too")
+	s.Equal(stored, meta)
+
+	// AddTAC itself goes through the same path.
+	_, ok, err := s.controller.AddTAC(s.ctx, "1385", "1389")
+	s.Require().NoError(err)
+	s.True(ok)
+	stored, _, err = s.controller.readClosureMeta(s.ctx, "1385")
+	s.Require().NoError(err)
+	s.Equal([]string{"1963", "1389"}, stored.AdditionalTGIDs)
+	s.True(stored.HeldUntil.Equal(heldUntil))
+}
+
+func (s *SlackctlSuite) TestCancelTAC_DetachesAdditionalTACs() {
+	s.preloadActiveTAC("1385", "TAC3", "ts-rescue-1")
+	_, _, err := s.controller.AddTAC(s.ctx, "1385", "1963")
+	s.Require().NoError(err)
+
+	_, ok, err := s.controller.CancelTAC(s.ctx, "1385")
+	s.Require().NoError(err)
+	s.True(ok)
+	s.assertTACDetached("1963")
+}
+
+func (s *SlackctlSuite) TestSwitchTAC_RepointsAdditionalTACs() {
+	s.preloadActiveTAC("1385", "TAC3", "ts-rescue-1")
+	_, _, err := s.controller.AddTAC(s.ctx, "1385", "1963")
+	s.Require().NoError(err)
+	_, _, err = s.controller.AddTAC(s.ctx, "1385", "1965")
+	s.Require().NoError(err)
+
+	// Promote one additional TAC to primary: it leaves the additional list, the old primary is
+	// detached, and both remaining TACs still route into the same incident.
+	newMeta, _, ok, err := s.controller.SwitchTAC(s.ctx, "1385", "1963")
+	s.Require().NoError(err)
+	s.True(ok)
+	s.Equal([]string{"1965"}, newMeta.AdditionalTGIDs)
+
+	s.assertTACDetached("1385")
+	s.assertTACRouted("1963", "ts-rescue-1", "1385")
+	s.assertTACRouted("1965", "ts-rescue-1", "1385")
+}
+
+// ============================================================================
+// Split
+// ============================================================================
+
+func splitCandidate(parent, tgid, tac string) transcribe.SplitCandidate {
+	return transcribe.SplitCandidate{
+		IncidentID:       "01J9ZC8Q3V7N4X2K5M6P8R0T1W",
+		ParentIncidentID: parent,
+		TGID:             tgid,
+		TACChannel:       tac,
+		Transcription:    "Rescue Trail, Tiger Mountain, " + tac,
+		SourceTalkgroup:  "1399",
+		DispatchedAt:     time.Now(),
+	}
+}
+
+func (s *SlackctlSuite) storeSplitCandidate(cand transcribe.SplitCandidate) {
+	payload, _ := json.Marshal(cand)
+	s.Require().NoError(s.dfly.Set(s.ctx, fmt.Sprintf(splitCandidateKeyFmt, cand.IncidentID), 30*time.Minute, string(payload)))
+}
+
+func (s *SlackctlSuite) TestSplitIncident_SoleTACClosesParentAndRoutesToNewIncident() {
+	s.preloadActiveTAC("1389", "TAC1", "ts-rescue-1")
+	cand := splitCandidate("1389", "1389", "TAC1")
+	s.storeSplitCandidate(cand)
+
+	res, err := s.controller.SplitIncident(s.ctx, cand, "ts-split-alert")
+	s.Require().NoError(err)
+	s.True(res.ParentClosed)
+	s.Equal(cand.IncidentID, res.Incident.IncidentID)
+	s.Equal("ts-split-alert", res.Incident.ThreadTS)
+	s.assertTACRouted("1389", "ts-split-alert", cand.IncidentID)
+
+	// The parent is handed to the sweeper, the new incident gets a fresh window.
+	parentScore, err := s.rdb.ZScore(s.ctx, activeTACsKey, "1389").Result()
+	s.Require().NoError(err)
+	s.Less(parentScore, float64(time.Now().Unix()), "parent closure must be due")
+	childScore, err := s.rdb.ZScore(s.ctx, activeTACsKey, cand.IncidentID).Result()
+	s.Require().NoError(err)
+	s.InDelta(float64(res.ExpiresAt.Unix()), childScore, 2)
+
+	exists, err := s.rdb.Exists(s.ctx, fmt.Sprintf(splitCandidateKeyFmt, cand.IncidentID)).Result()
+	s.Require().NoError(err)
+	s.EqualValues(0, exists, "a used candidate must be deleted")
+}
+
+func (s *SlackctlSuite) TestSplitIncident_PrimaryWithAdditionalTACPromotesIt() {
+	s.preloadActiveTAC("1385", "TAC3", "ts-rescue-1")
+	_, _, err := s.controller.AddTAC(s.ctx, "1385", "1963")
+	s.Require().NoError(err)
+	cand := splitCandidate("1385", "1385", "TAC3")
+
+	res, err := s.controller.SplitIncident(s.ctx, cand, "ts-split-alert")
+	s.Require().NoError(err)
+	s.False(res.ParentClosed)
+	s.Equal("1963", res.Parent.TGID)
+	s.Equal("TAC8", res.Parent.TACChannel)
+	s.Empty(res.Parent.AdditionalTGIDs)
+	s.assertTACRouted("1963", "ts-rescue-1", "1385")
+	s.assertTACRouted("1385", "ts-split-alert", cand.IncidentID)
+}
+
+func (s *SlackctlSuite) TestSplitIncident_AdditionalTACIsDropped() {
+	s.preloadActiveTAC("1385", "TAC3", "ts-rescue-1")
+	_, _, err := s.controller.AddTAC(s.ctx, "1385", "1963")
+	s.Require().NoError(err)
+	cand := splitCandidate("1385", "1963", "TAC8")
+
+	res, err := s.controller.SplitIncident(s.ctx, cand, "ts-split-alert")
+	s.Require().NoError(err)
+	s.False(res.ParentClosed)
+	s.Equal("1385", res.Parent.TGID)
+	s.Empty(res.Parent.AdditionalTGIDs)
+	s.assertTACRouted("1963", "ts-split-alert", cand.IncidentID)
+
+	thread, err := s.rdb.Get(s.ctx, fmt.Sprintf(tgRoutingKeyFmt, "1385")).Result()
+	s.Require().NoError(err)
+	s.Equal("ts-rescue-1", thread, "the parent keeps its primary TAC")
+}
+
+func (s *SlackctlSuite) TestSplitIncident_RefusesTACClaimedByAnotherRescue() {
+	s.preloadIncident("01J9ZC8Q3V7N4X2K5M6P8R0T2X", "1389", "TAC1", "ts-rescue-2")
+	cand := splitCandidate("1385", "1389", "TAC1")
+
+	_, err := s.controller.SplitIncident(s.ctx, cand, "ts-split-alert")
+	s.ErrorIs(err, ErrTACInUse)
+	s.assertTACRouted("1389", "ts-rescue-2", "01J9ZC8Q3V7N4X2K5M6P8R0T2X")
+}
+
+// ============================================================================
+// PinCADIncident
+// ============================================================================
+
+func (s *SlackctlSuite) TestPinCADIncident_PinsAndDropsCachedContext() {
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	s.preloadIncident(incident, "1389", "TAC1", "ts-rescue-1")
+	unitsKey := fmt.Sprintf(pulpoUnitsKeyFmt, incident)
+	s.Require().NoError(s.dfly.Set(s.ctx, unitsKey, 5*time.Minute, `{"incident_id":"26-1"}`))
+
+	meta, ok, err := s.controller.PinCADIncident(s.ctx, incident, "26-2")
+	s.Require().NoError(err)
+	s.True(ok)
+	s.Equal("ts-rescue-1", meta.ThreadTS)
+
+	pin, err := s.rdb.Get(s.ctx, fmt.Sprintf(pulpoPinKeyFmt, incident)).Result()
+	s.Require().NoError(err)
+	s.Equal("26-2", pin)
+	ttl, err := s.rdb.TTL(s.ctx, fmt.Sprintf(pulpoPinKeyFmt, incident)).Result()
+	s.Require().NoError(err)
+	s.Greater(ttl, 30*time.Minute)
+	exists, err := s.rdb.Exists(s.ctx, unitsKey).Result()
+	s.Require().NoError(err)
+	s.EqualValues(0, exists, "the cached match must be dropped so the pin applies on the next refresh")
+}
+
+// Nothing refreshes the pin, so it must last as long as the rescue can: a rescue held open past
+// the activation window (Extend, Keep, re-pages, traffic) keeps the operator's pin.
+func (s *SlackctlSuite) TestPinCADIncident_OutlivesTheActivationWindow() {
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	s.preloadIncident(incident, "1389", "TAC1", "ts-rescue-1")
+	_, _, err := s.controller.PinCADIncident(s.ctx, incident, "26-2")
+	s.Require().NoError(err)
+
+	pinTTL, err := s.rdb.TTL(s.ctx, fmt.Sprintf(pulpoPinKeyFmt, incident)).Result()
+	s.Require().NoError(err)
+	metaTTL, err := s.rdb.TTL(s.ctx, fmt.Sprintf(tacMetaKeyFmt, incident)).Result()
+	s.Require().NoError(err)
+	s.Greater(pinTTL, 2*s.controller.cfg.TacticalChannelActivationDuration)
+	s.InDelta(metaTTL.Seconds(), pinTTL.Seconds(), 5, "the pin lives as long as tac_meta")
+}
+
+func (s *SlackctlSuite) TestPinCADIncident_AutomaticClearsPin() {
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	s.preloadIncident(incident, "1389", "TAC1", "ts-rescue-1")
+	_, _, err := s.controller.PinCADIncident(s.ctx, incident, "26-2")
+	s.Require().NoError(err)
+
+	_, ok, err := s.controller.PinCADIncident(s.ctx, incident, transcribe.CADPinAutomatic)
+	s.Require().NoError(err)
+	s.True(ok)
+	exists, err := s.rdb.Exists(s.ctx, fmt.Sprintf(pulpoPinKeyFmt, incident)).Result()
+	s.Require().NoError(err)
+	s.EqualValues(0, exists)
+}
+
+func (s *SlackctlSuite) TestPinCADIncident_InactiveRescue() {
+	_, ok, err := s.controller.PinCADIncident(s.ctx, "01J9ZC8Q3V7N4X2K5M6P8R0T1W", "26-2")
+	s.Require().NoError(err)
+	s.False(ok)
+	exists, err := s.rdb.Exists(s.ctx, fmt.Sprintf(pulpoPinKeyFmt, "01J9ZC8Q3V7N4X2K5M6P8R0T1W")).Result()
+	s.Require().NoError(err)
+	s.EqualValues(0, exists, "no pin is written for a rescue that has ended")
+}
+
 // ============================================================================
 // Authorization
 // ============================================================================
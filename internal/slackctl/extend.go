@@ -11,54 +11,55 @@ import (
 	"github.com/slack-go/slack"
 )
 
-// ExtendTAC pushes the auto-close out by another full TacticalChannelActivationDuration.
-// It is independent of Slack so it can be unit-tested directly against Dragonfly.
+// ExtendTAC pushes the auto-close out by another full TacticalChannelActivationDuration,
+// capped at dispatch + TAC_MAX_LIFETIME when that is set. It is independent of Slack so it can be
+// unit-tested directly against Dragonfly.
 //
 // Effects, in order:
-//  1. SAddEx the TGID into `allowed_talkgroups` again — Dragonfly's per-member TTL is
-//     replaced when the same member is re-added, so this functions as a TTL refresh.
-//  2. Re-Set the `tg:<TGID>` routing key with the existing thread_ts and the new TTL.
-//  3. ZAdd the TGID to `active_tacs` with the new score (ZAdd updates the score when the
+//  1. Record the new expiry as the metadata's HeldUntil, so TAC_IDLE_TIMEOUT traffic doesn't
+//     pull the close back in before it. Written atomically (updateClosureMeta), so a TAC added
+//     meanwhile isn't lost.
+//  2. Re-arm every TAC on the incident: SAddEx into `allowed_talkgroups` again (Dragonfly's
+//     per-member TTL is replaced when the same member is re-added, so this functions as a TTL
+//     refresh), and re-Set the `tg:<TGID>` routing key and `tac_incident:<TGID>` index entry
+//     with the new TTL.
+//  3. ZAdd the incident to `active_tacs` with the new score (ZAdd updates the score when the
 //     member already exists).
-//  4. The metadata sibling does not need to change; its safety-net TTL is plenty long.
 //
 // Returns the new expiry time so the caller can render it in the Slack message, plus the
 // closure metadata so the caller can post a thread reply identifying the TAC.
-func (c *Controller) ExtendTAC(ctx context.Context, tgid string) (newExpiry time.Time, meta transcribe.ClosureMeta, ok bool, err error) {
-	if tgid == "" {
-		return time.Time{}, transcribe.ClosureMeta{}, false, errors.New("ExtendTAC: TGID is required")
+func (c *Controller) ExtendTAC(ctx context.Context, incidentID string) (newExpiry time.Time, meta transcribe.ClosureMeta, ok bool, err error) {
+	if incidentID == "" {
+		return time.Time{}, transcribe.ClosureMeta{}, false, errors.New("ExtendTAC: incident ID is required")
 	}
 
-	meta, found, err := c.readClosureMeta(ctx, tgid)
+	dur := c.cfg.TacticalChannelActivationDuration
+	meta, found, err := c.updateClosureMeta(ctx, incidentID, func(meta *transcribe.ClosureMeta) error {
+		newExpiry = meta.CapToLifetime(time.Now().Add(dur), c.cfg.TACMaxLifetime)
+		meta.HeldUntil = newExpiry
+		return nil
+	})
 	if err != nil {
-		return time.Time{}, transcribe.ClosureMeta{}, false, err
+		return time.Time{}, meta, false, err
 	}
 	if !found {
 		return time.Time{}, transcribe.ClosureMeta{}, false, nil
 	}
 
-	dur := c.cfg.TacticalChannelActivationDuration
-	newExpiry = time.Now().Add(dur)
-
-	if err := c.dfly.SAddEx(ctx, allowedTalkgroupsKey, dur, tgid); err != nil {
-		return time.Time{}, meta, false, fmt.Errorf("SAddEx allowed_talkgroups: %w", err)
-	}
-	// Refresh the routing key with the same thread_ts and the new TTL. Set is unconditional
-	// so we don't need a GET first; the value we want is already in meta.ThreadTS.
-	if err := c.dfly.Set(ctx, fmt.Sprintf(tgRoutingKeyFmt, tgid), dur, meta.ThreadTS); err != nil {
-		return time.Time{}, meta, false, fmt.Errorf("set tg:<TGID>: %w", err)
+	if err := c.attachTACs(ctx, meta, dur); err != nil {
+		return time.Time{}, meta, false, err
 	}
-	if err := c.dfly.ZAdd(ctx, activeTACsKey, float64(newExpiry.Unix()), tgid); err != nil {
+	if err := c.dfly.ZAdd(ctx, activeTACsKey, float64(newExpiry.Unix()), incidentID); err != nil {
 		return time.Time{}, meta, false, fmt.Errorf("ZAdd active_tacs: %w", err)
 	}
 	return newExpiry, meta, true, nil
 }
 
 func (c *Controller) handleExtend(ctx context.Context, payload slack.InteractionCallback, action *slack.BlockAction) {
-	tgid := action.Value
-	newExpiry, meta, ok, err := c.ExtendTAC(ctx, tgid)
+	incidentID := action.Value
+	newExpiry, meta, ok, err := c.ExtendTAC(ctx, incidentID)
 	if err != nil {
-		slog.Error("slackctl: extend state mutation failed", slog.String("error", err.Error()), slog.String("tgid", tgid), slog.String("user", payload.User.ID))
+		slog.Error("slackctl: extend state mutation failed", slog.String("error", err.Error()), slog.String("incident", incidentID), slog.String("user", payload.User.ID))
 		c.postEphemeral(payload, ":warning: Extend failed; check service logs.")
 		return
 	}
@@ -70,7 +71,7 @@ func (c *Controller) handleExtend(ctx context.Context, payload slack.Interaction
 	slog.Info("slackctl: extended TAC monitoring",
 		slog.String("user", payload.User.ID),
 		slog.String("user_name", payload.User.Name),
-		slog.String("tgid", tgid),
+		slog.String("incident", incidentID),
 		slog.String("tac_channel", meta.TACChannel),
 		slog.Time("new_expiry", newExpiry),
 	)
@@ -0,0 +1,115 @@
+package slackctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/searchandrescuegg/transcribe/internal/transcribe"
+	"github.com/slack-go/slack"
+)
+
+// KeepMonitoring answers an outcome close prompt with "not yet": it cancels the grace close the
+// prompt scheduled and puts back the expiry the rescue had before it.
+//
+// Effects, in order:
+//  1. Read closure metadata and the pending closure; ok=false (no error) when the rescue has
+//     already closed (the grace period ran out, or someone pressed Close).
+//  2. Read the previous expiry from close_suggested:<incident>. If it is missing or has
+//     passed meanwhile, give the rescue one activation window from now, capped at dispatch +
+//     TAC_MAX_LIFETIME.
+//  3. Record it as the metadata's HeldUntil (atomically, see updateClosureMeta), so
+//     TAC_IDLE_TIMEOUT traffic doesn't pull the close back in before it.
+//  4. Raise the active_tacs score to it (ZRaise: an Extend pressed during the grace period
+//     already pushed it further and wins) and re-arm routing to match.
+//  5. Overwrite close_suggested:<incident> with closeSuggestionKept. The marker stays so a
+//     later "Resolved" summary doesn't prompt again, but TAC_IDLE_TIMEOUT, which leaves the
+//     grace close alone while the prompt is unanswered, goes back to following the traffic.
+//     The sweeper deletes it with the other sidecars.
+func (c *Controller) KeepMonitoring(ctx context.Context, incidentID string) (expiresAt time.Time, meta transcribe.ClosureMeta, ok bool, err error) {
+	if incidentID == "" {
+		return time.Time{}, transcribe.ClosureMeta{}, false, errors.New("KeepMonitoring: incident ID is required")
+	}
+	meta, found, err := c.readClosureMeta(ctx, incidentID)
+	if err != nil {
+		return time.Time{}, transcribe.ClosureMeta{}, false, err
+	}
+	if !found {
+		return time.Time{}, transcribe.ClosureMeta{}, false, nil
+	}
+	score, err := c.dfly.ZScore(ctx, activeTACsKey, incidentID)
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, meta, false, nil
+	}
+	if err != nil {
+		return time.Time{}, meta, false, fmt.Errorf("ZScore active_tacs: %w", err)
+	}
+
+	now := time.Now()
+	expiresAt = meta.CapToLifetime(now.Add(c.cfg.TacticalChannelActivationDuration), c.cfg.TACMaxLifetime)
+	raw, err := c.dfly.Get(ctx, fmt.Sprintf(closeSuggestedKeyFmt, incidentID))
+	if err != nil {
+		return time.Time{}, meta, false, fmt.Errorf("get close_suggested: %w", err)
+	}
+	if previous, err := strconv.ParseInt(raw, 10, 64); err == nil && time.Unix(previous, 0).After(now) {
+		expiresAt = time.Unix(previous, 0)
+	}
+	if current := time.Unix(int64(score), 0); current.After(expiresAt) {
+		expiresAt = current
+	}
+
+	if expiresAt.After(meta.HeldUntil) {
+		meta, found, err = c.updateClosureMeta(ctx, incidentID, func(meta *transcribe.ClosureMeta) error {
+			if expiresAt.After(meta.HeldUntil) {
+				meta.HeldUntil = expiresAt
+			}
+			return nil
+		})
+		if err != nil {
+			return time.Time{}, meta, false, err
+		}
+		if !found {
+			return time.Time{}, transcribe.ClosureMeta{}, false, nil
+		}
+	}
+	if _, err := c.dfly.ZRaise(ctx, activeTACsKey, float64(expiresAt.Unix()), incidentID); err != nil {
+		return time.Time{}, meta, false, fmt.Errorf("ZRaise active_tacs: %w", err)
+	}
+	if err := c.attachTACs(ctx, meta, time.Until(expiresAt)); err != nil {
+		return time.Time{}, meta, false, err
+	}
+	if err := c.dfly.Set(ctx, fmt.Sprintf(closeSuggestedKeyFmt, incidentID), closureMetaTTL, closeSuggestionKept); err != nil {
+		return time.Time{}, meta, false, fmt.Errorf("set close_suggested: %w", err)
+	}
+	return expiresAt, meta, true, nil
+}
+
+func (c *Controller) handleKeep(ctx context.Context, payload slack.InteractionCallback, action *slack.BlockAction) {
+	incidentID := action.Value
+	expiresAt, meta, ok, err := c.KeepMonitoring(ctx, incidentID)
+	if err != nil {
+		slog.Error("slackctl: keep state mutation failed", slog.String("error", err.Error()), slog.String("incident", incidentID), slog.String("user", payload.User.ID))
+		c.postEphemeral(payload, ":warning: Keep failed; check service logs.")
+		return
+	}
+	if !ok {
+		c.postEphemeral(payload, ":information_source: This TAC monitoring window is no longer active (already closed or auto-expired).")
+		return
+	}
+
+	slog.Info("slackctl: kept TAC monitoring after close prompt",
+		slog.String("user", payload.User.ID),
+		slog.String("user_name", payload.User.Name),
+		slog.String("incident", incidentID),
+		slog.String("tac_channel", meta.TACChannel),
+		slog.Time("expires_at", expiresAt),
+	)
+
+	// The retired prompt is the audit trail; no separate thread reply.
+	c.retirePrompt(ctx, payload, transcribe.CloseSuggestionBlockIDPrefix,
+		fmt.Sprintf("Kept open by <@%s>; %s monitoring now closes at %s.", payload.User.ID, meta.TACChannel, expiresAt.Local().Format("15:04 MST")))
+}
@@ -0,0 +1,351 @@
+package slackctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/searchandrescuegg/transcribe/internal/transcribe"
+	"github.com/slack-go/slack"
+)
+
+var (
+	// ErrTACAlreadyMonitored means the picked TAC is already the primary or an additional TAC
+	// on this rescue. Surfaced as an ephemeral "no change".
+	ErrTACAlreadyMonitored = errors.New("TAC already monitored by this rescue")
+	// ErrTACInUse means the picked TAC belongs to a different active rescue. Two incidents
+	// can't share a TAC: its transmissions can only reply into one thread.
+	ErrTACInUse = errors.New("TAC is monitored by another active rescue")
+	// ErrTACNotAdditional means Drop was asked to remove the primary TAC (use Switch or
+	// Close instead) or a TAC the rescue doesn't monitor.
+	ErrTACNotAdditional = errors.New("TAC is not an additional TAC on this rescue")
+)
+
+// AddTAC attaches tgid to the rescue, so its transmissions reply into the same thread and feed
+// the same tac_transcripts list. The new TAC gets only talkgroup-scoped routing, with a TTL
+// matching the rescue's remaining window so everything lapses together.
+//
+// Effects, in order:
+//  1. Read tac_meta:<incident>; ok=false (no error) when the rescue is no longer active.
+//  2. Refuse a TAC already on this rescue (ErrTACAlreadyMonitored) or on another active
+//     rescue (ErrTACInUse).
+//  3. Append the TGID to AdditionalTGIDs in tac_meta:<incident>, atomically (see
+//     updateClosureMeta) so a concurrent rewrite such as a re-page can't drop it. Steps 1-3
+//     repeat if the metadata changes under them.
+//  4. SAddEx the TGID into allowed_talkgroups; SET tg:<TGID> = thread_ts; SET
+//     tac_incident:<TGID> = incident.
+func (c *Controller) AddTAC(ctx context.Context, incidentID, tgid string) (meta transcribe.ClosureMeta, ok bool, err error) {
+	if incidentID == "" || tgid == "" {
+		return transcribe.ClosureMeta{}, false, errors.New("AddTAC: incident ID and TGID are required")
+	}
+	if _, err := c.shortCodeForTGID(tgid); err != nil {
+		return transcribe.ClosureMeta{}, false, fmt.Errorf("resolve added TAC: %w", err)
+	}
+
+	meta, found, err := c.updateClosureMeta(ctx, incidentID, func(meta *transcribe.ClosureMeta) error {
+		if meta.HasTGID(tgid) {
+			return ErrTACAlreadyMonitored
+		}
+		owner, err := c.tacOwner(ctx, tgid)
+		if err != nil {
+			return err
+		}
+		if owner != "" {
+			return ErrTACInUse
+		}
+		meta.AdditionalTGIDs = append(meta.AdditionalTGIDs, tgid)
+		return nil
+	})
+	if err != nil || !found {
+		return meta, false, err
+	}
+
+	ttl := c.remainingWindow(ctx, incidentID)
+	if err := c.dfly.SAddEx(ctx, allowedTalkgroupsKey, ttl, tgid); err != nil {
+		return meta, false, fmt.Errorf("SAddEx allowed_talkgroups: %w", err)
+	}
+	if err := c.dfly.Set(ctx, fmt.Sprintf(tgRoutingKeyFmt, tgid), ttl, meta.ThreadTS); err != nil {
+		return meta, false, fmt.Errorf("set tg:<TGID>: %w", err)
+	}
+	if err := c.dfly.Set(ctx, fmt.Sprintf(tacIncidentKeyFmt, tgid), ttl, meta.IncidentID); err != nil {
+		return meta, false, fmt.Errorf("set tac_incident:<TGID>: %w", err)
+	}
+	return meta, true, nil
+}
+
+// DropTAC detaches an additional TAC from the rescue. Transcripts it already contributed stay
+// in tac_transcripts — they're part of the incident record.
+//
+// Effects, in order:
+//  1. Read tac_meta:<incident>; ok=false (no error) when the rescue is no longer active.
+//  2. Refuse the primary or an unmonitored TAC (ErrTACNotAdditional).
+//  3. Remove the TGID from tac_meta:<incident>, atomically like AddTAC.
+//  4. SREM the TGID from allowed_talkgroups; DEL tg:<TGID> and tac_incident:<TGID>.
+func (c *Controller) DropTAC(ctx context.Context, incidentID, tgid string) (meta transcribe.ClosureMeta, ok bool, err error) {
+	if incidentID == "" || tgid == "" {
+		return transcribe.ClosureMeta{}, false, errors.New("DropTAC: incident ID and TGID are required")
+	}
+
+	meta, found, err := c.updateClosureMeta(ctx, incidentID, func(meta *transcribe.ClosureMeta) error {
+		if !slices.Contains(meta.AdditionalTGIDs, tgid) {
+			return ErrTACNotAdditional
+		}
+		meta.AdditionalTGIDs = slices.DeleteFunc(meta.AdditionalTGIDs, func(t string) bool { return t == tgid })
+		return nil
+	})
+	if err != nil || !found {
+		return meta, false, err
+	}
+	if err := c.detachTACs(ctx, tgid); err != nil {
+		return meta, false, err
+	}
+	return meta, true, nil
+}
+
+// tacOwner returns the incident currently monitoring tgid, or "" when none is. Checks the
+// TGID→incident index, then the legacy layout where a pre-incident-ID rescue is stored under
+// its own TGID.
+func (c *Controller) tacOwner(ctx context.Context, tgid string) (string, error) {
+	owner, err := c.dfly.Get(ctx, fmt.Sprintf(tacIncidentKeyFmt, tgid))
+	if err != nil {
+		return "", fmt.Errorf("get tac_incident:<TGID>: %w", err)
+	}
+	if owner != "" {
+		return owner, nil
+	}
+	legacy, found, err := c.readClosureMeta(ctx, tgid)
+	if err != nil {
+		return "", err
+	}
+	if found && legacy.HasTGID(tgid) {
+		return legacy.IncidentID, nil
+	}
+	return "", nil
+}
+
+// errNoClosureMeta aborts a tac_meta update for a rescue that is no longer active.
+var errNoClosureMeta = errors.New("no closure metadata")
+
+// updateClosureMeta applies mutate to tac_meta:<incident> as one optimistic transaction
+// (dragonfly Update), so a concurrent writer — the transcribe service recording a re-page, or
+// another button — can't undo the change by rewriting a copy it read earlier. found=false (no
+// error) when the rescue is no longer active. mutate may run more than once, each time on a
+// fresh read; an error from it aborts the update and is returned along with that read.
+func (c *Controller) updateClosureMeta(ctx context.Context, incidentID string, mutate func(*transcribe.ClosureMeta) error) (meta transcribe.ClosureMeta, found bool, err error) {
+	err = c.dfly.Update(ctx, fmt.Sprintf(tacMetaKeyFmt, incidentID), closureMetaTTL, func(raw string) (string, error) {
+		if raw == "" {
+			return "", errNoClosureMeta
+		}
+		meta = transcribe.ClosureMeta{}
+		if err := json.Unmarshal([]byte(raw), &meta); err != nil {
+			return "", fmt.Errorf("unmarshal tac_meta:<incident>: %w", err)
+		}
+		if meta.IncidentID == "" {
+			meta.IncidentID = incidentID
+		}
+		if err := mutate(&meta); err != nil {
+			return "", err
+		}
+		payload, err := json.Marshal(meta)
+		if err != nil {
+			return "", fmt.Errorf("marshal closure meta: %w", err)
+		}
+		return string(payload), nil
+	})
+	switch {
+	case errors.Is(err, errNoClosureMeta):
+		return transcribe.ClosureMeta{}, false, nil
+	case err != nil:
+		return meta, true, err
+	}
+	return meta, true, nil
+}
+
+// writeClosureMeta rewrites tac_meta:<incident> in place with the 24h safety-net TTL the
+// transcribe service uses. active_tacs is untouched, so the rescue's expiry doesn't move.
+func (c *Controller) writeClosureMeta(ctx context.Context, meta transcribe.ClosureMeta) error {
+	payload, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("marshal closure meta: %w", err)
+	}
+	if err := c.dfly.Set(ctx, fmt.Sprintf(tacMetaKeyFmt, meta.IncidentID), closureMetaTTL, string(payload)); err != nil {
+		return fmt.Errorf("set tac_meta:<incident>: %w", err)
+	}
+	return nil
+}
+
+// detachTACs removes the talkgroup-scoped routing of the given TACs: allow-list membership,
+// the tg:<TGID> routing key and the tac_incident index entry.
+func (c *Controller) detachTACs(ctx context.Context, tgids ...string) error {
+	if len(tgids) == 0 {
+		return nil
+	}
+	members := make([]any, len(tgids))
+	keys := make([]string, 0, 2*len(tgids))
+	for i, tgid := range tgids {
+		members[i] = tgid
+		keys = append(keys, fmt.Sprintf(tgRoutingKeyFmt, tgid), fmt.Sprintf(tacIncidentKeyFmt, tgid))
+	}
+	if err := c.dfly.SRem(ctx, allowedTalkgroupsKey, members...); err != nil {
+		return fmt.Errorf("SRem allowed_talkgroups: %w", err)
+	}
+	if err := c.dfly.Del(ctx, keys...); err != nil {
+		return fmt.Errorf("del TAC routing: %w", err)
+	}
+	return nil
+}
+
+// attachTACs (re)writes the routing of every TAC on the incident with the given ttl: allow-list
+// membership, tg:<TGID> and the tac_incident index entry. Used by Extend (TTL refresh) and
+// Switch (new primary).
+func (c *Controller) attachTACs(ctx context.Context, meta transcribe.ClosureMeta, ttl time.Duration) error {
+	for _, tgid := range meta.TGIDs() {
+		if err := c.dfly.SAddEx(ctx, allowedTalkgroupsKey, ttl, tgid); err != nil {
+			return fmt.Errorf("SAddEx allowed_talkgroups %s: %w", tgid, err)
+		}
+		if err := c.dfly.Set(ctx, fmt.Sprintf(tgRoutingKeyFmt, tgid), ttl, meta.ThreadTS); err != nil {
+			return fmt.Errorf("set tg:%s: %w", tgid, err)
+		}
+		if err := c.dfly.Set(ctx, fmt.Sprintf(tacIncidentKeyFmt, tgid), ttl, meta.IncidentID); err != nil {
+			return fmt.Errorf("set tac_incident:%s: %w", tgid, err)
+		}
+	}
+	return nil
+}
+
+// remainingWindow is how long the incident has left before auto-close, read from its
+// active_tacs score. Falls back to a full activation window when the score is missing or
+// already past (the sweeper is about to claim it, and a short-lived key is harmless).
+func (c *Controller) remainingWindow(ctx context.Context, incidentID string) time.Duration {
+	if score, err := c.dfly.ZScore(ctx, activeTACsKey, incidentID); err == nil {
+		if remaining := time.Until(time.Unix(int64(score), 0)); remaining > 0 {
+			return remaining
+		}
+	}
+	return c.cfg.TacticalChannelActivationDuration
+}
+
+func (c *Controller) handleAddTAC(ctx context.Context, payload slack.InteractionCallback, action *slack.BlockAction) {
+	c.handleTACMembership(ctx, payload, action, "add", c.AddTAC)
+}
+
+func (c *Controller) handleDropTAC(ctx context.Context, payload slack.InteractionCallback, action *slack.BlockAction) {
+	c.handleTACMembership(ctx, payload, action, "drop", c.DropTAC)
+}
+
+// handleTACMembership is the shared Slack side of Add TAC / Drop TAC. Like Switch, the
+// incident comes from the actions block_id and the target from the selected option.
+func (c *Controller) handleTACMembership(ctx context.Context, payload slack.InteractionCallback, action *slack.BlockAction, verb string,
+	mutate func(ctx context.Context, incidentID, tgid string) (transcribe.ClosureMeta, bool, error)) {
+	incidentID, ok := parseIncidentIDFromBlockID(action.BlockID)
+	if !ok {
+		slog.Warn("slackctl: TAC membership action has unparseable block_id", slog.String("verb", verb), slog.String("block_id", action.BlockID))
+		c.postEphemeral(payload, ":warning: TAC change failed (malformed action). Check service logs.")
+		return
+	}
+	tgid := action.SelectedOption.Value
+	if tgid == "" {
+		c.postEphemeral(payload, ":warning: No TAC selected.")
+		return
+	}
+	channel, _ := c.shortCodeForTGID(tgid)
+	if channel == "" {
+		channel = tgid
+	}
+
+	meta, ok, err := mutate(ctx, incidentID, tgid)
+	switch {
+	case errors.Is(err, ErrTACAlreadyMonitored):
+		c.postEphemeral(payload, fmt.Sprintf(":information_source: Already monitoring %s — no change.", channel))
+		return
+	case errors.Is(err, ErrTACInUse):
+		c.postEphemeral(payload, fmt.Sprintf(":information_source: %s is already being monitored for another active rescue.", channel))
+		return
+	case errors.Is(err, ErrTACNotAdditional):
+		c.postEphemeral(payload, fmt.Sprintf(":information_source: %s isn't an additional TAC on this rescue — use Switch or Close instead.", channel))
+		return
+	case err != nil:
+		slog.Error("slackctl: TAC membership mutation failed",
+			slog.String("error", err.Error()),
+			slog.String("verb", verb),
+			slog.String("incident", incidentID),
+			slog.String("tgid", tgid),
+			slog.String("user", payload.User.ID))
+		c.postEphemeral(payload, ":warning: TAC change failed; check service logs.")
+		return
+	case !ok:
+		c.postEphemeral(payload, ":information_source: This rescue is no longer active (already cancelled or auto-expired).")
+		return
+	}
+
+	slog.Info("slackctl: changed rescue TACs",
+		slog.String("verb", verb),
+		slog.String("user", payload.User.ID),
+		slog.String("user_name", payload.User.Name),
+		slog.String("incident", incidentID),
+		slog.String("tgid", tgid),
+		slog.String("tac_channel", channel))
+
+	threadMsg := fmt.Sprintf(":heavy_plus_sign: Now also monitoring *%s* for this rescue (added by <@%s>).", channel, payload.User.ID)
+	if verb == "drop" {
+		threadMsg = fmt.Sprintf(":heavy_minus_sign: Stopped monitoring %s for this rescue (dropped by <@%s>).", channel, payload.User.ID)
+	}
+	if _, _, _, err := c.slackClient.SendMessageContext(ctx,
+		c.cfg.SlackChannelID,
+		slack.MsgOptionText(threadMsg, false),
+		slack.MsgOptionTS(meta.ThreadTS),
+		slack.MsgOptionAsUser(true),
+	); err != nil {
+		slog.Error("slackctl: failed to post TAC membership thread reply", slog.String("error", err.Error()))
+	}
+
+	c.rerenderAlert(ctx, payload, meta)
+}
+
+// rerenderAlert rebuilds the live alert so its status line and TAC controls reflect the
+// rescue's current TACs. After Add this matters for correctness, not just display: the Drop
+// select only exists once the rescue has an additional TAC. Skipped for rescues whose
+// metadata predates the stored transcription.
+func (c *Controller) rerenderAlert(ctx context.Context, payload slack.InteractionCallback, meta transcribe.ClosureMeta) {
+	if meta.MessageTS == "" || meta.Transcription == "" {
+		return
+	}
+	score, err := c.dfly.ZScore(ctx, activeTACsKey, meta.IncidentID)
+	if err != nil {
+		slog.Warn("slackctl: alert re-render skipped; could not read expiry", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		return
+	}
+
+	var sarNotified bool
+	if raw, err := c.dfly.Get(ctx, fmt.Sprintf(summaryDataKeyFmt, meta.IncidentID)); err == nil && raw != "" {
+		var summary ml.RescueSummary
+		if json.Unmarshal([]byte(raw), &summary) == nil {
+			sarNotified = summary.SARNotified
+		}
+	}
+
+	blocks := transcribe.BuildRescueTrailBlocks(&transcribe.RescueTrailBlocksInput{
+		TACChannel:            meta.TACChannel,
+		TranscriptionText:     meta.Transcription,
+		ExpiresAt:             time.Unix(int64(score), 0).Local(),
+		DispatchTGID:          transcribe.FireDispatch1TGID,
+		TACTalkgroupTGID:      meta.TGID,
+		IncidentID:            meta.IncidentID,
+		SARNotified:           sarNotified,
+		AdditionalTACChannels: meta.AdditionalTACChannels(),
+		MapLink:               meta.MapLink,
+	})
+	if _, _, _, err := c.slackClient.UpdateMessageContext(ctx,
+		payload.Container.ChannelID,
+		meta.MessageTS,
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionText(fmt.Sprintf("Rescue Trail — %s", meta.TACChannel), false),
+	); err != nil {
+		slog.Error("slackctl: failed to re-render alert after TAC change", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,254 @@
+package slackctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/transcribe"
+	"github.com/slack-go/slack"
+)
+
+// SplitResult describes what a split did, for the Slack-side replies.
+type SplitResult struct {
+	// Incident is the new incident created from the re-page.
+	Incident  transcribe.ClosureMeta
+	ExpiresAt time.Time
+	// Parent is the rescue the re-page had been deduped onto, as it stands after the split.
+	// Zero when the parent was no longer active.
+	Parent transcribe.ClosureMeta
+	// ParentClosed is set when the TAC was the parent's only channel, so the parent was closed
+	// through the normal sweeper path.
+	ParentClosed bool
+}
+
+// readSplitCandidate loads a pending split. found=false (no error) when it expired or was
+// already used.
+func (c *Controller) readSplitCandidate(ctx context.Context, candidateID string) (cand transcribe.SplitCandidate, found bool, err error) {
+	raw, err := c.dfly.Get(ctx, fmt.Sprintf(splitCandidateKeyFmt, candidateID))
+	if err != nil {
+		return transcribe.SplitCandidate{}, false, fmt.Errorf("get split_candidate: %w", err)
+	}
+	if raw == "" {
+		return transcribe.SplitCandidate{}, false, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &cand); err != nil {
+		return transcribe.SplitCandidate{}, false, fmt.Errorf("unmarshal split candidate: %w", err)
+	}
+	return cand, true, nil
+}
+
+// checkSplit refuses a split whose TAC has meanwhile been claimed by a rescue other than the
+// one the prompt was posted on (ErrTACInUse).
+func (c *Controller) checkSplit(ctx context.Context, cand transcribe.SplitCandidate) error {
+	owner, err := c.tacOwner(ctx, cand.TGID)
+	if err != nil {
+		return err
+	}
+	if owner != "" && owner != cand.ParentIncidentID {
+		return ErrTACInUse
+	}
+	return nil
+}
+
+// SplitIncident turns a split candidate into its own incident, whose alert the caller has
+// already posted at alertTS. The TAC moves from the parent rescue to the new incident.
+//
+// Effects, in order:
+//  1. Refuse if the TAC now belongs to a third rescue (ErrTACInUse).
+//  2. Release the TAC from the parent, if the parent still monitors it:
+//     - an additional TAC is dropped (as Drop TAC);
+//     - a primary with additional TACs hands the primary role to the first of them;
+//     - a primary that is the parent's only TAC closes the parent (as Close).
+//  3. Write tac_meta:<new incident>, ZADD it at now+activation, and route the TAC to it
+//     (SAddEx allowed_talkgroups, SET tg:<TGID>, SET tac_incident:<TGID>).
+//  4. DEL the candidate so a second press is a no-op.
+func (c *Controller) SplitIncident(ctx context.Context, cand transcribe.SplitCandidate, alertTS string) (SplitResult, error) {
+	if cand.IncidentID == "" || cand.TGID == "" || alertTS == "" {
+		return SplitResult{}, errors.New("SplitIncident: candidate and alert ts are required")
+	}
+	if err := c.checkSplit(ctx, cand); err != nil {
+		return SplitResult{}, err
+	}
+
+	var res SplitResult
+	parent, found, err := c.readClosureMeta(ctx, cand.ParentIncidentID)
+	if err != nil {
+		return SplitResult{}, err
+	}
+	if found && parent.HasTGID(cand.TGID) {
+		parent, res.ParentClosed, err = c.releaseTACFromParent(ctx, parent, cand.TGID)
+		if err != nil {
+			return SplitResult{}, err
+		}
+	}
+	if found {
+		res.Parent = parent
+	}
+
+	dur := c.cfg.TacticalChannelActivationDuration
+	res.ExpiresAt = time.Now().Add(dur)
+	res.Incident = transcribe.ClosureMeta{
+		IncidentID:      cand.IncidentID,
+		TGID:            cand.TGID,
+		TACChannel:      cand.TACChannel,
+		ThreadTS:        alertTS,
+		SourceTalkgroup: cand.SourceTalkgroup,
+		MessageTS:       alertTS,
+		Transcription:   cand.Transcription,
+		DispatchedAt:    cand.DispatchedAt,
+		MapLink:         cand.MapLink,
+	}
+	if err := c.scheduleClosure(ctx, res.Incident, res.ExpiresAt); err != nil {
+		return SplitResult{}, fmt.Errorf("schedule split incident: %w", err)
+	}
+	if err := c.attachTACs(ctx, res.Incident, dur); err != nil {
+		return SplitResult{}, err
+	}
+	if err := c.dfly.Del(ctx, fmt.Sprintf(splitCandidateKeyFmt, cand.IncidentID)); err != nil {
+		slog.Warn("slackctl: failed to delete used split candidate", slog.String("error", err.Error()), slog.String("candidate", cand.IncidentID))
+	}
+	return res, nil
+}
+
+// releaseTACFromParent removes tgid from the parent rescue and returns the parent as it now
+// stands. closed reports that tgid was the parent's only TAC and the parent was closed.
+func (c *Controller) releaseTACFromParent(ctx context.Context, parent transcribe.ClosureMeta, tgid string) (updated transcribe.ClosureMeta, closed bool, err error) {
+	switch {
+	case slices.Contains(parent.AdditionalTGIDs, tgid):
+		updated, _, err = c.DropTAC(ctx, parent.IncidentID, tgid)
+		return updated, false, err
+	case len(parent.AdditionalTGIDs) > 0:
+		promoted := parent.AdditionalTGIDs[0]
+		channel, err := c.shortCodeForTGID(promoted)
+		if err != nil {
+			return parent, false, fmt.Errorf("resolve promoted TAC: %w", err)
+		}
+		updated = parent
+		updated.TGID = promoted
+		updated.TACChannel = channel
+		updated.AdditionalTGIDs = slices.Clone(parent.AdditionalTGIDs[1:])
+		if err := c.writeClosureMeta(ctx, updated); err != nil {
+			return parent, false, err
+		}
+		if err := c.detachTACs(ctx, tgid); err != nil {
+			return updated, false, err
+		}
+		return updated, false, nil
+	default:
+		updated, _, err = c.CloseTAC(ctx, parent.IncidentID)
+		return updated, true, err
+	}
+}
+
+func (c *Controller) handleSplit(ctx context.Context, payload slack.InteractionCallback, action *slack.BlockAction) {
+	candidateID := action.Value
+	cand, found, err := c.readSplitCandidate(ctx, candidateID)
+	if err != nil {
+		slog.Error("slackctl: split candidate read failed", slog.String("error", err.Error()), slog.String("candidate", candidateID))
+		c.postEphemeral(payload, ":warning: Split failed; check service logs.")
+		return
+	}
+	if !found {
+		c.postEphemeral(payload, ":information_source: This split is no longer available (already split, the rescue closed, or the monitoring window lapsed).")
+		return
+	}
+	if err := c.checkSplit(ctx, cand); err != nil {
+		c.respondSplitError(payload, cand, err)
+		return
+	}
+
+	// Post the new alert first: if Slack refuses it, nothing has moved yet.
+	expiresAt := time.Now().Add(c.cfg.TacticalChannelActivationDuration).Local()
+	_, alertTS, err := c.slackClient.PostMessageContext(ctx,
+		c.cfg.SlackChannelID,
+		slack.MsgOptionBlocks(transcribe.BuildRescueTrailBlocks(&transcribe.RescueTrailBlocksInput{
+			TACChannel:        cand.TACChannel,
+			TranscriptionText: cand.Transcription,
+			ExpiresAt:         expiresAt,
+			DispatchTGID:      transcribe.FireDispatch1TGID,
+			TACTalkgroupTGID:  cand.TGID,
+			IncidentID:        cand.IncidentID,
+			MapLink:           cand.MapLink,
+		})...),
+		slack.MsgOptionText(fmt.Sprintf("Rescue Trail — %s", cand.TACChannel), false),
+	)
+	if err != nil {
+		slog.Error("slackctl: failed to post split alert", slog.String("error", err.Error()), slog.String("candidate", candidateID))
+		c.postEphemeral(payload, ":warning: Split failed (could not post the new alert); check service logs.")
+		return
+	}
+
+	res, err := c.SplitIncident(ctx, cand, alertTS)
+	if err != nil {
+		c.respondSplitError(payload, cand, err)
+		return
+	}
+
+	slog.Info("slackctl: split re-page into a new incident",
+		slog.String("user", payload.User.ID),
+		slog.String("user_name", payload.User.Name),
+		slog.String("incident", res.Incident.IncidentID),
+		slog.String("parent", cand.ParentIncidentID),
+		slog.String("tac_channel", cand.TACChannel),
+		slog.Bool("parent_closed", res.ParentClosed))
+
+	// Retire the prompt so it can't be pressed again, keeping the re-page text above it.
+	c.retirePrompt(ctx, payload, "rescue_split:", fmt.Sprintf("Split into a new incident by <@%s>.", payload.User.ID))
+
+	if res.Parent.ThreadTS == "" {
+		return
+	}
+	threadMsg := fmt.Sprintf(":twisted_rightwards_arrows: Split by <@%s>: the %s dispatch is now its own incident, and %s traffic goes to its alert.",
+		payload.User.ID, cand.TACChannel, cand.TACChannel)
+	if res.ParentClosed {
+		threadMsg += " This rescue had no other TAC, so its monitoring is closing."
+	}
+	if _, _, _, err := c.slackClient.SendMessageContext(ctx,
+		c.cfg.SlackChannelID,
+		slack.MsgOptionText(threadMsg, false),
+		slack.MsgOptionTS(res.Parent.ThreadTS),
+		slack.MsgOptionAsUser(true),
+	); err != nil {
+		slog.Error("slackctl: failed to post split thread reply", slog.String("error", err.Error()))
+	}
+	if !res.ParentClosed {
+		c.rerenderAlert(ctx, payload, res.Parent)
+	}
+}
+
+func (c *Controller) respondSplitError(payload slack.InteractionCallback, cand transcribe.SplitCandidate, err error) {
+	if errors.Is(err, ErrTACInUse) {
+		c.postEphemeral(payload, fmt.Sprintf(":information_source: %s is now monitored for another active rescue; switch that rescue first.", cand.TACChannel))
+		return
+	}
+	slog.Error("slackctl: split state mutation failed", slog.String("error", err.Error()), slog.String("candidate", cand.IncidentID), slog.String("user", payload.User.ID))
+	c.postEphemeral(payload, ":warning: Split failed; check service logs.")
+}
+
+// retirePrompt rewrites a clicked thread prompt without its actions block (the one whose
+// block_id starts with blockIDPrefix) and appends note, so the prompt can't be pressed again
+// but its text stays.
+func (c *Controller) retirePrompt(ctx context.Context, payload slack.InteractionCallback, blockIDPrefix, note string) {
+	kept := make([]slack.Block, 0, len(payload.Message.Blocks.BlockSet))
+	for _, b := range payload.Message.Blocks.BlockSet {
+		if a, ok := b.(*slack.ActionBlock); ok && strings.HasPrefix(a.BlockID, blockIDPrefix) {
+			continue
+		}
+		kept = append(kept, b)
+	}
+	kept = append(kept, slack.NewContextBlock("",
+		slack.NewTextBlockObject(slack.MarkdownType, note, false, false)))
+	if _, _, _, err := c.slackClient.UpdateMessageContext(ctx,
+		payload.Container.ChannelID,
+		payload.Container.MessageTs,
+		slack.MsgOptionBlocks(kept...),
+	); err != nil {
+		slog.Warn("slackctl: failed to retire prompt", slog.String("error", err.Error()), slog.String("block_id_prefix", blockIDPrefix))
+	}
+}
@@ -2,10 +2,10 @@ package slackctl
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,37 +17,42 @@ import (
 // ephemeral "no change" message rather than a hard error.
 var ErrSwitchSameTAC = errors.New("switch target equals current TAC")
 
-// SwitchTAC migrates the active rescue's allow-list / routing / closure state from oldTGID
-// to newTGID. Used by the Slack Switch-TAC dropdown when the LLM picked the wrong TAC and
-// leadership corrects it.
+// SwitchTAC moves the rescue's primary TAC to newTGID. Used by the Slack Switch-TAC dropdown
+// when the LLM picked the wrong TAC and leadership corrects it. All incident state is keyed by
+// incident ID, so nothing is copied: the thread, transcripts and live interpretation carry on
+// untouched, and only the talkgroup-scoped routing moves.
 //
 // Effects, in order:
-//  1. Read the closure metadata for oldTGID; if missing, the rescue is no longer active and
-//     we return ok=false (caller surfaces "TAC monitoring no longer active").
-//  2. Refuse if newTGID == oldTGID — same-TAC pick is a no-op.
-//  3. Update tac_meta:<newTGID> with a copy of the metadata pointing at the new TAC.
-//  4. SREM oldTGID from allowed_talkgroups; SAddEx newTGID with a fresh activation window.
-//  5. DEL tg:<oldTGID>; SET tg:<newTGID> = thread_ts (same TTL).
-//  6. ZREM oldTGID from active_tacs; ZADD newTGID at now+activation.
-//  7. DEL tac_meta:<oldTGID>.
+//  1. Read the closure metadata; if missing, the rescue is no longer active and we return
+//     ok=false (caller surfaces "TAC monitoring no longer active").
+//  2. Refuse if newTGID is already the primary (ErrSwitchSameTAC) or belongs to another
+//     active rescue (ErrTACInUse).
+//  3. Rewrite tac_meta:<incident> with the new TGID/TACChannel and ZADD the incident at
+//     now+activation (capped by TAC_MAX_LIFETIME, and held against TAC_IDLE_TIMEOUT).
+//  4. Re-arm routing for every TAC on the incident (SAddEx allowed_talkgroups, SET tg:<TGID>,
+//     SET tac_incident:<TGID>) with the fresh window.
+//  5. Detach the old TGID: SREM allowed_talkgroups, DEL tg:<oldTGID> and its index entry.
 //
-// Returns the updated metadata (with TGID/TACChannel set to the new values) plus the new
-// expiry so the Slack-side handler can include the new auto-close time in the thread reply.
-func (c *Controller) SwitchTAC(ctx context.Context, oldTGID, newTGID string) (newMeta transcribe.ClosureMeta, newExpiry time.Time, ok bool, err error) {
-	if oldTGID == "" || newTGID == "" {
-		return transcribe.ClosureMeta{}, time.Time{}, false, errors.New("SwitchTAC: oldTGID and newTGID are required")
-	}
-	if oldTGID == newTGID {
-		return transcribe.ClosureMeta{}, time.Time{}, false, ErrSwitchSameTAC
+// Switching to one of the rescue's additional TACs promotes it: it leaves AdditionalTGIDs and
+// the old primary is detached, as with any other switch.
+//
+// Returns the updated metadata plus the new expiry so the Slack-side handler can include the
+// new auto-close time in the thread reply.
+func (c *Controller) SwitchTAC(ctx context.Context, incidentID, newTGID string) (newMeta transcribe.ClosureMeta, newExpiry time.Time, ok bool, err error) {
+	if incidentID == "" || newTGID == "" {
+		return transcribe.ClosureMeta{}, time.Time{}, false, errors.New("SwitchTAC: incident ID and newTGID are required")
 	}
 
-	oldMeta, found, err := c.readClosureMeta(ctx, oldTGID)
+	oldMeta, found, err := c.readClosureMeta(ctx, incidentID)
 	if err != nil {
 		return transcribe.ClosureMeta{}, time.Time{}, false, fmt.Errorf("read closure meta: %w", err)
 	}
 	if !found {
 		return transcribe.ClosureMeta{}, time.Time{}, false, nil
 	}
+	if oldMeta.TGID == newTGID {
+		return oldMeta, time.Time{}, false, ErrSwitchSameTAC
+	}
 
 	// Resolve the new channel's short code (TAC1, TAC2, ...) from the canonical talkgroup
 	// table so the metadata records human-readable identity, not just a TGID.
@@ -55,17 +60,27 @@ func (c *Controller) SwitchTAC(ctx context.Context, oldTGID, newTGID string) (ne
 	if err != nil {
 		return transcribe.ClosureMeta{}, time.Time{}, false, fmt.Errorf("resolve new TAC: %w", err)
 	}
+	if !oldMeta.HasTGID(newTGID) {
+		owner, err := c.tacOwner(ctx, newTGID)
+		if err != nil {
+			return transcribe.ClosureMeta{}, time.Time{}, false, err
+		}
+		if owner != "" {
+			return oldMeta, time.Time{}, false, ErrTACInUse
+		}
+	}
 
 	dur := c.cfg.TacticalChannelActivationDuration
-	newExpiry = time.Now().Add(dur)
+	newExpiry = oldMeta.CapToLifetime(time.Now().Add(dur), c.cfg.TACMaxLifetime)
 
-	newMeta = transcribe.ClosureMeta{
-		TGID:            newTGID,
-		TACChannel:      newChannel,
-		ThreadTS:        oldMeta.ThreadTS,
-		SourceTalkgroup: oldMeta.SourceTalkgroup,
-		MessageTS:       oldMeta.MessageTS,
-	}
+	newMeta = oldMeta
+	newMeta.TGID = newTGID
+	newMeta.TACChannel = newChannel
+	// The new channel gets a full window even if it stays quiet at first (TAC_IDLE_TIMEOUT).
+	newMeta.HeldUntil = newExpiry
+	newMeta.AdditionalTGIDs = slices.DeleteFunc(slices.Clone(oldMeta.AdditionalTGIDs), func(t string) bool {
+		return t == newTGID
+	})
 
 	// Order: write new state BEFORE removing old. This means a reader observing mid-flight
 	// state sees both (which is fine; allowed_talkgroups membership is the only thing that
@@ -73,49 +88,23 @@ func (c *Controller) SwitchTAC(ctx context.Context, oldTGID, newTGID string) (ne
 	if err := c.scheduleClosure(ctx, newMeta, newExpiry); err != nil {
 		return transcribe.ClosureMeta{}, time.Time{}, false, fmt.Errorf("schedule new closure: %w", err)
 	}
-	if err := c.dfly.SAddEx(ctx, allowedTalkgroupsKey, dur, newTGID); err != nil {
-		return transcribe.ClosureMeta{}, time.Time{}, false, fmt.Errorf("SAddEx new allowed: %w", err)
+	if err := c.attachTACs(ctx, newMeta, dur); err != nil {
+		return transcribe.ClosureMeta{}, time.Time{}, false, err
 	}
-	if err := c.dfly.Set(ctx, fmt.Sprintf(tgRoutingKeyFmt, newTGID), dur, oldMeta.ThreadTS); err != nil {
-		return transcribe.ClosureMeta{}, time.Time{}, false, fmt.Errorf("set new routing: %w", err)
-	}
-
-	// Now tear down the old state.
-	if err := c.dfly.SRem(ctx, allowedTalkgroupsKey, oldTGID); err != nil {
-		return transcribe.ClosureMeta{}, time.Time{}, false, fmt.Errorf("SRem old allowed: %w", err)
-	}
-	if err := c.dfly.Del(ctx, fmt.Sprintf(tgRoutingKeyFmt, oldTGID)); err != nil {
-		return transcribe.ClosureMeta{}, time.Time{}, false, fmt.Errorf("del old routing: %w", err)
-	}
-	if _, err := c.dfly.ZRem(ctx, activeTACsKey, oldTGID); err != nil {
-		return transcribe.ClosureMeta{}, time.Time{}, false, fmt.Errorf("ZRem old closure: %w", err)
-	}
-	if err := c.dfly.Del(ctx,
-		fmt.Sprintf(tacMetaKeyFmt, oldTGID),
-		fmt.Sprintf(tacTranscriptsKeyFmt, oldTGID),
-		fmt.Sprintf(summaryTSKeyFmt, oldTGID),
-		fmt.Sprintf(summaryLockKeyFmt, oldTGID),
-		fmt.Sprintf(summaryStaleKeyFmt, oldTGID),
-		fmt.Sprintf(summaryDataKeyFmt, oldTGID),
-		fmt.Sprintf(pulpoUnitsKeyFmt, oldTGID),
-	); err != nil {
-		return transcribe.ClosureMeta{}, time.Time{}, false, fmt.Errorf("del old closure sidecars: %w", err)
+	if err := c.detachTACs(ctx, oldMeta.TGID); err != nil {
+		return transcribe.ClosureMeta{}, time.Time{}, false, err
 	}
 	return newMeta, newExpiry, true, nil
 }
 
-// scheduleClosure writes both the metadata key and the ZSET entry for the new TAC.
+// scheduleClosure writes both the metadata key and the ZSET entry for the incident.
 // Mirrors transcribe.TranscribeClient.ScheduleTACClosure but stays inside the slackctl
 // package so the controller doesn't need a TranscribeClient handle.
 func (c *Controller) scheduleClosure(ctx context.Context, meta transcribe.ClosureMeta, expiresAt time.Time) error {
-	payload, err := json.Marshal(meta)
-	if err != nil {
-		return fmt.Errorf("marshal new closure meta: %w", err)
-	}
-	if err := c.dfly.Set(ctx, fmt.Sprintf(tacMetaKeyFmt, meta.TGID), 24*time.Hour, string(payload)); err != nil {
+	if err := c.writeClosureMeta(ctx, meta); err != nil {
 		return err
 	}
-	return c.dfly.ZAdd(ctx, activeTACsKey, float64(expiresAt.Unix()), meta.TGID)
+	return c.dfly.ZAdd(ctx, activeTACsKey, float64(expiresAt.Unix()), meta.IncidentID)
 }
 
 // shortCodeForTGID resolves a TGID to its TAC1/TAC2/... short code via the canonical
@@ -130,20 +119,20 @@ func (c *Controller) shortCodeForTGID(tgid string) (string, error) {
 	return tg.RadioShortCode, nil
 }
 
-// parseOldTGIDFromBlockID extracts "1389" from "rescue_actions:1389". The block_id is
-// where the active TGID is stamped at render time; the select element's per-option value
-// carries the target TGID.
-func parseOldTGIDFromBlockID(blockID string) (string, bool) {
+// parseIncidentIDFromBlockID extracts the incident from "rescue_actions:<incident>". The
+// block_id is where the incident is stamped at render time; the select element's per-option
+// value carries the target TGID.
+func parseIncidentIDFromBlockID(blockID string) (string, bool) {
 	const prefix = "rescue_actions:"
 	if !strings.HasPrefix(blockID, prefix) {
 		return "", false
 	}
-	tgid := strings.TrimPrefix(blockID, prefix)
-	return tgid, tgid != ""
+	incidentID := strings.TrimPrefix(blockID, prefix)
+	return incidentID, incidentID != ""
 }
 
 func (c *Controller) handleSwitchTAC(ctx context.Context, payload slack.InteractionCallback, action *slack.BlockAction) {
-	oldTGID, ok := parseOldTGIDFromBlockID(action.BlockID)
+	incidentID, ok := parseIncidentIDFromBlockID(action.BlockID)
 	if !ok {
 		slog.Warn("slackctl: switch_tac action has unparseable block_id", slog.String("block_id", action.BlockID))
 		c.postEphemeral(payload, ":warning: Switch failed (malformed action). Check service logs.")
@@ -155,16 +144,29 @@ func (c *Controller) handleSwitchTAC(ctx context.Context, payload slack.Interact
 		c.postEphemeral(payload, ":warning: No new TAC selected.")
 		return
 	}
+	newChannel, _ := c.shortCodeForTGID(newTGID)
+	if newChannel == "" {
+		newChannel = newTGID
+	}
 
-	newMeta, newExpiry, ok2, err := c.SwitchTAC(ctx, oldTGID, newTGID)
+	// Read the current primary first, purely for the "switched from" wording.
+	var oldChannel string
+	if prev, found, err := c.readClosureMeta(ctx, incidentID); err == nil && found {
+		oldChannel = prev.TACChannel
+	}
+
+	newMeta, newExpiry, ok2, err := c.SwitchTAC(ctx, incidentID, newTGID)
 	switch {
 	case errors.Is(err, ErrSwitchSameTAC):
-		c.postEphemeral(payload, fmt.Sprintf(":information_source: Already monitoring %s — no change.", newMeta.TACChannel))
+		c.postEphemeral(payload, fmt.Sprintf(":information_source: Already monitoring %s — no change.", newChannel))
+		return
+	case errors.Is(err, ErrTACInUse):
+		c.postEphemeral(payload, fmt.Sprintf(":information_source: %s is already being monitored for another active rescue.", newChannel))
 		return
 	case err != nil:
 		slog.Error("slackctl: switch state mutation failed",
 			slog.String("error", err.Error()),
-			slog.String("old_tgid", oldTGID),
+			slog.String("incident", incidentID),
 			slog.String("new_tgid", newTGID),
 			slog.String("user", payload.User.ID))
 		c.postEphemeral(payload, ":warning: Switch failed; check service logs.")
@@ -174,15 +176,10 @@ func (c *Controller) handleSwitchTAC(ctx context.Context, payload slack.Interact
 		return
 	}
 
-	oldChannel, _ := c.shortCodeForTGID(oldTGID)
-	if oldChannel == "" {
-		oldChannel = oldTGID
-	}
-
 	slog.Info("slackctl: switched TAC monitoring",
 		slog.String("user", payload.User.ID),
 		slog.String("user_name", payload.User.Name),
-		slog.String("old_tgid", oldTGID),
+		slog.String("incident", incidentID),
 		slog.String("new_tgid", newTGID),
 		slog.String("old_tac", oldChannel),
 		slog.String("new_tac", newMeta.TACChannel))
@@ -198,7 +195,7 @@ func (c *Controller) handleSwitchTAC(ctx context.Context, payload slack.Interact
 		slog.Error("slackctl: failed to post switch thread reply", slog.String("error", err.Error()))
 	}
 
-	// Note: we deliberately don't chat.update the original alert — rebuilding its blocks
-	// would need the original transcription text which we don't persist in metadata. The
-	// thread reply is the canonical record of the correction.
+	// The incident ID on the alert's controls is unchanged by a switch, so the buttons keep
+	// working; re-render so the alert names the new TAC and shows the new expiry.
+	c.rerenderAlert(ctx, payload, newMeta)
 }
@@ -0,0 +1,260 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/slack-go/slack"
+)
+
+// After-action report: with AFTER_ACTION_REPORT_ENABLED, closing a rescue runs one more LLM
+// pass over the whole incident — the dispatch, every TAC transmission and the last live
+// summary — with a dedicated prompt (prompts.AfterActionSystemPrompt) and records the result.
+//
+// It hangs off the sweeper, so it covers both the auto-close and the alert's Close button
+// (which only moves the closure into the past; see slackctl.CloseTAC). Cancel (false alarm)
+// deletes the transcripts and never reaches the sweeper, so it gets no report — which is the
+// point of Cancel.
+//
+// The pass needs tac_transcripts and summary_data, and those are gone once the sweeper's
+// cleanup runs, so sweepOnce snapshots both first (two reads) and hands the snapshot to a
+// background goroutine that does the slow part: the model call, the S3 export and the Slack
+// post. The sweeper loop never waits on the model — a backend stall would otherwise hold every
+// later closure, warning and escalation for up to AFTER_ACTION_REPORT_TIMEOUT each. At most
+// afterActionMaxInFlight reports run at once; the rest queue for a slot with their snapshot in
+// hand. Sweep waits for them on shutdown, where the cancelled context cuts them short.
+//
+// Outputs, each best-effort and independent:
+//   - a formatted "After-Action Report" reply in the rescue thread;
+//   - <prefix><YYYY>/<MM>/<incident>.md and .json in the recordings bucket, when S3 is wired.
+
+// IncidentReport is the exported after-action record: the model's report plus the facts the
+// service knows for certain. It is the JSON document written to S3.
+type IncidentReport struct {
+	IncidentID            string               `json:"incident_id"`
+	TACChannels           []string             `json:"tac_channels"`
+	DispatchTranscription string               `json:"dispatch_transcription"`
+	DispatchedAt          time.Time            `json:"dispatched_at"` // zero for rescues scheduled before the field existed
+	ClosedAt              time.Time            `json:"closed_at"`
+	Report                ml.AfterActionReport `json:"report"`
+}
+
+// afterActionMaxInFlight caps the reports generating at once. Closures come in bursts (a
+// sweeper tick after an outage, a dispatcher closing several rescues) and each holds a model
+// call for up to AFTER_ACTION_REPORT_TIMEOUT.
+const afterActionMaxInFlight = 4
+
+// publishAfterActionReport snapshots what the report needs for a rescue the sweeper has just
+// closed and starts generating it in the background. It returns once the snapshot is taken,
+// so the caller can delete the sidecars straight away. Never fails the closure: every error is
+// logged and the sweeper carries on.
+func (tc *TranscribeClient) publishAfterActionReport(ctx context.Context, meta ClosureMeta, closedAt time.Time) {
+	if !tc.config.AfterActionReportEnabled {
+		return
+	}
+	transcripts, err := tc.readTranscripts(ctx, fmt.Sprintf(tacTranscriptsKeyFmt, meta.IncidentID))
+	if err != nil {
+		slog.Warn("after-action: failed to read transcripts; no report", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		return
+	}
+	if len(transcripts) == 0 {
+		// Nothing was said on the TAC; the dispatch alone doesn't make a report worth reading.
+		slog.Debug("after-action: no TAC traffic; skipping report", slog.String("incident", meta.IncidentID))
+		return
+	}
+
+	channels := append([]string{meta.TACChannel}, meta.AdditionalTACChannels()...)
+	in := ml.AfterActionInput{
+		DispatchTranscription: meta.Transcription,
+		TACChannel:            strings.Join(channels, ", "),
+		ClosedAt:              closedAt.Local().Format("15:04:05"),
+		TACTranscripts:        transcripts,
+	}
+	if !meta.DispatchedAt.IsZero() {
+		in.DispatchedAt = meta.DispatchedAt.Local().Format("15:04:05")
+		in.Duration = formatReportDuration(closedAt.Sub(meta.DispatchedAt))
+	}
+	in.LatestSummary, _ = tc.readSummaryData(ctx, meta.IncidentID)
+
+	tc.afterActionReports.Add(1)
+	go func() {
+		defer tc.afterActionReports.Done()
+		select {
+		case tc.afterActionSlots <- struct{}{}:
+			defer func() { <-tc.afterActionSlots }()
+		case <-ctx.Done():
+			slog.Warn("after-action: shutdown before report started", slog.String("incident", meta.IncidentID))
+			return
+		}
+		tc.generateAfterActionReport(ctx, meta, channels, in, closedAt)
+	}()
+}
+
+// generateAfterActionReport runs the model over a snapshot taken by publishAfterActionReport,
+// then exports and posts the result. It touches no incident state in Dragonfly, which the
+// sweeper has usually deleted by the time it runs.
+func (tc *TranscribeClient) generateAfterActionReport(ctx context.Context, meta ClosureMeta, channels []string, in ml.AfterActionInput, closedAt time.Time) {
+	genCtx, cancel := context.WithTimeout(ml.ContextWithIncident(ctx, meta.IncidentID), tc.config.AfterActionReportTimeout)
+	report, err := tc.mlClient.GenerateAfterActionReport(genCtx, in)
+	cancel()
+	if err != nil {
+		if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+			slog.Warn("after-action: shutdown interrupted report", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+			return
+		}
+		slog.Error("after-action: GenerateAfterActionReport failed", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		return
+	}
+	// The service knows the duration exactly; don't let the model round or re-derive it.
+	if in.Duration != "" {
+		report.Duration = in.Duration
+	}
+
+	full := IncidentReport{
+		IncidentID:            meta.IncidentID,
+		TACChannels:           channels,
+		DispatchTranscription: meta.Transcription,
+		DispatchedAt:          meta.DispatchedAt,
+		ClosedAt:              closedAt,
+		Report:                *report,
+	}
+	exported := tc.exportAfterActionReport(ctx, full)
+
+	if meta.ThreadTS == "" {
+		return
+	}
+	fallback := "After-action report"
+	if report.Headline != "" {
+		fallback += ": " + report.Headline
+	}
+	if err := tc.deliverSlack(ctx, outboundSlackMessage{
+		Kind:      slackOpPost,
+		Blocks:    slack.Blocks{BlockSet: BuildAfterActionReportBlocks(full, exported)},
+		Text:      fallback,
+		AsUser:    true,
+		ThreadTS:  meta.ThreadTS,
+		Talkgroup: meta.SourceTalkgroup,
+	}); err != nil {
+		slog.Error("after-action: failed to post report", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		return
+	}
+	slog.Info("after-action: posted report",
+		slog.String("incident", meta.IncidentID),
+		slog.String("headline", report.Headline),
+		slog.Int("timeline_events", len(report.Timeline)),
+		slog.Any("exported", exported))
+}
+
+// exportAfterActionReport writes the Markdown and JSON renderings to S3 and returns the keys
+// that were written. A nil S3 client (tests, or S3 not configured) exports nothing.
+func (tc *TranscribeClient) exportAfterActionReport(ctx context.Context, r IncidentReport) []string {
+	if tc.s3Client == nil {
+		return nil
+	}
+	encoded, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		slog.Error("after-action: failed to encode report", slog.String("error", err.Error()), slog.String("incident", r.IncidentID))
+		return nil
+	}
+	base := afterActionReportKey(tc.config.AfterActionReportS3Prefix, r.IncidentID, r.ClosedAt)
+	objects := []struct {
+		key, contentType string
+		body             []byte
+	}{
+		{base + ".md", "text/markdown; charset=utf-8", []byte(RenderAfterActionMarkdown(r))},
+		{base + ".json", "application/json", encoded},
+	}
+
+	var written []string
+	for _, o := range objects {
+		if err := tc.s3Client.PutObject(ctx, o.key, bytes.NewReader(o.body), int64(len(o.body)), o.contentType); err != nil {
+			slog.Error("after-action: failed to export report", slog.String("error", err.Error()), slog.String("key", o.key))
+			continue
+		}
+		written = append(written, o.key)
+	}
+	return written
+}
+
+// afterActionReportKey is the extension-less object key for a report. Year/month directories
+// keep a bucket listing browsable after a few seasons of rescues.
+func afterActionReportKey(prefix, incidentID string, closedAt time.Time) string {
+	return path.Join(prefix, closedAt.UTC().Format("2006/01"), incidentID)
+}
+
+// formatReportDuration renders a dispatch-to-close duration the way people say it: "45m",
+// "1h12m". Rounded to the minute; anything under a minute reads as "1m".
+func formatReportDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		d = time.Minute
+	}
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	if h == 0 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", h, m)
+}
+
+// RenderAfterActionMarkdown renders the exported Markdown document. It is meant to be read
+// (and pasted into run reviews) as-is, so every section is present even when empty.
+func RenderAfterActionMarkdown(r IncidentReport) string {
+	rep := r.Report
+	var b strings.Builder
+	fmt.Fprintf(&b, "# After-Action Report — %s\n\n", strings.Join(r.TACChannels, ", "))
+	if rep.Headline != "" {
+		fmt.Fprintf(&b, "**%s**\n\n", rep.Headline)
+	}
+
+	fmt.Fprintf(&b, "- **Incident:** %s\n", r.IncidentID)
+	if !r.DispatchedAt.IsZero() {
+		fmt.Fprintf(&b, "- **Dispatched:** %s\n", r.DispatchedAt.Local().Format("2006-01-02 15:04 MST"))
+	}
+	fmt.Fprintf(&b, "- **Closed:** %s\n", r.ClosedAt.Local().Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(&b, "- **Duration:** %s\n", emptyAsNone(rep.Duration))
+	fmt.Fprintf(&b, "- **Units:** %s\n", emptyAsNone(strings.Join(rep.Units, ", ")))
+	fmt.Fprintf(&b, "- **Patient outcome:** %s\n", emptyAsNone(rep.PatientOutcome))
+	fmt.Fprintf(&b, "- **SAR involvement:** %s\n", emptyAsNone(rep.SARInvolvement))
+
+	b.WriteString("\n## Timeline\n\n")
+	if len(rep.Timeline) == 0 {
+		b.WriteString("None recorded.\n")
+	}
+	for _, e := range rep.Timeline {
+		if e.CapturedAt != "" {
+			fmt.Fprintf(&b, "- `%s` — %s\n", e.CapturedAt, e.Description)
+			continue
+		}
+		fmt.Fprintf(&b, "- %s\n", e.Description)
+	}
+
+	b.WriteString("\n## Radio issues\n\n")
+	if len(rep.RadioIssues) == 0 {
+		b.WriteString("None noted.\n")
+	}
+	for _, issue := range rep.RadioIssues {
+		fmt.Fprintf(&b, "- %s\n", issue)
+	}
+
+	if r.DispatchTranscription != "" {
+		b.WriteString("\n## Dispatch\n\n> ")
+		b.WriteString(strings.ReplaceAll(r.DispatchTranscription, "\n", "\n> "))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func emptyAsNone(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "None"
+	}
+	return s
+}
@@ -0,0 +1,49 @@
+package transcribe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatReportDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		20 * time.Second:                "1m",
+		45*time.Minute + 10*time.Second: "45m",
+		72 * time.Minute:                "1h12m",
+		3*time.Hour + 5*time.Minute:     "3h05m",
+	} {
+		assert.Equal(t, want, formatReportDuration(d), d.String())
+	}
+}
+
+func TestAfterActionReportKey(t *testing.T) {
+	closedAt := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	assert.Equal(t, "reports/2026/03/01J9ZC8Q3V7N4X2K5M6P8R0T1W", afterActionReportKey("reports/", "01J9ZC8Q3V7N4X2K5M6P8R0T1W", closedAt))
+	assert.Equal(t, "aar/2026/03/1967", afterActionReportKey("aar", "1967", closedAt), "a prefix without a trailing slash still gets one")
+}
+
+func TestRenderAfterActionMarkdown(t *testing.T) {
+	md := RenderAfterActionMarkdown(IncidentReport{
+		IncidentID:            "01J9ZC8Q3V7N4X2K5M6P8R0T1W",
+		TACChannels:           []string{"TAC3", "TAC8"},
+		DispatchTranscription: "Rescue trail, Rattlesnake Ledge, TAC3",
+		ClosedAt:              time.Date(2026, 3, 1, 14, 15, 0, 0, time.Local),
+		Report: ml.AfterActionReport{
+			Headline:    "Injured hiker carried out",
+			Timeline:    []ml.RescueSummaryEvent{{CapturedAt: "13:05:00", Description: "Engine 171 on scene"}},
+			Units:       []string{"Engine 171"},
+			Duration:    "1h10m",
+			RadioIssues: []string{"13:20 — Medic 104 unreadable near the ledge"},
+		},
+	})
+	assert.Contains(t, md, "# After-Action Report — TAC3, TAC8")
+	assert.Contains(t, md, "- **Duration:** 1h10m")
+	assert.Contains(t, md, "- **Patient outcome:** None", "empty fields still render")
+	assert.Contains(t, md, "- `13:05:00` — Engine 171 on scene")
+	assert.Contains(t, md, "- 13:20 — Medic 104 unreadable near the ledge")
+	assert.Contains(t, md, "> Rescue trail, Rattlesnake Ledge, TAC3")
+	assert.NotContains(t, md, "Dispatched:", "unknown dispatch time is omitted")
+}
@@ -0,0 +1,167 @@
+package transcribe
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/cad"
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
+	"github.com/slack-go/slack"
+)
+
+// CAD poller: with CAD_POLL_ENABLED, a loop lists the agency's active CAD incidents every
+// CAD_POLL_INTERVAL and looks for rescues (CAD_POLL_CALL_TYPES) the radio path never picked up,
+// because the dispatch was garbled, missed by the ASR, or misread by the parser. An incident
+// still unmatched CAD_POLL_GRACE after CAD received it gets a "CAD shows a rescue, no radio
+// dispatch detected yet" alert.
+//
+// "Matched" is decided on the radio side: when a dispatch's unit-context warm-up matches a CAD
+// incident confidently, linkCADIncident records it. If the CAD alert went out first, the radio
+// rescue is linked in that alert's thread instead. The poller can't open a full rescue itself:
+// CAD doesn't say which TAC the incident is on, and the whole rescue lifecycle hangs off the TAC.
+//
+// Every replica runs the loop; SETNX on the alert key picks the one that posts. Incidents CAD
+// received more than cadPollLookback ago are ignored, so a replica starting mid-shift doesn't
+// alert on everything already in progress. Both keys are keyed by CAD incident, not rescue, and
+// simply expire.
+//
+//   STRING cad_radio:<cad incident> : rescue incident ID, TTL cadKeyTTL
+//   STRING cad_alert:<cad incident> : ts of the CAD alert ("pending" while posting), TTL cadKeyTTL
+
+const (
+	cadRadioKeyFmt  = "cad_radio:%s"
+	cadAlertKeyFmt  = "cad_alert:%s"
+	cadAlertPending = "pending"
+	cadKeyTTL       = 12 * time.Hour
+	cadPollLookback = 30 * time.Minute
+)
+
+// CADFeed lists active CAD incidents for the poller. Implemented by *cad.Resolver, which is also
+// the UnitResolver, so the poller needs PULPO_ENABLED.
+type CADFeed interface {
+	ActiveIncidents(ctx context.Context, callTypes []string) ([]cad.Incident, error)
+}
+
+// RunCADPoller is the long-running CAD poll loop. Returns immediately unless CAD_POLL_ENABLED
+// and the unit resolver can list incidents.
+func (tc *TranscribeClient) RunCADPoller(ctx context.Context) {
+	if !tc.config.CADPollEnabled {
+		return
+	}
+	feed, ok := tc.unitResolver.(CADFeed)
+	if !ok {
+		// main requires PULPO_ENABLED, so this only trips in a miswired test.
+		slog.Error("cad poller: no CAD feed; poller disabled")
+		return
+	}
+	ticker := time.NewTicker(tc.config.CADPollInterval)
+	defer ticker.Stop()
+	slog.Info("cad poller started", slog.Duration("interval", tc.config.CADPollInterval), slog.Any("call_types", tc.config.CADPollCallTypes))
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("cad poller stopping")
+			return
+		case <-ticker.C:
+			tc.pollCADOnce(ctx, feed, time.Now())
+		}
+	}
+}
+
+// pollCADOnce alerts on every due, unmatched CAD rescue no replica has alerted on yet.
+func (tc *TranscribeClient) pollCADOnce(ctx context.Context, feed CADFeed, now time.Time) {
+	incidents, err := feed.ActiveIncidents(ctx, tc.config.CADPollCallTypes)
+	if err != nil {
+		slog.Warn("cad poller: failed to list incidents", slog.String("error", err.Error()))
+		return
+	}
+	for _, inc := range incidents {
+		if !cadAlertDue(inc, now, tc.config.CADPollGrace) {
+			continue
+		}
+		if rescue, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(cadRadioKeyFmt, inc.ID)); err == nil && rescue != "" {
+			continue
+		}
+		tc.alertCADIncident(ctx, inc)
+	}
+}
+
+// cadAlertDue reports whether inc has waited out the grace period for a radio dispatch without
+// being too old to alert on. Incidents without a parseable received time are skipped: there is
+// no way to tell a fresh one from one that started hours ago.
+func cadAlertDue(inc cad.Incident, now time.Time, grace time.Duration) bool {
+	if inc.ReceivedAt.IsZero() {
+		return false
+	}
+	age := now.Sub(inc.ReceivedAt)
+	return age >= grace && age <= grace+cadPollLookback
+}
+
+// alertCADIncident claims inc and posts its alert, releasing the claim on failure so the next
+// poll retries.
+func (tc *TranscribeClient) alertCADIncident(ctx context.Context, inc cad.Incident) {
+	key := fmt.Sprintf(cadAlertKeyFmt, inc.ID)
+	claimed, err := tc.dragonflyClient.SetNX(ctx, key, cadKeyTTL, cadAlertPending)
+	if err != nil {
+		slog.Warn("cad poller: failed to claim; will retry", slog.String("error", err.Error()), slog.String("cad_incident", inc.ID))
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	ts, err := tc.sendSlackWithRetry(ctx, "",
+		slack.MsgOptionBlocks(BuildCADRescueBlocks(inc, tc.cadMapLink(inc))...),
+		slack.MsgOptionText(fmt.Sprintf("CAD shows a rescue with no radio dispatch detected: %s", inc.CallType), false),
+		slack.MsgOptionAsUser(true))
+	if err != nil {
+		slog.Error("cad poller: failed to post alert; will retry", slog.String("error", err.Error()), slog.String("cad_incident", inc.ID))
+		if err := tc.dragonflyClient.Del(ctx, key); err != nil {
+			slog.Warn("cad poller: failed to release claim", slog.String("error", err.Error()))
+		}
+		return
+	}
+	if err := tc.dragonflyClient.Set(ctx, key, cadKeyTTL, ts); err != nil {
+		slog.Warn("cad poller: failed to record alert ts; a later radio dispatch won't be linked", slog.String("error", err.Error()))
+	}
+	slog.Info("cad poller: alerted on unmatched CAD rescue", slog.String("cad_incident", inc.ID), slog.String("call_type", inc.CallType))
+}
+
+// cadMapLink links the CAD incident's own coordinates when map links are on.
+func (tc *TranscribeClient) cadMapLink(inc cad.Incident) *MapLink {
+	if !tc.config.MapLinksEnabled || !inc.Located {
+		return nil
+	}
+	return &MapLink{
+		Label: "CAD location",
+		URL:   gazetteer.MapURL(tc.config.MapLinkProvider, gazetteer.Point{Lat: inc.Lat, Lon: inc.Lon}),
+	}
+}
+
+// linkCADIncident records that the radio rescue incidentID is CAD incident uc.IncidentID, so the
+// poller doesn't alert on it, and links the rescue in the CAD alert's thread when the poller
+// already posted one. Only confident matches count; the roster fallback names no incident.
+// Best-effort: failures are logged.
+func (tc *TranscribeClient) linkCADIncident(ctx context.Context, incidentID, tacChannel string, uc cad.UnitContext, at time.Time) {
+	if !tc.config.CADPollEnabled || !uc.Matched || uc.IncidentID == "" {
+		return
+	}
+	if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(cadRadioKeyFmt, uc.IncidentID), cadKeyTTL, incidentID); err != nil {
+		slog.Warn("cad poller: failed to record radio match", slog.String("error", err.Error()), slog.String("cad_incident", uc.IncidentID))
+	}
+	alertTS, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(cadAlertKeyFmt, uc.IncidentID))
+	if err != nil || alertTS == "" || alertTS == cadAlertPending {
+		return
+	}
+	if err := tc.deliverSlack(ctx, outboundSlackMessage{
+		Kind:     slackOpPost,
+		Blocks:   slack.Blocks{BlockSet: BuildCADLinkedBlocks(tacChannel, at)},
+		Text:     fmt.Sprintf("Radio dispatch received on %s", tacChannel),
+		AsUser:   true,
+		ThreadTS: alertTS,
+	}); err != nil {
+		slog.Warn("cad poller: failed to link radio dispatch in CAD alert thread", slog.String("error", err.Error()), slog.String("cad_incident", uc.IncidentID))
+	}
+}
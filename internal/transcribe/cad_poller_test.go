@@ -0,0 +1,39 @@
+package transcribe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/cad"
+	"github.com/searchandrescuegg/transcribe/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCADAlertDue(t *testing.T) {
+	now := time.Date(2026, 7, 14, 14, 0, 0, 0, time.UTC)
+	grace := 3 * time.Minute
+	at := func(ago time.Duration) cad.Incident {
+		return cad.Incident{ID: "x", ReceivedAt: now.Add(-ago)}
+	}
+
+	assert.False(t, cadAlertDue(at(time.Minute), now, grace), "still inside the grace period")
+	assert.True(t, cadAlertDue(at(grace), now, grace))
+	assert.True(t, cadAlertDue(at(20*time.Minute), now, grace))
+	assert.False(t, cadAlertDue(at(grace+cadPollLookback+time.Second), now, grace), "too old; assumed already handled")
+	assert.False(t, cadAlertDue(cad.Incident{ID: "x"}, now, grace), "no received time")
+}
+
+func TestCADMapLink(t *testing.T) {
+	inc := cad.Incident{ID: "x", Lat: 47.488, Lon: -121.723, Located: true}
+
+	off := &TranscribeClient{config: &config.Config{}}
+	assert.Nil(t, off.cadMapLink(inc))
+
+	on := &TranscribeClient{config: &config.Config{MapLinksEnabled: true, MapLinkProvider: "osm"}}
+	link := on.cadMapLink(inc)
+	if assert.NotNil(t, link) {
+		assert.Equal(t, "CAD location", link.Label)
+		assert.Contains(t, link.URL, "mlat=47.48800")
+	}
+	assert.Nil(t, on.cadMapLink(cad.Incident{ID: "y"}), "no coordinates")
+}
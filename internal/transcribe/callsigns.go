@@ -0,0 +1,28 @@
+package transcribe
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// normalizeCallsigns runs the deterministic callsign pass (CALLSIGN_NORMALIZER_ENABLED) over a TAC
+// transmission, against the rescue's CAD roster when enrichment is on. It runs ahead of the LLM
+// cleanup, so with TAC_CLEANUP_ENABLED=false it is the only correction the thread reply gets.
+// Every rewrite is logged so a wrong one can be traced back to its transmission.
+func (tc *TranscribeClient) normalizeCallsigns(ctx context.Context, incidentID, text string) string {
+	if tc.callsigns == nil {
+		return text
+	}
+	var dispatchText string
+	if meta, ok := tc.readClosureMeta(ctx, incidentID); ok {
+		dispatchText = meta.Transcription
+	}
+	units := tc.unitContextFor(ctx, incidentID, dispatchText, time.Now())
+
+	normalized, subs := tc.callsigns.Normalize(text, units.Units)
+	for _, sub := range subs {
+		slog.Info("callsign normalized", slog.String("from", sub.From), slog.String("to", sub.To), slog.String("incident", incidentID))
+	}
+	return normalized
+}
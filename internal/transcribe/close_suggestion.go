@@ -0,0 +1,124 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/slack-go/slack"
+)
+
+// Outcome close prompt: the summarizer already reports the call's disposition in
+// RescueSummary.Outcome. With OUTCOME_CLOSE_ENABLED, the first summary whose Outcome is terminal
+// posts a "Looks resolved — close monitoring now?" reply with Close and Keep buttons, and pulls
+// the incident's active_tacs score in to now + OUTCOME_CLOSE_GRACE. Nothing else is needed for
+// the timeout: if nobody answers, the sweeper claims the closure like any other auto-close
+// (postChannelClosed, feedback button, sidecar cleanup). Close is the alert's own Close action;
+// Keep (internal/slackctl) puts the previous expiry back.
+//
+// One prompt per incident, ever: after Keep, a later "Resolved" summary doesn't ask again.
+//
+// While the prompt is unanswered, TAC_IDLE_TIMEOUT leaves the grace close alone: the radio
+// chatter that follows a resolution ("clear", "returning") would otherwise push the close back
+// out to now + idle and break the prompt's promise. A re-page leaves it alone too
+// (handleAdditionalDispatch). Keep overwrites the stashed expiry with closeSuggestionKept, which
+// hands the schedule back to the idle policy.
+//
+//   STRING close_suggested:<incident> : unix expiry before the prompt pulled it in (for Keep),
+//                                       or closeSuggestionKept once Keep has been pressed
+
+// closeSuggestedKeyFmt marks an incident as prompted. Mirrored in internal/slackctl.
+const closeSuggestedKeyFmt = "close_suggested:%s"
+
+// closeSuggestionKept is the close_suggested value Keep leaves behind. Mirrored in
+// internal/slackctl.
+const closeSuggestionKept = "kept"
+
+// closePromptPending reports whether an incident has an unanswered close prompt, i.e. a grace
+// close that traffic must not undo. A read error counts as pending so the caller leaves the
+// schedule as it is.
+func (tc *TranscribeClient) closePromptPending(ctx context.Context, incidentID string) bool {
+	raw, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(closeSuggestedKeyFmt, incidentID))
+	if err != nil {
+		slog.Warn("close prompt: failed to read prompt state", slog.String("error", err.Error()), slog.String("incident", incidentID))
+		return true
+	}
+	return raw != "" && raw != closeSuggestionKept
+}
+
+// terminalOutcomePrefixes are the Outcome phrasings the summarizer prompt asks for when the
+// incident itself is over. "Ongoing" and anything unrecognized are not terminal.
+var terminalOutcomePrefixes = []string{"resolved", "cancelled", "canceled", "false alarm"}
+
+// terminalOutcome reports whether a summary Outcome says the incident is over.
+func terminalOutcome(outcome string) bool {
+	o := strings.ToLower(strings.TrimSpace(outcome))
+	for _, p := range terminalOutcomePrefixes {
+		if strings.HasPrefix(o, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// maybeSuggestClose posts the close prompt the first time summary reports a terminal outcome.
+// Best-effort: on any failure the rescue keeps its existing schedule.
+func (tc *TranscribeClient) maybeSuggestClose(ctx context.Context, meta ClosureMeta, summary *ml.RescueSummary) {
+	if !tc.config.OutcomeCloseEnabled || summary == nil || !terminalOutcome(summary.Outcome) || meta.ThreadTS == "" {
+		return
+	}
+	score, err := tc.dragonflyClient.ZScore(ctx, activeTACsKey, meta.IncidentID)
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("close prompt: failed to read expiry", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		}
+		return // already closing or closed
+	}
+
+	// Claim before posting so concurrent summary passes (and replicas) prompt at most once.
+	key := fmt.Sprintf(closeSuggestedKeyFmt, meta.IncidentID)
+	claimed, err := tc.dragonflyClient.SetNX(ctx, key, closureMetaTTL, strconv.FormatInt(int64(score), 10))
+	if err != nil {
+		slog.Warn("close prompt: failed to claim; skipping", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	closesAt := time.Now().Add(tc.config.OutcomeCloseGrace)
+	if current := time.Unix(int64(score), 0); current.Before(closesAt) {
+		closesAt = current
+	} else if _, err := tc.dragonflyClient.ZLower(ctx, activeTACsKey, float64(closesAt.Unix()), meta.IncidentID); err != nil {
+		// Without the pulled-in close the prompt would promise a close that won't happen.
+		slog.Error("close prompt: failed to schedule grace close; not prompting",
+			slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		if err := tc.dragonflyClient.Del(ctx, key); err != nil {
+			slog.Warn("close prompt: failed to release claim", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		}
+		return
+	}
+
+	if err := tc.deliverSlack(ctx, outboundSlackMessage{
+		Kind:      slackOpPost,
+		Blocks:    slack.Blocks{BlockSet: BuildCloseSuggestionBlocks(meta.TACChannel, summary.Outcome, meta.IncidentID, closesAt)},
+		Text:      fmt.Sprintf("Looks resolved — close %s monitoring now?", meta.TACChannel),
+		AsUser:    true,
+		ThreadTS:  meta.ThreadTS,
+		Talkgroup: meta.SourceTalkgroup,
+	}); err != nil {
+		// The grace close stands: the summary said the incident is over, and the closure
+		// message will still post. Only the chance to Keep is lost.
+		slog.Error("close prompt: failed to post; monitoring still closes after the grace period",
+			slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		return
+	}
+	slog.Info("close prompt: posted",
+		slog.String("incident", meta.IncidentID), slog.String("outcome", summary.Outcome), slog.Time("closes_at", closesAt))
+}
@@ -0,0 +1,22 @@
+package transcribe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerminalOutcome(t *testing.T) {
+	for outcome, want := range map[string]bool{
+		"Resolved — patient transported": true,
+		"resolved — handled on scene":    true,
+		"Cancelled en route":             true,
+		"Canceled en route":              true,
+		"False alarm":                    true,
+		"Ongoing":                        false,
+		"":                               false,
+		"Patient resolved to walk out":   false,
+	} {
+		assert.Equal(t, want, terminalOutcome(outcome), outcome)
+	}
+}
@@ -0,0 +1,172 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/slack-go/slack"
+)
+
+// Closure policy: by default a rescue auto-closes exactly one activation window after the
+// dispatch or the last Extend, whether the channel is busy or has been silent for most of it.
+// Three independent, opt-in knobs make that follow the radio instead:
+//
+//   - TAC_IDLE_TIMEOUT: the close follows the traffic. The dispatch's activation window is the
+//     first window (units are often quiet while they respond); from the first transmission on,
+//     each one reschedules the close to now + timeout, earlier or later, so a TAC that goes
+//     quiet closes one timeout after its last transmission. A re-page, Extend or Keep is an
+//     explicit ask for a window and is held: traffic doesn't pull the close in before it
+//     (ClosureMeta.HeldUntil). The write is ZADD XX, so it never re-adds a closure the sweeper
+//     or Cancel already claimed, and routing is re-armed to match. An unanswered outcome close
+//     prompt (close_suggestion.go) pauses it.
+//   - TAC_MAX_LIFETIME: no schedule runs past dispatch + lifetime — not the initial window,
+//     traffic, re-pages, Extend or Keep. Works with or without the idle timeout.
+//   - TAC_CLOSING_WARNING: the sweeper posts a "closing soon" thread reply this long before the
+//     close, once per scheduled close time.
+//
+//   STRING closing_warned:<incident>:<unix expiry> : SetNX dedup for the warning reply
+
+const closingWarnedKeyFmt = "closing_warned:%s:%d"
+
+// CapToLifetime returns expiresAt, or dispatch + maxLifetime when that is sooner. Rescues
+// without a dispatch time (scheduled before the field existed) and a zero maxLifetime are
+// uncapped. Exported for the Slack controller's Extend and Keep.
+func (m ClosureMeta) CapToLifetime(expiresAt time.Time, maxLifetime time.Duration) time.Time {
+	if maxLifetime <= 0 || m.DispatchedAt.IsZero() {
+		return expiresAt
+	}
+	if limit := m.DispatchedAt.Add(maxLifetime); expiresAt.After(limit) {
+		return limit
+	}
+	return expiresAt
+}
+
+// idleExpiry returns the close time a transmission at now earns under the idle policy: now +
+// idle, no earlier than the held window, capped by the lifetime. ok=false when that is the
+// close already scheduled.
+func idleExpiry(current, now time.Time, meta ClosureMeta, idle, maxLifetime time.Duration) (expiresAt time.Time, ok bool) {
+	expiresAt = now.Add(idle)
+	if meta.HeldUntil.After(expiresAt) {
+		expiresAt = meta.HeldUntil
+	}
+	expiresAt = meta.CapToLifetime(expiresAt, maxLifetime)
+	if expiresAt.Unix() == current.Unix() {
+		return current, false
+	}
+	return expiresAt, true
+}
+
+// trafficCanExtend reports whether a transmission could still push a close at expiresAt
+// further out, for the warning's wording.
+func (tc *TranscribeClient) trafficCanExtend(meta ClosureMeta, expiresAt time.Time) bool {
+	if tc.config.TACIdleTimeout <= 0 {
+		return false
+	}
+	if tc.config.TACMaxLifetime <= 0 || meta.DispatchedAt.IsZero() {
+		return true
+	}
+	return expiresAt.Before(meta.DispatchedAt.Add(tc.config.TACMaxLifetime))
+}
+
+// extendOnActivity applies TAC_IDLE_TIMEOUT after a transmission on an incident's TAC.
+// Best-effort: a failure leaves the existing schedule in place, which is the pre-policy
+// behaviour.
+func (tc *TranscribeClient) extendOnActivity(ctx context.Context, incidentID string) {
+	if tc.config.TACIdleTimeout <= 0 {
+		return
+	}
+	score, err := tc.dragonflyClient.ZScore(ctx, activeTACsKey, incidentID)
+	if err != nil {
+		// redis.Nil: no pending closure (already closing, cancelled, or no rescue at all).
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("closure policy: failed to read expiry", slog.String("error", err.Error()), slog.String("incident", incidentID))
+		}
+		return
+	}
+	if tc.closePromptPending(ctx, incidentID) {
+		slog.Debug("closure policy: close prompt pending; traffic leaves the grace close alone", slog.String("incident", incidentID))
+		return
+	}
+	meta, ok := tc.readClosureMeta(ctx, incidentID)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	current := time.Unix(int64(score), 0)
+	if !current.After(now) {
+		return // due: the sweeper (or a Close) has it
+	}
+	expiresAt, ok := idleExpiry(current, now, meta, tc.config.TACIdleTimeout, tc.config.TACMaxLifetime)
+	if !ok {
+		return
+	}
+	moved, err := tc.dragonflyClient.ZUpdate(ctx, activeTACsKey, float64(expiresAt.Unix()), incidentID)
+	if err != nil {
+		slog.Warn("closure policy: failed to reschedule closure", slog.String("error", err.Error()), slog.String("incident", incidentID))
+		return
+	}
+	if !moved {
+		return
+	}
+	// Routing must outlive the new close time or the TAC's traffic would be dropped before the
+	// sweeper gets to it.
+	tc.refreshIncidentRouting(ctx, meta, expiresAt.Sub(now))
+	slog.Debug("closure policy: traffic rescheduled closure",
+		slog.String("incident", incidentID), slog.Time("expires_at", expiresAt), slog.Time("was", current))
+}
+
+// warnClosingSoon posts the TAC_CLOSING_WARNING reply for every rescue whose close falls within
+// the warning window. The dedup key includes the close time, so a rescue pushed out by traffic
+// or an Extend is warned again before its new close.
+func (tc *TranscribeClient) warnClosingSoon(ctx context.Context) {
+	now := time.Now()
+	incidentIDs, err := tc.dragonflyClient.ZRangeByScore(ctx, activeTACsKey,
+		"("+strconv.FormatInt(now.Unix(), 10), strconv.FormatInt(now.Add(tc.config.TACClosingWarning).Unix(), 10))
+	if err != nil {
+		slog.Error("closure policy: failed to query closing rescues", slog.String("error", err.Error()))
+		return
+	}
+	for _, incidentID := range incidentIDs {
+		score, err := tc.dragonflyClient.ZScore(ctx, activeTACsKey, incidentID)
+		if err != nil {
+			continue // claimed or cancelled since the range query
+		}
+		expiresAt := time.Unix(int64(score), 0)
+		key := fmt.Sprintf(closingWarnedKeyFmt, incidentID, expiresAt.Unix())
+		claimed, err := tc.dragonflyClient.SetNX(ctx, key, tc.config.TACClosingWarning+time.Minute, now.Unix())
+		if err != nil {
+			slog.Warn("closure policy: failed to claim warning; skipping this round",
+				slog.String("error", err.Error()), slog.String("incident", incidentID))
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		meta, ok := tc.readClosureMeta(ctx, incidentID)
+		if !ok || meta.ThreadTS == "" {
+			continue
+		}
+		if err := tc.deliverSlack(ctx, outboundSlackMessage{
+			Kind:      slackOpPost,
+			Blocks:    slack.Blocks{BlockSet: BuildClosingWarningBlocks(meta.TACChannel, expiresAt, tc.trafficCanExtend(meta, expiresAt))},
+			Text:      fmt.Sprintf("%s monitoring closes soon.", meta.TACChannel),
+			AsUser:    true,
+			ThreadTS:  meta.ThreadTS,
+			Talkgroup: meta.SourceTalkgroup,
+		}); err != nil {
+			slog.Error("closure policy: failed to post closing warning; will retry next tick",
+				slog.String("error", err.Error()), slog.String("incident", incidentID))
+			if err := tc.dragonflyClient.Del(ctx, key); err != nil {
+				slog.Warn("closure policy: failed to release warning claim", slog.String("error", err.Error()), slog.String("key", key))
+			}
+			continue
+		}
+		slog.Info("closure policy: posted closing warning",
+			slog.String("incident", incidentID), slog.String("tac", meta.TACChannel), slog.Time("closes_at", expiresAt))
+	}
+}
@@ -0,0 +1,44 @@
+package transcribe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdleExpiry(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	dispatched := now.Add(-50 * time.Minute)
+	cases := map[string]struct {
+		current     time.Time
+		meta        ClosureMeta
+		maxLifetime time.Duration
+		want        time.Time
+		wantOK      bool
+	}{
+		"pushes out":               {current: now.Add(5 * time.Minute), meta: ClosureMeta{DispatchedAt: dispatched}, want: now.Add(20 * time.Minute), wantOK: true},
+		"pulls a quiet close in":   {current: now.Add(28 * time.Minute), meta: ClosureMeta{DispatchedAt: dispatched}, want: now.Add(20 * time.Minute), wantOK: true},
+		"held window stands":       {current: now.Add(28 * time.Minute), meta: ClosureMeta{DispatchedAt: dispatched, HeldUntil: now.Add(28 * time.Minute)}, want: now.Add(28 * time.Minute)},
+		"past the hold":            {current: now.Add(5 * time.Minute), meta: ClosureMeta{DispatchedAt: dispatched, HeldUntil: now.Add(5 * time.Minute)}, want: now.Add(20 * time.Minute), wantOK: true},
+		"capped by lifetime":       {current: now.Add(5 * time.Minute), meta: ClosureMeta{DispatchedAt: dispatched}, maxLifetime: time.Hour, want: now.Add(10 * time.Minute), wantOK: true},
+		"cap already reached":      {current: now.Add(10 * time.Minute), meta: ClosureMeta{DispatchedAt: dispatched}, maxLifetime: time.Hour, want: now.Add(10 * time.Minute)},
+		"no dispatch time, no cap": {current: now.Add(5 * time.Minute), maxLifetime: time.Hour, want: now.Add(20 * time.Minute), wantOK: true},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, ok := idleExpiry(c.current, now, c.meta, 20*time.Minute, c.maxLifetime)
+			assert.Equal(t, c.wantOK, ok)
+			assert.Equal(t, c.want.Unix(), got.Unix())
+		})
+	}
+}
+
+func TestCapToLifetime(t *testing.T) {
+	dispatched := time.Unix(1_700_000_000, 0)
+	meta := ClosureMeta{DispatchedAt: dispatched}
+	assert.Equal(t, dispatched.Add(time.Hour), meta.CapToLifetime(dispatched.Add(90*time.Minute), time.Hour))
+	assert.Equal(t, dispatched.Add(30*time.Minute), meta.CapToLifetime(dispatched.Add(30*time.Minute), time.Hour))
+	assert.Equal(t, dispatched.Add(90*time.Minute), meta.CapToLifetime(dispatched.Add(90*time.Minute), 0), "zero lifetime is uncapped")
+	assert.Equal(t, dispatched.Add(90*time.Minute), ClosureMeta{}.CapToLifetime(dispatched.Add(90*time.Minute), time.Hour), "legacy rescues are uncapped")
+}
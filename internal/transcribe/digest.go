@@ -0,0 +1,233 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// Shift digest: with DIGEST_ENABLED, a loop checks every digestCheckInterval whether a
+// DIGEST_TIMES boundary has passed and, if so, posts what closed between the previous boundary
+// and this one (see incident_record.go for where the rows come from). With the default single
+// "07:00" that is a morning summary of the last 24 hours; "07:00,19:00" gives one per shift.
+//
+// Every replica runs the loop. The first to SETNX digest_sent:<boundary> posts; the others see
+// the key and skip. A failed post releases the key so the next check retries. Boundaries more
+// than digestCatchUp in the past are not posted, so a replica starting mid-afternoon doesn't
+// send the morning digest hours late.
+//
+//   STRING digest_sent:<unix boundary> : claim, TTL DIGEST_RETENTION
+
+const (
+	digestSentKeyFmt    = "digest_sent:%d"
+	digestCheckInterval = 30 * time.Second
+	digestCatchUp       = time.Hour
+)
+
+// clockTime is a wall-clock time of day from DIGEST_TIMES.
+type clockTime struct{ hour, minute int }
+
+// ValidateDigestTimes reports whether DIGEST_TIMES parses, so main can reject a bad value at
+// startup rather than leaving the digest silently disabled.
+func ValidateDigestTimes(raw []string) error {
+	_, err := parseDigestTimes(raw)
+	return err
+}
+
+// parseDigestTimes parses DIGEST_TIMES ("07:00", "07:00,19:00") into sorted, deduplicated
+// times of day.
+func parseDigestTimes(raw []string) ([]clockTime, error) {
+	seen := make(map[clockTime]bool)
+	var times []clockTime
+	for _, s := range raw {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse("15:04", s)
+		if err != nil {
+			return nil, fmt.Errorf("digest time %q: want HH:MM", s)
+		}
+		c := clockTime{t.Hour(), t.Minute()}
+		if !seen[c] {
+			seen[c] = true
+			times = append(times, c)
+		}
+	}
+	if len(times) == 0 {
+		return nil, fmt.Errorf("no digest times configured")
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].hour*60+times[i].minute < times[j].hour*60+times[j].minute
+	})
+	return times, nil
+}
+
+// digestWindow returns the most recent boundary at or before now (end) and the one before it
+// (start). Boundaries are built with time.Date in now's location, so a DST change moves the
+// window's length, not the wall-clock time the digest posts at.
+func digestWindow(now time.Time, times []clockTime) (start, end time.Time) {
+	var boundaries []time.Time
+	for back := 2; back >= 0; back-- {
+		day := now.AddDate(0, 0, -back)
+		for _, c := range times {
+			b := time.Date(day.Year(), day.Month(), day.Day(), c.hour, c.minute, 0, 0, now.Location())
+			if !b.After(now) {
+				boundaries = append(boundaries, b)
+			}
+		}
+	}
+	// Three days of at least one time each always leaves two boundaries at or before now.
+	return boundaries[len(boundaries)-2], boundaries[len(boundaries)-1]
+}
+
+// RunDigest is the long-running digest loop. Returns immediately unless DIGEST_ENABLED.
+func (tc *TranscribeClient) RunDigest(ctx context.Context) {
+	if !tc.config.DigestEnabled {
+		return
+	}
+	times, err := parseDigestTimes(tc.config.DigestTimes)
+	if err != nil {
+		// main validates DIGEST_TIMES, so this only trips in a miswired test.
+		slog.Error("digest: invalid DIGEST_TIMES; digest disabled", slog.String("error", err.Error()))
+		return
+	}
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+	slog.Info("shift digest started", slog.Any("times", tc.config.DigestTimes))
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("shift digest stopping")
+			return
+		case <-ticker.C:
+			tc.digestOnce(ctx, time.Now(), times)
+		}
+	}
+}
+
+// digestOnce posts the digest for the latest boundary if it is due and no replica has yet.
+func (tc *TranscribeClient) digestOnce(ctx context.Context, now time.Time, times []clockTime) {
+	start, end := digestWindow(now, times)
+	if now.Sub(end) > digestCatchUp {
+		return
+	}
+	key := fmt.Sprintf(digestSentKeyFmt, end.Unix())
+	claimed, err := tc.dragonflyClient.SetNX(ctx, key, tc.config.DigestRetention, "1")
+	if err != nil {
+		slog.Warn("digest: failed to claim; will retry", slog.String("error", err.Error()))
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	if err := tc.postDigest(ctx, start, end); err != nil {
+		slog.Error("digest: failed to post; will retry", slog.String("error", err.Error()), slog.Time("window_end", end))
+		if err := tc.dragonflyClient.Del(ctx, key); err != nil {
+			slog.Warn("digest: failed to release claim", slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	// Age the index along with the records it points at.
+	cutoff := strconv.FormatInt(now.Add(-tc.config.DigestRetention).Unix(), 10)
+	if _, err := tc.dragonflyClient.ZRemRangeByScore(ctx, closedIncidentsKey, "-inf", cutoff); err != nil {
+		slog.Warn("digest: failed to prune closed_incidents", slog.String("error", err.Error()))
+	}
+}
+
+// postDigest posts the digest message for (start, end] and, when anything closed, the CSV in
+// its thread. Only the message is required; a failed CSV upload is logged.
+func (tc *TranscribeClient) postDigest(ctx context.Context, start, end time.Time) error {
+	records, err := tc.readClosedIncidents(ctx, start, end)
+	if err != nil {
+		return err
+	}
+	channel := tc.config.DigestChannelID
+	if channel == "" {
+		channel = tc.config.SlackChannelID
+	}
+
+	ts, err := tc.retrySlack(ctx, "", func() (string, error) {
+		sendCtx, cancel := context.WithTimeout(ctx, tc.config.SlackTimeout)
+		defer cancel()
+		_, ts, _, err := tc.slackClient.SendMessageContext(sendCtx, channel,
+			slack.MsgOptionBlocks(BuildDigestBlocks(start, end, records)...),
+			slack.MsgOptionText(fmt.Sprintf("Shift digest: %d incidents closed", len(records)), false),
+			slack.MsgOptionAsUser(true))
+		return ts, err
+	})
+	if err != nil {
+		return fmt.Errorf("post digest: %w", err)
+	}
+	slog.Info("digest: posted", slog.Time("start", start), slog.Time("end", end), slog.Int("incidents", len(records)))
+	if len(records) == 0 {
+		return nil
+	}
+
+	payload, err := RenderDigestCSV(records)
+	if err != nil {
+		slog.Warn("digest: failed to render CSV", slog.String("error", err.Error()))
+		return nil
+	}
+	if _, err := tc.retrySlack(ctx, "", func() (string, error) {
+		uploadCtx, cancel := context.WithTimeout(ctx, tc.config.SlackTimeout)
+		defer cancel()
+		_, err := tc.slackClient.UploadFileV2Context(uploadCtx, slack.UploadFileV2Parameters{
+			Reader:          bytes.NewReader(payload),
+			FileSize:        len(payload),
+			Filename:        fmt.Sprintf("incidents-%s.csv", end.Format("2006-01-02-1504")),
+			Title:           "Closed incidents",
+			Channel:         channel,
+			ThreadTimestamp: ts,
+		})
+		return "", err
+	}); err != nil {
+		slog.Warn("digest: CSV upload failed; digest message still posted", slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// digestCSVHeader is the CSV column order; RenderDigestCSV writes one row per record in it.
+var digestCSVHeader = []string{"incident_id", "tac_channels", "dispatched_at", "closed_at", "duration_minutes", "headline", "outcome", "sar_notified", "false_alarm"}
+
+// RenderDigestCSV renders records as the CSV attached to the digest. Times are RFC 3339 in
+// local time so the file opens sensibly in a spreadsheet; unknown values are empty.
+func RenderDigestCSV(records []IncidentRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(digestCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		var dispatched, minutes string
+		if !r.DispatchedAt.IsZero() {
+			dispatched = r.DispatchedAt.Local().Format(time.RFC3339)
+			minutes = strconv.Itoa(int(r.Duration().Round(time.Minute).Minutes()))
+		}
+		if err := w.Write([]string{
+			r.IncidentID,
+			strings.Join(r.TACChannels, " "),
+			dispatched,
+			r.ClosedAt.Local().Format(time.RFC3339),
+			minutes,
+			r.Headline,
+			r.Outcome,
+			strconv.FormatBool(r.SARNotified),
+			strconv.FormatBool(r.FalseAlarm),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
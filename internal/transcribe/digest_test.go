@@ -0,0 +1,60 @@
+package transcribe
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDigestTimes(t *testing.T) {
+	times, err := parseDigestTimes([]string{"19:00", " 07:00", "07:00", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []clockTime{{7, 0}, {19, 0}}, times, "sorted and deduplicated")
+
+	_, err = parseDigestTimes([]string{"7am"})
+	assert.Error(t, err)
+	_, err = parseDigestTimes(nil)
+	assert.Error(t, err)
+}
+
+func TestDigestWindow(t *testing.T) {
+	loc := time.FixedZone("PST", -8*3600)
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 3, day, hour, minute, 0, 0, loc) }
+
+	start, end := digestWindow(at(10, 7, 20), []clockTime{{7, 0}})
+	assert.Equal(t, at(9, 7, 0), start, "one digest a day covers the previous 24 hours")
+	assert.Equal(t, at(10, 7, 0), end)
+
+	shifts := []clockTime{{7, 0}, {19, 0}}
+	start, end = digestWindow(at(10, 7, 0), shifts)
+	assert.Equal(t, at(9, 19, 0), start, "a boundary exactly at now counts as passed")
+	assert.Equal(t, at(10, 7, 0), end)
+
+	start, end = digestWindow(at(10, 3, 0), shifts)
+	assert.Equal(t, at(9, 7, 0), start)
+	assert.Equal(t, at(9, 19, 0), end)
+}
+
+func TestRenderDigestCSV(t *testing.T) {
+	closed := time.Date(2026, 3, 10, 14, 17, 0, 0, time.Local)
+	out, err := RenderDigestCSV([]IncidentRecord{
+		{IncidentID: "A", TACChannels: []string{"TAC3", "TAC8"}, DispatchedAt: closed.Add(-72 * time.Minute), ClosedAt: closed,
+			Headline: "Injured hiker, Mailbox Peak", Outcome: "Resolved — patient transported", SARNotified: true},
+		{IncidentID: "B", TACChannels: []string{"TAC1"}, ClosedAt: closed, FalseAlarm: true},
+	})
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(strings.NewReader(string(out))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, digestCSVHeader, rows[0])
+	assert.Equal(t, []string{"A", "TAC3 TAC8", closed.Add(-72 * time.Minute).Format(time.RFC3339), closed.Format(time.RFC3339), "72",
+		"Injured hiker, Mailbox Peak", "Resolved — patient transported", "true", "false"}, rows[1])
+	assert.Equal(t, "", rows[2][2], "unknown dispatch time is left empty")
+	assert.Equal(t, "", rows[2][4])
+	assert.Equal(t, "true", rows[2][8])
+}
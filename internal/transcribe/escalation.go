@@ -0,0 +1,83 @@
+package transcribe
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/escalation"
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+)
+
+// escalatedKeyFmt records that a rule has paged for an incident: escalated:<incident>:<rule>,
+// keyed by incident ID like every other sidecar. The ID is unique per rescue and survives a
+// Switch TAC, so moving a rescue to a new channel doesn't re-page everyone, and a later rescue on
+// the same TAC still gets its own pages. TTL matches tac_meta.
+const escalatedKeyFmt = "escalated:%s:%s"
+
+// evaluateEscalations pages for every rule that now holds for the rescue and hasn't already
+// paged for it. Called on each live-interpretation refresh and, for time-based rules, from the
+// sweeper. Best-effort: failures are logged and the dedup claim is released so the next
+// evaluation retries.
+func (tc *TranscribeClient) evaluateEscalations(ctx context.Context, meta ClosureMeta, summary *ml.RescueSummary) {
+	if tc.escalator == nil {
+		return
+	}
+	now := time.Now()
+	inc := escalation.Incident{
+		ID:           meta.IncidentID,
+		TACChannel:   meta.TACChannel,
+		DispatchedAt: meta.DispatchedAt,
+		Summary:      summary,
+	}
+	for _, rule := range tc.escalator.Due(inc, now) {
+		key := fmt.Sprintf(escalatedKeyFmt, meta.IncidentID, rule.Name)
+		// Claim before sending so concurrent workers (and replicas) page at most once.
+		claimed, err := tc.dragonflyClient.SetNX(ctx, key, closureMetaTTL, now.Unix())
+		if err != nil {
+			slog.Warn("escalation: failed to claim dedup key; skipping this round",
+				slog.String("error", err.Error()), slog.String("rule", rule.Name), slog.String("incident", meta.IncidentID))
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		page := escalation.BuildPage(rule, inc, now.Local(), buildOpenMHzURL([]string{meta.TGID, FireDispatch1TGID}))
+		sendCtx, cancel := context.WithTimeout(ctx, tc.config.EscalationTimeout)
+		err = tc.escalator.Send(sendCtx, page)
+		cancel()
+		if err != nil {
+			slog.Error("escalation: page failed; will retry on next evaluation",
+				slog.String("error", err.Error()), slog.String("rule", rule.Name), slog.String("incident", meta.IncidentID))
+			if err := tc.dragonflyClient.Del(ctx, key); err != nil {
+				slog.Warn("escalation: failed to release dedup key; rule won't retry for this rescue",
+					slog.String("error", err.Error()), slog.String("key", key))
+			}
+			continue
+		}
+		slog.Info("escalation: paged",
+			slog.String("rule", rule.Name), slog.String("incident", meta.IncidentID), slog.String("tac", meta.TACChannel))
+	}
+}
+
+// escalateActiveRescues evaluates every rescue that hasn't reached its closure time, so
+// duration rules fire on a quiet channel that isn't producing live-interpretation refreshes.
+func (tc *TranscribeClient) escalateActiveRescues(ctx context.Context) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	incidentIDs, err := tc.dragonflyClient.ZRangeByScore(ctx, activeTACsKey, "("+now, "+inf")
+	if err != nil {
+		slog.Warn("escalation: failed to list active rescues", slog.String("error", err.Error()))
+		return
+	}
+	for _, incidentID := range incidentIDs {
+		meta, ok := tc.readClosureMeta(ctx, incidentID)
+		if !ok {
+			continue
+		}
+		summary, _ := tc.readSummaryData(ctx, incidentID)
+		tc.evaluateEscalations(ctx, meta, summary)
+	}
+}
@@ -32,7 +32,7 @@ const (
 // feature isn't configured. Best-effort — bad config (malformed JSON, missing fields, etc.)
 // degrades gracefully to the bare form URL or empty string rather than blowing up the
 // closure path.
-func (tc *TranscribeClient) buildFeedbackURL(ctx context.Context, tgid string, meta ClosureMeta, closedAt time.Time) string {
+func (tc *TranscribeClient) buildFeedbackURL(ctx context.Context, incidentID string, meta ClosureMeta, closedAt time.Time) string {
 	if tc.config.FeedbackFormURL == "" {
 		return ""
 	}
@@ -47,10 +47,10 @@ func (tc *TranscribeClient) buildFeedbackURL(ctx context.Context, tgid string, m
 	// Pull the most recent structured summary (best-effort — empty if no TAC follow-ups
 	// fired, or if Dragonfly hiccupped). Missing summary fields just don't get prefilled.
 	var summary ml.RescueSummary
-	if raw, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(summaryDataKeyFmt, tgid)); err == nil && raw != "" {
+	if raw, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(summaryDataKeyFmt, incidentID)); err == nil && raw != "" {
 		if err := json.Unmarshal([]byte(raw), &summary); err != nil {
 			slog.Warn("feedback URL: summary_data unparseable; falling back to dispatch-only prefill",
-				slog.String("error", err.Error()), slog.String("tgid", tgid))
+				slog.String("error", err.Error()), slog.String("incident", incidentID))
 		}
 	}
 
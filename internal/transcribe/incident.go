@@ -0,0 +1,140 @@
+package transcribe
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/searchandrescuegg/transcribe/internal/dragonfly"
+)
+
+// Incident identity: every rescue gets a ULID when its alert posts, and every piece of
+// incident state is keyed by that ID rather than by the TAC it runs on:
+//
+//   STRING tac_meta:<incident>          : ClosureMeta (the incident record)
+//   ZSET   active_tacs                  : member = incident ID, score = unix expiry
+//   LIST   tac_transcripts:<incident>   , STRING summary_*:<incident>, pulpo_*:<incident>
+//   STRING escalated:<incident>:<rule>  : escalation dedup
+//
+// Talkgroup-scoped state stays keyed by TGID, because that's what an incoming transmission
+// carries:
+//
+//   SET    allowed_talkgroups   : member = TGID (per-member TTL)
+//   STRING tg:<TGID>            : thread_ts to reply into
+//   STRING tac_incident:<TGID>  : incident ID — the TGID→incident index
+//
+// Keying by ID means a TAC reused for a new rescue moments after the last one closed starts
+// from empty sidecars instead of inheriting stale transcripts or a stale summary, and Switch
+// TAC only re-points the index instead of copying every key to a new TGID.
+//
+// Migration: rescues scheduled before incident IDs existed have no tac_incident entry and no
+// IncidentID in their metadata. Their state is keyed by TGID, so the TGID *is* their incident
+// ID — incidentIDFor falls back to the TGID, and readClosureMeta stamps IncidentID with the key
+// the record was read from. Those rescues keep working untouched until they close (at most one
+// activation window plus extensions; tac_meta's 24h TTL bounds any stragglers).
+
+// tacIncidentKeyFmt is the TGID→incident index. Mirrored in internal/slackctl.
+const tacIncidentKeyFmt = "tac_incident:%s"
+
+// DeleteIncidentState deletes an incident's tac_meta and every sidecar keyed by its ID, including
+// the split candidates still pending on it. The sweeper's closure cleanup and slackctl's Cancel
+// both call it, so a new sidecar is cleared by both or by neither. Talkgroup-scoped keys and
+// active_tacs are the caller's: each path releases those in its own order.
+//
+// The candidate list is read first; if that read fails, the rest is still deleted and the error
+// returned (the candidates then lapse with their activation-window TTL).
+func DeleteIncidentState(ctx context.Context, dfly *dragonfly.DragonflyClient, incidentID string) error {
+	keys := []string{
+		fmt.Sprintf(tacMetaKeyFmt, incidentID),
+		fmt.Sprintf(tacTranscriptsKeyFmt, incidentID),
+		fmt.Sprintf(summaryTSKeyFmt, incidentID),
+		fmt.Sprintf(summaryLockKeyFmt, incidentID),
+		fmt.Sprintf(summaryStaleKeyFmt, incidentID),
+		fmt.Sprintf(summaryDataKeyFmt, incidentID),
+		fmt.Sprintf(summaryCompactionKeyFmt, incidentID),
+		fmt.Sprintf(pulpoUnitsKeyFmt, incidentID),
+		fmt.Sprintf(pulpoTimelineKeyFmt, incidentID),
+		fmt.Sprintf(pulpoTimelineLockKeyFmt, incidentID),
+		fmt.Sprintf(pulpoPinKeyFmt, incidentID),
+		fmt.Sprintf(closeSuggestedKeyFmt, incidentID),
+		fmt.Sprintf(splitCandidatesKeyFmt, incidentID),
+	}
+	candidates, listErr := dfly.LRange(ctx, fmt.Sprintf(splitCandidatesKeyFmt, incidentID), 0, -1)
+	for _, id := range candidates {
+		keys = append(keys, fmt.Sprintf(splitCandidateKeyFmt, id))
+	}
+	if err := dfly.Del(ctx, keys...); err != nil {
+		return fmt.Errorf("del incident state: %w", err)
+	}
+	if listErr != nil {
+		return fmt.Errorf("lrange split_candidates: %w", listErr)
+	}
+	return nil
+}
+
+// newIncidentID returns a fresh ULID. ULIDs sort by creation time, so incident IDs in logs,
+// webhooks and key listings read in dispatch order.
+func newIncidentID() string {
+	return ulid.Make().String()
+}
+
+// incidentIDFor resolves the incident a transmission heard on tgid belongs to. With no index
+// entry — a legacy rescue, or no active rescue at all — it falls back to tgid, which is both the
+// legacy key and a guaranteed miss on tac_meta for a TAC nobody is monitoring.
+func (tc *TranscribeClient) incidentIDFor(ctx context.Context, tgid string) string {
+	id, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(tacIncidentKeyFmt, tgid))
+	if err != nil {
+		slog.Warn("incident: failed to read TGID index; falling back to TGID",
+			slog.String("error", err.Error()), slog.String("tgid", tgid))
+		return tgid
+	}
+	if id == "" {
+		return tgid
+	}
+	return id
+}
+
+// refreshIncidentRouting re-arms allow-list membership, tg:<TGID> routing and the incident
+// index for every TAC on the incident with a fresh ttl. Best-effort per key; failures are
+// logged.
+func (tc *TranscribeClient) refreshIncidentRouting(ctx context.Context, meta ClosureMeta, ttl time.Duration) {
+	for _, tgid := range meta.TGIDs() {
+		if err := tc.dragonflyClient.SAddEx(ctx, "allowed_talkgroups", ttl, tgid); err != nil {
+			slog.Error("incident: failed to refresh allow-list", slog.String("error", err.Error()), slog.String("tgid", tgid))
+		}
+		if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(talkgroupKeyPrefix, tgid), ttl, meta.ThreadTS); err != nil {
+			slog.Error("incident: failed to refresh routing key", slog.String("error", err.Error()), slog.String("tgid", tgid))
+		}
+		if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(tacIncidentKeyFmt, tgid), ttl, meta.IncidentID); err != nil {
+			slog.Error("incident: failed to refresh TGID index", slog.String("error", err.Error()), slog.String("tgid", tgid))
+		}
+	}
+}
+
+// releaseIncidentRouting runs when an incident closes. It removes the index entries that still
+// point at this incident. It also removes allow-list membership and routing for the additional
+// TACs, since one added late in the rescue would otherwise outlive the closure. The primary's
+// allow-list entry and routing key lapse with their TTL as they always have.
+//
+// An index entry is only deleted while it still names this incident: in the few seconds between
+// the TTL lapsing and the sweeper tick, a fresh dispatch may already have claimed the TAC.
+func (tc *TranscribeClient) releaseIncidentRouting(ctx context.Context, meta ClosureMeta) {
+	for _, tgid := range meta.TGIDs() {
+		indexKey := fmt.Sprintf(tacIncidentKeyFmt, tgid)
+		if id, err := tc.dragonflyClient.Get(ctx, indexKey); err == nil && id == meta.IncidentID {
+			if err := tc.dragonflyClient.Del(ctx, indexKey); err != nil {
+				slog.Warn("incident: failed to delete TGID index", slog.String("error", err.Error()), slog.String("tgid", tgid))
+			}
+		}
+	}
+	for _, tgid := range meta.AdditionalTGIDs {
+		if err := tc.dragonflyClient.SRem(ctx, "allowed_talkgroups", tgid); err != nil {
+			slog.Warn("incident: failed to remove additional TAC from allow-list", slog.String("error", err.Error()), slog.String("tgid", tgid))
+		}
+		if err := tc.dragonflyClient.Del(ctx, fmt.Sprintf(talkgroupKeyPrefix, tgid)); err != nil {
+			slog.Warn("incident: failed to delete additional TAC routing", slog.String("error", err.Error()), slog.String("tgid", tgid))
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+)
+
+// Closed-incident records: the sweeper's cleanup deletes tac_meta and summary_data when a
+// rescue closes, so with DIGEST_ENABLED each closure first writes the few facts the shift
+// digest needs into a record that outlives it.
+//
+//   ZSET closed_incidents            : member = incident ID, score = unix close time
+//   STRING incident_record:<incident> : JSON IncidentRecord, TTL DIGEST_RETENTION
+//
+// Written by the sweeper (auto-close and Close) and by slackctl on Cancel, which marks the
+// record as a false alarm. The digest prunes index entries older than the retention window.
+
+const (
+	closedIncidentsKey   = "closed_incidents"
+	incidentRecordKeyFmt = "incident_record:%s"
+)
+
+// IncidentRecord is what the digest knows about a closed incident. Exported so slackctl can
+// write the Cancel variant without duplicating the schema.
+type IncidentRecord struct {
+	IncidentID   string    `json:"incident_id"`
+	TACChannels  []string  `json:"tac_channels"`
+	DispatchedAt time.Time `json:"dispatched_at"` // zero for rescues scheduled before the field existed
+	ClosedAt     time.Time `json:"closed_at"`
+	Headline     string    `json:"headline,omitempty"` // from the last live summary; empty if none ran
+	Outcome      string    `json:"outcome,omitempty"`
+	SARNotified  bool      `json:"sar_notified"`
+	// FalseAlarm is set when leadership pressed Cancel rather than the rescue closing normally.
+	FalseAlarm bool `json:"false_alarm"`
+}
+
+// NewIncidentRecord builds the record for a closing incident. summary may be nil.
+func NewIncidentRecord(meta ClosureMeta, summary *ml.RescueSummary, closedAt time.Time, falseAlarm bool) IncidentRecord {
+	r := IncidentRecord{
+		IncidentID:   meta.IncidentID,
+		TACChannels:  append([]string{meta.TACChannel}, meta.AdditionalTACChannels()...),
+		DispatchedAt: meta.DispatchedAt,
+		ClosedAt:     closedAt,
+		FalseAlarm:   falseAlarm,
+	}
+	if summary != nil {
+		r.Headline = summary.Headline
+		r.Outcome = summary.Outcome
+		r.SARNotified = summary.SARNotified
+	}
+	return r
+}
+
+// Duration is dispatch-to-close, or zero when the dispatch time is unknown.
+func (r IncidentRecord) Duration() time.Duration {
+	if r.DispatchedAt.IsZero() {
+		return 0
+	}
+	return r.ClosedAt.Sub(r.DispatchedAt)
+}
+
+// recordClosedIncident keeps the digest's record of a rescue the sweeper is closing. Must run
+// before the sidecar cleanup (it reads summary_data). Best-effort: a failure only leaves the
+// incident out of the next digest.
+func (tc *TranscribeClient) recordClosedIncident(ctx context.Context, meta ClosureMeta, closedAt time.Time) {
+	if !tc.config.DigestEnabled {
+		return
+	}
+	summary, _ := tc.readSummaryData(ctx, meta.IncidentID)
+	record := NewIncidentRecord(meta, summary, closedAt, false)
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		slog.Warn("digest: failed to encode incident record", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		return
+	}
+	if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(incidentRecordKeyFmt, meta.IncidentID), tc.config.DigestRetention, string(encoded)); err != nil {
+		slog.Warn("digest: failed to write incident record", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		return
+	}
+	if err := tc.dragonflyClient.ZAdd(ctx, closedIncidentsKey, float64(closedAt.Unix()), meta.IncidentID); err != nil {
+		slog.Warn("digest: failed to index incident record", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+	}
+}
+
+// readClosedIncidents returns the records of incidents closed in (start, end], oldest first.
+// Index entries whose record has expired are skipped.
+func (tc *TranscribeClient) readClosedIncidents(ctx context.Context, start, end time.Time) ([]IncidentRecord, error) {
+	ids, err := tc.dragonflyClient.ZRangeByScore(ctx, closedIncidentsKey,
+		"("+strconv.FormatInt(start.Unix(), 10), strconv.FormatInt(end.Unix(), 10))
+	if err != nil {
+		return nil, fmt.Errorf("ZRangeByScore closed_incidents: %w", err)
+	}
+	records := make([]IncidentRecord, 0, len(ids))
+	for _, id := range ids {
+		raw, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(incidentRecordKeyFmt, id))
+		if err != nil {
+			return nil, fmt.Errorf("get incident record: %w", err)
+		}
+		if raw == "" {
+			continue
+		}
+		var r IncidentRecord
+		if err := json.Unmarshal([]byte(raw), &r); err != nil {
+			slog.Warn("digest: dropping unparseable incident record", slog.String("error", err.Error()), slog.String("incident", id))
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
@@ -0,0 +1,32 @@
+package transcribe
+
+import (
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIncidentID_IsUniqueULID(t *testing.T) {
+	a, b := newIncidentID(), newIncidentID()
+	_, err := ulid.ParseStrict(a)
+	require.NoError(t, err)
+	assert.Len(t, a, ulid.EncodedSize)
+	assert.NotEqual(t, a, b)
+}
+
+func TestDecodeClosureMeta_LegacyRecordTakesItsKey(t *testing.T) {
+	// Metadata written before incident IDs existed is keyed by TGID and has no incident_id.
+	meta, err := decodeClosureMeta("1389", `{"tgid":"1389","tac_channel":"TAC1"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "1389", meta.IncidentID)
+
+	meta, err = decodeClosureMeta("01J9ZC8Q3V7N4X2K5M6P8R0T1W", `{"incident_id":"01J9ZC8Q3V7N4X2K5M6P8R0T1W","tgid":"1963"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "01J9ZC8Q3V7N4X2K5M6P8R0T1W", meta.IncidentID)
+	assert.Equal(t, "1963", meta.TGID)
+
+	_, err = decodeClosureMeta("1389", "not json")
+	assert.Error(t, err)
+}
@@ -5,6 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,8 +19,10 @@ import (
 	"github.com/searchandrescuegg/transcribe/internal/asr"
 	"github.com/searchandrescuegg/transcribe/internal/config"
 	"github.com/searchandrescuegg/transcribe/internal/dragonfly"
+	"github.com/searchandrescuegg/transcribe/internal/escalation"
 	"github.com/searchandrescuegg/transcribe/internal/ml"
 	internalpulsar "github.com/searchandrescuegg/transcribe/internal/pulsar"
+	"github.com/searchandrescuegg/transcribe/internal/s3"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -48,6 +56,14 @@ func (m *mockSlackPoster) UpdateMessageContext(ctx context.Context, channelID, t
 	return args.String(0), args.String(1), args.String(2), args.Error(3)
 }
 
+func (m *mockSlackPoster) UploadFileV2Context(ctx context.Context, params slack.UploadFileV2Parameters) (*slack.FileSummary, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*slack.FileSummary), args.Error(1)
+}
+
 type mockMLClient struct {
 	mock.Mock
 }
@@ -76,6 +92,22 @@ func (m *mockMLClient) CleanTACTranscript(ctx context.Context, in ml.TACCleanupI
 	return args.Get(0).(*ml.TACCleanupResult), args.Error(1)
 }
 
+func (m *mockMLClient) CompareDispatches(ctx context.Context, in ml.DispatchComparisonInput) (*ml.DispatchComparison, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ml.DispatchComparison), args.Error(1)
+}
+
+func (m *mockMLClient) GenerateAfterActionReport(ctx context.Context, in ml.AfterActionInput) (*ml.AfterActionReport, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ml.AfterActionReport), args.Error(1)
+}
+
 // ============================================================================
 // Suite
 // ============================================================================
@@ -227,15 +259,51 @@ func (s *DispatchSuite) TestProcessDispatchCall_HappyPath_TrailRescue() {
 	s.Require().NoError(err)
 	s.Equal("ts-rescue-1", thread, "thread_ts must be persisted under tg:%s", tg.TGID)
 
-	// Sweeper ZSET should hold one pending closure.
+	// Sweeper ZSET should hold one pending closure, keyed by a freshly minted incident ID.
 	members, err := s.rdb.ZRange(s.ctx, activeTACsKey, 0, -1).Result()
 	s.Require().NoError(err)
-	s.Len(members, 1, "exactly one pending TAC closure scheduled")
+	s.Require().Len(members, 1, "exactly one pending TAC closure scheduled")
+	incidentID := members[0]
+	s.NotEqual(tg.TGID, incidentID, "new rescues are keyed by incident ID, not TGID")
+	s.Equal(incidentID, tc.incidentIDFor(s.ctx, tg.TGID), "tac_incident:<TGID> must point at the new incident")
+	meta, ok := tc.readClosureMeta(s.ctx, incidentID)
+	s.Require().True(ok)
+	s.Equal(incidentID, meta.IncidentID)
+	s.Equal(tg.TGID, meta.TGID)
 
 	slackMock.AssertExpectations(s.T())
 	mlMock.AssertExpectations(s.T())
 }
 
+// A TAC reused for a new rescue right after the last one closed must start from empty incident
+// state — the previous rescue's transcripts and summary stay with its own incident ID.
+func (s *DispatchSuite) TestProcessDispatchCall_ReusedTAC_GetsFreshIncident() {
+	slackMock := new(mockSlackPoster)
+	mlMock := new(mockMLClient)
+	tc := s.newClientUnderTest(slackMock, mlMock)
+
+	tg := talkgroupFromRadioShortCode["TAC1"]
+	const previous = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	// Leftovers from a rescue on TAC1 whose window lapsed but whose sidecars haven't been swept.
+	s.Require().NoError(s.rdb.RPush(s.ctx, fmt.Sprintf(tacTranscriptsKeyFmt, previous), `{"text":"old"}`).Err())
+	s.Require().NoError(s.rdb.Set(s.ctx, fmt.Sprintf(summaryDataKeyFmt, previous), `{"headline":"old"}`, time.Hour).Err())
+
+	mlMock.On("ParseRelevantInformationFromDispatchMessage", mock.Anything, "raw").Return(
+		dispatchMessages(ml.DispatchMessage{CallType: "Rescue - Trail", TACChannel: "TAC1", CleanedTranscription: "new rescue"}), nil)
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).
+		Return("C-TEST", "ts-rescue-2", "", nil).Once()
+
+	parsed := &AdornedDeconstructedKey{dk: &DeconstructedKey{Talkgroup: FireDispatch1TGID}}
+	s.Require().NoError(tc.processDispatchCall(s.ctx, parsed, stubASRResponse("raw")))
+
+	incidentID := tc.incidentIDFor(s.ctx, tg.TGID)
+	s.NotEqual(previous, incidentID)
+	s.NotEqual(tg.TGID, incidentID)
+	exists, err := s.rdb.Exists(s.ctx, fmt.Sprintf(tacTranscriptsKeyFmt, incidentID), fmt.Sprintf(summaryDataKeyFmt, incidentID)).Result()
+	s.Require().NoError(err)
+	s.EqualValues(0, exists, "the new incident must not inherit the previous rescue's state")
+}
+
 func (s *DispatchSuite) TestProcessDispatchCall_NoTrailRescue_IsNoOp() {
 	slackMock := new(mockSlackPoster)
 	mlMock := new(mockMLClient)
@@ -306,6 +374,83 @@ func (s *DispatchSuite) TestProcessDispatchCall_AdditionalTone_DedupsToExistingT
 	s.EqualValues(1, zcount)
 }
 
+// With split detection on, a re-page naming a different place still dedups onto the active
+// rescue, but the thread reply carries a Split prompt backed by a stored candidate.
+func (s *DispatchSuite) TestProcessDispatchCall_DistinctRepage_OffersSplit() {
+	slackMock := new(mockSlackPoster)
+	mlMock := new(mockMLClient)
+	tc := s.newClientUnderTest(slackMock, mlMock)
+	tc.config.SplitDetectionEnabled = true
+	tc.config.SplitDetectionMinOverlap = 0.34
+
+	tgid := talkgroupFromRadioShortCode["TAC1"].TGID
+	meta := ClosureMeta{TGID: tgid, TACChannel: "TAC1", ThreadTS: "orig-thread", SourceTalkgroup: FireDispatch1TGID, MessageTS: "orig-thread", Transcription: "Aid 181 respond Rescue Trail, Mount Si trailhead, TAC 1"}
+	payload, _ := json.Marshal(meta)
+	s.Require().NoError(tc.dragonflyClient.Set(s.ctx, fmt.Sprintf(tacMetaKeyFmt, tgid), 1*time.Hour, string(payload)))
+	s.Require().NoError(tc.dragonflyClient.Set(s.ctx, fmt.Sprintf(talkgroupKeyPrefix, tgid), 1*time.Hour, "orig-thread"))
+
+	mlMock.On("ParseRelevantInformationFromDispatchMessage", mock.Anything, "raw2").Return(
+		dispatchMessages(ml.DispatchMessage{CallType: "Rescue - Trail", TACChannel: "TAC1", CleanedTranscription: "Medic 104 respond Rescue Trail, Tiger Mountain Poo Poo Point, TAC 1"}), nil)
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).Return("C-TEST", "reply-ts", "", nil).Once()
+
+	parsed := &AdornedDeconstructedKey{dk: &DeconstructedKey{Talkgroup: FireDispatch1TGID, Time: time.Now()}}
+	s.Require().NoError(tc.processDispatchCall(s.ctx, parsed, stubASRResponse("raw2")))
+	slackMock.AssertExpectations(s.T())
+
+	keys, err := s.rdb.Keys(s.ctx, "split_candidate:*").Result()
+	s.Require().NoError(err)
+	s.Require().Len(keys, 1, "a distinct re-page must store exactly one split candidate")
+	raw, err := s.rdb.Get(s.ctx, keys[0]).Result()
+	s.Require().NoError(err)
+	var cand SplitCandidate
+	s.Require().NoError(json.Unmarshal([]byte(raw), &cand))
+	s.Equal(tgid, cand.ParentIncidentID, "legacy rescue: parent incident is the TGID")
+	s.Equal(tgid, cand.TGID)
+	s.Equal("raw2", cand.Transcription)
+	s.Equal("split_candidate:"+cand.IncidentID, keys[0])
+
+	// Routing still belongs to the original rescue until an operator splits.
+	thread, err := tc.dragonflyClient.Get(s.ctx, fmt.Sprintf(talkgroupKeyPrefix, tgid))
+	s.Require().NoError(err)
+	s.Equal("orig-thread", thread)
+}
+
+// A re-page works from the tac_meta read when it was recognised. A TAC added from Slack after
+// that read must survive the re-page's reschedule.
+func (s *DispatchSuite) TestAdditionalDispatch_KeepsTACAddedSinceMetaWasRead() {
+	slackMock := new(mockSlackPoster)
+	tc := s.newClientUnderTest(slackMock, new(mockMLClient))
+
+	primary := talkgroupFromRadioShortCode["TAC3"].TGID
+	extra := talkgroupFromRadioShortCode["TAC8"].TGID
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	stale := ClosureMeta{
+		IncidentID: incident, TGID: primary, TACChannel: "TAC3", ThreadTS: "ts-rescue", SourceTalkgroup: FireDispatch1TGID,
+		MessageTS: "ts-rescue", Transcription: "Rescue Trail TAC 3 Mount Si",
+	}
+	// Add TAC lands between the re-page's read and its reschedule.
+	added := stale
+	added.AdditionalTGIDs = []string{extra}
+	payload, _ := json.Marshal(added)
+	s.Require().NoError(tc.dragonflyClient.Set(s.ctx, fmt.Sprintf(tacMetaKeyFmt, incident), 1*time.Hour, string(payload)))
+	s.Require().NoError(tc.dragonflyClient.ZAdd(s.ctx, activeTACsKey, float64(time.Now().Add(5*time.Minute).Unix()), incident))
+
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).Return("C-TEST", "reply-ts", "", nil).Once()
+	parsed := &AdornedDeconstructedKey{dk: &DeconstructedKey{Talkgroup: FireDispatch1TGID, Time: time.Now()}}
+	s.Require().NoError(tc.handleAdditionalDispatch(s.ctx, parsed, stubASRResponse("additional unit"), stale,
+		talkgroupFromTGID[primary], ml.DispatchMessage{CallType: "Rescue - Trail", TACChannel: "TAC3"}))
+	slackMock.AssertExpectations(s.T())
+
+	after, ok := tc.readClosureMeta(s.ctx, incident)
+	s.Require().True(ok)
+	s.Equal([]string{extra}, after.AdditionalTGIDs, "the re-page must not write back its stale copy")
+	s.WithinDuration(time.Now().Add(30*time.Minute), after.HeldUntil, 5*time.Second)
+	// The added TAC's routing is refreshed along with the primary's.
+	ttl, err := s.rdb.TTL(s.ctx, fmt.Sprintf(tacIncidentKeyFmt, extra)).Result()
+	s.Require().NoError(err)
+	s.Greater(ttl, 29*time.Minute)
+}
+
 func (s *DispatchSuite) TestProcessDispatchCall_UnknownTACChannel_ReturnsError() {
 	slackMock := new(mockSlackPoster)
 	mlMock := new(mockMLClient)
@@ -488,6 +633,31 @@ func (s *DispatchSuite) TestSweep_PicksUpDueClosure() {
 	s.EqualValues(0, exists, "tac_meta:<TGID> must be deleted alongside the ZSET entry")
 }
 
+// A split candidate is keyed by its own ID; closing its parent must still find and delete it.
+func (s *DispatchSuite) TestSweep_DeletesPendingSplitCandidates() {
+	slackMock := new(mockSlackPoster)
+	tc := s.newClientUnderTest(slackMock, new(mockMLClient))
+
+	tgid := talkgroupFromRadioShortCode["TAC1"].TGID
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	s.scheduleClosureFixture(incident, time.Now().Add(-1*time.Second).Unix(), ClosureMeta{
+		IncidentID: incident, TGID: tgid, TACChannel: "TAC1", ThreadTS: "ts-1", SourceTalkgroup: FireDispatch1TGID,
+	})
+	candidate, err := tc.saveSplitCandidate(s.ctx, SplitCandidate{ParentIncidentID: incident, TGID: tgid, TACChannel: "TAC1"})
+	s.Require().NoError(err)
+
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).
+		Return("C-TEST", "ts-closed", "", nil).Once()
+	tc.sweepOnce(s.ctx)
+	slackMock.AssertExpectations(s.T())
+
+	for _, key := range []string{fmt.Sprintf(splitCandidateKeyFmt, candidate), fmt.Sprintf(splitCandidatesKeyFmt, incident)} {
+		exists, err := s.rdb.Exists(s.ctx, key).Result()
+		s.Require().NoError(err)
+		s.EqualValues(0, exists, key)
+	}
+}
+
 // FIX (feedback URL prefill): the sweeper's sidecar cleanup must run AFTER postChannelClosed,
 // not before — the feedback-URL builder reads summary_data:<TGID>, and an early Del would
 // silently strip the headline + situation_summary prefill from the Google Form URL. This
@@ -626,7 +796,7 @@ func (s *DispatchSuite) TestLiveInterpretation_FirstTAC_PostsAndCachesSummaryTS(
 	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).
 		Return("C-TEST", "ts-summary-1", "", nil).Once()
 
-	tc.updateLiveInterpretation(s.ctx, tgid, time.Now(), "first transmission")
+	tc.updateLiveInterpretation(s.ctx, tgid, "TAC10", time.Now(), "first transmission")
 
 	mlMock.AssertExpectations(s.T())
 	slackMock.AssertExpectations(s.T())
@@ -671,7 +841,7 @@ func (s *DispatchSuite) TestLiveInterpretation_SubsequentTAC_ChatUpdatesExisting
 	slackMock.On("UpdateMessageContext", mock.Anything, "C-TEST", "ts-summary-1", mock.Anything).
 		Return("C-TEST", "ts-summary-1", "", nil).Once()
 
-	tc.updateLiveInterpretation(s.ctx, tgid, time.Now(), "follow-up")
+	tc.updateLiveInterpretation(s.ctx, tgid, "TAC10", time.Now(), "follow-up")
 
 	slackMock.AssertNotCalled(s.T(), "SendMessageContext", mock.Anything, mock.Anything, mock.Anything)
 	mlMock.AssertExpectations(s.T())
@@ -721,7 +891,7 @@ func (s *DispatchSuite) TestLiveInterpretation_ConcurrentBurst_BoundedToTwoLLMCa
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
-		tc.updateLiveInterpretation(s.ctx, tgid, time.Now(), "transmission 1")
+		tc.updateLiveInterpretation(s.ctx, tgid, "TAC10", time.Now(), "transmission 1")
 	}()
 
 	// Wait for the lock to actually be held before firing the burst (otherwise we're racing
@@ -732,9 +902,9 @@ func (s *DispatchSuite) TestLiveInterpretation_ConcurrentBurst_BoundedToTwoLLMCa
 	}, 2*time.Second, 20*time.Millisecond, "expected first worker to hold the summary lock")
 
 	// Burst: 3 more transmissions. Each should RPush and bail out without an LLM call.
-	tc.updateLiveInterpretation(s.ctx, tgid, time.Now(), "transmission 2")
-	tc.updateLiveInterpretation(s.ctx, tgid, time.Now(), "transmission 3")
-	tc.updateLiveInterpretation(s.ctx, tgid, time.Now(), "transmission 4")
+	tc.updateLiveInterpretation(s.ctx, tgid, "TAC10", time.Now(), "transmission 2")
+	tc.updateLiveInterpretation(s.ctx, tgid, "TAC10", time.Now(), "transmission 3")
+	tc.updateLiveInterpretation(s.ctx, tgid, "TAC10", time.Now(), "transmission 4")
 
 	// Stale flag must now be set — proves losers correctly signaled the lock holder.
 	stale, err := s.rdb.Get(s.ctx, fmt.Sprintf(summaryStaleKeyFmt, tgid)).Result()
@@ -797,8 +967,8 @@ func (s *DispatchSuite) TestLiveInterpretation_Additive_PassesPreviousSummary()
 	slackMock.On("UpdateMessageContext", mock.Anything, "C-TEST", "ts-summary-1", mock.Anything).
 		Return("C-TEST", "ts-summary-1", "", nil).Once()
 
-	tc.updateLiveInterpretation(s.ctx, tgid, time.Now(), "on scene")
-	tc.updateLiveInterpretation(s.ctx, tgid, time.Now(), "requesting SAR")
+	tc.updateLiveInterpretation(s.ctx, tgid, "TAC10", time.Now(), "on scene")
+	tc.updateLiveInterpretation(s.ctx, tgid, "TAC10", time.Now(), "requesting SAR")
 
 	mlMock.AssertExpectations(s.T())
 	slackMock.AssertExpectations(s.T())
@@ -850,6 +1020,46 @@ func (s *DispatchSuite) TestProcessNonDispatchCall_CleansBeforePostingAndStoring
 	s.NotContains(entries[0], "norwell", "raw ASR must not be stored")
 }
 
+// A transmission on an additional TAC of a multi-TAC rescue replies into the rescue thread and
+// feeds the incident's transcript list, attributed to the channel it was heard on.
+func (s *DispatchSuite) TestProcessNonDispatchCall_AdditionalTAC_FeedsPrimaryIncident() {
+	slackMock := new(mockSlackPoster)
+	mlMock := new(mockMLClient)
+	tc := s.newClientUnderTest(slackMock, mlMock)
+
+	primary := talkgroupFromRadioShortCode["TAC3"].TGID
+	extra := talkgroupFromRadioShortCode["TAC8"].TGID
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	meta := ClosureMeta{
+		IncidentID: incident, TGID: primary, TACChannel: "TAC3", ThreadTS: "ts-rescue", SourceTalkgroup: FireDispatch1TGID,
+		MessageTS: "ts-rescue", Transcription: "Rescue Trail TAC 3 Mount Si", AdditionalTGIDs: []string{extra},
+	}
+	payload, _ := json.Marshal(meta)
+	s.Require().NoError(tc.dragonflyClient.Set(s.ctx, fmt.Sprintf(tacMetaKeyFmt, incident), 1*time.Hour, string(payload)))
+	tc.refreshIncidentRouting(s.ctx, meta, 30*time.Minute)
+
+	mlMock.On("SummarizeRescue", mock.Anything, mock.MatchedBy(func(in ml.RescueSummaryInput) bool {
+		return in.TACChannel == "TAC3, TAC8" &&
+			len(in.TACTranscripts) == 1 &&
+			in.TACTranscripts[0].Channel == "TAC8"
+	})).Return(&ml.RescueSummary{Headline: "h"}, nil).Once()
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).
+		Return("C-TEST", "ts-x", "", nil).Twice()
+
+	parsed := &AdornedDeconstructedKey{dk: &DeconstructedKey{Talkgroup: extra, Time: time.Now()}}
+	s.Require().NoError(tc.processNonDispatchCall(s.ctx, parsed, stubASRResponse("SAR team at the trailhead")))
+
+	mlMock.AssertExpectations(s.T())
+	slackMock.AssertExpectations(s.T())
+
+	count, err := s.rdb.LLen(s.ctx, fmt.Sprintf(tacTranscriptsKeyFmt, incident)).Result()
+	s.Require().NoError(err)
+	s.EqualValues(1, count, "the transmission must land in the incident's transcript list")
+	exists, err := s.rdb.Exists(s.ctx, fmt.Sprintf(tacTranscriptsKeyFmt, primary), fmt.Sprintf(tacTranscriptsKeyFmt, extra)).Result()
+	s.Require().NoError(err)
+	s.EqualValues(0, exists, "no incident state may be created under either TGID")
+}
+
 func (s *DispatchSuite) TestLiveInterpretation_NoMeta_IsNoOp() {
 	slackMock := new(mockSlackPoster)
 	mlMock := new(mockMLClient)
@@ -857,7 +1067,7 @@ func (s *DispatchSuite) TestLiveInterpretation_NoMeta_IsNoOp() {
 
 	// No tac_meta: rescue isn't active. We still RPush the transcript (cheap/harmless), but
 	// must NOT call ML or Slack.
-	tc.updateLiveInterpretation(s.ctx, "9999", time.Now(), "anything")
+	tc.updateLiveInterpretation(s.ctx, "9999", "", time.Now(), "anything")
 
 	mlMock.AssertNotCalled(s.T(), "SummarizeRescue", mock.Anything, mock.Anything)
 	slackMock.AssertNotCalled(s.T(), "SendMessageContext", mock.Anything, mock.Anything, mock.Anything)
@@ -958,6 +1168,38 @@ func (s *DispatchSuite) TestProcessRecord_DispatchDedupHit_DoesNotSetInFlightMar
 	slackMock.AssertNotCalled(s.T(), "SendMessageContext", mock.Anything, mock.Anything, mock.Anything)
 }
 
+// An object without a Content-Length that runs past the size cap fails inside the ASR upload,
+// not at StreamFile. It is just as permanent, so processRecord acks it (returns nil) instead
+// of nacking it into the DLQ.
+func (s *DispatchSuite) TestProcessRecord_OversizedWithoutContentLength_Acks() {
+	s3Srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Flushing before the body forces a chunked response: no Content-Length to check up front.
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		_, _ = w.Write(make([]byte, 64*1024))
+	}))
+	defer s3Srv.Close()
+	asrSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"text":"should never be used"}`))
+	}))
+	defer asrSrv.Close()
+
+	mlMock := new(mockMLClient)
+	tc := s.newClientUnderTest(new(mockSlackPoster), mlMock)
+	s3Client, err := s3.NewS3Client("key", "secret", s3Srv.URL, "us-east-1", "recordings", 5*time.Second, 1024)
+	s.Require().NoError(err)
+	tc.s3Client = s3Client
+	tc.asrClient = asr.NewASRClient(asrSrv.URL, 5*time.Second)
+
+	record := &s3event.EventRecord{
+		EventName: s3event.EventObjectCreatedPut,
+		S3:        s3event.EventS3Data{Object: s3event.EventObjectData{Key: "1399-1777832036_852162500.0-call_001.wav"}},
+	}
+	s.Require().NoError(tc.processRecord(s.ctx, record), "an oversized object must be acked, not redelivered")
+	mlMock.AssertNotCalled(s.T(), "ParseRelevantInformationFromDispatchMessage", mock.Anything, mock.Anything)
+}
+
 // FIX (dispatch_in_flight cleanup): processRecord defers a clear of the marker so it
 // is always cleaned up after the dispatch path completes, regardless of outcome. This
 // pins the regression where a non-rescue dispatch (e.g. the LLM classifies as
@@ -1125,6 +1367,544 @@ func (s *DispatchSuite) TestHandleMessage_MalformedPayload_NacksWithoutSideEffec
 	mlMock.AssertNotCalled(s.T(), "ParseRelevantInformationFromDispatchMessage", mock.Anything, mock.Anything)
 }
 
+func (s *DispatchSuite) TestProcessNonDispatchCall_AudioUpload_PostsFileShareInThread() {
+	slackMock := new(mockSlackPoster)
+	tc := s.newClientUnderTest(slackMock, new(mockMLClient))
+	tc.config.SlackAudioMode = AudioModeUpload
+	tc.config.SlackAudioUploadFormat = AudioFormatWAV
+
+	tac1TGID := talkgroupFromRadioShortCode["TAC1"].TGID
+	s.Require().NoError(tc.dragonflyClient.Set(
+		s.ctx, fmt.Sprintf(talkgroupKeyPrefix, tac1TGID), 30*time.Minute, "ts-parent",
+	))
+
+	slackMock.On("UploadFileV2Context", mock.Anything, mock.MatchedBy(func(p slack.UploadFileV2Parameters) bool {
+		return p.ThreadTimestamp == "ts-parent" && p.Channel == "C-TEST" &&
+			p.Filename == "1385-1750542445_854412500.1-call_1.wav" && p.FileSize == 4 && len(p.Blocks.BlockSet) > 0
+	})).Return(&slack.FileSummary{ID: "F1"}, nil).Once()
+
+	parsed := &AdornedDeconstructedKey{
+		dk:    &DeconstructedKey{Talkgroup: tac1TGID},
+		key:   "2026/07/05/19/1385/1385-1750542445_854412500.1-call_1.wav",
+		audio: []byte("RIFF"),
+	}
+	s.Require().NoError(tc.processNonDispatchCall(s.ctx, parsed, stubASRResponse("update")))
+	slackMock.AssertExpectations(s.T())
+	slackMock.AssertNotCalled(s.T(), "SendMessageContext", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *DispatchSuite) TestProcessNonDispatchCall_AudioUploadFails_FallsBackToTextReply() {
+	slackMock := new(mockSlackPoster)
+	tc := s.newClientUnderTest(slackMock, new(mockMLClient))
+	tc.config.SlackAudioMode = AudioModeUpload
+
+	tac1TGID := talkgroupFromRadioShortCode["TAC1"].TGID
+	s.Require().NoError(tc.dragonflyClient.Set(
+		s.ctx, fmt.Sprintf(talkgroupKeyPrefix, tac1TGID), 30*time.Minute, "ts-parent",
+	))
+
+	slackMock.On("UploadFileV2Context", mock.Anything, mock.Anything).
+		Return(nil, slack.SlackErrorResponse{Err: "missing_scope"}).Once()
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).
+		Return("C-TEST", "ts-child", "", nil).Once()
+
+	parsed := &AdornedDeconstructedKey{
+		dk:    &DeconstructedKey{Talkgroup: tac1TGID},
+		key:   "1385-1750542445_854412500.1-call_1.wav",
+		audio: []byte("RIFF"),
+	}
+	s.Require().NoError(tc.processNonDispatchCall(s.ctx, parsed, stubASRResponse("update")))
+	slackMock.AssertExpectations(s.T())
+}
+
+// ============================================================================
+// Slack outbound queue: 2 cases
+// ============================================================================
+
+func (s *DispatchSuite) newQueuedClientUnderTest(slackMock SlackPoster) *TranscribeClient {
+	tc := s.newClientUnderTest(slackMock, new(mockMLClient))
+	tc.config.SlackQueueEnabled = true
+	tc.config.SlackQueueStream = "slack_outbound_test"
+	tc.config.SlackQueueMaxAttempts = 3
+	tc.config.SlackQueueMaxBackoff = 50 * time.Millisecond
+	tc.config.SlackQueueClaimIdle = time.Minute
+	s.T().Cleanup(func() { _ = s.rdb.Del(context.Background(), "slack_outbound_test").Err() })
+	return tc
+}
+
+func (s *DispatchSuite) TestSlackQueue_CoalescesUpdatesForSameTS() {
+	slackMock := new(mockSlackPoster)
+	tc := s.newQueuedClientUnderTest(slackMock)
+
+	// Three renders of the same message queued back-to-back; only the newest should reach Slack.
+	for _, text := range []string{"render-1", "render-2", "render-3"} {
+		s.Require().NoError(tc.deliverSlack(s.ctx, outboundSlackMessage{
+			Kind:   slackOpUpdate,
+			TS:     "ts-summary-1",
+			Blocks: slack.Blocks{BlockSet: []slack.Block{slack.NewDividerBlock()}},
+			Text:   text,
+		}))
+	}
+	slackMock.AssertNotCalled(s.T(), "UpdateMessageContext", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	sent := make(chan struct{})
+	slackMock.On("UpdateMessageContext", mock.Anything, "C-TEST", "ts-summary-1", mock.Anything).
+		Run(func(mock.Arguments) { close(sent) }).
+		Return("C-TEST", "ts-summary-1", "", nil).Once()
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	done := make(chan struct{})
+	go func() {
+		tc.RunSlackQueue(ctx)
+		close(done)
+	}()
+
+	// Entries are processed in order, so by the time the newest render is sent the two
+	// superseded ones have already been skipped and acked.
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		s.FailNow("queued chat.update was never sent")
+	}
+	s.Require().Eventually(func() bool {
+		pending, err := s.rdb.XPending(s.ctx, "slack_outbound_test", slackQueueGroup).Result()
+		return err == nil && pending.Count == 0
+	}, 5*time.Second, 20*time.Millisecond)
+	cancel()
+	<-done
+
+	slackMock.AssertNumberOfCalls(s.T(), "UpdateMessageContext", 1)
+}
+
+func (s *DispatchSuite) TestSlackQueue_RetriesServerErrorThenDelivers() {
+	slackMock := new(mockSlackPoster)
+	tc := s.newQueuedClientUnderTest(slackMock)
+
+	s.Require().NoError(tc.deliverSlack(s.ctx, outboundSlackMessage{
+		Kind:     slackOpPost,
+		ThreadTS: "ts-parent",
+		AsUser:   true,
+		Blocks:   slack.Blocks{BlockSet: []slack.Block{slack.NewDividerBlock()}},
+	}))
+
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).
+		Return("", "", "", slack.StatusCodeError{Code: 503, Status: "503 Service Unavailable"}).Once()
+	sent := make(chan struct{})
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).
+		Run(func(mock.Arguments) { close(sent) }).
+		Return("C-TEST", "ts-child", "", nil).Once()
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	done := make(chan struct{})
+	go func() {
+		tc.RunSlackQueue(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		s.FailNow("queued post was never retried")
+	}
+	s.Require().Eventually(func() bool {
+		pending, err := s.rdb.XPending(s.ctx, "slack_outbound_test", slackQueueGroup).Result()
+		return err == nil && pending.Count == 0
+	}, 5*time.Second, 20*time.Millisecond)
+	cancel()
+	<-done
+
+	slackMock.AssertExpectations(s.T())
+}
+
+// recordingTransport is an escalation.Transport that records pages and fails the first
+// failFirst sends.
+type recordingTransport struct {
+	mu        sync.Mutex
+	pages     []escalation.Page
+	failFirst int
+}
+
+func (r *recordingTransport) Send(_ context.Context, p escalation.Page) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failFirst > 0 {
+		r.failFirst--
+		return errors.New("gateway unavailable")
+	}
+	r.pages = append(r.pages, p)
+	return nil
+}
+
+func (r *recordingTransport) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pages)
+}
+
+func (s *DispatchSuite) TestEscalation_SARNotifiedPagesOncePerRescue() {
+	tc := s.newClientUnderTest(new(mockSlackPoster), new(mockMLClient))
+	tc.config.EscalationTimeout = time.Second
+	transport := &recordingTransport{}
+	tc.escalator = escalation.New([]escalation.Rule{{Name: "sar", Type: escalation.RuleSARNotified}}, transport)
+
+	meta := ClosureMeta{IncidentID: "inc-1", TGID: "1967", TACChannel: "TAC10", MessageTS: "ts-alert-1"}
+	summary := &ml.RescueSummary{Headline: "Injured hiker", SARNotified: true}
+
+	tc.evaluateEscalations(s.ctx, meta, &ml.RescueSummary{})
+	s.Equal(0, transport.count(), "rule doesn't hold yet")
+
+	tc.evaluateEscalations(s.ctx, meta, summary)
+	tc.evaluateEscalations(s.ctx, meta, summary)
+	s.Require().Equal(1, transport.count(), "second evaluation is deduped")
+	s.Contains(transport.pages[0].Body, "SAR notified")
+
+	// A later rescue on the same TAC is a new incident and pages again.
+	meta.IncidentID, meta.MessageTS = "inc-2", "ts-alert-2"
+	tc.evaluateEscalations(s.ctx, meta, summary)
+	s.Equal(2, transport.count())
+}
+
+func (s *DispatchSuite) TestEscalation_FailedPageRetriesAndActiveForFiresFromSweeper() {
+	tc := s.newClientUnderTest(new(mockSlackPoster), new(mockMLClient))
+	tc.config.EscalationTimeout = time.Second
+	transport := &recordingTransport{failFirst: 1}
+	tc.escalator = escalation.New([]escalation.Rule{{Name: "long", Type: escalation.RuleActiveFor, After: time.Hour}}, transport)
+
+	// Active rescue (expiry in the future) dispatched two hours ago.
+	meta := ClosureMeta{TGID: "1967", TACChannel: "TAC10", MessageTS: "ts-alert-1", DispatchedAt: time.Now().Add(-2 * time.Hour)}
+	s.scheduleClosureFixture("1967", time.Now().Add(time.Hour).Unix(), meta)
+
+	tc.sweepOnce(s.ctx)
+	s.Equal(0, transport.count(), "first page fails")
+	tc.sweepOnce(s.ctx)
+	s.Equal(1, transport.count(), "dedup claim was released, so the next tick retries")
+	tc.sweepOnce(s.ctx)
+	s.Equal(1, transport.count())
+}
+
+// ============================================================================
+// Closure policy: 4 cases
+// ============================================================================
+
+func (s *DispatchSuite) TestClosurePolicy_TrafficPushesClosureAndRouting() {
+	tc := s.newClientUnderTest(new(mockSlackPoster), new(mockMLClient))
+	tc.config.TACIdleTimeout = 20 * time.Minute
+
+	tgid := talkgroupFromRadioShortCode["TAC1"].TGID
+	meta := ClosureMeta{TGID: tgid, TACChannel: "TAC1", ThreadTS: "ts-1", DispatchedAt: time.Now().Add(-25 * time.Minute)}
+	s.scheduleClosureFixture(tgid, time.Now().Add(5*time.Minute).Unix(), meta)
+	tc.refreshIncidentRouting(s.ctx, ClosureMeta{IncidentID: tgid, TGID: tgid, ThreadTS: "ts-1"}, 5*time.Minute)
+
+	tc.extendOnActivity(s.ctx, tgid)
+
+	score, err := s.rdb.ZScore(s.ctx, activeTACsKey, tgid).Result()
+	s.Require().NoError(err)
+	s.InDelta(time.Now().Add(20*time.Minute).Unix(), int64(score), 2, "traffic pushes the close to now + idle timeout")
+	ttl, err := s.rdb.TTL(s.ctx, fmt.Sprintf(talkgroupKeyPrefix, tgid)).Result()
+	s.Require().NoError(err)
+	s.Greater(ttl, 15*time.Minute, "routing must be re-armed to outlive the new close time")
+}
+
+// After the first window, a quiet channel closes one idle timeout after its last transmission;
+// a held window (re-page, Extend, Keep) still runs to its end.
+func (s *DispatchSuite) TestClosurePolicy_TrafficPullsQuietCloseInButKeepsHeldWindow() {
+	tc := s.newClientUnderTest(new(mockSlackPoster), new(mockMLClient))
+	tc.config.TACIdleTimeout = 10 * time.Minute
+
+	tgid := talkgroupFromRadioShortCode["TAC1"].TGID
+	meta := ClosureMeta{TGID: tgid, TACChannel: "TAC1", ThreadTS: "ts-1", DispatchedAt: time.Now().Add(-2 * time.Minute)}
+	s.scheduleClosureFixture(tgid, time.Now().Add(28*time.Minute).Unix(), meta)
+
+	tc.extendOnActivity(s.ctx, tgid)
+	score, err := s.rdb.ZScore(s.ctx, activeTACsKey, tgid).Result()
+	s.Require().NoError(err)
+	s.InDelta(time.Now().Add(10*time.Minute).Unix(), int64(score), 2, "the close follows the last transmission")
+
+	held := time.Now().Add(25 * time.Minute)
+	meta.HeldUntil = held
+	s.scheduleClosureFixture(tgid, held.Unix(), meta)
+	tc.extendOnActivity(s.ctx, tgid)
+	score, err = s.rdb.ZScore(s.ctx, activeTACsKey, tgid).Result()
+	s.Require().NoError(err)
+	s.EqualValues(held.Unix(), score, "traffic must not cut a held window short")
+}
+
+func (s *DispatchSuite) TestClosurePolicy_LifetimeCapsAndClaimedClosureStaysClosed() {
+	tc := s.newClientUnderTest(new(mockSlackPoster), new(mockMLClient))
+	tc.config.TACIdleTimeout = 20 * time.Minute
+	tc.config.TACMaxLifetime = 40 * time.Minute
+
+	tgid := talkgroupFromRadioShortCode["TAC1"].TGID
+	meta := ClosureMeta{TGID: tgid, TACChannel: "TAC1", ThreadTS: "ts-1", DispatchedAt: time.Now().Add(-30 * time.Minute)}
+	s.scheduleClosureFixture(tgid, time.Now().Add(5*time.Minute).Unix(), meta)
+
+	tc.extendOnActivity(s.ctx, tgid)
+	score, err := s.rdb.ZScore(s.ctx, activeTACsKey, tgid).Result()
+	s.Require().NoError(err)
+	s.InDelta(time.Now().Add(10*time.Minute).Unix(), int64(score), 2, "capped at dispatch + max lifetime")
+
+	// Once the sweeper (or Cancel) has claimed the closure, traffic must not bring it back.
+	s.Require().NoError(s.rdb.ZRem(s.ctx, activeTACsKey, tgid).Err())
+	tc.extendOnActivity(s.ctx, tgid)
+	count, err := s.rdb.ZCard(s.ctx, activeTACsKey).Result()
+	s.Require().NoError(err)
+	s.EqualValues(0, count)
+}
+
+func (s *DispatchSuite) TestClosurePolicy_ClosingWarningOncePerCloseTime() {
+	slackMock := new(mockSlackPoster)
+	tc := s.newClientUnderTest(slackMock, new(mockMLClient))
+	tc.config.TACClosingWarning = 5 * time.Minute
+
+	tgid := talkgroupFromRadioShortCode["TAC1"].TGID
+	meta := ClosureMeta{TGID: tgid, TACChannel: "TAC1", ThreadTS: "ts-1", SourceTalkgroup: FireDispatch1TGID}
+	s.scheduleClosureFixture(tgid, time.Now().Add(2*time.Minute).Unix(), meta)
+
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).
+		Return("C-TEST", "ts-warn", "", nil).Twice()
+
+	tc.sweepOnce(s.ctx)
+	tc.sweepOnce(s.ctx)
+	slackMock.AssertNumberOfCalls(s.T(), "SendMessageContext", 1)
+
+	// A push (traffic or Extend) gives a new close time, which earns a new warning.
+	s.Require().NoError(s.rdb.ZAdd(s.ctx, activeTACsKey, redis.Z{Score: float64(time.Now().Add(3 * time.Minute).Unix()), Member: tgid}).Err())
+	tc.sweepOnce(s.ctx)
+	slackMock.AssertExpectations(s.T())
+}
+
+// ============================================================================
+// Outcome close prompt: 4 cases
+// ============================================================================
+
+func (s *DispatchSuite) TestCloseSuggestion_TerminalOutcomePromptsOnceAndPullsCloseIn() {
+	slackMock := new(mockSlackPoster)
+	tc := s.newClientUnderTest(slackMock, new(mockMLClient))
+	tc.config.OutcomeCloseEnabled = true
+	tc.config.OutcomeCloseGrace = 10 * time.Minute
+
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	previous := time.Now().Add(25 * time.Minute).Unix()
+	meta := ClosureMeta{IncidentID: incident, TGID: "1389", TACChannel: "TAC1", ThreadTS: "ts-1", SourceTalkgroup: FireDispatch1TGID}
+	s.scheduleClosureFixture(incident, previous, meta)
+
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).
+		Return("C-TEST", "ts-prompt", "", nil).Once()
+
+	resolved := &ml.RescueSummary{Outcome: "Resolved — patient transported"}
+	tc.maybeSuggestClose(s.ctx, meta, resolved)
+	tc.maybeSuggestClose(s.ctx, meta, resolved)
+	slackMock.AssertExpectations(s.T())
+
+	score, err := s.rdb.ZScore(s.ctx, activeTACsKey, incident).Result()
+	s.Require().NoError(err)
+	s.InDelta(time.Now().Add(10*time.Minute).Unix(), int64(score), 2, "unanswered, the sweeper closes it after the grace period")
+	stashed, err := s.rdb.Get(s.ctx, fmt.Sprintf(closeSuggestedKeyFmt, incident)).Result()
+	s.Require().NoError(err)
+	s.Equal(strconv.FormatInt(previous, 10), stashed, "Keep needs the expiry the prompt replaced")
+}
+
+func (s *DispatchSuite) TestCloseSuggestion_OngoingOrDisabledDoesNothing() {
+	slackMock := new(mockSlackPoster)
+	tc := s.newClientUnderTest(slackMock, new(mockMLClient))
+	tc.config.OutcomeCloseGrace = 10 * time.Minute
+
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	previous := time.Now().Add(25 * time.Minute).Unix()
+	meta := ClosureMeta{IncidentID: incident, TGID: "1389", TACChannel: "TAC1", ThreadTS: "ts-1"}
+	s.scheduleClosureFixture(incident, previous, meta)
+
+	tc.maybeSuggestClose(s.ctx, meta, &ml.RescueSummary{Outcome: "Resolved — handled on scene"})
+	tc.config.OutcomeCloseEnabled = true
+	tc.maybeSuggestClose(s.ctx, meta, &ml.RescueSummary{Outcome: "Ongoing"})
+
+	slackMock.AssertNotCalled(s.T(), "SendMessageContext", mock.Anything, mock.Anything, mock.Anything)
+	score, err := s.rdb.ZScore(s.ctx, activeTACsKey, incident).Result()
+	s.Require().NoError(err)
+	s.EqualValues(previous, score)
+}
+
+// With TAC_IDLE_TIMEOUT also set, the transmissions that follow a resolution must not push the
+// grace close back out; once Keep is pressed, traffic extends the rescue again.
+func (s *DispatchSuite) TestCloseSuggestion_TrafficAfterPromptKeepsGraceClose() {
+	slackMock := new(mockSlackPoster)
+	mlMock := new(mockMLClient)
+	tc := s.newClientUnderTest(slackMock, mlMock)
+	tc.config.OutcomeCloseEnabled = true
+	tc.config.OutcomeCloseGrace = 10 * time.Minute
+	tc.config.TACIdleTimeout = 20 * time.Minute
+
+	tgid := talkgroupFromRadioShortCode["TAC1"].TGID
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	meta := ClosureMeta{IncidentID: incident, TGID: tgid, TACChannel: "TAC1", ThreadTS: "ts-1", SourceTalkgroup: FireDispatch1TGID,
+		MessageTS: "ts-1", DispatchedAt: time.Now().Add(-30 * time.Minute)}
+	s.scheduleClosureFixture(incident, time.Now().Add(25*time.Minute).Unix(), meta)
+	tc.refreshIncidentRouting(s.ctx, meta, 25*time.Minute)
+
+	mlMock.On("SummarizeRescue", mock.Anything, mock.Anything).
+		Return(&ml.RescueSummary{Headline: "h", Outcome: "Resolved — patient transported"}, nil).Once()
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).
+		Return("C-TEST", "ts-x", "", nil).Times(3) // the prompt, the thread reply, the live summary
+
+	tc.maybeSuggestClose(s.ctx, meta, &ml.RescueSummary{Outcome: "Resolved — patient transported"})
+	parsed := &AdornedDeconstructedKey{dk: &DeconstructedKey{Talkgroup: tgid, Time: time.Now()}}
+	s.Require().NoError(tc.processNonDispatchCall(s.ctx, parsed, stubASRResponse("Engine 171 clear, returning")))
+
+	mlMock.AssertExpectations(s.T())
+	slackMock.AssertExpectations(s.T())
+	score, err := s.rdb.ZScore(s.ctx, activeTACsKey, incident).Result()
+	s.Require().NoError(err)
+	s.InDelta(time.Now().Add(10*time.Minute).Unix(), int64(score), 2, "traffic must not undo the grace close")
+
+	// What Keep leaves behind: the prompt is answered, so the idle policy applies again.
+	s.Require().NoError(s.rdb.Set(s.ctx, fmt.Sprintf(closeSuggestedKeyFmt, incident), closeSuggestionKept, time.Hour).Err())
+	tc.extendOnActivity(s.ctx, incident)
+	score, err = s.rdb.ZScore(s.ctx, activeTACsKey, incident).Result()
+	s.Require().NoError(err)
+	s.InDelta(time.Now().Add(20*time.Minute).Unix(), int64(score), 2)
+}
+
+// A re-page while the prompt is unanswered is noted in the thread but doesn't reschedule: the
+// prompt's close time still stands.
+func (s *DispatchSuite) TestCloseSuggestion_RepageAfterPromptKeepsGraceClose() {
+	slackMock := new(mockSlackPoster)
+	tc := s.newClientUnderTest(slackMock, new(mockMLClient))
+	tc.config.OutcomeCloseEnabled = true
+	tc.config.OutcomeCloseGrace = 10 * time.Minute
+
+	tgid := talkgroupFromRadioShortCode["TAC1"].TGID
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	meta := ClosureMeta{IncidentID: incident, TGID: tgid, TACChannel: "TAC1", ThreadTS: "ts-1", SourceTalkgroup: FireDispatch1TGID,
+		MessageTS: "ts-1", Transcription: "Rescue Trail TAC 1 Mount Si"}
+	s.scheduleClosureFixture(incident, time.Now().Add(25*time.Minute).Unix(), meta)
+
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).
+		Return("C-TEST", "ts-x", "", nil).Twice() // the prompt, the re-page reply
+
+	tc.maybeSuggestClose(s.ctx, meta, &ml.RescueSummary{Outcome: "Resolved — patient transported"})
+	parsed := &AdornedDeconstructedKey{dk: &DeconstructedKey{Talkgroup: FireDispatch1TGID, Time: time.Now()}}
+	s.Require().NoError(tc.handleAdditionalDispatch(s.ctx, parsed, stubASRResponse("additional unit"), meta,
+		talkgroupFromTGID[tgid], ml.DispatchMessage{CallType: "Rescue - Trail", TACChannel: "TAC1"}))
+
+	slackMock.AssertExpectations(s.T())
+	score, err := s.rdb.ZScore(s.ctx, activeTACsKey, incident).Result()
+	s.Require().NoError(err)
+	s.InDelta(time.Now().Add(10*time.Minute).Unix(), int64(score), 2, "a re-page must not undo the grace close")
+	after, ok := tc.readClosureMeta(s.ctx, incident)
+	s.Require().True(ok)
+	s.True(after.HeldUntil.IsZero(), "nor hold a window the prompt doesn't mention")
+}
+
+// ============================================================================
+// After-action report: 2 cases
+// ============================================================================
+
+func (s *DispatchSuite) TestAfterAction_SweepSnapshotsBeforeCleanup() {
+	slackMock := new(mockSlackPoster)
+	mlMock := new(mockMLClient)
+	tc := s.newClientUnderTest(slackMock, mlMock)
+	tc.config.AfterActionReportEnabled = true
+	tc.config.AfterActionReportTimeout = 5 * time.Second
+
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	meta := ClosureMeta{IncidentID: incident, TGID: "1389", TACChannel: "TAC1", ThreadTS: "ts-1", SourceTalkgroup: FireDispatch1TGID,
+		DispatchedAt: time.Now().Add(-72 * time.Minute)}
+	s.scheduleClosureFixture(incident, time.Now().Add(-time.Second).Unix(), meta)
+	s.Require().NoError(s.rdb.RPush(s.ctx, fmt.Sprintf(tacTranscriptsKeyFmt, incident),
+		`{"captured_at":"13:05:00","text":"Engine 171 on scene, patient contact"}`).Err())
+
+	mlMock.On("GenerateAfterActionReport", mock.Anything, mock.MatchedBy(func(in ml.AfterActionInput) bool {
+		return len(in.TACTranscripts) == 1 && in.Duration == "1h12m" && in.TACChannel == "TAC1"
+	})).Return(&ml.AfterActionReport{Headline: "Injured hiker carried out", Duration: "about an hour"}, nil).Once()
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).
+		Return("C-TEST", "ts-reply", "", nil).Twice() // Channel Closed + the report
+
+	tc.sweepOnce(s.ctx)
+
+	// The sweep returns with the report still generating, and the snapshot survives cleanup.
+	exists, err := s.rdb.Exists(s.ctx, fmt.Sprintf(tacTranscriptsKeyFmt, incident)).Result()
+	s.Require().NoError(err)
+	s.EqualValues(0, exists, "cleanup doesn't wait for the report")
+	tc.afterActionReports.Wait()
+
+	mlMock.AssertExpectations(s.T())
+	slackMock.AssertExpectations(s.T())
+}
+
+func (s *DispatchSuite) TestAfterAction_DisabledOrSilentTACSkipsReport() {
+	slackMock := new(mockSlackPoster)
+	mlMock := new(mockMLClient)
+	tc := s.newClientUnderTest(slackMock, mlMock)
+
+	meta := ClosureMeta{IncidentID: "01J9ZC8Q3V7N4X2K5M6P8R0T1W", TGID: "1389", TACChannel: "TAC1", ThreadTS: "ts-1", SourceTalkgroup: FireDispatch1TGID}
+	s.Require().NoError(s.rdb.RPush(s.ctx, fmt.Sprintf(tacTranscriptsKeyFmt, meta.IncidentID), `{"text":"on scene"}`).Err())
+	tc.publishAfterActionReport(s.ctx, meta, time.Now())
+
+	tc.config.AfterActionReportEnabled = true
+	meta.IncidentID = "01J9ZC8Q3V7N4X2K5M6P8R0T1X" // no transcripts
+	tc.publishAfterActionReport(s.ctx, meta, time.Now())
+	tc.afterActionReports.Wait()
+
+	mlMock.AssertNotCalled(s.T(), "GenerateAfterActionReport", mock.Anything, mock.Anything)
+	slackMock.AssertNotCalled(s.T(), "SendMessageContext", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// ============================================================================
+// Shift digest: 2 cases
+// ============================================================================
+
+func (s *DispatchSuite) TestDigest_SweptIncidentIsRetainedForDigest() {
+	slackMock := new(mockSlackPoster)
+	tc := s.newClientUnderTest(slackMock, new(mockMLClient))
+	tc.config.DigestEnabled = true
+	tc.config.DigestRetention = 72 * time.Hour
+
+	const incident = "01J9ZC8Q3V7N4X2K5M6P8R0T1W"
+	meta := ClosureMeta{IncidentID: incident, TGID: "1389", TACChannel: "TAC1", ThreadTS: "ts-1", SourceTalkgroup: FireDispatch1TGID}
+	s.scheduleClosureFixture(incident, time.Now().Add(-time.Second).Unix(), meta)
+	s.Require().NoError(s.rdb.Set(s.ctx, fmt.Sprintf(summaryDataKeyFmt, incident),
+		`{"headline":"Injured hiker","outcome":"Resolved — patient transported","sar_notified":true}`, time.Hour).Err())
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).Return("C-TEST", "ts-closed", "", nil).Once()
+
+	tc.sweepOnce(s.ctx)
+
+	records, err := tc.readClosedIncidents(s.ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Minute))
+	s.Require().NoError(err)
+	s.Require().Len(records, 1)
+	s.Equal("Injured hiker", records[0].Headline, "summary is captured before the sidecar cleanup")
+	s.True(records[0].SARNotified)
+	s.False(records[0].FalseAlarm)
+}
+
+func (s *DispatchSuite) TestDigest_OneReplicaPostsEachWindowWithCSV() {
+	slackMock := new(mockSlackPoster)
+	tc := s.newClientUnderTest(slackMock, new(mockMLClient))
+	tc.config.DigestEnabled = true
+	tc.config.DigestRetention = 72 * time.Hour
+	tc.config.DigestChannelID = "C-DIGEST"
+
+	now := time.Now()
+	times := []clockTime{{now.Hour(), now.Minute()}} // a boundary this minute
+	tc.recordClosedIncident(s.ctx, ClosureMeta{IncidentID: "inc-1", TACChannel: "TAC3"}, now.Add(-2*time.Hour))
+	tc.recordClosedIncident(s.ctx, ClosureMeta{IncidentID: "inc-old", TACChannel: "TAC1"}, now.Add(-48*time.Hour))
+
+	slackMock.On("SendMessageContext", mock.Anything, "C-DIGEST", mock.Anything).Return("C-DIGEST", "ts-digest", "", nil).Once()
+	var uploaded string
+	slackMock.On("UploadFileV2Context", mock.Anything, mock.MatchedBy(func(p slack.UploadFileV2Parameters) bool {
+		return p.Channel == "C-DIGEST" && p.ThreadTimestamp == "ts-digest" && strings.HasSuffix(p.Filename, ".csv")
+	})).Run(func(args mock.Arguments) {
+		body, _ := io.ReadAll(args.Get(1).(slack.UploadFileV2Parameters).Reader)
+		uploaded = string(body)
+	}).Return(&slack.FileSummary{ID: "F1"}, nil).Once()
+
+	tc.digestOnce(s.ctx, now, times)
+	tc.digestOnce(s.ctx, now.Add(30*time.Second), times) // another replica, same window
+
+	slackMock.AssertExpectations(s.T())
+	s.Contains(uploaded, "inc-1")
+	s.NotContains(uploaded, "inc-old", "closed before the window opened")
+}
+
 // ============================================================================
 // Misc helpers
 // ============================================================================
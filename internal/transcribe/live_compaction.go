@@ -0,0 +1,96 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+)
+
+// Transcript compaction (LIVE_INTERPRETATION_COMPACTION_ENABLED): every summary pass normally
+// sends the whole tac_transcripts list plus the previous summary, so on a multi-hour rescue with
+// hundreds of transmissions the prompt grows without bound until the call times out. Since the
+// previous summary is already an extended record of everything it saw, the transmissions it
+// covers can be dropped from later prompts: once the unsent tail passes a size limit, the
+// transmissions summary_data already reflects are folded into it and only the ones since are
+// sent.
+//
+// The list itself is never trimmed (the after-action report reads all of it); compaction only
+// moves the point the prompt starts from. The fold happens before the call, from what the last
+// successful pass covered, so a transmission is never dropped before some summary has seen it.
+//
+// Storage:
+//   STRING summary_compaction:<incident> → JSON summaryCompaction, same TTL as the list
+
+const summaryCompactionKeyFmt = "summary_compaction:%s"
+
+// summaryCompaction is where an incident's summary prompt starts. Indexes are into
+// tac_transcripts.
+type summaryCompaction struct {
+	// Folded is how many of the earliest transmissions are represented only by the previous
+	// summary and no longer sent.
+	Folded int `json:"folded"`
+	// Covered is how many transmissions the summary in summary_data was written from. The next
+	// fold moves Folded up to it.
+	Covered int `json:"covered"`
+	// Compactions counts folds, for the log.
+	Compactions int `json:"compactions"`
+}
+
+// planCompaction decides where this pass's prompt starts. Without a previous summary nothing can
+// be folded, and a state that doesn't fit the list (the list expired and restarted) is reset;
+// both send the whole list. Otherwise, when the tail past Folded is over either limit (0 =
+// unused) and the last summary covers more than Folded, Folded moves up to what it covers.
+func planCompaction(transcripts []ml.TACTranscript, state summaryCompaction, hasPrevious bool, maxTransmissions, maxTokens int) summaryCompaction {
+	if !hasPrevious || state.Covered > len(transcripts) || state.Folded > state.Covered || state.Folded < 0 {
+		return summaryCompaction{Compactions: state.Compactions}
+	}
+	tail := transcripts[state.Folded:]
+	over := (maxTransmissions > 0 && len(tail) > maxTransmissions) ||
+		(maxTokens > 0 && approxTokens(tail) > maxTokens)
+	if over && state.Covered > state.Folded {
+		state.Folded = state.Covered
+		state.Compactions++
+	}
+	return state
+}
+
+// approxTokens estimates the prompt tokens transcripts take at ~4 characters a token, plus the
+// timestamp and numbering each line carries. Close enough for a limit that only has to keep the
+// prompt well clear of the timeout.
+func approxTokens(transcripts []ml.TACTranscript) int {
+	n := 0
+	for _, t := range transcripts {
+		n += len(t.Text)/4 + 8
+	}
+	return n
+}
+
+// readSummaryCompaction returns the incident's compaction state, or the zero state (send
+// everything) when it is missing or unreadable.
+func (tc *TranscribeClient) readSummaryCompaction(ctx context.Context, incidentID string) summaryCompaction {
+	var state summaryCompaction
+	raw, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(summaryCompactionKeyFmt, incidentID))
+	if err != nil || raw == "" {
+		return state
+	}
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		return summaryCompaction{}
+	}
+	return state
+}
+
+// writeSummaryCompaction stores state after a pass whose summary was saved. Best-effort: a lost
+// write only means the next fold starts from the older state.
+func (tc *TranscribeClient) writeSummaryCompaction(ctx context.Context, incidentID string, state summaryCompaction, ttl time.Duration) {
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(summaryCompactionKeyFmt, incidentID), ttl, string(encoded)); err != nil {
+		slog.Warn("live interpretation: failed to save compaction state", slog.String("error", err.Error()), slog.String("incident", incidentID))
+	}
+}
@@ -0,0 +1,52 @@
+package transcribe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/stretchr/testify/assert"
+)
+
+func transmissions(n int, text string) []ml.TACTranscript {
+	out := make([]ml.TACTranscript, n)
+	for i := range out {
+		out[i] = ml.TACTranscript{CapturedAt: "13:00:00", Text: text}
+	}
+	return out
+}
+
+func TestPlanCompaction(t *testing.T) {
+	ts := transmissions(50, "copy")
+
+	// Under the limit: the whole list goes out.
+	got := planCompaction(ts[:10], summaryCompaction{Covered: 9}, true, 40, 0)
+	assert.Equal(t, 0, got.Folded)
+
+	// Over the limit: fold up to what the last summary covered, never past it.
+	got = planCompaction(ts, summaryCompaction{Covered: 48}, true, 40, 0)
+	assert.Equal(t, summaryCompaction{Folded: 48, Covered: 48, Compactions: 1}, got)
+
+	// The tail since the last fold is small again: no further fold.
+	got = planCompaction(ts, summaryCompaction{Folded: 30, Covered: 49, Compactions: 1}, true, 40, 0)
+	assert.Equal(t, 30, got.Folded)
+
+	// The token limit trips on a few long transmissions.
+	long := transmissions(5, strings.Repeat("w", 4000))
+	got = planCompaction(long, summaryCompaction{Covered: 4}, true, 0, 2000)
+	assert.Equal(t, 4, got.Folded)
+
+	// Nothing the summary covers beyond what's folded: keep sending the tail.
+	got = planCompaction(ts, summaryCompaction{Folded: 5, Covered: 5}, true, 40, 0)
+	assert.Equal(t, 5, got.Folded)
+}
+
+func TestPlanCompaction_ResetsWithoutABase(t *testing.T) {
+	ts := transmissions(50, "copy")
+
+	// No previous summary to fold into: send everything.
+	assert.Equal(t, 0, planCompaction(ts, summaryCompaction{Folded: 30, Covered: 48}, false, 40, 0).Folded)
+
+	// The list restarted (expired) under the state: start over.
+	assert.Equal(t, 0, planCompaction(ts[:3], summaryCompaction{Folded: 30, Covered: 48}, true, 40, 0).Folded)
+}
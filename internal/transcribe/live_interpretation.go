@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/searchandrescuegg/transcribe/internal/ml"
@@ -13,13 +14,13 @@ import (
 )
 
 // Live-interpretation feature: every TAC transmission appends a transcript entry to the
-// per-TAC list, then re-summarizes the rescue (dispatch + all transcripts so far) and
+// incident's list, then re-summarizes the rescue (dispatch + all transcripts so far) and
 // updates a "Live Interpretation" message in the rescue thread. Each summary is anchored
 // in cumulative context, so as the rescue expands the model sees the full history.
 //
 // Storage:
-//   LIST   tac_transcripts:<TGID>  → JSON-encoded {captured_at, text} per RPush
-//   STRING summary_ts:<TGID>       → message_ts of the running interpretation message
+//   LIST   tac_transcripts:<incident>  → JSON-encoded {captured_at, text, channel} per RPush
+//   STRING summary_ts:<incident>       → message_ts of the running interpretation message
 //
 // Both keys carry a TTL of 2 × TacticalChannelActivationDuration so a Switch that resets
 // the activation window doesn't lose mid-rescue context. The sweeper's metadata cleanup
@@ -61,6 +62,9 @@ const (
 type liveTranscriptEntry struct {
 	CapturedAt string `json:"captured_at"`
 	Text       string `json:"text"`
+	// Channel is the radio short code the transmission was heard on ("TAC8"). Empty for
+	// entries written before multi-TAC support.
+	Channel string `json:"channel,omitempty"`
 }
 
 // updateLiveInterpretation appends one TAC transcript and refreshes the rescue thread's
@@ -71,41 +75,41 @@ type liveTranscriptEntry struct {
 // Concurrency model: when N transmissions arrive nearly simultaneously (synthetic trigger
 // burst, real-world heavy traffic), each worker:
 //  1. RPushes its transcript (everyone records, lossless).
-//  2. Tries to SetNX a per-TGID summary lock. Loser sets a stale flag and returns immediately
+//  2. Tries to SetNX a per-incident summary lock. Loser sets a stale flag and returns immediately
 //     — no LLM call, no Slack post.
 //  3. Winner runs the summarize-and-post loop, which re-summarizes whenever the stale flag
 //     was set during the last cycle. Net cost: ~2 LLM calls per burst regardless of N.
-func (tc *TranscribeClient) updateLiveInterpretation(ctx context.Context, tacTGID string, capturedAt time.Time, transcript string) {
+func (tc *TranscribeClient) updateLiveInterpretation(ctx context.Context, incidentID, channel string, capturedAt time.Time, transcript string) {
 	if transcript == "" {
 		return
 	}
 
-	listKey := fmt.Sprintf(tacTranscriptsKeyFmt, tacTGID)
+	listKey := fmt.Sprintf(tacTranscriptsKeyFmt, incidentID)
 	listTTL := 2 * tc.config.TacticalChannelActivationDuration
-	if err := tc.appendTranscript(ctx, listKey, listTTL, capturedAt, transcript); err != nil {
-		slog.Warn("live interpretation: append failed", slog.String("error", err.Error()), slog.String("tgid", tacTGID))
+	if err := tc.appendTranscript(ctx, listKey, listTTL, channel, capturedAt, transcript); err != nil {
+		slog.Warn("live interpretation: append failed", slog.String("error", err.Error()), slog.String("incident", incidentID))
 		return
 	}
 
 	// Try to take ownership of the LLM-and-post cycle. Losers mark the rescue stale and
 	// return — the existing lock holder will pick up our transcript on its next pass.
-	lockKey := fmt.Sprintf(summaryLockKeyFmt, tacTGID)
+	lockKey := fmt.Sprintf(summaryLockKeyFmt, incidentID)
 	acquired, err := tc.dragonflyClient.SetNX(ctx, lockKey, summaryLockTTL, "1")
 	if err != nil {
-		slog.Warn("live interpretation: lock SetNX failed; skipping summary update", slog.String("error", err.Error()), slog.String("tgid", tacTGID))
+		slog.Warn("live interpretation: lock SetNX failed; skipping summary update", slog.String("error", err.Error()), slog.String("incident", incidentID))
 		return
 	}
 	if !acquired {
 		// Another worker is mid-summary. Mark stale so it knows to re-summarize when it
 		// finishes — guarantees our transcript ends up reflected in the displayed summary.
-		if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(summaryStaleKeyFmt, tacTGID), summaryStaleTTL, "1"); err != nil {
-			slog.Warn("live interpretation: failed to set stale flag", slog.String("error", err.Error()), slog.String("tgid", tacTGID))
+		if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(summaryStaleKeyFmt, incidentID), summaryStaleTTL, "1"); err != nil {
+			slog.Warn("live interpretation: failed to set stale flag", slog.String("error", err.Error()), slog.String("incident", incidentID))
 		}
 		return
 	}
 	defer func() {
 		if err := tc.dragonflyClient.Del(ctx, lockKey); err != nil {
-			slog.Warn("live interpretation: failed to release summary lock; will expire via TTL", slog.String("error", err.Error()), slog.String("tgid", tacTGID))
+			slog.Warn("live interpretation: failed to release summary lock; will expire via TTL", slog.String("error", err.Error()), slog.String("incident", incidentID))
 		}
 	}()
 
@@ -115,15 +119,15 @@ func (tc *TranscribeClient) updateLiveInterpretation(ctx context.Context, tacTGI
 	// pathological loop where the flag is being toggled forever.
 	const maxIterations = 5
 	for i := 0; i < maxIterations; i++ {
-		if err := tc.dragonflyClient.Del(ctx, fmt.Sprintf(summaryStaleKeyFmt, tacTGID)); err != nil {
+		if err := tc.dragonflyClient.Del(ctx, fmt.Sprintf(summaryStaleKeyFmt, incidentID)); err != nil {
 			// Failure to clear isn't fatal — worst case we run an extra summarize.
 			slog.Warn("live interpretation: failed to clear stale flag", slog.String("error", err.Error()))
 		}
-		if !tc.runOneSummaryPass(ctx, tacTGID, listKey, listTTL) {
+		if !tc.runOneSummaryPass(ctx, incidentID, listKey, listTTL) {
 			// Pass returned false: rescue isn't live (no metadata) or unrecoverable error.
 			return
 		}
-		stale, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(summaryStaleKeyFmt, tacTGID))
+		stale, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(summaryStaleKeyFmt, incidentID))
 		if err != nil {
 			slog.Warn("live interpretation: failed to read stale flag; assuming caught up", slog.String("error", err.Error()))
 			return
@@ -131,18 +135,19 @@ func (tc *TranscribeClient) updateLiveInterpretation(ctx context.Context, tacTGI
 		if stale != "1" {
 			return // No new transcripts arrived during our work — we're caught up.
 		}
-		slog.Debug("live interpretation: stale flag set during summary; re-running", slog.String("tgid", tacTGID), slog.Int("iteration", i+1))
+		slog.Debug("live interpretation: stale flag set during summary; re-running", slog.String("incident", incidentID), slog.Int("iteration", i+1))
 	}
-	slog.Warn("live interpretation: hit maxIterations; giving up to avoid infinite loop", slog.String("tgid", tacTGID))
+	slog.Warn("live interpretation: hit maxIterations; giving up to avoid infinite loop", slog.String("incident", incidentID))
 }
 
 // appendTranscript handles the RPush + Expire pair so the caller stays focused on the
 // concurrency policy. Re-stamps the TTL on every push so an active rescue's transcripts
 // list never expires under the rescue's feet.
-func (tc *TranscribeClient) appendTranscript(ctx context.Context, listKey string, listTTL time.Duration, capturedAt time.Time, transcript string) error {
+func (tc *TranscribeClient) appendTranscript(ctx context.Context, listKey string, listTTL time.Duration, channel string, capturedAt time.Time, transcript string) error {
 	encoded, err := json.Marshal(liveTranscriptEntry{
 		CapturedAt: capturedAt.Format("15:04:05"),
 		Text:       transcript,
+		Channel:    channel,
 	})
 	if err != nil {
 		return fmt.Errorf("marshal transcript entry: %w", err)
@@ -156,26 +161,36 @@ func (tc *TranscribeClient) appendTranscript(ctx context.Context, listKey string
 	return nil
 }
 
-// runOneSummaryPass reads the full transcripts list, calls SummarizeRescue, and posts (or
-// chat.update's) the running interpretation message. Returns false on terminal failures
-// (no metadata, ML unrecoverable error) so the caller stops iterating.
-func (tc *TranscribeClient) runOneSummaryPass(ctx context.Context, tacTGID, listKey string, listTTL time.Duration) bool {
+// readTranscripts decodes the whole tac_transcripts list in order. Unparseable entries are
+// dropped (logged) rather than failing the read; only the LRange itself can error.
+func (tc *TranscribeClient) readTranscripts(ctx context.Context, listKey string) ([]ml.TACTranscript, error) {
 	rawEntries, err := tc.dragonflyClient.LRange(ctx, listKey, 0, -1)
 	if err != nil {
-		slog.Warn("live interpretation: LRange failed", slog.String("error", err.Error()), slog.String("tgid", tacTGID))
-		return false
+		return nil, err
 	}
 	transcripts := make([]ml.TACTranscript, 0, len(rawEntries))
 	for _, raw := range rawEntries {
 		var e liveTranscriptEntry
 		if err := json.Unmarshal([]byte(raw), &e); err != nil {
-			slog.Warn("live interpretation: dropping unparseable transcript entry", slog.String("error", err.Error()))
+			slog.Warn("dropping unparseable transcript entry", slog.String("error", err.Error()), slog.String("key", listKey))
 			continue
 		}
-		transcripts = append(transcripts, ml.TACTranscript{CapturedAt: e.CapturedAt, Text: e.Text})
+		transcripts = append(transcripts, ml.TACTranscript{CapturedAt: e.CapturedAt, Text: e.Text, Channel: e.Channel})
 	}
+	return transcripts, nil
+}
 
-	meta, ok := tc.readClosureMeta(ctx, tacTGID)
+// runOneSummaryPass reads the full transcripts list, calls SummarizeRescue, and posts (or
+// chat.update's) the running interpretation message. Returns false on terminal failures
+// (no metadata, ML unrecoverable error) so the caller stops iterating.
+func (tc *TranscribeClient) runOneSummaryPass(ctx context.Context, incidentID, listKey string, listTTL time.Duration) bool {
+	transcripts, err := tc.readTranscripts(ctx, listKey)
+	if err != nil {
+		slog.Warn("live interpretation: LRange failed", slog.String("error", err.Error()), slog.String("incident", incidentID))
+		return false
+	}
+
+	meta, ok := tc.readClosureMeta(ctx, incidentID)
 	if !ok {
 		return false
 	}
@@ -185,69 +200,124 @@ func (tc *TranscribeClient) runOneSummaryPass(ctx context.Context, tacTGID, list
 	// between transmissions. A missing/unparseable prior summary simply degrades to a fresh
 	// (full-rewrite) pass. Also feed the CAD unit roster (empty when enrichment is off) so garbled
 	// callsigns can be canonicalized.
-	previousSummary, _ := tc.readSummaryData(ctx, tacTGID)
-	unitContext := tc.unitContextFor(ctx, tacTGID, meta.Transcription, time.Now())
+	previousSummary, _ := tc.readSummaryData(ctx, incidentID)
+	unitContext := tc.unitContextFor(ctx, incidentID, meta.Transcription, time.Now())
 
-	summary, err := tc.mlClient.SummarizeRescue(ctx, ml.RescueSummaryInput{
-		DispatchTranscription: meta.Transcription,
-		DispatchCallType:      "Rescue - Trail",
-		TACChannel:            meta.TACChannel,
-		TACTranscripts:        transcripts,
-		PreviousSummary:       previousSummary,
-		UnitContext:           unitContext,
-	})
+	// On a long rescue, send only the transmissions the previous summary doesn't already cover
+	// (see live_compaction.go).
+	var compaction summaryCompaction
+	if tc.config.LiveInterpretationCompactionEnabled {
+		before := tc.readSummaryCompaction(ctx, incidentID)
+		compaction = planCompaction(transcripts, before, previousSummary != nil,
+			tc.config.LiveInterpretationCompactMaxTransmissions, tc.config.LiveInterpretationCompactMaxTokens)
+		if compaction.Folded > before.Folded {
+			slog.Info("live interpretation: compacted transcripts into the previous summary",
+				slog.String("incident", incidentID),
+				slog.Int("folded", compaction.Folded),
+				slog.Int("sent", len(transcripts)-compaction.Folded))
+		}
+	}
+
+	input := ml.RescueSummaryInput{
+		DispatchTranscription:  meta.Transcription,
+		DispatchCallType:       "Rescue - Trail",
+		TACChannel:             strings.Join(append([]string{meta.TACChannel}, meta.AdditionalTACChannels()...), ", "),
+		TACTranscripts:         transcripts[compaction.Folded:],
+		PreviousSummary:        previousSummary,
+		CompactedTransmissions: compaction.Folded,
+		UnitContext:            unitContext.PromptBlock(),
+	}
+	// Tagged so token usage is totalled per incident, and the daily budget's throttle (if any)
+	// is applied per rescue.
+	mlCtx := ml.ContextWithIncident(ctx, incidentID)
+	var summary *ml.RescueSummary
+	if tc.config.LiveInterpretationStreamingEnabled {
+		// Show the headline and new key events as the model finishes them. The stream is stopped
+		// before the finished summary publishes, so a late partial can't overwrite it.
+		stream := tc.startLiveStream(ctx, incidentID, meta, previousSummary, listTTL)
+		summary, err = ml.SummarizeRescueStream(mlCtx, tc.mlClient, input, stream.offer)
+		stream.stop(ctx, err != nil)
+	} else {
+		summary, err = tc.mlClient.SummarizeRescue(mlCtx, input)
+	}
 	if err != nil {
+		if errors.Is(err, ml.ErrBudgetThrottled) {
+			slog.Info("live interpretation: skipped; over the daily token budget", slog.String("incident", incidentID))
+			return false
+		}
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			slog.Warn("live interpretation: shutdown interrupted summarize", slog.String("error", err.Error()))
 			return false
 		}
-		slog.Error("live interpretation: SummarizeRescue failed", slog.String("error", err.Error()), slog.String("tgid", tacTGID))
+		slog.Error("live interpretation: SummarizeRescue failed", slog.String("error", err.Error()), slog.String("incident", incidentID))
 		return false
 	}
 
-	tc.publishLiveInterpretation(ctx, tacTGID, meta, summary, listTTL)
+	stored := tc.publishLiveInterpretation(ctx, incidentID, meta, summary, listTTL)
+	if tc.config.LiveInterpretationCompactionEnabled && stored {
+		compaction.Covered = len(transcripts)
+		tc.writeSummaryCompaction(ctx, incidentID, compaction, listTTL)
+	}
 	slog.Info("live interpretation: posted summary",
-		slog.String("tgid", tacTGID),
+		slog.String("incident", incidentID),
 		slog.Int("transcripts_count", len(transcripts)),
 		slog.String("headline", summary.Headline))
 	return true
 }
 
 // publishLiveInterpretation posts (or chat.updates) the running-summary message in the
-// rescue thread. The message_ts is cached in summary_ts:<TGID> with the same TTL as the
-// transcripts list so an active rescue keeps a stable summary anchor.
-func (tc *TranscribeClient) publishLiveInterpretation(ctx context.Context, tacTGID string, meta ClosureMeta, summary *ml.RescueSummary, ttl time.Duration) {
+// rescue thread. The message_ts is cached in summary_ts:<incident> with the same TTL as the
+// transcripts list so an active rescue keeps a stable summary anchor. stored reports whether the
+// summary was saved to summary_data, which compaction needs to trust it as the next pass's base.
+func (tc *TranscribeClient) publishLiveInterpretation(ctx context.Context, incidentID string, meta ClosureMeta, summary *ml.RescueSummary, ttl time.Duration) (stored bool) {
 	// Read the previous SAR-notified state BEFORE overwriting summary_data, so we can detect
 	// the false→true transition and badge the parent alert exactly once (see below).
-	wasNotified := tc.summarySARNotified(ctx, tacTGID)
+	wasNotified := tc.summarySARNotified(ctx, incidentID)
 
 	// Cache the latest structured summary so the close path can prefill the feedback form
 	// without needing to re-run the LLM. Best-effort — if this write fails the live message
 	// still posts; the feedback button will just open with fewer prefilled fields.
 	if encoded, err := json.Marshal(summary); err == nil {
-		if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(summaryDataKeyFmt, tacTGID), ttl, string(encoded)); err != nil {
+		if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(summaryDataKeyFmt, incidentID), ttl, string(encoded)); err != nil {
 			slog.Warn("live interpretation: failed to cache summary_data; feedback prefill may be incomplete",
 				slog.String("error", err.Error()),
-				slog.String("tgid", tacTGID))
+				slog.String("incident", incidentID))
+		} else {
+			stored = true
 		}
 	}
 
 	// On the first transmission that reports SAR notification, badge the parent alert with the
 	// green check. Gated on the false→true transition (via the pre-write read above) so we do
 	// exactly one extra chat.update per rescue, not one per subsequent transmission. SAR
-	// notification is monotonic — the mention stays in the cumulative transcript history — so
-	// once badged it stays badged.
+	// notification is monotonic — the mention stays in the cumulative transcript history (or, once
+	// compacted, in the previous summary) — so once badged it stays badged.
 	if summary.SARNotified && !wasNotified {
-		tc.badgeParentAlertSAR(ctx, tacTGID, meta)
+		tc.badgeParentAlertSAR(ctx, meta)
 	}
 
-	blocks := BuildLiveInterpretationBlocks(summary, time.Now().Local())
-	fallback := summary.Headline
+	// Page leadership for any escalation rule the new summary trips (SAR notified, patient
+	// status, …). Deduplicated per rescue, so re-evaluating on every refresh is cheap.
+	tc.evaluateEscalations(ctx, meta, summary)
+
+	// Offer to close early once the summary says the incident is over (OUTCOME_CLOSE_ENABLED).
+	tc.maybeSuggestClose(ctx, meta, summary)
+
+	// The Slack upsert runs first, then the secondary sinks (notify.go).
+	tc.publishLiveInterpretationEvent(ctx, incidentID, meta, summary, time.Now().Local(), ttl)
+	return stored
+}
+
+// upsertLiveInterpretationMessage chat.updates the rescue's Live Interpretation message, or posts
+// it in the rescue thread and remembers its ts when there isn't one yet. Shared by the finished
+// summary and the streamed partials, which may be the ones that post it first.
+func (tc *TranscribeClient) upsertLiveInterpretationMessage(ctx context.Context, incidentID string, meta ClosureMeta, blocks []slack.Block, headline string, ttl time.Duration) {
+	fallback := headline
 	if fallback == "" {
 		fallback = "Live interpretation updated"
 	}
 
-	tsKey := fmt.Sprintf(summaryTSKeyFmt, tacTGID)
+	tsKey := fmt.Sprintf(summaryTSKeyFmt, incidentID)
 	existingTS, err := tc.dragonflyClient.Get(ctx, tsKey)
 	if err != nil {
 		slog.Warn("live interpretation: failed to read summary_ts; will post a new message", slog.String("error", err.Error()))
@@ -255,16 +325,18 @@ func (tc *TranscribeClient) publishLiveInterpretation(ctx context.Context, tacTG
 	}
 
 	if existingTS != "" {
-		// Update the existing message in-place.
-		if _, _, _, err := tc.slackClient.UpdateMessageContext(ctx,
-			tc.config.SlackChannelID,
-			existingTS,
-			slack.MsgOptionBlocks(blocks...),
-			slack.MsgOptionText(fallback, false),
-		); err != nil {
+		// Update the existing message in-place. Queued updates for the same ts coalesce, so
+		// a burst of transmissions during a Slack outage lands as one update of the newest render.
+		if err := tc.deliverSlack(ctx, outboundSlackMessage{
+			Kind:      slackOpUpdate,
+			TS:        existingTS,
+			Blocks:    slack.Blocks{BlockSet: blocks},
+			Text:      fallback,
+			Talkgroup: incidentID,
+		}); err != nil {
 			slog.Warn("live interpretation: chat.update failed; thread message will be stale until next transmission",
 				slog.String("error", err.Error()),
-				slog.String("tgid", tacTGID))
+				slog.String("incident", incidentID))
 		}
 		return
 	}
@@ -277,13 +349,13 @@ func (tc *TranscribeClient) publishLiveInterpretation(ctx context.Context, tacTG
 			slog.Warn("live interpretation: shutdown interrupted thread post", slog.String("error", err.Error()))
 			return
 		}
-		slog.Error("live interpretation: thread post failed", slog.String("error", err.Error()), slog.String("tgid", tacTGID))
+		slog.Error("live interpretation: thread post failed", slog.String("error", err.Error()), slog.String("incident", incidentID))
 		return
 	}
 	if err := tc.dragonflyClient.Set(ctx, tsKey, ttl, postedTS); err != nil {
 		slog.Warn("live interpretation: failed to persist summary_ts; next update will post a duplicate",
 			slog.String("error", err.Error()),
-			slog.String("tgid", tacTGID))
+			slog.String("incident", incidentID))
 	}
 }
 
@@ -296,42 +368,44 @@ func (tc *TranscribeClient) publishLiveInterpretation(ctx context.Context, tacTG
 // "Expires …" line stays accurate — including after an Extend — without threading the expiry
 // through ClosureMeta. If the expiry can't be read (rescue already closing/closed), skip
 // rather than render a bogus timestamp.
-func (tc *TranscribeClient) badgeParentAlertSAR(ctx context.Context, tgid string, meta ClosureMeta) {
+func (tc *TranscribeClient) badgeParentAlertSAR(ctx context.Context, meta ClosureMeta) {
 	if meta.MessageTS == "" || meta.Transcription == "" {
 		return // no message to update, or can't rebuild the alert faithfully
 	}
 
-	score, err := tc.dragonflyClient.ZScore(ctx, activeTACsKey, tgid)
+	score, err := tc.dragonflyClient.ZScore(ctx, activeTACsKey, meta.IncidentID)
 	if err != nil {
 		slog.Warn("live interpretation: SAR badge skipped; could not read expiry from active_tacs",
-			slog.String("error", err.Error()), slog.String("tgid", tgid))
+			slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
 		return
 	}
 	expiresAt := time.Unix(int64(score), 0).Local()
 
 	blocks := BuildRescueTrailBlocks(&RescueTrailBlocksInput{
-		TACChannel:        meta.TACChannel,
-		TranscriptionText: meta.Transcription,
-		ExpiresAt:         expiresAt,
-		DispatchTGID:      FireDispatch1TGID,
-		TACTalkgroupTGID:  meta.TGID, // keeps the Cancel/Close/Extend/Switch actions on the live alert
-		SARNotified:       true,
+		TACChannel:            meta.TACChannel,
+		TranscriptionText:     meta.Transcription,
+		ExpiresAt:             expiresAt,
+		DispatchTGID:          FireDispatch1TGID,
+		TACTalkgroupTGID:      meta.TGID, // keeps the Cancel/Close/Extend/Switch actions on the live alert
+		IncidentID:            meta.IncidentID,
+		SARNotified:           true,
+		AdditionalTACChannels: meta.AdditionalTACChannels(),
+		MapLink:               meta.MapLink,
 	})
 
-	updateCtx, cancel := context.WithTimeout(ctx, tc.config.SlackTimeout)
-	defer cancel()
-	if _, _, _, err := tc.slackClient.UpdateMessageContext(updateCtx,
-		tc.config.SlackChannelID,
-		meta.MessageTS,
-		slack.MsgOptionBlocks(blocks...),
-		slack.MsgOptionText(fmt.Sprintf("%s — Search & Rescue notified", meta.TACChannel), false),
-	); err != nil {
+	if err := tc.deliverSlack(ctx, outboundSlackMessage{
+		Kind:      slackOpUpdate,
+		TS:        meta.MessageTS,
+		Blocks:    slack.Blocks{BlockSet: blocks},
+		Text:      fmt.Sprintf("%s — Search & Rescue notified", meta.TACChannel),
+		Talkgroup: meta.TGID,
+	}); err != nil {
 		slog.Warn("live interpretation: failed to badge parent alert with SAR-notified",
-			slog.String("error", err.Error()), slog.String("tgid", tgid), slog.String("message_ts", meta.MessageTS))
+			slog.String("error", err.Error()), slog.String("incident", meta.IncidentID), slog.String("message_ts", meta.MessageTS))
 		return
 	}
 	slog.Info("live interpretation: badged parent alert — SAR notified",
-		slog.String("tgid", tgid), slog.String("tac", meta.TACChannel))
+		slog.String("incident", meta.IncidentID), slog.String("tac", meta.TACChannel))
 }
 
 // sendSlackInThread is a convenience wrapper that goes through sendSlackWithRetry and also
@@ -347,21 +421,21 @@ func (tc *TranscribeClient) sendSlackInThread(ctx context.Context, talkgroup, th
 	return tc.config.SlackChannelID, ts, err
 }
 
-// readClosureMeta reads tac_meta:<TGID> and JSON-decodes it. Returns ok=false (no error)
+// readClosureMeta reads tac_meta:<incident> and JSON-decodes it. Returns ok=false (no error)
 // when the metadata is missing — the rescue has likely been cancelled or auto-expired and
 // any further work is a no-op.
-func (tc *TranscribeClient) readClosureMeta(ctx context.Context, tgid string) (ClosureMeta, bool) {
-	raw, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(tacMetaKeyFmt, tgid))
+func (tc *TranscribeClient) readClosureMeta(ctx context.Context, incidentID string) (ClosureMeta, bool) {
+	raw, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(tacMetaKeyFmt, incidentID))
 	if err != nil {
-		slog.Warn("live interpretation: failed to read tac_meta", slog.String("error", err.Error()), slog.String("tgid", tgid))
+		slog.Warn("live interpretation: failed to read tac_meta", slog.String("error", err.Error()), slog.String("incident", incidentID))
 		return ClosureMeta{}, false
 	}
 	if raw == "" {
 		return ClosureMeta{}, false
 	}
-	var meta ClosureMeta
-	if err := json.Unmarshal([]byte(raw), &meta); err != nil {
-		slog.Warn("live interpretation: tac_meta unparseable", slog.String("error", err.Error()), slog.String("tgid", tgid))
+	meta, err := decodeClosureMeta(incidentID, raw)
+	if err != nil {
+		slog.Warn("live interpretation: tac_meta unparseable", slog.String("error", err.Error()), slog.String("incident", incidentID))
 		return ClosureMeta{}, false
 	}
 	return meta, true
@@ -0,0 +1,162 @@
+package transcribe
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+)
+
+// Streamed live interpretation (LIVE_INTERPRETATION_STREAMING_ENABLED): while the summary pass
+// streams SummarizeRescue, the headline and any new key events are shown as soon as the model
+// finishes writing them instead of after the whole response, which on a long rescue can take
+// most of the summary lock's TTL.
+//
+// Partials arrive on the ML client's goroutine, once per completed field. Publishing them there
+// would stall the stream behind Slack, so offer only parks the newest render and a separate
+// goroutine publishes it, at most once per LiveInterpretationStreamInterval (chat.update is rate
+// limited per workspace, and a burst of renders would only be coalesced away anyway). Only the
+// render is streamed: escalations, the SAR badge, summary_data and notifiers all wait for the
+// finished summary, so nothing acts on a half-written one.
+
+// liveStream is one summary pass's streamed updates.
+type liveStream struct {
+	tc         *TranscribeClient
+	incidentID string
+	meta       ClosureMeta
+	previous   *ml.RescueSummary
+	ttl        time.Duration
+	interval   time.Duration
+
+	mu      sync.Mutex
+	pending *ml.RescueSummary // newest render not yet published; nil when caught up
+	offered *ml.RescueSummary // newest render offered, to drop partials that add nothing
+	shown   *ml.RescueSummary // last render published, for the interrupted footer
+
+	cad     *LiveInterpretationCAD // looked up on first publish; CAD is a network call
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// startLiveStream starts publishing partials for one summary pass. previous is the summary the
+// pass extends (nil on the first pass); partials are shown on top of it.
+func (tc *TranscribeClient) startLiveStream(ctx context.Context, incidentID string, meta ClosureMeta, previous *ml.RescueSummary, ttl time.Duration) *liveStream {
+	s := &liveStream{
+		tc:         tc,
+		incidentID: incidentID,
+		meta:       meta,
+		previous:   previous,
+		ttl:        ttl,
+		interval:   tc.config.LiveInterpretationStreamInterval,
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	go s.run(ctx)
+	return s
+}
+
+// offer is the ml.StreamingRescueSummarizer callback. It never blocks on Slack.
+func (s *liveStream) offer(partial *ml.RescueSummary) {
+	s.mu.Lock()
+	base := s.offered
+	if base == nil {
+		base = s.previous
+	}
+	render, changed := mergePartialSummary(base, partial)
+	if changed {
+		s.offered, s.pending = render, render
+	}
+	s.mu.Unlock()
+	if !changed {
+		return
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// stop ends the stream and waits for an in-flight publish, so the caller's finished summary is
+// the last write to the message. failed marks the render already shown (if any) as interrupted,
+// since no finished summary is coming to replace its "updating" footer.
+func (s *liveStream) stop(ctx context.Context, failed bool) {
+	close(s.done)
+	<-s.stopped
+	if failed && s.shown != nil {
+		s.publish(ctx, s.shown, true)
+	}
+}
+
+func (s *liveStream) run(ctx context.Context) {
+	defer close(s.stopped)
+	for {
+		select {
+		case <-s.wake:
+		case <-s.done:
+			return
+		case <-ctx.Done():
+			return
+		}
+		s.mu.Lock()
+		render := s.pending
+		s.pending = nil
+		s.mu.Unlock()
+		if render == nil {
+			continue
+		}
+		s.publish(ctx, render, false)
+		s.shown = render
+
+		select {
+		case <-time.After(s.interval):
+		case <-s.done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *liveStream) publish(ctx context.Context, render *ml.RescueSummary, interrupted bool) {
+	if s.cad == nil {
+		cad := s.tc.liveInterpretationCAD(ctx, s.incidentID, s.meta)
+		s.cad = &cad
+	}
+	mapLink := s.tc.mapLinkFor(render.LocationDetail)
+	if mapLink == nil {
+		mapLink = s.meta.MapLink
+	}
+	blocks := BuildLiveInterpretationStreamingBlocks(render, mapLink, *s.cad, interrupted)
+	s.tc.upsertLiveInterpretationMessage(ctx, s.incidentID, s.meta, blocks, render.Headline, s.ttl)
+}
+
+// mergePartialSummary lays a streamed partial over base (the previous pass's summary, or the
+// last render): a new headline, and the partial's key events once it has more than base, which
+// is how new events show up when the model extends the previous summary. The other fields keep
+// base's values until the finished summary lands; a half-streamed summary would otherwise blank
+// them. Key events whose description hasn't streamed yet are ignored. changed is false when the
+// partial adds nothing to base.
+func mergePartialSummary(base, partial *ml.RescueSummary) (render *ml.RescueSummary, changed bool) {
+	out := ml.RescueSummary{}
+	if base != nil {
+		out = *base
+	}
+	if partial.Headline != "" && partial.Headline != out.Headline {
+		out.Headline = partial.Headline
+		changed = true
+	}
+	var events []ml.RescueSummaryEvent
+	for _, e := range partial.KeyEvents {
+		if e.Description != "" {
+			events = append(events, e)
+		}
+	}
+	if len(events) > len(out.KeyEvents) {
+		out.KeyEvents = events
+		changed = true
+	}
+	return &out, changed
+}
@@ -0,0 +1,44 @@
+package transcribe
+
+import (
+	"testing"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePartialSummary(t *testing.T) {
+	previous := &ml.RescueSummary{
+		Headline:         "Hiker down on Mailbox Peak",
+		SituationSummary: "Engine 8171 en route.",
+		KeyEvents:        []ml.RescueSummaryEvent{{CapturedAt: "13:02", Description: "E8171 at trailhead"}},
+	}
+
+	// Nothing finished streaming yet: no update.
+	_, changed := mergePartialSummary(previous, &ml.RescueSummary{})
+	assert.False(t, changed)
+
+	// The model re-emits the established event; not new.
+	_, changed = mergePartialSummary(previous, &ml.RescueSummary{KeyEvents: previous.KeyEvents})
+	assert.False(t, changed)
+
+	// A new headline keeps the previous pass's other fields.
+	render, changed := mergePartialSummary(previous, &ml.RescueSummary{Headline: "Patient contact on Mailbox Peak"})
+	assert.True(t, changed)
+	assert.Equal(t, "Patient contact on Mailbox Peak", render.Headline)
+	assert.Equal(t, "Engine 8171 en route.", render.SituationSummary)
+	assert.Len(t, render.KeyEvents, 1)
+	assert.Equal(t, "Hiker down on Mailbox Peak", previous.Headline, "previous isn't modified")
+
+	// A new event shows once its description has streamed.
+	_, changed = mergePartialSummary(previous, &ml.RescueSummary{KeyEvents: append(previous.KeyEvents, ml.RescueSummaryEvent{CapturedAt: "13:20"})})
+	assert.False(t, changed)
+	render, changed = mergePartialSummary(previous, &ml.RescueSummary{KeyEvents: append(previous.KeyEvents, ml.RescueSummaryEvent{CapturedAt: "13:20", Description: "Patient contact"})})
+	assert.True(t, changed)
+	assert.Len(t, render.KeyEvents, 2)
+
+	// First pass: no previous summary.
+	render, changed = mergePartialSummary(nil, &ml.RescueSummary{Headline: "Hiker down"})
+	assert.True(t, changed)
+	assert.Equal(t, "Hiker down", render.Headline)
+}
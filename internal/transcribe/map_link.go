@@ -0,0 +1,54 @@
+package transcribe
+
+import (
+	"fmt"
+
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/slack-go/slack"
+)
+
+// Map links (MAP_LINKS_ENABLED): the dispatch parser and summarizer return a
+// StructuredLocation alongside their free-text location; the gazetteer turns it into a point
+// and the point into a CalTopo or OpenStreetMap URL. The alert's link comes from the dispatch
+// and is stored in ClosureMeta so every re-render keeps it; the live interpretation resolves
+// the latest summary's location on each pass and falls back to the dispatch link.
+
+// MapLink is a resolved map button: what the point is and where it opens.
+type MapLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// mapLinkFor resolves loc to a map link, or nil when map links are off or nothing in loc is
+// recognizable.
+func (tc *TranscribeClient) mapLinkFor(loc ml.StructuredLocation) *MapLink {
+	if !tc.config.MapLinksEnabled || tc.gazetteer == nil {
+		return nil
+	}
+	p, ok := tc.gazetteer.Resolve(loc)
+	if !ok {
+		return nil
+	}
+	label := p.Label + " (approx.)"
+	if p.Spoken {
+		label = fmt.Sprintf("Reported coordinates %.5f, %.5f", p.Lat, p.Lon)
+	}
+	return &MapLink{Label: label, URL: gazetteer.MapURL(tc.config.MapLinkProvider, p)}
+}
+
+// buildMapLinkBlock renders the "Map: <label>" section with its URL button, shared by the
+// alert and the live interpretation. A URL button, so no slackctl routing.
+func buildMapLinkBlock(link *MapLink) slack.Block {
+	return slack.NewSectionBlock(
+		slack.NewTextBlockObject(slack.MarkdownType, "*Map:* "+link.Label, false, false),
+		nil,
+		slack.NewAccessory(
+			slack.NewButtonBlockElement(
+				ActionIDMapLink,
+				"",
+				slack.NewTextBlockObject(slack.PlainTextType, ":world_map: Open Map", true, false),
+			).WithURL(link.URL),
+		),
+	)
+}
@@ -0,0 +1,30 @@
+package transcribe
+
+import (
+	"testing"
+
+	"github.com/searchandrescuegg/transcribe/internal/config"
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapLinkFor(t *testing.T) {
+	places, err := gazetteer.Load("")
+	require.NoError(t, err)
+	tc := &TranscribeClient{config: &config.Config{MapLinksEnabled: true, MapLinkProvider: gazetteer.ProviderCalTopo}, gazetteer: places}
+
+	link := tc.mapLinkFor(ml.StructuredLocation{Trailhead: "Mt. Si trailhead"})
+	require.NotNil(t, link)
+	assert.Equal(t, "Mount Si Trailhead (approx.)", link.Label)
+	assert.Contains(t, link.URL, "https://caltopo.com/map.html#ll=47.48790,-121.72320")
+
+	link = tc.mapLinkFor(ml.StructuredLocation{Trailhead: "Mount Si Trailhead", Latitude: 47.51234, Longitude: -121.7})
+	require.NotNil(t, link)
+	assert.Equal(t, "Reported coordinates 47.51234, -121.70000", link.Label)
+
+	assert.Nil(t, tc.mapLinkFor(ml.StructuredLocation{Landmark: "the big rock"}))
+	assert.Nil(t, (&TranscribeClient{config: &config.Config{}, gazetteer: places}).mapLinkFor(ml.StructuredLocation{Trailhead: "Mount Si Trailhead"}),
+		"MAP_LINKS_ENABLED=false means no link")
+}
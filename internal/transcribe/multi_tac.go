@@ -0,0 +1,32 @@
+package transcribe
+
+import "slices"
+
+// Multi-TAC incidents: a large rescue often runs command on one TAC and the SAR team on
+// another. ClosureMeta.TGID is the primary TAC (the one the alert names and Switch moves);
+// TACs attached from the alert's "Add TAC" control are recorded in AdditionalTGIDs. Every TAC
+// on the incident gets the same talkgroup-scoped routing — allow-list membership, tg:<TGID>
+// and the tac_incident index (see incident.go) — so transmissions on any of them reply into
+// the one thread and feed the one transcript list.
+
+// TGIDs returns the primary TGID followed by any additional TACs.
+func (m ClosureMeta) TGIDs() []string {
+	return append([]string{m.TGID}, m.AdditionalTGIDs...)
+}
+
+// AdditionalTACChannels returns the radio short codes ("TAC8") of the additional TACs,
+// skipping any TGID the talkgroup table doesn't know.
+func (m ClosureMeta) AdditionalTACChannels() []string {
+	channels := make([]string, 0, len(m.AdditionalTGIDs))
+	for _, tgid := range m.AdditionalTGIDs {
+		if tg, ok := talkgroupFromTGID[tgid]; ok {
+			channels = append(channels, tg.RadioShortCode)
+		}
+	}
+	return channels
+}
+
+// HasTGID reports whether tgid is the primary or one of the additional TACs.
+func (m ClosureMeta) HasTGID(tgid string) bool {
+	return m.TGID == tgid || slices.Contains(m.AdditionalTGIDs, tgid)
+}
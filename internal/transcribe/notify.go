@@ -0,0 +1,203 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/searchandrescuegg/transcribe/internal/notify"
+	"github.com/slack-go/slack"
+)
+
+// Incident events go out through one notify.FanOut: the primary Slack channel (slackNotifier,
+// below) first, then the secondary sinks (NOTIFY_SINKS), which the fan-out bounds by
+// NOTIFY_TIMEOUT and whose failures it logs. The publish helpers return only the primary's
+// error, for the callers that act on it; the secondaries never affect the primary write.
+//
+// Writes that aren't incident events — the streamed live-interpretation partials, the SAR
+// badge, closing warnings, close prompts, after-action reports — stay on the direct Slack path.
+
+// newNotifier puts the primary Slack sink in front of secondary, or of an empty fan-out when no
+// secondary sinks are configured.
+func newNotifier(tc *TranscribeClient, secondary *notify.FanOut) *notify.FanOut {
+	if secondary == nil {
+		secondary = notify.NewFanOut(tc.config.NotifyTimeout)
+	}
+	return secondary.WithPrimary(&slackNotifier{tc: tc})
+}
+
+// primaryErr drops secondary-sink failures (already logged by the fan-out) from a delivery
+// error, leaving the primary's.
+func primaryErr(err error) error {
+	if errors.Is(err, notify.ErrPrimary) {
+		return err
+	}
+	return nil
+}
+
+// slackAlert is the Origin of an Alert event: what the rescue alert needs beyond the wire
+// payload, plus the ts of the posted alert, which the sink fills in.
+type slackAlert struct {
+	// Talkgroup is the dispatch talkgroup the page was heard on (the Slack pacing key).
+	Talkgroup string
+	// TGID is the TAC's talkgroup, which enables the alert's Cancel/Extend buttons.
+	TGID    string
+	MapLink *MapLink
+
+	// ThreadTS is set by the sink: the alert is the rescue thread's parent.
+	ThreadTS string
+}
+
+// slackThreadReply is the Origin of a ThreadReply event.
+type slackThreadReply struct {
+	ThreadTS  string
+	Talkgroup string
+	// ParsedKey carries the clip for SLACK_AUDIO_MODE=upload; nil skips the upload.
+	ParsedKey *AdornedDeconstructedKey
+	Title     string
+	// Blocks replaces the rendered transmission (a re-page carries its own, with the Split
+	// prompt).
+	Blocks []slack.Block
+}
+
+// slackLiveInterpretation is the Origin of a LiveInterpretation event.
+type slackLiveInterpretation struct {
+	Meta ClosureMeta
+	TTL  time.Duration
+}
+
+// publishAlert posts the rescue alert and notifies the secondaries. Returns the thread the
+// alert opened; an error means the alert didn't post and nothing else was notified.
+func (tc *TranscribeClient) publishAlert(ctx context.Context, incidentID string, tg TalkgroupInformation, origin *slackAlert, callType, transcription string, dispatchedAt, expiresAt time.Time) (string, error) {
+	err := tc.notifier.Alert(ctx, &notify.Alert{
+		Incident:      notify.Incident{ID: incidentID, TACChannel: tg.RadioShortCode, Origin: origin},
+		CallType:      callType,
+		Transcription: transcription,
+		DispatchedAt:  dispatchedAt,
+		ExpiresAt:     expiresAt,
+		ListenURL:     buildOpenMHzURL([]string{tg.TGID, FireDispatch1TGID}),
+	})
+	if err := primaryErr(err); err != nil {
+		return "", err
+	}
+	return origin.ThreadTS, nil
+}
+
+// publishThreadReply posts a thread reply and notifies the secondaries.
+func (tc *TranscribeClient) publishThreadReply(ctx context.Context, meta ClosureMeta, origin *slackThreadReply, channel, message string, at time.Time, audioURL string) error {
+	return primaryErr(tc.notifier.ThreadReply(ctx, &notify.ThreadReply{
+		Incident: notify.Incident{ID: meta.IncidentID, TACChannel: meta.TACChannel, Origin: origin},
+		Channel:  channel,
+		Message:  message,
+		At:       at,
+		AudioURL: audioURL,
+	}))
+}
+
+// publishLiveInterpretationEvent posts or updates the Live Interpretation message, then
+// notifies the secondaries. Best-effort: the Slack sink logs its own failures.
+func (tc *TranscribeClient) publishLiveInterpretationEvent(ctx context.Context, incidentID string, meta ClosureMeta, summary *ml.RescueSummary, updatedAt time.Time, ttl time.Duration) {
+	_ = tc.notifier.LiveInterpretation(ctx, &notify.LiveInterpretation{
+		Incident:  notify.Incident{ID: incidentID, TACChannel: meta.TACChannel, Origin: &slackLiveInterpretation{Meta: meta, TTL: ttl}},
+		Summary:   summary,
+		UpdatedAt: updatedAt,
+	})
+}
+
+// publishClosed closes the rescue in Slack (alert rewrite and Channel Closed reply), then
+// notifies the secondaries. Best-effort: the Slack sink logs its own failures.
+func (tc *TranscribeClient) publishClosed(ctx context.Context, m *ClosureMeta, closedAt time.Time) {
+	_ = tc.notifier.Closed(ctx, &notify.Closed{
+		Incident: notify.Incident{ID: m.IncidentID, TACChannel: m.TACChannel, Origin: m},
+		Reason:   notify.ClosedEnded,
+		ClosedAt: closedAt,
+	})
+}
+
+// slackNotifier is the primary sink: the bot-token Slack channel with the rescue thread. It
+// renders each event from its payload and Origin and writes it through the same paths as
+// before (retries, the outbound queue, audio uploads). Alert and ThreadReply return their
+// failures so the Pulsar message is redelivered; LiveInterpretation and Closed log them and
+// return nil, because neither is retried and the secondaries should still hear about them.
+type slackNotifier struct {
+	tc *TranscribeClient
+}
+
+func (n *slackNotifier) Name() string { return "slack" }
+
+func (n *slackNotifier) Alert(ctx context.Context, e *notify.Alert) error {
+	o, ok := e.Origin.(*slackAlert)
+	if !ok {
+		return fmt.Errorf("alert for %s has no Slack origin", e.ID)
+	}
+	ts, err := n.tc.sendSlackWithRetry(ctx, o.Talkgroup,
+		slack.MsgOptionBlocks(BuildRescueTrailBlocks(&RescueTrailBlocksInput{
+			TACChannel:        e.TACChannel,
+			TranscriptionText: e.Transcription,
+			ExpiresAt:         e.ExpiresAt,
+			DispatchTGID:      FireDispatch1TGID,
+			TACTalkgroupTGID:  o.TGID, // enables the slackctl controller's Cancel/Extend buttons
+			IncidentID:        e.ID,
+			MapLink:           o.MapLink,
+		})...))
+	if err != nil {
+		return err
+	}
+	o.ThreadTS = ts
+	return nil
+}
+
+func (n *slackNotifier) ThreadReply(ctx context.Context, e *notify.ThreadReply) error {
+	o, ok := e.Origin.(*slackThreadReply)
+	if !ok {
+		return fmt.Errorf("thread reply for %s has no Slack origin", e.ID)
+	}
+	blocks := o.Blocks
+	if blocks == nil {
+		blocks = BuildThreadCommunicationBlocks(&ThreadCommunicationBlocksInput{
+			Channel:  e.Channel,
+			Message:  e.Message,
+			TS:       e.At,
+			AudioURL: e.AudioURL,
+		})
+	}
+	if o.ParsedKey != nil && n.tc.uploadTransmissionReply(ctx, o.ParsedKey, o.ThreadTS, o.Title, blocks) {
+		return nil
+	}
+	return n.tc.deliverSlack(ctx, outboundSlackMessage{
+		Kind:      slackOpPost,
+		Blocks:    slack.Blocks{BlockSet: blocks},
+		AsUser:    true,
+		ThreadTS:  o.ThreadTS,
+		Talkgroup: o.Talkgroup,
+	})
+}
+
+func (n *slackNotifier) LiveInterpretation(ctx context.Context, e *notify.LiveInterpretation) error {
+	o, ok := e.Origin.(*slackLiveInterpretation)
+	if !ok {
+		slog.Error("live interpretation: event has no Slack origin", slog.String("incident", e.ID))
+		return nil
+	}
+	// Prefer where the TAC traffic says the patient is now; fall back to the dispatch location.
+	mapLink := n.tc.mapLinkFor(e.Summary.LocationDetail)
+	if mapLink == nil {
+		mapLink = o.Meta.MapLink
+	}
+	blocks := BuildLiveInterpretationBlocks(e.Summary, e.UpdatedAt, mapLink, n.tc.liveInterpretationCAD(ctx, e.ID, o.Meta))
+	n.tc.upsertLiveInterpretationMessage(ctx, e.ID, o.Meta, blocks, e.Summary.Headline, o.TTL)
+	return nil
+}
+
+func (n *slackNotifier) Closed(ctx context.Context, e *notify.Closed) error {
+	m, ok := e.Origin.(*ClosureMeta)
+	if !ok {
+		slog.Error("sweeper: closed event has no Slack origin", slog.String("incident", e.ID))
+		return nil
+	}
+	n.tc.postChannelClosed(ctx, m, e.ClosedAt)
+	return nil
+}
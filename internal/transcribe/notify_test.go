@@ -0,0 +1,61 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/config"
+	"github.com/searchandrescuegg/transcribe/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink is a secondary sink that records the alerts it receives.
+type recordingSink struct {
+	alerts []*notify.Alert
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+func (s *recordingSink) Alert(_ context.Context, e *notify.Alert) error {
+	s.alerts = append(s.alerts, e)
+	return nil
+}
+func (s *recordingSink) ThreadReply(context.Context, *notify.ThreadReply) error { return nil }
+func (s *recordingSink) LiveInterpretation(context.Context, *notify.LiveInterpretation) error {
+	return nil
+}
+func (s *recordingSink) Closed(context.Context, *notify.Closed) error { return nil }
+
+func TestPublishAlert_SlackIsThePrimarySink(t *testing.T) {
+	cfg := &config.Config{SlackChannelID: "C-TEST", SlackTimeout: time.Second, SlackSendMaxAttempts: 1, NotifyTimeout: time.Second}
+	tg := TalkgroupInformation{TGID: "1399", RadioShortCode: "FTAC 3"}
+	now := time.Now()
+
+	slackMock := new(mockSlackPoster)
+	sink := &recordingSink{}
+	tc := newTranscribeClientForTest(cfg, nil, nil, nil, nil, slackMock, nil)
+	secondary := notify.NewFanOut(cfg.NotifyTimeout)
+	secondary.Add(sink)
+	tc.notifier = newNotifier(tc, secondary)
+
+	// The alert posts to Slack first; its ts opens the thread and the secondary hears about it.
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).Return("C-TEST", "111.222", "", nil).Once()
+	ts, err := tc.publishAlert(context.Background(), "inc-1", tg, &slackAlert{Talkgroup: FireDispatch1TGID, TGID: tg.TGID},
+		"Rescue Trail", "rescue trail tac 3", now, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "111.222", ts)
+	require.Len(t, sink.alerts, 1)
+	assert.Equal(t, "inc-1", sink.alerts[0].ID)
+	assert.Equal(t, "FTAC 3", sink.alerts[0].TACChannel)
+
+	// A failed Slack post fails the alert and keeps it from the secondaries.
+	slackMock.On("SendMessageContext", mock.Anything, "C-TEST", mock.Anything).Return("", "", "", errors.New("boom")).Once()
+	_, err = tc.publishAlert(context.Background(), "inc-2", tg, &slackAlert{Talkgroup: FireDispatch1TGID, TGID: tg.TGID},
+		"Rescue Trail", "rescue trail tac 3", now, now.Add(time.Hour))
+	require.ErrorIs(t, err, notify.ErrPrimary)
+	assert.Len(t, sink.alerts, 1)
+	slackMock.AssertExpectations(t)
+}
@@ -0,0 +1,18 @@
+package transcribe
+
+import "log/slog"
+
+// snapPlaceNames runs the gazetteer's near-miss pre-pass (PLACE_NAME_SNAP_ENABLED) over ASR
+// text, logging every substitution so a wrong snap can be traced back to its recording. The
+// rules are deliberately strict (see gazetteer.Snap); anything they don't catch is still left
+// to the model and the place-name reference in its prompt.
+func (tc *TranscribeClient) snapPlaceNames(text, key string) string {
+	if !tc.config.PlaceNameSnapEnabled || tc.gazetteer == nil {
+		return text
+	}
+	snapped, subs := tc.gazetteer.Snap(text)
+	for _, sub := range subs {
+		slog.Info("place-name snap", slog.String("from", sub.From), slog.String("to", sub.To), slog.String("key", key))
+	}
+	return snapped
+}
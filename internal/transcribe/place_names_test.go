@@ -0,0 +1,22 @@
+package transcribe
+
+import (
+	"testing"
+
+	"github.com/searchandrescuegg/transcribe/internal/config"
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapPlaceNames(t *testing.T) {
+	places, err := gazetteer.Load("")
+	require.NoError(t, err)
+	const raw = "Aid 181 respond Rescue Trail, Mount Sai trailhead, TAC 3"
+
+	off := &TranscribeClient{config: &config.Config{}, gazetteer: places}
+	assert.Equal(t, raw, off.snapPlaceNames(raw, "k"), "PLACE_NAME_SNAP_ENABLED=false leaves the text alone")
+
+	on := &TranscribeClient{config: &config.Config{PlaceNameSnapEnabled: true}, gazetteer: places}
+	assert.Equal(t, "Aid 181 respond Rescue Trail, Mount Si Trailhead, TAC 3", on.snapPlaceNames(raw, "k"))
+}
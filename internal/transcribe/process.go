@@ -81,8 +81,13 @@ func (tc *TranscribeClient) processDispatchCall(ctx context.Context, parsedKey *
 	// here would overwrite the tg:<TGID> routing key, stealing TAC traffic + live interpretation
 	// away from the original thread and orphaning it (with no safe way to act on the old alert).
 	// Instead, refresh the activation window and note the re-page in the existing thread.
-	if meta, active := tc.readClosureMeta(ctx, tg.TGID); active {
-		return tc.handleAdditionalDispatch(ctx, parsedKey, tr, meta)
+	//
+	// The TGID→incident index also covers a TAC that is an additional channel on another rescue
+	// (multi-TAC), in which case the re-page belongs to that rescue's thread. HasTGID guards the
+	// legacy fallback: a pre-incident-ID rescue that was switched away from this TAC still lives
+	// under tac_meta:<this TGID> but no longer owns it.
+	if meta, active := tc.readClosureMeta(ctx, tc.incidentIDFor(ctx, tg.TGID)); active && meta.HasTGID(tg.TGID) {
+		return tc.handleAdditionalDispatch(ctx, parsedKey, tr, meta, tg, dispatchMessage)
 	}
 
 	err = tc.dragonflyClient.SAddEx(ctx, "allowed_talkgroups", tc.config.TacticalChannelActivationDuration, tg.TGID)
@@ -92,20 +97,23 @@ func (tc *TranscribeClient) processDispatchCall(ctx context.Context, parsedKey *
 
 	slog.Info("added TAC channel to allowed talkgroups", slog.String("tac_channel", dispatchMessage.TACChannel), slog.Any("talkgroup", tg), slog.String("message_hash", selectedMessageHash))
 
-	expiresAt := time.Now().Add(tc.config.TacticalChannelActivationDuration).Local()
+	// TAC_MAX_LIFETIME caps even the first window (closure_policy.go).
+	expiresAt := ClosureMeta{DispatchedAt: parsedKey.dk.Time}.
+		CapToLifetime(time.Now().Add(tc.config.TacticalChannelActivationDuration), tc.config.TACMaxLifetime).Local()
+	// Minted before the alert posts so its buttons can carry it.
+	incidentID := newIncidentID()
+	mapLink := tc.mapLinkFor(dispatchMessage.Location)
 
 	// FIX (review item #1): sendSlackWithRetry actually retries after RetryAfter on 429s,
 	// where the previous handleSlackRateLimit waited and silently dropped the message.
 	// FIX (review item #1, follow-on): if the post fails entirely, we now bail out instead of
 	// continuing to schedule a TAC closure against an empty thread_ts.
-	tsThread, err := tc.sendSlackWithRetry(ctx, parsedKey.dk.Talkgroup,
-		slack.MsgOptionBlocks(BuildRescueTrailBlocks(&RescueTrailBlocksInput{
-			TACChannel:        dispatchMessage.TACChannel,
-			TranscriptionText: tr.Transcription,
-			ExpiresAt:         expiresAt,
-			DispatchTGID:      FireDispatch1TGID,
-			TACTalkgroupTGID:  tg.TGID, // enables the slackctl controller's Cancel/Extend buttons
-		})...))
+	// The alert goes out through the notify fan-out: the Slack post first, then the secondary
+	// sinks, which are skipped when the post fails. TAC traffic that lands while the secondaries
+	// are still delivering finds no tg:<TGID> yet and is Nacked for redelivery, as before.
+	tsThread, err := tc.publishAlert(ctx, incidentID, tg,
+		&slackAlert{Talkgroup: parsedKey.dk.Talkgroup, TGID: tg.TGID, MapLink: mapLink},
+		dispatchMessage.CallType, tr.Transcription, parsedKey.dk.Time, expiresAt)
 	if err != nil {
 		return fmt.Errorf("%w: %s", ErrFailedToPostSlackMessage, err.Error())
 	}
@@ -117,18 +125,25 @@ func (tc *TranscribeClient) processDispatchCall(ctx context.Context, parsedKey *
 		slog.Error("failed to set TAC channel in Dragonfly", slog.String("error", err.Error()))
 	}
 
-	slog.Debug("set TAC channel in Dragonfly", slog.String("tac_channel", dispatchMessage.TACChannel), slog.String("thread_id", tsThread))
+	if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(tacIncidentKeyFmt, tg.TGID), tc.config.TacticalChannelActivationDuration, incidentID); err != nil {
+		slog.Error("failed to index TAC channel to incident", slog.String("error", err.Error()), slog.String("incident", incidentID))
+	}
+
+	slog.Debug("set TAC channel in Dragonfly", slog.String("tac_channel", dispatchMessage.TACChannel), slog.String("thread_id", tsThread), slog.String("incident", incidentID))
 
 	// FIX (review item #10 / option B): persisted ZSET entry replaces in-process time.AfterFunc.
 	// Previously a process restart would silently drop the scheduled "channel closed" Slack message;
 	// the sweeper goroutine now picks it up after restart based on the recorded expiry.
 	if err := tc.ScheduleTACClosure(ctx, ClosureMeta{
+		IncidentID:      incidentID,
 		TGID:            tg.TGID,
 		TACChannel:      dispatchMessage.TACChannel,
 		ThreadTS:        tsThread,
 		SourceTalkgroup: parsedKey.dk.Talkgroup,
 		MessageTS:       tsThread, // alert is the thread parent; ts == thread_ts for chat.update later
 		Transcription:   tr.Transcription,
+		DispatchedAt:    parsedKey.dk.Time,
+		MapLink:         mapLink,
 	}, expiresAt); err != nil {
 		slog.Error("failed to persist TAC closure schedule", slog.String("error", err.Error()), slog.String("tac_channel", dispatchMessage.TACChannel))
 	}
@@ -137,8 +152,10 @@ func (tc *TranscribeClient) processDispatchCall(ctx context.Context, parsedKey *
 	// disabled). Resolving here — right after we know the incident is a trail rescue — means the
 	// first TAC transmission already has a unit roster to canonicalize against, and the dispatch
 	// capture time anchors incident-recency scoring. Failures are swallowed inside the helper.
+	// A confident match also tells the CAD poller this rescue was heard on the radio.
 	if tc.unitResolver != nil {
-		tc.resolveAndCacheUnitContext(ctx, tg.TGID, tr.Transcription, parsedKey.dk.Time)
+		uc := tc.resolveAndCacheUnitContext(ctx, incidentID, tr.Transcription, parsedKey.dk.Time)
+		tc.linkCADIncident(ctx, incidentID, dispatchMessage.TACChannel, uc, parsedKey.dk.Time)
 	}
 
 	return nil
@@ -151,34 +168,92 @@ func (tc *TranscribeClient) processDispatchCall(ctx context.Context, parsedKey *
 // incident. Best-effort throughout — the per-S3-key dedup guard means this audio won't be
 // reprocessed on redelivery, so we swallow errors (logging them) and ack rather than risk losing
 // the re-page entirely or double-posting the reply.
-func (tc *TranscribeClient) handleAdditionalDispatch(ctx context.Context, parsedKey *AdornedDeconstructedKey, tr *asr.TranscriptionResponse, meta ClosureMeta) error {
+//
+// While an outcome close prompt is unanswered the window is left alone, as it is for traffic
+// (close_suggestion.go): the prompt promised a close time and a re-page doesn't answer it. Keep
+// hands the schedule back.
+//
+// With SPLIT_DETECTION_ENABLED the re-page is first compared with the active rescue's dispatch
+// (see split.go); when it looks like a different incident the reply carries a Split prompt.
+// The window is refreshed either way (barring a pending close prompt) — until an operator splits,
+// the TAC stays with this rescue.
+func (tc *TranscribeClient) handleAdditionalDispatch(ctx context.Context, parsedKey *AdornedDeconstructedKey, tr *asr.TranscriptionResponse, meta ClosureMeta, tg TalkgroupInformation, dm ml.DispatchMessage) error {
 	slog.Info("additional dispatch for an active rescue; deduping (no new alert)",
-		slog.String("tgid", meta.TGID), slog.String("tac_channel", meta.TACChannel), slog.String("thread", meta.ThreadTS))
-
-	expiresAt := time.Now().Add(tc.config.TacticalChannelActivationDuration).Local()
+		slog.String("incident", meta.IncidentID), slog.String("tac_channel", meta.TACChannel), slog.String("thread", meta.ThreadTS))
+
+	expiresAt := meta.CapToLifetime(time.Now().Add(tc.config.TacticalChannelActivationDuration), tc.config.TACMaxLifetime).Local()
+
+	// Hold the new window against TAC_IDLE_TIMEOUT: a fresh tone-out earns the full window.
+	// The ORIGINAL meta (thread_ts, message_ts, dispatch transcript) is kept so the feedback
+	// prefill still reflects the initiating dispatch. It is updated in place rather than
+	// rewritten from meta, which was read before this re-page: a TAC added from Slack since
+	// must not be dropped.
+	if tc.closePromptPending(ctx, meta.IncidentID) {
+		slog.Info("additional dispatch: close prompt pending; keeping its grace close", slog.String("incident", meta.IncidentID))
+	} else {
+		fresh, active, err := tc.updateClosureMeta(ctx, meta.IncidentID, func(m *ClosureMeta) { m.HeldUntil = expiresAt })
+		switch {
+		case err != nil:
+			slog.Error("additional dispatch: failed to hold the new window", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		case !active:
+			slog.Info("additional dispatch: rescue closed meanwhile; not rescheduling", slog.String("incident", meta.IncidentID))
+		default:
+			meta = fresh
+		}
+		if active {
+			// Refresh the activation window: allow-list membership and routing TTL for every TAC
+			// on the incident, then the auto-close.
+			tc.refreshIncidentRouting(ctx, meta, tc.config.TacticalChannelActivationDuration)
+			if err := tc.dragonflyClient.ZAdd(ctx, activeTACsKey, float64(expiresAt.Unix()), meta.IncidentID); err != nil {
+				slog.Error("additional dispatch: failed to reschedule closure", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+			}
+		}
+	}
 
-	// Refresh the activation window: allow-list membership, routing TTL, and the auto-close.
-	if err := tc.dragonflyClient.SAddEx(ctx, "allowed_talkgroups", tc.config.TacticalChannelActivationDuration, meta.TGID); err != nil {
-		slog.Error("additional dispatch: failed to refresh allow-list", slog.String("error", err.Error()), slog.String("tgid", meta.TGID))
+	// Note the re-page in the original thread so operators see the additional unit.
+	blocks := BuildAdditionalDispatchBlocks(meta.TACChannel, tr.Transcription, parsedKey.dk.Time)
+	if tc.config.SplitDetectionEnabled {
+		blocks = append(blocks, tc.splitPromptBlocks(ctx, parsedKey, tr, meta, tg, dm)...)
 	}
-	if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(talkgroupKeyPrefix, meta.TGID), tc.config.TacticalChannelActivationDuration, meta.ThreadTS); err != nil {
-		slog.Error("additional dispatch: failed to refresh routing key", slog.String("error", err.Error()), slog.String("tgid", meta.TGID))
+	// Secondary sinks get the re-page as a plain transmission on the dispatch talkgroup.
+	var channel string
+	if dispatchTG, ok := talkgroupFromTGID[parsedKey.dk.Talkgroup]; ok {
+		channel = dispatchTG.FullName
 	}
-	// Reuse the ORIGINAL meta (thread_ts, message_ts, dispatch transcript) with the new expiry so
-	// the auto-close pushes out and the feedback prefill still reflects the initiating dispatch.
-	if err := tc.ScheduleTACClosure(ctx, meta, expiresAt); err != nil {
-		slog.Error("additional dispatch: failed to reschedule closure", slog.String("error", err.Error()), slog.String("tgid", meta.TGID))
+	if err := tc.publishThreadReply(ctx, meta,
+		&slackThreadReply{ThreadTS: meta.ThreadTS, Talkgroup: parsedKey.dk.Talkgroup, Blocks: blocks},
+		channel, tr.Transcription, parsedKey.dk.Time, ""); err != nil {
+		slog.Error("additional dispatch: failed to post thread reply", slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
 	}
+	return nil
+}
 
-	// Note the re-page in the original thread so operators see the additional unit.
-	if _, err := tc.sendSlackWithRetry(ctx, parsedKey.dk.Talkgroup,
-		slack.MsgOptionBlocks(BuildAdditionalDispatchBlocks(meta.TACChannel, tr.Transcription, parsedKey.dk.Time)...),
-		slack.MsgOptionTS(meta.ThreadTS),
-		slack.MsgOptionAsUser(true),
-	); err != nil {
-		slog.Error("additional dispatch: failed to post thread reply", slog.String("error", err.Error()), slog.String("tgid", meta.TGID))
+// splitPromptBlocks returns the Split prompt for a re-page that looks like a different incident,
+// or nil when it looks like the same one (or the candidate couldn't be stored — a prompt whose
+// button can't act is worse than none).
+func (tc *TranscribeClient) splitPromptBlocks(ctx context.Context, parsedKey *AdornedDeconstructedKey, tr *asr.TranscriptionResponse, meta ClosureMeta, tg TalkgroupInformation, dm ml.DispatchMessage) []slack.Block {
+	distinct, reason := tc.assessSplit(ctx, meta, dm)
+	if !distinct {
+		return nil
 	}
-	return nil
+	candidateID, err := tc.saveSplitCandidate(ctx, SplitCandidate{
+		ParentIncidentID: meta.IncidentID,
+		TGID:             tg.TGID,
+		TACChannel:       dm.TACChannel,
+		CallType:         dm.CallType,
+		Transcription:    tr.Transcription,
+		SourceTalkgroup:  parsedKey.dk.Talkgroup,
+		DispatchedAt:     parsedKey.dk.Time,
+		MapLink:          tc.mapLinkFor(dm.Location),
+	})
+	if err != nil {
+		slog.Error("split detection: failed to store candidate; posting re-page without prompt",
+			slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		return nil
+	}
+	slog.Info("split detection: re-page looks like a different incident",
+		slog.String("incident", meta.IncidentID), slog.String("candidate", candidateID), slog.String("reason", reason))
+	return BuildSplitPromptBlocks(dm.TACChannel, reason, candidateID)
 }
 
 func (tc *TranscribeClient) processNonDispatchCall(ctx context.Context, parsedKey *AdornedDeconstructedKey, tr *asr.TranscriptionResponse) error {
@@ -203,33 +278,45 @@ func (tc *TranscribeClient) processNonDispatchCall(ctx context.Context, parsedKe
 
 	slog.Debug("found talkgroup information", slog.String("talkgroup", parsedKey.dk.Talkgroup), slog.Any("talkgroup_info", tgInfo))
 
-	// Clean the raw ASR transmission before it goes anywhere. The cleaned text is what we post
-	// in the thread AND what feeds the cumulative summary. Best-effort: on any failure we fall
-	// back to the raw transcription so the transmission is never dropped.
-	cleaned := tc.maybeCleanTranscript(ctx, parsedKey.dk.Talkgroup, tr.Transcription)
+	// File the transmission under its incident (shared by every TAC on a multi-TAC rescue) so
+	// cleanup context, transcripts and the live interpretation are shared.
+	incidentID := tc.incidentIDFor(ctx, parsedKey.dk.Talkgroup)
+
+	// Clean the raw ASR transmission before it goes anywhere: the deterministic callsign pass
+	// first, then the LLM cleanup. The cleaned text is what we post in the thread AND what feeds
+	// the cumulative summary. Best-effort: on any failure we fall back to the text as it was so
+	// the transmission is never dropped.
+	cleaned := tc.maybeCleanTranscript(ctx, incidentID, tc.normalizeCallsigns(ctx, incidentID, tr.Transcription))
 
-	// FIX (review item #1): sendSlackWithRetry actually retries on rate limit; the prior path
+	now := time.Now().Local()
+	audioURL := tc.presignTransmissionAudio(ctx, parsedKey)
+
+	// FIX (review item #1): the send path actually retries on rate limit; the prior path
 	// waited and discarded the message. Errors now propagate so Work() can Nack for redelivery.
-	if _, err := tc.sendSlackWithRetry(ctx, parsedKey.dk.Talkgroup,
-		slack.MsgOptionBlocks(BuildThreadCommunicationBlocks(&ThreadCommunicationBlocksInput{
-			Channel: tgInfo.FullName,
-			Message: cleaned,
-			TS:      time.Now().Local(),
-		})...),
-		slack.MsgOptionAsUser(true),
-		slack.MsgOptionTS(tsThread),
-	); err != nil {
+	// With SLACK_QUEUE_ENABLED the reply is enqueued durably and only an enqueue + inline
+	// fallback failure surfaces here. SLACK_AUDIO_MODE=upload posts the reply as a file share
+	// with the clip attached instead, falling through to the plain reply on failure. The
+	// secondary sinks hear about the transmission only once the Slack reply is through.
+	title := fmt.Sprintf("%s %s", tgInfo.FullName, now.Format(time.TimeOnly))
+	if err := tc.publishThreadReply(ctx,
+		ClosureMeta{IncidentID: incidentID, TGID: tgInfo.TGID, TACChannel: tgInfo.RadioShortCode},
+		&slackThreadReply{ThreadTS: tsThread, Talkgroup: parsedKey.dk.Talkgroup, ParsedKey: parsedKey, Title: title},
+		tgInfo.FullName, cleaned, now, audioURL); err != nil {
 		return fmt.Errorf("%w: %s", ErrFailedToPostSlackMessage, err.Error())
 	}
 
 	slog.Debug("posted transcription message to Slack", slog.String("talkgroup", parsedKey.dk.Talkgroup), slog.String("thread_id", tsThread))
 
+	// With TAC_IDLE_TIMEOUT, traffic keeps the rescue open (closure_policy.go).
+	tc.extendOnActivity(ctx, incidentID)
+
 	// Roll the rescue's live interpretation forward with the CLEANED text. Best-effort and
 	// decoupled — if the LLM call or chat.update fails we still consider the TAC transmission
 	// processed (the per-message thread reply above is the canonical record). Uses
 	// parsedKey.dk.Time as the capture moment so the model sees stable timestamps even when
-	// pipeline latency varies between transmissions.
-	tc.updateLiveInterpretation(ctx, parsedKey.dk.Talkgroup, parsedKey.dk.Time, cleaned)
+	// pipeline latency varies between transmissions. The channel tag attributes the
+	// transmission when the rescue spans several TACs.
+	tc.updateLiveInterpretation(ctx, incidentID, tgInfo.RadioShortCode, parsedKey.dk.Time, cleaned)
 	return nil
 }
 
@@ -237,7 +324,7 @@ func (tc *TranscribeClient) processNonDispatchCall(ctx context.Context, parsedKe
 // transcription. Gated by TAC_CLEANUP_ENABLED; when disabled (or on any error / empty result) it
 // returns the raw text unchanged so a transmission is never lost. The dispatch transcription and
 // (optional) CAD unit roster are passed as context so the model can disambiguate and canonicalize.
-func (tc *TranscribeClient) maybeCleanTranscript(ctx context.Context, tgid, raw string) string {
+func (tc *TranscribeClient) maybeCleanTranscript(ctx context.Context, incidentID, raw string) string {
 	if !tc.config.TACCleanupEnabled {
 		return raw
 	}
@@ -253,18 +340,18 @@ func (tc *TranscribeClient) maybeCleanTranscript(ctx context.Context, tgid, raw
 	}
 
 	var dispatchText string
-	if meta, ok := tc.readClosureMeta(cleanCtx, tgid); ok {
+	if meta, ok := tc.readClosureMeta(cleanCtx, incidentID); ok {
 		dispatchText = meta.Transcription
 	}
-	unitContext := tc.unitContextFor(cleanCtx, tgid, dispatchText, time.Now())
+	unitContext := tc.unitContextFor(cleanCtx, incidentID, dispatchText, time.Now())
 
-	res, err := tc.mlClient.CleanTACTranscript(cleanCtx, ml.TACCleanupInput{
+	res, err := tc.mlClient.CleanTACTranscript(ml.ContextWithIncident(cleanCtx, incidentID), ml.TACCleanupInput{
 		Text:            raw,
 		DispatchContext: dispatchText,
-		UnitContext:     unitContext,
+		UnitContext:     unitContext.PromptBlock(),
 	})
 	if err != nil {
-		slog.Warn("tac cleanup failed; posting raw transcription", slog.String("error", err.Error()), slog.String("tgid", tgid))
+		slog.Warn("tac cleanup failed; posting raw transcription", slog.String("error", err.Error()), slog.String("incident", incidentID))
 		return raw
 	}
 	if res == nil || strings.TrimSpace(res.CleanedText) == "" {
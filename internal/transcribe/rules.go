@@ -13,6 +13,11 @@ import (
 type AdornedDeconstructedKey struct {
 	dk *DeconstructedKey
 	ti *TalkgroupInformation
+
+	// key is the raw S3 object key and audio the downloaded object body (set by processRecord
+	// after the fetch). Both are only read by the optional thread-audio attachment.
+	key   string
+	audio []byte
 }
 
 func (tc *TranscribeClient) IsObjectAllowed(ctx context.Context, key string) (bool, *AdornedDeconstructedKey, error) {
@@ -38,8 +43,9 @@ func (tc *TranscribeClient) IsObjectAllowed(ctx context.Context, key string) (bo
 	talkgroupInfo := talkgroupFromTGID[parsedKey.Talkgroup]
 
 	adk = &AdornedDeconstructedKey{
-		dk: parsedKey,
-		ti: &talkgroupInfo,
+		dk:  parsedKey,
+		ti:  &talkgroupInfo,
+		key: key,
 	}
 
 	res, err := tc.dragonflyClient.SMisMember(ctx, "allowed_talkgroups", adk.ti.TGID)
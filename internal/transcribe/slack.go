@@ -2,9 +2,11 @@ package transcribe
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/searchandrescuegg/transcribe/internal/cad"
 	"github.com/searchandrescuegg/transcribe/internal/ml"
 	"github.com/slack-go/slack"
 )
@@ -21,9 +23,12 @@ import (
 // makes its tests independent of the talkgroups.go contents.
 //
 // TACTalkgroupTGID is the TGID of the activated tactical channel (e.g. "1389" for TAC1).
-// It's encoded as the action `value` on the Cancel and Extend buttons so the Slack
-// interactivity controller can identify which TAC to operate on without parsing the
-// message text. Empty string disables the action buttons (used by older callers / tests).
+// Empty string disables the action buttons (used by older callers / tests).
+//
+// IncidentID is encoded as the action `value` on the buttons and in the actions block_id so
+// the Slack interactivity controller can identify which rescue to operate on without parsing
+// the message text. Falls back to TACTalkgroupTGID when empty — the legacy key of rescues
+// scheduled before incident IDs existed.
 //
 // ClosedAt switches the builder into "closed" mode: the trailing "Expires HH:MM" line
 // becomes "Monitoring auto-closed at HH:MM", the actions block is omitted (no buttons),
@@ -39,12 +44,18 @@ type RescueTrailBlocksInput struct {
 	ExpiresAt         time.Time
 	DispatchTGID      string
 	TACTalkgroupTGID  string
+	IncidentID        string
 	ClosedAt          *time.Time
 	FeedbackURL       string
 	// SARNotified renders a green-check "Search & Rescue notified" badge on the alert when
 	// the live interpretation detects SAR has been contacted. Latched by the caller, so once
 	// set it persists through the closed-mode rewrite too.
 	SARNotified bool
+	// AdditionalTACChannels are short codes ("TAC8") of TACs added to a multi-TAC rescue.
+	// They join the status line and the OpenMHz link, and populate the Drop TAC control.
+	AdditionalTACChannels []string
+	// MapLink adds a map button for the resolved dispatch location. Nil renders no button.
+	MapLink *MapLink
 }
 
 // Action IDs are the routing keys the slackctl controller dispatches on. Keep these in
@@ -59,22 +70,48 @@ const (
 	ActionIDRescueClose     = "rescue_close"
 	ActionIDRescueExtend    = "rescue_extend"
 	ActionIDRescueSwitchTAC = "rescue_switch_tac"
+	// ActionIDRescueAddTAC / ActionIDRescueDropTAC attach or detach an additional TAC on a
+	// multi-TAC rescue. Like Switch, the incident ID comes from the block_id and the target
+	// TGID from the selected option. Drop only offers additional TACs; moving the primary
+	// is what Switch is for.
+	ActionIDRescueAddTAC  = "rescue_add_tac"
+	ActionIDRescueDropTAC = "rescue_drop_tac"
 	// ActionIDRescueDelete permanently removes the specific alert MESSAGE it was clicked on
 	// (chat.delete). If that message is the live alert it also tears the incident down (like
 	// Cancel, minus the tombstone); if it's an orphaned duplicate it only removes the message and
 	// leaves the live incident alone. Distinct from Cancel/Close, which leave a message behind.
 	ActionIDRescueDelete = "rescue_delete"
+	// ActionIDRescueSplit is the button on a split prompt (a re-page that looks like a
+	// different incident). Its value is the split candidate ID, which is also the incident ID
+	// the new incident gets if the operator splits.
+	ActionIDRescueSplit = "rescue_split"
+	// ActionIDRescueKeep is the Keep button on an outcome close prompt: it cancels the grace
+	// close and restores the previous expiry. The prompt's Close button reuses
+	// ActionIDRescueClose; both carry the incident ID.
+	ActionIDRescueKeep = "rescue_keep"
+	// CloseSuggestionBlockIDPrefix prefixes the close prompt's actions block_id, so the Close
+	// handler can tell a prompt click from an alert click and retire the prompt.
+	CloseSuggestionBlockIDPrefix = "rescue_close_prompt:"
 	// ActionIDFeedbackForm is the action_id on the URL-style Submit Feedback button.
 	// Slack sends a block_actions event for URL buttons too (so engagement is trackable),
 	// but we have nothing server-side to do — the controller's switch handles it as a
 	// no-op, preventing a noisy "unknown action_id" warn on every click.
 	ActionIDFeedbackForm = "feedback_form"
+	// ActionIDMapLink is the action_id on the URL-style Open Map button (MAP_LINKS_ENABLED).
+	// Same as the feedback button: acknowledged as a no-op so clicks don't log as unknown.
+	ActionIDMapLink = "map_link"
 	// ActionsBlockIDPrefix is the literal portion of the actions block_id. The full id is
-	// "rescue_actions:<TGID>", letting the switch-TAC handler recover the previous TGID
-	// without needing to look it up in Dragonfly. Cancel/Extend continue to read the TGID
-	// from the button's value field; only switch needs the block_id route because the
-	// select element's value carries the NEW (target) TGID.
+	// "rescue_actions:<incident>", letting the TAC select handlers recover the rescue without
+	// needing to look it up in Dragonfly. The buttons continue to read the incident from their
+	// value field; only the selects need the block_id route because the select element's
+	// value carries the target TGID.
 	ActionsBlockIDPrefix = "rescue_actions"
+	// ActionIDCADPin is the live interpretation's "Pin CAD incident" dropdown
+	// (CAD_MATCH_DETAILS_ENABLED). The option value is the CAD incident ID, or CADPinAutomatic
+	// to go back to the scored match; the rescue comes from the CADMatchBlockIDPrefix block_id.
+	ActionIDCADPin        = "cad_pin"
+	CADMatchBlockIDPrefix = "cad_match:"
+	CADPinAutomatic       = "auto"
 )
 
 func buildOpenMHzURL(channels []string) string {
@@ -93,7 +130,15 @@ func BuildRescueTrailBlocks(rtbi *RescueTrailBlocksInput) []slack.Block {
 		}
 	}
 
-	openMHzURL := buildOpenMHzURL([]string{talkgroup.TGID, rtbi.DispatchTGID})
+	listenTGIDs := []string{talkgroup.TGID}
+	statusName := talkgroup.ShortName
+	for _, code := range rtbi.AdditionalTACChannels {
+		if extra, ok := talkgroupFromRadioShortCode[code]; ok {
+			listenTGIDs = append(listenTGIDs, extra.TGID)
+			statusName += " + " + extra.ShortName
+		}
+	}
+	openMHzURL := buildOpenMHzURL(append(listenTGIDs, rtbi.DispatchTGID))
 
 	headerText := "Rescue Trail :helmet_with_white_cross: :evergreen_tree: :mountain:"
 	if rtbi.ClosedAt != nil {
@@ -149,7 +194,13 @@ func BuildRescueTrailBlocks(rtbi *RescueTrailBlocksInput) []slack.Block {
 		slack.NewDividerBlock(),
 
 		// Rich text block with expiration / closure info
-		buildRescueStatusBlock(talkgroup.ShortName, rtbi.ExpiresAt, rtbi.ClosedAt),
+		buildRescueStatusBlock(statusName, rtbi.ExpiresAt, rtbi.ClosedAt),
+	}
+
+	// Map button sits under the OpenMHz one (index 5, ahead of the SAR insertion below). Gated
+	// for the same byte-identical reason as the SAR badge.
+	if rtbi.MapLink != nil {
+		blocks = append(blocks[:5:5], append([]slack.Block{buildMapLinkBlock(rtbi.MapLink)}, blocks[5:]...)...)
 	}
 
 	// SAR-notified badge, inserted right after the header for at-a-glance visibility. Gated
@@ -163,7 +214,11 @@ func BuildRescueTrailBlocks(rtbi *RescueTrailBlocksInput) []slack.Block {
 	// Action buttons only render while the rescue is live. Once ClosedAt is set the alert
 	// is a frozen historical record — leadership can't extend or cancel a closed rescue.
 	if rtbi.TACTalkgroupTGID != "" && rtbi.ClosedAt == nil {
-		blocks = append(blocks, buildRescueActionsBlock(rtbi.TACChannel, rtbi.TACTalkgroupTGID))
+		incidentID := rtbi.IncidentID
+		if incidentID == "" {
+			incidentID = rtbi.TACTalkgroupTGID
+		}
+		blocks = append(blocks, buildRescueActionsBlock(rtbi.TACChannel, incidentID, rtbi.AdditionalTACChannels))
 	}
 
 	// Feedback button only on closed alerts AND only when a form URL was configured.
@@ -230,14 +285,14 @@ func buildRescueStatusBlock(shortName string, expiresAt time.Time, closedAt *tim
 	)
 }
 
-// buildRescueActionsBlock renders the Cancel + Extend buttons and the Switch-TAC select.
-// Cancel/Extend carry the current TGID as their button value; the select carries the
-// target TGID per option, with the current TGID encoded in the action block's id so the
-// switch handler can derive both old and new in one click.
-func buildRescueActionsBlock(tacChannel, tacTGID string) slack.Block {
+// buildRescueActionsBlock renders the Cancel + Extend buttons and the Switch / Add / Drop TAC
+// selects. The buttons carry the incident ID as their value; the selects carry the target TGID
+// per option, with the incident ID encoded in the action block's id so the handlers can derive
+// both in one click.
+func buildRescueActionsBlock(tacChannel, incidentID string, additionalTACs []string) slack.Block {
 	cancelBtn := slack.NewButtonBlockElement(
 		ActionIDRescueCancel,
-		tacTGID,
+		incidentID,
 		slack.NewTextBlockObject(slack.PlainTextType, "Cancel (False Alarm)", true, false),
 	)
 	cancelBtn.Style = slack.StyleDanger
@@ -250,7 +305,7 @@ func buildRescueActionsBlock(tacChannel, tacTGID string) slack.Block {
 
 	closeBtn := slack.NewButtonBlockElement(
 		ActionIDRescueClose,
-		tacTGID,
+		incidentID,
 		slack.NewTextBlockObject(slack.PlainTextType, "Close (End Rescue)", true, false),
 	)
 	closeBtn.Confirm = slack.NewConfirmationBlockObject(
@@ -262,7 +317,7 @@ func buildRescueActionsBlock(tacChannel, tacTGID string) slack.Block {
 
 	extendBtn := slack.NewButtonBlockElement(
 		ActionIDRescueExtend,
-		tacTGID,
+		incidentID,
 		slack.NewTextBlockObject(slack.PlainTextType, "Extend monitoring", true, false),
 	)
 	extendBtn.Confirm = slack.NewConfirmationBlockObject(
@@ -273,13 +328,22 @@ func buildRescueActionsBlock(tacChannel, tacTGID string) slack.Block {
 	)
 
 	switchSelect := buildSwitchTACSelect(tacChannel)
+	elements := []slack.BlockElement{cancelBtn, closeBtn, extendBtn, switchSelect}
+
+	monitored := append([]string{tacChannel}, additionalTACs...)
+	if addSelect := buildAddTACSelect(monitored); addSelect != nil {
+		elements = append(elements, addSelect)
+	}
+	if len(additionalTACs) > 0 {
+		elements = append(elements, buildDropTACSelect(additionalTACs))
+	}
 
 	// Delete: removes THIS alert message. Danger-styled + confirm because it's destructive and,
 	// on the live alert, also stops monitoring. The confirm can't know at render time whether the
 	// clicked message is the live alert or an orphaned duplicate, so it warns about both.
 	deleteBtn := slack.NewButtonBlockElement(
 		ActionIDRescueDelete,
-		tacTGID,
+		incidentID,
 		slack.NewTextBlockObject(slack.PlainTextType, "Delete message", true, false),
 	)
 	deleteBtn.Style = slack.StyleDanger
@@ -291,34 +355,78 @@ func buildRescueActionsBlock(tacChannel, tacTGID string) slack.Block {
 		slack.NewTextBlockObject(slack.PlainTextType, "Keep it", false, false),
 	)
 
-	// block_id encodes the active TGID so the switch handler knows what to migrate FROM.
-	blockID := fmt.Sprintf("%s:%s", ActionsBlockIDPrefix, tacTGID)
-	return slack.NewActionBlock(blockID, cancelBtn, closeBtn, extendBtn, switchSelect, deleteBtn)
+	// block_id encodes the incident so the select handlers know which rescue to act on.
+	blockID := fmt.Sprintf("%s:%s", ActionsBlockIDPrefix, incidentID)
+	return slack.NewActionBlock(blockID, append(elements, deleteBtn)...)
 }
 
-// buildSwitchTACSelect returns a static_select populated with TAC1-TAC10. Each option's
-// value is the TARGET TGID; the source TGID lives in the parent block_id. Confirm dialog
-// fires before the select event reaches our controller — destructive correction needs a
-// fat-finger guard same as Cancel/Extend.
-func buildSwitchTACSelect(currentTACChannel string) *slack.SelectBlockElement {
-	// TAC1..TAC10 in stable, human-readable order. We read the canonical entries from the
-	// derived short-code map so this stays in lockstep with talkgroups.go.
-	codes := []string{"TAC1", "TAC2", "TAC3", "TAC4", "TAC5", "TAC6", "TAC7", "TAC8", "TAC9", "TAC10"}
+// tacSelectCodes is TAC1..TAC10 in stable, human-readable order, shared by the TAC selects.
+var tacSelectCodes = []string{"TAC1", "TAC2", "TAC3", "TAC4", "TAC5", "TAC6", "TAC7", "TAC8", "TAC9", "TAC10"}
+
+// tacSelectOptions builds one option per code, value = TGID, label = "TAC3 — <name>".
+// Codes missing from the talkgroup table are skipped.
+func tacSelectOptions(codes []string) []*slack.OptionBlockObject {
 	options := make([]*slack.OptionBlockObject, 0, len(codes))
 	for _, code := range codes {
 		tg, ok := talkgroupFromRadioShortCode[code]
 		if !ok {
 			continue
 		}
-		// Target TGID lives in option.value; the user-facing label is the short code plus
-		// the human-friendly name so it's clear which channel they're picking.
-		label := fmt.Sprintf("%s — %s", code, tg.ShortName)
 		options = append(options, slack.NewOptionBlockObject(
 			tg.TGID,
-			slack.NewTextBlockObject(slack.PlainTextType, label, false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, fmt.Sprintf("%s — %s", code, tg.ShortName), false, false),
 			nil,
 		))
 	}
+	return options
+}
+
+// buildAddTACSelect offers every TAC not already monitored by this rescue. Returns nil when
+// all of them are (a select with no options is rejected by Slack).
+func buildAddTACSelect(monitored []string) *slack.SelectBlockElement {
+	var codes []string
+	for _, code := range tacSelectCodes {
+		if !slices.Contains(monitored, code) {
+			codes = append(codes, code)
+		}
+	}
+	options := tacSelectOptions(codes)
+	if len(options) == 0 {
+		return nil
+	}
+	placeholder := slack.NewTextBlockObject(slack.PlainTextType, "Add TAC…", false, false)
+	sel := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, placeholder, ActionIDRescueAddTAC, options...)
+	sel.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject(slack.PlainTextType, "Add TAC channel?", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Also transcribes the channel you pick into this rescue's thread and live interpretation, until the rescue closes.", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Add", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+	)
+	return sel
+}
+
+// buildDropTACSelect offers the rescue's additional TACs for removal.
+func buildDropTACSelect(additionalTACs []string) *slack.SelectBlockElement {
+	placeholder := slack.NewTextBlockObject(slack.PlainTextType, "Drop TAC…", false, false)
+	sel := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, placeholder, ActionIDRescueDropTAC, tacSelectOptions(additionalTACs)...)
+	sel.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject(slack.PlainTextType, "Drop TAC channel?", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Stops transcribing the channel you pick for this rescue. Transmissions already in the thread are kept.", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Drop", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Keep", false, false),
+	)
+	return sel
+}
+
+// buildSwitchTACSelect returns a static_select populated with TAC1-TAC10. Each option's
+// value is the TARGET TGID; the source TGID lives in the parent block_id. Confirm dialog
+// fires before the select event reaches our controller — destructive correction needs a
+// fat-finger guard same as Cancel/Extend.
+func buildSwitchTACSelect(currentTACChannel string) *slack.SelectBlockElement {
+	// Options are read from the derived short-code map so this stays in lockstep with
+	// talkgroups.go. Target TGID lives in option.value; the label is the short code plus the
+	// human-friendly name so it's clear which channel they're picking.
+	options := tacSelectOptions(tacSelectCodes)
 
 	placeholder := slack.NewTextBlockObject(slack.PlainTextType, fmt.Sprintf("Switch from %s…", currentTACChannel), false, false)
 	sel := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, placeholder, ActionIDRescueSwitchTAC, options...)
@@ -357,10 +465,276 @@ func BuildAdditionalDispatchBlocks(tacChannel, transcription string, at time.Tim
 	}
 }
 
+// BuildCADRescueBlocks renders the top-level alert the CAD poller posts for a rescue CAD knows
+// about but the radio path never alerted on. No buttons: there is no TAC to monitor yet.
+func BuildCADRescueBlocks(inc cad.Incident, mapLink *MapLink) []slack.Block {
+	received := "unknown"
+	if !inc.ReceivedAt.IsZero() {
+		received = inc.ReceivedAt.Local().Format("15:04 MST")
+	}
+	units := make([]string, 0, len(inc.Units))
+	for _, u := range inc.Units {
+		if u.Status != "" {
+			units = append(units, fmt.Sprintf("%s (%s)", u.ID, u.Status))
+		} else {
+			units = append(units, u.ID)
+		}
+	}
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType,
+				":satellite_antenna: *CAD shows a rescue — no radio dispatch detected yet.* The dispatch may have been missed or misheard; check the radio.",
+				false, false),
+			nil, nil,
+		),
+		slack.NewSectionBlock(nil, []*slack.TextBlockObject{
+			slack.NewTextBlockObject(slack.MarkdownType, "*Call Type:*\n"+orUnknown(inc.CallType), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, "*Received:*\n"+received, false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, "*Address:*\n"+orUnknown(inc.Address), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, "*Units:*\n"+orUnknown(strings.Join(units, ", ")), false, false),
+		}, nil),
+	}
+	if mapLink != nil {
+		blocks = append(blocks, buildMapLinkBlock(mapLink))
+	}
+	return append(blocks, slack.NewContextBlock("",
+		slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("From CAD incident %s. A radio dispatch that matches it will be linked in this thread.", inc.ID), false, false),
+	))
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// buildCADUnitTable renders the live interpretation's CAD units as a two-column monospace table.
+func buildCADUnitTable(units []cad.Unit) slack.Block {
+	width := len("Unit")
+	for _, u := range units {
+		width = max(width, len(u.ID))
+	}
+	var b strings.Builder
+	b.WriteString("*CAD units*\n```")
+	fmt.Fprintf(&b, "%-*s  %s\n", width, "Unit", "Status")
+	for _, u := range units {
+		fmt.Fprintf(&b, "%-*s  %s\n", width, u.ID, orUnknown(u.Status))
+	}
+	b.WriteString("```")
+	return slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, b.String(), false, false), nil, nil)
+}
+
+// LiveInterpretationCAD is the CAD view on the live interpretation: the matched incident's unit
+// table (CAD_UNIT_TIMELINE_ENABLED) and the match explanation with its pin dropdown
+// (CAD_MATCH_DETAILS_ENABLED). The zero value renders neither.
+type LiveInterpretationCAD struct {
+	// IncidentID is the rescue, carried in the pin dropdown's block_id.
+	IncidentID string
+	Units      []cad.Unit
+	Match      *cad.UnitContext
+}
+
+// maxCADMatchLines caps the candidates listed in the explanation; the dropdown offers them all.
+const maxCADMatchLines = 3
+
+// buildCADMatchBlock explains which CAD incident the unit context came from and why: the
+// top-scored candidates with their scores and shared location words. The text is left
+// unexpanded, so Slack collapses all but the first lines behind "See more", and the accessory
+// dropdown pins a different incident.
+func buildCADMatchBlock(incidentID string, uc *cad.UnitContext) slack.Block {
+	var b strings.Builder
+	switch {
+	case uc.Pinned:
+		fmt.Fprintf(&b, "*CAD match (pinned):* %s", cadIncidentLabel(uc.IncidentID, uc.CallType, uc.Address))
+	case uc.Matched:
+		fmt.Fprintf(&b, "*CAD match:* %s", cadIncidentLabel(uc.IncidentID, uc.CallType, uc.Address))
+	default:
+		b.WriteString("*CAD match:* none confident; prompts use the active rescue roster")
+	}
+	for i, c := range uc.Candidates {
+		if i == maxCADMatchLines {
+			fmt.Fprintf(&b, "\n…and %d more in the dropdown", len(uc.Candidates)-i)
+			break
+		}
+		shared := "no shared location words"
+		if len(c.Overlap) > 0 {
+			shared = "shared: " + strings.Join(c.Overlap, ", ")
+		}
+		marker := ""
+		if uc.Matched && c.IncidentID == uc.IncidentID {
+			marker = " ← chosen"
+		}
+		fmt.Fprintf(&b, "\n• `%.1f` %s · %s%s", c.Score, cadIncidentLabel(c.IncidentID, c.CallType, c.Address), shared, marker)
+	}
+	fmt.Fprintf(&b, "\n_A confident match needs a score of at least %.1f and a shared location word._", cad.MatchScoreThreshold)
+
+	section := slack.NewSectionBlock(
+		slack.NewTextBlockObject(slack.MarkdownType, b.String(), false, false),
+		nil,
+		slack.NewAccessory(buildCADPinSelect(uc)),
+		slack.SectionBlockOptionBlockID(CADMatchBlockIDPrefix+incidentID),
+	)
+	return section
+}
+
+// buildCADPinSelect offers "Automatic match" plus every candidate, preselecting the current pin.
+func buildCADPinSelect(uc *cad.UnitContext) *slack.SelectBlockElement {
+	automatic := slack.NewOptionBlockObject(CADPinAutomatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "Automatic match", false, false), nil)
+	options := []*slack.OptionBlockObject{automatic}
+	initial := automatic
+	for _, c := range uc.Candidates {
+		opt := slack.NewOptionBlockObject(c.IncidentID,
+			slack.NewTextBlockObject(slack.PlainTextType, clipRunes(cadIncidentLabel(c.IncidentID, c.CallType, c.Address), 75), false, false), nil)
+		options = append(options, opt)
+		if uc.Pinned && c.IncidentID == uc.IncidentID {
+			initial = opt
+		}
+	}
+	placeholder := slack.NewTextBlockObject(slack.PlainTextType, "Pin CAD incident…", false, false)
+	sel := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, placeholder, ActionIDCADPin, options...)
+	sel.InitialOption = initial
+	sel.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject(slack.PlainTextType, "Change CAD incident?", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "The unit roster for this rescue's cleanup and summaries will come from the incident you pick, starting with the next transmission.", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Change", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Keep", false, false),
+	)
+	return sel
+}
+
+// cadIncidentLabel renders "RESCUE — Mount Si Trailhead (26-104233)", dropping empty parts.
+func cadIncidentLabel(id, callType, address string) string {
+	var parts []string
+	for _, p := range []string{callType, address} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(parts, " — "), id)
+}
+
+// clipRunes shortens s to at most n runes, ending in "…" when cut (Slack caps option text).
+func clipRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// BuildUnitStatusBlocks renders the thread reply for CAD unit status changes: one line per
+// changed unit with its history so far ("E171 Dispatched → En Route → On Scene").
+func BuildUnitStatusBlocks(lines []string, at time.Time) []slack.Block {
+	return []slack.Block{
+		slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf(":fire_engine: *CAD* %s\n%s", at.Local().Format("15:04"), strings.Join(lines, "\n")),
+				false, false),
+		),
+	}
+}
+
+// BuildCADLinkedBlocks renders the reply in a CAD alert's thread once a radio dispatch matches
+// the incident.
+func BuildCADLinkedBlocks(tacChannel string, at time.Time) []slack.Block {
+	return []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf(":link: *Radio dispatch received* at %s on %s. Follow the rescue alert for that channel.",
+					at.Local().Format("15:04 MST"), tacChannel),
+				false, false),
+			nil, nil,
+		),
+	}
+}
+
+// BuildSplitPromptBlocks renders the prompt appended to an additional-dispatch reply when the
+// new dispatch looks like a different incident than the active rescue. reason is the
+// human-readable basis for the verdict (token mismatch or the LLM's one-liner).
+func BuildSplitPromptBlocks(tacChannel, reason, candidateID string) []slack.Block {
+	splitBtn := slack.NewButtonBlockElement(
+		ActionIDRescueSplit,
+		candidateID,
+		slack.NewTextBlockObject(slack.PlainTextType, "Split into new incident", true, false),
+	)
+	splitBtn.Style = slack.StylePrimary
+	splitBtn.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject(slack.PlainTextType, "Split into a new incident?", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, fmt.Sprintf("Posts a new alert for this dispatch and moves %s monitoring to it. This rescue stops monitoring %s and closes if it has no other TAC.", tacChannel, tacChannel), false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Split", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Keep together", false, false),
+	)
+
+	return []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf(":twisted_rightwards_arrows: *This may be a different incident* sharing %s. %s", tacChannel, reason),
+				false, false),
+			nil, nil,
+		),
+		slack.NewActionBlock("rescue_split:"+candidateID, splitBtn),
+	}
+}
+
+// BuildCloseSuggestionBlocks renders the thread prompt posted when the live summary reports a
+// terminal outcome. closesAt is when the grace close fires if nobody answers.
+func BuildCloseSuggestionBlocks(tacChannel, outcome, incidentID string, closesAt time.Time) []slack.Block {
+	closeBtn := slack.NewButtonBlockElement(
+		ActionIDRescueClose,
+		incidentID,
+		slack.NewTextBlockObject(slack.PlainTextType, "Close now", true, false),
+	)
+	closeBtn.Style = slack.StylePrimary
+	keepBtn := slack.NewButtonBlockElement(
+		ActionIDRescueKeep,
+		incidentID,
+		slack.NewTextBlockObject(slack.PlainTextType, "Keep monitoring", true, false),
+	)
+
+	return []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf(":checkered_flag: *Looks resolved — close monitoring now?* The live interpretation reports _%s_. %s monitoring closes automatically at %s unless someone presses *Keep monitoring*.",
+					outcome, tacChannel, closesAt.Local().Format("15:04 MST")),
+				false, false),
+			nil, nil,
+		),
+		slack.NewActionBlock(CloseSuggestionBlockIDPrefix+incidentID, closeBtn, keepBtn),
+	}
+}
+
+// BuildClosingWarningBlocks renders the "closing soon" thread reply posted TAC_CLOSING_WARNING
+// before an auto-close. trafficExtends says whether a transmission would still push the close
+// out (TAC_IDLE_TIMEOUT on and TAC_MAX_LIFETIME not yet reached); otherwise only Extend
+// will, up to the same lifetime.
+func BuildClosingWarningBlocks(tacChannel string, closesAt time.Time, trafficExtends bool) []slack.Block {
+	hint := "Press *Extend* on the alert to keep it open."
+	if trafficExtends {
+		hint = "More traffic on the channel keeps it open, or press *Extend* on the alert."
+	}
+	return []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf(":hourglass_flowing_sand: *%s monitoring closes at %s.* %s",
+					tacChannel, closesAt.Local().Format("15:04 MST"), hint),
+				false, false),
+			nil, nil,
+		),
+	}
+}
+
 type ThreadCommunicationBlocksInput struct {
 	Channel string
 	Message string
 	TS      time.Time
+	// AudioURL, when set, adds a "Play Clip" link to the transmission's original audio
+	// (SLACK_AUDIO_MODE=presign).
+	AudioURL string
 }
 
 func BuildThreadCommunicationBlocks(tcbi *ThreadCommunicationBlocksInput) []slack.Block {
@@ -388,17 +762,52 @@ func BuildThreadCommunicationBlocks(tcbi *ThreadCommunicationBlocksInput) []slac
 				Border: 0,
 			},
 		),
-		slack.NewDividerBlock(),
 	}
 
-	return blocks
+	if tcbi.AudioURL != "" {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, "Original audio:", false, false),
+			nil,
+			slack.NewAccessory(
+				slack.NewButtonBlockElement(
+					"play-clip-button",
+					"",
+					slack.NewTextBlockObject(slack.PlainTextType, ":loud_sound: Play Clip", true, false),
+				).WithURL(tcbi.AudioURL),
+			),
+		))
+	}
+
+	return append(blocks, slack.NewDividerBlock())
 }
 
 // BuildLiveInterpretationBlocks renders a structured rescue summary for the rolling
 // "Live Interpretation" message in the rescue thread. Posted on the first TAC transmission
 // and chat.update'd on each subsequent one. UpdatedAt is the moment the most recent TAC
-// transmission was processed; it lets viewers see how fresh the summary is.
-func BuildLiveInterpretationBlocks(s *ml.RescueSummary, updatedAt time.Time) []slack.Block {
+// transmission was processed; it lets viewers see how fresh the summary is. mapLink, when
+// non-nil, adds an Open Map button under the fields. liveCAD adds a table of the units and
+// statuses CAD reports (CAD_UNIT_TIMELINE_ENABLED), so CAD truth sits next to what the radio
+// traffic says, and the explanation of which CAD incident was matched with a dropdown to pin
+// another (CAD_MATCH_DETAILS_ENABLED).
+func BuildLiveInterpretationBlocks(s *ml.RescueSummary, updatedAt time.Time, mapLink *MapLink, liveCAD LiveInterpretationCAD) []slack.Block {
+	return buildLiveInterpretationBlocks(s, mapLink, liveCAD,
+		fmt.Sprintf(":hourglass_flowing_sand: Updated %s — refreshes after each TAC transmission.",
+			updatedAt.Format("01/02/06 15:04 MST")))
+}
+
+// BuildLiveInterpretationStreamingBlocks renders a summary that is still streaming
+// (LIVE_INTERPRETATION_STREAMING_ENABLED): the same layout, with a footer saying the model is
+// still writing. interrupted swaps that for a note that the update failed partway, for when the
+// stream errors after some of it was shown.
+func BuildLiveInterpretationStreamingBlocks(s *ml.RescueSummary, mapLink *MapLink, liveCAD LiveInterpretationCAD, interrupted bool) []slack.Block {
+	footer := ":writing_hand: Updating from the latest TAC traffic…"
+	if interrupted {
+		footer = ":warning: Update interrupted — refreshes after the next TAC transmission."
+	}
+	return buildLiveInterpretationBlocks(s, mapLink, liveCAD, footer)
+}
+
+func buildLiveInterpretationBlocks(s *ml.RescueSummary, mapLink *MapLink, liveCAD LiveInterpretationCAD, footer string) []slack.Block {
 	blocks := []slack.Block{
 		slack.NewHeaderBlock(
 			slack.NewTextBlockObject(slack.PlainTextType, "Live Interpretation :dna:", true, false),
@@ -454,6 +863,15 @@ func BuildLiveInterpretationBlocks(s *ml.RescueSummary, updatedAt time.Time) []s
 	if len(fields) > 0 {
 		blocks = append(blocks, slack.NewSectionBlock(nil, fields, nil))
 	}
+	if mapLink != nil {
+		blocks = append(blocks, buildMapLinkBlock(mapLink))
+	}
+	if len(liveCAD.Units) > 0 {
+		blocks = append(blocks, buildCADUnitTable(liveCAD.Units))
+	}
+	if liveCAD.Match != nil {
+		blocks = append(blocks, buildCADMatchBlock(liveCAD.IncidentID, liveCAD.Match))
+	}
 
 	if len(s.KeyEvents) > 0 {
 		var b strings.Builder
@@ -478,10 +896,7 @@ func BuildLiveInterpretationBlocks(s *ml.RescueSummary, updatedAt time.Time) []s
 
 	blocks = append(blocks,
 		slack.NewContextBlock("",
-			slack.NewTextBlockObject(slack.MarkdownType,
-				fmt.Sprintf(":hourglass_flowing_sand: Updated %s — refreshes after each TAC transmission.",
-					updatedAt.Format("01/02/06 15:04 MST")),
-				false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, footer, false, false),
 		),
 	)
 
@@ -521,3 +936,167 @@ func BuildChannelClosedBlocks(ccbi *ChannelClosedBlocksInput) []slack.Block {
 
 	return blocks
 }
+
+// slackSectionTextLimit is Slack's cap on a section block's text. The after-action timeline
+// is the one block here that can plausibly exceed it on a long rescue.
+const slackSectionTextLimit = 3000
+
+// BuildAfterActionReportBlocks renders the after-action report posted in the rescue thread at
+// closure. exported lists the S3 keys the full report was written to (empty when S3 export is
+// off or failed); a timeline too long for one Slack section is cut short with a pointer to them.
+func BuildAfterActionReportBlocks(r IncidentReport, exported []string) []slack.Block {
+	rep := r.Report
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(
+			slack.NewTextBlockObject(slack.PlainTextType, "After-Action Report :clipboard:", true, false),
+		),
+	}
+
+	if rep.Headline != "" {
+		blocks = append(blocks,
+			slack.NewSectionBlock(
+				slack.NewTextBlockObject(slack.MarkdownType, "*"+rep.Headline+"*", false, false),
+				nil, nil,
+			),
+		)
+	}
+
+	var fields []*slack.TextBlockObject
+	if rep.Duration != "" {
+		fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, "*Duration*\n"+rep.Duration, false, false))
+	}
+	if rep.PatientOutcome != "" {
+		fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, "*Patient outcome*\n"+rep.PatientOutcome, false, false))
+	}
+	if rep.SARInvolvement != "" {
+		fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, "*SAR involvement*\n"+rep.SARInvolvement, false, false))
+	}
+	if len(rep.Units) > 0 {
+		fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, "*Units*\n"+strings.Join(rep.Units, ", "), false, false))
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, slack.NewSectionBlock(nil, fields, nil))
+	}
+
+	if len(rep.Timeline) > 0 {
+		lines := make([]string, 0, len(rep.Timeline))
+		for _, e := range rep.Timeline {
+			if e.CapturedAt != "" {
+				lines = append(lines, fmt.Sprintf("• `%s` — %s", e.CapturedAt, e.Description))
+				continue
+			}
+			lines = append(lines, "• "+e.Description)
+		}
+		blocks = append(blocks,
+			slack.NewSectionBlock(
+				slack.NewTextBlockObject(slack.MarkdownType, bulletSection("*Timeline*", lines), false, false),
+				nil, nil,
+			),
+		)
+	}
+
+	radio := "*Radio issues*\nNone noted."
+	if len(rep.RadioIssues) > 0 {
+		lines := make([]string, 0, len(rep.RadioIssues))
+		for _, issue := range rep.RadioIssues {
+			lines = append(lines, "• "+issue)
+		}
+		radio = bulletSection("*Radio issues*", lines)
+	}
+	blocks = append(blocks,
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, radio, false, false), nil, nil),
+	)
+
+	footer := fmt.Sprintf(":lock: Closed %s", r.ClosedAt.Local().Format("01/02/06 15:04 MST"))
+	if len(exported) > 0 {
+		footer += " · Full report: `" + strings.Join(exported, "`, `") + "`"
+	}
+	blocks = append(blocks,
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, footer, false, false)),
+	)
+	return blocks
+}
+
+// bulletSection joins a title and bullet lines into one section's markdown, dropping trailing
+// lines (with a count) rather than letting Slack reject the whole message for an oversized block.
+func bulletSection(title string, lines []string) string {
+	// Room for the "…and N more" note, so appending it can't itself overflow.
+	const noteReserve = 64
+	text := title
+	for i, line := range lines {
+		if len(text)+1+len(line) > slackSectionTextLimit-noteReserve {
+			return text + fmt.Sprintf("\n_…and %d more in the full report._", len(lines)-i)
+		}
+		text += "\n" + line
+	}
+	return text
+}
+
+// BuildDigestBlocks renders the shift digest: a one-line tally for the window, then one line
+// per closed incident, oldest first. The CSV posted in the thread carries the same rows.
+func BuildDigestBlocks(start, end time.Time, records []IncidentRecord) []slack.Block {
+	window := fmt.Sprintf("%s → %s", start.Local().Format("Mon 15:04"), end.Local().Format("Mon 15:04 MST"))
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(
+			slack.NewTextBlockObject(slack.PlainTextType, "Shift Digest :sunrise:", true, false),
+		),
+	}
+	if len(records) == 0 {
+		return append(blocks,
+			slack.NewSectionBlock(
+				slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s* — no incidents closed.", window), false, false),
+				nil, nil,
+			),
+		)
+	}
+
+	var falseAlarms, sar int
+	lines := make([]string, 0, len(records))
+	for _, r := range records {
+		if r.FalseAlarm {
+			falseAlarms++
+		}
+		if r.SARNotified {
+			sar++
+		}
+		lines = append(lines, digestLine(r))
+	}
+	tally := fmt.Sprintf("*%s* — %d closed · %d false alarm · %d SAR notified", window, len(records), falseAlarms, sar)
+
+	return append(blocks,
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, tally, false, false), nil, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, bulletSection("*Incidents*", lines), false, false), nil, nil),
+		slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, ":page_facing_up: CSV in thread.", false, false),
+		),
+	)
+}
+
+// digestLine is one incident's row in the digest: channel, dispatch time and duration when
+// known, headline and outcome, then flags.
+func digestLine(r IncidentRecord) string {
+	var b strings.Builder
+	b.WriteString("• `")
+	b.WriteString(strings.Join(r.TACChannels, ", "))
+	b.WriteString("` ")
+	if r.DispatchedAt.IsZero() {
+		b.WriteString("closed " + r.ClosedAt.Local().Format("15:04"))
+	} else {
+		b.WriteString(r.DispatchedAt.Local().Format("15:04") + " · " + formatReportDuration(r.Duration()))
+	}
+	headline := r.Headline
+	if headline == "" {
+		headline = "_no live summary_"
+	}
+	b.WriteString(" — " + headline)
+	if r.Outcome != "" {
+		b.WriteString(" — _" + r.Outcome + "_")
+	}
+	if r.SARNotified {
+		b.WriteString(" :white_check_mark: SAR")
+	}
+	if r.FalseAlarm {
+		b.WriteString(" :no_entry_sign: false alarm")
+	}
+	return b.String()
+}
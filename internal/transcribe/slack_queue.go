@@ -0,0 +1,387 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/slack-go/slack"
+)
+
+// Durable outbound Slack queue (SLACK_QUEUE_ENABLED). Fire-and-forget Slack traffic — thread
+// replies, chat.updates of the live interpretation and the parent alert, the channel-closed
+// notice — is appended to a Dragonfly stream and drained by RunSlackQueue, so a Slack outage
+// delays those messages instead of dropping them (the old path logged and moved on).
+//
+// Data model:
+//   STREAM <SLACK_QUEUE_STREAM>           : entries {payload: JSON outboundSlackMessage}
+//   GROUP  slack_senders                  : one consumer per replica (hostname-pid)
+//   STRING slackq_latest:<channel>:<ts>   : stream ID of the newest queued chat.update for ts
+//
+// chat.updates are coalesced: each update records its own stream ID in slackq_latest, and the
+// sender skips any update whose ID is older than the recorded one — the newer entry carries the
+// full message, so the stale render never has to hit Slack. Posts are never coalesced.
+//
+// Messages whose ts the caller needs back (the rescue alert, the first live-interpretation
+// post) stay on the synchronous sendSlackWithRetry path.
+
+const (
+	slackQueueGroup        = "slack_senders"
+	slackQueueLatestKeyFmt = "slackq_latest:%s:%s"
+	slackQueueLatestTTL    = 1 * time.Hour
+	slackQueueReadCount    = 16
+	slackQueueReadBlock    = 2 * time.Second
+	slackQueuePayloadField = "payload"
+)
+
+type slackOpKind string
+
+const (
+	slackOpPost   slackOpKind = "post"
+	slackOpUpdate slackOpKind = "update"
+)
+
+// outboundSlackMessage is the serializable form of a Slack call. slack.MsgOption is a closure
+// and can't be persisted, so the queue carries the handful of options this service uses and
+// rebuilds them at send time.
+type outboundSlackMessage struct {
+	Kind      slackOpKind  `json:"kind"`
+	ChannelID string       `json:"channel_id"`
+	ThreadTS  string       `json:"thread_ts,omitempty"`
+	TS        string       `json:"ts,omitempty"` // chat.update target
+	Blocks    slack.Blocks `json:"blocks"`
+	Text      string       `json:"text,omitempty"`
+	AsUser    bool         `json:"as_user,omitempty"`
+	Broadcast bool         `json:"broadcast,omitempty"`
+	// Talkgroup is carried for log context only.
+	Talkgroup string `json:"talkgroup,omitempty"`
+}
+
+func (m *outboundSlackMessage) options() []slack.MsgOption {
+	opts := []slack.MsgOption{slack.MsgOptionBlocks(m.Blocks.BlockSet...)}
+	if m.ThreadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(m.ThreadTS))
+	}
+	if m.AsUser {
+		opts = append(opts, slack.MsgOptionAsUser(true))
+	}
+	if m.Text != "" {
+		opts = append(opts, slack.MsgOptionText(m.Text, false))
+	}
+	if m.Broadcast {
+		opts = append(opts, slack.MsgOptionBroadcast())
+	}
+	return opts
+}
+
+// deliverSlack sends a message whose ts the caller doesn't need. With the queue enabled it is
+// enqueued durably and this returns as soon as Dragonfly accepts it; if the enqueue itself fails
+// (Dragonfly unreachable) it falls back to an inline send so the message isn't lost to a
+// second outage. With the queue disabled it is always sent inline through retrySlack.
+func (tc *TranscribeClient) deliverSlack(ctx context.Context, msg outboundSlackMessage) error {
+	if msg.ChannelID == "" {
+		msg.ChannelID = tc.config.SlackChannelID
+	}
+	if tc.config.SlackQueueEnabled {
+		err := tc.enqueueSlack(ctx, msg)
+		if err == nil {
+			return nil
+		}
+		slog.Warn("slack queue: enqueue failed, sending inline",
+			slog.String("error", err.Error()),
+			slog.String("kind", string(msg.Kind)),
+			slog.String("talkgroup", msg.Talkgroup))
+	}
+	return tc.sendSlackInline(ctx, &msg)
+}
+
+func (tc *TranscribeClient) sendSlackInline(ctx context.Context, msg *outboundSlackMessage) error {
+	_, err := tc.retrySlack(ctx, msg.Talkgroup, func() (string, error) {
+		return "", tc.sendOutboundOnce(ctx, msg)
+	})
+	return err
+}
+
+func (tc *TranscribeClient) sendOutboundOnce(ctx context.Context, msg *outboundSlackMessage) error {
+	if msg.Kind == slackOpUpdate {
+		return tc.updateSlackOnce(ctx, msg.ChannelID, msg.TS, msg.options()...)
+	}
+	sendCtx, cancel := context.WithTimeout(ctx, tc.config.SlackTimeout)
+	defer cancel()
+	_, _, _, err := tc.slackClient.SendMessageContext(sendCtx, msg.ChannelID, msg.options()...)
+	return err
+}
+
+func (tc *TranscribeClient) enqueueSlack(ctx context.Context, msg outboundSlackMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal outbound slack message: %w", err)
+	}
+	id, err := tc.dragonflyClient.XAdd(ctx, tc.config.SlackQueueStream, tc.config.SlackQueueMaxLen,
+		map[string]interface{}{slackQueuePayloadField: string(payload)})
+	if err != nil {
+		return fmt.Errorf("xadd outbound slack message: %w", err)
+	}
+	if msg.Kind == slackOpUpdate {
+		// Best-effort: if this write fails the older queued update isn't suppressed, but it still
+		// can't win — the sender only skips entries OLDER than the recorded ID, so this entry
+		// (sent after the older one) lands last either way.
+		if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(slackQueueLatestKeyFmt, msg.ChannelID, msg.TS), slackQueueLatestTTL, id); err != nil {
+			slog.Warn("slack queue: failed to record latest update id; older updates won't be coalesced",
+				slog.String("error", err.Error()), slog.String("ts", msg.TS))
+		}
+	}
+	return nil
+}
+
+// RunSlackQueue is the long-running sender for the durable outbound queue. Intended to be run
+// as a goroutine alongside the worker pool and sweeper; returns immediately when
+// SLACK_QUEUE_ENABLED is false. Entries are processed one at a time so per-channel pacing and
+// ordering within a replica are trivially preserved.
+func (tc *TranscribeClient) RunSlackQueue(ctx context.Context) {
+	if !tc.config.SlackQueueEnabled {
+		return
+	}
+	stream := tc.config.SlackQueueStream
+	consumer := slackQueueConsumerName()
+
+	for {
+		err := tc.dragonflyClient.XGroupCreateMkStream(ctx, stream, slackQueueGroup)
+		if err == nil {
+			break
+		}
+		slog.Error("slack queue: failed to create consumer group, retrying", slog.String("error", err.Error()))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	pacer := newChannelPacer(tc.config.SlackQueueChannelInterval)
+	slog.Info("slack queue sender started",
+		slog.String("stream", stream),
+		slog.String("consumer", consumer),
+		slog.Duration("channel_interval", tc.config.SlackQueueChannelInterval))
+
+	for {
+		if ctx.Err() != nil {
+			slog.Info("slack queue sender stopping")
+			return
+		}
+
+		// Reclaim entries abandoned by a crashed replica (or by this one before a restart)
+		// before reading new work, so old messages don't starve behind a steady stream.
+		msgs, err := tc.dragonflyClient.XAutoClaim(ctx, stream, slackQueueGroup, consumer, tc.config.SlackQueueClaimIdle, slackQueueReadCount)
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("slack queue: xautoclaim failed", slog.String("error", err.Error()))
+		}
+		if len(msgs) == 0 {
+			msgs, err = tc.dragonflyClient.XReadGroup(ctx, stream, slackQueueGroup, consumer, slackQueueReadCount, slackQueueReadBlock)
+			if err != nil {
+				if ctx.Err() != nil {
+					continue
+				}
+				slog.Error("slack queue: xreadgroup failed", slog.String("error", err.Error()))
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+				}
+				continue
+			}
+		}
+
+		for _, m := range msgs {
+			tc.processQueuedSlack(ctx, consumer, m, pacer)
+		}
+	}
+}
+
+// processQueuedSlack delivers one stream entry, retrying transient failures with backoff.
+// The entry is acked on success, on a permanent failure, and when it has been superseded; it
+// is deliberately left pending on shutdown so the next sender reclaims it.
+func (tc *TranscribeClient) processQueuedSlack(ctx context.Context, consumer string, m redis.XMessage, pacer *channelPacer) {
+	raw, _ := m.Values[slackQueuePayloadField].(string)
+	var msg outboundSlackMessage
+	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+		slog.Error("slack queue: dropping undecodable entry", slog.String("error", err.Error()), slog.String("id", m.ID))
+		tc.ackQueuedSlack(ctx, m.ID)
+		return
+	}
+
+	maxAttempts := tc.config.SlackQueueMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	bo := newSlackBackOff(tc.config.SlackQueueMaxBackoff)
+
+	for attempt := 1; ; attempt++ {
+		// Re-checked on every attempt: during an outage newer renders pile up behind this one,
+		// and once any of them exists there's no point retrying the stale version.
+		if msg.Kind == slackOpUpdate && tc.slackUpdateSuperseded(ctx, &msg, m.ID) {
+			slog.Debug("slack queue: skipping superseded chat.update", slog.String("ts", msg.TS), slog.String("id", m.ID))
+			tc.ackQueuedSlack(ctx, m.ID)
+			return
+		}
+
+		if err := pacer.wait(ctx, msg.ChannelID); err != nil {
+			return
+		}
+
+		err := tc.sendOutboundOnce(ctx, &msg)
+		if err == nil {
+			tc.ackQueuedSlack(ctx, m.ID)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		wait, retryable := classifySlackError(err)
+		if !retryable || attempt >= maxAttempts {
+			slog.Error("slack queue: dropping message after failed delivery",
+				slog.String("error", err.Error()),
+				slog.Bool("retryable", retryable),
+				slog.Int("attempts", attempt),
+				slog.String("kind", string(msg.Kind)),
+				slog.String("talkgroup", msg.Talkgroup),
+				slog.String("id", m.ID))
+			tc.ackQueuedSlack(ctx, m.ID)
+			return
+		}
+		if wait == 0 {
+			wait = bo.NextBackOff()
+		}
+		slog.Warn("slack queue: delivery failed, retrying",
+			slog.String("error", err.Error()),
+			slog.Int("attempt", attempt),
+			slog.Duration("retry_in", wait),
+			slog.String("kind", string(msg.Kind)),
+			slog.String("talkgroup", msg.Talkgroup))
+
+		// Reset the entry's idle clock so another replica's XAutoClaim doesn't take it over
+		// while this sender is still working through the backoff.
+		if err := tc.dragonflyClient.XClaimTouch(ctx, tc.config.SlackQueueStream, slackQueueGroup, consumer, m.ID); err != nil {
+			slog.Warn("slack queue: failed to refresh pending entry", slog.String("error", err.Error()), slog.String("id", m.ID))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (tc *TranscribeClient) ackQueuedSlack(ctx context.Context, id string) {
+	if err := tc.dragonflyClient.XAck(ctx, tc.config.SlackQueueStream, slackQueueGroup, id); err != nil {
+		slog.Warn("slack queue: xack failed; entry may be re-sent after reclaim", slog.String("error", err.Error()), slog.String("id", id))
+	}
+}
+
+// slackUpdateSuperseded reports whether a newer chat.update for the same message is queued.
+// Read errors and a missing key mean "not superseded" — sending a redundant update is harmless,
+// skipping the only one is not.
+func (tc *TranscribeClient) slackUpdateSuperseded(ctx context.Context, msg *outboundSlackMessage, id string) bool {
+	latest, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(slackQueueLatestKeyFmt, msg.ChannelID, msg.TS))
+	if err != nil || latest == "" {
+		return false
+	}
+	return compareStreamIDs(latest, id) > 0
+}
+
+// compareStreamIDs orders two stream IDs ("<ms>-<seq>") numerically, returning -1, 0 or 1.
+// Malformed IDs fall back to a plain string comparison.
+func compareStreamIDs(a, b string) int {
+	aMS, aSeq, aOK := splitStreamID(a)
+	bMS, bSeq, bOK := splitStreamID(b)
+	if !aOK || !bOK {
+		return strings.Compare(a, b)
+	}
+	switch {
+	case aMS != bMS:
+		if aMS < bMS {
+			return -1
+		}
+		return 1
+	case aSeq != bSeq:
+		if aSeq < bSeq {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+func splitStreamID(id string) (uint64, uint64, bool) {
+	msPart, seqPart, found := strings.Cut(id, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if !found {
+		return ms, 0, true
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
+
+func slackQueueConsumerName() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "transcribe"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// channelPacer spaces Slack calls per channel. Slack allows roughly one chat.postMessage per
+// second per channel (bursts are tolerated briefly, then 429'd); pacing on our side keeps a
+// post-outage backlog from tripping the limit and stalling the queue on Retry-After waits.
+type channelPacer struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func newChannelPacer(interval time.Duration) *channelPacer {
+	return &channelPacer{interval: interval, now: time.Now, next: map[string]time.Time{}}
+}
+
+// reserve claims the next send slot for channel and returns how long to wait for it.
+func (p *channelPacer) reserve(channel string) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	slot := p.next[channel]
+	if slot.Before(now) {
+		slot = now
+	}
+	p.next[channel] = slot.Add(p.interval)
+	return slot.Sub(now)
+}
+
+func (p *channelPacer) wait(ctx context.Context, channel string) error {
+	d := p.reserve(channel)
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifySlackError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantRetryable bool
+		wantWait      time.Duration
+	}{
+		{name: "rate limited", err: &slack.RateLimitedError{RetryAfter: 3 * time.Second}, wantRetryable: true, wantWait: 3 * time.Second},
+		{name: "wrapped rate limited", err: fmt.Errorf("post: %w", &slack.RateLimitedError{RetryAfter: time.Second}), wantRetryable: true, wantWait: time.Second},
+		{name: "502", err: slack.StatusCodeError{Code: 502, Status: "502 Bad Gateway"}, wantRetryable: true},
+		{name: "404", err: slack.StatusCodeError{Code: 404, Status: "404 Not Found"}, wantRetryable: false},
+		{name: "transient api error", err: slack.SlackErrorResponse{Err: "internal_error"}, wantRetryable: true},
+		{name: "permanent api error", err: slack.SlackErrorResponse{Err: "channel_not_found"}, wantRetryable: false},
+		{name: "network error", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, wantRetryable: true},
+		{name: "per-attempt timeout", err: context.DeadlineExceeded, wantRetryable: true},
+		{name: "cancelled", err: context.Canceled, wantRetryable: false},
+		{name: "unknown", err: errors.New("boom"), wantRetryable: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, retryable := classifySlackError(tt.err)
+			assert.Equal(t, tt.wantRetryable, retryable)
+			assert.Equal(t, tt.wantWait, wait)
+		})
+	}
+}
+
+func TestCompareStreamIDs(t *testing.T) {
+	assert.Equal(t, 0, compareStreamIDs("1700000000000-0", "1700000000000-0"))
+	assert.Equal(t, 1, compareStreamIDs("1700000000001-0", "1700000000000-5"))
+	assert.Equal(t, -1, compareStreamIDs("1700000000000-2", "1700000000000-10"), "sequence compares numerically, not lexically")
+	assert.Equal(t, 1, compareStreamIDs("10000000000000-0", "9999999999999-0"), "ms compares numerically across digit counts")
+	assert.Equal(t, 0, compareStreamIDs("42", "42-0"))
+}
+
+func TestChannelPacer_SpacesCallsPerChannel(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	p := newChannelPacer(time.Second)
+	p.now = func() time.Time { return now }
+
+	assert.Equal(t, time.Duration(0), p.reserve("C1"), "first call goes immediately")
+	assert.Equal(t, time.Second, p.reserve("C1"), "second call waits one interval")
+	assert.Equal(t, 2*time.Second, p.reserve("C1"), "slots stack while the backlog drains")
+	assert.Equal(t, time.Duration(0), p.reserve("C2"), "channels are paced independently")
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, time.Duration(0), p.reserve("C1"), "an idle channel doesn't bank slots from the past")
+}
+
+func TestOutboundSlackMessage_RoundTripsBlocks(t *testing.T) {
+	in := outboundSlackMessage{
+		Kind:      slackOpUpdate,
+		ChannelID: "C-TEST",
+		TS:        "ts-1",
+		Blocks:    slack.Blocks{BlockSet: BuildChannelClosedBlocks(&ChannelClosedBlocksInput{Channel: "TAC1", ClosedAt: time.Unix(1_700_000_000, 0)})},
+		Text:      "closed",
+	}
+	raw, err := json.Marshal(in)
+	require.NoError(t, err)
+
+	var out outboundSlackMessage
+	require.NoError(t, json.Unmarshal(raw, &out))
+	assert.Equal(t, in.Kind, out.Kind)
+	assert.Equal(t, in.TS, out.TS)
+	require.Len(t, out.Blocks.BlockSet, len(in.Blocks.BlockSet))
+	for i := range in.Blocks.BlockSet {
+		assert.Equal(t, in.Blocks.BlockSet[i].BlockType(), out.Blocks.BlockSet[i].BlockType())
+	}
+	assert.Len(t, out.options(), 2, "update carries blocks + fallback text only")
+}
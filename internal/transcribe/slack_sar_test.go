@@ -6,8 +6,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/searchandrescuegg/transcribe/internal/cad"
 	"github.com/searchandrescuegg/transcribe/internal/ml"
 	"github.com/searchandrescuegg/transcribe/internal/transcribe"
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -68,10 +70,297 @@ func TestBuildLiveInterpretationBlocks_SARNotifiedBadge(t *testing.T) {
 	updated := time.Date(2026, 7, 9, 10, 15, 0, 0, time.UTC)
 
 	notified := marshalBlocks(t, transcribe.BuildLiveInterpretationBlocks(
-		&ml.RescueSummary{Headline: "hiker down", SARNotified: true}, updated))
+		&ml.RescueSummary{Headline: "hiker down", SARNotified: true}, updated, nil, transcribe.LiveInterpretationCAD{}))
 	assert.Contains(t, notified, sarBadgeText)
 
 	quiet := marshalBlocks(t, transcribe.BuildLiveInterpretationBlocks(
-		&ml.RescueSummary{Headline: "hiker down", SARNotified: false}, updated))
+		&ml.RescueSummary{Headline: "hiker down", SARNotified: false}, updated, nil, transcribe.LiveInterpretationCAD{}))
 	assert.False(t, strings.Contains(quiet, sarBadgeText), "no badge when SAR not notified")
 }
+
+func TestBuildLiveInterpretationStreamingBlocks_Footer(t *testing.T) {
+	summary := &ml.RescueSummary{Headline: "hiker down"}
+
+	streaming := marshalBlocks(t, transcribe.BuildLiveInterpretationStreamingBlocks(summary, nil, transcribe.LiveInterpretationCAD{}, false))
+	assert.Contains(t, streaming, "hiker down")
+	assert.Contains(t, streaming, "Updating from the latest TAC traffic")
+
+	interrupted := marshalBlocks(t, transcribe.BuildLiveInterpretationStreamingBlocks(summary, nil, transcribe.LiveInterpretationCAD{}, true))
+	assert.Contains(t, interrupted, "Update interrupted")
+	assert.NotContains(t, interrupted, "Updating from the latest TAC traffic")
+}
+
+func TestBuildThreadCommunicationBlocks_AudioURL(t *testing.T) {
+	base := transcribe.ThreadCommunicationBlocksInput{
+		Channel: "Fire TAC 3",
+		Message: "Engine 1838 at the trailhead",
+		TS:      time.Date(2026, 7, 9, 10, 10, 0, 0, time.UTC),
+	}
+
+	t.Run("no button without a url", func(t *testing.T) {
+		in := base
+		got := marshalBlocks(t, transcribe.BuildThreadCommunicationBlocks(&in))
+		assert.NotContains(t, got, "play-clip-button")
+	})
+
+	t.Run("play clip button links the presigned url", func(t *testing.T) {
+		in := base
+		in.AudioURL = "https://s3.example/bucket/1385-1.wav?X-Amz-Signature=abc"
+		blocks := transcribe.BuildThreadCommunicationBlocks(&in)
+		got := marshalBlocks(t, blocks)
+		assert.Contains(t, got, "play-clip-button")
+		assert.Contains(t, got, "X-Amz-Signature=abc")
+		assert.Equal(t, "divider", string(blocks[len(blocks)-1].BlockType()), "divider still closes the reply")
+	})
+}
+
+func TestBuildRescueTrailBlocks_MultiTAC(t *testing.T) {
+	in := transcribe.RescueTrailBlocksInput{
+		TACChannel:        "TAC3",
+		TranscriptionText: "Rescue Trail TAC 3, Mount Si trailhead",
+		ExpiresAt:         time.Date(2026, 7, 9, 10, 10, 0, 0, time.UTC),
+		DispatchTGID:      transcribe.FireDispatch1TGID,
+		TACTalkgroupTGID:  "1385",
+	}
+
+	t.Run("single TAC offers Add but not Drop", func(t *testing.T) {
+		got := marshalBlocks(t, transcribe.BuildRescueTrailBlocks(&in))
+		assert.Contains(t, got, transcribe.ActionIDRescueAddTAC)
+		assert.NotContains(t, got, transcribe.ActionIDRescueDropTAC)
+		assert.Contains(t, got, "FTAC 3 transcription has been activated")
+	})
+
+	t.Run("additional TAC joins status line, listen link and Drop select", func(t *testing.T) {
+		multi := in
+		multi.AdditionalTACChannels = []string{"TAC8"}
+		got := marshalBlocks(t, transcribe.BuildRescueTrailBlocks(&multi))
+		assert.Contains(t, got, "FTAC 3 + FTAC 8 transcription has been activated")
+		assert.Contains(t, got, "filter-code=1385,1963,1399")
+		assert.Contains(t, got, transcribe.ActionIDRescueDropTAC)
+
+		// The Add select must not offer a TAC the rescue already monitors.
+		var blocks []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(got), &blocks))
+		for _, b := range blocks {
+			elements, _ := b["elements"].([]any)
+			for _, e := range elements {
+				el, _ := e.(map[string]any)
+				if el["action_id"] != transcribe.ActionIDRescueAddTAC {
+					continue
+				}
+				opts, _ := json.Marshal(el["options"])
+				assert.NotContains(t, string(opts), `"1385"`)
+				assert.NotContains(t, string(opts), `"1963"`)
+				assert.Contains(t, string(opts), `"1389"`)
+			}
+		}
+	})
+}
+
+func TestBuildRescueTrailBlocks_ActionsCarryIncidentID(t *testing.T) {
+	in := transcribe.RescueTrailBlocksInput{
+		TACChannel:        "TAC1",
+		TranscriptionText: "Rescue Trail TAC 1",
+		ExpiresAt:         time.Date(2026, 7, 9, 10, 10, 0, 0, time.UTC),
+		DispatchTGID:      transcribe.FireDispatch1TGID,
+		TACTalkgroupTGID:  "1389",
+		IncidentID:        "01J9ZC8Q3V7N4X2K5M6P8R0T1W",
+	}
+	got := marshalBlocks(t, transcribe.BuildRescueTrailBlocks(&in))
+	assert.Contains(t, got, `"block_id":"rescue_actions:01J9ZC8Q3V7N4X2K5M6P8R0T1W"`)
+	assert.NotContains(t, got, `"block_id":"rescue_actions:1389"`)
+
+	// Alerts rendered for rescues that predate incident IDs fall back to the TGID.
+	in.IncidentID = ""
+	got = marshalBlocks(t, transcribe.BuildRescueTrailBlocks(&in))
+	assert.Contains(t, got, `"block_id":"rescue_actions:1389"`)
+}
+
+func TestBuildSplitPromptBlocks(t *testing.T) {
+	got := marshalBlocks(t, transcribe.BuildSplitPromptBlocks("TAC3", "Different trail.", "01J9ZC8Q3V7N4X2K5M6P8R0T1W"))
+	assert.Contains(t, got, transcribe.ActionIDRescueSplit)
+	assert.Contains(t, got, `"value":"01J9ZC8Q3V7N4X2K5M6P8R0T1W"`)
+	assert.Contains(t, got, `"block_id":"rescue_split:01J9ZC8Q3V7N4X2K5M6P8R0T1W"`)
+	assert.Contains(t, got, "Different trail.")
+	assert.Contains(t, got, "confirm", "split moves a TAC, so it needs a confirmation dialog")
+}
+
+func TestBuildClosingWarningBlocks(t *testing.T) {
+	closesAt := time.Date(2026, 3, 1, 14, 5, 0, 0, time.Local)
+
+	got := marshalBlocks(t, transcribe.BuildClosingWarningBlocks("TAC3", closesAt, true))
+	assert.Contains(t, got, "TAC3 monitoring closes at 14:05")
+	assert.Contains(t, got, "More traffic on the channel keeps it open")
+
+	got = marshalBlocks(t, transcribe.BuildClosingWarningBlocks("TAC3", closesAt, false))
+	assert.NotContains(t, got, "traffic", "past the lifetime cap (or without the idle policy) only Extend helps")
+	assert.Contains(t, got, "Extend")
+}
+
+func TestBuildCloseSuggestionBlocks(t *testing.T) {
+	closesAt := time.Date(2026, 3, 1, 14, 15, 0, 0, time.Local)
+	got := marshalBlocks(t, transcribe.BuildCloseSuggestionBlocks("TAC3", "Resolved — patient transported", "01J9ZC8Q3V7N4X2K5M6P8R0T1W", closesAt))
+	assert.Contains(t, got, "Looks resolved")
+	assert.Contains(t, got, "closes automatically at 14:15")
+	assert.Contains(t, got, `"action_id":"`+transcribe.ActionIDRescueClose+`"`, "Close reuses the alert's Close action")
+	assert.Contains(t, got, `"action_id":"`+transcribe.ActionIDRescueKeep+`"`)
+	assert.Contains(t, got, `"block_id":"`+transcribe.CloseSuggestionBlockIDPrefix+`01J9ZC8Q3V7N4X2K5M6P8R0T1W"`)
+}
+
+func TestBuildAfterActionReportBlocks(t *testing.T) {
+	r := transcribe.IncidentReport{
+		IncidentID:  "01J9ZC8Q3V7N4X2K5M6P8R0T1W",
+		TACChannels: []string{"TAC3"},
+		ClosedAt:    time.Date(2026, 3, 1, 14, 15, 0, 0, time.Local),
+		Report: ml.AfterActionReport{
+			Headline:       "Injured hiker carried out from Rattlesnake Ledge",
+			Timeline:       []ml.RescueSummaryEvent{{CapturedAt: "13:05:00", Description: "Engine 171 on scene"}},
+			Units:          []string{"Engine 171", "Medic 104"},
+			Duration:       "1h10m",
+			PatientOutcome: "Transported to Overlake",
+			SARInvolvement: "Not involved",
+		},
+	}
+	got := marshalBlocks(t, transcribe.BuildAfterActionReportBlocks(r, []string{"reports/2026/03/01J9ZC8Q3V7N4X2K5M6P8R0T1W.md"}))
+	assert.Contains(t, got, "After-Action Report")
+	assert.Contains(t, got, "Engine 171 on scene")
+	assert.Contains(t, got, "Transported to Overlake")
+	assert.Contains(t, got, "None noted.", "radio issues section is always present")
+	assert.Contains(t, got, "reports/2026/03/01J9ZC8Q3V7N4X2K5M6P8R0T1W.md")
+
+	// A timeline longer than one Slack section allows is cut short, not rejected.
+	for i := 0; i < 200; i++ {
+		r.Report.Timeline = append(r.Report.Timeline, ml.RescueSummaryEvent{CapturedAt: "13:30:00", Description: "Crews continue the carry-out down the switchbacks"})
+	}
+	blocks := transcribe.BuildAfterActionReportBlocks(r, nil)
+	for _, b := range blocks {
+		if section, ok := b.(*slack.SectionBlock); ok && section.Text != nil {
+			assert.LessOrEqual(t, len(section.Text.Text), 3000)
+		}
+	}
+	assert.Contains(t, marshalBlocks(t, blocks), "more in the full report")
+}
+
+func TestBuildDigestBlocks(t *testing.T) {
+	start := time.Date(2026, 3, 9, 7, 0, 0, 0, time.Local)
+	end := start.Add(24 * time.Hour)
+
+	got := marshalBlocks(t, transcribe.BuildDigestBlocks(start, end, nil))
+	assert.Contains(t, got, "Shift Digest")
+	assert.Contains(t, got, "no incidents closed")
+
+	got = marshalBlocks(t, transcribe.BuildDigestBlocks(start, end, []transcribe.IncidentRecord{
+		{TACChannels: []string{"TAC3"}, DispatchedAt: start.Add(6 * time.Hour), ClosedAt: start.Add(7*time.Hour + 12*time.Minute),
+			Headline: "Injured hiker", Outcome: "Resolved — patient transported", SARNotified: true},
+		{TACChannels: []string{"TAC1"}, ClosedAt: start.Add(9 * time.Hour), FalseAlarm: true},
+	}))
+	assert.Contains(t, got, "2 closed · 1 false alarm · 1 SAR notified")
+	assert.Contains(t, got, "13:00 · 1h12m — Injured hiker")
+	assert.Contains(t, got, "no live summary", "cancelled before any summary ran")
+	assert.Contains(t, got, "CSV in thread")
+}
+
+func TestMapLinkBlocks(t *testing.T) {
+	link := &transcribe.MapLink{Label: "Mount Si Trailhead (approx.)", URL: "https://caltopo.com/map.html#ll=47.48790,-121.72320&z=15&b=mbt"}
+
+	in := transcribe.RescueTrailBlocksInput{
+		TACChannel:        "TAC3",
+		TranscriptionText: "Rescue Trail TAC 3, Mount Si trailhead",
+		ExpiresAt:         time.Date(2026, 7, 9, 10, 10, 0, 0, time.UTC),
+		DispatchTGID:      transcribe.FireDispatch1TGID,
+	}
+	without := transcribe.BuildRescueTrailBlocks(&in)
+	assert.NotContains(t, marshalBlocks(t, without), transcribe.ActionIDMapLink)
+
+	in.MapLink = link
+	in.SARNotified = true
+	with := transcribe.BuildRescueTrailBlocks(&in)
+	require.Len(t, with, len(without)+2, "map button and SAR badge")
+	mapBlock := marshalBlocks(t, with[6]) // header, badge, divider, channel, transcription, OpenMHz, map
+	assert.Contains(t, mapBlock, transcribe.ActionIDMapLink)
+	assert.Contains(t, mapBlock, "Mount Si Trailhead (approx.)")
+	assert.Contains(t, mapBlock, "caltopo.com")
+
+	summary := &ml.RescueSummary{Headline: "hiker down", Location: "Mount Si trailhead"}
+	updated := time.Date(2026, 7, 9, 10, 15, 0, 0, time.UTC)
+	assert.NotContains(t, marshalBlocks(t, transcribe.BuildLiveInterpretationBlocks(summary, updated, nil, transcribe.LiveInterpretationCAD{})), transcribe.ActionIDMapLink)
+	assert.Contains(t, marshalBlocks(t, transcribe.BuildLiveInterpretationBlocks(summary, updated, link, transcribe.LiveInterpretationCAD{})), transcribe.ActionIDMapLink)
+}
+
+func TestBuildCADRescueBlocks(t *testing.T) {
+	inc := cad.Incident{
+		ID:         "inc-42",
+		CallType:   "RESCUE",
+		Address:    "Mount Si Trailhead, North Bend",
+		ReceivedAt: time.Date(2026, 7, 14, 14, 1, 0, 0, time.UTC),
+		Units:      []cad.Unit{{ID: "B171", Status: "On Scene"}, {ID: "E171"}},
+	}
+	got := marshalBlocks(t, transcribe.BuildCADRescueBlocks(inc, nil))
+	assert.Contains(t, got, "no radio dispatch detected yet")
+	assert.Contains(t, got, "Mount Si Trailhead, North Bend")
+	assert.Contains(t, got, "B171 (On Scene), E171")
+	assert.Contains(t, got, "CAD incident inc-42")
+	assert.NotContains(t, got, transcribe.ActionIDMapLink)
+
+	got = marshalBlocks(t, transcribe.BuildCADRescueBlocks(cad.Incident{ID: "inc-43"}, &transcribe.MapLink{Label: "CAD location", URL: "https://example.com"}))
+	assert.Contains(t, got, `*Address:*\nunknown`)
+	assert.Contains(t, got, transcribe.ActionIDMapLink)
+}
+
+func TestBuildLiveInterpretationBlocks_CADUnits(t *testing.T) {
+	summary := &ml.RescueSummary{Headline: "hiker down"}
+	updated := time.Date(2026, 7, 9, 10, 15, 0, 0, time.UTC)
+
+	assert.NotContains(t, marshalBlocks(t, transcribe.BuildLiveInterpretationBlocks(summary, updated, nil, transcribe.LiveInterpretationCAD{})), "CAD units")
+
+	got := marshalBlocks(t, transcribe.BuildLiveInterpretationBlocks(summary, updated, nil, transcribe.LiveInterpretationCAD{Units: []cad.Unit{
+		{ID: "B171", Status: "On Scene"},
+		{ID: "A181"},
+	}}))
+	assert.Contains(t, got, "*CAD units*")
+	assert.Contains(t, got, `B171  On Scene\n`)
+	assert.Contains(t, got, `A181  unknown\n`)
+}
+
+func TestBuildLiveInterpretationBlocks_CADMatch(t *testing.T) {
+	summary := &ml.RescueSummary{Headline: "hiker down"}
+	updated := time.Date(2026, 7, 9, 10, 15, 0, 0, time.UTC)
+	match := &cad.UnitContext{
+		Matched:    true,
+		IncidentID: "26-1",
+		CallType:   "RESCUE",
+		Address:    "Mount Si Trailhead",
+		Candidates: []cad.Candidate{
+			{IncidentID: "26-1", CallType: "RESCUE", Address: "Mount Si Trailhead", Score: 5, Overlap: []string{"mount", "trailhead"}},
+			{IncidentID: "26-2", CallType: "MEDICAL", Address: "Rattlesnake Lake Trailhead", Score: 2.5, Overlap: []string{"trailhead"}},
+			{IncidentID: "26-3", CallType: "TC"},
+			{IncidentID: "26-4", CallType: "AFA"},
+		},
+	}
+
+	got := marshalBlocks(t, transcribe.BuildLiveInterpretationBlocks(summary, updated, nil, transcribe.LiveInterpretationCAD{IncidentID: "inc-1", Match: match}))
+	assert.Contains(t, got, "*CAD match:* RESCUE — Mount Si Trailhead (26-1)")
+	assert.Contains(t, got, "• `5.0` RESCUE — Mount Si Trailhead (26-1) · shared: mount, trailhead ← chosen")
+	assert.Contains(t, got, "• `2.5` MEDICAL — Rattlesnake Lake Trailhead (26-2) · shared: trailhead")
+	assert.Contains(t, got, "• `0.0` TC (26-3) · no shared location words")
+	assert.Contains(t, got, "and 1 more in the dropdown")
+	assert.Contains(t, got, `"block_id":"`+transcribe.CADMatchBlockIDPrefix+`inc-1"`)
+	assert.Contains(t, got, transcribe.ActionIDCADPin)
+	assert.Contains(t, got, `"initial_option":{"text":{"type":"plain_text","text":"Automatic match","emoji":false},"value":"`+transcribe.CADPinAutomatic+`"}`)
+	assert.NotContains(t, got, `"expand":true`, "the explanation collapses behind See more")
+
+	match.Pinned, match.IncidentID, match.CallType, match.Address = true, "26-2", "MEDICAL", "Rattlesnake Lake Trailhead"
+	pinned := marshalBlocks(t, transcribe.BuildLiveInterpretationBlocks(summary, updated, nil, transcribe.LiveInterpretationCAD{IncidentID: "inc-1", Match: match}))
+	assert.Contains(t, pinned, "*CAD match (pinned):* MEDICAL — Rattlesnake Lake Trailhead (26-2)")
+	assert.Contains(t, pinned, `"initial_option":{"text":{"type":"plain_text","text":"MEDICAL — Rattlesnake Lake Trailhead (26-2)","emoji":false},"value":"26-2"}`)
+
+	match.Pinned, match.Matched = false, false
+	none := marshalBlocks(t, transcribe.BuildLiveInterpretationBlocks(summary, updated, nil, transcribe.LiveInterpretationCAD{IncidentID: "inc-1", Match: match}))
+	assert.Contains(t, none, "none confident")
+	assert.NotContains(t, none, "chosen")
+}
+
+func TestBuildUnitStatusBlocks(t *testing.T) {
+	got := marshalBlocks(t, transcribe.BuildUnitStatusBlocks([]string{"E171 Dispatched → En Route → On Scene", "M104 Dispatched"}, time.Now()))
+	assert.Contains(t, got, `E171 Dispatched → En Route → On Scene\nM104 Dispatched`)
+}
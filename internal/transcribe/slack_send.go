@@ -4,37 +4,71 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"net"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/slack-go/slack"
 )
 
+const (
+	defaultSlackSendMaxAttempts = 3
+	// slackSendMaxBackoff caps the wait between synchronous attempts. The synchronous path
+	// runs inside a Pulsar worker, so it must give up well inside WorkerTimeout and let the
+	// message nack rather than sit on a worker through a long outage.
+	slackSendMaxBackoff = 5 * time.Second
+)
+
 // FIX (review item #1): the old handleSlackRateLimit returned nil after waiting RetryAfter
 // but never actually re-sent the message, so any 429 silently dropped the alert. This wrapper
-// performs a single bounded retry against the same channel; non-rate-limit errors return as-is
-// and ctx cancellation aborts the wait. Returns the thread_ts of the posted message on success.
+// retries against the same channel up to SLACK_SEND_MAX_ATTEMPTS: 429s wait out Retry-After,
+// 5xx and network errors back off exponentially, permanent API errors return as-is, and ctx
+// cancellation aborts the wait. Returns the thread_ts of the posted message on success.
+//
+// Used for posts whose ts the caller needs immediately (the rescue alert, the first
+// live-interpretation message). Fire-and-forget traffic goes through deliverSlack instead,
+// which uses the durable queue when it is enabled.
 func (tc *TranscribeClient) sendSlackWithRetry(ctx context.Context, talkgroup string, opts ...slack.MsgOption) (string, error) {
-	ts, err := tc.sendSlackOnce(ctx, opts...)
-	if err == nil {
-		return ts, nil
-	}
+	return tc.retrySlack(ctx, talkgroup, func() (string, error) {
+		return tc.sendSlackOnce(ctx, opts...)
+	})
+}
 
-	var rate *slack.RateLimitedError
-	if !errors.As(err, &rate) || !rate.Retryable() {
-		return "", err
+// retrySlack runs call until it succeeds, fails permanently, or exhausts
+// SLACK_SEND_MAX_ATTEMPTS. Shared by the post and inline chat.update paths.
+func (tc *TranscribeClient) retrySlack(ctx context.Context, talkgroup string, call func() (string, error)) (string, error) {
+	attempts := tc.config.SlackSendMaxAttempts
+	if attempts <= 0 {
+		attempts = defaultSlackSendMaxAttempts
 	}
+	bo := newSlackBackOff(slackSendMaxBackoff)
 
-	slog.Warn("slack rate limited, retrying once",
-		slog.Duration("retry_after", rate.RetryAfter),
-		slog.String("talkgroup", talkgroup))
+	for attempt := 1; ; attempt++ {
+		ts, err := call()
+		if err == nil {
+			return ts, nil
+		}
 
-	select {
-	case <-ctx.Done():
-		return "", ctx.Err()
-	case <-time.After(rate.RetryAfter):
-	}
+		wait, retryable := classifySlackError(err)
+		if !retryable || attempt >= attempts || ctx.Err() != nil {
+			return "", err
+		}
+		if wait == 0 {
+			wait = bo.NextBackOff()
+		}
 
-	return tc.sendSlackOnce(ctx, opts...)
+		slog.Warn("slack call failed, retrying",
+			slog.String("error", err.Error()),
+			slog.Int("attempt", attempt),
+			slog.Duration("retry_in", wait),
+			slog.String("talkgroup", talkgroup))
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(wait):
+		}
+	}
 }
 
 func (tc *TranscribeClient) sendSlackOnce(ctx context.Context, opts ...slack.MsgOption) (string, error) {
@@ -43,3 +77,66 @@ func (tc *TranscribeClient) sendSlackOnce(ctx context.Context, opts ...slack.Msg
 	_, ts, _, err := tc.slackClient.SendMessageContext(sendCtx, tc.config.SlackChannelID, opts...)
 	return ts, err
 }
+
+func (tc *TranscribeClient) updateSlackOnce(ctx context.Context, channelID, ts string, opts ...slack.MsgOption) error {
+	updateCtx, cancel := context.WithTimeout(ctx, tc.config.SlackTimeout)
+	defer cancel()
+	_, _, _, err := tc.slackClient.UpdateMessageContext(updateCtx, channelID, ts, opts...)
+	return err
+}
+
+// transientSlackAPIErrors are ok:false error codes Slack documents as "try again later".
+// Everything else in a SlackErrorResponse (channel_not_found, message_not_found,
+// invalid_blocks, …) is a permanent failure that no amount of retrying will fix.
+var transientSlackAPIErrors = map[string]bool{
+	"internal_error":      true,
+	"fatal_error":         true,
+	"service_unavailable": true,
+	"request_timeout":     true,
+	"ratelimited":         true,
+}
+
+// classifySlackError decides whether a failed Slack call is worth retrying and, for rate
+// limits, the minimum wait Slack asked for. Caller-initiated cancellation is never retryable;
+// a per-attempt SlackTimeout expiry is (the caller checks its own ctx separately).
+func classifySlackError(err error) (time.Duration, bool) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return 0, false
+	}
+
+	var rate *slack.RateLimitedError
+	if errors.As(err, &rate) {
+		return rate.RetryAfter, rate.Retryable()
+	}
+
+	var status slack.StatusCodeError
+	if errors.As(err, &status) {
+		return 0, status.Retryable()
+	}
+
+	var apiErr slack.SlackErrorResponse
+	if errors.As(err, &apiErr) {
+		return 0, transientSlackAPIErrors[apiErr.Err]
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return 0, true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return 0, true
+	}
+	return 0, false
+}
+
+// newSlackBackOff returns an exponential backoff that never gives up on its own — callers
+// bound retries by attempt count instead, so a single slow attempt doesn't eat the budget.
+func newSlackBackOff(maxInterval time.Duration) *backoff.ExponentialBackOff {
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = 500 * time.Millisecond
+	bo.MaxInterval = maxInterval
+	bo.MaxElapsedTime = 0
+	bo.Reset()
+	return bo
+}
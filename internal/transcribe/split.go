@@ -0,0 +1,180 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+)
+
+// Split detection: a new dispatch onto a TAC that already has an active rescue is normally a
+// re-page of the same incident (handleAdditionalDispatch). Occasionally two unrelated rescues
+// are assigned the same TAC in a row. With SPLIT_DETECTION_ENABLED the re-page path compares
+// the two dispatches and, when they look distinct, appends a Split prompt to the re-page reply.
+// Pressing Split (internal/slackctl) posts a new alert for the dispatch as its own incident and
+// moves the TAC to it.
+//
+// The comparison is location-token overlap first — cheap, and it settles the common re-page
+// where both dispatches name the same trailhead — then, only for a token mismatch and only
+// with SPLIT_DETECTION_LLM_ENABLED, a structured "same incident?" call to confirm. Any doubt
+// resolves to "same incident": a missed split costs one Switch/Close click, a false prompt on
+// every re-page would train operators to ignore it.
+//
+//   STRING split_candidate:<candidate> : SplitCandidate JSON, TTL = activation window
+//   LIST   split_candidates:<incident>  : candidate IDs minted on the rescue's re-pages
+//
+// A candidate is keyed by the ID it will become, so the parent finds its own through
+// split_candidates: closing or cancelling the rescue deletes them with its other state
+// (DeleteIncidentState), and a Split prompt left in a closed rescue's thread answers "no longer
+// available" instead of opening a new incident from a dispatch nobody is monitoring.
+
+// splitCandidateKeyFmt holds a pending split until the operator acts or the window lapses.
+// Mirrored in internal/slackctl.
+const splitCandidateKeyFmt = "split_candidate:%s"
+
+// splitCandidatesKeyFmt indexes a rescue's split candidates by the parent's incident ID.
+const splitCandidatesKeyFmt = "split_candidates:%s"
+
+// SplitCandidate is everything slackctl needs to turn a re-page into its own incident without
+// re-running the pipeline. Exported so slackctl can decode it.
+type SplitCandidate struct {
+	// IncidentID is minted when the prompt posts and becomes the new incident's ID on split.
+	IncidentID string `json:"incident_id"`
+	// ParentIncidentID is the active rescue the re-page was deduped onto.
+	ParentIncidentID string    `json:"parent_incident_id"`
+	TGID             string    `json:"tgid"`
+	TACChannel       string    `json:"tac_channel"`
+	CallType         string    `json:"call_type,omitempty"`
+	Transcription    string    `json:"transcription"`
+	SourceTalkgroup  string    `json:"source_talkgroup"`
+	DispatchedAt     time.Time `json:"dispatched_at"`
+	// MapLink is the re-page's own resolved location, carried onto the split incident.
+	MapLink *MapLink `json:"map_link,omitempty"`
+}
+
+// dispatchBoilerplate are tokens every trail-rescue dispatch shares regardless of location:
+// call types, unit types and radio filler. Dropping them keeps the overlap about places.
+var dispatchBoilerplate = map[string]bool{
+	"rescue": true, "trail": true, "fire": true, "dispatch": true, "respond": true, "responding": true,
+	"engine": true, "medic": true, "battalion": true, "ladder": true, "unit": true, "units": true,
+	"channel": true, "with": true, "from": true, "that": true, "this": true, "have": true,
+	"near": true, "time": true, "report": true, "reported": true, "reporting": true, "caller": true,
+	"party": true, "patient": true, "year": true, "male": true, "female": true, "injury": true,
+	"injured": true, "hiker": true, "call": true, "additional": true, "emergency": true,
+}
+
+// locationTokens returns the significant word tokens of a dispatch, using the same rule as
+// cad.tokenize (lowercase alphanumeric runs of at least four characters), minus
+// dispatch boilerplate and all-digit tokens — the digits in a dispatch are mostly unit numbers,
+// which differ between an original page and a re-page of the same incident.
+func locationTokens(s string) map[string]bool {
+	out := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}) {
+		if len(field) < 4 || dispatchBoilerplate[field] || strings.Trim(field, "0123456789") == "" {
+			continue
+		}
+		out[field] = true
+	}
+	return out
+}
+
+// tokenOverlap is the share of the smaller token set found in the larger one. ok=false when
+// either side has no tokens, i.e. there is nothing to compare.
+func tokenOverlap(a, b map[string]bool) (overlap float64, ok bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return 0, false
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)), true
+}
+
+// assessSplit decides whether newDispatch looks like a different incident than the active
+// rescue. reason is operator-facing and only meaningful when distinct is true.
+func (tc *TranscribeClient) assessSplit(ctx context.Context, meta ClosureMeta, dm ml.DispatchMessage) (distinct bool, reason string) {
+	newText := dm.CleanedTranscription
+	if newText == "" || meta.Transcription == "" {
+		return false, ""
+	}
+
+	activeTokens, newTokens := locationTokens(meta.Transcription), locationTokens(newText)
+	overlap, ok := tokenOverlap(activeTokens, newTokens)
+	if !ok || overlap >= tc.config.SplitDetectionMinOverlap {
+		return false, ""
+	}
+	reason = fmt.Sprintf("Its location (%s) doesn't match the original dispatch (%s).",
+		sampleTokens(newTokens), sampleTokens(activeTokens))
+
+	if !tc.config.SplitDetectionLLMEnabled {
+		return true, reason
+	}
+	verdict, err := tc.mlClient.CompareDispatches(ml.ContextWithIncident(ctx, meta.IncidentID), ml.DispatchComparisonInput{
+		ActiveDispatch: meta.Transcription,
+		NewDispatch:    newText,
+		NewCallType:    dm.CallType,
+	})
+	if err != nil {
+		slog.Warn("split detection: LLM comparison failed; using location verdict",
+			slog.String("error", err.Error()), slog.String("incident", meta.IncidentID))
+		return true, reason
+	}
+	if verdict.SameIncident {
+		slog.Info("split detection: LLM judged re-page the same incident despite location mismatch",
+			slog.String("incident", meta.IncidentID), slog.String("reason", verdict.Reason))
+		return false, ""
+	}
+	if verdict.Reason != "" {
+		reason = verdict.Reason
+	}
+	return true, reason
+}
+
+// sampleTokens renders up to three tokens, sorted, for the operator-facing reason.
+func sampleTokens(tokens map[string]bool) string {
+	keys := make([]string, 0, len(tokens))
+	for t := range tokens {
+		keys = append(keys, t)
+	}
+	slices.Sort(keys)
+	if len(keys) > 3 {
+		keys = append(keys[:3], "…")
+	}
+	return strings.Join(keys, ", ")
+}
+
+// saveSplitCandidate stores the re-page for the Split button under a freshly minted incident
+// ID and returns it. The candidate lives as long as one activation window; after that the
+// rescue has moved on and the prompt is stale. It is indexed under the parent first, so a
+// candidate that exists is always one the parent's cleanup can find.
+func (tc *TranscribeClient) saveSplitCandidate(ctx context.Context, cand SplitCandidate) (string, error) {
+	cand.IncidentID = newIncidentID()
+	payload, err := json.Marshal(cand)
+	if err != nil {
+		return "", fmt.Errorf("marshal split candidate: %w", err)
+	}
+	indexKey := fmt.Sprintf(splitCandidatesKeyFmt, cand.ParentIncidentID)
+	if err := tc.dragonflyClient.RPush(ctx, indexKey, cand.IncidentID); err != nil {
+		return "", fmt.Errorf("rpush split_candidates: %w", err)
+	}
+	if err := tc.dragonflyClient.Expire(ctx, indexKey, closureMetaTTL); err != nil {
+		return "", fmt.Errorf("expire split_candidates: %w", err)
+	}
+	if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(splitCandidateKeyFmt, cand.IncidentID), tc.config.TacticalChannelActivationDuration, string(payload)); err != nil {
+		return "", fmt.Errorf("set split_candidate: %w", err)
+	}
+	return cand.IncidentID, nil
+}
@@ -0,0 +1,88 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/searchandrescuegg/transcribe/internal/config"
+	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLocationTokens_DropsBoilerplateAndUnitNumbers(t *testing.T) {
+	got := locationTokens("Engine 8171, Medic 104 respond Rescue - Trail, Mount Si Trailhead, TAC 3")
+	assert.Equal(t, map[string]bool{"mount": true, "trailhead": true}, got)
+}
+
+func TestTokenOverlap(t *testing.T) {
+	cases := map[string]struct {
+		a, b   string
+		want   float64
+		wantOK bool
+	}{
+		"same place, extra detail": {a: "Mount Si trailhead", b: "Mount Si trailhead upper parking lot", want: 1, wantOK: true},
+		"different places":         {a: "Mount Si trailhead", b: "Tiger Mountain Poo Poo Point", want: 0, wantOK: true},
+		"nothing to compare":       {a: "Rescue Trail TAC 3", b: "Tiger Mountain", wantOK: false},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, ok := tokenOverlap(locationTokens(c.a), locationTokens(c.b))
+			assert.Equal(t, c.wantOK, ok)
+			assert.InDelta(t, c.want, got, 0.001)
+		})
+	}
+}
+
+func TestAssessSplit(t *testing.T) {
+	active := ClosureMeta{IncidentID: "inc-1", Transcription: "Aid 181 respond Rescue Trail, Mount Si trailhead, TAC 3"}
+	sameDM := ml.DispatchMessage{CallType: "Rescue - Trail", CleanedTranscription: "Engine 8171 respond Rescue Trail, Mount Si trailhead, TAC 3"}
+	otherDM := ml.DispatchMessage{CallType: "Rescue - Trail", CleanedTranscription: "Medic 104 respond Rescue Trail, Tiger Mountain Poo Poo Point, TAC 3"}
+
+	newClient := func(llm bool, m *mockMLClient) *TranscribeClient {
+		return &TranscribeClient{
+			config:   &config.Config{SplitDetectionMinOverlap: 0.34, SplitDetectionLLMEnabled: llm},
+			mlClient: m,
+		}
+	}
+
+	t.Run("same location is a re-page", func(t *testing.T) {
+		m := new(mockMLClient)
+		distinct, _ := newClient(true, m).assessSplit(context.Background(), active, sameDM)
+		assert.False(t, distinct)
+		m.AssertNotCalled(t, "CompareDispatches", mock.Anything, mock.Anything)
+	})
+
+	t.Run("different location without LLM prompts", func(t *testing.T) {
+		distinct, reason := newClient(false, new(mockMLClient)).assessSplit(context.Background(), active, otherDM)
+		assert.True(t, distinct)
+		assert.Contains(t, reason, "tiger")
+		assert.Contains(t, reason, "trailhead")
+	})
+
+	t.Run("LLM can overrule a location mismatch", func(t *testing.T) {
+		m := new(mockMLClient)
+		m.On("CompareDispatches", mock.Anything, mock.Anything).Return(&ml.DispatchComparison{SameIncident: true, Reason: "same park"}, nil).Once()
+		distinct, _ := newClient(true, m).assessSplit(context.Background(), active, otherDM)
+		assert.False(t, distinct)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("LLM reason replaces the token reason", func(t *testing.T) {
+		m := new(mockMLClient)
+		m.On("CompareDispatches", mock.Anything, mock.MatchedBy(func(in ml.DispatchComparisonInput) bool {
+			return in.ActiveDispatch == active.Transcription && in.NewDispatch == otherDM.CleanedTranscription
+		})).Return(&ml.DispatchComparison{SameIncident: false, Reason: "Mount Si and Tiger Mountain are different trails."}, nil).Once()
+		distinct, reason := newClient(true, m).assessSplit(context.Background(), active, otherDM)
+		assert.True(t, distinct)
+		assert.Equal(t, "Mount Si and Tiger Mountain are different trails.", reason)
+	})
+
+	t.Run("LLM error keeps the location verdict", func(t *testing.T) {
+		m := new(mockMLClient)
+		m.On("CompareDispatches", mock.Anything, mock.Anything).Return(nil, errors.New("boom")).Once()
+		distinct, _ := newClient(true, m).assessSplit(context.Background(), active, otherDM)
+		assert.True(t, distinct)
+	})
+}
@@ -19,12 +19,12 @@ import (
 // entries via ZRem. Restarts no longer drop the closing message.
 //
 // Data model (also used by the Slack interactivity controller in internal/slackctl):
-//   ZSET active_tacs            : member = TGID,           score = unix expiry timestamp
-//   STRING tac_meta:<TGID>       : JSON-encoded ClosureMeta (TAC channel, thread_ts, etc.)
+//   ZSET active_tacs               : member = incident ID, score = unix expiry timestamp
+//   STRING tac_meta:<incident>      : JSON-encoded ClosureMeta (TAC channel, thread_ts, etc.)
 //
-// TGID-as-member lets the cancel handler ZRem the pending closure in O(1) without scanning
-// the whole set. Metadata lives in a sibling key so the ZSET stays small (sweeper queries
-// it on every tick).
+// Incident-ID-as-member lets the cancel handler ZRem the pending closure in O(1) without
+// scanning the whole set. Metadata lives in a sibling key so the ZSET stays small (sweeper
+// queries it on every tick). See incident.go for how IDs relate to TGIDs.
 
 const (
 	activeTACsKey  = "active_tacs"
@@ -35,6 +35,12 @@ const (
 // ClosureMeta is exported so the slackctl controller can deserialize it without duplicating
 // the schema. Field tags must match the marshalled form on disk.
 type ClosureMeta struct {
+	// IncidentID is the ULID every incident key is indexed by. Empty on disk for rescues
+	// scheduled before incident IDs existed; readClosureMeta fills it with the legacy key
+	// (the TGID) so callers can always key off it.
+	IncidentID string `json:"incident_id,omitempty"`
+	// TGID is the primary TAC the rescue is monitoring. It changes on Switch TAC; the
+	// IncidentID doesn't.
 	TGID            string `json:"tgid"`
 	TACChannel      string `json:"tac_channel"`
 	ThreadTS        string `json:"thread_ts"`
@@ -46,28 +52,77 @@ type ClosureMeta struct {
 	// Stored so the sweeper can rebuild the alert blocks (preserving the transcript)
 	// when the auto-close fires and we need to remove the action buttons.
 	Transcription string `json:"transcription,omitempty"`
+	// DispatchedAt is the capture time of the initiating dispatch, used by duration-based
+	// escalation rules. Zero for rescues scheduled before the field was added; those rules
+	// simply never fire for them.
+	DispatchedAt time.Time `json:"dispatched_at"`
+	// HeldUntil is the end of the last window someone asked for: a re-page, an Extend or a
+	// Keep. With TAC_IDLE_TIMEOUT, traffic reschedules the close to now + idle but never
+	// before this (closure_policy.go). Zero until one of those happens.
+	HeldUntil time.Time `json:"held_until"`
+	// AdditionalTGIDs are TACs attached to this incident after dispatch (slackctl "Add TAC").
+	// Their transmissions reply into the same thread and feed the same transcript list; see
+	// multi_tac.go.
+	AdditionalTGIDs []string `json:"additional_tgids,omitempty"`
+	// MapLink is the map button resolved from the dispatch location (MAP_LINKS_ENABLED). Kept
+	// here so every re-render of the alert keeps it. Nil when nothing resolved.
+	MapLink *MapLink `json:"map_link,omitempty"`
 }
 
 // ScheduleTACClosure persists a pending channel-closed notification keyed by expiry time.
 // Replaces the previous in-memory time.AfterFunc which did not survive process restarts.
 // Exported so the Slack interactivity controller can call it on Extend.
 func (tc *TranscribeClient) ScheduleTACClosure(ctx context.Context, meta ClosureMeta, expiresAt time.Time) error {
-	if meta.TGID == "" {
-		return errors.New("ScheduleTACClosure: TGID is required")
+	if meta.IncidentID == "" || meta.TGID == "" {
+		return errors.New("ScheduleTACClosure: IncidentID and TGID are required")
 	}
 	payload, err := json.Marshal(meta)
 	if err != nil {
 		return fmt.Errorf("marshal closure meta: %w", err)
 	}
-	if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(tacMetaKeyFmt, meta.TGID), closureMetaTTL, string(payload)); err != nil {
+	if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(tacMetaKeyFmt, meta.IncidentID), closureMetaTTL, string(payload)); err != nil {
 		return fmt.Errorf("write closure metadata: %w", err)
 	}
-	if err := tc.dragonflyClient.ZAdd(ctx, activeTACsKey, float64(expiresAt.Unix()), meta.TGID); err != nil {
+	if err := tc.dragonflyClient.ZAdd(ctx, activeTACsKey, float64(expiresAt.Unix()), meta.IncidentID); err != nil {
 		return fmt.Errorf("schedule closure in ZSET: %w", err)
 	}
 	return nil
 }
 
+// errNoClosureMeta aborts a tac_meta update for a rescue that is no longer active.
+var errNoClosureMeta = errors.New("no closure metadata")
+
+// updateClosureMeta applies mutate to tac_meta:<incident> as one optimistic transaction
+// (dragonfly Update). Callers holding a ClosureMeta read earlier use it instead of
+// ScheduleTACClosure, which would write that copy back over whatever the Slack controller
+// changed since (an added TAC, an Extend). active=false (no error) when the rescue has closed.
+// mutate may run more than once, each time on a fresh read.
+func (tc *TranscribeClient) updateClosureMeta(ctx context.Context, incidentID string, mutate func(*ClosureMeta)) (meta ClosureMeta, active bool, err error) {
+	err = tc.dragonflyClient.Update(ctx, fmt.Sprintf(tacMetaKeyFmt, incidentID), closureMetaTTL, func(raw string) (string, error) {
+		if raw == "" {
+			return "", errNoClosureMeta
+		}
+		decoded, err := decodeClosureMeta(incidentID, raw)
+		if err != nil {
+			return "", fmt.Errorf("unmarshal closure meta: %w", err)
+		}
+		mutate(&decoded)
+		payload, err := json.Marshal(decoded)
+		if err != nil {
+			return "", fmt.Errorf("marshal closure meta: %w", err)
+		}
+		meta = decoded
+		return string(payload), nil
+	})
+	switch {
+	case errors.Is(err, errNoClosureMeta):
+		return ClosureMeta{}, false, nil
+	case err != nil:
+		return ClosureMeta{}, true, err
+	}
+	return meta, true, nil
+}
+
 // Sweep is the long-running loop that polls for due closures and posts them.
 // Intended to be run as a goroutine alongside the worker pool.
 func (tc *TranscribeClient) Sweep(ctx context.Context) {
@@ -78,6 +133,8 @@ func (tc *TranscribeClient) Sweep(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			slog.Info("TAC closure sweeper stopping")
+			// Reports still generating see the same cancelled context; let them log and exit.
+			tc.afterActionReports.Wait()
 			return
 		case <-ticker.C:
 			tc.sweepOnce(ctx)
@@ -86,67 +143,89 @@ func (tc *TranscribeClient) Sweep(ctx context.Context) {
 }
 
 func (tc *TranscribeClient) sweepOnce(ctx context.Context) {
+	// Duration-based escalation rules need a clock, not a transmission; piggyback on the
+	// sweeper tick rather than running another goroutine.
+	if tc.escalator != nil && tc.escalator.HasTimeRules() {
+		tc.escalateActiveRescues(ctx)
+	}
+	if tc.config.TACClosingWarning > 0 {
+		tc.warnClosingSoon(ctx)
+	}
+
 	now := strconv.FormatInt(time.Now().Unix(), 10)
-	tgids, err := tc.dragonflyClient.ZRangeByScore(ctx, activeTACsKey, "0", now)
+	incidentIDs, err := tc.dragonflyClient.ZRangeByScore(ctx, activeTACsKey, "0", now)
 	if err != nil {
 		slog.Error("sweeper: failed to query active_tacs", slog.String("error", err.Error()))
 		return
 	}
-	for _, tgid := range tgids {
+	for _, incidentID := range incidentIDs {
 		// FIX (concurrency): ZRem returns 1 only for the goroutine that actually removed the
 		// member, so even with multiple sweeper instances each due closure is delivered exactly
 		// once. We claim before reading metadata so a competing cancel-handler can't race us.
-		removed, err := tc.dragonflyClient.ZRem(ctx, activeTACsKey, tgid)
+		removed, err := tc.dragonflyClient.ZRem(ctx, activeTACsKey, incidentID)
 		if err != nil {
-			slog.Error("sweeper: failed to claim TGID", slog.String("error", err.Error()), slog.String("tgid", tgid))
+			slog.Error("sweeper: failed to claim incident", slog.String("error", err.Error()), slog.String("incident", incidentID))
 			continue
 		}
 		if removed == 0 {
 			continue
 		}
-		metaKey := fmt.Sprintf(tacMetaKeyFmt, tgid)
+		metaKey := fmt.Sprintf(tacMetaKeyFmt, incidentID)
 		raw, err := tc.dragonflyClient.Get(ctx, metaKey)
 		if err != nil {
-			slog.Error("sweeper: failed to read closure metadata", slog.String("error", err.Error()), slog.String("tgid", tgid))
+			slog.Error("sweeper: failed to read closure metadata", slog.String("error", err.Error()), slog.String("incident", incidentID))
 			continue
 		}
 
 		// FIX (feedback URL prefill): cleanup MUST run after postChannelClosed, not before.
-		// The feedback-URL builder inside updateAlertForClosure reads summary_data:<TGID>
+		// The feedback-URL builder inside updateAlertForClosure reads summary_data:<incident>
 		// for the headline + situation summary prefill — if Del runs first, those fields
 		// silently fall back to empty in the form URL. Inline closure so each early-return
 		// path also runs cleanup but the post-success path goes through it AFTER the post.
 		cleanup := func() {
-			_ = tc.dragonflyClient.Del(ctx,
-				metaKey,
-				fmt.Sprintf(tacTranscriptsKeyFmt, tgid),
-				fmt.Sprintf(summaryTSKeyFmt, tgid),
-				fmt.Sprintf(summaryLockKeyFmt, tgid),
-				fmt.Sprintf(summaryStaleKeyFmt, tgid),
-				fmt.Sprintf(summaryDataKeyFmt, tgid),
-				fmt.Sprintf(pulpoUnitsKeyFmt, tgid),
-			)
+			if err := DeleteIncidentState(ctx, tc.dragonflyClient, incidentID); err != nil {
+				slog.Warn("sweeper: closure cleanup incomplete", slog.String("error", err.Error()), slog.String("incident", incidentID))
+			}
 		}
 
 		if raw == "" {
-			slog.Warn("sweeper: closure metadata missing, skipping", slog.String("tgid", tgid))
+			slog.Warn("sweeper: closure metadata missing, skipping", slog.String("incident", incidentID))
 			cleanup()
 			continue
 		}
-		var meta ClosureMeta
-		if err := json.Unmarshal([]byte(raw), &meta); err != nil {
-			slog.Error("sweeper: failed to unmarshal closure metadata, dropping", slog.String("error", err.Error()), slog.String("tgid", tgid), slog.String("raw", raw))
+		meta, err := decodeClosureMeta(incidentID, raw)
+		if err != nil {
+			slog.Error("sweeper: failed to unmarshal closure metadata, dropping", slog.String("error", err.Error()), slog.String("incident", incidentID), slog.String("raw", raw))
 			cleanup()
 			continue
 		}
-		tc.postChannelClosed(ctx, &meta)
+		tc.publishClosed(ctx, &meta, time.Now().Local())
+		tc.releaseIncidentRouting(ctx, meta)
+		// Both read tac_transcripts / summary_data, so they too must run before cleanup. The
+		// after-action report only snapshots them here; the model call runs in the background.
+		closedAt := time.Now()
+		tc.publishAfterActionReport(ctx, meta, closedAt)
+		tc.recordClosedIncident(ctx, meta, closedAt)
 		cleanup()
 	}
 }
 
-func (tc *TranscribeClient) postChannelClosed(ctx context.Context, m *ClosureMeta) {
-	closedAt := time.Now().Local()
+// decodeClosureMeta parses a tac_meta record read from tac_meta:<key>. Legacy records carry
+// no IncidentID; the key they were stored under (their TGID) is their ID.
+func decodeClosureMeta(key, raw string) (ClosureMeta, error) {
+	var meta ClosureMeta
+	if err := json.Unmarshal([]byte(raw), &meta); err != nil {
+		return ClosureMeta{}, err
+	}
+	if meta.IncidentID == "" {
+		meta.IncidentID = key
+	}
+	return meta, nil
+}
 
+// postChannelClosed is the Slack side of a close, run by the primary sink (notify.go) ahead of
+// the secondary sinks' Closed events.
+func (tc *TranscribeClient) postChannelClosed(ctx context.Context, m *ClosureMeta, closedAt time.Time) {
 	// 1) Rewrite the parent alert: same blocks, but the actions row is gone and the
 	// "Expires …" line becomes "FTAC X monitoring auto-closed at …". We swallow update
 	// errors (just log + continue) because the thread reply below is the canonical signal;
@@ -154,18 +233,17 @@ func (tc *TranscribeClient) postChannelClosed(ctx context.Context, m *ClosureMet
 	tc.updateAlertForClosure(ctx, m, closedAt)
 
 	// 2) Post the channel-closed notice in the rescue thread.
-	msgOptions := []slack.MsgOption{
-		slack.MsgOptionBlocks(BuildChannelClosedBlocks(&ChannelClosedBlocksInput{
+	if err := tc.deliverSlack(ctx, outboundSlackMessage{
+		Kind: slackOpPost,
+		Blocks: slack.Blocks{BlockSet: BuildChannelClosedBlocks(&ChannelClosedBlocksInput{
 			Channel:  m.TACChannel,
 			ClosedAt: closedAt,
-		})...),
-		slack.MsgOptionAsUser(true),
-		slack.MsgOptionTS(m.ThreadTS),
-	}
-	if tc.config.SlackChannelClosedBroadcastEnabled {
-		msgOptions = append(msgOptions, slack.MsgOptionBroadcast())
-	}
-	if _, err := tc.sendSlackWithRetry(ctx, m.SourceTalkgroup, msgOptions...); err != nil {
+		})},
+		AsUser:    true,
+		ThreadTS:  m.ThreadTS,
+		Broadcast: tc.config.SlackChannelClosedBroadcastEnabled,
+		Talkgroup: m.SourceTalkgroup,
+	}); err != nil {
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			slog.Warn("sweeper: shutdown interrupted channel-closed post", slog.String("error", err.Error()), slog.String("tac", m.TACChannel))
 			return
@@ -191,10 +269,10 @@ func (tc *TranscribeClient) updateAlertForClosure(ctx context.Context, m *Closur
 	}
 
 	// Build the feedback URL BEFORE the closure cleanup runs (the sidecar Del happens in
-	// the caller AFTER this method returns). buildFeedbackURL reads summary_data:<TGID>
+	// the caller AFTER this method returns). buildFeedbackURL reads summary_data:<incident>
 	// for the headline + situation summary; it returns "" cleanly when FEEDBACK_FORM_URL
 	// is not configured.
-	feedbackURL := tc.buildFeedbackURL(ctx, m.TGID, *m, closedAt)
+	feedbackURL := tc.buildFeedbackURL(ctx, m.IncidentID, *m, closedAt)
 
 	blocks := BuildRescueTrailBlocks(&RescueTrailBlocksInput{
 		TACChannel:        m.TACChannel,
@@ -205,19 +283,20 @@ func (tc *TranscribeClient) updateAlertForClosure(ctx context.Context, m *Closur
 		ClosedAt:         &closedAt,
 		FeedbackURL:      feedbackURL,
 		// Preserve the SAR-notified badge on the closed alert if it was set during the rescue.
-		SARNotified: tc.summarySARNotified(ctx, m.TGID),
+		SARNotified:           tc.summarySARNotified(ctx, m.IncidentID),
+		AdditionalTACChannels: m.AdditionalTACChannels(),
+		MapLink:               m.MapLink,
 	})
 
-	updateCtx, cancel := context.WithTimeout(ctx, tc.config.SlackTimeout)
-	defer cancel()
-	if _, _, _, err := tc.slackClient.UpdateMessageContext(updateCtx,
-		tc.config.SlackChannelID,
-		m.MessageTS,
-		slack.MsgOptionBlocks(blocks...),
+	if err := tc.deliverSlack(ctx, outboundSlackMessage{
+		Kind:   slackOpUpdate,
+		TS:     m.MessageTS,
+		Blocks: slack.Blocks{BlockSet: blocks},
 		// chat.update requires a fallback text; keep it terse so notification previews are
 		// useful even though they're rare on already-seen messages.
-		slack.MsgOptionText(fmt.Sprintf("%s monitoring auto-closed.", m.TACChannel), false),
-	); err != nil {
+		Text:      fmt.Sprintf("%s monitoring auto-closed.", m.TACChannel),
+		Talkgroup: m.SourceTalkgroup,
+	}); err != nil {
 		slog.Warn("sweeper: failed to update parent alert (thread reply still posts)",
 			slog.String("error", err.Error()),
 			slog.String("tac", m.TACChannel),
@@ -228,8 +307,8 @@ func (tc *TranscribeClient) updateAlertForClosure(ctx context.Context, m *Closur
 // readSummaryData reads and decodes the latest cached RescueSummary for a rescue. Returns
 // (nil, false) when the key is missing or unparseable. Best-effort: callers treat a false ok as
 // "no prior summary" and proceed.
-func (tc *TranscribeClient) readSummaryData(ctx context.Context, tgid string) (*ml.RescueSummary, bool) {
-	raw, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(summaryDataKeyFmt, tgid))
+func (tc *TranscribeClient) readSummaryData(ctx context.Context, incidentID string) (*ml.RescueSummary, bool) {
+	raw, err := tc.dragonflyClient.Get(ctx, fmt.Sprintf(summaryDataKeyFmt, incidentID))
 	if err != nil || raw == "" {
 		return nil, false
 	}
@@ -243,7 +322,7 @@ func (tc *TranscribeClient) readSummaryData(ctx context.Context, tgid string) (*
 // summarySARNotified reads the latest cached RescueSummary and reports whether SAR was
 // notified, so the green-check badge survives onto the closed alert. Best-effort — any read
 // or decode failure defaults to false (no badge).
-func (tc *TranscribeClient) summarySARNotified(ctx context.Context, tgid string) bool {
-	s, ok := tc.readSummaryData(ctx, tgid)
+func (tc *TranscribeClient) summarySARNotified(ctx context.Context, incidentID string) bool {
+	s, ok := tc.readSummaryData(ctx, incidentID)
 	return ok && s.SARNotified
 }
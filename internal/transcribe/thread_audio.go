@@ -0,0 +1,88 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/searchandrescuegg/transcribe/internal/audio"
+	"github.com/slack-go/slack"
+)
+
+// SLACK_AUDIO_MODE values.
+const (
+	AudioModeUpload  = "upload"
+	AudioModePresign = "presign"
+)
+
+// SLACK_AUDIO_UPLOAD_FORMAT values. Both upload PCM WAV; wav-downsampled only shrinks it to
+// 8 kHz mono. There is no compressed (Opus/MP3) format: see package audio.
+const (
+	AudioFormatWAV            = "wav"
+	AudioFormatWAVDownsampled = "wav-downsampled"
+)
+
+// presignTransmissionAudio returns a presigned GET URL for the transmission's S3 object when
+// SLACK_AUDIO_MODE=presign, or "" when disabled or signing fails. Best-effort: a missing link
+// never blocks the thread reply.
+func (tc *TranscribeClient) presignTransmissionAudio(ctx context.Context, parsedKey *AdornedDeconstructedKey) string {
+	if tc.config.SlackAudioMode != AudioModePresign || tc.s3Client == nil || parsedKey.key == "" {
+		return ""
+	}
+	url, err := tc.s3Client.Presign(ctx, parsedKey.key, tc.config.SlackAudioPresignTTL)
+	if err != nil {
+		slog.Warn("thread audio: presign failed; posting reply without a clip link",
+			slog.String("error", err.Error()), slog.String("key", parsedKey.key))
+		return ""
+	}
+	return url
+}
+
+// uploadTransmissionReply posts the thread reply as a files.uploadV2 share carrying the
+// original clip, with blocks as the message body. Returns false when the mode is off, the
+// audio isn't available, or the upload fails — the caller then posts the plain reply, so a
+// files:write misconfiguration degrades to text rather than losing the transmission.
+//
+// Uploads bypass the outbound queue: the clip can be megabytes, and the stream is sized for
+// small JSON payloads.
+func (tc *TranscribeClient) uploadTransmissionReply(ctx context.Context, parsedKey *AdornedDeconstructedKey, threadTS, title string, blocks []slack.Block) bool {
+	if tc.config.SlackAudioMode != AudioModeUpload || len(parsedKey.audio) == 0 {
+		return false
+	}
+
+	payload, filename := parsedKey.audio, path.Base(parsedKey.key)
+	if tc.config.SlackAudioUploadFormat == AudioFormatWAVDownsampled {
+		downsampled, err := audio.DownsampleWAV(parsedKey.audio)
+		if err != nil {
+			// Unusual encodings still upload — just at full size.
+			slog.Warn("thread audio: downsample failed; uploading original",
+				slog.String("error", err.Error()), slog.String("key", parsedKey.key))
+		} else {
+			payload = downsampled
+			filename = strings.TrimSuffix(filename, path.Ext(filename)) + "-8k.wav"
+		}
+	}
+
+	_, err := tc.retrySlack(ctx, parsedKey.dk.Talkgroup, func() (string, error) {
+		uploadCtx, cancel := context.WithTimeout(ctx, tc.config.SlackTimeout)
+		defer cancel()
+		_, err := tc.slackClient.UploadFileV2Context(uploadCtx, slack.UploadFileV2Parameters{
+			Reader:          bytes.NewReader(payload),
+			FileSize:        len(payload),
+			Filename:        filename,
+			Title:           title,
+			Blocks:          slack.Blocks{BlockSet: blocks},
+			Channel:         tc.config.SlackChannelID,
+			ThreadTimestamp: threadTS,
+		})
+		return "", err
+	})
+	if err != nil {
+		slog.Warn("thread audio: upload failed; falling back to text reply",
+			slog.String("error", err.Error()), slog.String("key", parsedKey.key))
+		return false
+	}
+	return true
+}
@@ -4,17 +4,25 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"strings"
+	"sync"
 	"time"
 
 	pulsarapi "github.com/apache/pulsar-client-go/pulsar"
 	"github.com/searchandrescuegg/transcribe/internal/asr"
+	"github.com/searchandrescuegg/transcribe/internal/cad"
+	"github.com/searchandrescuegg/transcribe/internal/callsign"
 	"github.com/searchandrescuegg/transcribe/internal/config"
 	"github.com/searchandrescuegg/transcribe/internal/dataset"
 	"github.com/searchandrescuegg/transcribe/internal/dragonfly"
+	"github.com/searchandrescuegg/transcribe/internal/escalation"
+	"github.com/searchandrescuegg/transcribe/internal/gazetteer"
 	"github.com/searchandrescuegg/transcribe/internal/ml"
+	"github.com/searchandrescuegg/transcribe/internal/notify"
 	"github.com/searchandrescuegg/transcribe/internal/pulsar"
 	"github.com/searchandrescuegg/transcribe/internal/s3"
 	"github.com/slack-go/slack"
@@ -44,28 +52,36 @@ const (
 // UpdateMessageContext lets the sweeper rewrite the parent rescue alert when the auto-close
 // fires (remove the actions block, change "expires" to "auto-closed"). The signature mirrors
 // the slack-go method exactly so *slack.Client continues to satisfy the interface.
+//
+// UploadFileV2Context backs SLACK_AUDIO_MODE=upload, which posts a TAC transmission's thread
+// reply as a file share carrying the original audio clip.
 type SlackPoster interface {
 	SendMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, string, error)
 	UpdateMessageContext(ctx context.Context, channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error)
+	UploadFileV2Context(ctx context.Context, params slack.UploadFileV2Parameters) (*slack.FileSummary, error)
 }
 
 // MLClient bundles the ML capabilities the worker uses: the dispatch-parser for turning a raw
-// transcription into structured trail-rescue info, the per-transmission TAC cleaner, and the
+// transcription into structured trail-rescue info, the per-transmission TAC cleaner, the
 // summarizer that turns dispatch + ordered TAC transmissions into a structured situational
-// summary. Both the OpenAI-compatible and Anthropic backends implement all three, so production
-// wiring stays a single dependency.
+// summary, and the same-incident check used by split detection. Both the OpenAI-compatible and
+// Anthropic backends implement all four, so production wiring stays a single dependency.
 type MLClient interface {
 	ml.DispatchMessageParser
 	ml.RescueSummarizer
 	ml.TranscriptCleaner
+	ml.IncidentComparer
+	ml.AfterActionReporter
 }
 
-// UnitResolver produces a rendered "units currently assigned to the call" prompt block from the
-// CAD (PulsePoint) feed, used to canonicalize garbled unit callsigns in cleanup and summaries.
+// UnitResolver produces the "units currently assigned to the call" context from the CAD feed
+// (whichever CAD_PROVIDER is configured), used to canonicalize garbled unit callsigns in the
+// callsign normalizer, cleanup and summaries. pinnedID is the CAD incident an operator pinned
+// for the rescue from the live interpretation, or empty for the automatic match.
 // Optional — nil when PULPO_ENABLED is false, in which case unit context is simply empty and the
-// LLM calls run exactly as they did before. Implemented by *pulsepoint.Resolver.
+// LLM calls run exactly as they did before. Implemented by *cad.Resolver.
 type UnitResolver interface {
-	RescueUnitBlock(ctx context.Context, dispatchText string, referenceTime time.Time) (string, error)
+	ResolveForRescue(ctx context.Context, dispatchText string, referenceTime time.Time, pinnedID string) (cad.UnitContext, error)
 }
 
 type TranscribeClient struct {
@@ -83,36 +99,66 @@ type TranscribeClient struct {
 	// unitResolver is the optional CAD unit-enrichment source. Nil disables enrichment.
 	unitResolver UnitResolver
 
+	// callsigns is the deterministic callsign pass run on TAC transmissions ahead of cleanup.
+	// Nil when CALLSIGN_NORMALIZER_ENABLED is false.
+	callsigns *callsign.Normalizer
+
+	// notifier fans incident events out to the primary Slack sink, then to the secondary
+	// sinks (Teams, signed webhooks, partner Slack workspaces) when NOTIFY_SINKS is set.
+	notifier *notify.FanOut
+
+	// escalator pages people outside Slack when an escalation rule fires. Nil when
+	// ESCALATION_RULES is empty.
+	escalator *escalation.Escalator
+
+	// gazetteer is the place-name list behind the snap pre-pass (PLACE_NAME_SNAP_ENABLED)
+	// and map links (MAP_LINKS_ENABLED). Nil in tests that exercise neither.
+	gazetteer *gazetteer.Gazetteer
+
+	// afterActionSlots bounds the after-action reports generating at once, and
+	// afterActionReports tracks them so Sweep can wait for them on shutdown.
+	afterActionSlots   chan struct{}
+	afterActionReports sync.WaitGroup
+
 	config *config.Config
 }
 
-func NewTranscribeClient(config *config.Config, pulsarClient *pulsar.PulsarClient, s3Client *s3.S3Client, asrClient *asr.ASRClient, mlClient MLClient, dragonflyClient *dragonfly.DragonflyClient, recorder dataset.Recorder, unitResolver UnitResolver) *TranscribeClient {
-	return &TranscribeClient{
-		pulsarClient:    pulsarClient,
-		s3Client:        s3Client,
-		asrClient:       asrClient,
-		mlClient:        mlClient,
-		slackClient:     slack.New(config.SlackToken),
-		dragonflyClient: dragonflyClient,
-		recorder:        recorder,
-		unitResolver:    unitResolver,
-		config:          config,
+func NewTranscribeClient(config *config.Config, pulsarClient *pulsar.PulsarClient, s3Client *s3.S3Client, asrClient *asr.ASRClient, mlClient MLClient, dragonflyClient *dragonfly.DragonflyClient, recorder dataset.Recorder, unitResolver UnitResolver, callsigns *callsign.Normalizer, notifier *notify.FanOut, escalator *escalation.Escalator, places *gazetteer.Gazetteer) *TranscribeClient {
+	tc := &TranscribeClient{
+		pulsarClient:     pulsarClient,
+		s3Client:         s3Client,
+		asrClient:        asrClient,
+		mlClient:         mlClient,
+		slackClient:      slack.New(config.SlackToken),
+		dragonflyClient:  dragonflyClient,
+		recorder:         recorder,
+		unitResolver:     unitResolver,
+		callsigns:        callsigns,
+		escalator:        escalator,
+		gazetteer:        places,
+		afterActionSlots: make(chan struct{}, afterActionMaxInFlight),
+		config:           config,
 	}
+	tc.notifier = newNotifier(tc, notifier)
+	return tc
 }
 
 // newTranscribeClientForTest is a test-only constructor that accepts a SlackPoster directly.
 // Avoids the production NewTranscribeClient's hard-coded slack.New(token) so unit tests can
 // inject a testify mock.
 func newTranscribeClientForTest(c *config.Config, pulsarClient *pulsar.PulsarClient, s3Client *s3.S3Client, asrClient *asr.ASRClient, mlClient MLClient, slackClient SlackPoster, dragonflyClient *dragonfly.DragonflyClient) *TranscribeClient {
-	return &TranscribeClient{
-		pulsarClient:    pulsarClient,
-		s3Client:        s3Client,
-		asrClient:       asrClient,
-		mlClient:        mlClient,
-		slackClient:     slackClient,
-		dragonflyClient: dragonflyClient,
-		config:          c,
+	tc := &TranscribeClient{
+		pulsarClient:     pulsarClient,
+		s3Client:         s3Client,
+		asrClient:        asrClient,
+		mlClient:         mlClient,
+		slackClient:      slackClient,
+		dragonflyClient:  dragonflyClient,
+		afterActionSlots: make(chan struct{}, afterActionMaxInFlight),
+		config:           c,
 	}
+	tc.notifier = newNotifier(tc, nil)
+	return tc
 }
 
 func (tc *TranscribeClient) Work(ctx context.Context) {
@@ -275,16 +321,48 @@ func (tc *TranscribeClient) processRecord(ctx context.Context, record *s3event.E
 		}()
 	}
 
-	fileBytes, err := tc.s3Client.GetFile(ctx, key)
+	// Stream the object straight into the ASR request rather than reading it into memory and
+	// then copying it again into a multipart buffer. The S3 client's max-object-size guard
+	// fails runaway recordings before (Content-Length) or while (bytes read) they're pulled.
+	body, size, err := tc.s3Client.StreamFile(ctx, key)
 	if err != nil {
+		if errors.Is(err, s3.ErrObjectTooLarge) {
+			// Permanent: redelivery would just fail the same way until the DLQ. Ack and move on.
+			slog.Error("skipping oversized S3 object", slog.String("error", err.Error()), slog.String("key", key))
+			return nil
+		}
 		return fmt.Errorf("failed to get S3 file: %w", err)
 	}
-	slog.Debug("got S3 file", slog.String("key", key))
+	defer func() {
+		_ = body.Close()
+	}()
+	slog.Debug("streaming S3 file to ASR", slog.String("key", key), slog.Int64("size", size))
+
+	// SLACK_AUDIO_MODE=upload needs the bytes again after transcription; only then tee a copy
+	// (bounded by the same size guard) instead of holding every recording in memory.
+	var asrInput io.Reader = body
+	var audioCopy *bytes.Buffer
+	if tc.config.SlackAudioMode == AudioModeUpload {
+		audioCopy = &bytes.Buffer{}
+		if size > 0 {
+			audioCopy.Grow(int(size))
+		}
+		asrInput = io.TeeReader(body, audioCopy)
+	}
 
-	tr, err := tc.asrClient.Transcribe(ctx, key, bytes.NewBuffer(fileBytes))
+	tr, err := tc.asrClient.Transcribe(ctx, key, asrInput)
 	if err != nil {
+		if errors.Is(err, s3.ErrObjectTooLarge) {
+			// The object had no (or a lying) Content-Length and went over the cap mid-read, so the
+			// guard tripped inside the upload to ASR. Just as permanent as the up-front case.
+			slog.Error("skipping oversized S3 object", slog.String("error", err.Error()), slog.String("key", key))
+			return nil
+		}
 		return fmt.Errorf("failed to transcribe file: %w", err)
 	}
+	if audioCopy != nil {
+		parsedKey.audio = audioCopy.Bytes()
+	}
 	slog.Info("transcription completed", slog.String("key", key), slog.String("transcription", tr.Transcription), slog.Bool("no_speech", tr.NoSpeechDetected))
 
 	isDispatch := parsedKey.dk.Talkgroup == FireDispatch1TGID
@@ -315,6 +393,10 @@ func (tc *TranscribeClient) processRecord(ctx context.Context, record *s3event.E
 		return nil
 	}
 
+	// Fix obvious place-name near-misses before any model sees the text. After the dataset
+	// capture on purpose: the dataset keeps what the ASR actually produced.
+	tr.Transcription = tc.snapPlaceNames(tr.Transcription, key)
+
 	if parsedKey.dk.Talkgroup == FireDispatch1TGID {
 		if err := tc.processDispatchCall(ctx, parsedKey, tr); err != nil {
 			return fmt.Errorf("failed to process fire dispatch call (talkgroup=%s): %w", parsedKey.dk.Talkgroup, err)
@@ -2,76 +2,90 @@ package transcribe
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/cad"
 )
 
-// CAD (PulsePoint) unit-context enrichment, threaded into the per-transmission cleanup and the
-// live summary so the model can canonicalize garbled unit callsigns against the units actually
-// assigned to the call.
+// CAD unit-context enrichment, threaded into the per-transmission cleanup and the live summary so
+// the model can canonicalize garbled unit callsigns against the units actually assigned to the
+// call.
 //
-// The resolved, pre-rendered prompt block is cached per-rescue in Dragonfly under
-// pulpo_units:<TGID> with a short TTL (PulpoRefreshInterval). That TTL means the roster
-// self-refreshes as units are added over the life of the incident, and it self-expires without
-// needing explicit cleanup — though it is also DEL'd on every teardown path alongside the other
-// sidecars (CLAUDE.md invariant #6). A resolved-but-empty result is cached as a sentinel so a
-// consistently-empty or erroring CAD feed doesn't get re-hit on every transmission.
+// The resolved unit context is cached per-rescue in Dragonfly under pulpo_units:<incident>, as
+// JSON so the callsign normalizer can read the roster as well as the prompts rendering it, with a
+// short TTL (PulpoRefreshInterval). That TTL means the roster self-refreshes as units are added
+// over the life of the incident, and it self-expires without needing explicit cleanup — though
+// it is also DEL'd on every teardown path alongside the other sidecars (CLAUDE.md invariant #6).
+// A resolved-but-empty result is cached too (as an empty context, which still encodes to a
+// non-empty value) so a consistently-empty or erroring CAD feed doesn't get re-hit on every
+// transmission.
 
-const (
-	pulpoUnitsKeyFmt = "pulpo_units:%s"
+const pulpoUnitsKeyFmt = "pulpo_units:%s"
 
-	// emptyUnitSentinel marks "resolved, but no unit context" so it can be cached distinctly
-	// from a cache miss (Dragonfly Get returns "" for both a missing key and an empty value).
-	emptyUnitSentinel = "\x00none"
-)
+// pulpoPinKeyFmt holds the CAD incident an operator pinned for the rescue from the live
+// interpretation's dropdown (CAD_MATCH_DETAILS_ENABLED), overriding the automatic match while
+// it stays active. Written by slackctl, which also drops the cached unit context so the pin
+// applies on the next refresh. TTL closureMetaTTL, since nothing refreshes it while the rescue
+// stays open; DEL'd on every teardown path.
+const pulpoPinKeyFmt = "pulpo_pin:%s"
 
-// unitContextFor returns the rendered CAD unit-context block for a rescue, reading the per-rescue
-// cache first and resolving+caching on a miss. Best-effort: returns "" (no context) when
-// enrichment is disabled, the resolver errors, or CAD has nothing for this call — the cleanup and
-// summary then run exactly as they would without enrichment.
+// unitContextFor returns the CAD unit context for a rescue, reading the per-rescue cache first
+// and resolving+caching on a miss. Best-effort: returns a zero context (no units, so PromptBlock
+// renders "") when enrichment is disabled, the resolver errors, or CAD has nothing for this call —
+// the normalizer, cleanup and summary then run exactly as they would without enrichment.
 //
 // referenceTime scores incident recency; pass the rescue's dispatch capture time at dispatch, and
 // time.Now() on later refreshes (active CAD incidents are inherently current, so a drifting
 // reference only weakens a tiebreak, never the primary location/call-type match).
-func (tc *TranscribeClient) unitContextFor(ctx context.Context, tgid, dispatchText string, referenceTime time.Time) string {
+func (tc *TranscribeClient) unitContextFor(ctx context.Context, incidentID, dispatchText string, referenceTime time.Time) cad.UnitContext {
 	if tc.unitResolver == nil {
-		return ""
+		return cad.UnitContext{}
 	}
 
-	key := fmt.Sprintf(pulpoUnitsKeyFmt, tgid)
+	key := fmt.Sprintf(pulpoUnitsKeyFmt, incidentID)
 	if cached, err := tc.dragonflyClient.Get(ctx, key); err == nil && cached != "" {
-		if cached == emptyUnitSentinel {
-			return ""
+		var uc cad.UnitContext
+		// An undecodable value (e.g. a rendered block cached by an older build) is a miss.
+		if err := json.Unmarshal([]byte(cached), &uc); err == nil {
+			return uc
 		}
-		return cached
 	}
 
-	return tc.resolveAndCacheUnitContext(ctx, tgid, dispatchText, referenceTime)
+	return tc.resolveAndCacheUnitContext(ctx, incidentID, dispatchText, referenceTime)
 }
 
-// resolveAndCacheUnitContext calls the resolver and writes the result (or the empty sentinel) into
-// the per-rescue cache. Exposed as its own method so processDispatchCall can warm the cache at
+// resolveAndCacheUnitContext calls the resolver and writes the result (empty on error) into the
+// per-rescue cache. Exposed as its own method so processDispatchCall can warm the cache at
 // dispatch time without blocking the alert.
-func (tc *TranscribeClient) resolveAndCacheUnitContext(ctx context.Context, tgid, dispatchText string, referenceTime time.Time) string {
+func (tc *TranscribeClient) resolveAndCacheUnitContext(ctx context.Context, incidentID, dispatchText string, referenceTime time.Time) cad.UnitContext {
 	if tc.unitResolver == nil {
-		return ""
+		return cad.UnitContext{}
 	}
 
-	block, err := tc.unitResolver.RescueUnitBlock(ctx, dispatchText, referenceTime)
+	pinned, _ := tc.dragonflyClient.Get(ctx, fmt.Sprintf(pulpoPinKeyFmt, incidentID))
+	uc, err := tc.unitResolver.ResolveForRescue(ctx, dispatchText, referenceTime, pinned)
+	if err == nil && pinned != "" && !uc.Pinned {
+		slog.Warn("unit enrichment: pinned CAD incident is no longer active; using the automatic match",
+			slog.String("incident", incidentID), slog.String("cad_incident", pinned))
+	}
 	if err != nil {
 		slog.Warn("unit enrichment: resolve failed; continuing without unit context",
-			slog.String("error", err.Error()), slog.String("tgid", tgid))
-		block = ""
+			slog.String("error", err.Error()), slog.String("incident", incidentID))
+		uc = cad.UnitContext{}
+	} else {
+		tc.recordUnitTimeline(ctx, incidentID, uc)
 	}
 
-	toCache := block
-	if toCache == "" {
-		toCache = emptyUnitSentinel
+	encoded, err := json.Marshal(uc)
+	if err != nil {
+		return uc
 	}
-	if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(pulpoUnitsKeyFmt, tgid), tc.config.PulpoRefreshInterval, toCache); err != nil {
+	if err := tc.dragonflyClient.Set(ctx, fmt.Sprintf(pulpoUnitsKeyFmt, incidentID), tc.config.PulpoRefreshInterval, string(encoded)); err != nil {
 		slog.Warn("unit enrichment: failed to cache unit context",
-			slog.String("error", err.Error()), slog.String("tgid", tgid))
+			slog.String("error", err.Error()), slog.String("incident", incidentID))
 	}
-	return block
+	return uc
 }
@@ -0,0 +1,178 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/searchandrescuegg/transcribe/internal/cad"
+	"github.com/slack-go/slack"
+)
+
+// CAD unit timeline (CAD_UNIT_TIMELINE_ENABLED): every time the unit context refreshes (at most
+// once per PulpoRefreshInterval, on the next transmission or summary after the cache expires),
+// the new snapshot of the matched CAD incident is diffed against the units' status history and
+// any change is posted in the rescue thread as one compact line per unit, e.g.
+// "E171 Dispatched → En Route → On Scene". The live interpretation also shows the current
+// units and statuses next to the radio-derived summary.
+//
+// Only a confidently matched incident counts: the roster fallback is the agency's units, not this
+// rescue's. The first snapshot of an incident is the baseline and posts nothing; if the match
+// moves to a different CAD incident the history starts over.
+//
+//   STRING pulpo_unit_timeline:<incident> : JSON unitTimeline, TTL 2 × activation window
+//   STRING pulpo_timeline_lock:<incident> : refresh claim so concurrent workers post once
+//
+// Both are DEL'd on every teardown path alongside the other sidecars.
+
+const (
+	pulpoTimelineKeyFmt     = "pulpo_unit_timeline:%s"
+	pulpoTimelineLockKeyFmt = "pulpo_timeline_lock:%s"
+	pulpoTimelineLockTTL    = 10 * time.Second
+
+	// unitOffCall is shown when a unit drops off the CAD incident (cleared or reassigned).
+	unitOffCall = "off the call"
+)
+
+// unitTimeline is the status history of one CAD incident's units, in first-seen order.
+type unitTimeline struct {
+	CADIncidentID string        `json:"cad_incident_id"`
+	Units         []unitHistory `json:"units"`
+}
+
+type unitHistory struct {
+	ID       string   `json:"id"`
+	Statuses []string `json:"statuses"`
+}
+
+func (h unitHistory) current() string { return h.Statuses[len(h.Statuses)-1] }
+
+// line renders the history as "E171 Dispatched → En Route → On Scene".
+func (h unitHistory) line() string {
+	return h.ID + " " + strings.Join(h.Statuses, " → ")
+}
+
+// advanceTimeline folds a new snapshot into prev and returns the new timeline and the units
+// whose status changed, in timeline order. A nil prev, or one for a different CAD incident,
+// makes uc the baseline with no changes.
+func advanceTimeline(prev *unitTimeline, uc cad.UnitContext) (unitTimeline, []unitHistory) {
+	status := func(u cad.Unit) string {
+		if u.Status == "" {
+			return "Assigned"
+		}
+		return u.Status
+	}
+	if prev == nil || prev.CADIncidentID != uc.IncidentID {
+		next := unitTimeline{CADIncidentID: uc.IncidentID}
+		for _, u := range uc.Units {
+			next.Units = append(next.Units, unitHistory{ID: u.ID, Statuses: []string{status(u)}})
+		}
+		return next, nil
+	}
+
+	now := make(map[string]string, len(uc.Units))
+	for _, u := range uc.Units {
+		now[u.ID] = status(u)
+	}
+	next := unitTimeline{CADIncidentID: prev.CADIncidentID}
+	var changed []unitHistory
+	seen := make(map[string]bool, len(prev.Units))
+	for _, h := range prev.Units {
+		seen[h.ID] = true
+		h.Statuses = append([]string(nil), h.Statuses...)
+		s, onCall := now[h.ID]
+		if !onCall {
+			s = unitOffCall
+		}
+		if s != h.current() {
+			h.Statuses = append(h.Statuses, s)
+			changed = append(changed, h)
+		}
+		next.Units = append(next.Units, h)
+	}
+	for _, u := range uc.Units {
+		if seen[u.ID] {
+			continue
+		}
+		h := unitHistory{ID: u.ID, Statuses: []string{status(u)}}
+		next.Units = append(next.Units, h)
+		changed = append(changed, h)
+	}
+	return next, changed
+}
+
+// recordUnitTimeline diffs a freshly resolved unit context into the incident's timeline and
+// posts any changes in the rescue thread. Best-effort: failures are logged and the timeline
+// simply catches up on the next refresh.
+func (tc *TranscribeClient) recordUnitTimeline(ctx context.Context, incidentID string, uc cad.UnitContext) {
+	if !tc.config.CADUnitTimelineEnabled || !uc.Matched || uc.IncidentID == "" {
+		return
+	}
+	acquired, err := tc.dragonflyClient.SetNX(ctx, fmt.Sprintf(pulpoTimelineLockKeyFmt, incidentID), pulpoTimelineLockTTL, "1")
+	if err != nil || !acquired {
+		// Another worker is diffing the same snapshot.
+		return
+	}
+	defer func() { _ = tc.dragonflyClient.Del(ctx, fmt.Sprintf(pulpoTimelineLockKeyFmt, incidentID)) }()
+
+	key := fmt.Sprintf(pulpoTimelineKeyFmt, incidentID)
+	var prev *unitTimeline
+	if raw, err := tc.dragonflyClient.Get(ctx, key); err == nil && raw != "" {
+		var t unitTimeline
+		if err := json.Unmarshal([]byte(raw), &t); err == nil {
+			prev = &t
+		}
+	}
+	next, changed := advanceTimeline(prev, uc)
+	encoded, err := json.Marshal(next)
+	if err != nil {
+		return
+	}
+	if err := tc.dragonflyClient.Set(ctx, key, 2*tc.config.TacticalChannelActivationDuration, string(encoded)); err != nil {
+		slog.Warn("unit timeline: failed to store; changes will repost on the next refresh", slog.String("error", err.Error()), slog.String("incident", incidentID))
+		return
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	meta, ok := tc.readClosureMeta(ctx, incidentID)
+	if !ok || meta.ThreadTS == "" {
+		return
+	}
+	lines := make([]string, len(changed))
+	for i, h := range changed {
+		lines[i] = h.line()
+	}
+	if err := tc.deliverSlack(ctx, outboundSlackMessage{
+		Kind:      slackOpPost,
+		Blocks:    slack.Blocks{BlockSet: BuildUnitStatusBlocks(lines, time.Now())},
+		Text:      "CAD unit status: " + strings.Join(lines, "; "),
+		AsUser:    true,
+		ThreadTS:  meta.ThreadTS,
+		Talkgroup: meta.SourceTalkgroup,
+	}); err != nil {
+		slog.Warn("unit timeline: failed to post status changes", slog.String("error", err.Error()), slog.String("incident", incidentID))
+	}
+}
+
+// liveInterpretationCAD returns the CAD view for the live interpretation: the matched incident's
+// roster when the timeline is enabled, and the match explanation when match details are. Zero
+// (nothing rendered) when both are off or CAD had no active incidents.
+func (tc *TranscribeClient) liveInterpretationCAD(ctx context.Context, incidentID string, meta ClosureMeta) LiveInterpretationCAD {
+	live := LiveInterpretationCAD{IncidentID: incidentID}
+	if !tc.config.CADUnitTimelineEnabled && !tc.config.CADMatchDetailsEnabled {
+		return live
+	}
+	uc := tc.unitContextFor(ctx, incidentID, meta.Transcription, time.Now())
+	if tc.config.CADUnitTimelineEnabled && uc.Matched {
+		live.Units = uc.Units
+	}
+	if tc.config.CADMatchDetailsEnabled && len(uc.Candidates) > 0 {
+		live.Match = &uc
+	}
+	return live
+}
@@ -0,0 +1,55 @@
+package transcribe
+
+import (
+	"testing"
+
+	"github.com/searchandrescuegg/transcribe/internal/cad"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdvanceTimeline(t *testing.T) {
+	snapshot := func(id string, units ...cad.Unit) cad.UnitContext {
+		return cad.UnitContext{Matched: true, IncidentID: id, Units: units}
+	}
+
+	tl, changed := advanceTimeline(nil, snapshot("cad-1",
+		cad.Unit{ID: "E171", Status: "Dispatched"},
+		cad.Unit{ID: "B171"},
+	))
+	assert.Empty(t, changed, "the first snapshot is the baseline")
+	assert.Equal(t, []unitHistory{{ID: "E171", Statuses: []string{"Dispatched"}}, {ID: "B171", Statuses: []string{"Assigned"}}}, tl.Units)
+
+	tl, changed = advanceTimeline(&tl, snapshot("cad-1",
+		cad.Unit{ID: "E171", Status: "En Route"},
+		cad.Unit{ID: "B171"},
+	))
+	if assert.Len(t, changed, 1) {
+		assert.Equal(t, "E171 Dispatched → En Route", changed[0].line())
+	}
+
+	tl, changed = advanceTimeline(&tl, snapshot("cad-1",
+		cad.Unit{ID: "E171", Status: "On Scene"},
+		cad.Unit{ID: "M104", Status: "Dispatched"},
+	))
+	var lines []string
+	for _, h := range changed {
+		lines = append(lines, h.line())
+	}
+	assert.Equal(t, []string{
+		"E171 Dispatched → En Route → On Scene",
+		"B171 Assigned → off the call",
+		"M104 Dispatched",
+	}, lines)
+
+	unchanged, changed := advanceTimeline(&tl, snapshot("cad-1",
+		cad.Unit{ID: "E171", Status: "On Scene"},
+		cad.Unit{ID: "M104", Status: "Dispatched"},
+	))
+	assert.Empty(t, changed, "same snapshot, nothing to post")
+	assert.Equal(t, tl, unchanged)
+
+	rematched, changed := advanceTimeline(&tl, snapshot("cad-2", cad.Unit{ID: "R3", Status: "Dispatched"}))
+	assert.Empty(t, changed, "a different CAD incident starts a new baseline")
+	assert.Equal(t, "cad-2", rematched.CADIncidentID)
+	assert.Len(t, rematched.Units, 1)
+}